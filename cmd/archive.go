@@ -0,0 +1,421 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/archive"
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/EndlessUphill/git-helper/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var (
+	archiveOutput   string
+	archiveFormat   string
+	archiveLFS      bool
+	archiveMetadata bool
+	restoreBare     bool
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive [repo]",
+	Short: "Export a repository to a bundle or tarball",
+	Long: `Export the complete history of a repository to a single file, for
+compliance backups or moving a repository between offline machines.
+
+Without a repository, the current one is exported; otherwise the repository
+(owner/repo or a URL) is mirror-cloned first. Two formats are available:
+
+  bundle  a plain git bundle of every ref, usable with 'git clone'
+  tar     a .tar.gz holding the bundle plus, optionally, Git LFS objects
+          (--lfs) and GitHub metadata (--metadata): repository settings,
+          issues, pull requests, comments, releases, labels and milestones
+
+Import an archive with 'githelper restore-archive'.
+
+Example:
+  githelper archive                              # ./<repo>-<timestamp>.tar.gz
+  githelper archive -o backup.bundle             # Bundle only
+  githelper archive owner/repo --lfs --metadata  # Full export of a GitHub repo
+  githelper archive --format bundle`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runArchive,
+}
+
+var restoreArchiveCmd = &cobra.Command{
+	Use:   "restore-archive <file> [directory]",
+	Short: "Import a repository from an archive or bundle",
+	Long: `Recreate a repository from a file written by 'githelper archive'.
+
+All branches and tags are restored as local branches and tags, origin is
+pointed back at the archived repository's remote, and LFS objects are put in
+place. GitHub metadata is extracted to .git/githelper/metadata/ for reference.
+
+Example:
+  githelper restore-archive repo-20240102-150405.tar.gz
+  githelper restore-archive backup.bundle my-repo
+  githelper restore-archive repo.tar.gz --bare   # Mirror, ready for 'git push --mirror'`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runRestoreArchive,
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+	rootCmd.AddCommand(restoreArchiveCmd)
+	archiveCmd.Flags().StringVarP(&archiveOutput, "output", "o", "", "file to write (default is <repo>-<timestamp>.tar.gz or .bundle)")
+	archiveCmd.Flags().StringVar(&archiveFormat, "format", "", "archive format: tar or bundle (default from --output, else tar)")
+	archiveCmd.Flags().BoolVar(&archiveLFS, "lfs", false, "include Git LFS objects (tar only)")
+	archiveCmd.Flags().BoolVar(&archiveMetadata, "metadata", false, "include issues, pull requests and other GitHub metadata (tar only)")
+	restoreArchiveCmd.Flags().BoolVar(&restoreBare, "bare", false, "restore as a bare mirror repository")
+}
+
+func runArchive(cmd *cobra.Command, args []string) error {
+	format := archiveFormat
+	if format == "" {
+		format = "tar"
+		if strings.HasSuffix(archiveOutput, ".bundle") {
+			format = "bundle"
+		}
+	}
+	if format != "tar" && format != "bundle" {
+		return fmt.Errorf("invalid format '%s'. Use tar or bundle", format)
+	}
+	if format == "bundle" && (archiveLFS || archiveMetadata) {
+		return fmt.Errorf("--lfs and --metadata need the tar format")
+	}
+
+	// Export the current repository, or a mirror clone of the given one
+	var source, remote string
+	if len(args) > 0 {
+		url, err := expandRepoURL(args[0], false)
+		if err != nil {
+			return err
+		}
+		tmpDir, err := makeTempDir("", "githelper-archive-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		ui.Stepf("📥 Cloning %s...", url)
+		source = filepath.Join(tmpDir, "repo.git")
+		cloneCmd := gitCommand("clone", "--mirror", "--quiet", url, source)
+		cloneCmd.Stdout = os.Stdout
+		cloneCmd.Stderr = os.Stderr
+		if err := cloneCmd.Run(); err != nil {
+			return fmt.Errorf("failed to clone repository: %w", err)
+		}
+		remote = url
+	} else {
+		if err := checkGitRepo(); err != nil {
+			return err
+		}
+		root, err := getRepoRoot()
+		if err != nil {
+			return err
+		}
+		source = root
+		remote, _ = getOriginURL()
+	}
+
+	name := archiveName(source, remote)
+	output := archiveOutput
+	if output == "" {
+		ext := ".tar.gz"
+		if format == "bundle" {
+			ext = ".bundle"
+		}
+		output = fmt.Sprintf("%s-%s%s", name, time.Now().Format("20060102-150405"), ext)
+	}
+	output, err := filepath.Abs(output)
+	if err != nil {
+		return err
+	}
+
+	if format == "bundle" {
+		ui.Step("📦 Bundling history...")
+		if err := createBundle(source, output); err != nil {
+			return err
+		}
+		ui.Successf("Repository archived to %s", output)
+		return nil
+	}
+
+	staging, err := makeTempDir("", "githelper-archive-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	ui.Step("📦 Bundling history...")
+	if err := createBundle(source, filepath.Join(staging, archive.BundleFile)); err != nil {
+		return err
+	}
+
+	manifest := archive.Manifest{
+		Name:      name,
+		Remote:    remote,
+		Created:   time.Now(),
+		CreatedBy: "githelper " + version.Version,
+	}
+	if head, err := gitCommand("-C", source, "symbolic-ref", "-q", "HEAD").Output(); err == nil {
+		manifest.Head = strings.TrimSpace(string(head))
+	}
+
+	if archiveLFS {
+		ui.Step("📥 Fetching Git LFS objects...")
+		if err := exportLFSObjects(source, filepath.Join(staging, archive.LFSDir)); err != nil {
+			return err
+		}
+		manifest.LFS = true
+	}
+
+	if archiveMetadata {
+		ui.Step("📋 Exporting GitHub metadata...")
+		files, err := exportGitHubMetadata(remote, filepath.Join(staging, archive.MetadataDir))
+		if err != nil {
+			return err
+		}
+		manifest.Metadata = files
+	}
+
+	if err := archive.WriteManifest(staging, manifest); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	ui.Step("🗜️  Compressing archive...")
+	if err := archive.Pack(staging, output); err != nil {
+		return err
+	}
+
+	ui.Successf("Repository archived to %s", output)
+	return nil
+}
+
+// archiveName returns the repository name used for the archive file name
+func archiveName(source, remote string) string {
+	if remote != "" {
+		name := strings.TrimSuffix(strings.TrimSuffix(remote, "/"), ".git")
+		if i := strings.LastIndexAny(name, "/:"); i >= 0 {
+			name = name[i+1:]
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(source), ".git")
+}
+
+func createBundle(source, file string) error {
+	bundleCmd := gitCommand("-C", source, "bundle", "create", "-q", file, "--all")
+	if output, err := bundleCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create bundle: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// gitDir returns the absolute .git directory of the repository at dir
+func gitDir(dir string) (string, error) {
+	output, err := gitCommand("-C", dir, "rev-parse", "--path-format=absolute", "--git-common-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("error.git_dir"), err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// exportLFSObjects downloads every LFS object of the repository and copies
+// them to dir
+func exportLFSObjects(source, dir string) error {
+	if _, err := exec.LookPath("git-lfs"); err != nil {
+		return fmt.Errorf("--lfs needs Git LFS installed (https://git-lfs.com)")
+	}
+
+	fetchCmd := gitCommand("-C", source, "lfs", "fetch", "--all")
+	fetchCmd.Stdout = os.Stdout
+	fetchCmd.Stderr = os.Stderr
+	if err := fetchCmd.Run(); err != nil {
+		return fmt.Errorf("failed to fetch LFS objects: %w", err)
+	}
+
+	repoGitDir, err := gitDir(source)
+	if err != nil {
+		return err
+	}
+	objects := filepath.Join(repoGitDir, "lfs", "objects")
+	if _, err := os.Stat(objects); os.IsNotExist(err) {
+		return nil
+	}
+	if err := archive.CopyDir(objects, filepath.Join(dir, "objects")); err != nil {
+		return fmt.Errorf("failed to copy LFS objects: %w", err)
+	}
+	return nil
+}
+
+// exportGitHubMetadata writes the GitHub metadata of the repository at remote
+// to one JSON file per kind in dir and returns the file names
+func exportGitHubMetadata(remote, dir string) ([]string, error) {
+	hostname, repoPath, err := github.ParseRepoURL(remote)
+	if err != nil {
+		return nil, fmt.Errorf("--metadata needs a GitHub remote: %w", err)
+	}
+	host, err := resolveHost(hostname)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newHostClient(host)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, name, _ := strings.Cut(repoPath, "/")
+	metadata, err := client.ExportMetadata(context.Background(), owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export metadata of %s: %w", repoPath, err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	parts := map[string]interface{}{
+		"repository.json":    metadata.Repository,
+		"issues.json":        metadata.Issues,
+		"pull_requests.json": metadata.PullRequests,
+		"comments.json":      metadata.Comments,
+		"releases.json":      metadata.Releases,
+		"labels.json":        metadata.Labels,
+		"milestones.json":    metadata.Milestones,
+	}
+	files := sortedKeys(parts)
+	for _, file := range files {
+		data, err := json.MarshalIndent(parts[file], "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+
+	ui.Printf("   %d issues, %d pull requests, %d releases\n",
+		len(metadata.Issues), len(metadata.PullRequests), len(metadata.Releases))
+	return files, nil
+}
+
+func runRestoreArchive(cmd *cobra.Command, args []string) error {
+	file, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(file); err != nil {
+		return fmt.Errorf("archive not found: %s", args[0])
+	}
+
+	// Plain bundles are cloned directly; tarballs are unpacked first
+	bundle := file
+	var manifest archive.Manifest
+	var unpacked string
+	if !strings.HasSuffix(file, ".bundle") {
+		unpacked, err = makeTempDir("", "githelper-restore-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(unpacked)
+
+		ui.Step("📂 Unpacking archive...")
+		if err := archive.Unpack(file, unpacked); err != nil {
+			return err
+		}
+		if manifest, err = archive.ReadManifest(unpacked); err != nil {
+			return err
+		}
+		bundle = filepath.Join(unpacked, archive.BundleFile)
+	}
+
+	target := ""
+	if len(args) > 1 {
+		target = args[1]
+	} else if manifest.Name != "" {
+		target = manifest.Name
+	} else {
+		target = strings.TrimSuffix(filepath.Base(file), ".bundle")
+	}
+	if restoreBare && !strings.HasSuffix(target, ".git") {
+		target += ".git"
+	}
+	if entries, err := os.ReadDir(target); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s already exists and is not empty", target)
+	}
+
+	ui.Stepf("📥 Restoring repository to %s...", target)
+	cloneArgs := []string{"clone", "--quiet"}
+	if restoreBare {
+		cloneArgs = append(cloneArgs, "--mirror")
+	}
+	cloneCmd := gitCommand(append(cloneArgs, bundle, target)...)
+	cloneCmd.Stderr = os.Stderr
+	if err := cloneCmd.Run(); err != nil {
+		return fmt.Errorf("failed to restore repository: %w", err)
+	}
+
+	// A regular clone only creates the default branch; bring back the others
+	if !restoreBare {
+		fetchCmd := gitCommand("-C", target, "fetch", "--quiet", "--update-head-ok", bundle,
+			"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*")
+		fetchCmd.Stderr = os.Stderr
+		if err := fetchCmd.Run(); err != nil {
+			return fmt.Errorf("failed to restore branches: %w", err)
+		}
+	}
+
+	// Point origin back at the archived repository instead of the bundle
+	if manifest.Remote != "" {
+		gitCommand("-C", target, "remote", "set-url", "origin", manifest.Remote).Run()
+	} else {
+		gitCommand("-C", target, "remote", "remove", "origin").Run()
+	}
+
+	repoGitDir, err := gitDir(target)
+	if err != nil {
+		return err
+	}
+
+	if manifest.LFS {
+		objects := filepath.Join(unpacked, archive.LFSDir, "objects")
+		if _, err := os.Stat(objects); err == nil {
+			ui.Step("📦 Restoring Git LFS objects...")
+			if err := archive.CopyDir(objects, filepath.Join(repoGitDir, "lfs", "objects")); err != nil {
+				return fmt.Errorf("failed to restore LFS objects: %w", err)
+			}
+			if _, err := exec.LookPath("git-lfs"); err == nil && !restoreBare {
+				gitCommand("-C", target, "lfs", "install", "--local").Run()
+				checkoutCmd := gitCommand("-C", target, "lfs", "checkout")
+				checkoutCmd.Stderr = os.Stderr
+				checkoutCmd.Run()
+			}
+		}
+	}
+
+	if len(manifest.Metadata) > 0 {
+		metadataDir := filepath.Join(repoGitDir, "githelper", "metadata")
+		if err := archive.CopyDir(filepath.Join(unpacked, archive.MetadataDir), metadataDir); err != nil {
+			return fmt.Errorf("failed to restore metadata: %w", err)
+		}
+		ui.Stepf("📋 GitHub metadata saved to %s", metadataDir)
+	}
+
+	ui.Successf("Repository restored to %s", target)
+	if restoreBare {
+		ui.Println("\nTo publish it on a new server:")
+		ui.Printf("git -C %s push --mirror <new-url>\n", target)
+	}
+	return nil
+}
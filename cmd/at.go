@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	atBranch  string
+	atDetach  bool
+	atPrint   bool
+	atHistory bool
+)
+
+var atCmd = &cobra.Command{
+	Use:   "at <date/time>",
+	Short: "Check out the code as it was at a given time",
+	Long: `Find the commit the current branch pointed to at a given time and check it
+out, to see what the code looked like then.
+
+The commit comes from the reflog of the branch, which records where it pointed
+in this clone, including commits that were later rewritten. When the reflog
+doesn't go back that far (it expires after 90 days, and a fresh clone has
+none), the last commit made before that time is used instead; --history
+always does that.
+
+The commit is checked out into a temporary worktree, leaving your working
+directory alone; --detach checks it out in place instead. Dates are anything
+git understands: "last tuesday", "2 weeks ago", "2024-03-01 14:00".
+
+Example:
+  githelper at "last tuesday"
+  githelper at "2024-03-01 14:00" --branch main
+  githelper at yesterday --detach
+  githelper at "1 month ago" --print     # Only print the commit`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAt,
+}
+
+func init() {
+	rootCmd.AddCommand(atCmd)
+	atCmd.Flags().StringVarP(&atBranch, "branch", "b", "", "branch to look at (default: the current branch)")
+	atCmd.Flags().BoolVar(&atDetach, "detach", false, "check out in place as a detached HEAD instead of a temporary worktree")
+	atCmd.Flags().BoolVar(&atPrint, "print", false, "only print the commit")
+	atCmd.Flags().BoolVar(&atHistory, "history", false, "use the last commit before the time instead of the reflog")
+}
+
+func runAt(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	when := args[0]
+	branch := atBranch
+	if branch == "" {
+		var err error
+		if branch, err = getCurrentBranch(); err != nil {
+			return err
+		}
+		if branch == "HEAD" {
+			return fmt.Errorf("HEAD is detached, pass --branch")
+		}
+	}
+
+	commit, fromReflog, err := commitAt(branch, when, atHistory)
+	if err != nil {
+		return err
+	}
+	if atPrint {
+		ui.Println(commit)
+		return nil
+	}
+
+	details, err := gitCommand("log", "-1", "--format=%h %s (%an, %cd)", "--date=format:%Y-%m-%d %H:%M", commit).Output()
+	if err != nil {
+		return fmt.Errorf("failed to read commit: %w", err)
+	}
+	source := "last commit before then"
+	if fromReflog {
+		source = "from the reflog"
+	}
+	ui.Stepf("🕰️  %s at %s, %s:", branch, when, source)
+	ui.Printf("  %s\n", strings.TrimSpace(string(details)))
+
+	if atDetach {
+		if hasChanges, err := hasUncommittedChanges(); err != nil {
+			return err
+		} else if hasChanges {
+			return fmt.Errorf("you have uncommitted changes. Please commit or stash them first, or leave out --detach")
+		}
+		if output, err := gitCommand("switch", "--detach", commit).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to check out %s: %s", shortSHA(commit), strings.TrimSpace(string(output)))
+		}
+		ui.Success("\nHEAD is detached at that commit")
+		ui.Stepf("👉 Go back with 'git switch %s'", branch)
+		ui.Step("💡 Commits made here belong to no branch: save them with 'githelper rescue <branch>'")
+		return nil
+	}
+
+	dir, err := makeTempDir("", "githelper-at-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	if output, err := gitCommand("worktree", "add", "--detach", dir, commit).CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return fmt.Errorf("failed to create worktree: %s", strings.TrimSpace(string(output)))
+	}
+	ui.Successf("\nChecked out into a temporary worktree:\n👉 cd %s", dir)
+	ui.Stepf("🧹 Remove it when done with 'git worktree remove %s'", dir)
+	return nil
+}
+
+// commitAt returns the commit a branch pointed to at a time, from its reflog
+// when it goes back that far, or else the last commit before then on its
+// first-parent history. fromReflog tells which one it is.
+func commitAt(branch, when string, history bool) (commit string, fromReflog bool, err error) {
+	if !history {
+		var stderr bytes.Buffer
+		revCmd := gitCommand("rev-parse", "--verify", branch+"@{"+when+"}")
+		revCmd.Stderr = &stderr
+		output, err := revCmd.Output()
+		// git falls back to the oldest entry, with a warning, when the reflog
+		// doesn't go back far enough
+		if err == nil && !strings.Contains(stderr.String(), "only goes back to") {
+			return strings.TrimSpace(string(output)), true, nil
+		}
+	}
+
+	output, err := gitCommand("rev-list", "-1", "--first-parent", "--before="+when, branch, "--").Output()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to find the commit of %s at %s: %w", branch, when, err)
+	}
+	commit = strings.TrimSpace(string(output))
+	if commit == "" {
+		return "", false, fmt.Errorf("%s has no commits before %s", branch, when)
+	}
+	return commit, false, nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitAt(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	// The reflog is dated with the committer date
+	var commits []string
+	for _, date := range []string{"2024-01-01", "2024-02-01", "2024-03-01"} {
+		require.NoError(t, os.WriteFile("test.txt", []byte(date), 0644))
+		runGit(t, tmpDir, "add", ".")
+		commitCmd := exec.Command("git", "commit", "-m", date)
+		commitCmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE="+date+" 12:00")
+		require.NoError(t, commitCmd.Run())
+		sha, err := exec.Command("git", "rev-parse", "HEAD").Output()
+		require.NoError(t, err)
+		commits = append(commits, strings.TrimSpace(string(sha)))
+	}
+	runGit(t, tmpDir, "branch", "-M", "main")
+
+	commit, fromReflog, err := commitAt("main", "2024-02-15", false)
+	require.NoError(t, err)
+	assert.Equal(t, commits[1], commit)
+	assert.True(t, fromReflog)
+
+	// Rewritten commits only exist in the reflog
+	runGit(t, tmpDir, "reset", "--hard", commits[0])
+	commit, _, err = commitAt("main", "2024-02-15", false)
+	require.NoError(t, err)
+	assert.Equal(t, commits[1], commit)
+	commit, fromReflog, err = commitAt("main", "2024-02-15", true)
+	require.NoError(t, err)
+	assert.Equal(t, commits[0], commit)
+	assert.False(t, fromReflog)
+
+	_, _, err = commitAt("main", "2023-06-01", false)
+	assert.ErrorContains(t, err, "no commits before")
+}
@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/auth"
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	loginWithToken bool
+)
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Log in to GitHub and manage stored tokens",
+	Long: `Authenticate githelper with GitHub and GitHub Enterprise hosts.
+
+'auth login' opens GitHub's device flow: you get a one-time code to enter in
+the browser and githelper receives a token. The token is stored in the OS
+keychain, or in ~/.githelper/credentials.yaml (readable only by you) when no
+keychain is available.
+
+A token set in the config file or GITHELPER_GITHUB_TOKEN takes precedence over
+the stored one.
+
+Example:
+  githelper auth login                          # Log in to github.com
+  githelper auth login --host github.example.com
+  echo "$TOKEN" | githelper auth login --with-token
+  githelper auth status                         # Show logged in hosts
+  githelper auth logout`,
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in with the browser-based device flow",
+	Args:  cobra.NoArgs,
+	RunE:  runAuthLogin,
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the hosts you are logged in to",
+	Args:  cobra.NoArgs,
+	RunE:  runAuthStatus,
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored token for a host",
+	Args:  cobra.NoArgs,
+	RunE:  runAuthLogout,
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authLoginCmd, authStatusCmd, authLogoutCmd)
+	authLoginCmd.Flags().BoolVar(&loginWithToken, "with-token", false, "read a personal access token from stdin instead")
+}
+
+func runAuthLogin(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	host, err := resolveHost("")
+	if err != nil {
+		return err
+	}
+
+	var token string
+	if loginWithToken {
+		token, err = readTokenFromStdin()
+	} else {
+		token, err = deviceFlowLogin(ctx, host.Name)
+	}
+	if err != nil {
+		return err
+	}
+
+	login, source, err := storeVerifiedToken(ctx, host, token)
+	if err != nil {
+		return err
+	}
+
+	ui.Successf("Logged in to %s as %s (token saved to %s)", host.Name, login, source)
+	return nil
+}
+
+// storeVerifiedToken checks that token works on host before storing it,
+// returning the login it belongs to and where it was saved
+func storeVerifiedToken(ctx context.Context, host github.Host, token string) (string, auth.Source, error) {
+	host.Token = token
+	client, err := github.NewHostClient(host)
+	if err != nil {
+		return "", "", err
+	}
+	login, _, err := client.CurrentUser(ctx)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to verify token: %w", err)
+	}
+
+	store, err := auth.NewStore()
+	if err != nil {
+		return "", "", err
+	}
+	source, err := store.Set(host.Name, token)
+	if err != nil {
+		return "", "", err
+	}
+	return login, source, nil
+}
+
+func readTokenFromStdin() (string, error) {
+	reader := bufio.NewReader(os.Stdin)
+	token, err := reader.ReadString('\n')
+	if err != nil && token == "" {
+		return "", fmt.Errorf("failed to read token from stdin: %w", err)
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("no token provided on stdin")
+	}
+	return token, nil
+}
+
+func deviceFlowLogin(ctx context.Context, hostname string) (string, error) {
+	clientID, err := oauthClientID(hostname)
+	if err != nil {
+		return "", err
+	}
+	if !isInteractive() {
+		return "", fmt.Errorf("device flow login needs a terminal. Use --with-token instead")
+	}
+
+	flow := auth.NewDeviceFlow(hostname, clientID)
+	code, err := flow.RequestCode(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	ui.Stepf("🔑 First copy your one-time code: %s", code.UserCode)
+	ui.Stepf("🌐 Then open %s in your browser and enter it", code.VerificationURI)
+	ui.Step("⏳ Waiting for authorization...")
+
+	return flow.WaitForToken(ctx, code)
+}
+
+// oauthClientID returns the OAuth app used for the device flow on a host.
+// Enterprise hosts need their own app registered in hosts.<name>.client_id.
+func oauthClientID(hostname string) (string, error) {
+	settings, err := hostConfig(hostname)
+	if err != nil {
+		return "", err
+	}
+	if settings.ClientID != "" {
+		return settings.ClientID, nil
+	}
+	if hostname == github.DefaultHost {
+		if clientID := viper.GetString("oauth_client_id"); clientID != "" {
+			return clientID, nil
+		}
+		if auth.ClientID != "" {
+			return auth.ClientID, nil
+		}
+	}
+	return "", fmt.Errorf("no OAuth app configured for %s. Set hosts.%s.client_id (or oauth_client_id for github.com), or use --with-token", hostname, hostname)
+}
+
+func runAuthStatus(cmd *cobra.Command, args []string) error {
+	hosts, err := knownHosts()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	loggedIn := 0
+	for _, name := range hosts {
+		credential, err := hostCredential(name)
+		if errors.Is(err, auth.ErrNoToken) {
+			ui.Printf("%s\n  ❌ Not logged in\n", name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		host, err := resolveHost(name)
+		if err != nil {
+			return err
+		}
+		client, err := github.NewHostClient(host)
+		if err != nil {
+			return err
+		}
+
+		login, scopes, err := client.CurrentUser(ctx)
+		if err != nil {
+			ui.Printf("%s\n  ⚠️  Token from %s is not valid: %v\n", name, credential.Source, err)
+			continue
+		}
+		loggedIn++
+		ui.Printf("%s\n  ✅ Logged in as %s (token from %s)\n", name, login, credential.Source)
+		if len(scopes) > 0 {
+			ui.Printf("  Scopes: %s\n", strings.Join(scopes, ", "))
+		}
+	}
+
+	if loggedIn == 0 {
+		return fmt.Errorf("not logged in to any host. Run 'githelper auth login'")
+	}
+	return nil
+}
+
+// knownHosts lists the default host, the configured hosts and the hosts
+// with a stored token
+func knownHosts() ([]string, error) {
+	seen := map[string]bool{defaultHostName(): true}
+
+	configured, err := configuredHosts()
+	if err != nil {
+		return nil, err
+	}
+	for name := range configured {
+		seen[name] = true
+	}
+
+	store, err := auth.NewStore()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range store.Hosts() {
+		seen[name] = true
+	}
+
+	hosts := make([]string, 0, len(seen))
+	for name := range seen {
+		hosts = append(hosts, name)
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
+
+func runAuthLogout(cmd *cobra.Command, args []string) error {
+	name := defaultHostName()
+	store, err := auth.NewStore()
+	if err != nil {
+		return err
+	}
+
+	if err := store.Delete(name); err != nil {
+		if errors.Is(err, auth.ErrNoToken) {
+			return fmt.Errorf("not logged in to %s", name)
+		}
+		return err
+	}
+
+	ui.Successf("Logged out of %s", name)
+	if credential, err := hostCredential(name); err == nil {
+		ui.Infof("A token from the %s is still used for %s", credential.Source, name)
+	}
+	return nil
+}
@@ -0,0 +1,411 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	noBackup       bool
+	listAllBackups bool
+)
+
+// defaultBackupKeep is how many bundles are kept per repository unless
+// backup.keep says otherwise
+const defaultBackupKeep = 10
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Manage backup bundles of repositories",
+	Long: `Before clean and purge rewrite history, githelper saves every ref of the
+repository to a git bundle in ~/.githelper/backups/<repo>-<timestamp>.bundle.
+A bundle is a complete copy of the history that survives even if the
+repository is deleted, so a bad rewrite can always be undone.
+
+Configure backups in .githelper.yaml:
+
+  backup:
+    enabled: true     # set to false to skip automatic bundles
+    keep: 10          # bundles kept per repository
+    dir: ~/backups    # default is ~/.githelper/backups
+
+Example:
+  githelper backup create                 # Back up the current repository now
+  githelper backup list                   # Bundles of the current repository
+  githelper backup restore                # Pick a bundle (the newest without a terminal)
+  githelper backup restore repo-20240102-150405.bundle`,
+}
+
+var backupCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a backup bundle of the current repository",
+	Args:  cobra.NoArgs,
+	RunE:  runBackupCreate,
+}
+
+var backupListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the backup bundles of the current repository",
+	Args:  cobra.NoArgs,
+	RunE:  runBackupList,
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore [bundle]",
+	Short: "Restore branches and tags from a backup bundle",
+	Long: `Restore branches and tags from a backup bundle.
+
+Every branch and tag in the bundle is reset to its backed-up commit; branches
+created since the backup are left alone. The current state is recorded first,
+so 'githelper rollback' undoes the restore.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runBackupRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(backupCreateCmd)
+	backupCmd.AddCommand(backupListCmd)
+	backupCmd.AddCommand(backupRestoreCmd)
+	backupListCmd.Flags().BoolVarP(&listAllBackups, "all", "a", false, "list the bundles of all repositories")
+	backupRestoreCmd.Flags().BoolVar(&noFzf, "no-fzf", false, "disable fzf usage even if available")
+	backupRestoreCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the refs in the bundle without restoring them")
+
+	cleanCmd.Flags().BoolVar(&noBackup, "no-backup", false, "don't create a backup bundle before rewriting history")
+	purgeCmd.Flags().BoolVar(&noBackup, "no-backup", false, "don't create a backup bundle before rewriting history")
+}
+
+// backupDir returns the directory backup bundles are written to
+func backupDir() (string, error) {
+	if dir := viper.GetString("backup.dir"); dir != "" {
+		if rest, ok := strings.CutPrefix(dir, "~"); ok {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			dir = filepath.Join(home, rest)
+		}
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".githelper", "backups"), nil
+}
+
+// backupBeforeRewrite bundles the repository before an operation rewrites
+// history, unless disabled with --no-backup or backup.enabled: false
+func backupBeforeRewrite() error {
+	if noBackup || (viper.IsSet("backup.enabled") && !viper.GetBool("backup.enabled")) {
+		return nil
+	}
+
+	bundle, err := createBackupBundle()
+	if err != nil {
+		return fmt.Errorf("%w (skip the backup with --no-backup)", err)
+	}
+	ui.Stepf("💾 Saved a backup to %s (restore with 'githelper backup restore')", bundle)
+	return nil
+}
+
+func runBackupCreate(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	ui.Step("💾 Creating backup bundle...")
+	bundle, err := createBackupBundle()
+	if err != nil {
+		return err
+	}
+	ui.Successf("Backup saved to %s", bundle)
+	return nil
+}
+
+// createBackupBundle writes every ref of the current repository to a new
+// bundle and removes the oldest bundles beyond backup.keep
+func createBackupBundle() (string, error) {
+	dir, err := backupDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	name := repoName()
+	if name == "" {
+		name = "repo"
+	}
+	bundle := filepath.Join(dir, fmt.Sprintf("%s-%s.bundle", name, time.Now().Format("20060102-150405")))
+
+	if err := createBundle(".", bundle); err != nil {
+		os.Remove(bundle)
+		return "", err
+	}
+
+	keep := defaultBackupKeep
+	if viper.IsSet("backup.keep") {
+		keep = viper.GetInt("backup.keep")
+	}
+	if keep > 0 {
+		backups, err := listBackups(name)
+		if err == nil && len(backups) > keep {
+			for _, old := range backups[keep:] {
+				os.Remove(old.Path)
+			}
+		}
+	}
+	return bundle, nil
+}
+
+type Backup struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// listBackups returns the bundles of the repository name (or all bundles
+// when name is empty), newest first
+func listBackups(name string) ([]Backup, error) {
+	dir, err := backupDir()
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := "*.bundle"
+	if name != "" {
+		pattern = name + "-*.bundle"
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		// A repo named "app" must not pick up the bundles of "app-server"
+		if name != "" && !isBackupOf(filepath.Base(match), name) {
+			continue
+		}
+		backups = append(backups, Backup{Path: match, Size: info.Size(), ModTime: info.ModTime()})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Path > backups[j].Path })
+	return backups, nil
+}
+
+// isBackupOf reports whether file is <name>-<yyyymmdd>-<hhmmss>.bundle
+func isBackupOf(file, name string) bool {
+	stamp, ok := strings.CutPrefix(strings.TrimSuffix(file, ".bundle"), name+"-")
+	if !ok {
+		return false
+	}
+	_, err := time.Parse("20060102-150405", stamp)
+	return err == nil
+}
+
+func runBackupList(cmd *cobra.Command, args []string) error {
+	name := ""
+	if !listAllBackups {
+		if err := checkGitRepo(); err != nil {
+			return err
+		}
+		name = repoName()
+	}
+
+	backups, err := listBackups(name)
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		ui.Println("No backups found")
+		return nil
+	}
+
+	for _, backup := range backups {
+		ui.Printf("%-45s %10s  %s\n", filepath.Base(backup.Path), formatSize(backup.Size),
+			backup.ModTime.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+func runBackupRestore(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	var bundle string
+	if len(args) > 0 {
+		bundle = args[0]
+		if _, err := os.Stat(bundle); err != nil {
+			dir, dirErr := backupDir()
+			if dirErr != nil {
+				return dirErr
+			}
+			bundle = filepath.Join(dir, args[0])
+		}
+	} else {
+		backups, err := listBackups(repoName())
+		if err != nil {
+			return err
+		}
+		if len(backups) == 0 {
+			return fmt.Errorf("no backups found for %s. Pass the path of a bundle", repoName())
+		}
+		if !isInteractive() {
+			// Without prompts, restore the most recent backup
+			bundle = backups[0].Path
+		} else {
+			selected, err := selectBackup(backups)
+			if err != nil {
+				return err
+			}
+			if selected == nil {
+				ui.Error("Operation cancelled")
+				return nil
+			}
+			bundle = selected.Path
+		}
+	}
+
+	if output, err := gitCommand("bundle", "verify", "-q", bundle).CombinedOutput(); err != nil {
+		return fmt.Errorf("invalid bundle %s: %s", bundle, strings.TrimSpace(string(output)))
+	}
+
+	output, err := gitCommand("bundle", "list-heads", bundle).Output()
+	if err != nil {
+		return fmt.Errorf("failed to read bundle: %w", err)
+	}
+	var refs []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if _, ref, ok := strings.Cut(line, " "); ok &&
+			(strings.HasPrefix(ref, "refs/heads/") || strings.HasPrefix(ref, "refs/tags/")) {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == 0 {
+		return fmt.Errorf("bundle %s contains no branches or tags", bundle)
+	}
+
+	ui.Stepf("📦 %s contains:", filepath.Base(bundle))
+	for _, ref := range refs {
+		ui.Printf("  %s\n", strings.TrimPrefix(ref, "refs/"))
+	}
+
+	if dryRun {
+		ui.Step("\n🔍 Dry run - nothing was restored")
+		return nil
+	}
+
+	status, err := gitCommand("status", "--porcelain", "--untracked-files=no").Output()
+	if err != nil {
+		return fmt.Errorf(i18n.T("error.git_status"), err)
+	}
+	if len(status) > 0 {
+		return fmt.Errorf("you have uncommitted changes. Commit or stash them before restoring")
+	}
+
+	ui.Warn("\nWARNING: This resets the branches and tags above to their backed-up commits!")
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	if err := recordOperation("backup-restore", true); err != nil {
+		return err
+	}
+
+	ui.Step("\n⏪ Restoring refs from backup...")
+	fetchCmd := gitCommand("fetch", "--quiet", "--force", "--update-head-ok", bundle,
+		"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*")
+	fetchCmd.Stderr = os.Stderr
+	if err := fetchCmd.Run(); err != nil {
+		return fmt.Errorf("failed to restore from bundle: %w", err)
+	}
+
+	// The checked out branch may have moved; update the working tree to match
+	resetCmd := gitCommand("reset", "--hard", "--quiet")
+	resetCmd.Stderr = os.Stderr
+	if err := resetCmd.Run(); err != nil {
+		return fmt.Errorf("failed to update working tree: %w", err)
+	}
+
+	ui.Success("Restored from backup!")
+	ui.Println("\nTo update the remote, force push the restored branches:")
+	ui.Println("git push origin --force --all && git push origin --force --tags")
+	return nil
+}
+
+func selectBackup(backups []Backup) (*Backup, error) {
+	if !noFzf && isInteractive() {
+		if _, err := exec.LookPath("fzf"); err == nil {
+			return selectBackupWithFzf(backups)
+		}
+	}
+	return selectBackupWithList(backups)
+}
+
+func selectBackupWithFzf(backups []Backup) (*Backup, error) {
+	var input strings.Builder
+	for i, backup := range backups {
+		fmt.Fprintf(&input, "%d\t%s\t%s\t%s\n", i, filepath.Base(backup.Path), formatSize(backup.Size), backup.Path)
+	}
+
+	fzfCmd := exec.Command("fzf",
+		"--height", "50%",
+		"--reverse",
+		"--delimiter", "\t",
+		"--with-nth", "2,3",
+		"--preview", "git bundle list-heads {4}",
+		"--preview-window", "right:50%")
+
+	fzfCmd.Stdin = strings.NewReader(input.String())
+	fzfCmd.Stderr = os.Stderr
+
+	output, err := fzfCmd.Output()
+	if err != nil {
+		return nil, nil // User cancelled
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &index); err != nil || index < 0 || index >= len(backups) {
+		return nil, fmt.Errorf("invalid selection")
+	}
+	return &backups[index], nil
+}
+
+func selectBackupWithList(backups []Backup) (*Backup, error) {
+	ui.Println("\nBackups:")
+	for i, backup := range backups {
+		ui.Printf("%2d: %s (%s)\n", i+1, filepath.Base(backup.Path), formatSize(backup.Size))
+	}
+
+	input := readInput("\nSelect backup number (or press Enter to cancel): ")
+
+	if input == "" {
+		return nil, nil
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(input, "%d", &index); err != nil || index < 1 || index > len(backups) {
+		return nil, fmt.Errorf("invalid selection")
+	}
+	return &backups[index-1], nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBackupOf(t *testing.T) {
+	assert.True(t, isBackupOf("app-20240102-150405.bundle", "app"))
+	assert.False(t, isBackupOf("app-server-20240102-150405.bundle", "app"))
+	assert.True(t, isBackupOf("app-server-20240102-150405.bundle", "app-server"))
+	assert.False(t, isBackupOf("app-latest.bundle", "app"))
+}
+
+func TestCreateBackupBundle(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "initial")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	backups := t.TempDir()
+	defer viper.Reset()
+	viper.Set("backup.dir", backups)
+	viper.Set("backup.keep", 1)
+
+	// Older bundles beyond backup.keep are removed
+	old := filepath.Join(backups, repoName()+"-20000101-000000.bundle")
+	require.NoError(t, os.WriteFile(old, nil, 0600))
+
+	bundle, err := createBackupBundle()
+	require.NoError(t, err)
+	assert.FileExists(t, bundle)
+	assert.NoFileExists(t, old)
+
+	output, err := exec.Command("git", "bundle", "list-heads", bundle).Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "refs/heads/")
+
+	listed, err := listBackups(repoName())
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, bundle, listed[0].Path)
+}
@@ -6,6 +6,8 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/hooks"
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -32,15 +34,32 @@ Example workflow:
      - If the bug is gone: run 'git bisect good'
   4. Git will eventually find the exact commit that introduced the bug
 
+  $ githelper bisect --good v1.2.0 --run "go test ./parser/..." --notify
+
+With --run, a command tests each commit instead of you: it exits 0 when the
+commit is good, 125 when it can't be tested and another code when it's bad.
+githelper prints the first bad commit and ends the bisect. Add --notify to be
+told when it's found. --good and --bad give the commits to start from
+instead of selecting them.
+
 Tips:
   - You can use 'git bisect reset' to abort the process
   - Write a test script to automate the verification
-  - Use 'git bisect run ./test.sh' to automate the entire process`,
+  - Use 'githelper bisect --run ./test.sh' to automate the entire process`,
 	RunE: runBisect,
 }
 
+var (
+	bisectGood string
+	bisectBad  string
+	bisectRun  string
+)
+
 func init() {
 	rootCmd.AddCommand(bisectCmd)
+	bisectCmd.Flags().StringVar(&bisectGood, "good", "", "known good commit, instead of selecting it")
+	bisectCmd.Flags().StringVar(&bisectBad, "bad", "", "known bad commit, instead of selecting it (default HEAD with --good)")
+	bisectCmd.Flags().StringVar(&bisectRun, "run", "", "shell command telling good commits (exit 0) from bad ones, to find the first bad commit automatically")
 }
 
 func runBisect(cmd *cobra.Command, args []string) error {
@@ -49,59 +68,95 @@ func runBisect(cmd *cobra.Command, args []string) error {
 	}
 
 	// Start bisect
-	fmt.Println("🔎 Starting Git Bisect...")
-	if err := exec.Command("git", "bisect", "start").Run(); err != nil {
+	ui.Step("🔎 Starting Git Bisect...")
+	if err := gitCommand("bisect", "start").Run(); err != nil {
 		return fmt.Errorf("failed to start git bisect: %w", err)
 	}
 
 	// Get good commit
-	fmt.Println("\n📌 Select a known GOOD commit (where everything worked):")
-	goodCommit, err := selectCommitForBisect()
-	if err != nil {
-		return fmt.Errorf("failed to select good commit: %w", err)
-	}
+	goodCommit := bisectGood
 	if goodCommit == "" {
-		return fmt.Errorf("no good commit selected")
+		ui.Step("\n📌 Select a known GOOD commit (where everything worked):")
+		var err error
+		if goodCommit, err = selectCommitForBisect(); err != nil {
+			return fmt.Errorf("failed to select good commit: %w", err)
+		}
+		if goodCommit == "" {
+			return fmt.Errorf("no good commit selected")
+		}
 	}
 
-	// Get bad commit
-	fmt.Println("\n📌 Select a known BAD commit (where the bug exists):")
-	badCommit, err := selectCommitForBisect()
-	if err != nil {
-		return fmt.Errorf("failed to select bad commit: %w", err)
+	// Get bad commit, HEAD when the good one was given
+	badCommit := bisectBad
+	if badCommit == "" && bisectGood != "" {
+		badCommit = "HEAD"
 	}
 	if badCommit == "" {
-		return fmt.Errorf("no bad commit selected")
+		ui.Step("\n📌 Select a known BAD commit (where the bug exists):")
+		var err error
+		if badCommit, err = selectCommitForBisect(); err != nil {
+			return fmt.Errorf("failed to select bad commit: %w", err)
+		}
+		if badCommit == "" {
+			return fmt.Errorf("no bad commit selected")
+		}
 	}
 
 	// Mark good and bad commits
-	if err := exec.Command("git", "bisect", "good", goodCommit).Run(); err != nil {
+	if err := gitCommand("bisect", "good", goodCommit).Run(); err != nil {
 		return fmt.Errorf("failed to mark good commit: %w", err)
 	}
-	if err := exec.Command("git", "bisect", "bad", badCommit).Run(); err != nil {
+	if err := gitCommand("bisect", "bad", badCommit).Run(); err != nil {
 		return fmt.Errorf("failed to mark bad commit: %w", err)
 	}
+	if bisectRun != "" {
+		return runBisectCommand(bisectRun)
+	}
 
 	// Print instructions
-	fmt.Println("\n🛠️  Git bisect is now running!")
-	fmt.Println("\nInstructions:")
-	fmt.Println("1. Git will checkout different commits for you to test")
-	fmt.Println("2. Test if the bug exists in each commit")
-	fmt.Println("3. Mark each commit using:")
-	fmt.Println("   - git bisect good  (if the bug is NOT present)")
-	fmt.Println("   - git bisect bad   (if the bug IS present)")
-	fmt.Println("\nAutomation tip:")
-	fmt.Println("If you have a test script, you can automate the process:")
-	fmt.Println("git bisect run ./test.sh")
-	fmt.Println("\nTo abort the bisect process:")
-	fmt.Println("git bisect reset")
+	ui.Step("\n🛠️  Git bisect is now running!")
+	ui.Println("\nInstructions:")
+	ui.Println("1. Git will checkout different commits for you to test")
+	ui.Println("2. Test if the bug exists in each commit")
+	ui.Println("3. Mark each commit using:")
+	ui.Println("   - git bisect good  (if the bug is NOT present)")
+	ui.Println("   - git bisect bad   (if the bug IS present)")
+	ui.Println("\nAutomation tip:")
+	ui.Println("If you have a test script, you can automate the process:")
+	ui.Println("git bisect run ./test.sh")
+	ui.Println("\nTo abort the bisect process:")
+	ui.Println("git bisect reset")
+
+	return nil
+}
 
+// runBisectCommand lets git bisect run a shell command on each commit, then
+// reports the first bad commit and ends the bisect
+func runBisectCommand(line string) error {
+	ui.Stepf("\n🤖 Running '%s' on each commit...", line)
+	shell := hooks.Command(commandCtx, line)
+	run := gitCommand(append([]string{"bisect", "run"}, shell.Args...)...)
+	run.Stdout, run.Stderr = ui.Status(), ui.Status()
+	if err := run.Run(); err != nil {
+		return fmt.Errorf("git bisect run failed: %w. Run 'git bisect reset' to stop bisecting", err)
+	}
+
+	culprit, err := gitOutput("log", "-1", "--format=%h %s", "refs/bisect/bad")
+	if err != nil {
+		return fmt.Errorf("failed to find the first bad commit: %w", err)
+	}
+	if err := gitCommand("bisect", "reset").Run(); err != nil {
+		ui.Warnf("Failed to end the bisect, run 'git bisect reset': %v", err)
+	}
+	found := strings.TrimSpace(string(culprit))
+	ui.Successf("First bad commit: %s", found)
+	notifyResult("First bad commit: " + found)
 	return nil
 }
 
 func selectCommitForBisect() (string, error) {
 	// Try using fzf if available
-	if !noFzf {
+	if !noFzf && isInteractive() {
 		if _, err := exec.LookPath("fzf"); err == nil {
 			return selectCommitWithFzfForBisect()
 		}
@@ -111,7 +166,7 @@ func selectCommitForBisect() (string, error) {
 
 func selectCommitWithFzfForBisect() (string, error) {
 	// Get git log
-	logCmd := exec.Command("git", "log", "--oneline", "--color=always")
+	logCmd := gitCommand("log", "--oneline", "--color=always")
 	logOutput, err := logCmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get git log: %w", err)
@@ -144,7 +199,7 @@ func selectCommitWithFzfForBisect() (string, error) {
 
 func selectCommitWithListForBisect() (string, error) {
 	// Get recent commits
-	logCmd := exec.Command("git", "log", "--oneline", "-n", "20")
+	logCmd := gitCommand("log", "--oneline", "-n", "20")
 	output, err := logCmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get git log: %w", err)
@@ -152,15 +207,13 @@ func selectCommitWithListForBisect() (string, error) {
 
 	// Display commits
 	commits := strings.Split(strings.TrimSpace(string(output)), "\n")
-	fmt.Println("\nRecent commits:")
+	ui.Println("\nRecent commits:")
 	for i, commit := range commits {
-		fmt.Printf("%2d: %s\n", i+1, commit)
+		ui.Printf("%2d: %s\n", i+1, commit)
 	}
 
 	// Get user selection
-	fmt.Print("\nSelect commit number (or press Enter to cancel): ")
-	var input string
-	fmt.Scanln(&input)
+	input := readInput("\nSelect commit number (or press Enter to cancel): ")
 
 	if input == "" {
 		return "", nil
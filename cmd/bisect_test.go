@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBisectRun(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("fakes notify-send")
+	}
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	good := r.Commit("Add parser", "parser.txt", "v1\n")
+	r.Commit("Tune parser", "parser.txt", "v2\n")
+	bad := r.Commit("Break parser", "bug.txt", "oops\n")
+	r.Commit("Document parser", "README.md", "docs\n")
+
+	// A fake notify-send records the notification
+	bin := t.TempDir()
+	notified := filepath.Join(bin, "notified.txt")
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "notify-send"), []byte("#!/bin/sh\nprintf '%s\\n' \"$@\" > "+notified+"\n"), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// execute resets --notify before the command is finished, so the
+	// config asks for the notification instead
+	defer viper.Reset()
+	viper.Set("notify.desktop", true)
+	stdout, _, err := execute(t, "bisect", "--good", good, "--run", "test ! -f bug.txt")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "First bad commit: "+bad[:7]+" Break parser")
+	assert.Equal(t, "main", r.Branch())
+	assert.NoFileExists(t, r.Path(".git/BISECT_LOG"))
+
+	finishNotify(0, nil)
+	data, err := os.ReadFile(notified)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "githelper bisect run succeeded\n")
+	assert.Contains(t, string(data), "First bad commit: "+bad[:7]+" Break parser")
+}
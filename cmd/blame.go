@@ -1,23 +1,40 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"os/exec"
 	"strconv"
+	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+var (
+	lineSpec   string
+	showPatch  bool
+	blameStats bool
+)
+
 var blameCmd = &cobra.Command{
-	Use:   "blame <file> <line>",
+	Use:   "blame <file> [line|start-end|start,+count|/regex/|:function]",
 	Short: "Find line author across all commits",
-	Long: `Track changes to a specific line across all commits.
+	Long: `Track changes to specific lines across all commits.
 
 This command helps you:
-1. Show all changes to a specific line
+1. Show all changes to a line, a range of lines or a function
 2. See who modified it and why
-3. Track the line's evolution
+3. Track the code's evolution
+
+When no line is given, you pick one from a preview of the file.
+
+With --stats, it instead shows per-author ownership and an age heatmap for a
+file, or ownership aggregated across every file in a directory.
+
+Commits listed in .git-blame-ignore-revs (see 'githelper blame ignore-rev')
+are left out of both.
 
 Useful when:
 - Investigating code history
@@ -25,13 +42,34 @@ Useful when:
 - Understanding why code changed
 
 Example:
-  githelper blame main.go 42    # Show history of line 42 in main.go`,
-	Args: cobra.ExactArgs(2),
+  githelper blame main.go 42            # Show history of line 42 in main.go
+  githelper blame main.go 42-60         # Show history of lines 42 to 60
+  githelper blame main.go 42,+5         # Show history of 5 lines from 42
+  githelper blame main.go /^func/       # Track the first line matching a regex
+  githelper blame main.go -L :main      # Track the main function
+  githelper blame main.go               # Pick the line interactively
+  githelper blame main.go 42 --patch    # Include the diffs
+  githelper blame --stats main.go       # Ownership and age heatmap
+  githelper blame --stats internal/     # Ownership across a directory
+  githelper blame ignore-rev 1a2b3c4    # Hide a reformat commit from blame`,
+	Args: cobra.RangeArgs(1, 2),
 	RunE: runBlame,
 }
 
 func init() {
 	rootCmd.AddCommand(blameCmd)
+	blameCmd.Flags().StringVarP(&lineSpec, "lines", "L", "", "line, range (42-60, 42,+5), regex (/pattern/) or function (:FuncName) to track")
+	blameCmd.Flags().BoolVarP(&showPatch, "patch", "p", false, "show the diff of each change")
+	blameCmd.Flags().BoolVar(&blameStats, "stats", false, "show ownership and age statistics for a file or directory")
+}
+
+// LineChange is a commit that touched the tracked lines
+type LineChange struct {
+	Commit  string
+	Hash    string
+	Author  string
+	Date    string
+	Subject string
 }
 
 func runBlame(cmd *cobra.Command, args []string) error {
@@ -40,9 +78,9 @@ func runBlame(cmd *cobra.Command, args []string) error {
 	}
 
 	file := args[0]
-	line, err := strconv.Atoi(args[1])
-	if err != nil {
-		return fmt.Errorf("invalid line number: %s", args[1])
+
+	if blameStats {
+		return runBlameStats(file)
 	}
 
 	// Check if file exists
@@ -50,14 +88,214 @@ func runBlame(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("file not found: %s", file)
 	}
 
-	// Show line history
-	fmt.Printf("📜 History for %s line %d:\n\n", file, line)
-	logCmd := exec.Command("git", "log", "-L", fmt.Sprintf("%d,%d:%s", line, line, file))
-	logCmd.Stdout = os.Stdout
-	logCmd.Stderr = os.Stderr
-	if err := logCmd.Run(); err != nil {
-		return fmt.Errorf("failed to get line history: %w", err)
+	spec := lineSpec
+	if len(args) > 1 {
+		spec = args[1]
+	}
+	if spec == "" {
+		line, err := selectLine(file)
+		if err != nil {
+			return err
+		}
+		if line == 0 {
+			return fmt.Errorf("no line selected")
+		}
+		spec = strconv.Itoa(line)
+	}
+
+	rangeArg, description, err := parseLineSpec(spec, file)
+	if err != nil {
+		return err
+	}
+
+	ui.Printf("📜 History for %s %s:\n\n", file, description)
+
+	if showPatch {
+		logCmd := gitCommand("log", "-L", rangeArg)
+		logCmd.Stdout = os.Stdout
+		logCmd.Stderr = os.Stderr
+		if err := logCmd.Run(); err != nil {
+			return fmt.Errorf("failed to get line history: %w", err)
+		}
+		return nil
+	}
+
+	changes, err := getLineHistory(rangeArg)
+	if err != nil {
+		return err
 	}
 
+	ignored := ignoredRevs()
+	hidden := 0
+	ui.Printf("%-10s %-20s %-10s %s\n", "COMMIT", "AUTHOR", "DATE", "MESSAGE")
+	for _, change := range changes {
+		if ignored[change.Commit] {
+			hidden++
+			continue
+		}
+		ui.Printf("%-10s %-20s %-10s %s\n", change.Hash, truncate(change.Author, 20), change.Date, change.Subject)
+	}
+	ui.Printf("\n%d commit(s) changed these lines. Use --patch to see the diffs.\n", len(changes)-hidden)
+	if hidden > 0 {
+		ui.Printf("%d formatting commit(s) listed in %s are hidden.\n", hidden, blameIgnoreFile)
+	}
 	return nil
-} 
\ No newline at end of file
+}
+
+// parseLineSpec turns "42", "42-60", "42,60", "42,+5", "/regex/" or
+// ":FuncName" into a git log -L argument
+func parseLineSpec(spec, file string) (string, string, error) {
+	if strings.HasPrefix(spec, ":") {
+		if len(spec) == 1 {
+			return "", "", fmt.Errorf("missing function name in %q", spec)
+		}
+		return fmt.Sprintf("%s:%s", spec, file), fmt.Sprintf("function %s", spec[1:]), nil
+	}
+	if strings.HasPrefix(spec, "/") {
+		return parseRegexLineSpec(spec, file)
+	}
+
+	start, end, found := strings.Cut(spec, "-")
+	if !found {
+		start, end, found = strings.Cut(spec, ",")
+	}
+	if !found {
+		end = start
+	}
+
+	from, err := strconv.Atoi(strings.TrimSpace(start))
+	if err != nil || from < 1 {
+		return "", "", fmt.Errorf("invalid line number: %s", spec)
+	}
+	end = strings.TrimSpace(end)
+	var to int
+	if count, ok := strings.CutPrefix(end, "+"); ok {
+		// N,+M is M lines starting at N, as in git
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 1 {
+			return "", "", fmt.Errorf("invalid line range: %s", spec)
+		}
+		to = from + n - 1
+	} else if to, err = strconv.Atoi(end); err != nil || to < from {
+		return "", "", fmt.Errorf("invalid line range: %s", spec)
+	}
+
+	description := fmt.Sprintf("line %d", from)
+	if to != from {
+		description = fmt.Sprintf("lines %d-%d", from, to)
+	}
+	return fmt.Sprintf("%d,%d:%s", from, to, file), description, nil
+}
+
+// parseRegexLineSpec handles "/regex/", the first line matching regex, and
+// "/regex/,end" where end is anything git accepts: a line number, +count or
+// another /regex/
+func parseRegexLineSpec(spec, file string) (string, string, error) {
+	closing := strings.Index(spec[1:], "/")
+	if closing < 1 {
+		return "", "", fmt.Errorf("invalid line pattern: %s", spec)
+	}
+	pattern, rest := spec[:closing+2], spec[closing+2:]
+	if rest == "" {
+		return fmt.Sprintf("%s,+1:%s", pattern, file), fmt.Sprintf("line matching %s", pattern), nil
+	}
+	end, ok := strings.CutPrefix(rest, ",")
+	if !ok || end == "" {
+		return "", "", fmt.Errorf("invalid line range: %s", spec)
+	}
+	return fmt.Sprintf("%s:%s", spec, file), fmt.Sprintf("lines %s to %s", pattern, end), nil
+}
+
+func getLineHistory(rangeArg string) ([]LineChange, error) {
+	cmd := gitCommand("log", "-L", rangeArg, "--no-patch", "--date=short",
+		"--format=%H%x1f%h%x1f%an%x1f%ad%x1f%s")
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to get line history: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to get line history: %w", err)
+	}
+
+	var changes []LineChange
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		changes = append(changes, LineChange{
+			Commit:  fields[0],
+			Hash:    fields[1],
+			Author:  fields[2],
+			Date:    fields[3],
+			Subject: fields[4],
+		})
+	}
+	return changes, nil
+}
+
+func selectLine(file string) (int, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+
+	if !noFzf && isInteractive() {
+		if _, err := exec.LookPath("fzf"); err == nil {
+			return selectLineWithFzf(file, lines)
+		}
+	}
+	return selectLineWithList(lines)
+}
+
+func selectLineWithFzf(file string, lines []string) (int, error) {
+	var input strings.Builder
+	for i, line := range lines {
+		fmt.Fprintf(&input, "%d\t%s\n", i+1, line)
+	}
+
+	fzfCmd := exec.Command("fzf",
+		"--height", "50%",
+		"--reverse",
+		"--delimiter", "\t",
+		"--preview", blameLinePreview(file),
+		"--preview-window", "down:40%")
+
+	fzfCmd.Stdin = strings.NewReader(input.String())
+	fzfCmd.Stderr = os.Stderr
+
+	output, err := fzfCmd.Output()
+	if err != nil {
+		return 0, nil // User cancelled
+	}
+
+	number, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\t")
+	return strconv.Atoi(number)
+}
+
+// blameLinePreview is the fzf preview listing the commits that changed the
+// highlighted line
+func blameLinePreview(file string) string {
+	return fmt.Sprintf("git log -L {1},{1}:%s --no-patch --oneline", shellQuote(file))
+}
+
+func selectLineWithList(lines []string) (int, error) {
+	ui.Println()
+	for i, line := range lines {
+		ui.Printf("%4d: %s\n", i+1, line)
+	}
+
+	input := readInput("\nSelect line number (or press Enter to cancel): ")
+
+	if input == "" {
+		return 0, nil
+	}
+
+	index, err := strconv.Atoi(input)
+	if err != nil || index < 1 || index > len(lines) {
+		return 0, fmt.Errorf("invalid selection")
+	}
+	return index, nil
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// blameIgnoreFile is the conventional name of the file listing the commits
+// blame skips, which GitHub also honors
+const blameIgnoreFile = ".git-blame-ignore-revs"
+
+var (
+	ignoreRevReason string
+	ignoreRevList   bool
+	ignoreRevRemove bool
+)
+
+var blameIgnoreRevCmd = &cobra.Command{
+	Use:   "ignore-rev [commit]...",
+	Short: "Hide formatting commits from blame",
+	Long: `Add commits that only reformat code (running a formatter, changing
+indentation, renaming on a large scale) to .git-blame-ignore-revs, so blame
+attributes their lines to the commits before them.
+
+The file is created at the repository root and blame.ignoreRevsFile is set
+so 'git blame' uses it too; GitHub reads it as well once it is committed.
+'githelper blame' hides the listed commits from line histories, and
+'blame --stats' and 'owners suggest' skip them even when the setting is
+missing, e.g. in a fresh clone.
+
+Example:
+  githelper blame ignore-rev 1a2b3c4                 # Ignore a reformat commit
+  githelper blame ignore-rev HEAD -m "gofmt -s"      # With a reason
+  githelper blame ignore-rev --list
+  githelper blame ignore-rev --remove 1a2b3c4`,
+	RunE: runBlameIgnoreRev,
+}
+
+func init() {
+	blameCmd.AddCommand(blameIgnoreRevCmd)
+	blameIgnoreRevCmd.Flags().StringVarP(&ignoreRevReason, "message", "m", "", "comment written above the commits (default: their subject)")
+	blameIgnoreRevCmd.Flags().BoolVarP(&ignoreRevList, "list", "l", false, "list the ignored commits")
+	blameIgnoreRevCmd.Flags().BoolVar(&ignoreRevRemove, "remove", false, "stop ignoring the commits")
+}
+
+func runBlameIgnoreRev(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	root, err := getRepoRoot()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(root, blameIgnoreFile)
+
+	if ignoreRevList {
+		return listIgnoredRevs(path)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("give the commits to ignore, or --list")
+	}
+
+	var shas []string
+	for _, arg := range args {
+		output, err := gitCommand("rev-parse", "--verify", "-q", arg+"^{commit}").Output()
+		if err != nil {
+			return fmt.Errorf("'%s' is not a commit", arg)
+		}
+		shas = append(shas, strings.TrimSpace(string(output)))
+	}
+
+	if ignoreRevRemove {
+		removed, err := removeIgnoredRevs(path, shas)
+		if err != nil {
+			return err
+		}
+		ui.Successf("Removed %d commit(s) from %s", removed, blameIgnoreFile)
+		return nil
+	}
+
+	added, err := addIgnoredRevs(path, shas, ignoreRevReason)
+	if err != nil {
+		return err
+	}
+	for _, sha := range shas {
+		if added[sha] {
+			ui.Stepf("🙈 Ignoring %s %s", shortSHA(sha), commitSubject(sha))
+		} else {
+			ui.Infof("%s is already ignored", shortSHA(sha))
+		}
+	}
+
+	configured, _ := gitCommand("config", "blame.ignoreRevsFile").Output()
+	if strings.TrimSpace(string(configured)) == "" {
+		if err := gitCommand("config", "blame.ignoreRevsFile", blameIgnoreFile).Run(); err != nil {
+			return fmt.Errorf("failed to set blame.ignoreRevsFile: %w", err)
+		}
+		ui.Stepf("⚙️  Set blame.ignoreRevsFile to %s", blameIgnoreFile)
+	}
+	if len(added) > 0 {
+		ui.Stepf("💡 Commit %s to share it with others", blameIgnoreFile)
+	}
+	return nil
+}
+
+func listIgnoredRevs(path string) error {
+	revs, err := readIgnoredRevs(path)
+	if err != nil {
+		return err
+	}
+	if len(revs) == 0 {
+		ui.Printf("No commits in %s\n", blameIgnoreFile)
+		return nil
+	}
+	for _, sha := range revs {
+		ui.Printf("%s %s\n", shortSHA(sha), commitSubject(sha))
+	}
+	return nil
+}
+
+// readIgnoredRevs returns the commits listed in an ignore-revs file, which
+// has one commit per line and # comments. A missing file lists nothing.
+func readIgnoredRevs(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	defer file.Close()
+
+	var revs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			revs = append(revs, line)
+		}
+	}
+	return revs, scanner.Err()
+}
+
+// addIgnoredRevs appends the commits that aren't listed yet, under a comment
+// with the reason or each commit's subject, and returns those it added
+func addIgnoredRevs(path string, shas []string, reason string) (map[string]bool, error) {
+	existing, err := readIgnoredRevs(path)
+	if err != nil {
+		return nil, err
+	}
+	listed := map[string]bool{}
+	for _, sha := range existing {
+		listed[sha] = true
+	}
+
+	var entry strings.Builder
+	added := map[string]bool{}
+	if reason != "" {
+		fmt.Fprintf(&entry, "# %s\n", reason)
+	}
+	for _, sha := range shas {
+		if listed[sha] || added[sha] {
+			continue
+		}
+		if reason == "" {
+			fmt.Fprintf(&entry, "# %s\n", commitSubject(sha))
+		}
+		fmt.Fprintf(&entry, "%s\n", sha)
+		added[sha] = true
+	}
+	if len(added) == 0 {
+		return added, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", blameIgnoreFile, err)
+	}
+	if len(content) > 0 {
+		if content[len(content)-1] != '\n' {
+			content = append(content, '\n')
+		}
+		content = append(content, '\n')
+	}
+	content = append(content, entry.String()...)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", blameIgnoreFile, err)
+	}
+	return added, nil
+}
+
+// removeIgnoredRevs deletes the lines listing the commits and returns how
+// many it removed. Comments are kept.
+func removeIgnoredRevs(path string, shas []string) (int, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read %s: %w", blameIgnoreFile, err)
+	}
+	remove := map[string]bool{}
+	for _, sha := range shas {
+		remove[sha] = true
+	}
+
+	var kept []string
+	removed := 0
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		rev, _, _ := strings.Cut(line, "#")
+		if remove[strings.TrimSpace(rev)] {
+			removed++
+			continue
+		}
+		kept = append(kept, line)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(kept, "")), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", blameIgnoreFile, err)
+	}
+	return removed, nil
+}
+
+// ignoredRevsFile returns the ignore-revs file blame should use: the one set
+// in blame.ignoreRevsFile, or .git-blame-ignore-revs at the repository root.
+// Empty when there is none.
+func ignoredRevsFile() string {
+	root, err := getRepoRoot()
+	if err != nil {
+		return ""
+	}
+	path := blameIgnoreFile
+	if output, err := gitCommand("config", "blame.ignoreRevsFile").Output(); err == nil {
+		if configured := strings.TrimSpace(string(output)); configured != "" {
+			path = configured
+		}
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// blameIgnoreArgs returns the git blame arguments that skip the commits of
+// .git-blame-ignore-revs when blame.ignoreRevsFile isn't set
+func blameIgnoreArgs() []string {
+	if output, _ := gitCommand("config", "blame.ignoreRevsFile").Output(); len(output) > 0 {
+		return nil // git blame reads it itself
+	}
+	if path := ignoredRevsFile(); path != "" {
+		return []string{"--ignore-revs-file", path}
+	}
+	return nil
+}
+
+// ignoredRevs returns the set of commits blame skips
+func ignoredRevs() map[string]bool {
+	revs := map[string]bool{}
+	if path := ignoredRevsFile(); path != "" {
+		list, _ := readIgnoredRevs(path)
+		for _, sha := range list {
+			revs[sha] = true
+		}
+	}
+	return revs
+}
+
+func commitSubject(sha string) string {
+	output, _ := gitCommand("log", "-1", "--format=%s", sha).Output()
+	return strings.TrimSpace(string(output))
+}
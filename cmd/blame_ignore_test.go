@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddAndRemoveIgnoredRevs(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "Reformat everything")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	output, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	require.NoError(t, err)
+	sha := strings.TrimSpace(string(output))
+	path := filepath.Join(tmpDir, blameIgnoreFile)
+
+	added, err := addIgnoredRevs(path, []string{sha}, "")
+	require.NoError(t, err)
+	assert.True(t, added[sha])
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# Reformat everything\n"+sha+"\n", string(content))
+
+	// Adding it again changes nothing
+	added, err = addIgnoredRevs(path, []string{sha}, "gofmt")
+	require.NoError(t, err)
+	assert.Empty(t, added)
+
+	revs, err := readIgnoredRevs(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{sha}, revs)
+	assert.True(t, ignoredRevs()[sha])
+	assert.Equal(t, []string{"--ignore-revs-file", path}, blameIgnoreArgs())
+
+	removed, err := removeIgnoredRevs(path, []string{sha})
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	revs, err = readIgnoredRevs(path)
+	require.NoError(t, err)
+	assert.Empty(t, revs)
+}
+
+func TestBlameStatsSkipIgnoredRevs(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "initial")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.WriteFile("test.txt", []byte("  test content\n"), 0644))
+	runGit(t, tmpDir, "commit", "-am", "Reindent", "--author", "Formatter <fmt@example.com>")
+	output, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	require.NoError(t, err)
+
+	stats, err := collectBlameStats([]string{"test.txt"})
+	require.NoError(t, err)
+	assert.Contains(t, stats.Authors, "fmt@example.com")
+
+	_, err = addIgnoredRevs(filepath.Join(tmpDir, blameIgnoreFile), []string{strings.TrimSpace(string(output))}, "")
+	require.NoError(t, err)
+	stats, err = collectBlameStats([]string{"test.txt"})
+	require.NoError(t, err)
+	assert.NotContains(t, stats.Authors, "fmt@example.com")
+	assert.Contains(t, stats.Authors, "test@example.com")
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+)
+
+// ageBuckets are the heatmap columns, from newest to oldest
+var ageBuckets = []struct {
+	Label  string
+	MaxAge time.Duration
+}{
+	{"< 1 month", 30 * 24 * time.Hour},
+	{"1-6 months", 182 * 24 * time.Hour},
+	{"6-12 months", 365 * 24 * time.Hour},
+	{"1-2 years", 2 * 365 * 24 * time.Hour},
+	{"> 2 years", 0},
+}
+
+// heatShades render line age in the file strip, newest first
+var heatShades = []rune{'█', '▓', '▒', '░', '·'}
+
+// BlameLine is the author information git blame reports for a single line
+type BlameLine struct {
+	Author string
+	Email  string
+	Time   time.Time
+}
+
+// AuthorStats is the ownership of an author over a set of files
+type AuthorStats struct {
+	Name  string
+	Email string
+	Lines int
+	Files map[string]int
+}
+
+// BlameStats aggregates blame information over one or more files
+type BlameStats struct {
+	TotalLines int
+	Authors    map[string]*AuthorStats
+	AgeBuckets []int
+	Files      map[string][]BlameLine
+}
+
+func runBlameStats(path string) error {
+	files, err := listBlameFiles(path)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no tracked files found in %s", path)
+	}
+
+	ui.Printf("🔍 Collecting blame information for %d file(s)...\n\n", len(files))
+	stats, err := collectBlameStats(files)
+	if err != nil {
+		return err
+	}
+	if stats.TotalLines == 0 {
+		return fmt.Errorf("no blame information found for %s", path)
+	}
+
+	ui.Printf("👥 Ownership of %s (%d lines):\n\n", path, stats.TotalLines)
+	for _, author := range stats.SortedAuthors() {
+		percent := percentOf(author.Lines, stats.TotalLines)
+		ui.Printf("  %-25s %6d lines %5.1f%% %s\n",
+			truncate(author.Name, 25), author.Lines, percent, bar(percent, 30))
+	}
+
+	ui.Step("\n🔥 Age of lines:")
+	ui.Println()
+	for i, bucket := range ageBuckets {
+		percent := percentOf(stats.AgeBuckets[i], stats.TotalLines)
+		ui.Printf("  %c %-12s %6d lines %5.1f%% %s\n",
+			heatShades[i], bucket.Label, stats.AgeBuckets[i], percent, bar(percent, 30))
+	}
+
+	if len(files) == 1 {
+		ui.Step("\n🗺️  Heatmap (top to bottom of the file):")
+		ui.Println()
+		ui.Printf("  %s\n", heatStrip(stats.Files[files[0]], 60))
+	} else {
+		ui.Step("\n📁 Top files by author:")
+		ui.Println()
+		for _, author := range stats.SortedAuthors() {
+			ui.Printf("  %s: %s\n", author.Name, strings.Join(author.TopFiles(3), ", "))
+		}
+	}
+	return nil
+}
+
+// listBlameFiles returns the tracked files under path (or path itself)
+func listBlameFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("path not found: %s", path)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	output, err := gitCommand("ls-files", "--", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// collectBlameStats runs git blame over the files in parallel and aggregates
+// per-author ownership and line age
+func collectBlameStats(files []string) (*BlameStats, error) {
+	stats := &BlameStats{
+		Authors:    make(map[string]*AuthorStats),
+		AgeBuckets: make([]int, len(ageBuckets)),
+		Files:      make(map[string][]BlameLine),
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		jobs = make(chan string)
+	)
+	now := time.Now()
+	ignoreArgs := blameIgnoreArgs()
+
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				lines, err := blameFile(file, ignoreArgs...)
+				if err != nil {
+					continue // binary, deleted or otherwise unblameable
+				}
+				mu.Lock()
+				stats.add(file, lines, now)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, file := range files {
+		jobs <- file
+	}
+	close(jobs)
+	wg.Wait()
+
+	return stats, nil
+}
+
+func (s *BlameStats) add(file string, lines []BlameLine, now time.Time) {
+	s.Files[file] = lines
+	for _, line := range lines {
+		s.TotalLines++
+		author, ok := s.Authors[line.Email]
+		if !ok {
+			author = &AuthorStats{Name: line.Author, Email: line.Email, Files: make(map[string]int)}
+			s.Authors[line.Email] = author
+		}
+		author.Lines++
+		author.Files[file]++
+		s.AgeBuckets[ageBucket(now.Sub(line.Time))]++
+	}
+}
+
+// SortedAuthors returns authors ordered by the number of lines they own
+func (s *BlameStats) SortedAuthors() []*AuthorStats {
+	authors := make([]*AuthorStats, 0, len(s.Authors))
+	for _, author := range s.Authors {
+		authors = append(authors, author)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Lines != authors[j].Lines {
+			return authors[i].Lines > authors[j].Lines
+		}
+		return authors[i].Name < authors[j].Name
+	})
+	return authors
+}
+
+// TopFiles returns the files where the author owns the most lines
+func (a *AuthorStats) TopFiles(n int) []string {
+	return topKeys(a.Files, n)
+}
+
+func blameFile(file string, extraArgs ...string) ([]BlameLine, error) {
+	args := append([]string{"blame", "--line-porcelain"}, extraArgs...)
+	cmd := gitCommand(append(args, "--", file)...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseBlamePorcelain(string(output))
+}
+
+// parseBlamePorcelain reads the author of each line from git blame
+// --line-porcelain output
+func parseBlamePorcelain(output string) ([]BlameLine, error) {
+	var lines []BlameLine
+	var current BlameLine
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "author "):
+			current.Author = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "author-mail "):
+			current.Email = strings.Trim(strings.TrimPrefix(line, "author-mail "), "<>")
+		case strings.HasPrefix(line, "author-time "):
+			seconds, _ := strconv.ParseInt(strings.TrimPrefix(line, "author-time "), 10, 64)
+			current.Time = time.Unix(seconds, 0)
+		case strings.HasPrefix(line, "\t"):
+			// The content line ends each record
+			lines = append(lines, current)
+			current = BlameLine{}
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func ageBucket(age time.Duration) int {
+	for i, bucket := range ageBuckets {
+		if bucket.MaxAge == 0 || age < bucket.MaxAge {
+			return i
+		}
+	}
+	return len(ageBuckets) - 1
+}
+
+// heatStrip compresses the file into width cells, each shaded by the age of
+// the newest line it covers
+func heatStrip(lines []BlameLine, width int) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	if len(lines) < width {
+		width = len(lines)
+	}
+
+	now := time.Now()
+	var strip strings.Builder
+	for cell := 0; cell < width; cell++ {
+		from := cell * len(lines) / width
+		to := (cell + 1) * len(lines) / width
+		newest := len(ageBuckets) - 1
+		for _, line := range lines[from:to] {
+			if bucket := ageBucket(now.Sub(line.Time)); bucket < newest {
+				newest = bucket
+			}
+		}
+		strip.WriteRune(heatShades[newest])
+	}
+	return strip.String()
+}
+
+// percentOf is n as a percentage of total, 0 when there is nothing to count
+func percentOf(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
+
+func bar(percent float64, width int) string {
+	filled := int(percent / 100 * float64(width))
+	return strings.Repeat("■", filled)
+}
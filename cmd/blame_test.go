@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLineSpec(t *testing.T) {
+	tests := []struct {
+		spec        string
+		rangeArg    string
+		description string
+		wantErr     string
+	}{
+		{spec: "42", rangeArg: "42,42:main.go", description: "line 42"},
+		{spec: "42-60", rangeArg: "42,60:main.go", description: "lines 42-60"},
+		{spec: "42,60", rangeArg: "42,60:main.go", description: "lines 42-60"},
+		{spec: "42,+5", rangeArg: "42,46:main.go", description: "lines 42-46"},
+		{spec: "42,+1", rangeArg: "42,42:main.go", description: "line 42"},
+		{spec: ":main", rangeArg: ":main:main.go", description: "function main"},
+		{spec: "/^func main/", rangeArg: "/^func main/,+1:main.go", description: "line matching /^func main/"},
+		{spec: "/^func main/,+3", rangeArg: "/^func main/,+3:main.go", description: "lines /^func main/ to +3"},
+		{spec: "/^func/,/^}/", rangeArg: "/^func/,/^}/:main.go", description: "lines /^func/ to /^}/"},
+		{spec: ":", wantErr: "missing function name"},
+		{spec: "0", wantErr: "invalid line number"},
+		{spec: "abc", wantErr: "invalid line number"},
+		{spec: "60-42", wantErr: "invalid line range"},
+		{spec: "42,+0", wantErr: "invalid line range"},
+		{spec: "42,+x", wantErr: "invalid line range"},
+		{spec: "//", wantErr: "invalid line pattern"},
+		{spec: "/main", wantErr: "invalid line pattern"},
+		{spec: "/main/x", wantErr: "invalid line range"},
+		{spec: "/main/,", wantErr: "invalid line range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			rangeArg, description, err := parseLineSpec(tt.spec, "main.go")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.rangeArg, rangeArg)
+			assert.Equal(t, tt.description, description)
+		})
+	}
+}
+
+func TestBlameLinePreview(t *testing.T) {
+	assert.Equal(t, "git log -L {1},{1}:'main.go' --no-patch --oneline", blameLinePreview("main.go"))
+	assert.Equal(t, `git log -L {1},{1}:'it'\''s here; rm -rf x' --no-patch --oneline`, blameLinePreview("it's here; rm -rf x"))
+}
+
+func TestBlameLineSpecs(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Add main", "main.go", "package main\n\nfunc main() {\n}\n")
+	r.Commit("Print hello", "main.go", "package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n")
+
+	stdout, _, err := execute(t, "blame", "main.go", "/println/")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Print hello")
+	assert.NotContains(t, stdout, "Add main")
+
+	stdout, _, err = execute(t, "blame", "main.go", "1,+2")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "lines 1-2")
+	assert.Contains(t, stdout, "Add main")
+	assert.NotContains(t, stdout, "Print hello")
+}
+
+func TestParseBlamePorcelain(t *testing.T) {
+	record := func(sha, author, email string, at int64, content string) string {
+		return fmt.Sprintf("%s 1 1 1\nauthor %s\nauthor-mail <%s>\nauthor-time %d\nauthor-tz +0000\nsummary Change\nfilename main.go\n\t%s\n",
+			sha, author, email, at, content)
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		expect []BlameLine
+	}{
+		{name: "empty", output: "", expect: nil},
+		{
+			name:   "one record per line",
+			output: record("aaaa", "Alice", "alice@example.com", 1700000000, "package main") + record("bbbb", "Bob Smith", "bob@example.com", 1600000000, "func main() {}"),
+			expect: []BlameLine{
+				{Author: "Alice", Email: "alice@example.com", Time: time.Unix(1700000000, 0)},
+				{Author: "Bob Smith", Email: "bob@example.com", Time: time.Unix(1600000000, 0)},
+			},
+		},
+		{
+			name:   "content that looks like a header",
+			output: record("aaaa", "Alice", "alice@example.com", 1700000000, "author Mallory"),
+			expect: []BlameLine{{Author: "Alice", Email: "alice@example.com", Time: time.Unix(1700000000, 0)}},
+		},
+		{
+			name:   "uncommitted lines",
+			output: record("0000", "Not Committed Yet", "not.committed.yet", 1700000000, "x"),
+			expect: []BlameLine{{Author: "Not Committed Yet", Email: "not.committed.yet", Time: time.Unix(1700000000, 0)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines, err := parseBlamePorcelain(tt.output)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, lines)
+		})
+	}
+}
+
+func TestPercentOf(t *testing.T) {
+	tests := []struct {
+		n, total int
+		expect   float64
+	}{
+		{0, 0, 0},
+		{0, 10, 0},
+		{1, 4, 25},
+		{1, 3, 100.0 / 3},
+		{10, 10, 100},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d of %d", tt.n, tt.total), func(t *testing.T) {
+			assert.InDelta(t, tt.expect, percentOf(tt.n, tt.total), 1e-9)
+		})
+	}
+}
+
+func TestBlameStatsAdd(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	alice := BlameLine{Author: "Alice", Email: "alice@example.com", Time: now.AddDate(0, 0, -1)}
+	bob := BlameLine{Author: "Bob", Email: "bob@example.com", Time: now.AddDate(-3, 0, 0)}
+
+	stats := &BlameStats{
+		Authors:    make(map[string]*AuthorStats),
+		AgeBuckets: make([]int, len(ageBuckets)),
+		Files:      make(map[string][]BlameLine),
+	}
+	stats.add("main.go", []BlameLine{alice, alice, bob}, now)
+	stats.add("util.go", []BlameLine{bob, bob, bob}, now)
+
+	assert.Equal(t, 6, stats.TotalLines)
+	assert.Equal(t, []int{2, 0, 0, 0, 4}, stats.AgeBuckets)
+	authors := stats.SortedAuthors()
+	require.Len(t, authors, 2)
+	assert.Equal(t, "Bob", authors[0].Name)
+	assert.Equal(t, 4, authors[0].Lines)
+	assert.Equal(t, []string{"util.go", "main.go"}, authors[0].TopFiles(3))
+	assert.InDelta(t, 100.0/3, percentOf(authors[1].Lines, stats.TotalLines), 1e-9)
+	assert.Equal(t, "■■■■■■■■■■", bar(percentOf(authors[1].Lines, stats.TotalLines), 30))
+}
+
+func TestAgeBucket(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		age    time.Duration
+		expect string
+	}{
+		{0, "< 1 month"},
+		{29 * day, "< 1 month"},
+		{30 * day, "1-6 months"},
+		{200 * day, "6-12 months"},
+		{400 * day, "1-2 years"},
+		{3 * 365 * day, "> 2 years"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expect, func(t *testing.T) {
+			assert.Equal(t, tt.expect, ageBuckets[ageBucket(tt.age)].Label)
+		})
+	}
+}
+
+func TestBlameStats(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Add main", "src/main.go", "package main\n\nfunc main() {}\n")
+	r.WriteFile("src/util.go", "package main\n")
+	r.Git("add", "src/util.go")
+	r.Git("commit", "--quiet", "--author", "Bob <bob@example.com>", "-m", "Add util")
+
+	stdout, _, err := execute(t, "blame", "--stats", "src")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Ownership of src (4 lines)")
+	assert.Regexp(t, `Test\s+3 lines  75\.0%`, stdout)
+	assert.Regexp(t, `Bob\s+1 lines  25\.0%`, stdout)
+	assert.Contains(t, stdout, "Bob: src/util.go")
+}
@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// BranchPolicy is the branch_policy section of .githelper.yaml
+type BranchPolicy struct {
+	// Template names branches from {type}, {ticket} and {slug}
+	Template string
+	Types    []string
+	// RequireTicket makes {ticket} mandatory; otherwise it may be left out
+	// along with the separator after it
+	RequireTicket bool
+	// Pattern, when set, is the regexp names must match instead of one
+	// derived from Template
+	Pattern string
+	// Exempt are the branch patterns the policy doesn't apply to
+	Exempt []string
+}
+
+// defaultBranchPolicy applies to the settings branch_policy doesn't set
+var defaultBranchPolicy = BranchPolicy{
+	Template: "{type}/{ticket}-{slug}",
+	Types:    []string{"feat", "fix", "chore", "docs", "refactor", "test", "perf", "ci"},
+	Exempt:   []string{"main", "master", "develop"},
+}
+
+var (
+	branchNewType   string
+	branchNewTicket string
+	branchNewBase   string
+	branchNewPrint  bool
+)
+
+var branchCmd = &cobra.Command{
+	Use:   "branch",
+	Short: "Name branches after the branch policy",
+	Long: `Create branches whose names follow the branch policy of the repository, and
+check the names of existing ones.
+
+The policy is read from branch_policy in .githelper.yaml:
+
+  branch_policy:
+    template: "{type}/{ticket}-{slug}"   # default
+    types: [feat, fix, chore]            # allowed {type}s
+    require_ticket: true                 # {ticket} can't be left out
+    exempt: [main, develop, "release/*"] # branches the policy ignores
+
+{ticket} is an issue number or an ID matching commit_template.ticket_pattern
+(e.g. PROJ-123) and {slug} the description in lowercase words joined by
+dashes. Protected branches (safety.protected_branches) are always exempt.
+Set pattern to a regexp to check names against it instead of the template.
+
+'githelper push' refuses to publish new branches that break the policy when
+branch_policy is configured.
+
+Example:
+  githelper branch new "Fix login redirect" --type fix --ticket PROJ-42
+  githelper branch new "fix: login redirect"      # fix/login-redirect
+  githelper branch new "Add search" --print       # Only print the name
+  githelper branch check                          # Check the current branch`,
+}
+
+var branchNewCmd = &cobra.Command{
+	Use:   "new <description>",
+	Short: "Create a branch named after the policy and switch to it",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runBranchNew,
+}
+
+var branchCheckCmd = &cobra.Command{
+	Use:   "check [branch]",
+	Short: "Check that a branch name follows the policy",
+	Long: `Check that a branch name follows the branch policy, the current branch by
+default, and exit with an error when it doesn't. Use it in a pre-push hook:
+
+  githelper branch check || exit 1`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runBranchCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(branchCmd)
+	branchCmd.AddCommand(branchNewCmd, branchCheckCmd)
+	branchNewCmd.Flags().StringVarP(&branchNewType, "type", "t", "", "type of change (default: from a 'type:' prefix of the description, or feat)")
+	branchNewCmd.Flags().StringVar(&branchNewTicket, "ticket", "", "ticket or issue number (default: found in the description)")
+	branchNewCmd.Flags().StringVar(&branchNewBase, "base", "", "start point of the branch (default: HEAD)")
+	branchNewCmd.Flags().BoolVar(&branchNewPrint, "print", false, "only print the branch name")
+}
+
+// loadBranchPolicy reads branch_policy from the configuration, falling back
+// to the defaults for unset values
+func loadBranchPolicy() BranchPolicy {
+	policy := defaultBranchPolicy
+	if template := viper.GetString("branch_policy.template"); template != "" {
+		policy.Template = template
+	}
+	if viper.IsSet("branch_policy.types") {
+		policy.Types = viper.GetStringSlice("branch_policy.types")
+	}
+	if viper.IsSet("branch_policy.exempt") {
+		policy.Exempt = viper.GetStringSlice("branch_policy.exempt")
+	}
+	policy.RequireTicket = viper.GetBool("branch_policy.require_ticket")
+	policy.Pattern = viper.GetString("branch_policy.pattern")
+	return policy
+}
+
+// branchPlaceholder matches a placeholder of the template along with the
+// separator after it
+var branchPlaceholder = regexp.MustCompile(`\{(\w+)\}([-_/.]?)`)
+
+// branchSlug turns a description into lowercase words joined by dashes,
+// e.g. "Fix login redirect!" into fix-login-redirect
+func branchSlug(description string) string {
+	slug := generateBranchName(description)
+	// generateBranchName makes names start with a letter, which the rest of
+	// the name makes unnecessary
+	if rest, ok := strings.CutPrefix(slug, "branch-"); ok && (rest == "" || rest[0] >= '0' && rest[0] <= '9') {
+		slug = rest
+	}
+	for strings.Contains(slug, "--") {
+		slug = strings.ReplaceAll(slug, "--", "-")
+	}
+	return strings.Trim(slug, "-")
+}
+
+// Name renders the template. An empty value drops its placeholder along
+// with the separator after it.
+func (p BranchPolicy) Name(kind, ticket, description string) string {
+	values := map[string]string{"type": kind, "ticket": ticket, "slug": branchSlug(description)}
+	name := branchPlaceholder.ReplaceAllStringFunc(p.Template, func(match string) string {
+		parts := branchPlaceholder.FindStringSubmatch(match)
+		value, ok := values[parts[1]]
+		if !ok {
+			return match
+		}
+		if value == "" {
+			return ""
+		}
+		return value + parts[2]
+	})
+	return strings.TrimRight(name, "-_/.")
+}
+
+// regexp returns the regexp names must match
+func (p BranchPolicy) regexp() (*regexp.Regexp, error) {
+	if p.Pattern != "" {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid branch_policy.pattern: %w", err)
+		}
+		return re, nil
+	}
+
+	ticketPattern := viper.GetString("commit_template.ticket_pattern")
+	if ticketPattern == "" {
+		ticketPattern = defaultTicketPattern
+	}
+	kinds := `[a-z]+`
+	if len(p.Types) > 0 {
+		var quoted []string
+		for _, kind := range p.Types {
+			quoted = append(quoted, regexp.QuoteMeta(kind))
+		}
+		kinds = strings.Join(quoted, "|")
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	last := 0
+	for _, loc := range branchPlaceholder.FindAllStringSubmatchIndex(p.Template, -1) {
+		expr.WriteString(regexp.QuoteMeta(p.Template[last:loc[0]]))
+		last = loc[1]
+		separator := regexp.QuoteMeta(p.Template[loc[4]:loc[5]])
+		switch placeholder := p.Template[loc[2]:loc[3]]; placeholder {
+		case "type":
+			expr.WriteString("(?:" + kinds + ")" + separator)
+		case "ticket":
+			ticket := "(?:" + ticketPattern + "|[0-9]+)" + separator
+			if !p.RequireTicket {
+				ticket = "(?:" + ticket + ")?"
+			}
+			expr.WriteString(ticket)
+		case "slug":
+			expr.WriteString(`[a-z0-9]+(?:-[a-z0-9]+)*` + separator)
+		default:
+			return nil, fmt.Errorf("unknown placeholder {%s} in branch_policy.template", placeholder)
+		}
+	}
+	expr.WriteString(regexp.QuoteMeta(p.Template[last:]))
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid branch_policy.template: %w", err)
+	}
+	return re, nil
+}
+
+// Check returns an error explaining how branch breaks the policy, or nil
+func (p BranchPolicy) Check(branch string) error {
+	if isProtectedBranch(branch) {
+		return nil
+	}
+	for _, pattern := range p.Exempt {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return nil
+		}
+	}
+
+	re, err := p.regexp()
+	if err != nil {
+		return err
+	}
+	if re.MatchString(branch) {
+		return nil
+	}
+	if p.Pattern != "" {
+		return fmt.Errorf("branch '%s' doesn't match the branch policy %s", branch, p.Pattern)
+	}
+	example := p.Name(p.exampleType(), "PROJ-123", "add login page")
+	return fmt.Errorf("branch '%s' doesn't follow the branch policy %s, e.g. %s", branch, p.Template, example)
+}
+
+func (p BranchPolicy) exampleType() string {
+	if len(p.Types) > 0 {
+		return p.Types[0]
+	}
+	return "feat"
+}
+
+// newBranchName names a branch after the policy. The type defaults to the
+// conventional commit prefix of the description and the ticket to the one
+// mentioned in it.
+func newBranchName(policy BranchPolicy, description, kind, ticket string) (string, error) {
+	fields := parseConventionalHeader(description)
+	if fields.Type != "" {
+		description = fields.Summary
+		if kind == "" {
+			kind = fields.Type
+		}
+	}
+	if kind == "" {
+		kind = policy.exampleType()
+	}
+	if ticket == "" {
+		var err error
+		ticket, err = extractTicket(description, viper.GetString("commit_template.ticket_pattern"))
+		if err != nil {
+			return "", err
+		}
+	}
+	if ticket != "" {
+		description = strings.Replace(description, ticket, "", 1)
+	}
+	return policy.Name(strings.ToLower(kind), ticket, description), nil
+}
+
+func runBranchNew(cmd *cobra.Command, args []string) error {
+	policy := loadBranchPolicy()
+	branch, err := newBranchName(policy, strings.Join(args, " "), branchNewType, branchNewTicket)
+	if err != nil {
+		return err
+	}
+	if err := policy.Check(branch); err != nil {
+		ui.Warn(err.Error())
+	}
+	if branchNewPrint {
+		ui.Println(branch)
+		copyToClipboard("branch name", branch)
+		return nil
+	}
+
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	if err := gitCommand("check-ref-format", "--branch", branch).Run(); err != nil {
+		return fmt.Errorf("'%s' is not a valid branch name", branch)
+	}
+	if gitCommand("rev-parse", "--verify", "-q", "refs/heads/"+branch).Run() == nil {
+		return fmt.Errorf("branch '%s' already exists. Use 'githelper switch %s'", branch, branch)
+	}
+
+	switchArgs := []string{"switch", "--quiet", "-c", branch}
+	if branchNewBase != "" {
+		switchArgs = append(switchArgs, "--no-track", branchNewBase)
+	}
+	ui.Stepf("🌱 Creating branch '%s'...", branch)
+	if err := gitRun(switchArgs...); err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+	ui.Successf("Switched to new branch '%s'", branch)
+	copyToClipboard("branch name", branch)
+	return nil
+}
+
+func runBranchCheck(cmd *cobra.Command, args []string) error {
+	var branch string
+	if len(args) > 0 {
+		branch = args[0]
+	} else {
+		if err := checkGitRepo(); err != nil {
+			return err
+		}
+		var err error
+		if branch, err = getCurrentBranch(); err != nil {
+			return err
+		}
+	}
+
+	if err := loadBranchPolicy().Check(branch); err != nil {
+		return err
+	}
+	ui.Successf("'%s' follows the branch policy", branch)
+	return nil
+}
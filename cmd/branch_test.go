@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchPolicyName(t *testing.T) {
+	defer viper.Reset()
+	policy := defaultBranchPolicy
+	name := func(description, kind, ticket string) string {
+		branch, err := newBranchName(policy, description, kind, ticket)
+		require.NoError(t, err)
+		return branch
+	}
+	assert.Equal(t, "feat/PROJ-42-add-login-page", name("Add login page", "", "PROJ-42"))
+	assert.Equal(t, "fix/login-redirect", name("fix: Login redirect", "", ""))
+	assert.Equal(t, "fix/ABC-7-login-redirect", name("ABC-7 Login redirect", "Fix", ""))
+	assert.Equal(t, "feat/42", name("!!!", "", "42"))
+
+	policy.Template = "{ticket}_{slug}"
+	assert.Equal(t, "PROJ-1_search", name("Search", "", "PROJ-1"))
+
+	viper.Set("commit_template.ticket_pattern", "[A-Z+")
+	_, err := newBranchName(policy, "Search", "", "")
+	assert.ErrorContains(t, err, "invalid commit_template.ticket_pattern")
+}
+
+func TestBranchPolicyCheck(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("safety.protected_branches", []string{"release/*"})
+	policy := defaultBranchPolicy
+
+	for _, branch := range []string{"feat/PROJ-42-add-login", "fix/login", "docs/42-readme", "main", "release/2.0"} {
+		assert.NoError(t, policy.Check(branch), branch)
+	}
+	for _, branch := range []string{"feature/login", "fix/Login", "fix/", "fix/login-", "my-branch", "feat/PROJ-42"} {
+		assert.Error(t, policy.Check(branch), branch)
+	}
+	assert.ErrorContains(t, policy.Check("wip"), "e.g. feat/PROJ-123-add-login-page")
+
+	policy.RequireTicket = true
+	assert.Error(t, policy.Check("fix/login"))
+	assert.NoError(t, policy.Check("fix/PROJ-1-login"))
+
+	policy.Pattern = `^[a-z]+/`
+	assert.NoError(t, policy.Check("user/anything"))
+	policy.Pattern = ""
+	policy.Template = "{type}/{name}"
+	assert.ErrorContains(t, policy.Check("fix/x"), "unknown placeholder {name}")
+}
+
+func TestBranchNew(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("branch_policy.types", []string{"feature", "bugfix"})
+
+	r := testutil.NewRepo(t)
+	r.Commits("base", 1)
+	r.WithRemote()
+	r.Chdir()
+
+	stdout, _, err := execute(t, "branch", "new", "Add", "search", "--print")
+	require.NoError(t, err)
+	assert.Equal(t, "feature/add-search\n", stdout)
+	assert.False(t, r.Exists("refs/heads/feature/add-search"))
+
+	// --copy pipes the name to clipboard.command
+	viper.Set("clipboard.command", []string{"sh", "-c", "cat > copied.txt"})
+	_, _, err = execute(t, "branch", "new", "Add", "search", "--print", "--copy")
+	require.NoError(t, err)
+	assert.Equal(t, "feature/add-search", r.ReadFile("copied.txt"))
+	require.NoError(t, os.Remove(r.Path("copied.txt")))
+
+	_, _, err = execute(t, "branch", "new", "Crash on start", "-t", "bugfix", "--ticket", "12")
+	require.NoError(t, err)
+	assert.Equal(t, "bugfix/12-crash-on-start", r.Branch())
+	_, _, err = execute(t, "branch", "check")
+	assert.NoError(t, err)
+
+	_, _, err = execute(t, "branch", "check", "fix/crash")
+	assert.ErrorContains(t, err, "doesn't follow the branch policy")
+
+	// push refuses new branches that break the policy
+	r.Checkout("wip")
+	r.Commits("wip", 1)
+	_, _, err = execute(t, "push")
+	assert.ErrorContains(t, err, "branch 'wip' doesn't follow the branch policy")
+	assert.False(t, r.Exists("refs/remotes/origin/wip"))
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checksInterval     time.Duration
+	checksTimeout      time.Duration
+	checksStartTimeout time.Duration
+	checksFailFast     bool
+)
+
+var errChecksTimeout = errors.New("timed out waiting for checks")
+
+var checksCmd = &cobra.Command{
+	Use:   "checks",
+	Short: "Follow the CI checks of a commit",
+	Long: `Follow the CI checks (check runs and commit statuses) GitHub reports for a
+commit.
+
+Example:
+  githelper checks wait                 # Wait for the checks of HEAD
+  githelper checks wait main --timeout 1h`,
+}
+
+var checksWaitCmd = &cobra.Command{
+	Use:   "wait [ref]",
+	Short: "Wait until the checks of a commit finish",
+	Long: `Poll the check runs and commit statuses of a commit until none is pending,
+showing the progress, then list the results. Exits non-zero when a check
+failed, when no check started within --start-timeout or when --timeout ran
+out, so scripts can wait for CI before merging or deploying.
+
+The ref defaults to HEAD and is resolved locally when possible; push the
+commit first so GitHub has checks to report.
+
+Example:
+  githelper checks wait
+  githelper checks wait feature --fail-fast
+  githelper checks wait v1.2.0 --interval 30s --timeout 1h
+  githelper checks wait && githelper pr merge --method squash`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runChecksWait,
+}
+
+func init() {
+	rootCmd.AddCommand(checksCmd)
+	checksCmd.AddCommand(checksWaitCmd)
+	checksWaitCmd.Flags().DurationVar(&checksInterval, "interval", 10*time.Second, "time between polls")
+	checksWaitCmd.Flags().DurationVar(&checksTimeout, "timeout", 30*time.Minute, "give up after this long (0 waits forever)")
+	checksWaitCmd.Flags().DurationVar(&checksStartTimeout, "start-timeout", 2*time.Minute, "fail when no check was reported after this long")
+	checksWaitCmd.Flags().BoolVar(&checksFailFast, "fail-fast", false, "stop as soon as a check fails")
+}
+
+func runChecksWait(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	if checksInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	ref := "HEAD"
+	if len(args) > 0 {
+		ref = args[0]
+	}
+	// Resolve locally so a moving branch doesn't change the commit waited on;
+	// refs only GitHub knows are passed as they are
+	if output, err := gitCommand("rev-parse", "--verify", "-q", ref+"^{commit}").Output(); err == nil {
+		ref = strings.TrimSpace(string(output))
+	}
+
+	client, owner, name, err := originClient()
+	if err != nil {
+		return err
+	}
+	ui.Stepf("⏳ Waiting for the checks of %s in %s/%s", shortSHA(ref), owner, name)
+
+	fetch := func() ([]github.Check, error) {
+		return client.Checks(context.Background(), owner, name, ref)
+	}
+	checks, err := waitForChecks(fetch, ui.Out(), stdoutIsTerminal())
+	if len(checks) > 0 {
+		ui.Println()
+		printChecks(ui.Out(), checks)
+	}
+	if err != nil {
+		return err
+	}
+	ui.Success("\nAll checks passed")
+	notifyResult(fmt.Sprintf("All %d checks of %s passed", len(checks), shortSHA(ref)))
+	return nil
+}
+
+// checksSleep waits between polls; tests replace it
+var checksSleep = time.Sleep
+
+// waitForChecks polls fetch until no check is pending (or one failed, with
+// --fail-fast), printing a progress line whenever the counts change. With
+// live set, the line is redrawn in place. It returns the last checks seen.
+func waitForChecks(fetch func() ([]github.Check, error), w io.Writer, live bool) ([]github.Check, error) {
+	var elapsed time.Duration
+	last := ""
+	for {
+		checks, err := fetch()
+		if err != nil {
+			if live && last != "" {
+				fmt.Fprintln(w)
+			}
+			return nil, fmt.Errorf("failed to get checks: %w", err)
+		}
+		passed, pending, failed := github.CountChecks(checks)
+
+		line := fmt.Sprintf("🧪 %d/%d passed, %d pending, %d failed", passed, len(checks), pending, failed)
+		if len(checks) == 0 {
+			line = "🧪 Waiting for checks to start"
+		}
+		if live {
+			fmt.Fprintf(w, "\r%s (%s)\033[K", line, elapsed.Round(time.Second))
+		} else if line != last {
+			fmt.Fprintln(w, line)
+		}
+		last = line
+
+		done := len(checks) > 0 && pending == 0
+		switch {
+		case failed > 0 && (done || checksFailFast):
+			err = fmt.Errorf("%d check(s) failed", failed)
+		case done:
+		case len(checks) == 0 && checksStartTimeout > 0 && elapsed >= checksStartTimeout:
+			err = fmt.Errorf("no checks reported after %s. Was the commit pushed, and does the repository run CI?", checksStartTimeout)
+		case checksTimeout > 0 && elapsed >= checksTimeout:
+			err = fmt.Errorf("%w after %s: %d still pending", errChecksTimeout, checksTimeout, pending)
+		default:
+			checksSleep(checksInterval)
+			elapsed += checksInterval
+			continue
+		}
+		if live {
+			fmt.Fprintln(w)
+		}
+		return checks, err
+	}
+}
+
+// stdoutIsTerminal reports whether output goes to a terminal, where progress
+// can be redrawn in place
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
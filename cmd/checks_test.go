@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChecks returns each set of checks in turn, repeating the last one
+func fakeChecks(polls ...[]github.Check) func() ([]github.Check, error) {
+	calls := 0
+	return func() ([]github.Check, error) {
+		checks := polls[min(calls, len(polls)-1)]
+		calls++
+		return checks, nil
+	}
+}
+
+func withChecksOptions(t *testing.T, timeout, startTimeout time.Duration, failFast bool) {
+	oldSleep, oldInterval, oldTimeout, oldStart, oldFailFast := checksSleep, checksInterval, checksTimeout, checksStartTimeout, checksFailFast
+	t.Cleanup(func() {
+		checksSleep, checksInterval, checksTimeout, checksStartTimeout, checksFailFast = oldSleep, oldInterval, oldTimeout, oldStart, oldFailFast
+	})
+	checksSleep = func(time.Duration) {}
+	checksInterval = 10 * time.Second
+	checksTimeout, checksStartTimeout, checksFailFast = timeout, startTimeout, failFast
+}
+
+func TestWaitForChecks(t *testing.T) {
+	withChecksOptions(t, time.Hour, time.Minute, false)
+	var out bytes.Buffer
+	checks, err := waitForChecks(fakeChecks(
+		nil,
+		[]github.Check{{Name: "build", State: github.CheckPending}, {Name: "lint", State: github.CheckSuccess}},
+		[]github.Check{{Name: "build", State: github.CheckSuccess}, {Name: "lint", State: github.CheckSuccess}},
+	), &out, false)
+	require.NoError(t, err)
+	assert.Len(t, checks, 2)
+	assert.Equal(t, "🧪 Waiting for checks to start\n🧪 1/2 passed, 1 pending, 0 failed\n🧪 2/2 passed, 0 pending, 0 failed\n", out.String())
+}
+
+func TestWaitForChecksFailure(t *testing.T) {
+	polls := [][]github.Check{
+		{{Name: "build", State: github.CheckPending}, {Name: "lint", State: github.CheckFailure}},
+		{{Name: "build", State: github.CheckSuccess}, {Name: "lint", State: github.CheckFailure}},
+	}
+
+	// Waits for the other checks to finish, unless failing fast
+	withChecksOptions(t, time.Hour, time.Minute, false)
+	checks, err := waitForChecks(fakeChecks(polls...), &bytes.Buffer{}, false)
+	assert.EqualError(t, err, "1 check(s) failed")
+	assert.Equal(t, github.CheckSuccess, checks[0].State)
+
+	withChecksOptions(t, time.Hour, time.Minute, true)
+	checks, err = waitForChecks(fakeChecks(polls...), &bytes.Buffer{}, false)
+	assert.EqualError(t, err, "1 check(s) failed")
+	assert.Equal(t, github.CheckPending, checks[0].State)
+}
+
+func TestWaitForChecksTimeouts(t *testing.T) {
+	withChecksOptions(t, time.Hour, time.Minute, false)
+	_, err := waitForChecks(fakeChecks(nil), &bytes.Buffer{}, false)
+	assert.ErrorContains(t, err, "no checks reported after 1m0s")
+
+	withChecksOptions(t, time.Minute, time.Minute, false)
+	_, err = waitForChecks(fakeChecks([]github.Check{{Name: "build", State: github.CheckPending}}), &bytes.Buffer{}, false)
+	assert.True(t, errors.Is(err, errChecksTimeout))
+}
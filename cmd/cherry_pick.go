@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -26,13 +27,17 @@ Useful when:
 - Want to test specific commits
 
 Example:
-  githelper cherry-pick 123     # Cherry-pick from PR #123`,
+  githelper cherry-pick 123     # Cherry-pick from PR #123
+  githelper cherry-pick 123 --select 1a2b3c4d,5e6f7a8b  # Skip the picker`,
 	Args: cobra.ExactArgs(1),
 	RunE: runCherryPick,
 }
 
+var cherryPickSelect []string
+
 func init() {
 	rootCmd.AddCommand(cherryPickCmd)
+	cherryPickCmd.Flags().StringSliceVar(&cherryPickSelect, "select", nil, "commits of the PR to cherry-pick without prompting")
 }
 
 func runCherryPick(cmd *cobra.Command, args []string) error {
@@ -47,8 +52,8 @@ func runCherryPick(cmd *cobra.Command, args []string) error {
 	}
 
 	// Fetch PR
-	fmt.Printf("🔄 Fetching PR #%d...\n", prNum)
-	fetchCmd := exec.Command("git", "fetch", "origin", fmt.Sprintf("pull/%d/head:pr-%d", prNum, prNum))
+	ui.Stepf("🔄 Fetching PR #%d...", prNum)
+	fetchCmd := gitCommand("fetch", "origin", fmt.Sprintf("pull/%d/head:pr-%d", prNum, prNum))
 	fetchCmd.Stderr = os.Stderr
 	if err := fetchCmd.Run(); err != nil {
 		return fmt.Errorf("failed to fetch PR: %w", err)
@@ -65,8 +70,8 @@ func runCherryPick(cmd *cobra.Command, args []string) error {
 
 	// Cherry-pick each commit
 	for _, commit := range commits {
-		fmt.Printf("🍒 Cherry-picking commit %s...\n", commit[:8])
-		cherryCmd := exec.Command("git", "cherry-pick", commit)
+		ui.Stepf("🍒 Cherry-picking commit %s...", commit[:8])
+		cherryCmd := gitCommand("cherry-pick", commit)
 		cherryCmd.Stdout = os.Stdout
 		cherryCmd.Stderr = os.Stderr
 		if err := cherryCmd.Run(); err != nil {
@@ -74,12 +79,15 @@ func runCherryPick(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	fmt.Printf("✅ Successfully cherry-picked %d commit(s)!\n", len(commits))
+	ui.Successf("Successfully cherry-picked %d commit(s)!", len(commits))
 	return nil
 }
 
 func selectCommitsWithFzf(prNum int) ([]string, error) {
-	if !noFzf {
+	if len(cherryPickSelect) > 0 {
+		return preselectedPRCommits(prNum)
+	}
+	if !noFzf && isInteractive() {
 		if _, err := exec.LookPath("fzf"); err == nil {
 			return selectCommitsWithFzfInteractive(prNum)
 		}
@@ -89,7 +97,7 @@ func selectCommitsWithFzf(prNum int) ([]string, error) {
 
 func selectCommitsWithFzfInteractive(prNum int) ([]string, error) {
 	// Get commit log
-	logCmd := exec.Command("git", "log", "--oneline", "--reverse", fmt.Sprintf("pr-%d", prNum))
+	logCmd := gitCommand("log", "--oneline", "--reverse", fmt.Sprintf("pr-%d", prNum))
 	output, err := logCmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get commit log: %w", err)
@@ -126,8 +134,8 @@ func selectCommitsWithFzfInteractive(prNum int) ([]string, error) {
 
 func selectCommitsWithList(prNum int) ([]string, error) {
 	// Show commits
-	fmt.Printf("\nCommits in PR #%d:\n", prNum)
-	logCmd := exec.Command("git", "log", "--oneline", "--reverse", fmt.Sprintf("pr-%d", prNum))
+	ui.Printf("\nCommits in PR #%d:\n", prNum)
+	logCmd := gitCommand("log", "--oneline", "--reverse", fmt.Sprintf("pr-%d", prNum))
 	logCmd.Stdout = os.Stdout
 	logCmd.Stderr = os.Stderr
 	if err := logCmd.Run(); err != nil {
@@ -135,13 +143,37 @@ func selectCommitsWithList(prNum int) ([]string, error) {
 	}
 
 	// Get commit hashes
-	fmt.Print("\nEnter commit hashes to cherry-pick (space-separated): ")
-	var input string
-	fmt.Scanln(&input)
+	input := readInput("\nEnter commit hashes to cherry-pick (space-separated): ")
 
 	if input == "" {
 		return nil, nil
 	}
 
 	return strings.Fields(input), nil
-} 
\ No newline at end of file
+} 
+// preselectedPRCommits returns the commits of the PR named by --select, in
+// the order they were made
+func preselectedPRCommits(prNum int) ([]string, error) {
+	output, err := gitCommand("log", "--format=%H", "--reverse", fmt.Sprintf("pr-%d", prNum)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit log: %w", err)
+	}
+
+	var commits []string
+	found := make(map[string]bool)
+	for _, hash := range splitLines(string(output)) {
+		for _, item := range cherryPickSelect {
+			if sameCommit(hash, item) {
+				commits = append(commits, hash)
+				found[item] = true
+				break
+			}
+		}
+	}
+	for _, item := range cherryPickSelect {
+		if !found[item] {
+			return nil, fmt.Errorf("no commit matching '%s' found in PR #%d", item, prNum)
+		}
+	}
+	return commits, nil
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
@@ -9,32 +10,44 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/git"
+	"github.com/EndlessUphill/git-helper/internal/progress"
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
 	numFiles  int
 	threshold string
+	cleanDirs bool
 )
 
 var cleanCmd = &cobra.Command{
-	Use:   "clean [file]",
+	Use:   "clean [path...]",
 	Short: "Find and remove large files from git history",
 	Long: `Find and remove large files that are bloating your git repository.
 
 This command helps you clean up your repository by:
 1. Finding the largest files in git history
 2. Letting you select which files to remove
-3. Completely removing selected files from git history
-4. Optionally force pushing the cleaned history
+3. Showing how many files, bytes and commits the removal affects
+4. Completely removing selected files from git history
+
+Paths can be files, directories (everything in them is removed) or glob
+patterns such as '*.zip' or 'assets/**/*.psd'. Quote globs so your shell
+doesn't expand them; globs without a slash match at any depth.
 
 ⚠️  WARNING: This rewrites git history! Use with caution on shared repositories.
 
 Example:
   githelper clean              # Interactive file selection
   githelper clean large.zip   # Remove specific file
+  githelper clean vendor/ '*.mp4'  # Remove a directory and all videos
+  githelper clean --dirs      # Pick from the largest directories
   githelper clean --top 20    # Show top 20 largest files
-  githelper clean --min 100MB # Show files larger than 100MB`,
+  githelper clean --min 100MB # Show files larger than 100MB
+  githelper clean large.zip --gc  # Shrink the repository afterwards
+  githelper clean large.zip --push  # Force push and tell teammates what to run`,
 	RunE: runClean,
 }
 
@@ -42,6 +55,8 @@ func init() {
 	rootCmd.AddCommand(cleanCmd)
 	cleanCmd.Flags().IntVarP(&numFiles, "top", "n", 10, "number of largest files to show")
 	cleanCmd.Flags().StringVarP(&threshold, "min", "m", "", "minimum file size (e.g., 100MB)")
+	cleanCmd.Flags().BoolVar(&cleanDirs, "dirs", false, "list the largest directories instead of files")
+	cleanCmd.Flags().StringVar(&preselectedFile, "select-file", "", "file to remove without prompting")
 }
 
 type LargeFile struct {
@@ -54,54 +69,41 @@ func runClean(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	var fileToPurge string
-	var err error
+	if len(args) == 0 && preselectedFile != "" {
+		args = []string{preselectedFile}
+	}
 
-	if len(args) > 0 {
-		fileToPurge = args[0]
-	} else {
+	if len(args) == 0 {
+		if size, err := git.RepoSize(commandCtx, ""); err == nil {
+			ui.Stepf("📦 Repository size: %s", describeRepoSize(size))
+		}
 		// Find and select large file
-		fmt.Println("🔍 Finding large files in git history...")
-		fileToPurge, err = selectLargeFile()
+		fileToPurge, err := selectLargeFile()
 		if err != nil {
 			return err
 		}
 		if fileToPurge == "" {
 			return fmt.Errorf("no file selected")
 		}
+		args = []string{fileToPurge}
 	}
 
-	// Confirm action
-	fmt.Printf("\n⚠️  WARNING: This will permanently remove '%s' from git history!\n", fileToPurge)
-	fmt.Println("This action CANNOT be undone and will rewrite git history.")
-	if !confirmAction() {
-		fmt.Println("❌ Operation cancelled")
-		return nil
+	removed, err := removeFromHistory("clean", args)
+	if err != nil || !removed {
+		return err
 	}
 
-	// Remove file from git history
-	fmt.Printf("\n🗑️  Removing '%s' from history...\n", fileToPurge)
-	filterCmd := exec.Command("git", "filter-branch", "--force",
-		"--index-filter", fmt.Sprintf("git rm --cached --ignore-unmatch %s", fileToPurge),
-		"--prune-empty", "--tag-name-filter", "cat", "--", "--all")
-	
-	filterCmd.Stdout = os.Stdout
-	filterCmd.Stderr = os.Stderr
-	
-	if err := filterCmd.Run(); err != nil {
-		return fmt.Errorf("failed to remove file from history: %w", err)
+	ui.Success("\nFiles removed from git history!")
+	notifyResult("Files removed from git history", args...)
+	if err := pushAfterRewrite(fmt.Sprintf("%s were removed from history", strings.Join(args, ", "))); err != nil {
+		return err
 	}
-
-	fmt.Println("\n✅ File removed from git history!")
-	fmt.Println("\n⚠️  To push these changes:")
-	fmt.Println("git push origin --force --all")
-
-	return nil
+	return gcAfterHistoryRewrite()
 }
 
 func selectLargeFile() (string, error) {
 	// Try using fzf if available
-	if !noFzf {
+	if !noFzf && isInteractive() {
 		if _, err := exec.LookPath("fzf"); err == nil {
 			return selectLargeFileWithFzf()
 		}
@@ -110,42 +112,30 @@ func selectLargeFile() (string, error) {
 }
 
 func getLargeFiles() ([]LargeFile, error) {
-	// Get all objects in git history
-	cmd := exec.Command("sh", "-c", `git rev-list --objects --all | awk '{print $1}' | git cat-file --batch-check='%(objecttype) %(objectname) %(objectsize) %(rest)' | grep '^blob' | awk '{print $3 " " $4}'`)
-	output, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get git objects: %w", err)
+	var minSize int64
+	if threshold != "" {
+		var err error
+		if minSize, err = parseSize(threshold); err != nil {
+			return nil, fmt.Errorf("invalid size threshold: %w", err)
+		}
 	}
 
-	// Parse output and create file list
+	// Get all objects in git history
 	var files []LargeFile
-	scanner := bufio.NewScanner(strings.NewReader(string(output)))
-	for scanner.Scan() {
-		parts := strings.SplitN(scanner.Text(), " ", 2)
-		if len(parts) != 2 {
-			continue
-		}
-
-		size, err := strconv.ParseInt(parts[0], 10, 64)
-		if err != nil {
-			continue
+	var err error
+	if cleanDirs {
+		if files, err = historyBlobs("🔍 Finding large directories in git history", 0, "--all"); err != nil {
+			return nil, err
 		}
-
-		// Apply size threshold if specified
-		if threshold != "" {
-			thresholdBytes, err := parseSize(threshold)
-			if err != nil {
-				return nil, fmt.Errorf("invalid size threshold: %w", err)
-			}
-			if size < thresholdBytes {
-				continue
+		var dirs []LargeFile
+		for _, dir := range largestDirectories(files) {
+			if dir.Size >= minSize {
+				dirs = append(dirs, dir)
 			}
 		}
-
-		files = append(files, LargeFile{
-			Path: parts[1],
-			Size: size,
-		})
+		files = dirs
+	} else if files, err = historyBlobs("🔍 Finding large files in git history", minSize, "--all"); err != nil {
+		return nil, err
 	}
 
 	// Sort by size
@@ -161,6 +151,67 @@ func getLargeFiles() ([]LargeFile, error) {
 	return files, nil
 }
 
+// historyBlobs returns the files of at least minSize bytes in the whole
+// history of the given rev-list arguments, showing progress under title while
+// history is scanned
+func historyBlobs(title string, minSize int64, revs ...string) ([]LargeFile, error) {
+	ind := progress.Start(ui.Status(), title)
+	blobs, err := git.LargeBlobs(commandCtx, minSize, func(phase string, read int64) {
+		ind.Update(phase, read, 0)
+	}, revs...)
+	if err != nil {
+		ind.Fail()
+		return nil, err
+	}
+	ind.Done()
+
+	files := make([]LargeFile, 0, len(blobs))
+	for _, blob := range blobs {
+		files = append(files, LargeFile{Path: blob.Path, Size: blob.Size})
+	}
+	return files, nil
+}
+
+// findLargeBlobs returns the files of at least minSize bytes among the
+// objects reachable from the given rev-list arguments. It suits small ranges
+// of commits; historyBlobs is faster for whole histories.
+func findLargeBlobs(minSize int64, revs ...string) ([]LargeFile, error) {
+	args := append([]string{"rev-list", "--objects"}, revs...)
+	objects, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git objects: %w", err)
+	}
+
+	// Look up the type and size of each object; the path after the object
+	// name is passed through as %(rest)
+	cmd := gitCommand("cat-file", "--batch-check=%(objecttype) %(objectsize) %(rest)")
+	cmd.Stdin = bytes.NewReader(objects)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object sizes: %w", err)
+	}
+
+	var files []LargeFile
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), " ", 3)
+		if len(parts) != 3 || parts[0] != "blob" {
+			continue
+		}
+
+		size, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || size < minSize {
+			continue
+		}
+
+		files = append(files, LargeFile{
+			Path: parts[2],
+			Size: size,
+		})
+	}
+	return files, nil
+}
+
 func selectLargeFileWithFzf() (string, error) {
 	files, err := getLargeFiles()
 	if err != nil {
@@ -201,14 +252,16 @@ func selectLargeFileWithList() (string, error) {
 		return "", err
 	}
 
-	fmt.Println("\nLargest files in repository:")
+	if cleanDirs {
+		ui.Println("\nLargest directories in repository:")
+	} else {
+		ui.Println("\nLargest files in repository:")
+	}
 	for i, file := range files {
-		fmt.Printf("%2d: %s (%s)\n", i+1, file.Path, formatSize(file.Size))
+		ui.Printf("%2d: %s (%s)\n", i+1, file.Path, formatSize(file.Size))
 	}
 
-	fmt.Print("\nSelect file number (or press Enter to cancel): ")
-	var input string
-	fmt.Scanln(&input)
+	input := readInput("\nSelect file number (or press Enter to cancel): ")
 
 	if input == "" {
 		return "", nil
@@ -224,14 +277,19 @@ func selectLargeFileWithList() (string, error) {
 
 func parseSize(size string) (int64, error) {
 	size = strings.ToUpper(size)
-	multipliers := map[string]int64{
-		"B":  1,
-		"KB": 1024,
-		"MB": 1024 * 1024,
-		"GB": 1024 * 1024 * 1024,
+	// Longest suffixes first so "KB" isn't mistaken for "B"
+	multipliers := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"GB", 1024 * 1024 * 1024},
+		{"MB", 1024 * 1024},
+		{"KB", 1024},
+		{"B", 1},
 	}
 
-	for suffix, multiplier := range multipliers {
+	for _, m := range multipliers {
+		suffix, multiplier := m.suffix, m.multiplier
 		if strings.HasSuffix(size, suffix) {
 			value := strings.TrimSuffix(size, suffix)
 			number, err := strconv.ParseFloat(value, 64)
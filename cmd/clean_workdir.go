@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/codeowners"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultArtifactPatterns are the build artifacts removed unless
+// clean_workdir.patterns or --pattern say otherwise
+var defaultArtifactPatterns = []string{
+	"node_modules/", "dist/", "build/", "target/", "out/", "coverage/",
+	"__pycache__/", ".pytest_cache/", ".mypy_cache/", ".tox/", ".gradle/", ".next/",
+	"*.pyc", "*.o", "*.class", "*.log",
+}
+
+var (
+	cleanWorkdirForce    bool
+	cleanWorkdirCurrent  bool
+	cleanWorkdirPatterns []string
+)
+
+// Artifact is an untracked file or directory matching an artifact pattern
+type Artifact struct {
+	// Path is relative to the worktree, with a trailing slash for directories
+	Path    string
+	Pattern string
+	Size    int64
+}
+
+var cleanWorkdirCmd = &cobra.Command{
+	Use:   "clean-workdir",
+	Short: "Remove untracked build artifacts from every worktree",
+	Long: `Find the untracked files and directories matching build artifact patterns in
+every worktree of the repository, and remove them with --force. Without
+--force nothing is deleted: the artifacts are listed with their size.
+
+Patterns are gitignore-style: "dist/" matches directories named dist anywhere,
+"*.log" matches files. They come from --pattern, clean_workdir.patterns in
+.githelper.yaml, or default to common artifacts:
+  ` + strings.Join(defaultArtifactPatterns, " ") + `
+
+Tracked files and nested repositories are never touched. This cleans the
+working directory only; to remove files from history, see 'githelper clean'.
+
+Example:
+  githelper clean-workdir                     # List the artifacts
+  githelper clean-workdir --force             # Remove them
+  githelper clean-workdir -p node_modules/ -p '*.tmp' --force
+  githelper clean-workdir --current           # Only this worktree`,
+	Args: cobra.NoArgs,
+	RunE: runCleanWorkdir,
+}
+
+func init() {
+	rootCmd.AddCommand(cleanWorkdirCmd)
+	cleanWorkdirCmd.Flags().BoolVarP(&cleanWorkdirForce, "force", "f", false, "remove the artifacts instead of listing them")
+	cleanWorkdirCmd.Flags().BoolVar(&cleanWorkdirCurrent, "current", false, "only clean the current worktree")
+	cleanWorkdirCmd.Flags().StringSliceVarP(&cleanWorkdirPatterns, "pattern", "p", nil, "artifact pattern, may be repeated (default: clean_workdir.patterns or common artifacts)")
+}
+
+func runCleanWorkdir(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	patterns := cleanWorkdirPatterns
+	if len(patterns) == 0 {
+		patterns = viper.GetStringSlice("clean_workdir.patterns")
+	}
+	if len(patterns) == 0 {
+		patterns = defaultArtifactPatterns
+	}
+
+	var worktrees []string
+	if cleanWorkdirCurrent {
+		root, err := getRepoRoot()
+		if err != nil {
+			return err
+		}
+		worktrees = []string{root}
+	} else {
+		var err error
+		if worktrees, err = worktreePaths(); err != nil {
+			return err
+		}
+	}
+
+	found := map[string][]Artifact{}
+	var count int
+	var total int64
+	for _, worktree := range worktrees {
+		artifacts, err := findArtifacts(worktree, patterns)
+		if err != nil {
+			return err
+		}
+		if len(artifacts) == 0 {
+			continue
+		}
+		found[worktree] = artifacts
+		ui.Stepf("📂 %s", worktree)
+		for _, artifact := range artifacts {
+			ui.Printf("  %-50s %10s  (%s)\n", artifact.Path, formatSize(artifact.Size), artifact.Pattern)
+			count++
+			total += artifact.Size
+		}
+	}
+	if count == 0 {
+		ui.Successf("No build artifacts in %d worktree(s)", len(worktrees))
+		return nil
+	}
+	ui.Stepf("\n📊 %d artifact(s) in %d worktree(s), %s", count, len(found), formatSize(total))
+
+	if !cleanWorkdirForce {
+		ui.Step("🔍 Nothing was removed, pass --force to remove them")
+		return nil
+	}
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	for _, worktree := range worktrees {
+		for _, artifact := range found[worktree] {
+			if err := os.RemoveAll(filepath.Join(worktree, filepath.FromSlash(artifact.Path))); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", artifact.Path, err)
+			}
+		}
+	}
+	ui.Successf("Removed %d artifact(s), freed %s", count, formatSize(total))
+	return nil
+}
+
+// findArtifacts returns the untracked files and directories of a worktree
+// that match the patterns, ignored ones included
+func findArtifacts(worktree string, patterns []string) ([]Artifact, error) {
+	// Wholly untracked directories are listed once, with a trailing slash
+	output, err := gitCommand("-C", worktree, "ls-files", "-z", "--others", "--directory", "--no-empty-directory").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files in %s: %w", worktree, err)
+	}
+
+	var artifacts []Artifact
+	add := func(rel string, dir bool, pattern string) {
+		full := filepath.Join(worktree, filepath.FromSlash(rel))
+		if dir {
+			artifacts = append(artifacts, Artifact{Path: rel + "/", Pattern: pattern, Size: dirSize(full)})
+		} else if info, err := os.Lstat(full); err == nil {
+			artifacts = append(artifacts, Artifact{Path: rel, Pattern: pattern, Size: info.Size()})
+		}
+	}
+
+	for _, entry := range strings.Split(string(output), "\x00") {
+		if entry == "" {
+			continue
+		}
+		rel := strings.TrimSuffix(entry, "/")
+		dir := strings.HasSuffix(entry, "/")
+		full := filepath.Join(worktree, filepath.FromSlash(rel))
+		if dir && isNestedRepo(full) {
+			continue
+		}
+		if pattern := artifactPattern(rel, dir, patterns); pattern != "" {
+			add(rel, dir, pattern)
+			continue
+		}
+		if !dir {
+			continue
+		}
+
+		// Look for artifacts inside untracked directories that don't match
+		err := filepath.WalkDir(full, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || p == full {
+				return err
+			}
+			sub, _ := filepath.Rel(worktree, p)
+			sub = filepath.ToSlash(sub)
+			if d.IsDir() && isNestedRepo(p) {
+				return filepath.SkipDir
+			}
+			if pattern := artifactPattern(sub, d.IsDir(), patterns); pattern != "" {
+				add(sub, d.IsDir(), pattern)
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", full, err)
+		}
+	}
+	return artifacts, nil
+}
+
+// artifactPattern returns the first pattern matching a path, or "". Patterns
+// ending with a slash only match directories, and the files below them.
+func artifactPattern(rel string, dir bool, patterns []string) string {
+	for _, pattern := range patterns {
+		target := rel
+		if strings.HasSuffix(pattern, "/") && !dir {
+			target = path.Dir(rel)
+			if target == "." {
+				continue
+			}
+		}
+		if codeowners.MatchPattern(pattern, target) {
+			return pattern
+		}
+	}
+	return ""
+}
+
+func isNestedRepo(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// dirSize returns the total size of the files below a directory
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArtifactPattern(t *testing.T) {
+	patterns := []string{"dist/", "*.log"}
+	assert.Equal(t, "dist/", artifactPattern("web/dist", true, patterns))
+	assert.Equal(t, "dist/", artifactPattern("dist/app.js", false, patterns))
+	assert.Equal(t, "", artifactPattern("dist", false, patterns))
+	assert.Equal(t, "*.log", artifactPattern("logs/debug.log", false, patterns))
+	assert.Equal(t, "", artifactPattern("main.go", false, patterns))
+}
+
+func TestFindArtifacts(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	write := func(rel, content string) {
+		full := filepath.Join(tmpDir, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+	write(".gitignore", "node_modules/\n")
+	write("build/keep.txt", "tracked")
+	runGit(t, tmpDir, "add", ".")
+	runGit(t, tmpDir, "commit", "-m", "base")
+
+	write("node_modules/pkg/index.js", "12345")
+	write("build/output.bin", "untracked in a tracked directory")
+	write("scratch/notes.txt", "keep me")
+	write("scratch/debug.log", "log")
+	write("vendor/lib/.git", "gitdir: elsewhere")
+	write("vendor/lib/debug.log", "nested repository")
+
+	artifacts, err := findArtifacts(tmpDir, []string{"node_modules/", "build/", "*.log"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Artifact{
+		{Path: "node_modules/", Pattern: "node_modules/", Size: 5},
+		{Path: "build/output.bin", Pattern: "build/", Size: 32},
+		{Path: "scratch/debug.log", Pattern: "*.log", Size: 3},
+	}, artifacts)
+}
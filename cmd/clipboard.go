@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/EndlessUphill/git-helper/internal/clipboard"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var copyOutput bool
+
+func init() {
+	commitCmd.Flags().BoolVar(&copyOutput, "copy", false, "copy the commit message to the clipboard")
+	branchNewCmd.Flags().BoolVar(&copyOutput, "copy", false, "copy the branch name to the clipboard")
+	recoverCmd.Flags().BoolVar(&copyOutput, "copy", false, "copy the hash of the recovered commit to the clipboard")
+	linkCmd.Flags().BoolVar(&copyOutput, "copy", false, "copy the links to the clipboard")
+	pushCmd.Flags().BoolVar(&copyOutput, "copy", false, "with --rewritten, copy the instructions for teammates to the clipboard")
+	for _, c := range []*cobra.Command{cleanCmd, purgeCmd, rewriteAuthorCmd} {
+		c.Flags().BoolVar(&copyOutput, "copy", false, "with --push, copy the instructions for teammates to the clipboard")
+	}
+}
+
+// copyToClipboard copies text when --copy is set, with clipboard.command
+// when it is configured. what names the text in the messages.
+func copyToClipboard(what, text string) {
+	if !copyOutput {
+		return
+	}
+	if err := clipboard.Copy(text, viper.GetStringSlice("clipboard.command")...); err != nil {
+		ui.Warnf("Could not copy the %s: %v", what, err)
+		return
+	}
+	ui.Successf("📋 Copied the %s to the clipboard", what)
+}
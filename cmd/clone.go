@@ -2,18 +2,20 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/git"
+	"github.com/EndlessUphill/git-helper/internal/progress"
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
 	depth       int
 	singleBranch bool
 	noTags      bool
+	cloneWorktrees bool
 )
 
 var cloneCmd = &cobra.Command{
@@ -34,7 +36,15 @@ Useful for:
 Example:
   githelper clone https://github.com/org/repo.git        # Normal clone
   githelper clone --depth 1 https://github.com/org/repo  # Shallow clone
-  githelper clone --single-branch org/repo               # Clone only default branch`,
+  githelper clone --single-branch org/repo               # Clone only default branch
+  githelper clone --worktrees org/repo                   # Bare clone with a worktree per branch
+
+With --worktrees the repository is cloned bare into <directory>/.bare, with a
+worktree for the default branch and the newest release branches next to it
+(<directory>/main, <directory>/release/2.1). clone.worktree_branches in
+~/.githelper.yaml lists the branches and patterns to check out (default
+release/*), and clone.release_worktrees how many branches a pattern checks
+out (default 2).`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runClone,
 }
@@ -44,6 +54,7 @@ func init() {
 	cloneCmd.Flags().IntVarP(&depth, "depth", "d", 0, "create a shallow clone with specified depth")
 	cloneCmd.Flags().BoolVar(&singleBranch, "single-branch", false, "clone only the default branch")
 	cloneCmd.Flags().BoolVar(&noTags, "no-tags", false, "don't clone any tags")
+	cloneCmd.Flags().BoolVar(&cloneWorktrees, "worktrees", false, "clone bare into .bare with a worktree per configured branch")
 }
 
 func runClone(cmd *cobra.Command, args []string) error {
@@ -59,7 +70,14 @@ func runClone(cmd *cobra.Command, args []string) error {
 	}
 
 	// Normalize repository URL
-	repo = normalizeRepoURL(repo)
+	repo, err := normalizeRepoURL(repo)
+	if err != nil {
+		return err
+	}
+
+	if cloneWorktrees {
+		return runCloneWorktrees(repo, directory)
+	}
 
 	// Build clone command with options
 	cloneArgs := []string{"clone"}
@@ -76,52 +94,43 @@ func runClone(cmd *cobra.Command, args []string) error {
 		cloneArgs = append(cloneArgs, "--no-tags")
 	}
 
-	// Add progress display
+	// Progress is shown even though git's stderr isn't a terminal
 	cloneArgs = append(cloneArgs, "--progress")
 
 	// Add repository URL and directory
 	cloneArgs = append(cloneArgs, repo, directory)
 
 	// Show what we're doing
-	fmt.Printf("🔄 Cloning repository: %s\n", repo)
+	ui.Stepf("🔄 Cloning repository: %s", repo)
 	if depth > 0 {
-		fmt.Printf("📏 Shallow clone with depth: %d\n", depth)
+		ui.Stepf("📏 Shallow clone with depth: %d", depth)
 	}
 	if singleBranch {
-		fmt.Println("🌿 Cloning only the default branch")
+		ui.Step("🌿 Cloning only the default branch")
 	}
 	if noTags {
-		fmt.Println("🏷️  Skipping tag download")
+		ui.Step("🏷️  Skipping tag download")
 	}
 
 	// Run the clone command
-	cloneCmd := exec.Command("git", cloneArgs...)
-	cloneCmd.Stdout = os.Stdout
-	cloneCmd.Stderr = os.Stderr
-
-	if err := cloneCmd.Run(); err != nil {
+	if err := progress.Git(ui.Status(), "📥 Cloning", gitCommand(cloneArgs...)); err != nil {
 		return fmt.Errorf("failed to clone repository: %w", err)
 	}
 
 	// Get repo size after cloning
-	size, err := getRepoSize(directory)
-	if err == nil {
-		fmt.Printf("📦 Repository size: %s\n", formatSize(size))
+	if size, err := git.RepoSize(commandCtx, directory); err == nil {
+		ui.Stepf("📦 Repository size: %s", describeRepoSize(size))
 	}
 
-	fmt.Printf("✅ Repository cloned successfully to: %s\n", directory)
+	ui.Successf("Repository cloned successfully to: %s", directory)
+	notifyResult(fmt.Sprintf("Cloned %s to %s", repo, directory))
 	return nil
 }
 
-func normalizeRepoURL(repo string) string {
-	// Handle GitHub shorthand (org/repo)
-	if !strings.Contains(repo, "://") && !strings.Contains(repo, "@") {
-		if !strings.HasSuffix(repo, ".git") {
-			repo += ".git"
-		}
-		return "https://github.com/" + repo
-	}
-	return repo
+func normalizeRepoURL(repo string) (string, error) {
+	// Handle GitHub shorthand (org/repo) on the default host, over SSH when
+	// the config or profile asks for it
+	return expandRepoURL(repo, viper.IsSet("use_ssh") && viper.GetBool("use_ssh"))
 }
 
 func getDefaultDirectory(repo string) string {
@@ -138,17 +147,3 @@ func getDefaultDirectory(repo string) string {
 	parts := strings.Split(repo, "/")
 	return parts[len(parts)-1]
 }
-
-func getRepoSize(directory string) (int64, error) {
-	var size int64
-	err := filepath.Walk(directory, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			size += info.Size()
-		}
-		return nil
-	})
-	return size, err
-}
\ No newline at end of file
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorktreeBranches(t *testing.T) {
+	branches := []string{"release/2.10", "release/2.9", "release/1.0", "main", "feature"}
+	assert.Equal(t, []string{"main", "release/2.10", "release/2.9"},
+		worktreeBranches("main", branches, []string{"release/*"}, 2))
+	assert.Equal(t, []string{"main", "feature", "release/2.10"},
+		worktreeBranches("main", branches, []string{"main", "feature", "release/*", "hotfix/*"}, 1))
+	assert.Equal(t, []string{"main", "release/2.10", "release/2.9", "release/1.0"},
+		worktreeBranches("main", branches, []string{"release/*"}, 0), "0 checks out every match")
+}
+
+func TestCloneWorktrees(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Commits("base", 1)
+	r.WithRemote()
+	for _, branch := range []string{"release/1.0", "release/1.1", "release/2.0"} {
+		r.Checkout(branch)
+		r.Commit("Release "+branch, "VERSION", branch+"\n")
+		r.Git("push", "--quiet", "origin", branch)
+	}
+	r.Checkout("main")
+
+	dir := filepath.Join(t.TempDir(), "app")
+	_, _, err := execute(t, "clone", "--worktrees", r.Remote, dir)
+	require.NoError(t, err)
+
+	assert.DirExists(t, filepath.Join(dir, ".bare"))
+	assert.FileExists(t, filepath.Join(dir, "main", "base1.txt"))
+	version, err := os.ReadFile(filepath.Join(dir, "release", "2.0", "VERSION"))
+	require.NoError(t, err)
+	assert.Equal(t, "release/2.0\n", string(version))
+	assert.DirExists(t, filepath.Join(dir, "release", "1.1"))
+	assert.NoDirExists(t, filepath.Join(dir, "release", "1.0"), "only the two newest releases")
+
+	upstream, err := exec.Command("git", "-C", filepath.Join(dir, "main"), "rev-parse", "--abbrev-ref", "@{upstream}").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "origin/main", strings.TrimSpace(string(upstream)))
+
+	_, _, err = execute(t, "clone", "--worktrees", r.Remote, dir)
+	assert.ErrorContains(t, err, "not empty")
+}
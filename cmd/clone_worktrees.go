@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/progress"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/viper"
+)
+
+// defaultWorktreeBranches are the branches 'clone --worktrees' checks out
+// next to the default branch when clone.worktree_branches isn't set
+var defaultWorktreeBranches = []string{"release/*"}
+
+// defaultReleaseWorktrees is how many branches a pattern checks out when
+// clone.release_worktrees isn't set
+const defaultReleaseWorktrees = 2
+
+// worktreeBranches picks the branches to check out: the default branch, then
+// for each pattern the branch it names or, for a glob such as release/*, the
+// newest matching ones. branches must be sorted newest version first.
+func worktreeBranches(defaultBranch string, branches, patterns []string, perPattern int) []string {
+	selected := []string{defaultBranch}
+	seen := map[string]bool{defaultBranch: true}
+	for _, pattern := range patterns {
+		matched := 0
+		for _, branch := range branches {
+			if perPattern > 0 && matched == perPattern {
+				break
+			}
+			if ok, _ := path.Match(pattern, branch); !ok {
+				continue
+			}
+			matched++
+			if !seen[branch] {
+				seen[branch] = true
+				selected = append(selected, branch)
+			}
+		}
+	}
+	return selected
+}
+
+// runCloneWorktrees clones repo as a bare repository in directory/.bare and
+// adds a worktree per branch next to it:
+//
+//	directory/.bare          the repository
+//	directory/.git           points git at .bare
+//	directory/main           worktree of the default branch
+//	directory/release/2.1    worktree of a release branch
+func runCloneWorktrees(repo, directory string) error {
+	if entries, err := os.ReadDir(directory); err == nil && len(entries) > 0 {
+		return fmt.Errorf("destination '%s' already exists and is not empty", directory)
+	}
+
+	cloneArgs := []string{"clone", "--bare", "--progress"}
+	if depth > 0 {
+		cloneArgs = append(cloneArgs, "--depth", fmt.Sprintf("%d", depth))
+	}
+	if singleBranch {
+		cloneArgs = append(cloneArgs, "--single-branch")
+	}
+	if noTags {
+		cloneArgs = append(cloneArgs, "--no-tags")
+	}
+	cloneArgs = append(cloneArgs, repo, filepath.Join(directory, ".bare"))
+
+	ui.Stepf("🔄 Cloning repository: %s", repo)
+	if err := progress.Git(ui.Status(), "📥 Cloning", gitCommand(cloneArgs...)); err != nil {
+		return fmt.Errorf("failed to clone repository: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(directory, ".git"), []byte("gitdir: ./.bare\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Join(directory, ".git"), err)
+	}
+
+	// A bare clone has no remote-tracking branches; fetch them so the
+	// worktrees can track origin
+	if err := gitRun("-C", directory, "config", "remote.origin.fetch", "+refs/heads/*:refs/remotes/origin/*"); err != nil {
+		return fmt.Errorf("failed to configure origin: %w", err)
+	}
+	ui.Step("📡 Fetching remote branches...")
+	if err := gitRun("-C", directory, "fetch", "--quiet", "origin"); err != nil {
+		return fmt.Errorf("failed to fetch origin: %w", err)
+	}
+
+	output, err := gitCommand("-C", directory, "symbolic-ref", "--short", "HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("failed to determine the default branch: %w", err)
+	}
+	mainBranch := strings.TrimSpace(string(output))
+	output, err = gitCommand("-C", directory, "for-each-ref", "--sort=-version:refname", "--format=%(refname:short)", "refs/heads").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	patterns := defaultWorktreeBranches
+	if viper.IsSet("clone.worktree_branches") {
+		patterns = viper.GetStringSlice("clone.worktree_branches")
+	}
+	perPattern := defaultReleaseWorktrees
+	if viper.IsSet("clone.release_worktrees") {
+		perPattern = viper.GetInt("clone.release_worktrees")
+	}
+
+	for _, branch := range worktreeBranches(mainBranch, splitLines(string(output)), patterns, perPattern) {
+		ui.Stepf("🌱 Adding worktree for '%s'...", branch)
+		if err := gitRun("-C", directory, "worktree", "add", "--quiet", branch, branch); err != nil {
+			return fmt.Errorf("failed to add worktree for %s: %w", branch, err)
+		}
+		if err := gitRun("-C", directory, "branch", "--quiet", "--set-upstream-to", "origin/"+branch, branch); err != nil {
+			ui.Warnf("Couldn't set the upstream of '%s': %v", branch, err)
+		}
+	}
+
+	ui.Successf("Repository cloned with worktrees to: %s", directory)
+	notifyResult(fmt.Sprintf("Cloned %s with worktrees to %s", repo, directory))
+	ui.Printf("   cd %s\n", filepath.Join(directory, mainBranch))
+	return nil
+}
@@ -2,19 +2,27 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
+	"unicode"
 
 	"github.com/EndlessUphill/git-helper/internal/ai"
+	"github.com/EndlessUphill/git-helper/internal/gitmoji"
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	skipEdit    bool
-	commitType  string
+	skipEdit      bool
+	commitType    string
+	offlineAI     bool
+	noAICache     bool
+	commitScope   string
+	commitSummary string
 )
 
 var commitCmd = &cobra.Command{
@@ -24,7 +32,42 @@ var commitCmd = &cobra.Command{
 Common types: feat, fix, docs, style, refactor, test, chore.
 Format: <type>[optional scope]: <description>
 
-Example: feat(auth): add OAuth2 authentication`,
+Example: feat(auth): add OAuth2 authentication
+
+AI-generated messages are cached per staged diff in ~/.githelper/cache/ai.
+When no API key is configured or the AI provider cannot be reached, a
+message is derived offline from the changed file paths and diff stats (or
+always, with --offline).
+
+The message header can be customized with commit_template in .githelper.yaml:
+
+  commit_template:
+    format: "{{.Type}}{{if .Scope}}({{.Scope}}){{end}}{{if .Breaking}}!{{end}}: [{{.Ticket}}] {{.Summary}}"
+    ticket_pattern: "[A-Z][A-Z0-9]+-[0-9]+"
+
+Ticket is extracted from the current branch name (e.g. feature/JIRA-123-login).
+Breaking is true for headers marked with "!", such as "feat(api)!: drop v1".
+
+Without a terminal, or with --no-edit, there is no editor to write the
+summary in: pass --type and --message, e.g.
+
+  githelper commit --type fix --message "handle empty config files" --no-edit
+
+With use_gitmoji (or --gitmoji) messages start with a gitmoji: the manual
+flow lets you pick or search one instead of a type, and --ai adds the one
+matching the change. 'githelper gitmoji' lists them.
+
+--ai follows the conventions of the repository, learned from its last 100
+commits: conventional types and scopes, tense, capitalization, emoji and where
+ticket IDs go. commit_conventions in .githelper.yaml overrides them:
+
+  commit_conventions:
+    learn: false                 # don't look at the history
+    sample: 200                  # commits to learn from (default 100)
+    conventional: true           # "type(scope): summary" subjects
+    types: [feat, fix, chore]
+    scopes: [api, cli, docs]
+    instructions: "Mention the affected service in the body"`,
 	RunE: runCommit,
 }
 
@@ -33,7 +76,12 @@ func init() {
 	flags := commitCmd.Flags()
 	flags.BoolVarP(&skipEdit, "no-edit", "n", false, "skip editing the generated message")
 	flags.StringVarP(&commitType, "type", "t", "", "commit type (feat, fix, docs, etc.)")
+	flags.StringVar(&commitScope, "scope", "", "commit scope")
+	flags.StringVarP(&commitSummary, "message", "m", "", "summary of the change, written after the type")
+	addSigningFlags(commitCmd)
 	flags.BoolVarP(&useAI, "ai", "a", false, "use AI to generate commit message")
+	flags.BoolVar(&offlineAI, "offline", false, "generate the message from diff stats without calling the AI provider")
+	flags.BoolVar(&noAICache, "no-cache", false, "ignore cached AI messages for the staged changes")
 }
 
 func runCommit(cmd *cobra.Command, args []string) error {
@@ -51,6 +99,9 @@ func runCommit(cmd *cobra.Command, args []string) error {
 	if summary == "" {
 		return fmt.Errorf("no staged changes found. Use 'git add' to stage changes")
 	}
+	if useAI && commitSummary != "" {
+		return fmt.Errorf("--message can't be used with --ai, which writes the summary")
+	}
 
 	// Generate commit message
 	message, err := generateCommitMessage(summary)
@@ -58,28 +109,38 @@ func runCommit(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Allow user to edit unless --no-edit flag is set
-	if !skipEdit {
+	// Allow user to edit unless --no-edit flag is set or there is no terminal
+	if !skipEdit && isInteractive() {
 		message, err = editMessage(message)
 		if err != nil {
 			return err
 		}
+	} else {
+		message = stripCommentLines(message)
+	}
+
+	if strings.TrimSpace(message) == "" {
+		return fmt.Errorf("empty commit message, nothing committed")
 	}
 
 	// Make the commit
-	return makeCommit(message)
+	if err := makeCommit(message); err != nil {
+		return err
+	}
+	copyToClipboard("commit message", message)
+	return nil
 }
 
 func checkGitRepo() error {
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
+	cmd := gitCommand("rev-parse", "--git-dir")
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("not a git repository")
+		return errors.New(i18n.T("error.not_git_repo"))
 	}
 	return nil
 }
 
 func getStagedChangesSummary() (string, error) {
-	cmd := exec.Command("git", "diff", "--cached", "--stat")
+	cmd := gitCommand("diff", "--cached", "--stat")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get staged changes: %w", err)
@@ -88,7 +149,7 @@ func getStagedChangesSummary() (string, error) {
 }
 
 func getDetailedDiff() (string, error) {
-	cmd := exec.Command("git", "diff", "--cached")
+	cmd := gitCommand("diff", "--cached")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get detailed diff: %w", err)
@@ -106,35 +167,44 @@ func generateCommitMessage(summary string) (string, error) {
 			return "", err
 		}
 
-		// Get OpenAI API key
-		apiKey := viper.GetString("openai_api_key")
-		if apiKey == "" {
-			return "", fmt.Errorf("OpenAI API key not found in config")
+		aiMessage, err := generateAIMessage(diff)
+		if err != nil {
+			return "", err
 		}
 
-		// Generate commit message using AI
-		generator := ai.NewCommitGenerator(apiKey)
-		aiMessage, err := generator.GenerateCommitMessage(diff)
+		aiMessage, err = applyCommitTemplate(aiMessage)
 		if err != nil {
 			return "", err
 		}
+		if useGitmoji() {
+			aiMessage = withGitmoji(aiMessage)
+		}
 
 		message.WriteString(aiMessage)
 	} else {
 		// Original manual commit message generation
-		if commitType == "" {
-			fmt.Println("Available commit types:")
-			fmt.Println("1. feat     - A new feature")
-			fmt.Println("2. fix      - A bug fix")
-			fmt.Println("3. docs     - Documentation only changes")
-			fmt.Println("4. style    - Changes that don't affect the meaning of the code")
-			fmt.Println("5. refactor - Code change that neither fixes a bug nor adds a feature")
-			fmt.Println("6. test     - Adding missing tests or correcting existing tests")
-			fmt.Println("7. chore    - Changes to the build process or auxiliary tools")
-			
-			fmt.Print("\nEnter commit type (or number): ")
-			var input string
-			fmt.Scanln(&input)
+		// Neither picker can ask for the type without a terminal
+		if commitType == "" && !isInteractive() {
+			return "", fmt.Errorf("running non-interactively, pass --type to choose the commit type")
+		}
+		var picked *gitmoji.Gitmoji
+		if commitType == "" && useGitmoji() {
+			if g, ok := pickGitmoji(); ok {
+				picked = &g
+				commitType = g.Type
+			}
+		}
+		if commitType == "" && picked == nil {
+			ui.Println("Available commit types:")
+			ui.Println("1. feat     - A new feature")
+			ui.Println("2. fix      - A bug fix")
+			ui.Println("3. docs     - Documentation only changes")
+			ui.Println("4. style    - Changes that don't affect the meaning of the code")
+			ui.Println("5. refactor - Code change that neither fixes a bug nor adds a feature")
+			ui.Println("6. test     - Adding missing tests or correcting existing tests")
+			ui.Println("7. chore    - Changes to the build process or auxiliary tools")
+
+			input := readInput("\nEnter commit type (or number): ")
 
 			// Handle numeric input
 			switch input {
@@ -155,13 +225,39 @@ func generateCommitMessage(summary string) (string, error) {
 			default:
 				commitType = input
 			}
+			if commitType == "" {
+				return "", fmt.Errorf("no commit type chosen, nothing committed")
+			}
 		}
-		message.WriteString(fmt.Sprintf("%s: ", commitType))
+		// Without an editor, the summary can only come from --message
+		if commitSummary == "" && (skipEdit || !isInteractive()) {
+			return "", fmt.Errorf("no summary of the change: pass --message, or run in a terminal without --no-edit to write it in the editor")
+		}
+		ticket, err := currentTicket()
+		if err != nil {
+			return "", err
+		}
+		header, err := renderCommitTemplate(viper.GetString("commit_template.format"), CommitFields{
+			Type:    commitType,
+			Scope:   commitScope,
+			Ticket:  ticket,
+			Summary: commitSummary,
+		})
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case picked != nil:
+			header = picked.String(gitmojiShortcodes()) + " " + header
+		case useGitmoji():
+			header = withGitmoji(header)
+		}
+		message.WriteString(header)
 	}
 
 	// Add summary of changes
 	message.WriteString("\n\n# Changes to be committed:\n")
-	message.WriteString(fmt.Sprintf("# %s\n", summary))
+	message.WriteString(commentLines(summary))
 	if useAI {
 		message.WriteString("\n# AI-generated commit message above\n")
 	}
@@ -170,6 +266,73 @@ func generateCommitMessage(summary string) (string, error) {
 	return message.String(), nil
 }
 
+// generateAIMessage returns the cached message for a diff when there is one,
+// asks the AI provider otherwise, and falls back to an offline message when
+// there is no API key or the provider cannot be reached.
+func generateAIMessage(diff string) (string, error) {
+	if offlineAI {
+		return generateOfflineMessage()
+	}
+
+	apiKey := viper.GetString("openai_api_key")
+	if apiKey == "" {
+		ui.Warn("No OpenAI API key configured (githelper config set openai_api_key <key>)")
+		ui.Println("Falling back to an offline message based on the changed files")
+		return generateOfflineMessage()
+	}
+
+	// Messages are cached per diff and conventions, so changing the
+	// conventions generates a new one
+	conventions, err := commitConventions()
+	if err != nil {
+		return "", err
+	}
+	cacheKey := diff + "\n" + conventions.Prompt()
+
+	var cache *ai.Cache
+	if dir, err := ai.DefaultCacheDir(); err == nil {
+		cache = ai.NewCache(dir)
+	}
+	if cache != nil && !noAICache {
+		if cached, ok := cache.Get(cacheKey); ok {
+			ui.Step("♻️  Using cached AI commit message for these changes")
+			return cached, nil
+		}
+	}
+
+	// Generate commit message using AI
+	generator := ai.NewCommitGenerator(apiKey)
+	generator.Conventions = conventions
+	aiMessage, err := generator.GenerateCommitMessage(diff)
+	if err != nil {
+		ui.Warnf("AI generation failed: %v", err)
+		ui.Println("Falling back to an offline message based on the changed files")
+		return generateOfflineMessage()
+	}
+
+	if cache != nil {
+		if err := cache.Put(cacheKey, aiMessage); err != nil && viper.GetBool("debug") {
+			ui.Printf("Failed to cache AI message: %v\n", err)
+		}
+	}
+	return aiMessage, nil
+}
+
+// generateOfflineMessage describes the staged files. Renames are listed as a
+// deletion and an addition: with rename detection numstat writes the path as
+// "{old => new}", which doesn't match the new path from --name-status.
+func generateOfflineMessage() (string, error) {
+	numstat, err := gitCommand("diff", "--cached", "--no-renames", "--numstat").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff stats: %w", err)
+	}
+	nameStatus, err := gitCommand("diff", "--cached", "--no-renames", "--name-status").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff status: %w", err)
+	}
+	return ai.OfflineCommitMessage(ai.ParseNumstat(string(numstat), string(nameStatus))), nil
+}
+
 func editMessage(message string) (string, error) {
 	// Create temporary file
 	tmpfile, err := os.CreateTemp("", "COMMIT_EDITMSG")
@@ -184,17 +347,8 @@ func editMessage(message string) (string, error) {
 	}
 	tmpfile.Close()
 
-	// Get editor command (use EDITOR env var or default to vim)
-	editor := os.Getenv("EDITOR")
-	if editor == "" {
-		editor = "vim"
-	}
-
-	// Open editor
-	cmd := exec.Command(editor, tmpfile.Name())
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// Open editor ($EDITOR, git's core.editor, or the platform default)
+	cmd := editorCommand(tmpfile.Name())
 	if err := cmd.Run(); err != nil {
 		return "", fmt.Errorf("failed to open editor: %w", err)
 	}
@@ -205,22 +359,49 @@ func editMessage(message string) (string, error) {
 		return "", fmt.Errorf("failed to read edited message: %w", err)
 	}
 
-	// Remove comments and empty lines
+	return stripCommentLines(string(content)), nil
+}
+
+// commentLines turns every line of text into a comment of the commit message
+func commentLines(text string) string {
+	var out strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		out.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+	}
+	return out.String()
+}
+
+// stripCommentLines cleans up a commit message like git's cleanup=strip:
+// comment lines are dropped, trailing spaces removed, runs of blank lines
+// collapsed to one and blank lines at the start and end trimmed. The blank
+// line between the subject and the body is kept.
+func stripCommentLines(content string) string {
 	var lines []string
-	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	blank := false
+	scanner := bufio.NewScanner(strings.NewReader(content))
 	for scanner.Scan() {
-		line := scanner.Text()
-		if !strings.HasPrefix(strings.TrimSpace(line), "#") && line != "" {
-			lines = append(lines, line)
+		line := strings.TrimRightFunc(scanner.Text(), unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(line, "#"):
+			continue
+		case line == "":
+			blank = len(lines) > 0
+			continue
 		}
+		if blank {
+			lines = append(lines, "")
+			blank = false
+		}
+		lines = append(lines, line)
 	}
 
-	return strings.Join(lines, "\n"), nil
+	return strings.Join(lines, "\n")
 }
 
 func makeCommit(message string) error {
-	cmd := exec.Command("git", "commit", "-m", message)
+	args := append([]string{"commit", "-m", message}, commitSigningArgs()...)
+	cmd := gitCommand(args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
-} 
\ No newline at end of file
+}
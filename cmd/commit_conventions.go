@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/EndlessUphill/git-helper/internal/ai"
+	"github.com/EndlessUphill/git-helper/internal/gitmoji"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultConventionSample is how many recent commits commit --ai learns
+	// the conventions of the repository from
+	defaultConventionSample = 100
+	// minConventionSample is the fewest commits worth learning from
+	minConventionSample = 5
+)
+
+// commitConventions returns the commit message conventions --ai follows:
+// learned from the recent commits of the repository unless
+// commit_conventions.learn is false, with the commit_conventions settings
+// taking precedence
+func commitConventions() (*ai.Conventions, error) {
+	conventions := ai.DefaultConventions()
+	if !viper.IsSet("commit_conventions.learn") || viper.GetBool("commit_conventions.learn") {
+		sample := defaultConventionSample
+		if viper.IsSet("commit_conventions.sample") {
+			sample = viper.GetInt("commit_conventions.sample")
+		}
+		output, err := gitCommand("log", "--no-merges", "--format=%s", "-n", strconv.Itoa(sample)).Output()
+		if subjects := splitLines(string(output)); err == nil && len(subjects) >= minConventionSample {
+			conventions = ai.InferConventions(subjects)
+		}
+	}
+
+	if viper.IsSet("commit_conventions.conventional") {
+		conventions.Conventional = viper.GetBool("commit_conventions.conventional")
+	}
+	if viper.IsSet("commit_conventions.types") {
+		conventions.Types = viper.GetStringSlice("commit_conventions.types")
+	}
+	if viper.IsSet("commit_conventions.scopes") {
+		conventions.Scopes = viper.GetStringSlice("commit_conventions.scopes")
+	}
+	if conventions.Conventional && len(conventions.Types) == 0 {
+		conventions.Types = ai.DefaultConventions().Types
+	}
+	if useGitmoji() {
+		conventions.Emoji = "unicode"
+		if gitmojiShortcodes() {
+			conventions.Emoji = "shortcode"
+		}
+		for _, g := range gitmoji.All {
+			conventions.EmojiChoices = append(conventions.EmojiChoices, g.String(gitmojiShortcodes())+" "+g.Description)
+		}
+	}
+	conventions.Instructions = viper.GetString("commit_conventions.instructions")
+	ticket, err := currentTicket()
+	if err != nil {
+		return nil, err
+	}
+	conventions.Ticket = ticket
+	return &conventions, nil
+}
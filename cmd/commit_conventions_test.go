@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/ai"
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitConventions(t *testing.T) {
+	defer viper.Reset()
+	r := testutil.NewRepo(t)
+	r.Chdir()
+
+	// Too few commits to learn from
+	r.Commit("Added readme", "README.md", "readme\n")
+	assert.Equal(t, ai.DefaultConventions().Types, mustConventions(t).Types)
+
+	for i, subject := range []string{"[WEB-1] Added search", "[WEB-2] Fixed crash", "[WEB-3] Updated docs", "[WEB-4] Removed flag"} {
+		r.Commit(subject, "file.txt", string(rune('a'+i)))
+	}
+	r.Checkout("WEB-5-login")
+	conventions := mustConventions(t)
+	assert.False(t, conventions.Conventional)
+	assert.Equal(t, "past", conventions.Mood)
+	assert.Equal(t, "[%s] ", conventions.TicketFormat)
+	assert.Equal(t, "WEB-5", conventions.Ticket)
+	assert.Equal(t, "[WEB-4] Removed flag", conventions.Examples[0])
+
+	viper.Set("commit_conventions.conventional", true)
+	viper.Set("commit_conventions.scopes", []string{"web"})
+	viper.Set("commit_conventions.instructions", "Mention the page")
+	conventions = mustConventions(t)
+	assert.True(t, conventions.Conventional)
+	assert.Equal(t, ai.DefaultConventions().Types, conventions.Types)
+	assert.Equal(t, []string{"web"}, conventions.Scopes)
+	assert.Equal(t, "Mention the page", conventions.Instructions)
+
+	viper.Set("commit_conventions.learn", false)
+	assert.Equal(t, "imperative", mustConventions(t).Mood)
+
+	viper.Set("commit_template.ticket_pattern", "[A-Z+")
+	_, err := commitConventions()
+	assert.ErrorContains(t, err, "invalid commit_template.ticket_pattern")
+}
+
+func mustConventions(t *testing.T) *ai.Conventions {
+	t.Helper()
+	conventions, err := commitConventions()
+	require.NoError(t, err)
+	return conventions
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	defaultCommitTemplate = `{{.Type}}{{if .Scope}}({{.Scope}}){{end}}{{if .Breaking}}!{{end}}: {{.Summary}}`
+	defaultTicketPattern  = `[A-Z][A-Z0-9]+-[0-9]+`
+)
+
+// CommitFields are the values available to the commit_template format
+type CommitFields struct {
+	Type    string
+	Scope   string
+	Ticket  string
+	Summary string
+	// Breaking is set by the "!" of "feat(api)!: drop v1"
+	Breaking bool
+}
+
+var conventionalHeader = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!?):\s*(.*)$`)
+
+// parseConventionalHeader splits a "type(scope): summary" line into its parts.
+// Lines that don't follow the convention are returned as the summary.
+func parseConventionalHeader(line string) CommitFields {
+	matches := conventionalHeader.FindStringSubmatch(strings.TrimSpace(line))
+	if matches == nil {
+		return CommitFields{Summary: strings.TrimSpace(line)}
+	}
+	return CommitFields{Type: matches[1], Scope: matches[2], Breaking: matches[3] == "!", Summary: matches[4]}
+}
+
+// extractTicket finds a ticket ID such as JIRA-123 in a branch name
+func extractTicket(branch, pattern string) (string, error) {
+	if pattern == "" {
+		pattern = defaultTicketPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid commit_template.ticket_pattern: %w", err)
+	}
+	return re.FindString(branch), nil
+}
+
+func renderCommitTemplate(format string, fields CommitFields) (string, error) {
+	if format == "" {
+		format = defaultCommitTemplate
+	}
+	tmpl, err := template.New("commit").Parse(format)
+	if err != nil {
+		return "", fmt.Errorf("invalid commit_template format: %w", err)
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, fields); err != nil {
+		return "", fmt.Errorf("failed to render commit_template: %w", err)
+	}
+	return out.String(), nil
+}
+
+// currentTicket extracts the ticket ID from the current branch name using
+// commit_template.ticket_pattern
+func currentTicket() (string, error) {
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return "", nil
+	}
+	return extractTicket(branch, viper.GetString("commit_template.ticket_pattern"))
+}
+
+// applyCommitTemplate re-renders the header of a generated message through the
+// configured commit_template, keeping any body intact
+func applyCommitTemplate(message string) (string, error) {
+	header, body, _ := strings.Cut(message, "\n")
+	fields := parseConventionalHeader(header)
+	if fields.Type == "" {
+		return message, nil
+	}
+	ticket, err := currentTicket()
+	if err != nil {
+		return "", err
+	}
+	fields.Ticket = ticket
+
+	rendered, err := renderCommitTemplate(viper.GetString("commit_template.format"), fields)
+	if err != nil {
+		return "", err
+	}
+	if body != "" {
+		rendered += "\n" + body
+	}
+	return rendered, nil
+}
@@ -4,9 +4,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/EndlessUphill/git-helper/internal/ai"
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupGitRepo(t *testing.T) (string, func()) {
@@ -78,47 +83,178 @@ func TestGenerateCommitMessage(t *testing.T) {
 		name       string
 		summary    string
 		commitType string
-		useAI      bool
-		wantErr    bool
+		message    string
+		want       string
+		wantErr    string
 	}{
 		{
-			name:       "manual commit without type",
-			summary:    "test.txt | 1 +",
-			commitType: "",
-			useAI:      false,
-			wantErr:    false,
+			name:    "manual commit without type",
+			summary: "test.txt | 1 +",
+			message: "add tests",
+			wantErr: "pass --type",
 		},
 		{
-			name:       "manual commit with type",
+			name:       "manual commit without summary",
 			summary:    "test.txt | 1 +",
 			commitType: "feat",
-			useAI:      false,
-			wantErr:    false,
+			wantErr:    "pass --message",
 		},
 		{
-			name:       "AI commit without API key",
-			summary:    "test.txt | 1 +",
-			useAI:      true,
-			wantErr:    true,
+			name:       "manual commit with type and summary",
+			summary:    " test.txt | 1 +\n 1 file changed, 1 insertion(+)\n",
+			commitType: "feat",
+			message:    "add tests",
+			want:       "feat: add tests\n\n# Changes to be committed:\n#  test.txt | 1 +\n#  1 file changed, 1 insertion(+)\n",
 		},
 	}
 
+	defer func() { commitType, commitSummary = "", "" }()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Set up test state
-			useAI = tt.useAI
 			commitType = tt.commitType
+			commitSummary = tt.message
 
 			msg, err := generateCommitMessage(tt.summary)
 
-			if tt.wantErr {
-				assert.Error(t, err)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
 			} else {
 				assert.NoError(t, err)
-				if !tt.useAI {
-					assert.Contains(t, msg, tt.summary)
-				}
+				assert.Contains(t, msg, tt.want)
+				assert.Equal(t, "feat: add tests", stripCommentLines(msg))
 			}
 		})
 	}
-} 
\ No newline at end of file
+}
+
+func TestGenerateAIMessageWithoutAPIKey(t *testing.T) {
+	defer viper.Reset()
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	content := strings.Repeat("// line\n", 10)
+	r.Commit("Base", "old_name.go", content, "README.md", "readme\n")
+	r.Git("mv", "old_name.go", "main.go")
+	r.WriteFile("main.go", content+"// new line\n")
+	r.Git("add", "main.go")
+
+	// Falls back to the offline message, which keeps the stats of renamed files
+	msg, err := generateAIMessage("diff")
+	require.NoError(t, err)
+	offline := ai.OfflineCommitMessage([]ai.FileStat{
+		{Path: "main.go", Status: "A", Added: 11},
+		{Path: "old_name.go", Status: "D", Deleted: 10},
+	})
+	assert.Equal(t, offline, msg)
+}
+
+func TestCommitNonInteractive(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	r.WriteFile("new.txt", "new\n")
+	r.Git("add", "new.txt")
+
+	_, _, err := execute(t, "commit")
+	assert.ErrorContains(t, err, "pass --type")
+	_, _, err = execute(t, "commit", "--type", "feat")
+	assert.ErrorContains(t, err, "pass --message")
+	assert.Equal(t, []string{"Base"}, r.Log("HEAD"))
+
+	_, _, err = execute(t, "commit", "--type", "feat", "--scope", "docs", "-m", "add new.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "feat(docs): add new.txt", r.Git("log", "-1", "--format=%B"))
+}
+
+func TestStripCommentLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{"subject only", "feat: add tests\n\n# Changes to be committed:\n#  a.txt | 1 +\n", "feat: add tests"},
+		{"body is kept", "feat: add\n\n- a.go (+1/-0)\n- b.go (+1/-0)\n", "feat: add\n\n- a.go (+1/-0)\n- b.go (+1/-0)"},
+		{"blank lines collapsed and trimmed", "\n\nfix: x  \n\n\n\nbody\n# comment\n\n", "fix: x\n\nbody"},
+		{"indented hash is text", "docs: x\n\n  # heading\n", "docs: x\n\n  # heading"},
+		{"only comments", "# nothing\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stripCommentLines(tt.message))
+		})
+	}
+}
+
+func TestCommitOfflineKeepsBody(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	r.WriteFile("pkg/a.go", "package pkg\n")
+	r.WriteFile("pkg/b.go", "package pkg\n")
+	r.Git("add", "pkg")
+
+	_, _, err := execute(t, "commit", "--ai", "--offline", "--no-edit")
+	require.NoError(t, err)
+	subject := r.Git("log", "-1", "--format=%s")
+	assert.NotContains(t, subject, "pkg/a.go")
+	assert.Contains(t, subject, "2 files")
+	assert.Equal(t, "- pkg/a.go (+1/-0)\n- pkg/b.go (+1/-0)", r.Git("log", "-1", "--format=%b"))
+}
+
+func TestCommentLines(t *testing.T) {
+	assert.Equal(t, "#  a.txt | 1 +\n#  1 file changed\n", commentLines(" a.txt | 1 +\n 1 file changed\n"))
+	assert.Equal(t, "# a\n#\n# b\n", commentLines("a\n\nb"))
+}
+
+func TestExtractTicket(t *testing.T) {
+	ticket := func(branch, pattern string) string {
+		ticket, err := extractTicket(branch, pattern)
+		require.NoError(t, err)
+		return ticket
+	}
+	assert.Equal(t, "JIRA-123", ticket("feature/JIRA-123-login-page", ""))
+	assert.Equal(t, "", ticket("fix-login-page", ""))
+	assert.Equal(t, "#42", ticket("issue-#42", "#[0-9]+"))
+
+	_, err := extractTicket("issue-#42", "#[0-9")
+	assert.ErrorContains(t, err, "invalid commit_template.ticket_pattern")
+}
+
+func TestRenderCommitTemplate(t *testing.T) {
+	fields := parseConventionalHeader("feat(auth): add OAuth2 login")
+	assert.Equal(t, CommitFields{Type: "feat", Scope: "auth", Summary: "add OAuth2 login"}, fields)
+
+	msg, err := renderCommitTemplate("", fields)
+	assert.NoError(t, err)
+	assert.Equal(t, "feat(auth): add OAuth2 login", msg)
+
+	fields.Ticket = "JIRA-123"
+	msg, err = renderCommitTemplate("{{.Type}}: [{{.Ticket}}] {{.Summary}}", fields)
+	assert.NoError(t, err)
+	assert.Equal(t, "feat: [JIRA-123] add OAuth2 login", msg)
+
+	_, err = renderCommitTemplate("{{.Type", fields)
+	assert.Error(t, err)
+}
+
+func TestApplyCommitTemplateKeepsBreakingChanges(t *testing.T) {
+	defer viper.Reset()
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+
+	fields := parseConventionalHeader("feat(api)!: drop v1")
+	assert.Equal(t, CommitFields{Type: "feat", Scope: "api", Breaking: true, Summary: "drop v1"}, fields)
+
+	msg, err := applyCommitTemplate("feat(api)!: drop v1\n\nBREAKING CHANGE: v1 is gone")
+	require.NoError(t, err)
+	assert.Equal(t, "feat(api)!: drop v1\n\nBREAKING CHANGE: v1 is gone", msg)
+
+	msg, err = applyCommitTemplate("fix: handle nil")
+	require.NoError(t, err)
+	assert.Equal(t, "fix: handle nil", msg)
+
+	viper.Set("commit_template.ticket_pattern", "[A-Z+")
+	_, err = applyCommitTemplate("fix: handle nil")
+	assert.ErrorContains(t, err, "invalid commit_template.ticket_pattern")
+}
@@ -1,6 +1,11 @@
 package cmd
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/EndlessUphill/git-helper/internal/git"
+	"github.com/spf13/cobra"
+)
 
 type ReflogEntry struct {
 	Hash        string
@@ -13,8 +18,35 @@ var (
 	force      bool
 	dryRun     bool
 	useAI      bool
+	signOff    bool
+	gpgSign    string
 )
 
+// addSigningFlags registers --signoff and --gpg-sign on commands that create commits.
+// Like git's -S, the key ID is optional, so it has to be attached with "=":
+// in "-S KEYID" the key ID would be taken as an argument.
+func addSigningFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&signOff, "signoff", "s", false, "add a Signed-off-by trailer (DCO)")
+	cmd.Flags().StringVarP(&gpgSign, "gpg-sign", "S", "", "GPG/SSH-sign the commit; choose the key with -S=KEYID or --gpg-sign=KEYID")
+	cmd.Flags().Lookup("gpg-sign").NoOptDefVal = "default"
+}
+
+// commitSigningArgs returns the extra git commit arguments for the signing flags
+func commitSigningArgs() []string {
+	var args []string
+	if signOff {
+		args = append(args, "--signoff")
+	}
+	switch gpgSign {
+	case "":
+	case "default":
+		args = append(args, "--gpg-sign")
+	default:
+		args = append(args, "--gpg-sign="+gpgSign)
+	}
+	return args
+}
+
 func formatSize(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
@@ -26,4 +58,13 @@ func formatSize(bytes int64) string {
 		exp++
 	}
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
-} 
\ No newline at end of file
+}
+
+// describeRepoSize formats the size of a repository with its split between
+// the git directory and the working tree
+func describeRepoSize(size *git.Size) string {
+	if size.WorkTree == 0 {
+		return formatSize(size.GitDir)
+	}
+	return fmt.Sprintf("%s (.git %s, working tree %s)", formatSize(size.Total()), formatSize(size.GitDir), formatSize(size.WorkTree))
+}
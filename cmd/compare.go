@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	compareDepth int
+	compareLimit int
+	compareFiles bool
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <base> [head]",
+	Short: "Summarize the differences between two branches",
+	Long: `Compare two branches (or any commits): the commits only on each side, the
+changed files grouped by directory and a summary of the changes.
+
+Like a GitHub comparison, the file changes are those of head since it forked
+from base. Head defaults to the current branch. With --files, pick changed
+files to see their diff in the pager.
+
+Example:
+  githelper compare main                  # main vs the current branch
+  githelper compare main feature
+  githelper compare v1.2.0 v1.3.0 --depth 2
+  githelper compare main feature --files  # Browse the file diffs`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runCompare,
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+	compareCmd.Flags().IntVar(&compareDepth, "depth", 1, "directory depth of the summary")
+	compareCmd.Flags().IntVarP(&compareLimit, "limit", "n", 10, "maximum number of commits to list per side")
+	compareCmd.Flags().BoolVar(&compareFiles, "files", false, "select changed files to view their diff")
+	compareCmd.Flags().BoolVar(&noFzf, "no-fzf", false, "disable fzf usage even if available")
+}
+
+// FileChange is a file changed between two commits
+type FileChange struct {
+	Path    string
+	OldPath string
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+// DirSummary is the changes to the files of a directory
+type DirSummary struct {
+	Dir     string
+	Files   int
+	Added   int
+	Deleted int
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	base, head := args[0], "HEAD"
+	if len(args) > 1 {
+		head = args[1]
+	}
+	for _, rev := range []string{base, head} {
+		if gitCommand("rev-parse", "--verify", "-q", rev+"^{commit}").Run() != nil {
+			return fmt.Errorf("'%s' is not a branch or commit", rev)
+		}
+	}
+	if compareDepth < 1 {
+		return fmt.Errorf("--depth must be at least 1")
+	}
+
+	onlyBase, onlyHead, err := aheadBehind(base, head)
+	if err != nil {
+		return err
+	}
+	ui.Stepf("🔀 Comparing %s...%s", base, head)
+	printCompareCommits(fmt.Sprintf("📤 %d commit(s) only in %s:", onlyHead, head), base+".."+head, onlyHead)
+	printCompareCommits(fmt.Sprintf("📥 %d commit(s) only in %s:", onlyBase, base), head+".."+base, onlyBase)
+
+	changes, err := changedFiles(base + "..." + head)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		ui.Success("\nNo file changes")
+		return nil
+	}
+
+	ui.Step("\n📁 Changed files by directory:")
+	summaries := summarizeByDirectory(changes, compareDepth)
+	width := 0
+	maxChurn := 0
+	for _, summary := range summaries {
+		width = max(width, len(summary.Dir))
+		maxChurn = max(maxChurn, summary.Added+summary.Deleted)
+	}
+	for _, summary := range summaries {
+		percent := float64(summary.Added+summary.Deleted) * 100 / float64(max(maxChurn, 1))
+		ui.Printf("  %-*s %4d file(s) %7s %7s %s\n", width, summary.Dir, summary.Files,
+			"+"+strconv.Itoa(summary.Added), "-"+strconv.Itoa(summary.Deleted), bar(percent, 20))
+	}
+
+	added, deleted := 0, 0
+	for _, change := range changes {
+		added += change.Added
+		deleted += change.Deleted
+	}
+	ui.Stepf("\n📊 %d file(s) changed, %d insertion(s)(+), %d deletion(s)(-)", len(changes), added, deleted)
+
+	if compareFiles {
+		return browseFileDiffs(base+"..."+head, changes)
+	}
+	return nil
+}
+
+func printCompareCommits(title, revRange string, count int) {
+	ui.Printf("\n%s\n", title)
+	if count == 0 {
+		return
+	}
+	output, _ := gitCommand("log", "--format=    %h %s", "-n", strconv.Itoa(compareLimit), revRange).Output()
+	ui.Print(string(output))
+	if count > compareLimit {
+		ui.Printf("    ... and %d more\n", count-compareLimit)
+	}
+}
+
+// changedFiles lists the files changed in a diff range with their line counts
+func changedFiles(diffRange string) ([]FileChange, error) {
+	output, err := gitCommand("diff", "--numstat", "-z", "-M", diffRange).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s: %w", diffRange, err)
+	}
+	return parseNumstat(string(output)), nil
+}
+
+// parseNumstat parses 'git diff --numstat -z'. Records are
+// "added\tdeleted\tpath\0", or "added\tdeleted\t\0old\0new\0" for renames;
+// binary files have "-" counts.
+func parseNumstat(output string) []FileChange {
+	fields := strings.Split(output, "\x00")
+	var changes []FileChange
+	for i := 0; i < len(fields); i++ {
+		parts := strings.SplitN(fields[i], "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		change := FileChange{Path: parts[2], Binary: parts[0] == "-"}
+		change.Added, _ = strconv.Atoi(parts[0])
+		change.Deleted, _ = strconv.Atoi(parts[1])
+		if change.Path == "" && i+2 < len(fields) {
+			change.OldPath, change.Path = fields[i+1], fields[i+2]
+			i += 2
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// summarizeByDirectory groups changes by their directory cut at depth, most
+// changed lines first. Files in the root are grouped under ".".
+func summarizeByDirectory(changes []FileChange, depth int) []DirSummary {
+	byDir := map[string]*DirSummary{}
+	for _, change := range changes {
+		dir := path.Dir(change.Path)
+		if parts := strings.Split(dir, "/"); dir != "." && len(parts) > depth {
+			dir = strings.Join(parts[:depth], "/")
+		}
+		if dir != "." {
+			dir += "/"
+		}
+		summary, ok := byDir[dir]
+		if !ok {
+			summary = &DirSummary{Dir: dir}
+			byDir[dir] = summary
+		}
+		summary.Files++
+		summary.Added += change.Added
+		summary.Deleted += change.Deleted
+	}
+
+	summaries := make([]DirSummary, 0, len(byDir))
+	for _, summary := range byDir {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if a.Added+a.Deleted != b.Added+b.Deleted {
+			return a.Added+a.Deleted > b.Added+b.Deleted
+		}
+		return a.Dir < b.Dir
+	})
+	return summaries
+}
+
+// browseFileDiffs lets the user pick changed files and shows their diff in
+// the pager until they cancel
+func browseFileDiffs(diffRange string, changes []FileChange) error {
+	if !isInteractive() {
+		return fmt.Errorf("--files needs an interactive terminal")
+	}
+	useFzf := false
+	if !noFzf {
+		_, err := exec.LookPath("fzf")
+		useFzf = err == nil
+	}
+
+	for {
+		var change *FileChange
+		var err error
+		if useFzf {
+			change, err = selectChangeWithFzf(diffRange, changes)
+		} else {
+			change, err = selectChangeWithList(changes)
+		}
+		if err != nil || change == nil {
+			return err
+		}
+
+		diffArgs := []string{"diff", "-M", diffRange, "--"}
+		if change.OldPath != "" {
+			diffArgs = append(diffArgs, change.OldPath)
+		}
+		diffCmd := gitCommand(append(diffArgs, change.Path)...)
+		diffCmd.Stdin = os.Stdin
+		diffCmd.Stdout = os.Stdout
+		diffCmd.Stderr = os.Stderr
+		if err := diffCmd.Run(); err != nil {
+			return fmt.Errorf("failed to show diff: %w", err)
+		}
+	}
+}
+
+func changeLine(change FileChange) string {
+	name := change.Path
+	if change.OldPath != "" {
+		name = change.OldPath + " → " + change.Path
+	}
+	if change.Binary {
+		return name + " (binary)"
+	}
+	return fmt.Sprintf("%s (+%d -%d)", name, change.Added, change.Deleted)
+}
+
+func selectChangeWithFzf(diffRange string, changes []FileChange) (*FileChange, error) {
+	var input strings.Builder
+	for i, change := range changes {
+		fmt.Fprintf(&input, "%d\t%s\t%s\n", i, changeLine(change), change.Path)
+	}
+
+	fzfCmd := exec.Command("fzf",
+		"--height", "80%",
+		"--reverse",
+		"--delimiter", "\t",
+		"--with-nth", "2",
+		"--header", "Select a file to view its diff (Esc to quit)",
+		"--preview", fmt.Sprintf("git diff --stat --color=always -M %s -- {3}", shellQuote(diffRange)),
+		"--preview-window", "down:3")
+
+	fzfCmd.Stdin = strings.NewReader(input.String())
+	fzfCmd.Stderr = os.Stderr
+
+	output, err := fzfCmd.Output()
+	if err != nil {
+		return nil, nil // User cancelled
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &index); err != nil || index < 0 || index >= len(changes) {
+		return nil, fmt.Errorf("invalid selection")
+	}
+	return &changes[index], nil
+}
+
+func selectChangeWithList(changes []FileChange) (*FileChange, error) {
+	ui.Println("\nChanged files:")
+	for i, change := range changes {
+		ui.Printf("%3d: %s\n", i+1, changeLine(change))
+	}
+
+	input := readInput("\nSelect file number to view its diff (or press Enter to quit): ")
+	if input == "" {
+		return nil, nil
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(input, "%d", &index); err != nil || index < 1 || index > len(changes) {
+		return nil, fmt.Errorf("invalid selection")
+	}
+	return &changes[index-1], nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNumstat(t *testing.T) {
+	output := "10\t2\tcmd/root.go\x00-\t-\tlogo.png\x003\t1\t\x00old/name.go\x00new/name.go\x00"
+	changes := parseNumstat(output)
+	require.Len(t, changes, 3)
+	assert.Equal(t, FileChange{Path: "cmd/root.go", Added: 10, Deleted: 2}, changes[0])
+	assert.True(t, changes[1].Binary)
+	assert.Equal(t, FileChange{Path: "new/name.go", OldPath: "old/name.go", Added: 3, Deleted: 1}, changes[2])
+}
+
+func TestSummarizeByDirectory(t *testing.T) {
+	changes := []FileChange{
+		{Path: "README.md", Added: 1},
+		{Path: "cmd/root.go", Added: 10, Deleted: 5},
+		{Path: "internal/git/git.go", Added: 20},
+		{Path: "internal/github/pr.go", Added: 3},
+	}
+	assert.Equal(t, []DirSummary{
+		{Dir: "internal/", Files: 2, Added: 23},
+		{Dir: "cmd/", Files: 1, Added: 10, Deleted: 5},
+		{Dir: ".", Files: 1, Added: 1},
+	}, summarizeByDirectory(changes, 1))
+
+	summaries := summarizeByDirectory(changes, 2)
+	assert.Equal(t, "internal/git/", summaries[0].Dir)
+}
+
+func TestChangedFiles(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "initial")
+	runGit(t, tmpDir, "branch", "base")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.MkdirAll("src", 0755))
+	require.NoError(t, os.WriteFile("src/a.go", []byte("package src\n\nfunc A() {}\n"), 0644))
+	runGit(t, tmpDir, "add", ".")
+	runGit(t, tmpDir, "commit", "-m", "add a")
+
+	changes, err := changedFiles("base...HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []FileChange{{Path: "src/a.go", Added: 3}}, changes)
+}
@@ -0,0 +1,508 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	configLocal bool
+	showSecrets bool
+)
+
+// ConfigKey describes a setting githelper understands
+type ConfigKey struct {
+	Key         string
+	Type        string // string, bool, int or list
+	Secret      bool
+	Description string
+}
+
+// configKeys lists the known settings. A "*" segment matches any name, such
+// as the hostname in hosts.*.token.
+var configKeys = []ConfigKey{
+	{Key: "github_token", Type: "string", Secret: true, Description: "GitHub token for the default host"},
+	{Key: "openai_api_key", Type: "string", Secret: true, Description: "OpenAI API key for --ai"},
+	{Key: "default_org", Type: "string", Description: "default organization"},
+	{Key: "debug", Type: "bool", Description: "enable debug logging"},
+	{Key: "non_interactive", Type: "bool", Description: "never prompt"},
+	{Key: "no_emoji", Type: "bool", Description: "plain text output without emoji, colors or box drawing, like --plain"},
+	{Key: "theme", Type: "string", Description: "colors and icons of messages: default, colorblind, high-contrast or ascii"},
+	{Key: "no_color", Type: "bool", Description: "don't color the output, like --no-color"},
+	{Key: "language", Type: "string", Description: "language of translated messages (sync and shared prompts), e.g. en or es (default from LANG)"},
+	{Key: "use_ssh", Type: "bool", Description: "use SSH URLs for git operations"},
+	{Key: "default_branch", Type: "string", Description: "main branch of the repository when origin/HEAD is wrong (use with --local)"},
+	{Key: "default_host", Type: "string", Description: "GitHub host used by default"},
+	{Key: "oauth_client_id", Type: "string", Description: "OAuth app for 'auth login' on github.com"},
+	{Key: "hosts.*.api_url", Type: "string", Description: "REST API URL of an Enterprise host"},
+	{Key: "hosts.*.upload_url", Type: "string", Description: "upload API URL of an Enterprise host"},
+	{Key: "hosts.*.token", Type: "string", Secret: true, Description: "token for a host"},
+	{Key: "hosts.*.client_id", Type: "string", Description: "OAuth app for 'auth login' on a host"},
+	{Key: "profile", Type: "string", Description: "active profile"},
+	{Key: "profiles.*.github_token", Type: "string", Secret: true, Description: "token used by a profile"},
+	{Key: "profiles.*.default_org", Type: "string", Description: "default organization of a profile"},
+	{Key: "profiles.*.host", Type: "string", Description: "default host of a profile"},
+	{Key: "profiles.*.use_ssh", Type: "bool", Description: "SSH preference of a profile"},
+	{Key: "profiles.*.user_name", Type: "string", Description: "commit author name of a profile"},
+	{Key: "profiles.*.user_email", Type: "string", Description: "commit author email of a profile"},
+	{Key: "aliases.*", Type: "string", Description: "githelper command line run by 'githelper run <alias>'"},
+	{Key: "workflows.*.description", Type: "string", Description: "description of a workflow"},
+	{Key: "workflows.*.params", Type: "list", Description: "parameters of a workflow"},
+	{Key: "workflows.*.steps", Type: "list", Description: "git and githelper steps of a workflow"},
+	{Key: "hooks.*.pre", Type: "list", Description: "shell commands run before a githelper command; one failing stops it"},
+	{Key: "hooks.*.post", Type: "list", Description: "shell commands run after a githelper command succeeded"},
+	{Key: "hooks.*.on_error", Type: "list", Description: "shell commands run after a githelper command failed"},
+	{Key: "commit_template.format", Type: "string", Description: "template for commit message headers"},
+	{Key: "commit_template.ticket_pattern", Type: "string", Description: "regexp extracting tickets from branch names"},
+	{Key: "use_gitmoji", Type: "bool", Description: "start commit messages with a gitmoji"},
+	{Key: "gitmoji_format", Type: "string", Description: "write gitmoji as emoji (default) or shortcode"},
+	{Key: "commit_conventions.learn", Type: "bool", Description: "'commit --ai' learns the commit conventions from the history (default true)"},
+	{Key: "commit_conventions.sample", Type: "int", Description: "recent commits 'commit --ai' learns from (default 100)"},
+	{Key: "commit_conventions.conventional", Type: "bool", Description: "'commit --ai' writes conventional commit subjects"},
+	{Key: "commit_conventions.types", Type: "list", Description: "conventional types 'commit --ai' uses"},
+	{Key: "commit_conventions.scopes", Type: "list", Description: "scopes 'commit --ai' prefers"},
+	{Key: "commit_conventions.instructions", Type: "string", Description: "extra instructions for 'commit --ai'"},
+	{Key: "safety.protected_branches", Type: "list", Description: "branches history rewrites refuse to touch"},
+	{Key: "safety.confirm_repo_name", Type: "bool", Description: "type the repo name to confirm dangerous operations"},
+	{Key: "safety.dangerous_operations", Type: "list", Description: "operations needing the repo name confirmation"},
+	{Key: "backup.enabled", Type: "bool", Description: "bundle the repository before clean and purge rewrite history"},
+	{Key: "backup.keep", Type: "int", Description: "backup bundles kept per repository"},
+	{Key: "backup.dir", Type: "string", Description: "directory for backup bundles"},
+	{Key: "gc.after_rewrite", Type: "bool", Description: "run 'githelper gc' after clean and purge rewrite history"},
+	{Key: "rewrite.push", Type: "bool", Description: "force push after clean, purge and rewrite-author, like --push"},
+	{Key: "rewrite.remote", Type: "string", Description: "remote rewritten history is pushed to (default origin)"},
+	{Key: "notify.webhooks", Type: "list", Description: "Slack, Discord or JSON webhook URLs told when long operations such as mirror syncs and history rewrites finish"},
+	{Key: "notify.desktop", Type: "bool", Description: "show a desktop notification when long operations finish, as if --notify was given"},
+	{Key: "notify.min_duration", Type: "string", Description: "shortest operation notify.webhooks and notify.desktop tell about, e.g. 1m (default 0)"},
+	{Key: "clipboard.command", Type: "list", Description: "program and arguments --copy pipes the text to, instead of the platform's clipboard tool"},
+	{Key: "lint_history.no_merges", Type: "bool", Description: "'githelper lint-history' rejects merge commits (default true)"},
+	{Key: "lint_history.no_fixups", Type: "bool", Description: "'githelper lint-history' rejects fixup!/squash! commits (default true)"},
+	{Key: "lint_history.conventional", Type: "bool", Description: "'githelper lint-history' requires conventional commit subjects"},
+	{Key: "lint_history.types", Type: "list", Description: "conventional commit types 'githelper lint-history' allows"},
+	{Key: "lint_history.max_subject_length", Type: "int", Description: "longest subject 'githelper lint-history' allows (default 72, 0 disables)"},
+	{Key: "lint_history.signed", Type: "bool", Description: "'githelper lint-history' requires signed commits"},
+	{Key: "precheck.commands", Type: "list", Description: "linters and tests 'githelper precheck' runs, as shell command lines"},
+	{Key: "precheck.debug_patterns", Type: "list", Description: "regexps of debug statements 'githelper precheck' rejects, besides the built-in ones"},
+	{Key: "clean_workdir.patterns", Type: "list", Description: "build artifact patterns 'githelper clean-workdir' removes"},
+	{Key: "clone.worktree_branches", Type: "list", Description: "branches and patterns 'githelper clone --worktrees' checks out (default release/*)"},
+	{Key: "clone.release_worktrees", Type: "int", Description: "newest branches each clone.worktree_branches pattern checks out (default 2)"},
+	{Key: "branch_policy.template", Type: "string", Description: "template of branch names, e.g. {type}/{ticket}-{slug} (default)"},
+	{Key: "branch_policy.types", Type: "list", Description: "{type}s branch names may use"},
+	{Key: "branch_policy.require_ticket", Type: "bool", Description: "branch names must include a {ticket}"},
+	{Key: "branch_policy.pattern", Type: "string", Description: "regexp branch names must match instead of the template"},
+	{Key: "branch_policy.exempt", Type: "list", Description: "branches the branch policy ignores (default main, master, develop)"},
+	{Key: "sync.strategy", Type: "string", Description: "default strategy for diverged branches in 'githelper sync'"},
+	{Key: "push.max_file_size", Type: "string", Description: "largest file 'githelper push' accepts, e.g. 50MB"},
+	{Key: "policy.max_file_size", Type: "string", Description: "largest file 'githelper policy check' accepts (default 100MB)"},
+	{Key: "policy.forbidden_paths", Type: "list", Description: "gitignore-style patterns commits may not add or change"},
+	{Key: "policy.email_domains", Type: "list", Description: "author and committer email domains 'githelper policy check' accepts"},
+	{Key: "policy.secrets", Type: "bool", Description: "'githelper policy check' rejects added secrets (default true)"},
+	{Key: "mirror.webhook_secret", Type: "string", Secret: true, Description: "secret verifying webhooks sent to 'githelper mirror --listen'"},
+	{Key: "history", Type: "bool", Description: "record commands for 'githelper log' (default true)"},
+	{Key: "sparse_profiles.*", Type: "list", Description: "directories 'githelper sparse use <profile>' checks out"},
+	{Key: "watch.interval", Type: "string", Description: "time between 'githelper watch' checkpoints, e.g. 2m (default 5m)"},
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Read and write githelper settings",
+	Long: `Manage githelper settings without hand-editing YAML.
+
+Settings are written to ~/.githelper.yaml, or with --local to the
+.githelper.yaml at the root of the current repository. Only known keys are
+accepted, and secrets such as tokens are masked in the output.
+
+Example:
+  githelper config list                          # Show all settings
+  githelper config get safety.protected_branches
+  githelper config set default_org my-org
+  githelper config set --local safety.protected_branches main,release/*
+  githelper config set hosts.github.example.com.api_url https://github.example.com/api/v3/
+  githelper config edit                          # Open the file in your editor`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting (lists are comma-separated)",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigSet,
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigList,
+}
+
+var configEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Open the config file in your editor",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigEdit,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd, configSetCmd, configListCmd, configEditCmd)
+	configCmd.PersistentFlags().BoolVar(&configLocal, "local", false, "use the repository's .githelper.yaml")
+	configGetCmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "print secrets unmasked")
+	configListCmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "print secrets unmasked")
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	path := splitConfigKey(args[0])
+	known, err := lookupConfigKey(path)
+	if err != nil {
+		return err
+	}
+
+	var settings map[string]interface{}
+	if configLocal {
+		file, err := localConfigPath()
+		if err != nil {
+			return err
+		}
+		if settings, err = readConfigSettings(file); err != nil {
+			return err
+		}
+	} else {
+		settings = viper.AllSettings()
+	}
+
+	flat := make(map[string]interface{})
+	flattenSettings("", settings, flat)
+	value, ok := flat[strings.Join(path, ".")]
+	if !ok {
+		return fmt.Errorf("%s is not set", args[0])
+	}
+	ui.Println(formatSetting(value, known.Secret))
+	return nil
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	path := splitConfigKey(args[0])
+	known, err := lookupConfigKey(path)
+	if err != nil {
+		return err
+	}
+	if known.Secret && configLocal {
+		return fmt.Errorf("%s is a secret and the repository config is usually committed. Set it without --local", args[0])
+	}
+	if configLocal && !repoConfigAllows(known.Key) {
+		return fmt.Errorf("%s is ignored in the repository config, as a cloned repository must not change it. Set it without --local", args[0])
+	}
+
+	value, err := parseConfigValue(known, args[1])
+	if err != nil {
+		return err
+	}
+
+	file, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	if err := writeConfigValue(file, path, value); err != nil {
+		return err
+	}
+
+	ui.Successf("Set %s in %s", args[0], file)
+	return nil
+}
+
+func runConfigList(cmd *cobra.Command, args []string) error {
+	settings := viper.AllSettings()
+	source := "effective settings"
+	if configLocal {
+		file, err := localConfigPath()
+		if err != nil {
+			return err
+		}
+		if settings, err = readConfigSettings(file); err != nil {
+			return err
+		}
+		source = file
+	}
+
+	flat := make(map[string]interface{})
+	flattenSettings("", settings, flat)
+	if len(flat) == 0 {
+		ui.Printf("No settings in %s\n", source)
+		return nil
+	}
+
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		secret := false
+		if known, err := lookupConfigKey(splitConfigKey(key)); err == nil {
+			secret = known.Secret
+		}
+		ui.Printf("%s=%s\n", key, formatSetting(flat[key], secret))
+	}
+	return nil
+}
+
+func runConfigEdit(cmd *cobra.Command, args []string) error {
+	file, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		if err := os.WriteFile(file, []byte("# githelper configuration\n"), 0600); err != nil {
+			return fmt.Errorf("failed to create config file: %w", err)
+		}
+	}
+
+	editor := editorCommand(file)
+	if err := editor.Run(); err != nil {
+		return fmt.Errorf("failed to open editor: %w", err)
+	}
+
+	// Check the result so mistakes surface now rather than on the next command
+	settings, err := readConfigSettings(file)
+	if err != nil {
+		return err
+	}
+	flat := make(map[string]interface{})
+	flattenSettings("", settings, flat)
+	for key := range flat {
+		if _, err := lookupConfigKey(splitConfigKey(key)); err != nil {
+			ui.Warnf("%v", err)
+		}
+	}
+	return nil
+}
+
+// configFilePath returns the file that set and edit write to
+func configFilePath() (string, error) {
+	if configLocal {
+		return localConfigPath()
+	}
+	if file := viper.ConfigFileUsed(); file != "" {
+		return file, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".githelper.yaml"), nil
+}
+
+func localConfigPath() (string, error) {
+	if err := checkGitRepo(); err != nil {
+		return "", err
+	}
+	root, err := getRepoRoot()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(root, ".githelper.yaml"), nil
+}
+
+// splitConfigKey splits a dotted key into its path. The hostname in
+// hosts.<host>.<field> may itself contain dots.
+func splitConfigKey(key string) []string {
+	key = strings.ToLower(key)
+	if rest, ok := strings.CutPrefix(key, "hosts."); ok {
+		if i := strings.LastIndex(rest, "."); i > 0 {
+			return []string{"hosts", rest[:i], rest[i+1:]}
+		}
+	}
+	return strings.Split(key, ".")
+}
+
+func lookupConfigKey(path []string) (ConfigKey, error) {
+	for _, known := range configKeys {
+		pattern := strings.Split(known.Key, ".")
+		if len(pattern) != len(path) {
+			continue
+		}
+		matches := true
+		for i := range pattern {
+			if pattern[i] != "*" && pattern[i] != path[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return known, nil
+		}
+	}
+	return ConfigKey{}, fmt.Errorf("unknown config key '%s'. Run 'githelper config list' or see the README for known keys", strings.Join(path, "."))
+}
+
+func parseConfigValue(known ConfigKey, raw string) (interface{}, error) {
+	switch known.Type {
+	case "bool":
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects true or false, got '%s'", known.Key, raw)
+		}
+		return value, nil
+	case "int":
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s expects a number, got '%s'", known.Key, raw)
+		}
+		return value, nil
+	case "list":
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+	return raw, nil
+}
+
+func readConfigSettings(file string) (map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return settings, nil
+}
+
+// writeConfigValue sets a value in a YAML file, keeping its comments and
+// key order
+func writeConfigValue(file string, path []string, value interface{}) error {
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	updated, err := setYAMLValue(data, path, value)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", file, err)
+	}
+	if err := os.WriteFile(file, updated, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+func setYAMLValue(data []byte, path []string, value interface{}) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return nil, err
+	}
+
+	node := doc.Content[0]
+	for i, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s is not a section", strings.Join(path[:i], "."))
+		}
+
+		var child *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if strings.EqualFold(node.Content[j].Value, key) {
+				child = node.Content[j+1]
+				break
+			}
+		}
+
+		last := i == len(path)-1
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+		if last {
+			comment := child.LineComment
+			*child = valueNode
+			child.LineComment = comment
+		}
+		node = child
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// flattenSettings turns nested settings into dotted keys. Hostnames with dots
+// end up the same whether viper nested them by segment or kept them whole.
+func flattenSettings(prefix string, settings map[string]interface{}, flat map[string]interface{}) {
+	for key, value := range settings {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if section, ok := value.(map[string]interface{}); ok {
+			flattenSettings(key, section, flat)
+			continue
+		}
+		flat[key] = value
+	}
+}
+
+func formatSetting(value interface{}, secret bool) string {
+	var text string
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		text = strings.Join(items, ",")
+	case []string:
+		text = strings.Join(v, ",")
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	if secret && !showSecrets {
+		return maskSecret(text)
+	}
+	return text
+}
+
+// maskSecret hides all but the first and last characters of a secret
+func maskSecret(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", 8) + secret[len(secret)-4:]
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitConfigKey(t *testing.T) {
+	assert.Equal(t, []string{"github_token"}, splitConfigKey("github_token"))
+	assert.Equal(t, []string{"safety", "protected_branches"}, splitConfigKey("safety.protected_branches"))
+	assert.Equal(t, []string{"hosts", "github.example.com", "api_url"}, splitConfigKey("hosts.github.example.com.api_url"))
+}
+
+func TestLookupConfigKey(t *testing.T) {
+	known, err := lookupConfigKey(splitConfigKey("hosts.github.example.com.token"))
+	assert.NoError(t, err)
+	assert.True(t, known.Secret)
+
+	_, err = lookupConfigKey(splitConfigKey("safety.unknown"))
+	assert.Error(t, err)
+}
+
+func TestParseConfigValue(t *testing.T) {
+	value, err := parseConfigValue(ConfigKey{Key: "debug", Type: "bool"}, "true")
+	assert.NoError(t, err)
+	assert.Equal(t, true, value)
+
+	_, err = parseConfigValue(ConfigKey{Key: "debug", Type: "bool"}, "maybe")
+	assert.Error(t, err)
+
+	value, err = parseConfigValue(ConfigKey{Key: "safety.protected_branches", Type: "list"}, "main, release/*")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main", "release/*"}, value)
+}
+
+func TestSetYAMLValue(t *testing.T) {
+	input := "# githelper\ndebug: false # keep\nsafety:\n  confirm_repo_name: true\n"
+
+	output, err := setYAMLValue([]byte(input), []string{"debug"}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "# githelper\ndebug: true # keep\nsafety:\n  confirm_repo_name: true\n", string(output))
+
+	output, err = setYAMLValue(output, []string{"hosts", "github.example.com", "token"}, "secret")
+	assert.NoError(t, err)
+	assert.Contains(t, string(output), "hosts:\n  github.example.com:\n    token: secret\n")
+
+	output, err = setYAMLValue(nil, []string{"safety", "protected_branches"}, []string{"main"})
+	assert.NoError(t, err)
+	assert.Equal(t, "safety:\n  protected_branches:\n    - main\n", string(output))
+}
+
+func TestMaskSecret(t *testing.T) {
+	assert.Equal(t, "ghp_********5678", maskSecret("ghp_abcdefgh12345678"))
+	assert.Equal(t, "*****", maskSecret("short"))
+}
+
+func TestRepoConfigOnlyMergesRepositorySettings(t *testing.T) {
+	defer viper.Reset()
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", ".githelper.yaml", `github_token: attacker
+default_host: evil.example.com
+hosts:
+  github.com:
+    api_url: https://evil.example.com/api
+    token: attacker
+safety:
+  protected_branches: [main, release/*]
+aliases:
+  up: sync
+`)
+	require.NoError(t, viper.MergeConfigMap(map[string]interface{}{
+		"github_token": "mine",
+		"hosts":        map[string]interface{}{"github.com": map[string]interface{}{"token": "mine"}},
+	}))
+
+	require.NoError(t, mergeConfigFile(r.Path(".githelper.yaml")))
+	assert.Equal(t, "mine", viper.GetString("github_token"))
+	assert.Equal(t, "mine", viper.GetString("hosts.github.com.token"))
+	assert.Empty(t, viper.GetString("hosts.github.com.api_url"))
+	assert.Empty(t, viper.GetString("default_host"))
+	assert.Equal(t, []string{"main", "release/*"}, viper.GetStringSlice("safety.protected_branches"))
+	// Aliases run commands, so they wait for 'githelper hooks trust'
+	assert.Empty(t, viper.GetString("aliases.up"))
+
+	r.Git("config", hooksTrustedKey, mustChecksum(t))
+	require.NoError(t, mergeConfigFile(r.Path(".githelper.yaml")))
+	assert.Equal(t, "sync", viper.GetString("aliases.up"))
+	assert.Empty(t, viper.GetString("hosts.github.com.api_url"))
+}
+
+func TestRepoConfigAllows(t *testing.T) {
+	assert.True(t, repoConfigAllows("safety.protected_branches"))
+	assert.True(t, repoConfigAllows("default_branch"))
+	assert.True(t, repoConfigAllows("workflows.*.steps"))
+	assert.False(t, repoConfigAllows("hosts.*.api_url"))
+	assert.False(t, repoConfigAllows("github_token"))
+	assert.False(t, repoConfigAllows("default_host"))
+}
+
+func mustChecksum(t *testing.T) string {
+	t.Helper()
+	checksum, err := repoCommandsChecksum()
+	require.NoError(t, err)
+	require.NotEmpty(t, checksum)
+	return checksum
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 
 	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/progress"
 	gh "github.com/google/go-github/v53/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -23,7 +24,14 @@ var copyCmd = &cobra.Command{
 	Use:   "copy [source-repo-url]",
 	Short: "Copy a repository with full history",
 	Long: `Copy a repository including all branches and tags to a new destination.
-Example: githelper copy https://github.com/user/repo --dest newuser/repo`,
+
+The destination is created on the default host (github.com, default_host from
+the config, or --host), so repositories can be copied to and from GitHub
+Enterprise Server.
+
+Example:
+  githelper copy https://github.com/user/repo --dest newuser/repo
+  githelper copy git@github.com:user/repo.git --dest team/repo --host github.example.com`,
 	Args: cobra.ExactArgs(1),
 	RunE: runCopy,
 }
@@ -52,17 +60,16 @@ func init() {
 func runCopy(cmd *cobra.Command, args []string) error {
 	sourceURL := args[0]
 	
-	// Validate GitHub URL format
-	_, err := parseGitHubURL(sourceURL)
-	if err != nil {
-		return err
+	// Validate repository URL format
+	if _, _, err := github.ParseRepoURL(sourceURL); err != nil {
+		return fmt.Errorf("%w. Use HTTPS (https://github.com/user/repo) or SSH (git@github.com:user/repo)", err)
 	}
 
 	if dryRun {
 		return performDryRun(sourceURL, destination)
 	}
 
-	fmt.Printf("🔄 Starting repository copy from %s to %s\n", sourceURL, destination)
+	ui.Stepf("🔄 Starting repository copy from %s to %s", sourceURL, destination)
 
 	// Get system temp directory
 	tmpDir := os.TempDir()
@@ -71,29 +78,25 @@ func runCopy(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create a subdirectory for our operation
-	workDir, err := os.MkdirTemp(tmpDir, "githelper-copy-*")
+	workDir, err := makeTempDir(tmpDir, "githelper-copy-*")
 	if err != nil {
 		return fmt.Errorf("failed to create working directory in %s: %w", tmpDir, err)
 	}
 	defer func() {
 		if err := os.RemoveAll(workDir); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to clean up temporary directory %s: %v\n", workDir, err)
+			ui.Warnf("Failed to clean up temporary directory %s: %v", workDir, err)
 		}
 	}()
 
-	fmt.Printf("📁 Working directory: %s\n", workDir)
+	ui.Stepf("📁 Working directory: %s", workDir)
 
 	// Clone the source repository with mirror flag
-	fmt.Printf("📥 Cloning source repository...\n")
 	if err := cloneMirror(sourceURL, workDir); err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return fmt.Errorf("git clone failed: %s", exitErr.Stderr)
-		}
 		return fmt.Errorf("failed to clone source repository: %w", err)
 	}
 
 	// Create the destination repository
-	fmt.Printf("📝 Creating destination repository...\n")
+	ui.Step("📝 Creating destination repository...")
 	if err := createDestinationRepo(destination, isOrg); err != nil {
 		if ghErr, ok := err.(*gh.ErrorResponse); ok {
 			if ghErr.Response.StatusCode == 422 {
@@ -104,65 +107,51 @@ func runCopy(cmd *cobra.Command, args []string) error {
 	}
 
 	// Push to destination
-	fmt.Printf("📤 Pushing repository content...\n")
 	if err := pushMirror(workDir, destination); err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return fmt.Errorf("git push failed: %s", exitErr.Stderr)
-		}
 		return fmt.Errorf("failed to push to destination: %w", err)
 	}
 
-	fmt.Printf("✅ Successfully copied repository to %s\n", destination)
+	ui.Successf("Successfully copied repository to %s", destination)
+	notifyResult(fmt.Sprintf("Copied %s to %s", args[0], destination))
 	return nil
 }
 
 func performDryRun(sourceURL, dest string) error {
-	fmt.Println("🔍 Dry run - no changes will be made")
-	fmt.Printf("Would perform the following actions:\n\n")
-	fmt.Printf("1. Create temporary directory for cloning\n")
-	fmt.Printf("2. Clone %s with --mirror flag\n", sourceURL)
-	fmt.Printf("3. Create new repository at %s\n", dest)
-	fmt.Printf("   - Private: %v\n", repoConfig.Private)
-	fmt.Printf("   - Description: %s\n", repoConfig.Description)
+	ui.Step("🔍 Dry run - no changes will be made")
+	ui.Printf("Would perform the following actions:\n\n")
+	ui.Printf("1. Create temporary directory for cloning\n")
+	ui.Printf("2. Clone %s with --mirror flag\n", sourceURL)
+	ui.Printf("3. Create new repository at %s on %s\n", dest, defaultHostName())
+	ui.Printf("   - Private: %v\n", repoConfig.Private)
+	ui.Printf("   - Description: %s\n", repoConfig.Description)
 	if len(repoConfig.Topics) > 0 {
-		fmt.Printf("   - Topics: %s\n", strings.Join(repoConfig.Topics, ", "))
+		ui.Printf("   - Topics: %s\n", strings.Join(repoConfig.Topics, ", "))
 	}
-	fmt.Printf("   - Issues enabled: %v\n", repoConfig.HasIssues)
-	fmt.Printf("   - Wiki enabled: %v\n", repoConfig.HasWiki)
-	fmt.Printf("4. Push mirror to destination\n")
-	fmt.Printf("5. Clean up temporary directory\n")
+	ui.Printf("   - Issues enabled: %v\n", repoConfig.HasIssues)
+	ui.Printf("   - Wiki enabled: %v\n", repoConfig.HasWiki)
+	ui.Printf("4. Push mirror to destination\n")
+	ui.Printf("5. Clean up temporary directory\n")
 	return nil
 }
 
 func cloneMirror(sourceURL, dir string) error {
-	cmd := exec.Command("git", "clone", "--mirror", sourceURL, dir)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	cmd := gitCommand("clone", "--mirror", "--progress", sourceURL, dir)
+	return progress.Git(ui.Status(), "📥 Cloning source repository", cmd)
 }
 
 func createDestinationRepo(dest string, isOrg bool) error {
 	ctx := context.Background()
 	
-	// Get GitHub token with more verbose error handling
-	token := viper.GetString("github_token")
-	if token == "" {
-		// Try environment variable directly as fallback
-		token = os.Getenv("GITHELPER_GITHUB_TOKEN")
-		if token == "" {
-			return fmt.Errorf("GitHub token not found. Either:\n" +
-				"1. Set GITHELPER_GITHUB_TOKEN environment variable\n" +
-				"2. Add github_token to ~/.githelper.yaml\n" +
-				"3. Use --config to specify a config file")
-		}
-	}
-
-	if viper.GetBool("debug") {
-		fmt.Printf("Token length: %d\n", len(token))
+	host, err := resolveHost("")
+	if err != nil {
+		return err
 	}
 
 	// Create our internal GitHub client
-	client := github.NewClient(token)
+	client, err := newHostClient(host)
+	if err != nil {
+		return err
+	}
 	
 	// Parse owner and repo name from destination
 	owner, repo, found := strings.Cut(dest, "/")
@@ -180,37 +169,13 @@ func createDestinationRepo(dest string, isOrg bool) error {
 
 func pushMirror(dir, dest string) error {
 	// Allow users to choose their preferred URL format
-	useSSH := viper.GetBool("use_ssh")
-	var destURL string
-	
-	if useSSH {
-		destURL = fmt.Sprintf("git@github.com:%s.git", dest)
-	} else {
-		destURL = fmt.Sprintf("https://github.com/%s.git", dest)
+	host, err := resolveHost("")
+	if err != nil {
+		return err
 	}
+	destURL := host.CloneURL(dest, viper.GetBool("use_ssh"))
 
-	cmd := exec.Command("git", "push", "--mirror", destURL)
+	cmd := gitCommand("push", "--mirror", "--progress", destURL)
 	cmd.Dir = dir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return progress.Git(ui.Status(), "📤 Pushing repository content", cmd)
 }
-
-// Add this function to parse and validate GitHub URLs
-func parseGitHubURL(url string) (string, error) {
-	// Handle SSH format: git@github.com:user/repo.git
-	if strings.HasPrefix(url, "git@github.com:") {
-		url = strings.TrimPrefix(url, "git@github.com:")
-		url = strings.TrimSuffix(url, ".git")
-		return url, nil
-	}
-
-	// Handle HTTPS format: https://github.com/user/repo
-	if strings.HasPrefix(url, "https://github.com/") {
-		url = strings.TrimPrefix(url, "https://github.com/")
-		url = strings.TrimSuffix(url, ".git")
-		return url, nil
-	}
-
-	return "", fmt.Errorf("invalid GitHub URL format. Use HTTPS (https://github.com/user/repo) or SSH (git@github.com:user/repo)")
-} 
\ No newline at end of file
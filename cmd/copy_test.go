@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDestinationRepo(t *testing.T) {
+	server := fakeGitHub(t)
+	originalConfig := repoConfig
+	defer func() { repoConfig = originalConfig }()
+	repoConfig = github.RepoConfig{Private: true, Topics: []string{"mirror"}}
+
+	require.NoError(t, createDestinationRepo("octo-org/app", true))
+	repo := server.Repo("octo-org/app")
+	require.NotNil(t, repo)
+	assert.True(t, repo.Private)
+	assert.Equal(t, "Repository copied using GitHelper", repo.Description)
+	assert.Equal(t, []string{"mirror"}, repo.Topics)
+
+	err := createDestinationRepo("octo-org/app", true)
+	assert.ErrorIs(t, err, github.ErrRepoExists)
+	assert.Error(t, createDestinationRepo("no-owner", false))
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"github.com/EndlessUphill/git-helper/internal/git"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var defaultBranchRemote string
+
+var defaultBranchCmd = &cobra.Command{
+	Use:   "default-branch",
+	Short: "Show or set the default branch of the repository",
+	Long: `Show the branch that prune, sync-fork and worktree cleanup treat as the
+main branch.
+
+It is read from origin/HEAD, which 'git clone' sets. Repositories that were
+created with 'git init' and 'git remote add' often don't have it; githelper
+then asks the remote once and remembers the answer, or falls back to main,
+master, trunk or develop.
+
+Override it for one repository with:
+  githelper config set --local default_branch develop
+
+Example:
+  githelper default-branch              # Print the default branch
+  githelper default-branch set          # Ask origin and save origin/HEAD
+  githelper default-branch set develop  # Point origin/HEAD at develop`,
+	Args: cobra.NoArgs,
+	RunE: runDefaultBranch,
+}
+
+var defaultBranchSetCmd = &cobra.Command{
+	Use:   "set [branch]",
+	Short: "Set origin/HEAD, from the remote or to the given branch",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runDefaultBranchSet,
+}
+
+func init() {
+	rootCmd.AddCommand(defaultBranchCmd)
+	defaultBranchCmd.AddCommand(defaultBranchSetCmd)
+	defaultBranchCmd.PersistentFlags().StringVar(&defaultBranchRemote, "remote", "origin", "remote whose default branch to use")
+}
+
+func runDefaultBranch(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	if override := viper.GetString("default_branch"); override != "" {
+		ui.Printf("%s (default_branch setting)\n", override)
+		return nil
+	}
+	branch, err := git.DefaultBranch(commandCtx, defaultBranchRemote)
+	if err != nil {
+		return err
+	}
+	ui.Println(branch)
+	return nil
+}
+
+func runDefaultBranchSet(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	var branch string
+	if len(args) > 0 {
+		branch = args[0]
+	} else {
+		ui.Stepf("🔄 Asking %s for its default branch...", defaultBranchRemote)
+	}
+
+	branch, err := git.SetDefaultBranch(commandCtx, defaultBranchRemote, branch)
+	if err != nil {
+		return err
+	}
+	ui.Successf("%s/HEAD now points at %s", defaultBranchRemote, branch)
+	if override := viper.GetString("default_branch"); override != "" && override != branch {
+		ui.Warnf("The default_branch setting (%s) still takes precedence", override)
+	}
+	return nil
+}
+
+// defaultBranch returns the main branch of the repository: the
+// default_branch setting, or the default branch of origin
+func defaultBranch() (string, error) {
+	return defaultBranchOf("origin")
+}
+
+// defaultBranchOf is defaultBranch for another remote, such as upstream
+func defaultBranchOf(remote string) (string, error) {
+	if override := viper.GetString("default_branch"); override != "" {
+		return override, nil
+	}
+	return git.DefaultBranch(commandCtx, remote)
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var deployRef string
+
+var deployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Show what is deployed to each environment",
+	Long: `Read the GitHub deployments and environments of the repository to show which
+commit is live in each environment and what would ship with the next deploy.
+
+Example:
+  githelper deploy list
+  githelper deploy status production`,
+}
+
+var deployListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the environments and the commit live in each",
+	Long: `List the deployment environments of the repository with the commit of their
+last successful deployment. When a newer deployment is still running or
+failed, it is shown below.
+
+Example:
+  githelper deploy list`,
+	Args: cobra.NoArgs,
+	RunE: runDeployList,
+}
+
+var deployStatusCmd = &cobra.Command{
+	Use:   "status [environment]",
+	Short: "Show what will ship to an environment",
+	Long: `Compare the commit live in an environment with the current branch (or
+--ref): the commits that will ship with the next deploy, the files they
+change, and the commits live in the environment that the branch doesn't
+contain, which a deploy would roll back.
+
+Without an environment, every environment is compared. The live commit is
+fetched from origin when it isn't in the local repository.
+
+Example:
+  githelper deploy status                     # Every environment
+  githelper deploy status production
+  githelper deploy status production --ref main`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDeployStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(deployCmd)
+	deployCmd.AddCommand(deployListCmd)
+	deployCmd.AddCommand(deployStatusCmd)
+	deployStatusCmd.Flags().StringVar(&deployRef, "ref", "", "branch or commit to compare (default: the current branch)")
+	deployStatusCmd.Flags().IntVarP(&compareLimit, "limit", "n", 10, "maximum number of commits to list")
+}
+
+func runDeployList(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	client, owner, name, err := originClient()
+	if err != nil {
+		return err
+	}
+	envs, err := client.Environments(context.Background(), owner, name)
+	if err != nil {
+		return fmt.Errorf("failed to get deployments: %w", err)
+	}
+	if len(envs) == 0 {
+		ui.Infof("%s/%s has no deployments", owner, name)
+		return nil
+	}
+
+	width := 0
+	for _, env := range envs {
+		width = max(width, len(env.Name))
+	}
+	ui.Stepf("🚀 Environments of %s/%s:", owner, name)
+	for _, env := range envs {
+		switch {
+		case env.Latest == nil:
+			ui.Printf("  %-*s    never deployed\n", width, env.Name)
+		case env.Live == nil:
+			ui.Printf("  %-*s    nothing live\n", width, env.Name)
+		default:
+			ui.Printf("  %-*s %s\n", width, env.Name, describeDeployment(*env.Live))
+		}
+		if env.Latest != nil && env.Latest != env.Live {
+			ui.Printf("  %-*s %s\n", width, "", describeDeployment(*env.Latest))
+		}
+	}
+	return nil
+}
+
+func runDeployStatus(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	ref := deployRef
+	if ref == "" {
+		branch, err := getCurrentBranch()
+		if err != nil {
+			return err
+		}
+		ref = branch
+	}
+	if gitCommand("rev-parse", "--verify", "-q", ref+"^{commit}").Run() != nil {
+		return fmt.Errorf("'%s' is not a branch or commit", ref)
+	}
+
+	client, owner, name, err := originClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var envs []github.EnvironmentStatus
+	if len(args) > 0 {
+		env, err := client.EnvironmentStatus(ctx, owner, name, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get deployments: %w", err)
+		}
+		if env.Latest == nil {
+			return fmt.Errorf("%s/%s has no deployments to %s", owner, name, args[0])
+		}
+		envs = append(envs, *env)
+	} else {
+		envs, err = client.Environments(ctx, owner, name)
+		if err != nil {
+			return fmt.Errorf("failed to get deployments: %w", err)
+		}
+		if len(envs) == 0 {
+			ui.Infof("%s/%s has no deployments", owner, name)
+			return nil
+		}
+	}
+
+	for i, env := range envs {
+		if i > 0 {
+			ui.Println()
+		}
+		if err := printWhatWillShip(env, ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printWhatWillShip compares the commit live in an environment with ref
+func printWhatWillShip(env github.EnvironmentStatus, ref string) error {
+	ui.Stepf("🌍 %s", env.Name)
+	if env.Latest != nil && env.Latest != env.Live {
+		ui.Printf("  Latest: %s\n", describeDeployment(*env.Latest))
+	}
+	if env.Live == nil {
+		ui.Println("  Nothing is live yet: everything will ship")
+		return nil
+	}
+	ui.Printf("  Live:   %s\n", describeDeployment(*env.Live))
+
+	live := env.Live.SHA
+	if err := ensureCommit(live); err != nil {
+		return err
+	}
+	onlyLive, onlyRef, err := aheadBehind(live, ref)
+	if err != nil {
+		return err
+	}
+	if onlyLive == 0 && onlyRef == 0 {
+		ui.Printf("  ✅ %s is live in %s\n", ref, env.Name)
+		return nil
+	}
+
+	printCompareCommits(fmt.Sprintf("🚢 %d commit(s) in %s will ship:", onlyRef, ref), live+".."+ref, onlyRef)
+	if onlyLive > 0 {
+		printCompareCommits(fmt.Sprintf("⚠️  %d commit(s) live in %s are not in %s and would be rolled back:", onlyLive, env.Name, ref),
+			ref+".."+live, onlyLive)
+	}
+
+	changes, err := changedFiles(live + ".." + ref)
+	if err != nil {
+		return err
+	}
+	added, deleted := 0, 0
+	for _, change := range changes {
+		added += change.Added
+		deleted += change.Deleted
+	}
+	ui.Stepf("\n📊 %d file(s) changed, %d insertion(s)(+), %d deletion(s)(-)", len(changes), added, deleted)
+	return nil
+}
+
+// describeDeployment formats a deployment on one line
+func describeDeployment(d github.Deployment) string {
+	parts := []string{deployStateIcon(d.State), shortSHA(d.SHA)}
+	if d.Ref != "" && d.Ref != d.SHA {
+		parts = append(parts, d.Ref)
+	}
+	if d.State != "success" {
+		parts = append(parts, d.State)
+	}
+	parts = append(parts, timeAgo(d.Created))
+	if d.Creator != "" {
+		parts = append(parts, "by "+d.Creator)
+	}
+	if d.URL != "" {
+		parts = append(parts, d.URL)
+	}
+	return strings.Join(parts, " ")
+}
+
+func deployStateIcon(state string) string {
+	switch state {
+	case "success":
+		return "✅"
+	case "failure", "error":
+		return "❌"
+	case "inactive":
+		return "💤"
+	}
+	return "⏳"
+}
+
+// ensureCommit fetches a commit from origin when it isn't in the local
+// repository, e.g. a deployed commit from a branch that was never fetched
+func ensureCommit(sha string) error {
+	if gitCommand("cat-file", "-e", sha+"^{commit}").Run() == nil {
+		return nil
+	}
+	ui.Stepf("📥 Fetching %s from origin...", shortSHA(sha))
+	if output, err := gitCommand("fetch", "-q", "origin", sha).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to fetch %s: %s", shortSHA(sha), strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureCommitFetchesFromOrigin(t *testing.T) {
+	originDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, originDir, "commit", "-m", "base")
+	runGit(t, originDir, "checkout", "-q", "-b", "release")
+	runGit(t, originDir, "commit", "--allow-empty", "-m", "deployed")
+	output, err := exec.Command("git", "-C", originDir, "rev-parse", "HEAD").Output()
+	require.NoError(t, err)
+	deployed := strings.TrimSpace(string(output))
+	runGit(t, originDir, "checkout", "-q", "-")
+
+	cloneDir := t.TempDir()
+	runGit(t, cloneDir, "clone", "-q", "--single-branch", "file://"+originDir, ".")
+	runGit(t, originDir, "config", "uploadpack.allowAnySHA1InWant", "true")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(cloneDir)
+
+	assert.Error(t, exec.Command("git", "cat-file", "-e", deployed).Run())
+	require.NoError(t, ensureCommit(deployed))
+	assert.NoError(t, exec.Command("git", "cat-file", "-e", deployed).Run())
+}
+
+func TestDescribeDeployment(t *testing.T) {
+	live := github.Deployment{SHA: "0123456789abcdef", Ref: "main", State: "success", Creator: "alice", Created: time.Now()}
+	assert.Equal(t, "✅ 0123456 main just now by alice", describeDeployment(live))
+
+	running := github.Deployment{SHA: "fedcba9876543210", Ref: "fedcba9876543210", State: "in_progress", Created: time.Now()}
+	assert.Equal(t, "⏳ fedcba9 in_progress just now", describeDeployment(running))
+}
@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// crlfExtensions are the files Windows tools expect CRLF line endings in,
+// whatever the line ending of the repository
+var crlfExtensions = []string{".bat", ".cmd"}
+
+var (
+	eolStyle         string
+	eolCommitMessage string
+	eolNoCommit      bool
+)
+
+// EOLEntry is the line ending information 'git ls-files --eol' reports for
+// a file
+type EOLEntry struct {
+	Path string
+	// Index and Worktree are lf, crlf, mixed, none (no line endings) or
+	// -text (binary)
+	Index    string
+	Worktree string
+	// Attr is the text and eol attributes applying to the file, empty when
+	// .gitattributes doesn't set them
+	Attr string
+}
+
+var eolCmd = &cobra.Command{
+	Use:   "eol",
+	Short: "Check and fix the line ending policy of the repository",
+	Long: `Check how line endings are handled in the repository and fix the problems:
+files without a text attribute, whose line endings depend on the core.autocrlf
+setting of each clone, files committed with CRLF or mixed line endings, and
+core.autocrlf settings that don't suit the platform.
+
+Example:
+  githelper eol check
+  githelper eol fix --dry-run
+  githelper eol fix`,
+}
+
+var eolCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report line ending problems",
+	Long: `Report the line ending settings of the repository and the files with line
+ending problems. Exits with an error when 'githelper eol fix' has something to
+fix, so it can run in CI.
+
+Example:
+  githelper eol check`,
+	Args: cobra.NoArgs,
+	RunE: runEOLCheck,
+}
+
+var eolFixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Write .gitattributes and commit the renormalized files",
+	Long: `Fix the line endings of the repository:
+  1. Add rules to .gitattributes so every file has a text attribute: text files
+     get --eol line endings (lf by default), .bat and .cmd files CRLF, and the
+     binary files found are marked binary. Existing rules are kept and take
+     precedence.
+  2. Renormalize the files, converting the ones committed with CRLF or mixed
+     line endings, and commit the result.
+  3. Check the renormalized files out again with the new line endings.
+
+The working tree must be clean. Use --dry-run to see the rules and the files
+that would change.
+
+Example:
+  githelper eol fix
+  githelper eol fix --eol native     # Line endings of each platform in the working tree
+  githelper eol fix --dry-run
+  githelper eol fix --no-commit      # Leave the changes staged`,
+	Args: cobra.NoArgs,
+	RunE: runEOLFix,
+}
+
+func init() {
+	rootCmd.AddCommand(eolCmd)
+	eolCmd.AddCommand(eolCheckCmd)
+	eolCmd.AddCommand(eolFixCmd)
+	eolFixCmd.Flags().StringVar(&eolStyle, "eol", "lf", "line endings of text files in the working tree: lf, crlf or native")
+	eolFixCmd.Flags().StringVarP(&eolCommitMessage, "message", "m", "Normalize line endings", "commit message")
+	eolFixCmd.Flags().BoolVar(&eolNoCommit, "no-commit", false, "stage the changes without committing")
+	eolFixCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would change without changing anything")
+}
+
+func runEOLCheck(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	entries, err := eolEntries()
+	if err != nil {
+		return err
+	}
+
+	problems := 0
+	autocrlf := gitConfigValue("core.autocrlf")
+	ui.Stepf("⚙️  core.autocrlf: %s", valueOrUnset(autocrlf))
+	if eol := gitConfigValue("core.eol"); eol != "" {
+		ui.Stepf("⚙️  core.eol: %s", eol)
+	}
+	if warning := autocrlfWarning(autocrlf, runtime.GOOS); warning != "" {
+		ui.Warnf("%s", warning)
+		problems++
+	}
+
+	var text, binary int
+	counts := map[string]int{}
+	var unspecified, crlf, mixed []string
+	for _, e := range entries {
+		if e.Index == "-text" {
+			binary++
+		} else {
+			text++
+			counts[e.Index]++
+		}
+		switch {
+		case e.Attr == "":
+			unspecified = append(unspecified, e.Path)
+		case e.Index == "crlf" && !strings.Contains(e.Attr, "-text"):
+			crlf = append(crlf, e.Path)
+		}
+		if e.Index == "mixed" || e.Worktree == "mixed" {
+			mixed = append(mixed, e.Path)
+		}
+	}
+	ui.Stepf("📄 %d text file(s): %d LF, %d CRLF, %d mixed, %d without line endings; %d binary", text, counts["lf"], counts["crlf"], counts["mixed"], counts["none"], binary)
+
+	report := func(title string, files []string) {
+		if len(files) == 0 {
+			return
+		}
+		problems++
+		ui.Errorf("\n%s, %d file(s):", title, len(files))
+		printFileList(ui.Out(), files, 20)
+	}
+	report("No text attribute in .gitattributes, line endings depend on core.autocrlf", unspecified)
+	report("Committed with CRLF although .gitattributes normalizes them", crlf)
+	report("Mixed line endings", mixed)
+
+	if problems > 0 {
+		return fmt.Errorf("line endings need fixing, run 'githelper eol fix'")
+	}
+	ui.Success("\nLine endings are consistent")
+	return nil
+}
+
+func runEOLFix(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	if eolStyle != "lf" && eolStyle != "crlf" && eolStyle != "native" {
+		return fmt.Errorf("invalid --eol %q, use lf, crlf or native", eolStyle)
+	}
+	if hasChanges, err := hasUncommittedChanges(); err != nil {
+		return err
+	} else if hasChanges && !dryRun {
+		return fmt.Errorf("you have uncommitted changes. Please commit or stash them first")
+	}
+	root, err := getRepoRoot()
+	if err != nil {
+		return err
+	}
+	// Paths and .gitattributes are relative to the root
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	if err := os.Chdir(root); err != nil {
+		return err
+	}
+
+	entries, err := eolEntries()
+	if err != nil {
+		return err
+	}
+	rules := gitattributesRules(entries, eolStyle)
+	existing, err := os.ReadFile(".gitattributes")
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read .gitattributes: %w", err)
+	}
+
+	var config []string
+	if len(rules) > 0 {
+		ui.Step("📝 Rules to add to .gitattributes:")
+		for _, rule := range rules {
+			ui.Printf("  %s\n", rule)
+		}
+		// Renormalize as if the rules were in .gitattributes: they come
+		// first there, so existing rules take precedence just like over
+		// core.attributesFile
+		tmp, err := os.CreateTemp("", "githelper-gitattributes-*")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		_, err = tmp.WriteString(strings.Join(rules, "\n") + "\n")
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		config = append(config, "core.attributesFile="+tmp.Name())
+	} else {
+		ui.Success("Every file has a text attribute in .gitattributes")
+	}
+
+	files, err := renormalizedFiles([]string{"."}, config...)
+	if err != nil {
+		return err
+	}
+	if len(files) > 0 {
+		ui.Stepf("🔧 Files to renormalize, %d file(s):", len(files))
+		printFileList(ui.Out(), files, 20)
+	}
+	if len(rules) == 0 && len(files) == 0 {
+		ui.Success("Line endings are already normalized")
+		return nil
+	}
+	if dryRun {
+		ui.Step("\n🔍 Dry run - nothing was changed")
+		return nil
+	}
+
+	if len(rules) > 0 {
+		content := "# Line endings, see 'githelper eol check'\n" + strings.Join(rules, "\n") + "\n"
+		if len(existing) > 0 {
+			content += "\n" + string(existing)
+		}
+		if err := os.WriteFile(".gitattributes", []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write .gitattributes: %w", err)
+		}
+		if err := gitCommand("add", ".gitattributes").Run(); err != nil {
+			return fmt.Errorf("failed to stage .gitattributes: %w", err)
+		}
+	}
+	if output, err := gitCommand("add", "--renormalize", ".").CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to renormalize files: %s", strings.TrimSpace(string(output)))
+	}
+	// The working tree still has the old line endings
+	if err := checkoutWithLineEndings(eolCheckoutFiles(entries, files)); err != nil {
+		return err
+	}
+
+	if eolNoCommit {
+		ui.Success("Line endings normalized, the changes are staged")
+		return nil
+	}
+	commitArgs := append([]string{"commit", "-q", "-m", eolCommitMessage}, commitSigningArgs()...)
+	commitCmd := gitCommand(commitArgs...)
+	commitCmd.Stdout = os.Stdout
+	commitCmd.Stderr = os.Stderr
+	if err := commitCmd.Run(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	ui.Successf("Committed the normalized line endings of %d file(s)", len(files))
+	return nil
+}
+
+// eolCheckoutFiles returns the text files whose line endings in the working
+// tree may change: the renormalized ones and the ones that had no text
+// attribute
+func eolCheckoutFiles(entries []EOLEntry, renormalized []string) []string {
+	files := slices.Clone(renormalized)
+	for _, e := range entries {
+		if e.Attr == "" && e.Index != "-text" && !slices.Contains(files, e.Path) {
+			files = append(files, e.Path)
+		}
+	}
+	return files
+}
+
+// checkoutWithLineEndings checks files out again; git skips the ones it
+// considers up to date, so they are removed first
+func checkoutWithLineEndings(files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to check out %s: %w", file, err)
+		}
+	}
+	checkoutCmd := gitCommand(append([]string{"checkout", "--"}, files...)...)
+	checkoutCmd.Stderr = os.Stderr
+	if err := checkoutCmd.Run(); err != nil {
+		return fmt.Errorf("failed to check out renormalized files: %w", err)
+	}
+	return nil
+}
+
+// eolEntries lists the line endings of every tracked file
+func eolEntries() ([]EOLEntry, error) {
+	output, err := gitCommand("ls-files", "--eol", "-z").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list line endings: %w", err)
+	}
+	return parseEOLEntries(output), nil
+}
+
+// parseEOLEntries parses the output of 'git ls-files --eol -z'
+func parseEOLEntries(output []byte) []EOLEntry {
+	var entries []EOLEntry
+	for _, record := range bytes.Split(output, []byte{0}) {
+		info, file, ok := strings.Cut(string(record), "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(info)
+		if len(fields) < 2 {
+			continue
+		}
+		entries = append(entries, EOLEntry{
+			Path:     file,
+			Index:    strings.TrimPrefix(fields[0], "i/"),
+			Worktree: strings.TrimPrefix(fields[1], "w/"),
+			Attr:     strings.TrimPrefix(strings.Join(fields[2:], " "), "attr/"),
+		})
+	}
+	return entries
+}
+
+// gitattributesRules returns the rules giving every file without a text
+// attribute one: binary files by extension, or by path when they have none
+func gitattributesRules(entries []EOLEntry, style string) []string {
+	var unspecified []EOLEntry
+	for _, e := range entries {
+		if e.Attr == "" {
+			unspecified = append(unspecified, e)
+		}
+	}
+	if len(unspecified) == 0 {
+		return nil
+	}
+
+	rule := "* text=auto"
+	if style != "native" {
+		rule += " eol=" + style
+	}
+	rules := []string{rule}
+	var crlfRules, binaryRules []string
+	textExts := map[string]bool{}
+	for _, e := range unspecified {
+		if e.Index != "-text" {
+			textExts[strings.ToLower(path.Ext(e.Path))] = true
+		}
+	}
+	for _, e := range unspecified {
+		ext := strings.ToLower(path.Ext(e.Path))
+		switch {
+		case e.Index != "-text":
+			if slices.Contains(crlfExtensions, ext) && style != "crlf" {
+				crlfRules = append(crlfRules, "*"+ext+" text eol=crlf")
+			}
+		case ext == "" || textExts[ext]:
+			// Text files share the extension, only this file is binary
+			binaryRules = append(binaryRules, "/"+e.Path+" binary")
+		default:
+			binaryRules = append(binaryRules, "*"+ext+" binary")
+		}
+	}
+	slices.Sort(crlfRules)
+	slices.Sort(binaryRules)
+	rules = append(rules, slices.Compact(crlfRules)...)
+	return append(rules, slices.Compact(binaryRules)...)
+}
+
+// autocrlfWarning explains what is wrong with a core.autocrlf value on a
+// platform, or returns ""
+func autocrlfWarning(autocrlf, goos string) string {
+	switch {
+	case autocrlf == "true" && goos != "windows":
+		return "core.autocrlf is true, files get CRLF line endings on checkout: set it to input or false"
+	case autocrlf == "input" && goos == "windows":
+		return "core.autocrlf is input, files checked out on Windows keep LF line endings: set it to true or use .gitattributes"
+	}
+	return ""
+}
+
+func valueOrUnset(value string) string {
+	if value == "" {
+		return "unset"
+	}
+	return value
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEOLEntries(t *testing.T) {
+	output := "i/lf    w/crlf  attr/text=auto eol=lf \tsrc/main.go\x00" +
+		"i/-text w/-text attr/                 \timage with spaces.png\x00"
+	assert.Equal(t, []EOLEntry{
+		{Path: "src/main.go", Index: "lf", Worktree: "crlf", Attr: "text=auto eol=lf"},
+		{Path: "image with spaces.png", Index: "-text", Worktree: "-text"},
+	}, parseEOLEntries([]byte(output)))
+}
+
+func TestGitattributesRules(t *testing.T) {
+	entries := []EOLEntry{
+		{Path: "main.go", Index: "lf"},
+		{Path: "build.bat", Index: "crlf"},
+		{Path: "logo.png", Index: "-text"},
+		{Path: "icons/app.png", Index: "-text"},
+		{Path: "data.bin", Index: "-text"},
+		{Path: "notes.bin", Index: "lf"},
+		{Path: "tool", Index: "-text"},
+		{Path: "README.md", Index: "lf", Attr: "text"},
+	}
+	assert.Equal(t, []string{
+		"* text=auto eol=lf",
+		"*.bat text eol=crlf",
+		"*.png binary",
+		"/data.bin binary",
+		"/tool binary",
+	}, gitattributesRules(entries, "lf"))
+	assert.Equal(t, []string{"* text=auto"}, gitattributesRules(entries[:1], "native"))
+	assert.Nil(t, gitattributesRules(entries[7:], "lf"))
+}
+
+func TestAutocrlfWarning(t *testing.T) {
+	assert.NotEmpty(t, autocrlfWarning("true", "linux"))
+	assert.Empty(t, autocrlfWarning("true", "windows"))
+	assert.NotEmpty(t, autocrlfWarning("input", "windows"))
+	assert.Empty(t, autocrlfWarning("", "darwin"))
+}
+
+func TestEOLFix(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+	eolStyle = "lf"
+	eolCommitMessage = "Normalize line endings"
+
+	require.NoError(t, os.WriteFile("crlf.txt", []byte("a\r\nb\r\n"), 0644))
+	require.NoError(t, os.WriteFile("run.bat", []byte("@echo off\n"), 0644))
+	runGit(t, tmpDir, "add", ".")
+	runGit(t, tmpDir, "commit", "-m", "base")
+	runGit(t, tmpDir, "config", "user.name", "Test")
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+
+	require.NoError(t, runEOLFix(eolFixCmd, nil))
+
+	attributes, err := os.ReadFile(".gitattributes")
+	require.NoError(t, err)
+	assert.Contains(t, string(attributes), "* text=auto eol=lf\n*.bat text eol=crlf\n")
+	committed, err := exec.Command("git", "show", "HEAD:crlf.txt").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\n", string(committed))
+	script, err := os.ReadFile("run.bat")
+	require.NoError(t, err)
+	assert.Equal(t, "@echo off\r\n", string(script))
+	status, err := exec.Command("git", "status", "--porcelain").Output()
+	require.NoError(t, err)
+	assert.Empty(t, string(status))
+
+	entries, err := eolEntries()
+	require.NoError(t, err)
+	assert.Nil(t, gitattributesRules(entries, "lf"))
+}
@@ -0,0 +1,459 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ai"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Explanation describes a git situation in plain English and how to get out
+// of it
+type Explanation struct {
+	Title   string
+	Details string
+	Fixes   []Fix
+}
+
+// Fix is a command that fixes a situation
+type Fix struct {
+	Command string
+	Note    string
+}
+
+// errorRule explains the git error messages matching a pattern
+type errorRule struct {
+	pattern *regexp.Regexp
+	explain func(match []string) Explanation
+}
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [error message]",
+	Short: "Explain the repository state or a git error, and how to fix it",
+	Long: `Explain in plain English what is going on and print the commands that fix it.
+
+Without arguments, the repository is inspected: a rebase, merge, cherry-pick,
+revert, patch or bisect in progress, conflicts, a detached HEAD, a branch
+without commits, or a branch that diverged from its upstream. With an error
+message as argument, or "-" to read it from standard input, the message is
+explained instead.
+
+When an OpenAI API key is configured, the AI provider writes the explanation
+from the error, the findings and 'git status'; otherwise, or with --offline,
+built-in rules are used.
+
+Example:
+  githelper explain
+  githelper explain "fatal: refusing to merge unrelated histories"
+  git push 2>&1 | githelper explain -`,
+	RunE: runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().BoolVar(&offlineAI, "offline", false, "use the built-in rules without calling the AI provider")
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	message := strings.Join(args, " ")
+	if message == "-" {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read standard input: %w", err)
+		}
+		message = string(input)
+	}
+	message = strings.TrimSpace(message)
+
+	var explanations []Explanation
+	if message != "" {
+		explanations = explainError(message)
+	} else {
+		if err := checkGitRepo(); err != nil {
+			return err
+		}
+		var err error
+		if explanations, err = explainRepoState(); err != nil {
+			return err
+		}
+	}
+
+	apiKey := viper.GetString("openai_api_key")
+	if !offlineAI && apiKey != "" && (message != "" || len(explanations) > 0) {
+		if text, err := explainWithAI(apiKey, message, explanations); err != nil {
+			ui.Warnf("AI explanation failed: %v", err)
+			ui.Println("Falling back to the built-in rules")
+		} else {
+			ui.Println(text)
+			return nil
+		}
+	}
+
+	switch {
+	case len(explanations) > 0:
+		printExplanations(ui.Out(), explanations)
+	case message != "":
+		ui.Step("🤷 This message isn't one githelper knows about.")
+		ui.Step("💡 Configure openai_api_key to have the AI provider explain it")
+	default:
+		ui.Success("Nothing unusual: no operation in progress and the branch is in sync")
+	}
+	return nil
+}
+
+func printExplanations(w io.Writer, explanations []Explanation) {
+	for i, e := range explanations {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "🔎 %s\n", e.Title)
+		fmt.Fprintf(w, "   %s\n", strings.ReplaceAll(e.Details, "\n", "\n   "))
+		if len(e.Fixes) > 0 {
+			fmt.Fprintln(w, "👉 To fix it:")
+		}
+		for _, fix := range e.Fixes {
+			fmt.Fprintf(w, "   %-40s # %s\n", fix.Command, fix.Note)
+		}
+	}
+}
+
+// explainWithAI asks the AI provider to explain the error message, or the
+// findings when there is none
+func explainWithAI(apiKey, message string, explanations []Explanation) (string, error) {
+	problem := message
+	if problem == "" {
+		var titles []string
+		for _, e := range explanations {
+			titles = append(titles, e.Title)
+		}
+		problem = strings.Join(titles, "\n")
+	}
+	status, _ := gitCommand("status", "--branch", "--short").CombinedOutput()
+
+	var commands strings.Builder
+	for _, c := range rootCmd.Commands() {
+		if c.IsAvailableCommand() {
+			fmt.Fprintf(&commands, "%s: %s\n", c.Name(), c.Short)
+		}
+	}
+
+	ui.Step("🤖 Asking the AI provider...")
+	return ai.NewExplainer(apiKey).Explain(problem, strings.TrimSpace(string(status)), commands.String())
+}
+
+// explainRepoState explains the unusual states the repository is in
+func explainRepoState() ([]Explanation, error) {
+	var explanations []Explanation
+
+	switch {
+	case gitPathExists("rebase-merge") || (gitPathExists("rebase-apply") && !gitPathExists("rebase-apply/applying")):
+		explanations = append(explanations, Explanation{
+			Title:   "A rebase is in progress",
+			Details: "git is replaying your commits one by one and stopped, usually on a conflict\nor because a commit was marked for editing.",
+			Fixes: []Fix{
+				{"githelper resolve", "resolve the conflicts, if any"},
+				{"git rebase --continue", "go on with the next commit"},
+				{"git rebase --abort", "give up and go back to before the rebase"},
+			},
+		})
+	case gitPathExists("rebase-apply"):
+		explanations = append(explanations, Explanation{
+			Title:   "Applying patches is in progress",
+			Details: "git am stopped on a patch that doesn't apply cleanly.",
+			Fixes: []Fix{
+				{"githelper patch apply --continue", "after fixing and staging the files"},
+				{"githelper patch apply --skip", "leave this patch out"},
+				{"githelper patch apply --abort", "go back to before applying"},
+			},
+		})
+	}
+	if gitPathExists("MERGE_HEAD") {
+		explanations = append(explanations, Explanation{
+			Title:   "A merge is in progress",
+			Details: "git couldn't merge the branches automatically and is waiting for you to\nfinish the merge.",
+			Fixes: []Fix{
+				{"githelper resolve", "resolve the conflicts"},
+				{"git merge --continue", "commit the merge"},
+				{"git merge --abort", "give up and go back to before the merge"},
+			},
+		})
+	}
+	if gitPathExists("CHERRY_PICK_HEAD") {
+		explanations = append(explanations, Explanation{
+			Title:   "A cherry-pick is in progress",
+			Details: "Copying a commit onto this branch stopped on a conflict.",
+			Fixes: []Fix{
+				{"githelper resolve", "resolve the conflicts"},
+				{"git cherry-pick --continue", "commit the copy"},
+				{"git cherry-pick --abort", "give up"},
+			},
+		})
+	}
+	if gitPathExists("REVERT_HEAD") {
+		explanations = append(explanations, Explanation{
+			Title:   "A revert is in progress",
+			Details: "Undoing a commit stopped on a conflict with later changes.",
+			Fixes: []Fix{
+				{"githelper resolve", "resolve the conflicts"},
+				{"git revert --continue", "commit the revert"},
+				{"git revert --abort", "give up"},
+			},
+		})
+	}
+	if gitPathExists("BISECT_LOG") {
+		explanations = append(explanations, Explanation{
+			Title:   "A bisect is in progress",
+			Details: "git is checking out commits to find the one that introduced a bug.",
+			Fixes: []Fix{
+				{"git bisect good", "or 'git bisect bad', to mark the current commit"},
+				{"git bisect reset", "stop and go back to your branch"},
+			},
+		})
+	}
+	if hasConflicts() && len(explanations) == 0 {
+		explanations = append(explanations, Explanation{
+			Title:   "Some files have conflicts",
+			Details: "Files contain conflict markers from a merge, stash or checkout that\ncouldn't combine both sides.",
+			Fixes:   []Fix{{"githelper resolve", "pick a side for each file, or edit and 'git add' them"}},
+		})
+	}
+
+	if gitCommand("rev-parse", "--verify", "-q", "HEAD").Run() != nil {
+		branch, _ := gitCommand("symbolic-ref", "--short", "HEAD").Output()
+		explanations = append(explanations, Explanation{
+			Title:   fmt.Sprintf("Branch '%s' has no commits yet", strings.TrimSpace(string(branch))),
+			Details: "The branch is unborn: it only exists once the first commit is made, so\ncommands that need a commit (log, push, switch) fail.",
+			Fixes: []Fix{
+				{"git add .", "stage the files"},
+				{"githelper commit", "make the first commit"},
+			},
+		})
+		return explanations, nil
+	}
+
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+	if branch == "HEAD" {
+		// Rebases and bisects detach HEAD on purpose
+		if len(explanations) == 0 {
+			explanations = append(explanations, Explanation{
+				Title:   "HEAD is detached",
+				Details: "You checked out a commit instead of a branch. You can look around, but\ncommits made here belong to no branch and are easy to lose.",
+				Fixes: []Fix{
+					{"git switch -", "go back to the previous branch"},
+					{"githelper rescue <branch>", "keep the commits made here on a new branch"},
+				},
+			})
+		}
+		return explanations, nil
+	}
+
+	if !hasUpstream(branch) {
+		explanations = append(explanations, Explanation{
+			Title:   fmt.Sprintf("Branch '%s' has no upstream", branch),
+			Details: "It isn't linked to a remote branch, so git doesn't know where to push or\npull.",
+			Fixes:   []Fix{{"git push -u origin " + branch, "publish it and link it"}},
+		})
+		return explanations, nil
+	}
+	ahead, behind, err := aheadBehind(branch, branch+"@{upstream}")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ahead > 0 && behind > 0:
+		explanations = append(explanations, Explanation{
+			Title:   fmt.Sprintf("Branch '%s' diverged from its upstream", branch),
+			Details: fmt.Sprintf("You have %d commit(s) that aren't pushed and the remote has %d you don't\nhave, so a push is rejected until you combine them.", ahead, behind),
+			Fixes: []Fix{
+				{"githelper sync", "rebase your commits on the remote ones"},
+				{"githelper push", "then push"},
+			},
+		})
+	case behind > 0:
+		explanations = append(explanations, Explanation{
+			Title:   fmt.Sprintf("Branch '%s' is behind its upstream", branch),
+			Details: fmt.Sprintf("The remote has %d commit(s) you don't have yet.", behind),
+			Fixes:   []Fix{{"githelper sync", "bring them in"}},
+		})
+	}
+	return explanations, nil
+}
+
+// errorRules explain common git error messages
+var errorRules = []errorRule{
+	{regexp.MustCompile(`(?i)rejected.*\((fetch first|non-fast-forward)\)|tip of your current branch is behind`), func([]string) Explanation {
+		return Explanation{
+			Title:   "The push was rejected because the remote has commits you don't have",
+			Details: "Someone pushed to the branch since you last pulled. git refuses to push\nbecause it would throw their commits away.",
+			Fixes: []Fix{
+				{"githelper sync", "rebase your commits on theirs"},
+				{"githelper push", "then push again"},
+			},
+		}
+	}},
+	{regexp.MustCompile(`(?i)need to specify how to reconcile divergent branches|have diverged`), func([]string) Explanation {
+		return Explanation{
+			Title:   "Your branch and the remote one diverged",
+			Details: "Both have commits the other doesn't, and git won't guess whether to merge\nor rebase them.",
+			Fixes: []Fix{
+				{"githelper sync", "rebase your commits on the remote ones"},
+				{"git config pull.rebase true", "make 'git pull' always rebase"},
+			},
+		}
+	}},
+	{regexp.MustCompile(`(?i)refusing to merge unrelated histories`), func([]string) Explanation {
+		return Explanation{
+			Title:   "The two branches have no commit in common",
+			Details: "This happens when a repository was created with a README on GitHub and a\nseparate one was started locally.",
+			Fixes:   []Fix{{"git pull --allow-unrelated-histories", "combine them anyway, if they belong together"}},
+		}
+	}},
+	{regexp.MustCompile(`(?i)your local changes to the following files would be overwritten`), func([]string) Explanation {
+		return Explanation{
+			Title:   "Uncommitted changes are in the way",
+			Details: "The command would overwrite files you changed but didn't commit.",
+			Fixes: []Fix{
+				{"githelper commit", "commit them"},
+				{"git stash", "or put them aside, and 'git stash pop' afterwards"},
+			},
+		}
+	}},
+	{regexp.MustCompile(`(?i)CONFLICT \(|automatic merge failed|could not apply`), func([]string) Explanation {
+		return Explanation{
+			Title:   "Both sides changed the same lines",
+			Details: "git couldn't combine the changes and left conflict markers in the files.",
+			Fixes: []Fix{
+				{"githelper resolve", "pick a side for each file"},
+				{"githelper explain", "see how to continue or abort the operation"},
+			},
+		}
+	}},
+	{regexp.MustCompile(`(?i)the current branch (\S+) has no upstream branch`), func(match []string) Explanation {
+		return Explanation{
+			Title:   fmt.Sprintf("Branch '%s' isn't linked to a remote branch", match[1]),
+			Details: "git doesn't know where to push it because it was never pushed.",
+			Fixes:   []Fix{{"git push -u origin " + match[1], "publish it and link it"}},
+		}
+	}},
+	{regexp.MustCompile(`(?i)src refspec (\S+) does not match any`), func(match []string) Explanation {
+		return Explanation{
+			Title:   fmt.Sprintf("There is no local branch or commit named '%s'", match[1]),
+			Details: "Either the name is misspelled, or the branch has no commits yet.",
+			Fixes: []Fix{
+				{"git branch", "check the branch names"},
+				{"githelper commit", "make the first commit"},
+			},
+		}
+	}},
+	{regexp.MustCompile(`(?i)pathspec '([^']+)' did not match any`), func(match []string) Explanation {
+		return Explanation{
+			Title:   fmt.Sprintf("git doesn't know '%s'", match[1]),
+			Details: "No file or branch has that name. Remote branches need a fetch before they\ncan be switched to.",
+			Fixes: []Fix{
+				{"git fetch", "get the remote branches"},
+				{"githelper switch", "pick a branch from a list"},
+			},
+		}
+	}},
+	{regexp.MustCompile(`(?i)you are in 'detached HEAD' state`), func([]string) Explanation {
+		return Explanation{
+			Title:   "You checked out a commit instead of a branch",
+			Details: "Commits made now belong to no branch and are easy to lose.",
+			Fixes: []Fix{
+				{"git switch -", "go back to the previous branch"},
+				{"githelper rescue <branch>", "keep the commits made here on a new branch"},
+			},
+		}
+	}},
+	{regexp.MustCompile(`(?i)not a git repository`), func([]string) Explanation {
+		return Explanation{
+			Title:   "This directory isn't in a git repository",
+			Details: "git looks for a .git directory here and in the parent directories.",
+			Fixes: []Fix{
+				{"cd <repository>", "go to the repository"},
+				{"git init", "or turn this directory into one"},
+			},
+		}
+	}},
+	{regexp.MustCompile(`(?i)please tell me who you are|author identity unknown`), func([]string) Explanation {
+		return Explanation{
+			Title:   "git doesn't know your name and email",
+			Details: "Every commit records its author, so they must be configured first.",
+			Fixes: []Fix{
+				{`git config --global user.name "Your Name"`, "set your name"},
+				{"git config --global user.email you@example.com", "set your email"},
+			},
+		}
+	}},
+	{regexp.MustCompile(`(?i)permission denied \(publickey\)`), func([]string) Explanation {
+		return Explanation{
+			Title:   "The server didn't accept your SSH key",
+			Details: "No SSH key is loaded, or the key isn't added to your account.",
+			Fixes: []Fix{
+				{"ssh -T git@github.com", "check which key is used"},
+				{"githelper auth login", "or log in and use HTTPS instead"},
+			},
+		}
+	}},
+	{regexp.MustCompile(`(?i)authentication failed|could not read username`), func([]string) Explanation {
+		return Explanation{
+			Title:   "The server didn't accept your credentials",
+			Details: "The stored password or token is wrong or expired. GitHub no longer accepts\naccount passwords over HTTPS.",
+			Fixes:   []Fix{{"githelper auth login", "log in again"}},
+		}
+	}},
+	{regexp.MustCompile(`(?i)unable to create '([^']*index\.lock)': file exists`), func(match []string) Explanation {
+		return Explanation{
+			Title:   "Another git process is running, or one crashed",
+			Details: "git locks the index while changing it, and the lock file is still there.",
+			Fixes:   []Fix{{"rm " + match[1], "only once no git command is running"}},
+		}
+	}},
+	{regexp.MustCompile(`(?i)cannot lock ref '([^']+)'`), func(match []string) Explanation {
+		return Explanation{
+			Title:   fmt.Sprintf("git couldn't update %s", match[1]),
+			Details: "A stale remote branch conflicts with the name, for example 'feature' with\n'feature/login', or another process holds the lock.",
+			Fixes:   []Fix{{"git remote prune origin", "drop remote branches that no longer exist"}},
+		}
+	}},
+	{regexp.MustCompile(`(?i)exceeds GitHub's file size limit|large files detected|GH001`), func([]string) Explanation {
+		return Explanation{
+			Title:   "A commit contains a file that is too large",
+			Details: "GitHub refuses files over 100 MB, even when a later commit deletes them.",
+			Fixes: []Fix{
+				{"githelper purge <file>", "remove the file from the history"},
+				{"git lfs track <pattern>", "store such files with Git LFS"},
+			},
+		}
+	}},
+	{regexp.MustCompile(`(?i)gpg failed to sign the data`), func([]string) Explanation {
+		return Explanation{
+			Title:   "The commit couldn't be signed",
+			Details: "commit.gpgsign is on, but the signing program failed: the key is missing,\nexpired, or gpg can't ask for its passphrase.",
+			Fixes: []Fix{
+				{"export GPG_TTY=$(tty)", "let gpg prompt in this terminal"},
+				{"git config user.signingkey <key>", "use a key that exists"},
+			},
+		}
+	}},
+}
+
+// explainError explains the known errors in a git message
+func explainError(message string) []Explanation {
+	var explanations []Explanation
+	for _, rule := range errorRules {
+		if match := rule.pattern.FindStringSubmatch(message); match != nil {
+			explanations = append(explanations, rule.explain(match))
+		}
+	}
+	return explanations
+}
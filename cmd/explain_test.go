@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainError(t *testing.T) {
+	explanations := explainError(`To github.com:octo/app.git
+ ! [rejected]        main -> main (fetch first)
+error: failed to push some refs to 'github.com:octo/app.git'`)
+	require.Len(t, explanations, 1)
+	assert.Contains(t, explanations[0].Title, "push was rejected")
+	assert.Equal(t, "githelper sync", explanations[0].Fixes[0].Command)
+
+	explanations = explainError("fatal: The current branch feature/login has no upstream branch.")
+	require.Len(t, explanations, 1)
+	assert.Equal(t, "git push -u origin feature/login", explanations[0].Fixes[0].Command)
+
+	assert.Empty(t, explainError("everything is fine"))
+}
+
+func TestExplainRepoState(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	explanations, err := explainRepoState()
+	require.NoError(t, err)
+	require.Len(t, explanations, 1)
+	assert.Contains(t, explanations[0].Title, "has no commits yet")
+
+	runGit(t, tmpDir, "config", "user.name", "Test")
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+	runGit(t, tmpDir, "commit", "-m", "base")
+	runGit(t, tmpDir, "branch", "-M", "main")
+	runGit(t, tmpDir, "checkout", "-b", "feature")
+	require.NoError(t, os.WriteFile("test.txt", []byte("feature"), 0644))
+	runGit(t, tmpDir, "commit", "-am", "feature")
+	runGit(t, tmpDir, "checkout", "main")
+	require.NoError(t, os.WriteFile("test.txt", []byte("main"), 0644))
+	runGit(t, tmpDir, "commit", "-am", "main")
+	require.Error(t, exec.Command("git", "merge", "feature").Run())
+
+	explanations, err = explainRepoState()
+	require.NoError(t, err)
+	require.Len(t, explanations, 2)
+	assert.Equal(t, "A merge is in progress", explanations[0].Title)
+	// The branch has no upstream
+	assert.Contains(t, explanations[1].Title, "has no upstream")
+
+	runGit(t, tmpDir, "merge", "--abort")
+	runGit(t, tmpDir, "checkout", "--detach")
+	explanations, err = explainRepoState()
+	require.NoError(t, err)
+	require.Len(t, explanations, 1)
+	assert.Equal(t, "HEAD is detached", explanations[0].Title)
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	feedSince    time.Duration
+	feedLimit    int
+	feedWatch    bool
+	feedInterval time.Duration
+)
+
+var feedCmd = &cobra.Command{
+	Use:   "feed",
+	Short: "Show recent activity on GitHub and in your clone in one timeline",
+	Long: `Show what happened lately, oldest first: pushes, pull requests, releases
+and issues on the GitHub repository of origin, and your own commits,
+checkouts, resets and rebases from the reflog.
+
+Without a token for the host, or offline, only the local activity is shown.
+With --watch the feed keeps running and prints new events as they come.
+
+Example:
+  githelper feed                   # The last week
+  githelper feed --since 24h -n 50
+  githelper feed --watch --interval 30s`,
+	Args: cobra.NoArgs,
+	RunE: runFeed,
+}
+
+func init() {
+	rootCmd.AddCommand(feedCmd)
+	feedCmd.Flags().DurationVar(&feedSince, "since", 7*24*time.Hour, "how far back the feed goes")
+	feedCmd.Flags().IntVarP(&feedLimit, "limit", "n", 30, "maximum number of events shown at first")
+	feedCmd.Flags().BoolVarP(&feedWatch, "watch", "w", false, "keep printing new events")
+	feedCmd.Flags().DurationVar(&feedInterval, "interval", time.Minute, "time between refreshes with --watch")
+}
+
+// feedEntry is an event of the timeline, from GitHub or the reflog
+type feedEntry struct {
+	// ID tells entries apart between refreshes
+	ID   string
+	Time time.Time
+	Icon string
+	Text string
+}
+
+func runFeed(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	if feedWatch && feedInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	since := time.Now().Add(-feedSince)
+
+	seen := map[string]bool{}
+	remote := true
+	for first := true; ; first = false {
+		var entries []feedEntry
+		if remote {
+			events, err := githubActivity(since)
+			if err != nil {
+				// No point asking again on every refresh
+				ui.Warnf("Showing local activity only: %v", err)
+				remote = false
+			}
+			entries = append(entries, events...)
+		}
+		local, err := localActivity(since)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, local...)
+
+		var fresh []feedEntry
+		for _, entry := range sortFeed(entries) {
+			if !seen[entry.ID] {
+				seen[entry.ID] = true
+				fresh = append(fresh, entry)
+			}
+		}
+		if first {
+			if len(fresh) == 0 {
+				ui.Printf("No activity in the last %s\n", feedSince)
+			}
+			if feedLimit > 0 && len(fresh) > feedLimit {
+				fresh = fresh[len(fresh)-feedLimit:]
+			}
+		}
+		printFeed(ui.Out(), fresh)
+
+		if !feedWatch {
+			return nil
+		}
+		time.Sleep(feedInterval)
+	}
+}
+
+// githubActivity returns the events of the origin repository since a time
+func githubActivity(since time.Time) ([]feedEntry, error) {
+	client, owner, name, err := originClient()
+	if err != nil {
+		return nil, err
+	}
+	events, err := client.RepoEvents(context.Background(), owner, name, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch GitHub events: %w", err)
+	}
+
+	var entries []feedEntry
+	for _, event := range events {
+		if event.Created.Before(since) {
+			continue
+		}
+		entries = append(entries, feedEntry{
+			ID:   "github:" + event.ID,
+			Time: event.Created,
+			Icon: feedIcon(event.Kind),
+			Text: event.Actor + " " + event.Summary,
+		})
+	}
+	return entries, nil
+}
+
+// localActivity returns the entries of the HEAD reflog since a time
+func localActivity(since time.Time) ([]feedEntry, error) {
+	if gitCommand("rev-parse", "--verify", "-q", "HEAD").Run() != nil {
+		return nil, nil // No commit yet
+	}
+	// With --date=unix, %gd is HEAD@{<seconds>}
+	output, err := gitCommand("reflog", "show", "HEAD", "--date=unix", "--format=%gd%x00%h%x00%gs").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the reflog: %w", err)
+	}
+
+	var entries []feedEntry
+	for _, line := range splitLines(string(output)) {
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		selector, hash, subject := fields[0], fields[1], fields[2]
+		seconds, err := strconv.ParseInt(strings.TrimSuffix(selector[strings.LastIndex(selector, "{")+1:], "}"), 10, 64)
+		if err != nil {
+			continue
+		}
+		when := time.Unix(seconds, 0)
+		if when.Before(since) {
+			break // Newest first
+		}
+		entries = append(entries, feedEntry{
+			ID:   "local:" + selector + ":" + hash + ":" + subject,
+			Time: when,
+			Icon: "💻",
+			Text: fmt.Sprintf("%s (%s)", subject, hash),
+		})
+	}
+	return entries, nil
+}
+
+// sortFeed orders entries oldest first
+func sortFeed(entries []feedEntry) []feedEntry {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	return entries
+}
+
+func printFeed(w io.Writer, entries []feedEntry) {
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s  %s %s\n", entry.Time.Local().Format("Jan 02 15:04"), entry.Icon, entry.Text)
+	}
+}
+
+func feedIcon(kind string) string {
+	switch kind {
+	case github.EventPush:
+		return "⬆️"
+	case github.EventPullRequest:
+		return "🔀"
+	case github.EventRelease:
+		return "🏷️"
+	case github.EventIssue:
+		return "🐛"
+	}
+	return "•"
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/github/githubtest"
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeed(t *testing.T) {
+	server := fakeGitHub(t)
+	repo := server.AddRepo("octo/app")
+	now := time.Now()
+	repo.Events = []githubtest.Event{
+		{Type: "PullRequestEvent", Actor: "hubot", Created: now.Add(-time.Hour), Payload: map[string]interface{}{
+			"action": "opened", "pull_request": map[string]interface{}{"number": 3, "title": "Add search"},
+		}},
+		{Type: "WatchEvent", Actor: "fan", Created: now.Add(-2 * time.Hour), Payload: map[string]interface{}{"action": "started"}},
+		{Type: "PushEvent", Actor: "octocat", Created: now.Add(-3 * time.Hour), Payload: map[string]interface{}{
+			"ref": "refs/heads/main", "size": 2,
+		}},
+		{Type: "IssuesEvent", Actor: "octocat", Created: now.Add(-30 * 24 * time.Hour), Payload: map[string]interface{}{
+			"action": "opened", "issue": map[string]interface{}{"number": 1, "title": "Too old"},
+		}},
+	}
+
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	r.Git("remote", "add", "origin", "https://"+githubtest.Hostname+"/octo/app.git")
+
+	stdout, _, err := execute(t, "feed", "--since", "24h")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 3, stdout)
+	assert.Contains(t, lines[0], "⬆️ octocat pushed 2 commits to main")
+	assert.Contains(t, lines[1], "🔀 hubot opened pull request #3 Add search")
+	assert.Contains(t, lines[2], "💻 commit (initial): Base")
+
+	// Without access to GitHub the local activity is still shown
+	r.Git("remote", "set-url", "origin", "/nowhere")
+	stdout, stderr, err := execute(t, "feed", "--since", "24h")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "Showing local activity only")
+	assert.Contains(t, stdout, "💻 commit (initial): Base")
+	assert.NotContains(t, stdout, "octocat")
+}
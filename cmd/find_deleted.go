@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreToBranch string
+)
+
+var findDeletedCmd = &cobra.Command{
+	Use:   "find-deleted <pattern>",
+	Short: "Find and restore files deleted from history",
+	Long: `Search git history for deleted files matching a pattern.
+
+This command helps you get back a file that was removed by:
+1. Searching every commit that deleted a matching file
+2. Showing when and by whom each file was removed
+3. Restoring the last version to the working tree or a new branch
+
+The pattern is a shell glob matched against the file path (e.g. "*.sql" or
+"config/*"); a plain word matches any path containing it.
+
+Example:
+  githelper find-deleted "*.sql"                   # Pick a deleted file to restore
+  githelper find-deleted config --branch restore   # Restore onto a new branch
+  githelper find-deleted "*.sql" --select-file db/schema.sql  # Skip the picker`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFindDeleted,
+}
+
+func init() {
+	rootCmd.AddCommand(findDeletedCmd)
+	findDeletedCmd.Flags().StringVarP(&restoreToBranch, "branch", "b", "", "restore the file on a new branch instead of the working tree")
+	findDeletedCmd.Flags().StringVar(&preselectedFile, "select-file", "", "deleted file to restore without prompting")
+}
+
+// DeletedFile is a file removed by a commit
+type DeletedFile struct {
+	Path    string
+	Commit  string
+	Author  string
+	Date    string
+	Subject string
+}
+
+func runFindDeleted(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	ui.Step("🔍 Searching history for deleted files...")
+	files, err := findDeletedFiles(args[0])
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no deleted files matching '%s' found", args[0])
+	}
+
+	selected, err := selectDeletedFile(files)
+	if err != nil {
+		return err
+	}
+	if selected == nil {
+		return fmt.Errorf("no file selected")
+	}
+
+	ui.Stepf("\n🗑️  %s was deleted in %s by %s on %s", selected.Path, selected.Commit[:8], selected.Author, selected.Date)
+	ui.Printf("   %s\n", selected.Subject)
+
+	if restoreToBranch != "" {
+		ui.Stepf("\n🌱 Creating branch '%s'...", restoreToBranch)
+		checkoutCmd := gitCommand("checkout", "-b", restoreToBranch)
+		checkoutCmd.Stderr = os.Stderr
+		if err := checkoutCmd.Run(); err != nil {
+			return fmt.Errorf("failed to create branch: %w", err)
+		}
+	} else if exists, err := deletedFileExists(selected); err != nil {
+		return err
+	} else if exists {
+		ui.Warnf("\n%s exists in the working tree and will be overwritten", selected.Path)
+		if !confirmAction() {
+			ui.Error("Operation cancelled")
+			return nil
+		}
+	}
+
+	if err := restoreDeletedFile(selected); err != nil {
+		return err
+	}
+
+	ui.Successf("Restored %s (staged)", selected.Path)
+	return nil
+}
+
+// deletedFileExists reports whether something is at the file's path again.
+// Paths from git log are relative to the repository root, not the current
+// directory.
+func deletedFileExists(file *DeletedFile) (bool, error) {
+	root, err := getRepoRoot()
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(filepath.Join(root, filepath.FromSlash(file.Path)))
+	return err == nil, nil
+}
+
+// restoreDeletedFile checks out the file from the parent of the deleting
+// commit, where it still exists
+func restoreDeletedFile(file *DeletedFile) error {
+	restoreCmd := gitCommand("checkout", file.Commit+"^", "--", ":(top)"+file.Path)
+	restoreCmd.Stderr = os.Stderr
+	if err := restoreCmd.Run(); err != nil {
+		return fmt.Errorf("failed to restore file: %w", err)
+	}
+	return nil
+}
+
+func findDeletedFiles(pattern string) ([]DeletedFile, error) {
+	cmd := gitCommand("log", "--all", "--diff-filter=D", "--name-only", "--date=short",
+		"--format=%x1e%H%x1f%an%x1f%ad%x1f%s")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search history: %w", err)
+	}
+
+	var files []DeletedFile
+	seen := make(map[string]bool)
+	for _, record := range strings.Split(string(output), "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		header := strings.Split(lines[0], "\x1f")
+		if len(header) != 4 {
+			continue
+		}
+		for _, path := range lines[1:] {
+			path = strings.TrimSpace(path)
+			// Only keep the most recent deletion of each path
+			if path == "" || seen[path] || !matchesPathPattern(path, pattern) {
+				continue
+			}
+			seen[path] = true
+			files = append(files, DeletedFile{
+				Path:    path,
+				Commit:  header[0],
+				Author:  header[1],
+				Date:    header[2],
+				Subject: header[3],
+			})
+		}
+	}
+	return files, nil
+}
+
+// matchesPathPattern matches a glob against the full path or its base name,
+// and plain words as substrings
+func matchesPathPattern(file, pattern string) bool {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return strings.Contains(file, pattern)
+	}
+	if ok, _ := path.Match(pattern, file); ok {
+		return true
+	}
+	ok, _ := path.Match(pattern, path.Base(file))
+	return ok
+}
+
+// selectDeletedFile asks which of the files to restore. A single match is
+// selected right away.
+func selectDeletedFile(files []DeletedFile) (*DeletedFile, error) {
+	if preselectedFile != "" {
+		for i := range files {
+			if files[i].Path == preselectedFile {
+				return &files[i], nil
+			}
+		}
+		return nil, fmt.Errorf("'%s' is not among the deleted files found", preselectedFile)
+	}
+	if len(files) == 1 {
+		return &files[0], nil
+	}
+	if !noFzf && isInteractive() {
+		if _, err := exec.LookPath("fzf"); err == nil {
+			return selectDeletedFileWithFzf(files)
+		}
+	}
+	return selectDeletedFileWithList(files)
+}
+
+func selectDeletedFileWithFzf(files []DeletedFile) (*DeletedFile, error) {
+	var input strings.Builder
+	for i, file := range files {
+		fmt.Fprintf(&input, "%d\t%s\t%s %s %s\t%s^:%s\n", i, file.Path, file.Commit[:8], file.Date, file.Author, file.Commit, file.Path)
+	}
+
+	// Show the last version of the file before it was deleted
+	previewCmd := "git show {4}"
+
+	fzfCmd := exec.Command("fzf",
+		"--height", "50%",
+		"--reverse",
+		"--delimiter", "\t",
+		"--with-nth", "2,3",
+		"--preview", previewCmd,
+		"--preview-window", "right:50%")
+
+	fzfCmd.Stdin = strings.NewReader(input.String())
+	fzfCmd.Stderr = os.Stderr
+
+	output, err := fzfCmd.Output()
+	if err != nil {
+		return nil, nil // User cancelled
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &index); err != nil || index < 0 || index >= len(files) {
+		return nil, fmt.Errorf("invalid selection")
+	}
+	return &files[index], nil
+}
+
+func selectDeletedFileWithList(files []DeletedFile) (*DeletedFile, error) {
+	ui.Println("\nDeleted files:")
+	for i, file := range files {
+		ui.Printf("%2d: %s (deleted %s by %s in %s)\n", i+1, file.Path, file.Date, file.Author, file.Commit[:8])
+	}
+
+	input := readInput("\nSelect file number (or press Enter to cancel): ")
+
+	if input == "" {
+		return nil, nil
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(input, "%d", &index); err != nil || index < 1 || index > len(files) {
+		return nil, fmt.Errorf("invalid selection")
+	}
+	return &files[index-1], nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDeletedFiles(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Add schema", "db/schema.sql", "v1\n", "db/seed.sql", "seed\n", "README.md", "readme\n")
+	r.Git("rm", "--quiet", "db/schema.sql")
+	first := r.Commit("Drop schema")
+	r.Commit("Add schema again", "db/schema.sql", "v2\n")
+	r.Git("rm", "--quiet", "db/schema.sql", "db/seed.sql")
+	last := r.Commit("Drop the database: seeds and schema")
+
+	files, err := findDeletedFiles("*.sql")
+	require.NoError(t, err)
+	require.Len(t, files, 2, "only the most recent deletion of each path")
+	assert.Equal(t, DeletedFile{Path: "db/schema.sql", Commit: last, Author: "Test", Date: files[0].Date, Subject: "Drop the database: seeds and schema"}, files[0])
+	assert.Equal(t, "db/seed.sql", files[1].Path)
+	assert.Equal(t, last, files[1].Commit)
+	assert.NotEqual(t, first, files[0].Commit)
+
+	files, err = findDeletedFiles("seed")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "db/seed.sql", files[0].Path)
+
+	files, err = findDeletedFiles("README")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestRestoreDeletedFileFromSubdirectory(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Commit("Add config", "config/app.yaml", "port: 80\n", "src/main.go", "package main\n")
+	r.Git("rm", "--quiet", "config/app.yaml")
+	r.Commit("Drop config")
+	r.Chdir()
+	require.NoError(t, os.Chdir("src"))
+
+	files, err := findDeletedFiles("app.yaml")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "config/app.yaml", files[0].Path, "paths are relative to the repository root")
+
+	exists, err := deletedFileExists(&files[0])
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, restoreDeletedFile(&files[0]))
+	assert.Equal(t, "port: 80\n", r.ReadFile("config/app.yaml"))
+	assert.NoFileExists(t, "config/app.yaml", "nothing is restored relative to the current directory")
+
+	exists, err = deletedFileExists(&files[0])
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/git"
+	"github.com/EndlessUphill/git-helper/internal/journal"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	gcQuick        bool
+	gcKeepRollback bool
+	gcExpire       string
+	gcAfterRewrite bool
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Shrink the repository after rewriting history",
+	Long: `Free the space still used by history that clean, purge or other rewrites
+replaced, and report the size of the repository before and after.
+
+Old commits stay in the repository as long as something refers to them: the
+reflogs, the refs/original/ refs filter-branch leaves behind and the backups
+'githelper rollback' keeps. gc saves a backup bundle of every ref first, then
+deletes those references, expires the reflogs and repacks aggressively.
+
+Afterwards 'githelper rollback' and 'githelper recover' can no longer bring
+back the old history; restore it from the backup bundle instead (see
+'githelper backup').
+
+Example:
+  githelper gc
+  githelper gc --dry-run           # Show what would be removed
+  githelper gc --quick             # Repack without recomputing deltas
+  githelper gc --keep-rollback     # Keep what 'githelper rollback' needs
+  githelper gc --expire 30.days    # Keep the last 30 days of reflogs`,
+	Args: cobra.NoArgs,
+	RunE: runGC,
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+	gcCmd.Flags().BoolVar(&gcQuick, "quick", false, "repack without recomputing deltas (much faster, less compact)")
+	gcCmd.Flags().BoolVar(&gcKeepRollback, "keep-rollback", false, "keep the backups of 'githelper rollback'")
+	gcCmd.Flags().StringVar(&gcExpire, "expire", "now", "expire reflog entries older than this (e.g. now, 30.days)")
+	gcCmd.Flags().BoolVar(&noBackup, "no-backup", false, "don't create a backup bundle first")
+	gcCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be removed without changing anything")
+
+	cleanCmd.Flags().BoolVar(&gcAfterRewrite, "gc", false, "run 'githelper gc' afterwards to shrink the repository")
+	purgeCmd.Flags().BoolVar(&gcAfterRewrite, "gc", false, "run 'githelper gc' afterwards to shrink the repository")
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	original, err := listRefs("refs/original/")
+	if err != nil {
+		return err
+	}
+	backups, err := listRefs(journal.BackupPrefix)
+	if err != nil {
+		return err
+	}
+	size, err := git.RepoSize(commandCtx, "")
+	if err != nil {
+		return err
+	}
+
+	ui.Stepf("📦 Repository size: %s", formatSize(size.Objects()))
+	printObjectCounts(size)
+	ui.Step("\n🧹 gc will:")
+	if len(original) > 0 {
+		ui.Printf("  - delete %d ref(s) under refs/original/ left by filter-branch\n", len(original))
+	}
+	if len(backups) > 0 && !gcKeepRollback {
+		ui.Printf("  - delete %d rollback backup ref(s) and the rollback journal\n", len(backups))
+	}
+	ui.Printf("  - expire reflog entries older than %s\n", gcExpire)
+	if gcQuick {
+		ui.Println("  - repack and prune unreachable objects")
+	} else {
+		ui.Println("  - repack aggressively and prune unreachable objects")
+	}
+
+	if dryRun {
+		ui.Step("\n🔍 Dry run - nothing was changed")
+		return nil
+	}
+	ui.Warn("\nOld history will no longer be recoverable with rollback or recover.")
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	if err := backupBeforeRewrite(); err != nil {
+		return err
+	}
+	return collectGarbage()
+}
+
+// collectGarbage removes what keeps rewritten history alive, expires the
+// reflogs and repacks, reporting the size before and after. It doesn't
+// confirm or back up.
+func collectGarbage() error {
+	before, err := git.RepoSize(commandCtx, "")
+	if err != nil {
+		return err
+	}
+
+	if err := deleteRefs("refs/original/"); err != nil {
+		return err
+	}
+	if !gcKeepRollback {
+		j, err := journal.Open(commandCtx)
+		if err != nil {
+			return err
+		}
+		if err := j.Clear(commandCtx); err != nil {
+			return err
+		}
+	}
+
+	// Expire every reflog but the stash's, whose entries are the stashes
+	refs, err := listRefs("")
+	if err != nil {
+		return err
+	}
+	expireArgs := []string{"reflog", "expire", "--expire=" + gcExpire, "--expire-unreachable=" + gcExpire, "HEAD"}
+	for _, ref := range refs {
+		if ref != "refs/stash" {
+			expireArgs = append(expireArgs, ref)
+		}
+	}
+	ui.Step("🗓️  Expiring reflogs...")
+	expireCmd := gitCommand(expireArgs...)
+	if output, err := expireCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to expire reflogs: %s", strings.TrimSpace(string(output)))
+	}
+
+	ui.Step("📦 Repacking...")
+	gcArgs := []string{"gc", "--prune=" + gcExpire}
+	if !gcQuick {
+		gcArgs = append(gcArgs, "--aggressive")
+	}
+	repackCmd := gitCommand(gcArgs...)
+	repackCmd.Stdout = os.Stdout
+	repackCmd.Stderr = os.Stderr
+	if err := repackCmd.Run(); err != nil {
+		return fmt.Errorf("failed to repack: %w", err)
+	}
+
+	after, err := git.RepoSize(commandCtx, "")
+	if err != nil {
+		return err
+	}
+	saved := before.Objects() - after.Objects()
+	ui.Printf("✅ Repository size: %s → %s", formatSize(before.Objects()), formatSize(after.Objects()))
+	if saved > 0 {
+		ui.Printf(" (saved %s)", formatSize(saved))
+	}
+	ui.Println()
+	return nil
+}
+
+// gcAfterHistoryRewrite runs gc after clean or purge when --gc or
+// gc.after_rewrite asks for it, and suggests it otherwise. The rewrite was
+// already confirmed and backed up.
+func gcAfterHistoryRewrite() error {
+	if !gcAfterRewrite && !viper.GetBool("gc.after_rewrite") {
+		ui.Step("\n💡 The old history still takes up space until you run 'githelper gc'")
+		return nil
+	}
+	ui.Println()
+	return collectGarbage()
+}
+
+// listRefs returns the refs starting with prefix, or every ref when empty
+func listRefs(prefix string) ([]string, error) {
+	args := []string{"for-each-ref", "--format=%(refname)"}
+	if prefix != "" {
+		args = append(args, prefix)
+	}
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+func deleteRefs(prefix string) error {
+	refs, err := listRefs(prefix)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if output, err := gitCommand("update-ref", "-d", ref).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to delete %s: %s", ref, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/journal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "initial")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.WriteFile("test.txt", []byte("rewritten away"), 0644))
+	runGit(t, tmpDir, "commit", "-am", "to be dropped")
+	dropped, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	require.NoError(t, err)
+	require.NoError(t, recordOperation("test", false))
+	runGit(t, tmpDir, "reset", "--hard", "HEAD~1")
+	runGit(t, tmpDir, "update-ref", "refs/original/refs/heads/old", "HEAD")
+	require.NoError(t, os.WriteFile("test.txt", []byte("stashed"), 0644))
+	runGit(t, tmpDir, "stash")
+
+	require.NoError(t, collectGarbage())
+
+	original, err := listRefs("refs/original/")
+	require.NoError(t, err)
+	assert.Empty(t, original)
+	backups, err := listRefs(journal.BackupPrefix)
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+	// The dropped commit is gone, the stash is kept
+	assert.Error(t, exec.Command("git", "cat-file", "-e", strings.TrimSpace(string(dropped))).Run())
+	assert.NoError(t, exec.Command("git", "rev-parse", "--verify", "-q", "stash@{0}").Run())
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/EndlessUphill/git-helper/internal/version"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var (
+	genManDir      string
+	genMarkdownDir string
+)
+
+// genDocsCmd is run by 'make docs' when packaging, so it isn't listed in help
+var genDocsCmd = &cobra.Command{
+	Use:   "gen-docs",
+	Short: "Generate man pages and markdown reference docs",
+	Long: `Write a man page and a markdown page for every command, e.g.
+githelper-copy.1 and githelper_copy.md, from the help text of the commands.
+
+Set SOURCE_DATE_EPOCH for reproducible man page dates.
+
+Example:
+  githelper gen-docs --man-dir bin/man --markdown-dir bin/docs`,
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runGenDocs,
+}
+
+func init() {
+	rootCmd.AddCommand(genDocsCmd)
+	genDocsCmd.Flags().StringVar(&genManDir, "man-dir", "", "directory for the man pages")
+	genDocsCmd.Flags().StringVar(&genMarkdownDir, "markdown-dir", "", "directory for the markdown pages")
+}
+
+func runGenDocs(cmd *cobra.Command, args []string) error {
+	if genManDir == "" && genMarkdownDir == "" {
+		return fmt.Errorf("set --man-dir, --markdown-dir or both")
+	}
+	root := cmd.Root()
+	// The generated files only change when the commands do
+	root.DisableAutoGenTag = true
+
+	if genManDir != "" {
+		if err := os.MkdirAll(genManDir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", genManDir, err)
+		}
+		header := &doc.GenManHeader{
+			Title:   "GITHELPER",
+			Section: "1",
+			Source:  "githelper " + version.Version,
+			Manual:  "GitHelper Manual",
+		}
+		if err := doc.GenManTree(root, header, genManDir); err != nil {
+			return fmt.Errorf("failed to generate man pages: %w", err)
+		}
+		ui.Successf("Man pages written to %s", genManDir)
+	}
+
+	if genMarkdownDir != "" {
+		if err := os.MkdirAll(genMarkdownDir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", genMarkdownDir, err)
+		}
+		if err := doc.GenMarkdownTree(root, genMarkdownDir); err != nil {
+			return fmt.Errorf("failed to generate markdown: %w", err)
+		}
+		ui.Successf("Markdown written to %s", genMarkdownDir)
+	}
+	return nil
+}
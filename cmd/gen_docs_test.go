@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenDocs(t *testing.T) {
+	dir := t.TempDir()
+	genManDir = filepath.Join(dir, "man")
+	genMarkdownDir = filepath.Join(dir, "markdown")
+	defer func() { genManDir, genMarkdownDir = "", "" }()
+
+	require.NoError(t, runGenDocs(genDocsCmd, nil))
+
+	page, err := os.ReadFile(filepath.Join(genManDir, "githelper-copy.1"))
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(page), "githelper-copy"))
+	assert.FileExists(t, filepath.Join(genMarkdownDir, "githelper_copy.md"))
+
+	// Hidden commands stay out of the docs
+	assert.NoFileExists(t, filepath.Join(genManDir, "githelper-gen-docs.1"))
+}
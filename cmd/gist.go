@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/secrets"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gistName        string
+	gistDescription string
+	gistPublic      bool
+	gistLimit       int
+)
+
+var gistCmd = &cobra.Command{
+	Use:   "gist",
+	Short: "Create, list and clone GitHub gists",
+	Long: `Share snippets and command output as GitHub gists.
+
+Gists use the same token as every other command (see 'githelper auth').
+
+Example:
+  some-command | githelper gist create --name out.txt
+  githelper gist create main.go util.go -d "Repro for #42"
+  githelper gist list
+  githelper gist clone 5b7f0c3a`,
+}
+
+var gistCreateCmd = &cobra.Command{
+	Use:   "create [file...]",
+	Short: "Create a gist from files or standard input",
+	Long: `Create a gist from files, or from standard input when no file (or "-") is
+given. Gists are secret unless --public is passed.
+
+The content is checked for secrets such as tokens and private keys before it
+is uploaded.
+
+Example:
+  go test ./... 2>&1 | githelper gist create --name test-output.txt
+  githelper gist create config.yaml -d "Working config" --public`,
+	RunE: runGistCreate,
+}
+
+var gistListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List your gists",
+	Args:  cobra.NoArgs,
+	RunE:  runGistList,
+}
+
+var gistCloneCmd = &cobra.Command{
+	Use:   "clone <id|url> [directory]",
+	Short: "Clone a gist as a git repository",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE:  runGistClone,
+}
+
+func init() {
+	rootCmd.AddCommand(gistCmd)
+	gistCmd.AddCommand(gistCreateCmd)
+	gistCmd.AddCommand(gistListCmd)
+	gistCmd.AddCommand(gistCloneCmd)
+
+	gistCreateCmd.Flags().StringVar(&gistName, "name", "snippet.txt", "file name for content read from standard input")
+	gistCreateCmd.Flags().StringVarP(&gistDescription, "description", "d", "", "description of the gist")
+	gistCreateCmd.Flags().BoolVar(&gistPublic, "public", false, "make the gist public")
+
+	gistListCmd.Flags().IntVarP(&gistLimit, "limit", "n", 30, "maximum number of gists to list")
+}
+
+// gistClient connects to the default host
+func gistClient() (*github.Client, error) {
+	host, err := resolveHost("")
+	if err != nil {
+		return nil, err
+	}
+	return newHostClient(host)
+}
+
+func runGistCreate(cmd *cobra.Command, args []string) error {
+	files, err := readGistFiles(args, os.Stdin, gistName)
+	if err != nil {
+		return err
+	}
+
+	var found []secrets.Finding
+	for _, name := range sortedKeys(files) {
+		for i, line := range strings.Split(files[name], "\n") {
+			for _, finding := range secrets.ScanLine(line) {
+				finding.File, finding.Line = name, i+1
+				found = append(found, finding)
+			}
+		}
+	}
+	if len(found) > 0 {
+		ui.Warn("Possible secrets:")
+		for _, finding := range found {
+			ui.Printf("  %s: %s (%s)\n", finding.Location(), finding.Rule.Description, finding.Match)
+		}
+		if !confirmAction() {
+			ui.Error("Operation cancelled")
+			return nil
+		}
+	}
+
+	client, err := gistClient()
+	if err != nil {
+		return err
+	}
+	visibility := "secret"
+	if gistPublic {
+		visibility = "public"
+	}
+	ui.Stepf("📤 Creating %s gist with %d file(s)...", visibility, len(files))
+	gist, err := client.CreateGist(context.Background(), gistDescription, gistPublic, files)
+	if err != nil {
+		return fmt.Errorf("failed to create gist: %w", err)
+	}
+	ui.Successf("%s", gist.URL)
+	return nil
+}
+
+// readGistFiles reads the named files, or stdin under stdinName for "-" or
+// when no file is given
+func readGistFiles(paths []string, stdin io.Reader, stdinName string) (map[string]string, error) {
+	if len(paths) == 0 {
+		if file, ok := stdin.(*os.File); ok {
+			if info, err := file.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+				return nil, fmt.Errorf("give the files to upload, or pipe content into the command")
+			}
+		}
+		paths = []string{"-"}
+	}
+
+	files := map[string]string{}
+	for _, path := range paths {
+		name := filepath.Base(path)
+		var content []byte
+		var err error
+		if path == "-" {
+			name = stdinName
+			content, err = io.ReadAll(stdin)
+		} else {
+			content, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if _, exists := files[name]; exists {
+			return nil, fmt.Errorf("two files are named '%s'; gists can't contain directories", name)
+		}
+		if strings.TrimSpace(string(content)) == "" {
+			return nil, fmt.Errorf("%s is empty", name)
+		}
+		files[name] = string(content)
+	}
+	return files, nil
+}
+
+func runGistList(cmd *cobra.Command, args []string) error {
+	client, err := gistClient()
+	if err != nil {
+		return err
+	}
+	gists, err := client.ListGists(context.Background(), gistLimit)
+	if err != nil {
+		return fmt.Errorf("failed to list gists: %w", err)
+	}
+	if len(gists) == 0 {
+		ui.Println("No gists found")
+		return nil
+	}
+
+	for _, gist := range gists {
+		visibility := "secret"
+		if gist.Public {
+			visibility = "public"
+		}
+		description := gist.Description
+		if description == "" {
+			description = strings.Join(gist.Files, ", ")
+		}
+		ui.Printf("%-32s %-6s %-10s %s\n", gist.ID, visibility, timeAgo(gist.Updated), description)
+	}
+	return nil
+}
+
+func runGistClone(cmd *cobra.Command, args []string) error {
+	id := gistID(args[0])
+	client, err := gistClient()
+	if err != nil {
+		return err
+	}
+	gist, err := client.GetGist(context.Background(), id)
+	if err != nil {
+		return fmt.Errorf("failed to get gist %s: %w", id, err)
+	}
+
+	cloneArgs := []string{"clone", gist.CloneURL}
+	if len(args) > 1 {
+		cloneArgs = append(cloneArgs, args[1])
+	}
+	ui.Stepf("📥 Cloning gist %s...", id)
+	cloneCmd := gitCommand(cloneArgs...)
+	cloneCmd.Stdout = os.Stdout
+	cloneCmd.Stderr = os.Stderr
+	if err := cloneCmd.Run(); err != nil {
+		return fmt.Errorf("failed to clone gist: %w", err)
+	}
+	ui.Success("Gist cloned")
+	return nil
+}
+
+// gistID extracts the ID from a gist URL, e.g.
+// https://gist.github.com/user/5b7f0c3a, or returns id unchanged
+func gistID(id string) string {
+	id = strings.TrimSuffix(strings.TrimSuffix(id, "/"), ".git")
+	return id[strings.LastIndex(id, "/")+1:]
+}
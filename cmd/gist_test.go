@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadGistFiles(t *testing.T) {
+	files, err := readGistFiles(nil, strings.NewReader("output\n"), "out.txt")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"out.txt": "output\n"}, files)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0644))
+	files, err = readGistFiles([]string{path, "-"}, strings.NewReader("log"), "log.txt")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"main.go": "package main\n", "log.txt": "log"}, files)
+
+	_, err = readGistFiles([]string{path, path}, nil, "")
+	assert.ErrorContains(t, err, "two files are named")
+
+	_, err = readGistFiles(nil, strings.NewReader("  \n"), "empty.txt")
+	assert.ErrorContains(t, err, "empty")
+}
+
+func TestGistID(t *testing.T) {
+	assert.Equal(t, "5b7f0c3a", gistID("5b7f0c3a"))
+	assert.Equal(t, "5b7f0c3a", gistID("https://gist.github.com/octo/5b7f0c3a"))
+	assert.Equal(t, "5b7f0c3a", gistID("https://gist.github.com/5b7f0c3a.git"))
+}
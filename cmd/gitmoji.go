@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/gitmoji"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var commitGitmoji bool
+
+var gitmojiCmd = &cobra.Command{
+	Use:   "gitmoji [search]",
+	Short: "List the gitmoji and the commit types they stand for",
+	Long: `List the gitmoji githelper uses in commit messages, with their shortcode and
+the conventional commit type each one maps to. Words filter the list.
+
+Gitmoji mode is enabled with use_gitmoji in ~/.githelper.yaml, or --gitmoji
+on a single commit. 'githelper commit' then picks a gitmoji instead of a
+commit type, and --ai starts its messages with one. gitmoji_format: shortcode
+writes :sparkles: instead of ✨.
+
+Example:
+  githelper gitmoji               # The whole table
+  githelper gitmoji fix           # Gitmoji for fixes
+  githelper config set use_gitmoji true`,
+	RunE: runGitmoji,
+}
+
+func init() {
+	rootCmd.AddCommand(gitmojiCmd)
+	commitCmd.Flags().BoolVar(&commitGitmoji, "gitmoji", false, "start the message with a gitmoji (default is use_gitmoji)")
+}
+
+// useGitmoji reports whether commit messages start with a gitmoji
+func useGitmoji() bool {
+	return commitGitmoji || viper.GetBool("use_gitmoji")
+}
+
+// gitmojiShortcodes reports whether gitmoji are written as :shortcodes:
+func gitmojiShortcodes() bool {
+	return viper.GetString("gitmoji_format") == "shortcode"
+}
+
+func runGitmoji(cmd *cobra.Command, args []string) error {
+	list := gitmoji.All
+	if len(args) > 0 {
+		list = gitmoji.Search(strings.Join(args, " "))
+		if len(list) == 0 {
+			return fmt.Errorf("no gitmoji matches '%s'", strings.Join(args, " "))
+		}
+	}
+	for _, g := range list {
+		ui.Printf("%s  %-24s %-9s %s\n", g.Emoji, g.Code, g.Type, g.Description)
+	}
+	return nil
+}
+
+// withGitmoji starts a commit message with the gitmoji of its conventional
+// type, or rewrites the gitmoji it starts with in the configured format.
+// Messages without either are returned unchanged.
+func withGitmoji(message string) string {
+	shortcode := gitmojiShortcodes()
+	if g, rest, ok := gitmoji.Prefix(message); ok {
+		return g.String(shortcode) + " " + rest
+	}
+	header, _, _ := strings.Cut(message, "\n")
+	g, ok := gitmoji.ForType(parseConventionalHeader(header).Type)
+	if !ok {
+		return message
+	}
+	return g.String(shortcode) + " " + message
+}
+
+// chooseGitmoji resolves an answer to the gitmoji prompt: a number in the
+// list shown, a commit type, a shortcode or emoji, or search words
+func chooseGitmoji(input string, shown []gitmoji.Gitmoji) []gitmoji.Gitmoji {
+	if n, err := strconv.Atoi(input); err == nil {
+		if n < 1 || n > len(shown) {
+			return nil
+		}
+		return shown[n-1 : n]
+	}
+	if g, ok := gitmoji.ForType(input); ok {
+		return []gitmoji.Gitmoji{g}
+	}
+	return gitmoji.Search(input)
+}
+
+// pickGitmoji asks for the gitmoji of a commit, with fzf when it is
+// installed. It returns false when none was picked.
+func pickGitmoji() (gitmoji.Gitmoji, bool) {
+	if _, err := exec.LookPath("fzf"); err == nil && isInteractive() {
+		return pickGitmojiWithFzf()
+	}
+
+	shown := gitmoji.All
+	for {
+		ui.Println("Gitmoji:")
+		for i, g := range shown {
+			ui.Printf("%2d. %s  %-9s %s\n", i+1, g.Emoji, g.Type, g.Description)
+		}
+		input := readInput("\nEnter a number, commit type or search words (Enter to skip): ")
+		if input == "" {
+			return gitmoji.Gitmoji{}, false
+		}
+		switch found := chooseGitmoji(input, shown); len(found) {
+		case 0:
+			ui.Warnf("No gitmoji matches '%s'", input)
+			shown = gitmoji.All
+		case 1:
+			return found[0], true
+		default:
+			shown = found
+		}
+	}
+}
+
+func pickGitmojiWithFzf() (gitmoji.Gitmoji, bool) {
+	var input strings.Builder
+	for _, g := range gitmoji.All {
+		fmt.Fprintf(&input, "%s %s %s - %s\n", g.Emoji, g.Code, g.Type, g.Description)
+	}
+
+	fzfCmd := exec.Command("fzf", "--height", "50%", "--reverse", "--prompt", "gitmoji> ")
+	fzfCmd.Stdin = strings.NewReader(input.String())
+	fzfCmd.Stderr = os.Stderr
+	output, err := fzfCmd.Output()
+	if err != nil {
+		return gitmoji.Gitmoji{}, false // User cancelled
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) < 2 {
+		return gitmoji.Gitmoji{}, false
+	}
+	found := gitmoji.Search(fields[1])
+	if len(found) != 1 {
+		return gitmoji.Gitmoji{}, false
+	}
+	return found[0], true
+}
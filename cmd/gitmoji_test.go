@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/gitmoji"
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithGitmoji(t *testing.T) {
+	defer viper.Reset()
+	assert.Equal(t, "✨ feat(api): add search\n\nBody", withGitmoji("feat(api): add search\n\nBody"))
+	assert.Equal(t, "🚑️ Fix the login", withGitmoji(":ambulance: Fix the login"))
+	assert.Equal(t, "Update readme", withGitmoji("Update readme"))
+
+	viper.Set("gitmoji_format", "shortcode")
+	assert.Equal(t, ":bug: fix: handle nil", withGitmoji("fix: handle nil"))
+	assert.Equal(t, ":bug: fix: handle nil", withGitmoji("🐛 fix: handle nil"))
+}
+
+func TestChooseGitmoji(t *testing.T) {
+	shown := gitmoji.Search("dependency")
+	assert.Equal(t, []gitmoji.Gitmoji{shown[1]}, chooseGitmoji("2", shown))
+	assert.Empty(t, chooseGitmoji("9", shown))
+	assert.Equal(t, "🐛", chooseGitmoji("fix", gitmoji.All)[0].Emoji)
+	assert.Equal(t, "🔥", chooseGitmoji("remove code", gitmoji.All)[0].Emoji)
+	assert.Greater(t, len(chooseGitmoji("ci", gitmoji.All)), 0)
+}
+
+func TestCommitGitmoji(t *testing.T) {
+	defer viper.Reset()
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.WriteFile("main.go", "package main\n")
+	r.Git("add", "main.go")
+
+	_, _, err := execute(t, "commit", "--type", "fix", "--gitmoji", "-m", "add main", "--no-edit")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(r.Log("HEAD")[0], "🐛 fix: add main"), r.Log("HEAD")[0])
+
+	// Without a terminal there is no picker: fail once, before asking
+	r.WriteFile("util.go", "package main\n")
+	r.Git("add", "util.go")
+	stdout, stderr, err := execute(t, "commit", "--gitmoji", "-m", "add util")
+	assert.ErrorContains(t, err, "pass --type")
+	assert.Equal(t, 1, strings.Count(stdout+stderr, "pass --type"))
+	assert.NotContains(t, stdout, "Gitmoji:")
+	assert.Len(t, r.Log("HEAD"), 1)
+
+	stdout, _, err = execute(t, "gitmoji", "typos")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, ":pencil2:")
+}
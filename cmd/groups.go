@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// commandGroup is a section of the command list in 'githelper --help'
+type commandGroup struct {
+	ID       string
+	Title    string
+	Commands []string
+	// Examples are shown in the root help, one short command line each
+	Examples []string
+}
+
+// commandGroups files every top-level command under a section. A new command
+// must be added here; TestCommandGroups checks that none is left out.
+var commandGroups = []commandGroup{
+	{
+		ID:    "branching",
+		Title: "Branching and Syncing:",
+		Commands: []string{"start", "switch", "sync", "sync-fork", "push", "preflight", "precheck", "compare",
+			"cherry-pick", "patch", "resolve", "prune", "prune-remotes", "branch", "rename-branch", "default-branch", "tag", "worktree", "sparse"},
+		Examples: []string{
+			"githelper start 123             # Branch off for issue #123",
+			"githelper sync                  # Pull and push safely",
+		},
+	},
+	{
+		ID:    "history",
+		Title: "History Rewriting:",
+		Commands: []string{"squash", "reword", "split", "revert", "clean", "purge", "rewrite-author",
+			"resign", "lint-history"},
+		Examples: []string{
+			"githelper squash 3              # Squash the last three commits",
+			"githelper purge .env            # Remove a secret from all history",
+		},
+	},
+	{
+		ID:    "recovery",
+		Title: "Recovery and Safety Nets:",
+		Commands: []string{"rollback", "undo", "recover", "rescue", "restore", "rollback-file", "find-deleted",
+			"at", "snapshot", "watch", "backup", "restore-archive", "log"},
+		Examples: []string{
+			"githelper rollback              # Undo the last destructive command",
+			"githelper recover               # Find commits lost to a reset",
+		},
+	},
+	{
+		ID:    "github",
+		Title: "GitHub:",
+		Commands: []string{"pr", "inbox", "checks", "deploy", "release", "repo", "clone", "copy", "mirror",
+			"gist", "labels", "policy", "open", "link", "feed"},
+		Examples: []string{
+			"githelper pr status             # Reviews and checks of your PR",
+			"githelper inbox                 # Review requests and notifications",
+		},
+	},
+	{
+		ID:       "ai",
+		Title:    "AI Assistance:",
+		Commands: []string{"commit", "gitmoji", "explain"},
+		Examples: []string{
+			"githelper commit --ai           # Write the commit message for you",
+			"githelper explain               # What state is my repository in?",
+		},
+	},
+	{
+		ID:       "inspect",
+		Title:    "Inspecting History:",
+		Commands: []string{"blame", "search", "bisect", "stats", "owners", "handoff", "verify"},
+		Examples: []string{
+			"githelper search \"API_KEY\"      # Commits that added or removed text",
+		},
+	},
+	{
+		ID:       "maintenance",
+		Title:    "Repository Maintenance:",
+		Commands: []string{"gc", "maintenance", "refresh", "clean-workdir", "eol", "archive"},
+		Examples: []string{
+			"githelper maintenance enable    # Keep a large repository fast",
+		},
+	},
+	{
+		ID:    "setup",
+		Title: "Setup and Configuration:",
+		Commands: []string{"setup", "auth", "config", "profile", "run", "hooks", "jobs", "plugin", "tips", "version",
+			"help", "completion"},
+		Examples: []string{
+			"githelper setup                 # First-time configuration",
+			"githelper tips                  # Commands that help right now",
+		},
+	},
+}
+
+// applyCommandGroups sorts the top-level commands into their groups and
+// lists examples per group in the root help. It runs once every command is
+// registered.
+func applyCommandGroups(root *cobra.Command) {
+	groupOf := map[string]string{}
+	var examples []string
+	for _, group := range commandGroups {
+		root.AddGroup(&cobra.Group{ID: group.ID, Title: group.Title})
+		for _, name := range group.Commands {
+			groupOf[name] = group.ID
+		}
+		if len(examples) > 0 {
+			examples = append(examples, "")
+		}
+		examples = append(examples, fmt.Sprintf("  # %s", strings.TrimSuffix(group.Title, ":")))
+		for _, example := range group.Examples {
+			examples = append(examples, "  "+example)
+		}
+	}
+	root.Example = strings.Join(examples, "\n")
+
+	// help and completion are created by cobra when it runs
+	root.SetHelpCommandGroupID(groupOf["help"])
+	root.SetCompletionCommandGroupID(groupOf["completion"])
+	for _, c := range root.Commands() {
+		if id, ok := groupOf[c.Name()]; ok {
+			c.GroupID = id
+		}
+	}
+}
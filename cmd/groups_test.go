@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandGroups(t *testing.T) {
+	grouped := map[string]bool{}
+	for _, group := range commandGroups {
+		for _, name := range group.Commands {
+			assert.False(t, grouped[name], "%s is in two groups", name)
+			grouped[name] = true
+		}
+	}
+
+	registered := map[string]bool{"help": true, "completion": true}
+	for _, c := range rootCmd.Commands() {
+		if c.Hidden {
+			continue
+		}
+		registered[c.Name()] = true
+		assert.True(t, grouped[c.Name()], "%s is in no group of commandGroups", c.Name())
+	}
+	for name := range grouped {
+		assert.True(t, registered[name], "%s in commandGroups is not a command", name)
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// assumeYes skips safety confirmations so commands can run in automation
+var assumeYes bool
+
+// defaultDangerousOperations are the commands that require typing the
+// repository name when safety.confirm_repo_name is enabled
+var defaultDangerousOperations = []string{"undo", "purge", "clean", "rewrite-author"}
+
+// isProtectedBranch reports whether branch matches one of the
+// safety.protected_branches patterns (e.g. "main" or "release/*")
+func isProtectedBranch(branch string) bool {
+	for _, pattern := range viper.GetStringSlice("safety.protected_branches") {
+		if ok, _ := path.Match(pattern, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// guardOperation consults the safety section of .githelper.yaml before a
+// command rewrites or force-pushes the given branches. It refuses to touch
+// protected branches and, for dangerous operations, asks the user to type the
+// repository name. --yes skips both checks.
+func guardOperation(operation string, branches ...string) error {
+	if assumeYes {
+		return nil
+	}
+
+	var protected []string
+	for _, branch := range branches {
+		if isProtectedBranch(branch) {
+			protected = append(protected, branch)
+		}
+	}
+	if len(protected) > 0 {
+		return fmt.Errorf("'%s' would rewrite protected branch(es): %s. Pass --yes to override",
+			operation, strings.Join(protected, ", "))
+	}
+
+	if !viper.GetBool("safety.confirm_repo_name") || !isDangerousOperation(operation) {
+		return nil
+	}
+
+	name := repoName()
+	input := readInput(fmt.Sprintf("\n🔒 '%s' is a dangerous operation. Type the repository name (%s) to continue: ", operation, name))
+	if input != name {
+		return fmt.Errorf("repository name did not match, '%s' cancelled", operation)
+	}
+	return nil
+}
+
+func isDangerousOperation(operation string) bool {
+	operations := viper.GetStringSlice("safety.dangerous_operations")
+	if len(operations) == 0 {
+		operations = defaultDangerousOperations
+	}
+	for _, op := range operations {
+		if op == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// repoName returns the repository name from the origin URL, or the name of
+// the top-level directory when there is no origin
+func repoName() string {
+	if url, err := getOriginURL(); err == nil && url != "" {
+		url = strings.TrimSuffix(url, ".git")
+		url = strings.TrimSuffix(url, "/")
+		if i := strings.LastIndexAny(url, "/:"); i >= 0 {
+			return url[i+1:]
+		}
+		return url
+	}
+
+	output, err := gitCommand("rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(strings.TrimSpace(string(output)))
+}
+
+// getLocalBranches returns the names of all local branches
+func getLocalBranches() ([]string, error) {
+	output, err := gitCommand("for-each-ref", "refs/heads", "--format=%(refname:short)").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+
+	var branches []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			branches = append(branches, line)
+		}
+	}
+	return branches, nil
+}
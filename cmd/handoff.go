@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	handoffThreshold  float64
+	handoffGitHubUser string
+	handoffOutput     string
+)
+
+// OwnedPath is a file or directory where an author wrote most of the lines
+type OwnedPath struct {
+	Path  string
+	Lines int
+	Total int
+}
+
+// Share returns the percentage of the lines written by the author
+func (o OwnedPath) Share() float64 {
+	return float64(o.Lines) * 100 / float64(o.Total)
+}
+
+// OwnedBranch is an unmerged branch whose last commit is the author's
+type OwnedBranch struct {
+	Name    string
+	Ahead   int
+	Updated string
+}
+
+var handoffCmd = &cobra.Command{
+	Use:   "handoff <author> [path]",
+	Short: "Report what a departing teammate owns, as markdown",
+	Long: `Write a markdown report of what a teammate owns, to plan their handoff:
+  - the files and directories where they wrote most of the current lines,
+    from git blame (ignoring the revisions in .git-blame-ignore-revs)
+  - the branches not merged into the default branch whose last commit is theirs
+  - their open pull requests on GitHub
+
+The author matches names and emails, case-insensitively and partially, so
+"alice" matches "Alice Smith <alice@example.com>". Pull requests are found
+with --github-user, which defaults to the author when it looks like a login.
+
+Example:
+  githelper handoff alice
+  githelper handoff alice@example.com internal/ --threshold 75
+  githelper handoff "Alice Smith" --github-user asmith -o handoff.md`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runHandoff,
+}
+
+func init() {
+	rootCmd.AddCommand(handoffCmd)
+	handoffCmd.Flags().Float64Var(&handoffThreshold, "threshold", 50, "share of the lines, in percent, above which the author owns a file")
+	handoffCmd.Flags().StringVar(&handoffGitHubUser, "github-user", "", "GitHub login of the author (default: the author, if it looks like a login)")
+	handoffCmd.Flags().StringVarP(&handoffOutput, "output", "o", "", "write the report to a file instead of standard output")
+}
+
+func runHandoff(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	author := args[0]
+	scope := "."
+	if len(args) > 1 {
+		scope = args[1]
+	}
+
+	files, err := listBlameFiles(scope)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ui.Err(), "🔍 Collecting blame information for %d file(s)...\n", len(files))
+	stats, err := collectBlameStats(files)
+	if err != nil {
+		return err
+	}
+	var identities []string
+	for _, a := range stats.SortedAuthors() {
+		if authorMatches(a.Name, a.Email, author) {
+			identities = append(identities, fmt.Sprintf("%s <%s>", a.Name, a.Email))
+		}
+	}
+	ownedFiles, ownedDirs := ownedPaths(stats, author, handoffThreshold)
+
+	base, err := defaultBranch()
+	if err != nil {
+		return fmt.Errorf("failed to find the default branch: %w", err)
+	}
+	baseRef := "origin/" + base
+	if gitCommand("rev-parse", "--verify", "-q", baseRef).Run() != nil {
+		baseRef = base
+	}
+	branches, err := ownedBranches(author, baseRef)
+	if err != nil {
+		return err
+	}
+
+	login := handoffGitHubUser
+	if login == "" && !strings.ContainsAny(author, " @") {
+		login = author
+	}
+	var prs []github.PullRequest
+	var prError error
+	if login != "" {
+		prs, prError = handoffPullRequests(login)
+	}
+
+	out := ui.Out()
+	if handoffOutput != "" {
+		file, err := os.Create(handoffOutput)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", handoffOutput, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	fmt.Fprintf(out, "# Handoff report: %s\n\n", author)
+	fmt.Fprintf(out, "Generated on %s from `%s`, branch `%s`.\n\n", time.Now().Format("2006-01-02"), repoName(), base)
+	if len(identities) > 0 {
+		fmt.Fprintf(out, "Matched authors: %s\n\n", strings.Join(identities, ", "))
+	} else {
+		fmt.Fprintf(out, "No lines in %s are authored by %s.\n\n", scope, author)
+	}
+
+	fmt.Fprintf(out, "## Directories\n\n")
+	writeOwnedTable(out, "Directory", ownedDirs)
+	fmt.Fprintf(out, "## Files\n\n")
+	writeOwnedTable(out, "File", ownedFiles)
+
+	fmt.Fprintf(out, "## Unmerged branches\n\n")
+	if len(branches) == 0 {
+		fmt.Fprintf(out, "None.\n\n")
+	} else {
+		fmt.Fprintf(out, "| Branch | Commits ahead of %s | Last commit |\n|---|---:|---|\n", base)
+		for _, b := range branches {
+			fmt.Fprintf(out, "| `%s` | %d | %s |\n", b.Name, b.Ahead, b.Updated)
+		}
+		fmt.Fprintln(out)
+	}
+
+	fmt.Fprintf(out, "## Open pull requests\n\n")
+	switch {
+	case login == "":
+		fmt.Fprintf(out, "Not checked: pass --github-user with their GitHub login.\n")
+	case prError != nil:
+		fmt.Fprintf(out, "Not checked: %v\n", prError)
+	case len(prs) == 0:
+		fmt.Fprintf(out, "None by @%s.\n", login)
+	default:
+		fmt.Fprintf(out, "| Pull request | Branch | Updated |\n|---|---|---|\n")
+		for _, pr := range prs {
+			title := pr.Title
+			if pr.Draft {
+				title += " (draft)"
+			}
+			fmt.Fprintf(out, "| [#%d %s](%s) | `%s` | %s |\n", pr.Number, escapeTableCell(title), pr.URL, pr.Branch, pr.Updated.Format("2006-01-02"))
+		}
+	}
+
+	if handoffOutput != "" {
+		ui.Successf("Wrote the handoff report to %s", handoffOutput)
+	}
+	return nil
+}
+
+// authorMatches reports whether a name or email matches the query, partially
+// and case-insensitively
+func authorMatches(name, email, query string) bool {
+	query = strings.ToLower(query)
+	return strings.Contains(strings.ToLower(name), query) || strings.Contains(strings.ToLower(email), query)
+}
+
+// ownedPaths returns the files and directories where the author wrote at
+// least threshold percent of the lines, the largest first. Only the topmost
+// owned directories are listed.
+func ownedPaths(stats *BlameStats, author string, threshold float64) (files, dirs []OwnedPath) {
+	dirLines := map[string]*OwnedPath{}
+	for file, lines := range stats.Files {
+		owned := OwnedPath{Path: file, Total: len(lines)}
+		for _, line := range lines {
+			if authorMatches(line.Author, line.Email, author) {
+				owned.Lines++
+			}
+		}
+		if owned.Total == 0 {
+			continue
+		}
+		if owned.Lines > 0 && owned.Share() >= threshold {
+			files = append(files, owned)
+		}
+		for dir := path.Dir(file); dir != "."; dir = path.Dir(dir) {
+			d, ok := dirLines[dir]
+			if !ok {
+				d = &OwnedPath{Path: dir + "/"}
+				dirLines[dir] = d
+			}
+			d.Lines += owned.Lines
+			d.Total += owned.Total
+		}
+	}
+
+	for dir, d := range dirLines {
+		if d.Lines == 0 || d.Share() < threshold {
+			continue
+		}
+		// Skip directories inside an owned one
+		parentOwned := false
+		for parent := path.Dir(dir); parent != "."; parent = path.Dir(parent) {
+			if p := dirLines[parent]; p.Lines > 0 && p.Share() >= threshold {
+				parentOwned = true
+				break
+			}
+		}
+		if !parentOwned {
+			dirs = append(dirs, *d)
+		}
+	}
+	sortOwned(files)
+	sortOwned(dirs)
+	return files, dirs
+}
+
+func sortOwned(paths []OwnedPath) {
+	sort.Slice(paths, func(i, j int) bool {
+		if paths[i].Lines != paths[j].Lines {
+			return paths[i].Lines > paths[j].Lines
+		}
+		return paths[i].Path < paths[j].Path
+	})
+}
+
+func writeOwnedTable(out io.Writer, title string, paths []OwnedPath) {
+	if len(paths) == 0 {
+		fmt.Fprintf(out, "None.\n\n")
+		return
+	}
+	fmt.Fprintf(out, "| %s | Their lines | Share |\n|---|---:|---:|\n", title)
+	for _, p := range paths {
+		fmt.Fprintf(out, "| `%s` | %d of %d | %.0f%% |\n", p.Path, p.Lines, p.Total, p.Share())
+	}
+	fmt.Fprintln(out)
+}
+
+// ownedBranches returns the local and remote branches not merged into base
+// whose last commit is by the author
+func ownedBranches(author, base string) ([]OwnedBranch, error) {
+	output, err := gitCommand("for-each-ref", "--no-merged="+base,
+		"--format=%(refname:short)%09%(symref)%09%(authorname)%09%(authoremail:trim)%09%(committerdate:relative)",
+		"refs/heads", "refs/remotes").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the branches not merged into %s: %w", base, err)
+	}
+
+	var branches []OwnedBranch
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 || fields[1] != "" || !authorMatches(fields[2], fields[3], author) {
+			continue
+		}
+		count, err := gitCommand("rev-list", "--count", base+".."+fields[0]).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s with %s: %w", fields[0], base, err)
+		}
+		var ahead int
+		fmt.Sscanf(string(count), "%d", &ahead)
+		branches = append(branches, OwnedBranch{Name: fields[0], Ahead: ahead, Updated: fields[4]})
+	}
+	return branches, nil
+}
+
+func handoffPullRequests(login string) ([]github.PullRequest, error) {
+	client, owner, name, err := originClient()
+	if err != nil {
+		return nil, err
+	}
+	prs, err := client.OpenPullRequestsBy(context.Background(), owner, name, login)
+	if errors.Is(err, github.ErrUnauthorized) {
+		return nil, errors.New(i18n.T("error.not_authorized"))
+	}
+	return prs, err
+}
+
+// escapeTableCell keeps text from breaking a markdown table
+func escapeTableCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
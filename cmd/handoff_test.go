@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnedPaths(t *testing.T) {
+	alice := BlameLine{Author: "Alice Smith", Email: "alice@example.com"}
+	bob := BlameLine{Author: "Bob", Email: "bob@example.com"}
+	stats := &BlameStats{Files: map[string][]BlameLine{
+		"api/server.go":       {alice, alice, alice},
+		"api/handlers/get.go": {alice, bob},
+		"web/app.js":          {bob, bob, alice},
+		"README.md":           {alice, alice},
+	}}
+
+	files, dirs := ownedPaths(stats, "alice", 50)
+	assert.Equal(t, []OwnedPath{
+		{Path: "api/server.go", Lines: 3, Total: 3},
+		{Path: "README.md", Lines: 2, Total: 2},
+		{Path: "api/handlers/get.go", Lines: 1, Total: 2},
+	}, files)
+	// api/handlers/ is inside api/, which is listed instead
+	assert.Equal(t, []OwnedPath{{Path: "api/", Lines: 4, Total: 5}}, dirs)
+
+	files, dirs = ownedPaths(stats, "BOB@example", 60)
+	assert.Equal(t, []OwnedPath{{Path: "web/app.js", Lines: 2, Total: 3}}, files)
+	assert.Equal(t, []OwnedPath{{Path: "web/", Lines: 2, Total: 3}}, dirs)
+}
+
+func TestOwnedBranches(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	runGit(t, tmpDir, "commit", "-m", "base")
+	runGit(t, tmpDir, "branch", "-M", "main")
+	runGit(t, tmpDir, "branch", "merged")
+	runGit(t, tmpDir, "checkout", "-b", "feature")
+	runGit(t, tmpDir, "commit", "--allow-empty", "-m", "work", "--author", "Alice <alice@example.com>")
+	runGit(t, tmpDir, "commit", "--allow-empty", "-m", "more", "--author", "Alice <alice@example.com>")
+	runGit(t, tmpDir, "checkout", "-b", "other", "main")
+	runGit(t, tmpDir, "commit", "--allow-empty", "-m", "theirs")
+
+	branches, err := ownedBranches("alice", "main")
+	require.NoError(t, err)
+	require.Len(t, branches, 1)
+	assert.Equal(t, "feature", branches[0].Name)
+	assert.Equal(t, 2, branches[0].Ahead)
+}
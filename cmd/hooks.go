@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/config"
+	"github.com/EndlessUphill/git-helper/internal/history"
+	"github.com/EndlessUphill/git-helper/internal/hooks"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// hooksTrustedKey is the git config key holding the checksum of the
+// repository hooks the user allowed to run
+const hooksTrustedKey = "githelper.hooks-trusted"
+
+var noHooks bool
+
+// hooksSkip lists the commands that never run hooks
+var hooksSkip = map[string]bool{
+	"hooks":      true,
+	"help":       true,
+	"completion": true,
+	"__complete": true,
+	"version":    true,
+	"gen-docs":   true,
+}
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "List the hooks run before and after githelper commands",
+	Long: `List the shell commands configured to run around githelper commands.
+
+Hooks are set per command path in the hooks section of .githelper.yaml; "*"
+applies to every command:
+
+  hooks:
+    squash:
+      pre: ["go test ./..."]           # a failing pre hook stops the command
+    release create:
+      post: ['curl -sf -X POST -d "{\"text\": \"Released $GITHELPER_ARGS\"}" "$SLACK_WEBHOOK"']
+    "*":
+      on_error: ["echo \"$GITHELPER_COMMAND failed: $GITHELPER_ERROR\" >> ~/githelper-errors.log"]
+
+Hooks run with the shell from the top of the repository. The operation is
+described by GITHELPER_HOOK (pre, post or on_error), GITHELPER_COMMAND,
+GITHELPER_ARGS, GITHELPER_REPO, GITHELPER_BRANCH, GITHELPER_DRY_RUN, and for
+post and on_error hooks GITHELPER_EXIT_CODE and GITHELPER_ERROR.
+
+Hooks in the repository's .githelper.yaml come with the code, so they only
+run once you have read them and run 'githelper hooks trust'; the same goes for
+its aliases, workflows and precheck commands. --no-hooks skips hooks for one command.
+
+Example:
+  githelper hooks          # The hooks of every command
+  githelper hooks trust    # Allow the repository's hooks to run`,
+	Args: cobra.NoArgs,
+	RunE: runHooksList,
+}
+
+var hooksTrustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "Allow the hooks of the repository's .githelper.yaml to run",
+	Long: `Allow the hooks, aliases, workflows and precheck commands of the
+repository's .githelper.yaml to run. They are trusted as they are now: when they change, they stop running
+until you trust them again.`,
+	Args: cobra.NoArgs,
+	RunE: runHooksTrust,
+}
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksTrustCmd)
+	rootCmd.PersistentFlags().BoolVar(&noHooks, "no-hooks", false, "don't run the hooks configured for the command")
+}
+
+// hookRecording is a command whose post or on_error hooks are still to run
+type hookRecording struct {
+	op    hooks.Operation
+	hooks config.CommandHooks
+}
+
+var hookRun *hookRecording
+
+// configuredHooks returns the hooks section of the config
+func configuredHooks() (map[string]config.CommandHooks, error) {
+	var all map[string]config.CommandHooks
+	if err := viper.UnmarshalKey("hooks", &all); err != nil {
+		return nil, fmt.Errorf("invalid hooks configuration: %w", err)
+	}
+	return all, nil
+}
+
+// hooksFor returns the hooks of a command: those of "*", then its own
+func hooksFor(all map[string]config.CommandHooks, command string) config.CommandHooks {
+	var result config.CommandHooks
+	for _, key := range []string{"*", strings.ToLower(command)} {
+		h := all[key]
+		result.Pre = append(result.Pre, h.Pre...)
+		result.Post = append(result.Post, h.Post...)
+		result.OnError = append(result.OnError, h.OnError...)
+	}
+	return result
+}
+
+// repoCommandsChecksum returns the checksum of the settings of the
+// repository's .githelper.yaml that run commands (see repoTrustedKeys), or ""
+// when it has none
+func repoCommandsChecksum() (string, error) {
+	path := repoConfigFile()
+	if path == "" {
+		return "", nil
+	}
+	settings, err := readConfigSettings(path)
+	if err != nil {
+		return "", err
+	}
+	commands := pickSettings(settings, repoTrustedKeys)
+	if len(commands) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(commands)
+	if err != nil {
+		return "", fmt.Errorf("invalid commands in %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// repoCommandsTrusted reports whether the commands of the repository's
+// .githelper.yaml may run: it has none, or the user trusted them as they are
+func repoCommandsTrusted() (bool, error) {
+	checksum, err := repoCommandsChecksum()
+	if err != nil || checksum == "" {
+		return err == nil, err
+	}
+	trusted, _ := gitCommand("config", "--get", hooksTrustedKey).Output()
+	return strings.TrimSpace(string(trusted)) == checksum, nil
+}
+
+// warnUntrustedHooks warns when the repository's .githelper.yaml has hooks
+// for the command that don't run because they aren't trusted
+func warnUntrustedHooks(command string) error {
+	path := repoConfigFile()
+	if path == "" {
+		return nil
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var repoHooks map[string]config.CommandHooks
+	if err := v.UnmarshalKey("hooks", &repoHooks); err != nil {
+		return fmt.Errorf("invalid hooks in %s: %w", path, err)
+	}
+	if hooksFor(repoHooks, command).Empty() {
+		return nil
+	}
+	trusted, err := repoCommandsTrusted()
+	if err != nil || trusted {
+		return err
+	}
+	ui.Warn("Hooks skipped: the hooks in this repository's .githelper.yaml changed or were never trusted. Review them, then run 'githelper hooks trust'")
+	return nil
+}
+
+// warnUntrustedRepoSetting warns when the repository's .githelper.yaml sets
+// key, one of repoTrustedKeys, but isn't trusted, so it was left out
+func warnUntrustedRepoSetting(key, what string) error {
+	path := repoConfigFile()
+	if path == "" {
+		return nil
+	}
+	settings, err := readConfigSettings(path)
+	if err != nil || len(pickSettings(settings, []string{key})) == 0 {
+		return err
+	}
+	trusted, err := repoCommandsTrusted()
+	if err != nil || trusted {
+		return err
+	}
+	ui.Warnf("The %s of this repository's .githelper.yaml were skipped: they changed or were never trusted. Review them, then run 'githelper hooks trust'", what)
+	return nil
+}
+
+// startHooks runs the pre hooks of the command about to run, and keeps the
+// post and on_error hooks for finishHooks. A failing pre hook stops the
+// command. With --background, the job runs them.
+func startHooks(cmd *cobra.Command) error {
+	hookRun = nil
+	if noHooks || hooks.Running() || runInBackground {
+		return nil
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if hooksSkip[c.Name()] {
+			return nil
+		}
+	}
+	all, err := configuredHooks()
+	if err != nil {
+		return err
+	}
+	command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	if err := warnUntrustedHooks(command); err != nil {
+		return err
+	}
+	set := hooksFor(all, command)
+	if set.Empty() {
+		return nil
+	}
+
+	op := hooks.Operation{Command: command, Args: history.Redact(os.Args[1:])}
+	if root, err := getRepoRoot(); err == nil {
+		op.Repo = root
+		op.Branch, _ = getCurrentBranch()
+	}
+	if flag := cmd.Flags().Lookup("dry-run"); flag != nil {
+		op.DryRun = flag.Value.String() == "true"
+	}
+
+	if len(set.Pre) > 0 {
+		ui.Stepf("🪝 Running the pre hooks of %s...", command)
+		if err := hooks.Run(commandCtx, hooks.Pre, set.Pre, op, ui.Status()); err != nil {
+			return err
+		}
+	}
+	hookRun = &hookRecording{op: op, hooks: set}
+	return nil
+}
+
+// finishHooks runs the post hooks of the command that ran, or its on_error
+// hooks when it failed. The command is over, so hooks failing only warn.
+func finishHooks(exitCode int, err error) {
+	if hookRun == nil {
+		return
+	}
+	op, set := hookRun.op, hookRun.hooks
+	hookRun = nil
+
+	stage, lines := hooks.Post, set.Post
+	if err != nil {
+		stage, lines = hooks.OnError, set.OnError
+		op.ExitCode, op.Error = exitCode, err.Error()
+	}
+	if len(lines) == 0 {
+		return
+	}
+	ui.Stepf("🪝 Running the %s hooks of %s...", stage, op.Command)
+	if err := hooks.Run(context.Background(), stage, lines, op, ui.Status()); err != nil {
+		ui.Warnf("%v", err)
+	}
+}
+
+func runHooksList(cmd *cobra.Command, args []string) error {
+	all, err := configuredHooks()
+	if err != nil {
+		return err
+	}
+	if len(all) == 0 {
+		ui.Println("No hooks configured. See 'githelper hooks --help' to add some.")
+		return nil
+	}
+	for _, command := range sortedKeys(all) {
+		ui.Println(command)
+		h := all[command]
+		for _, stage := range []struct {
+			name  string
+			lines []string
+		}{{hooks.Pre, h.Pre}, {hooks.Post, h.Post}, {hooks.OnError, h.OnError}} {
+			for _, line := range stage.lines {
+				ui.Printf("  %s: %s\n", stage.name, line)
+			}
+		}
+	}
+
+	trusted, err := repoCommandsTrusted()
+	if err != nil {
+		return err
+	}
+	if !trusted {
+		ui.Warn("\nThe hooks of this repository's .githelper.yaml don't run until you trust them with 'githelper hooks trust'")
+	}
+	return nil
+}
+
+func runHooksTrust(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	checksum, err := repoCommandsChecksum()
+	if err != nil {
+		return err
+	}
+	if checksum == "" {
+		ui.Info("This repository's .githelper.yaml has no hooks, aliases, workflows or precheck commands")
+		return nil
+	}
+	if err := gitCommand("config", hooksTrustedKey, checksum).Run(); err != nil {
+		return fmt.Errorf("failed to trust the hooks: %w", err)
+	}
+	ui.Success("The hooks, aliases, workflows and precheck commands of this repository's .githelper.yaml will run")
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	defer viper.Reset()
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	viper.Set("hooks", map[string]interface{}{
+		"*":          map[string]interface{}{"on_error": []string{`echo "$GITHELPER_EXIT_CODE $GITHELPER_ERROR" > failed.txt`}},
+		"branch new": map[string]interface{}{"pre": []string{`echo "$GITHELPER_HOOK $GITHELPER_COMMAND on $GITHELPER_BRANCH" > pre.txt`}},
+	})
+
+	_, _, err := execute(t, "branch", "new", "Add", "search")
+	require.NoError(t, err)
+	assert.Equal(t, "pre branch new on main\n", r.ReadFile("pre.txt"))
+	finishHooks(1, assert.AnError)
+	assert.Equal(t, "1 "+assert.AnError.Error()+"\n", r.ReadFile("failed.txt"))
+
+	// A failing pre hook stops the command
+	viper.Set("hooks.branch new.pre", []string{"exit 4"})
+	_, _, err = execute(t, "branch", "new", "Add", "filters")
+	assert.EqualError(t, err, "pre hook 'exit 4' failed: exit status 4")
+	assert.False(t, r.Exists("refs/heads/feat/add-filters"))
+
+	_, _, err = execute(t, "branch", "new", "Add", "filters", "--no-hooks")
+	assert.NoError(t, err)
+}
+
+func TestRepoHooksNeedTrust(t *testing.T) {
+	defer viper.Reset()
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", ".githelper.yaml", "hooks:\n  branch new:\n    pre: [\"touch ran.txt\"]\n")
+
+	_, stderr, err := execute(t, "branch", "new", "Add", "search", "--print")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "githelper hooks trust")
+	assert.NoFileExists(t, r.Path("ran.txt"))
+
+	_, _, err = execute(t, "hooks", "trust")
+	require.NoError(t, err)
+	_, _, err = execute(t, "branch", "new", "Add", "search", "--print")
+	require.NoError(t, err)
+	assert.FileExists(t, r.Path("ran.txt"))
+
+	// Changed hooks need trusting again
+	r.WriteFile(".githelper.yaml", "hooks:\n  branch new:\n    pre: [\"touch other.txt\"]\n")
+	_, _, err = execute(t, "branch", "new", "Add", "search", "--print")
+	require.NoError(t, err)
+	assert.NoFileExists(t, r.Path("other.txt"))
+}
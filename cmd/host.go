@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/auth"
+	"github.com/EndlessUphill/git-helper/internal/config"
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/viper"
+)
+
+var hostName string
+
+// defaultHostName returns the host used when none is given: --host,
+// default_host from the config, or github.com
+func defaultHostName() string {
+	if hostName != "" {
+		return strings.ToLower(hostName)
+	}
+	if name := viper.GetString("default_host"); name != "" {
+		return strings.ToLower(name)
+	}
+	return github.DefaultHost
+}
+
+// resolveHost returns the settings of the named host from the hosts section
+// of the config. An empty name means the default host.
+func resolveHost(name string) (github.Host, error) {
+	if name == "" {
+		name = defaultHostName()
+	}
+	name = strings.ToLower(name)
+
+	settings, err := hostConfig(name)
+	if err != nil {
+		return github.Host{}, err
+	}
+	credential, err := hostCredential(name)
+	if err != nil && !errors.Is(err, auth.ErrNoToken) {
+		return github.Host{}, err
+	}
+
+	host := github.Host{Name: name, Token: credential.Token}
+	if name == github.DefaultHost {
+		if settings.APIURL != "" || settings.UploadURL != "" {
+			ui.Warnf("hosts.%s.api_url and upload_url are ignored: %s always uses its own API", name, name)
+		}
+		return host, nil
+	}
+	for _, endpoint := range []struct {
+		key, url string
+		target   *string
+	}{{"api_url", settings.APIURL, &host.APIURL}, {"upload_url", settings.UploadURL, &host.UploadURL}} {
+		if endpoint.url == "" {
+			continue
+		}
+		key := fmt.Sprintf("hosts.%s.%s", name, endpoint.key)
+		if err := checkHostURL(key, endpoint.url); err != nil {
+			return github.Host{}, err
+		}
+		*endpoint.target = endpoint.url
+	}
+	return host, nil
+}
+
+// checkHostURL checks a host's API URL before the token is sent there: it
+// must use https, except on the local machine, and can't come from the
+// repository's .githelper.yaml, which anyone can commit
+func checkHostURL(key, value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("invalid %s '%s': not an absolute URL", key, value)
+	}
+	if parsed.Scheme != "https" && !(parsed.Scheme == "http" && isLoopback(parsed.Hostname())) {
+		return fmt.Errorf("invalid %s '%s': the token is only sent over https", key, value)
+	}
+	if path := repoConfigFile(); path != "" {
+		if settings, err := readConfigSettings(path); err == nil {
+			flat := make(map[string]interface{})
+			flattenSettings("", settings, flat)
+			for name, set := range flat {
+				if strings.EqualFold(name, key) && set == value {
+					return fmt.Errorf("%s can't come from the repository's .githelper.yaml. Set it in ~/.githelper.yaml", key)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isLoopback reports whether host is the local machine
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// configuredHosts returns the hosts section of the config
+func configuredHosts() (map[string]config.HostConfig, error) {
+	var hosts map[string]config.HostConfig
+	if err := viper.UnmarshalKey("hosts", &hosts); err != nil {
+		return nil, fmt.Errorf("invalid hosts configuration: %w", err)
+	}
+	return hosts, nil
+}
+
+func hostConfig(name string) (config.HostConfig, error) {
+	hosts, err := configuredHosts()
+	if err != nil {
+		return config.HostConfig{}, err
+	}
+	return hosts[name], nil
+}
+
+// hostCredential finds the token for a host through internal/auth. The
+// top-level github_token belongs to the default host.
+func hostCredential(name string) (auth.Credential, error) {
+	settings, err := hostConfig(name)
+	if err != nil {
+		return auth.Credential{}, err
+	}
+
+	configured := settings.Token
+	if configured == "" && name == defaultHostName() {
+		configured = viper.GetString("github_token")
+		if configured == "" {
+			configured = os.Getenv("GITHELPER_GITHUB_TOKEN")
+		}
+	}
+	return auth.Lookup(commandCtx, name, configured)
+}
+
+// newHostClient creates an API client for the host, failing with setup
+// instructions when no token is configured
+func newHostClient(host github.Host) (*github.Client, error) {
+	if host.Token == "" {
+		return nil, fmt.Errorf("GitHub token not found for %s. Either:\n"+
+			"1. Run 'githelper auth login --host %s' (or 'gh auth login')\n"+
+			"2. Set GITHELPER_GITHUB_TOKEN environment variable\n"+
+			"3. Add github_token (or hosts.%s.token) to ~/.githelper.yaml\n"+
+			"4. Store an HTTPS token for %s in a git credential helper", host.Name, host.Name, host.Name, host.Name)
+	}
+
+	if viper.GetBool("debug") {
+		ui.Printf("API URL: %s\n", host.APIBaseURL())
+		ui.Printf("Token length: %d\n", len(host.Token))
+	}
+
+	return github.NewHostClient(host)
+}
+
+// expandRepoURL turns owner/repo shorthand into a clone URL on the default
+// host and leaves full URLs and local paths untouched
+func expandRepoURL(repo string, ssh bool) (string, error) {
+	if strings.Contains(repo, "://") || strings.Contains(repo, "@") || strings.Count(repo, "/") != 1 {
+		return repo, nil
+	}
+	if _, err := os.Stat(repo); err == nil {
+		return repo, nil
+	}
+
+	host, err := resolveHost("")
+	if err != nil {
+		return "", err
+	}
+	return host.CloneURL(strings.TrimSuffix(repo, ".git"), ssh), nil
+}
+
+// originClient returns an API client for the host of the origin remote,
+// and the owner and name of the repository there
+func originClient() (*github.Client, string, string, error) {
+	originURL, err := getOriginURL()
+	if err != nil {
+		return nil, "", "", fmt.Errorf("no origin remote found")
+	}
+	hostname, repoPath, err := github.ParseRepoURL(originURL)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("origin is not a GitHub repository: %w", err)
+	}
+	host, err := resolveHost(hostname)
+	if err != nil {
+		return nil, "", "", err
+	}
+	client, err := newHostClient(host)
+	if err != nil {
+		return nil, "", "", err
+	}
+	owner, name, _ := strings.Cut(repoPath, "/")
+	return client, owner, name, nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveHostURLs(t *testing.T) {
+	defer viper.Reset()
+	t.Chdir(t.TempDir())
+	viper.Set("github_token", "token")
+	viper.Set("hosts", map[string]interface{}{
+		"github.com":         map[string]interface{}{"api_url": "https://evil.example.com/"},
+		"github.example.com": map[string]interface{}{"api_url": "https://github.example.com/api/v3/", "token": "token"},
+		"plain.example.com":  map[string]interface{}{"api_url": "http://plain.example.com/api/v3/", "token": "token"},
+		"local.example.com":  map[string]interface{}{"api_url": "http://127.0.0.1:8080/api/v3/", "token": "token"},
+	})
+
+	host, err := resolveHost("github.com")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.github.com/", host.APIBaseURL(), "github.com keeps its own API")
+
+	host, err = resolveHost("github.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.example.com/api/v3/", host.APIBaseURL())
+
+	_, err = resolveHost("plain.example.com")
+	assert.ErrorContains(t, err, "only sent over https")
+
+	host, err = resolveHost("local.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:8080/api/v3/", host.APIBaseURL())
+}
+
+func TestResolveHostRejectsRepoURLs(t *testing.T) {
+	defer viper.Reset()
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", ".githelper.yaml", "hosts:\n  github.example.com:\n    api_url: https://evil.example.com/api/v3/\n")
+	// As if the repository's file were passed with --config
+	viper.Set("hosts", map[string]interface{}{
+		"github.example.com": map[string]interface{}{"api_url": "https://evil.example.com/api/v3/", "token": "token"},
+	})
+
+	_, err := resolveHost("github.example.com")
+	assert.ErrorContains(t, err, "can't come from the repository's .githelper.yaml")
+}
+
+func TestIsLoopback(t *testing.T) {
+	assert.True(t, isLoopback("localhost"))
+	assert.True(t, isLoopback("127.0.0.1"))
+	assert.True(t, isLoopback("::1"))
+	assert.False(t, isLoopback("example.com"))
+	assert.False(t, isLoopback("10.0.0.1"))
+}
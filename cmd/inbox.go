@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	inboxReviewsOnly       bool
+	inboxNotificationsOnly bool
+	inboxAll               bool
+	inboxLimit             int
+)
+
+var inboxCmd = &cobra.Command{
+	Use:   "inbox",
+	Short: "Triage review requests and GitHub notifications",
+	Long: `List the pull requests awaiting your review and your unread GitHub
+notifications across all repositories.
+
+Pick an item (with fzf when available) to open it in the browser, check out
+a pull request locally, or mark the notification as read. Checking out only
+works for pull requests of the repository you are in.
+
+Example:
+  githelper inbox                  # Review requests and unread notifications
+  githelper inbox --reviews        # Only review requests
+  githelper inbox --notifications --all
+  githelper inbox --non-interactive | grep review`,
+	Args: cobra.NoArgs,
+	RunE: runInbox,
+}
+
+func init() {
+	rootCmd.AddCommand(inboxCmd)
+	inboxCmd.Flags().BoolVar(&inboxReviewsOnly, "reviews", false, "only show pull requests awaiting your review")
+	inboxCmd.Flags().BoolVar(&inboxNotificationsOnly, "notifications", false, "only show notifications")
+	inboxCmd.Flags().BoolVar(&inboxAll, "all", false, "include notifications already read")
+	inboxCmd.Flags().IntVarP(&inboxLimit, "limit", "n", 50, "maximum number of items of each kind")
+	inboxCmd.Flags().BoolVar(&noFzf, "no-fzf", false, "disable fzf usage even if available")
+}
+
+func runInbox(cmd *cobra.Command, args []string) error {
+	if inboxReviewsOnly && inboxNotificationsOnly {
+		return fmt.Errorf("--reviews and --notifications can't be combined")
+	}
+	host, err := resolveHost("")
+	if err != nil {
+		return err
+	}
+	client, err := newHostClient(host)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var reviews, notifications []github.InboxItem
+	if !inboxNotificationsOnly {
+		ui.Step("🔍 Fetching review requests...")
+		if reviews, err = client.ReviewRequests(ctx, inboxLimit); err != nil {
+			return fmt.Errorf("failed to fetch review requests: %w", err)
+		}
+	}
+	if !inboxReviewsOnly {
+		ui.Step("🔍 Fetching notifications...")
+		if notifications, err = client.Notifications(ctx, inboxAll, inboxLimit); err != nil {
+			return fmt.Errorf("failed to fetch notifications: %w", err)
+		}
+	}
+
+	items := mergeInbox(reviews, notifications)
+	if len(items) == 0 {
+		ui.Success("Inbox zero!")
+		return nil
+	}
+
+	if !isInteractive() {
+		printInbox(ui.Out(), items)
+		return nil
+	}
+
+	item, err := selectInboxItem(items)
+	if err != nil || item == nil {
+		return err
+	}
+	return actOnInboxItem(client, item)
+}
+
+// mergeInbox combines review requests and notifications, most recently
+// updated first. A notification about a pull request that is also a review
+// request is folded into it, so it can still be marked as read.
+func mergeInbox(reviews, notifications []github.InboxItem) []github.InboxItem {
+	items := append([]github.InboxItem{}, reviews...)
+	index := map[string]int{}
+	for i, item := range items {
+		index[inboxKey(item)] = i
+	}
+	for _, item := range notifications {
+		if i, ok := index[inboxKey(item)]; ok && item.Number != 0 {
+			items[i].ThreadID = item.ThreadID
+			continue
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if (items[i].Kind == github.InboxReview) != (items[j].Kind == github.InboxReview) {
+			return items[i].Kind == github.InboxReview
+		}
+		return items[i].Updated.After(items[j].Updated)
+	})
+	return items
+}
+
+func inboxKey(item github.InboxItem) string {
+	return item.Repo + "#" + strconv.Itoa(item.Number)
+}
+
+// inboxLine describes an item on one line
+func inboxLine(item github.InboxItem) string {
+	icon := "🔔"
+	switch {
+	case item.Kind == github.InboxReview:
+		icon = "👀"
+	case !item.Unread:
+		icon = "  "
+	}
+	ref := item.Repo
+	if item.Number != 0 {
+		ref += "#" + strconv.Itoa(item.Number)
+	}
+	return fmt.Sprintf("%s %-40s %s (%s, %s)", icon, ref, item.Title, strings.ReplaceAll(item.Reason, "_", " "), timeAgo(item.Updated))
+}
+
+func printInbox(w io.Writer, items []github.InboxItem) {
+	for _, item := range items {
+		fmt.Fprintln(w, inboxLine(item))
+	}
+}
+
+// timeAgo formats how long ago t was, e.g. "3h ago"
+func timeAgo(t time.Time) string {
+	elapsed := time.Since(t)
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm ago", int(elapsed.Minutes()))
+	case elapsed < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(elapsed.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(elapsed.Hours()/24))
+}
+
+func selectInboxItem(items []github.InboxItem) (*github.InboxItem, error) {
+	if !noFzf {
+		if _, err := exec.LookPath("fzf"); err == nil {
+			return selectInboxItemWithFzf(items)
+		}
+	}
+	return selectInboxItemWithList(items)
+}
+
+func selectInboxItemWithFzf(items []github.InboxItem) (*github.InboxItem, error) {
+	var input strings.Builder
+	for i, item := range items {
+		fmt.Fprintf(&input, "%d\t%s\n", i, inboxLine(item))
+	}
+
+	fzfCmd := exec.Command("fzf",
+		"--height", "50%",
+		"--reverse",
+		"--delimiter", "\t",
+		"--with-nth", "2",
+		"--header", "Select an item to open, check out or mark as read")
+
+	fzfCmd.Stdin = strings.NewReader(input.String())
+	fzfCmd.Stderr = os.Stderr
+
+	output, err := fzfCmd.Output()
+	if err != nil {
+		return nil, nil // User cancelled
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &index); err != nil || index < 0 || index >= len(items) {
+		return nil, fmt.Errorf("invalid selection")
+	}
+	return &items[index], nil
+}
+
+func selectInboxItemWithList(items []github.InboxItem) (*github.InboxItem, error) {
+	ui.Println("\nInbox:")
+	for i, item := range items {
+		ui.Printf("%2d: %s\n", i+1, inboxLine(item))
+	}
+
+	input := readInput("\nSelect item number (or press Enter to cancel): ")
+	if input == "" {
+		return nil, nil
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(input, "%d", &index); err != nil || index < 1 || index > len(items) {
+		return nil, fmt.Errorf("invalid selection")
+	}
+	return &items[index-1], nil
+}
+
+func actOnInboxItem(client *github.Client, item *github.InboxItem) error {
+	ui.Printf("\n%s\n%s\n\n", inboxLine(*item), item.URL)
+	ui.Println("o. Open in browser")
+	if item.Type == "PullRequest" {
+		ui.Println("c. Check out locally")
+	}
+	if item.ThreadID != "" {
+		ui.Println("r. Mark as read")
+	}
+
+	switch readInput("\nAction (or press Enter to cancel): ") {
+	case "o", "O":
+		if err := openBrowser(item.URL); err != nil {
+			return err
+		}
+		if item.ThreadID != "" {
+			return markInboxItemRead(client, item)
+		}
+		return nil
+	case "c", "C":
+		if item.Type != "PullRequest" {
+			return fmt.Errorf("only pull requests can be checked out")
+		}
+		return checkoutPullRequest(client, item.Repo, item.Number)
+	case "r", "R":
+		if item.ThreadID == "" {
+			return fmt.Errorf("review requests are cleared by reviewing")
+		}
+		return markInboxItemRead(client, item)
+	case "":
+		return nil
+	}
+	return fmt.Errorf("invalid action")
+}
+
+func markInboxItemRead(client *github.Client, item *github.InboxItem) error {
+	if err := client.MarkThreadRead(context.Background(), item.ThreadID); err != nil {
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+	ui.Success("Marked as read")
+	return nil
+}
+
+// checkoutPullRequest fetches a pull request of the current repository into
+// a local branch named after its head branch and switches to it
+func checkoutPullRequest(client *github.Client, repo string, number int) error {
+	if err := checkGitRepo(); err != nil {
+		return fmt.Errorf("run this in a clone of %s to check out #%d", repo, number)
+	}
+	originURL, err := getOriginURL()
+	if err != nil {
+		return fmt.Errorf("no origin remote found")
+	}
+	_, originRepo, err := github.ParseRepoURL(originURL)
+	if err != nil || !strings.EqualFold(originRepo, repo) {
+		return fmt.Errorf("this repository is not %s. Run 'githelper clone %s' first", repo, repo)
+	}
+
+	owner, name, _ := strings.Cut(repo, "/")
+	branch, err := client.PullRequestBranch(context.Background(), owner, name, number)
+	if err != nil {
+		return fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	if hasChanges, err := hasUncommittedChanges(); err != nil {
+		return err
+	} else if hasChanges {
+		return fmt.Errorf("you have uncommitted changes. Please commit or stash them first")
+	}
+
+	// A local branch of that name that isn't this pull request is left alone
+	local := branch
+	if gitCommand("rev-parse", "--verify", "-q", "refs/heads/"+local).Run() == nil {
+		local = fmt.Sprintf("pr-%d", number)
+	}
+
+	ui.Stepf("📥 Fetching #%d into %s...", number, local)
+	fetchCmd := gitCommand("fetch", "origin", fmt.Sprintf("+refs/pull/%d/head:refs/heads/%s", number, local))
+	fetchCmd.Stderr = os.Stderr
+	if err := fetchCmd.Run(); err != nil {
+		return fmt.Errorf("failed to fetch pull request: %w", err)
+	}
+	if output, err := gitCommand("checkout", local).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to check out %s: %s", local, strings.TrimSpace(string(output)))
+	}
+	ui.Successf("Checked out #%d on %s", number, local)
+	return nil
+}
+
+// openBrowser opens url in the default browser
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch {
+	case os.Getenv("BROWSER") != "":
+		cmd = exec.Command(os.Getenv("BROWSER"), url)
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("open", url)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser, visit %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeInbox(t *testing.T) {
+	now := time.Now()
+	reviews := []github.InboxItem{
+		{Kind: github.InboxReview, Repo: "octo/app", Number: 1, Updated: now.Add(-time.Hour)},
+	}
+	notifications := []github.InboxItem{
+		{Kind: github.InboxNotification, ThreadID: "10", Repo: "octo/app", Number: 1, Updated: now},
+		{Kind: github.InboxNotification, ThreadID: "11", Repo: "octo/lib", Number: 2, Updated: now.Add(-time.Minute)},
+		{Kind: github.InboxNotification, ThreadID: "12", Repo: "octo/lib", Number: 3, Updated: now},
+	}
+
+	items := mergeInbox(reviews, notifications)
+	assert.Len(t, items, 3)
+	// Review requests first, carrying the thread of their notification
+	assert.Equal(t, github.InboxReview, items[0].Kind)
+	assert.Equal(t, "10", items[0].ThreadID)
+	assert.Equal(t, "12", items[1].ThreadID)
+	assert.Equal(t, "11", items[2].ThreadID)
+}
@@ -0,0 +1,643 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/github/githubtest"
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// execute runs githelper with args in the current directory, like the
+// binary would, and returns what it printed to stdout and stderr
+func execute(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	// The user's config, history and journal stay out of it
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GITHELPER_NON_INTERACTIVE", "true")
+
+	var stdout, stderr bytes.Buffer
+	ui.SetOutput(&stdout, &stderr)
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	rootCmd.SetArgs(args)
+	defer func() {
+		ui.SetOutput(nil, nil)
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+		resetFlags(rootCmd)
+	}()
+
+	err := rootCmd.ExecuteContext(context.Background())
+	return stdout.String(), stderr.String(), err
+}
+
+// resetFlags sets every flag back to its default, as cobra keeps the
+// values of the last run
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
+
+// fakeGitHub starts a fake GitHub server and makes it the default host, so
+// commands talk to it instead of github.com
+func fakeGitHub(t *testing.T) *githubtest.Server {
+	t.Helper()
+	server := githubtest.NewServer(t)
+	viper.Set("default_host", githubtest.Hostname)
+	viper.Set("hosts", map[string]interface{}{
+		githubtest.Hostname: map[string]interface{}{
+			"api_url":    server.Host().APIURL,
+			"upload_url": server.Host().UploadURL,
+			"token":      server.Token,
+		},
+	})
+	t.Cleanup(viper.Reset)
+	return server
+}
+
+func TestCommandsOnFixtures(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(r *testutil.Repo)
+		args  []string
+		// wantErr is part of the error message, when the command must fail
+		wantErr string
+		check   func(t *testing.T, r *testutil.Repo, stdout string)
+	}{
+		{
+			name: "squash combines the last commits",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.Commits("work", 3)
+			},
+			args: []string{"squash", "3", "-m", "Add work", "--yes"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, []string{"Add work", "Base"}, r.Log("HEAD"))
+				assert.Equal(t, "work 3\n", r.ReadFile("work3.txt"))
+			},
+		},
+		{
+			name: "squash fails on more commits than there are",
+			setup: func(r *testutil.Repo) {
+				r.Commits("work", 2)
+			},
+			args:    []string{"squash", "5", "-m", "Too many", "--yes"},
+			wantErr: "failed to reset commits",
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, 2, r.Count("HEAD"), "nothing is rewritten")
+			},
+		},
+		{
+			name: "squash --dry-run shows the git commands only",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.Commits("work", 2)
+			},
+			args: []string{"squash", "2", "-m", "Add work", "--dry-run"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Contains(t, stdout, "Would run: git reset --soft HEAD~2\n")
+				assert.Contains(t, stdout, "Would run: git commit -m 'Add work'\n")
+				assert.Equal(t, 3, r.Count("HEAD"))
+				assert.NoDirExists(t, r.Path(".git/githelper"), "nothing is journaled")
+			},
+		},
+		{
+			name: "squash --interactive combines the chosen commits only",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.Commits("work", 4)
+			},
+			args: []string{"squash", "4", "-i", "--select", "1,3", "-m", "Combined", "--yes"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, []string{"Add work3.txt", "Combined", "Add work1.txt", "Base"}, r.Log("HEAD"))
+				assert.Equal(t, "work 4\n", r.ReadFile("work4.txt"))
+				assert.Equal(t, "work 4", r.Git("show", "HEAD~1:work4.txt"), "the newest commit is folded in")
+				assert.Equal(t, "", r.Status())
+			},
+		},
+		{
+			name: "squash --interactive --dry-run shows the rebase plan",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.Commits("work", 3)
+			},
+			args: []string{"squash", "-i", "--select", "1-2", "-m", "Combined", "--dry-run"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Contains(t, stdout, "Rebase plan:")
+				assert.Contains(t, stdout, "fixup ")
+				assert.Equal(t, []string{"Add work3.txt", "Add work2.txt", "Add work1.txt", "Base"}, r.Log("HEAD"))
+				assert.NoDirExists(t, r.Path(".git/githelper"), "nothing is journaled")
+			},
+		},
+		{
+			name: "squash --interactive needs two commits",
+			setup: func(r *testutil.Repo) {
+				r.Commits("work", 3)
+			},
+			args:    []string{"squash", "-i", "--select", "2", "--yes"},
+			wantErr: "select at least 2 commits",
+		},
+		{
+			name: "undo --hard drops pushed commits and their changes",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.Commits("work", 2)
+				r.WithRemote()
+			},
+			args: []string{"undo", "-n", "2", "--hard", "--yes"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, []string{"Base"}, r.Log("HEAD"))
+				assert.Equal(t, r.Head(), r.RevParse("origin/main"), "the undo is pushed")
+				assert.NoFileExists(t, r.Path("work1.txt"))
+			},
+		},
+		{
+			name: "prune deletes merged branches only",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.MergedBranches("done-1", "done-2")
+				r.Checkout("wip")
+				r.Commit("Unmerged work", "wip.txt", "wip\n")
+				r.Checkout("main")
+			},
+			args: []string{"prune", "--force", "--main", "main"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.False(t, r.Exists("refs/heads/done-1"))
+				assert.False(t, r.Exists("refs/heads/done-2"))
+				assert.True(t, r.Exists("refs/heads/wip"))
+			},
+		},
+		{
+			name: "prune --dry-run lists the branches it would delete",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.MergedBranches("done")
+			},
+			args: []string{"prune", "--dry-run", "--main", "main"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Contains(t, stdout, "Would run: git fetch -p\n")
+				assert.Contains(t, stdout, "Would run: git branch -d done\n")
+				assert.True(t, r.Exists("refs/heads/done"))
+			},
+		},
+		{
+			name: "rescue saves commits made on a detached HEAD",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.DetachedCommits(2)
+			},
+			args: []string{"rescue", "saved-work"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, "saved-work", r.Branch())
+				assert.Equal(t, []string{"Add detached2.txt", "Add detached1.txt", "Base"}, r.Log("saved-work"))
+			},
+		},
+		{
+			name: "rescue needs a detached HEAD",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+			},
+			args:    []string{"rescue", "saved-work"},
+			wantErr: "not in detached HEAD state",
+		},
+		{
+			name: "sync fast-forwards a branch behind origin",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.WithRemote()
+				r.PushFromClone("main", 2)
+			},
+			args: []string{"sync"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, r.RevParse("origin/main"), r.Head())
+				assert.Contains(t, stdout, "Successfully synchronized")
+			},
+		},
+		{
+			name: "sync --strategy reset keeps a backup of diverged commits",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.WithRemote()
+				r.PushFromClone("main", 1)
+				r.Commit("Local work", "local.txt", "local\n")
+			},
+			args: []string{"sync", "--strategy", "reset"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, r.RevParse("origin/main"), r.Head())
+				backups := r.Git("branch", "--list", "backup/main-*", "--format=%(refname:short)")
+				require.NotEmpty(t, backups)
+				assert.Equal(t, "Local work", r.Log(backups)[0])
+			},
+		},
+		{
+			name: "sync --strategy reset refuses to reset onto another branch",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.WithRemote()
+				r.Git("checkout", "--quiet", "-b", "feature")
+				r.Commit("Feature work", "feature.txt", "feature\n")
+			},
+			args:    []string{"sync", "main", "--strategy", "reset"},
+			wantErr: "reset only matches the current branch (feature)",
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, "Feature work", r.Log("HEAD")[0])
+			},
+		},
+		{
+			name: "sync <branch> --strategy branch saves the commits after the current branch",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.WithRemote()
+				r.PushFromClone("main", 1)
+				r.Git("checkout", "--quiet", "-b", "feature")
+				r.Commit("Feature work", "feature.txt", "feature\n")
+			},
+			args: []string{"sync", "main", "--strategy", "branch"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, "feature", r.Branch())
+				assert.Equal(t, r.RevParse("origin/main"), r.Head())
+				saved := r.Git("branch", "--list", "feature-diverged-*", "--format=%(refname:short)")
+				require.NotEmpty(t, saved)
+				assert.Equal(t, "Feature work", r.Log(saved)[0])
+				assert.Empty(t, r.Git("branch", "--list", "main-diverged-*"))
+			},
+		},
+		{
+			name: "sync refuses to pick a strategy for diverged branches",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.WithRemote()
+				r.PushFromClone("main", 1)
+				r.Commit("Local work", "local.txt", "local\n")
+			},
+			args:    []string{"sync"},
+			wantErr: "branches have diverged",
+		},
+		{
+			name: "clean removes a large file from history",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.LargeBlob("assets/video.bin", 2<<20)
+				r.Commit("Add more.txt", "more.txt", "more\n")
+			},
+			args: []string{"clean", "--select-file", "assets/video.bin", "--no-backup", "--yes"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Empty(t, r.Git("log", "--format=%h", "HEAD", "--", "assets/video.bin"))
+				// The commit that only added the file is gone with it
+				assert.Equal(t, []string{"Add more.txt", "Base"}, r.Log("HEAD"))
+			},
+		},
+		{
+			name: "purge --push force pushes the rewritten branches and tags",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.Commit("Add config", "secret.env", "TOKEN=abc\n", "app.txt", "app\n")
+				r.Git("tag", "-a", "v1", "-m", "Release 1")
+				r.Commit("More", "more.txt", "more\n")
+				r.WithRemote()
+				r.Git("push", "--quiet", "origin", "v1")
+			},
+			args: []string{"purge", "secret.env", "--push", "--notice", "NOTICE.md", "--no-backup", "--yes"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, r.Head(), r.Git("ls-remote", "origin", "refs/heads/main")[:40])
+				assert.Equal(t, r.Git("rev-parse", "v1"), r.Git("ls-remote", "origin", "refs/tags/v1")[:40])
+				assert.Contains(t, stdout, "git fetch origin --prune --tags --force\n")
+				assert.Contains(t, stdout, "git switch main && git reset --hard origin/main\n")
+				notice := r.ReadFile("NOTICE.md")
+				assert.Contains(t, notice, "secret.env were removed from history")
+				assert.Contains(t, notice, "| `tag v1` |")
+			},
+		},
+		{
+			name: "purge warns about other copies of the secret",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.Commit("Add config", "secret.env", "DB_PASSWORD=correct-horse-battery\n", "copy.env", "DB_PASSWORD=correct-horse-battery\n")
+				r.Commit("Note the staging setup", "notes.txt", "staging uses correct-horse-battery too\n")
+				r.Commit("Use correct-horse-battery on staging", "staging.txt", "on\n")
+				r.WriteFile("scratch.txt", "correct-horse-battery\n")
+			},
+			args: []string{"purge", "secret.env", "--no-backup", "--yes"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Contains(t, stdout, "copy.env has the same content as secret.env")
+				assert.Contains(t, stdout, " notes.txt:1 (corr")
+				assert.Contains(t, stdout, "the message of ")
+				assert.NotContains(t, stdout, "notes.txt:1 in the working tree", "committed files are reported from history")
+				assert.Contains(t, stdout, "scratch.txt:1 in the working tree")
+				assert.NotContains(t, stdout, "secret.env:1 in the working tree", "the purged file is not a copy")
+				assert.NotContains(t, stdout, "correct-horse-battery", "secrets are redacted")
+				assert.Contains(t, stdout, "- [ ] Rotate every secret that was in secret.env, not only the ones above (1 found)\n")
+			},
+		},
+		{
+			name: "push --rewritten refuses branches changed on the remote since the last fetch",
+			setup: func(r *testutil.Repo) {
+				r.Commits("work", 2)
+				r.WithRemote()
+				r.PushFromClone("main", 1)
+				r.Git("commit", "--quiet", "--amend", "-m", "Reworded")
+			},
+			args:    []string{"push", "--rewritten", "--yes"},
+			wantErr: "main changed on origin since your last fetch",
+		},
+		{
+			name: "push --rewritten --dry-run shows the leases",
+			setup: func(r *testutil.Repo) {
+				r.Commits("work", 2)
+				r.WithRemote()
+				r.Git("commit", "--quiet", "--amend", "-m", "Reworded")
+			},
+			args: []string{"push", "--rewritten", "--dry-run"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				old := r.RevParse("origin/main")
+				assert.Contains(t, stdout, "Would run: git push --progress --force-with-lease=refs/heads/main:"+old+" origin refs/heads/main:refs/heads/main\n")
+				assert.Equal(t, old, r.Git("ls-remote", "origin", "refs/heads/main")[:40], "nothing is pushed")
+			},
+		},
+		{
+			name: "recover --grep finds a lost commit by its changes",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.LostCommits(2)
+			},
+			args: []string{"recover", "--grep", "LOST 2", "--yes"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Contains(t, stdout, "Add lost2.txt (main@{1}: commit: Add lost2.txt)")
+				assert.Equal(t, []string{"Add lost2.txt", "Add lost1.txt", "Base"}, r.Log("HEAD"))
+			},
+		},
+		{
+			name: "recover --grep finds a dropped stash",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.WriteFile("base.txt", "remember the rollout flag\n")
+				r.Git("stash", "--quiet")
+				r.Git("stash", "drop", "--quiet")
+			},
+			args: []string{"recover", "--grep", "rollout flag", "--yes"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Contains(t, stdout, "(dangling)")
+				assert.Equal(t, "remember the rollout flag\n", r.ReadFile("base.txt"))
+			},
+		},
+		{
+			name: "recover --grep fails when nothing matches",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.LostCommits(1)
+			},
+			args:    []string{"recover", "--grep", "nowhere to be found", "--yes"},
+			wantErr: "no commit in the reflog or among dangling commits contains 'nowhere to be found'",
+		},
+		{
+			name: "rollback restores the history before a squash",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.Commits("work", 2)
+				_, _, err := execute(t, "squash", "2", "-m", "Squashed", "--yes")
+				require.NoError(t, err)
+			},
+			args: []string{"rollback", "--yes"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, []string{"Add work2.txt", "Add work1.txt", "Base"}, r.Log("HEAD"))
+			},
+		},
+		{
+			name: "open --print links a file at a line on the current branch",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "cmd/root.go", "package cmd\n")
+				r.Git("remote", "add", "origin", "git@github.com:acme/widgets.git")
+				r.Git("update-ref", "refs/remotes/origin/main", "HEAD")
+			},
+			args: []string{"open", "--print", "cmd/root.go:3"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, "https://github.com/acme/widgets/blob/main/cmd/root.go#L3\n", stdout)
+			},
+		},
+		{
+			name: "open --print links unpushed branches to the default branch",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.Git("remote", "add", "origin", "https://github.example.com/acme/widgets.git")
+				r.Git("update-ref", "refs/remotes/origin/main", "HEAD")
+				r.Git("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+				r.Checkout("wip")
+			},
+			args: []string{"open", "--print"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, "https://github.example.com/acme/widgets/tree/main\n", stdout)
+			},
+		},
+		{
+			name: "open --print commit links a commit",
+			setup: func(r *testutil.Repo) {
+				r.Commits("work", 2)
+				r.Git("remote", "add", "origin", "git@github.com:acme/widgets.git")
+				r.Git("update-ref", "refs/remotes/origin/main", "HEAD")
+			},
+			args: []string{"open", "--print", "commit", "HEAD~1"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, "https://github.com/acme/widgets/commit/"+r.RevParse("HEAD~1")+"\n", stdout)
+			},
+		},
+		{
+			name: "link --markdown pins lines to the current commit",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "cmd/root.go", "package cmd\n\nfunc a() {}\n\nfunc b() {}\n")
+				r.Git("remote", "add", "origin", "git@github.com:acme/widgets.git")
+				r.Git("update-ref", "refs/remotes/origin/main", "HEAD")
+			},
+			args: []string{"link", "--markdown", "cmd/root.go:3-5"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Equal(t, "[cmd/root.go:3-5](https://github.com/acme/widgets/blob/"+r.Head()+"/cmd/root.go#L3-L5)\n", stdout)
+			},
+		},
+		{
+			name: "link fails on lines past the end of the file",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "one\ntwo\n")
+				r.Git("remote", "add", "origin", "git@github.com:acme/widgets.git")
+			},
+			args:    []string{"link", "base.txt:3"},
+			wantErr: "base.txt: line 3 is past the end of the file (2 lines)",
+		},
+		{
+			name: "tips point at a conflicted merge",
+			setup: func(r *testutil.Repo) {
+				r.Commit("Base", "base.txt", "base\n")
+				r.Conflict("feature", "shared.txt")
+			},
+			args: []string{"tips"},
+			check: func(t *testing.T, r *testutil.Repo, stdout string) {
+				assert.Contains(t, stdout, "githelper resolve")
+				assert.Contains(t, stdout, "A merge is in progress")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testutil.NewRepo(t)
+			r.Chdir()
+			tt.setup(r)
+
+			stdout, stderr, err := execute(t, tt.args...)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else if err != nil {
+				t.Fatalf("githelper %s: %v\n%s%s", strings.Join(tt.args, " "), err, stdout, stderr)
+			}
+			if tt.check != nil {
+				tt.check(t, r, stdout)
+			}
+		})
+	}
+}
+
+func TestSelectionFlags(t *testing.T) {
+	t.Run("find-deleted --select-file", func(t *testing.T) {
+		r := testutil.NewRepo(t)
+		r.Chdir()
+		r.Commit("Add docs", "docs/a.md", "a\n", "docs/b.md", "b\n", "x.md", "x\n")
+		r.Git("rm", "--quiet", "docs/a.md", "docs/b.md", "x.md")
+		r.Commit("Drop docs")
+
+		_, _, err := execute(t, "find-deleted", "*.md", "--select-file", "docs/c.md")
+		assert.ErrorContains(t, err, "'docs/c.md' is not among the deleted files found")
+
+		_, _, err = execute(t, "find-deleted", "*.md", "--select-file", "docs/b.md")
+		require.NoError(t, err)
+		assert.Equal(t, "b\n", r.ReadFile("docs/b.md"))
+		assert.NoFileExists(t, r.Path("docs/a.md"))
+
+		// A single match needs no selection
+		_, _, err = execute(t, "find-deleted", "x.md", "--yes")
+		require.NoError(t, err)
+		assert.Equal(t, "x\n", r.ReadFile("x.md"))
+	})
+
+	t.Run("recover --select", func(t *testing.T) {
+		r := testutil.NewRepo(t)
+		r.Chdir()
+		r.Commit("Base", "base.txt", "base\n")
+		lost := r.LostCommits(2)
+
+		_, _, err := execute(t, "recover", "--select", "ffffffff", "--yes")
+		assert.ErrorContains(t, err, "no reflog entry matching 'ffffffff' found")
+
+		_, _, err = execute(t, "recover", "--select", lost[0][:8], "--yes")
+		require.NoError(t, err)
+		assert.Equal(t, lost[0], r.Head())
+
+		// HEAD@{2} is the last commit before the reset that lost it
+		_, _, err = execute(t, "recover", "--select", "HEAD@{2}", "--yes")
+		require.NoError(t, err)
+		assert.Equal(t, lost[1], r.Head())
+
+		_, _, err = execute(t, "recover", "--grep", "lost", "--select", lost[0], "--yes")
+		require.NoError(t, err)
+		assert.Equal(t, lost[0], r.Head())
+	})
+
+	t.Run("restore --select --name", func(t *testing.T) {
+		r := testutil.NewRepo(t)
+		r.Chdir()
+		r.Commit("Base", "base.txt", "base\n")
+		r.Git("checkout", "--quiet", "-b", "feature")
+		feature := r.Commit("Add feature", "feature.txt", "feature\n")
+		r.Checkout("main")
+		r.Git("branch", "--quiet", "-D", "feature")
+
+		_, _, err := execute(t, "restore", "--select", feature, "--name", "feature")
+		require.NoError(t, err)
+		assert.Equal(t, "feature", r.Branch())
+		assert.Equal(t, feature, r.Head())
+	})
+
+	t.Run("switch --select", func(t *testing.T) {
+		r := testutil.NewRepo(t)
+		r.Chdir()
+		r.Commit("Base", "base.txt", "base\n")
+		r.Git("branch", "feature")
+
+		_, _, err := execute(t, "switch", "--select", "feat")
+		assert.ErrorContains(t, err, "no branch matching 'feat' found")
+
+		_, _, err = execute(t, "switch", "--select", "feature")
+		require.NoError(t, err)
+		assert.Equal(t, "feature", r.Branch())
+	})
+
+	t.Run("search --select", func(t *testing.T) {
+		r := testutil.NewRepo(t)
+		r.Chdir()
+		added := r.Commit("Add size", "size.go", "func getRepoSize() {}\n")
+		r.Commit("Remove size", "size.go", "\n")
+
+		stdout, _, err := execute(t, "search", "getRepoSize", "--select", added[:8])
+		require.NoError(t, err)
+		assert.NotContains(t, stdout, "Select commit number")
+
+		_, _, err = execute(t, "search", "getRepoSize", "--select", "ffffffff")
+		assert.ErrorContains(t, err, "no commit changing 'getRepoSize' matching 'ffffffff' found")
+	})
+
+	t.Run("worktree switch --select", func(t *testing.T) {
+		r := testutil.NewRepo(t)
+		r.Chdir()
+		r.Commit("Base", "base.txt", "base\n")
+		r.Git("worktree", "add", "--quiet", "-b", "hotfix", filepath.Join(t.TempDir(), "hotfix"))
+
+		stdout, _, err := execute(t, "worktree", "switch", "--select", "hotfix")
+		require.NoError(t, err)
+		assert.Contains(t, stdout, "Now in: ")
+		assert.Contains(t, stdout, "hotfix")
+
+		_, _, err = execute(t, "worktree", "switch", "--select", "release")
+		assert.ErrorContains(t, err, "no worktree matching 'release' found")
+	})
+
+	t.Run("cherry-pick --select", func(t *testing.T) {
+		r := testutil.NewRepo(t)
+		r.Chdir()
+		r.Commit("Base", "base.txt", "base\n")
+		r.WithRemote()
+		r.Git("checkout", "--quiet", "-b", "pr")
+		shas := r.Commits("pr", 3)
+		r.Git("push", "--quiet", "origin", "pr:refs/pull/7/head")
+		r.Checkout("main")
+
+		_, _, err := execute(t, "cherry-pick", "7", "--select", "ffffffff")
+		assert.ErrorContains(t, err, "no commit matching 'ffffffff' found in PR #7")
+
+		_, _, err = execute(t, "cherry-pick", "7", "--select", shas[2][:8]+","+shas[0][:8])
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Add pr3.txt", "Add pr1.txt", "Base"}, r.Log("HEAD"), "picked in the order of the PR")
+	})
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/git"
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/plain"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// interruptGrace is how long a cancelled command gets to stop on its own,
+// e.g. while it waits for input, before githelper cleans up and exits
+const interruptGrace = 3 * time.Second
+
+// commandTimeout is the --timeout flag
+var commandTimeout time.Duration
+
+// commandCtx is the context of the running command: it's cancelled on
+// Ctrl+C or SIGTERM and when --timeout expires. Tests run commands without one.
+var commandCtx = context.Background()
+
+// gitOperation is an operation git leaves in progress between commands
+type gitOperation struct {
+	Name string
+	// Path, in the git directory, exists while the operation is in progress
+	Path  string
+	Abort []string
+}
+
+var gitOperations = []gitOperation{
+	{"rebase", "rebase-merge", []string{"rebase", "--abort"}},
+	{"rebase", "rebase-apply", []string{"rebase", "--abort"}},
+	{"am", "rebase-apply/applying", []string{"am", "--abort"}},
+	{"merge", "MERGE_HEAD", []string{"merge", "--abort"}},
+	{"cherry-pick", "CHERRY_PICK_HEAD", []string{"cherry-pick", "--abort"}},
+	{"revert", "REVERT_HEAD", []string{"revert", "--abort"}},
+	{"bisect", "BISECT_LOG", []string{"bisect", "reset"}},
+}
+
+var (
+	cancelCommand context.CancelFunc = func() {}
+	commandDone                      = make(chan struct{})
+
+	// operationsBefore are the operations in progress when the command
+	// started, which aren't aborted if it's interrupted
+	operationsBefore map[string]bool
+
+	cleanupMu   sync.Mutex
+	cleanupOnce sync.Once
+	tempPaths   []string
+)
+
+// gitCommand returns a git command that's interrupted along with the
+// running command
+func gitCommand(args ...string) *exec.Cmd {
+	return git.Command(commandCtx, args...)
+}
+
+// executeWithInterrupts runs the root command so that Ctrl+C, SIGTERM and
+// --timeout stop it cleanly: the rebase, merge or bisect it started is
+// aborted and its temporary files are removed. A second Ctrl+C exits at once.
+func executeWithInterrupts() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		cancel()
+		<-signals
+		exitInterrupted()
+	}()
+
+	defer plain.Restore()
+	err := rootCmd.ExecuteContext(ctx)
+	close(commandDone)
+	cancelCommand()
+	if commandCtx.Err() == nil {
+		if err != nil {
+			finishHooks(1, err)
+			finishNotify(1, err)
+			finishHistory(1, err)
+			finishJob(1, err)
+		} else {
+			finishHooks(0, nil)
+			finishNotify(0, nil)
+			finishHistory(0, nil)
+			finishJob(0, nil)
+		}
+		return err
+	}
+	cleanUpInterrupted()
+	// A command that stops by itself on Ctrl+C, such as watch, returns nil
+	if err == nil {
+		finishHooks(0, nil)
+		finishNotify(0, nil)
+		finishHistory(0, nil)
+		finishJob(0, nil)
+		return nil
+	}
+	if errors.Is(commandCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf(i18n.T("interrupt.timed_out"), commandTimeout)
+	} else {
+		err = errors.New(i18n.T("interrupt.interrupted"))
+	}
+	finishHooks(130, err)
+	finishNotify(130, err)
+	finishHistory(130, err)
+	finishJob(130, err)
+	return err
+}
+
+// startCommandContext applies --timeout to the command about to run and
+// notes the git operations already in progress
+func startCommandContext(cmd *cobra.Command) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		return
+	}
+	if commandTimeout > 0 {
+		ctx, cancelCommand = context.WithTimeout(ctx, commandTimeout)
+		cmd.SetContext(ctx)
+	}
+	commandCtx = ctx
+	operationsBefore = operationsInProgress()
+
+	go func() {
+		select {
+		case <-commandDone:
+			return
+		case <-ctx.Done():
+		}
+		select {
+		case <-commandDone:
+		case <-time.After(interruptGrace):
+			exitInterrupted()
+		}
+	}()
+}
+
+// exitInterrupted cleans up and exits when the command doesn't stop
+func exitInterrupted() {
+	if errors.Is(commandCtx.Err(), context.DeadlineExceeded) {
+		fmt.Fprintln(ui.Err(), "\n"+i18n.T("interrupt.timed_out_notice", commandTimeout))
+	} else {
+		fmt.Fprintln(ui.Err(), "\n"+i18n.T("interrupt.interrupted_notice"))
+	}
+	cleanUpInterrupted()
+	finishHistory(130, errors.New(i18n.T("interrupt.interrupted")))
+	finishJob(130, errors.New(i18n.T("interrupt.interrupted")))
+	plain.Restore()
+	os.Exit(130)
+}
+
+// cleanUpInterrupted aborts the git operations the command started and
+// removes its temporary files. It runs git without the cancelled context.
+func cleanUpInterrupted() {
+	cleanupOnce.Do(func() {
+		for _, op := range gitOperations {
+			if operationsBefore[op.Name] || !operationsInProgress()[op.Name] {
+				continue
+			}
+			if output, err := exec.Command("git", op.Abort...).CombinedOutput(); err != nil {
+				ui.Warn(i18n.T("interrupt.abort_failed", op.Name, strings.TrimSpace(string(output))))
+			} else {
+				fmt.Fprintln(ui.Err(), i18n.T("interrupt.aborted", op.Name))
+			}
+		}
+
+		cleanupMu.Lock()
+		defer cleanupMu.Unlock()
+		for _, path := range tempPaths {
+			os.RemoveAll(path)
+		}
+	})
+}
+
+// operationsInProgress returns the names of the git operations in progress
+func operationsInProgress() map[string]bool {
+	ops := map[string]bool{}
+	output, err := exec.Command("git", "rev-parse", "--path-format=absolute", "--git-dir").Output()
+	if err != nil {
+		return ops
+	}
+	gitDir := strings.TrimSpace(string(output))
+	for _, op := range gitOperations {
+		if _, err := os.Stat(gitDir + "/" + op.Path); err == nil {
+			ops[op.Name] = true
+		}
+	}
+	// git am keeps its state where a rebase would
+	if ops["am"] {
+		delete(ops, "rebase")
+	}
+	return ops
+}
+
+// makeTempDir creates a temporary directory like os.MkdirTemp. The caller
+// still removes it; it's also removed if githelper is interrupted first.
+func makeTempDir(dir, pattern string) (string, error) {
+	path, err := os.MkdirTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	cleanupMu.Lock()
+	tempPaths = append(tempPaths, path)
+	cleanupMu.Unlock()
+	return path, nil
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanUpInterrupted(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+	defer func() { cleanupOnce = sync.Once{}; tempPaths = nil }()
+
+	runGit(t, tmpDir, "commit", "-m", "initial")
+	runGit(t, tmpDir, "branch", "-M", "main")
+	runGit(t, tmpDir, "checkout", "-q", "-b", "feature")
+	require.NoError(t, os.WriteFile("test.txt", []byte("feature"), 0644))
+	runGit(t, tmpDir, "commit", "-q", "-am", "feature")
+	runGit(t, tmpDir, "checkout", "-q", "main")
+	require.NoError(t, os.WriteFile("test.txt", []byte("main"), 0644))
+	runGit(t, tmpDir, "commit", "-q", "-am", "main")
+
+	// A bisect started before the command is left alone
+	runGit(t, tmpDir, "bisect", "start")
+	operationsBefore = operationsInProgress()
+	assert.Equal(t, map[string]bool{"bisect": true}, operationsBefore)
+
+	// The command stopped in the middle of a rebase
+	runGit(t, tmpDir, "checkout", "-q", "feature")
+	assert.Error(t, gitCommand("rebase", "main").Run())
+	assert.True(t, operationsInProgress()["rebase"])
+	dir, err := makeTempDir("", "githelper-test-*")
+	require.NoError(t, err)
+
+	cleanUpInterrupted()
+	assert.Equal(t, map[string]bool{"bisect": true}, operationsInProgress())
+	_, err = os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+	content, err := os.ReadFile("test.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "feature", string(content))
+}
@@ -0,0 +1,356 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/history"
+	"github.com/EndlessUphill/git-helper/internal/jobs"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// jobsKeep is how many jobs that are over the store keeps
+const jobsKeep = 50
+
+var runInBackground bool
+
+// jobID is the ID of the job this process runs, when it was started with
+// --background. It's taken out of the environment so the githelper
+// commands of hooks and workflows don't think they are the job.
+var jobID = takeJobID()
+
+// Tests replace these
+var (
+	openJobStore   = jobs.DefaultStore
+	jobsExecutable = os.Executable
+	jobsPoll       = 500 * time.Millisecond
+)
+
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "List the commands running in the background",
+	Long: `List the commands started with --background, running or over.
+
+Long operations take --background to run as a job: clone, copy, mirror,
+mirror run-once, checks wait and the bulk repo commands. The job keeps running
+when you close the terminal, and its output goes to a log in
+~/.githelper/jobs. Jobs can't prompt, so commands that ask for confirmation
+need --yes.
+
+Example:
+  githelper clone torvalds/linux --background --notify
+  githelper jobs              # Every job and its state
+  githelper jobs logs 3       # The output of job 3 so far
+  githelper jobs attach 3     # Follow it until it ends (Ctrl+C detaches)
+  githelper jobs cancel 3`,
+	Args: cobra.NoArgs,
+	RunE: runJobsList,
+}
+
+var jobsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the background jobs",
+	Args:  cobra.NoArgs,
+	RunE:  runJobsList,
+}
+
+var jobsLogsCmd = &cobra.Command{
+	Use:   "logs [id]",
+	Short: "Print the output of a job (default: the last one)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runJobsLogs,
+}
+
+var jobsAttachCmd = &cobra.Command{
+	Use:   "attach [id]",
+	Short: "Follow the output of a job until it ends (default: the last one)",
+	Long: `Print the output of a job and follow it until the job ends. The command
+fails when the job did. Ctrl+C detaches and leaves the job running.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runJobsAttach,
+}
+
+var jobsCancelCmd = &cobra.Command{
+	Use:   "cancel <id>",
+	Short: "Stop a running job, as Ctrl+C would",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runJobsCancel,
+}
+
+func init() {
+	rootCmd.AddCommand(jobsCmd)
+	jobsCmd.AddCommand(jobsListCmd, jobsLogsCmd, jobsAttachCmd, jobsCancelCmd)
+
+	for _, c := range []*cobra.Command{cloneCmd, copyCmd, mirrorCmd, mirrorRunOnceCmd, checksWaitCmd,
+		repoApplyCmd, repoArchiveCmd, repoUnarchiveCmd, repoTransferCmd} {
+		backgroundable(c)
+	}
+}
+
+// backgroundable adds --background to a command, which starts it as a job
+// instead of running it
+func backgroundable(c *cobra.Command) {
+	c.Flags().BoolVar(&runInBackground, "background", false, "run as a background job (see 'githelper jobs')")
+	run := c.RunE
+	c.RunE = func(cmd *cobra.Command, args []string) error {
+		if runInBackground {
+			return startJob(cmd, args)
+		}
+		return run(cmd, args)
+	}
+}
+
+func takeJobID() int {
+	id, _ := strconv.Atoi(os.Getenv(jobs.EnvID))
+	os.Unsetenv(jobs.EnvID)
+	return id
+}
+
+// jobArgs returns the command line running cmd again without --background:
+// its path, the flags that were set and its arguments
+func jobArgs(cmd *cobra.Command, args []string) []string {
+	line := strings.Fields(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()))
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if !f.Changed || f.Name == "background" {
+			return
+		}
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			for _, item := range slice.GetSlice() {
+				line = append(line, "--"+f.Name+"="+item)
+			}
+			return
+		}
+		line = append(line, "--"+f.Name+"="+f.Value.String())
+	})
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			line = append(line, "--")
+			break
+		}
+	}
+	return append(line, args...)
+}
+
+// startJob runs cmd again in a detached process whose output goes to the
+// log of a new job
+func startJob(cmd *cobra.Command, args []string) error {
+	if cmd.Parent() == repoCmd && !assumeYes {
+		return fmt.Errorf("a background job can't ask for confirmation: pass --yes, after checking what it does with --dry-run")
+	}
+	if repoListFile == "-" {
+		return fmt.Errorf("a background job can't read standard input: save the list to a file for --file")
+	}
+	store, err := openJobStore()
+	if err != nil {
+		return err
+	}
+	executable, err := jobsExecutable()
+	if err != nil {
+		return fmt.Errorf("failed to find the githelper executable: %w", err)
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	line := jobArgs(cmd, args)
+	job := &jobs.Job{Command: strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "), Args: history.Redact(line), Dir: dir}
+	if err := store.Create(job); err != nil {
+		return err
+	}
+	log, err := os.Create(store.LogPath(job.ID))
+	if err != nil {
+		return fmt.Errorf("failed to create the job log: %w", err)
+	}
+	defer log.Close()
+
+	child := exec.Command(executable, line...)
+	child.Dir = dir
+	child.Stdout, child.Stderr = log, log
+	child.Env = append(os.Environ(), fmt.Sprintf("%s=%d", jobs.EnvID, job.ID), "GITHELPER_NON_INTERACTIVE=true")
+	jobs.Detach(child)
+	job.Started = time.Now()
+	if err := child.Start(); err != nil {
+		err = fmt.Errorf("failed to start the job: %w", err)
+		store.Save(job)
+		store.Finish(job.ID, jobs.Result{Finished: time.Now(), ExitCode: 1, Error: err.Error()})
+		return err
+	}
+	job.PID = child.Process.Pid
+	// Reaped if this process outlives it, e.g. in a workflow
+	go child.Wait()
+	if err := store.Save(job); err != nil {
+		return err
+	}
+	if err := store.Prune(jobsKeep); err != nil {
+		ui.Warnf("Failed to remove old jobs: %v", err)
+	}
+
+	ui.Successf("Started job %d: githelper %s", job.ID, strings.Join(job.Args, " "))
+	ui.Infof("Follow it with 'githelper jobs attach %d'", job.ID)
+	return nil
+}
+
+// finishJob records how the command ended, when it runs as a job. Failing
+// to record it only warns.
+func finishJob(exitCode int, err error) {
+	if jobID == 0 {
+		return
+	}
+	store, serr := openJobStore()
+	if serr == nil {
+		result := jobs.Result{Finished: time.Now(), ExitCode: exitCode}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		serr = store.Finish(jobID, result)
+	}
+	if serr != nil {
+		ui.Warnf("Failed to record the end of job %d: %v", jobID, serr)
+	}
+}
+
+// findJob returns the job of args[0], or the last job without arguments
+func findJob(store *jobs.Store, args []string) (*jobs.Job, error) {
+	if len(args) == 0 {
+		all, err := store.List()
+		if err != nil {
+			return nil, err
+		}
+		if len(all) == 0 {
+			return nil, fmt.Errorf("no background jobs")
+		}
+		return all[len(all)-1], nil
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid job ID '%s'", args[0])
+	}
+	return store.Get(id)
+}
+
+func runJobsList(cmd *cobra.Command, args []string) error {
+	store, err := openJobStore()
+	if err != nil {
+		return err
+	}
+	all, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(all) == 0 {
+		ui.Println("No background jobs. Start one with --background, e.g. 'githelper clone <repo> --background'")
+		return nil
+	}
+	ui.Printf("%-4s %-10s %-13s %-9s %s\n", "ID", "STATE", "STARTED", "DURATION", "COMMAND")
+	for _, job := range all {
+		ui.Printf("%-4d %-10s %-13s %-9s githelper %s\n", job.ID, job.State(), job.Started.Local().Format("Jan 02 15:04"),
+			job.Duration().Round(time.Second), strings.Join(job.Args, " "))
+	}
+	return nil
+}
+
+func runJobsLogs(cmd *cobra.Command, args []string) error {
+	store, err := openJobStore()
+	if err != nil {
+		return err
+	}
+	job, err := findJob(store, args)
+	if err != nil {
+		return err
+	}
+	log, err := os.Open(store.LogPath(job.ID))
+	if err != nil {
+		return fmt.Errorf("failed to open the log of job %d: %w", job.ID, err)
+	}
+	defer log.Close()
+	_, err = io.Copy(ui.Out(), log)
+	return err
+}
+
+func runJobsAttach(cmd *cobra.Command, args []string) error {
+	store, err := openJobStore()
+	if err != nil {
+		return err
+	}
+	job, err := findJob(store, args)
+	if err != nil {
+		return err
+	}
+	job, err = followJob(commandCtx, store, job.ID, ui.Out())
+	if errors.Is(err, context.Canceled) {
+		ui.Infof("\nDetached from job %d, which keeps running", job.ID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch state := job.State(); state {
+	case jobs.Succeeded:
+		ui.Successf("\nJob %d succeeded in %s", job.ID, job.Duration().Round(time.Second))
+		return nil
+	case jobs.Lost:
+		return fmt.Errorf("job %d stopped without recording how", job.ID)
+	default:
+		return fmt.Errorf("job %d %s: %s", job.ID, state, job.Result.Error)
+	}
+}
+
+// followJob copies the log of a job to w until the job is over, and returns
+// the job as it ended
+func followJob(ctx context.Context, store *jobs.Store, id int, w io.Writer) (*jobs.Job, error) {
+	log, err := os.Open(store.LogPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the log of job %d: %w", id, err)
+	}
+	defer log.Close()
+
+	for {
+		if _, err := io.Copy(w, log); err != nil {
+			return nil, err
+		}
+		job, err := store.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		if job.State() != jobs.Running {
+			// What it wrote between the copy and its end
+			_, err := io.Copy(w, log)
+			return job, err
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-time.After(jobsPoll):
+		}
+	}
+}
+
+func runJobsCancel(cmd *cobra.Command, args []string) error {
+	store, err := openJobStore()
+	if err != nil {
+		return err
+	}
+	job, err := findJob(store, args)
+	if err != nil {
+		return err
+	}
+	if state := job.State(); state != jobs.Running {
+		return fmt.Errorf("job %d is not running (%s)", job.ID, state)
+	}
+	if err := jobs.Terminate(job.PID); err != nil {
+		return fmt.Errorf("failed to stop job %d: %w", job.ID, err)
+	}
+	ui.Successf("Stopping job %d: githelper %s", job.ID, strings.Join(job.Args, " "))
+	return nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withFakeJobs keeps jobs in a temporary store and runs them with a script
+// that prints its arguments, or sleeps when FAKE_JOB_SLEEP is set
+func withFakeJobs(t *testing.T) *jobs.Store {
+	if runtime.GOOS == "windows" {
+		t.Skip("fakes githelper with a shell script")
+	}
+	store := &jobs.Store{Dir: t.TempDir()}
+	script := filepath.Join(t.TempDir(), "githelper")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho \"ran $* as job $"+jobs.EnvID+"\"\n[ -n \"$FAKE_JOB_SLEEP\" ] && exec sleep 30\nexit 0\n"), 0755))
+
+	oldStore, oldExecutable, oldPoll := openJobStore, jobsExecutable, jobsPoll
+	t.Cleanup(func() { openJobStore, jobsExecutable, jobsPoll = oldStore, oldExecutable, oldPoll })
+	openJobStore = func() (*jobs.Store, error) { return store, nil }
+	jobsExecutable = func() (string, error) { return script, nil }
+	jobsPoll = 10 * time.Millisecond
+	return store
+}
+
+// waitForExit waits for the process of a job to end
+func waitForExit(t *testing.T, store *jobs.Store, id int) {
+	job, err := store.Get(id)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return !jobs.Alive(job.PID) }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestJobs(t *testing.T) {
+	store := withFakeJobs(t)
+
+	stdout, _, err := execute(t, "clone", "acme/widget", "--depth", "1", "--background")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Started job 1: githelper clone --depth=1 acme/widget")
+	waitForExit(t, store, 1)
+
+	stdout, _, err = execute(t, "jobs", "logs", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "ran clone --depth=1 acme/widget as job 1\n", stdout)
+
+	// The fake doesn't record how it ended, as githelper does
+	stdout, _, err = execute(t, "jobs")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "1    lost")
+	jobID = 1
+	finishJob(0, nil)
+	jobID = 0
+	stdout, _, err = execute(t, "jobs", "list")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "1    succeeded")
+	assert.Contains(t, stdout, "githelper clone --depth=1 acme/widget")
+
+	stdout, _, err = execute(t, "jobs", "attach")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "ran clone --depth=1 acme/widget as job 1\n")
+	assert.Contains(t, stdout, "Job 1 succeeded")
+
+	_, _, err = execute(t, "jobs", "cancel", "1")
+	assert.EqualError(t, err, "job 1 is not running (succeeded)")
+}
+
+func TestJobsCancel(t *testing.T) {
+	store := withFakeJobs(t)
+	t.Setenv("FAKE_JOB_SLEEP", "1")
+
+	_, _, err := execute(t, "checks", "wait", "--background")
+	require.NoError(t, err)
+	job, err := store.Get(1)
+	require.NoError(t, err)
+	assert.Equal(t, jobs.Running, job.State())
+
+	_, _, err = execute(t, "jobs", "cancel", "1")
+	require.NoError(t, err)
+	waitForExit(t, store, 1)
+}
+
+func TestJobsNeedYes(t *testing.T) {
+	withFakeJobs(t)
+	_, _, err := execute(t, "repo", "archive", "acme/old", "--background")
+	assert.ErrorContains(t, err, "pass --yes")
+	_, _, err = execute(t, "jobs", "logs")
+	assert.EqualError(t, err, "no background jobs")
+}
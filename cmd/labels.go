@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	labelsFrom  string
+	labelsFile  string
+	labelsTo    []string
+	labelsPrune bool
+)
+
+var labelsCmd = &cobra.Command{
+	Use:   "labels",
+	Short: "Manage issue labels",
+	Long: `Manage the issue labels of GitHub repositories.
+
+Example:
+  githelper labels sync --from octo/template --to octo/app,octo/lib`,
+}
+
+var labelsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Make repositories share the same labels",
+	Long: `Create and update labels so that repositories share the labels of a
+source repository (--from) or of a YAML file (--file).
+
+The file uses the labels section of 'githelper repo apply' settings files,
+and its repositories and prune_labels entries are used when --to and --prune
+aren't given. Labels are matched by name, ignoring case. Labels that only
+exist in a target are kept unless --prune is passed.
+
+Example labels.yaml:
+  repositories: [octo/app, octo/lib]
+  labels:
+    - {name: bug, color: d73a4a, description: Something isn't working}
+    - {name: enhancement, color: a2eeef}
+
+Example:
+  githelper labels sync --from octo/template --to octo/app,octo/lib
+  githelper labels sync --file labels.yaml --dry-run
+  githelper labels sync --file labels.yaml --prune   # Also delete other labels`,
+	Args: cobra.NoArgs,
+	RunE: runLabelsSync,
+}
+
+func init() {
+	rootCmd.AddCommand(labelsCmd)
+	labelsCmd.AddCommand(labelsSyncCmd)
+	labelsSyncCmd.Flags().StringVar(&labelsFrom, "from", "", "repository to copy the labels from, as owner/name")
+	labelsSyncCmd.Flags().StringVarP(&labelsFile, "file", "f", "", "YAML file with the labels")
+	labelsSyncCmd.Flags().StringSliceVar(&labelsTo, "to", nil, "repositories to update, as owner/name (default: the file's repositories or origin)")
+	labelsSyncCmd.Flags().BoolVar(&labelsPrune, "prune", false, "delete labels that the source doesn't have")
+	labelsSyncCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the changes without applying them")
+}
+
+func runLabelsSync(cmd *cobra.Command, args []string) error {
+	if (labelsFrom == "") == (labelsFile == "") {
+		return fmt.Errorf("give the labels with either --from or --file")
+	}
+
+	targets := labelsTo
+	prune := labelsPrune
+	var desired []github.Label
+	if labelsFile != "" {
+		spec, err := github.LoadRepoSpec(labelsFile)
+		if err != nil {
+			return err
+		}
+		if len(spec.Labels) == 0 {
+			return fmt.Errorf("%s has no labels", labelsFile)
+		}
+		desired = spec.Labels
+		if len(targets) == 0 {
+			targets = spec.Repositories
+		}
+		prune = prune || spec.PruneLabels
+	}
+
+	client, targets, err := repoTargets(targets)
+	if err != nil {
+		return fmt.Errorf("%w. Pass the repositories with --to", err)
+	}
+	ctx := context.Background()
+
+	if labelsFrom != "" {
+		owner, name, ok := strings.Cut(labelsFrom, "/")
+		if !ok {
+			return fmt.Errorf("invalid repository '%s', use owner/name", labelsFrom)
+		}
+		if desired, err = client.ListLabels(ctx, owner, name); err != nil {
+			return fmt.Errorf("failed to list labels of %s: %w", labelsFrom, err)
+		}
+		ui.Stepf("🏷️  %s has %d label(s)", labelsFrom, len(desired))
+	}
+
+	plans := map[string][]github.LabelChange{}
+	total := 0
+	for _, repo := range targets {
+		if strings.EqualFold(repo, labelsFrom) {
+			continue
+		}
+		owner, name, _ := strings.Cut(repo, "/")
+		current, err := client.ListLabels(ctx, owner, name)
+		if err != nil {
+			return fmt.Errorf("failed to list labels of %s: %w", repo, err)
+		}
+		changes := github.PlanLabels(current, desired, prune)
+		plans[repo] = changes
+		total += len(changes)
+
+		if len(changes) == 0 {
+			ui.Successf("%s is up to date", repo)
+			continue
+		}
+		ui.Stepf("📝 %s:", repo)
+		for _, change := range changes {
+			ui.Printf("  %s\n", github.DescribeLabelChange(change))
+		}
+	}
+
+	if total == 0 {
+		return nil
+	}
+	if dryRun {
+		ui.Stepf("\n🔍 Dry run - %d change(s) not applied", total)
+		return nil
+	}
+	ui.Printf("\n%d change(s) will be applied\n", total)
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	failed := 0
+	for _, repo := range targets {
+		owner, name, _ := strings.Cut(repo, "/")
+		for _, change := range plans[repo] {
+			if err := client.ApplyLabelChange(ctx, owner, name, change); err != nil {
+				ui.Errorf("%s: failed to %s: %v", repo, github.DescribeLabelChange(change), err)
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d label change(s) failed", failed)
+	}
+	ui.Success("Labels synced!")
+	return nil
+}
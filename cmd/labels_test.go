@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/github/githubtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// labelWrites returns the requests that changed labels
+func labelWrites(server *githubtest.Server) []githubtest.Request {
+	var writes []githubtest.Request
+	for _, request := range server.Requests() {
+		if request.Method != "GET" {
+			writes = append(writes, request)
+		}
+	}
+	return writes
+}
+
+func TestLabelsSync(t *testing.T) {
+	server := fakeGitHub(t)
+	server.AddRepo("octo/template").Labels = []github.Label{
+		{Name: "bug", Color: "d73a4a", Description: "Something isn't working"},
+		{Name: "enhancement", Color: "a2eeef"},
+	}
+	app := server.AddRepo("octo/app")
+	app.Labels = []github.Label{
+		{Name: "Bug", Color: "ff0000"},
+		{Name: "enhancement", Color: "a2eeef"},
+		{Name: "wontfix", Color: "ffffff"},
+	}
+	lib := server.AddRepo("octo/lib")
+
+	// A dry run lists the changes of every repository and writes nothing
+	stdout, _, err := execute(t, "labels", "sync", "--from", "octo/template", "--to", "octo/app,octo/lib", "--prune", "--dry-run")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "update label Bug (rename to bug, color ff0000 → d73a4a, new description)")
+	assert.Contains(t, stdout, "delete label wontfix")
+	assert.Contains(t, stdout, "create label bug (#d73a4a)")
+	assert.Contains(t, stdout, "create label enhancement (#a2eeef)")
+	assert.Contains(t, stdout, "Dry run - 4 change(s) not applied")
+	assert.Empty(t, labelWrites(server))
+
+	// Without --prune, labels only in a target are kept
+	_, _, err = execute(t, "labels", "sync", "--from", "octo/template", "--to", "octo/app,octo/lib", "--yes")
+	require.NoError(t, err)
+	assert.Equal(t, []github.Label{
+		{Name: "bug", Color: "d73a4a", Description: "Something isn't working"},
+		{Name: "enhancement", Color: "a2eeef"},
+		{Name: "wontfix", Color: "ffffff"},
+	}, app.Labels)
+	assert.Equal(t, server.Repo("octo/template").Labels, lib.Labels)
+	assert.Equal(t, []githubtest.Request{
+		{Method: "PATCH", Path: "/repos/octo/app/labels/Bug"},
+		{Method: "POST", Path: "/repos/octo/lib/labels"},
+		{Method: "POST", Path: "/repos/octo/lib/labels"},
+	}, labelWrites(server))
+
+	stdout, _, err = execute(t, "labels", "sync", "--from", "octo/template", "--to", "octo/app,octo/lib", "--prune", "--yes")
+	require.NoError(t, err)
+	assert.NotContains(t, stdout, "octo/lib:")
+	assert.Equal(t, server.Repo("octo/template").Labels, app.Labels)
+}
+
+func TestLabelsSyncFromFile(t *testing.T) {
+	server := fakeGitHub(t)
+	app := server.AddRepo("octo/app")
+	app.Labels = []github.Label{{Name: "bug", Color: "d73a4a"}, {Name: "question", Color: "d876e3"}}
+
+	file := filepath.Join(t.TempDir(), "labels.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`repositories: [octo/app]
+prune_labels: true
+labels:
+  - {name: bug, color: "#D73A4A"}
+  - {name: docs, color: 0075ca}
+`), 0644))
+
+	stdout, _, err := execute(t, "labels", "sync", "--file", file, "--yes")
+	require.NoError(t, err)
+	assert.NotContains(t, stdout, "update label bug", "colors match ignoring case and #")
+	assert.Equal(t, []github.Label{{Name: "bug", Color: "d73a4a"}, {Name: "docs", Color: "0075ca"}}, app.Labels)
+
+	stdout, _, err = execute(t, "labels", "sync", "--file", file, "--yes")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "octo/app is up to date")
+
+	_, _, err = execute(t, "labels", "sync", "--file", file, "--from", "octo/app")
+	assert.ErrorContains(t, err, "either --from or --file")
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var linkMarkdown bool
+
+var linkCmd = &cobra.Command{
+	Use:   "link <file>[:<line>[-<line>]]...",
+	Short: "Print a permalink to lines of code, pinned to the current commit",
+	Long: `Print the GitHub permalink of a file, or of a line or range of lines in it,
+at the commit checked out. Unlike a link to a branch, it keeps pointing at
+the same code after the file changes, so it is safe to paste into issues,
+reviews and chat.
+
+The link only works once the commit is pushed; you are warned when it
+isn't, and when the file has changes that aren't committed, as the lines may
+not match.
+
+Example:
+  githelper link cmd/root.go:42
+  githelper link cmd/root.go:10-20 --markdown   # [cmd/root.go:10-20](https://...)
+  githelper link internal/ui/ui.go:7 --copy
+  githelper link go.mod main.go:1-5             # One link per line`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runLink,
+}
+
+func init() {
+	rootCmd.AddCommand(linkCmd)
+	linkCmd.Flags().BoolVarP(&linkMarkdown, "markdown", "m", false, "print markdown links, titled with the file and lines")
+}
+
+func runLink(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	web, err := originWebURL()
+	if err != nil {
+		return err
+	}
+	output, err := gitOutput("rev-parse", "--verify", "-q", "HEAD")
+	if err != nil {
+		return fmt.Errorf("no commit to link to yet")
+	}
+	head := strings.TrimSpace(string(output))
+	warnUnpushed(head)
+
+	var links []string
+	for _, arg := range args {
+		link, err := permalink(web, head, arg)
+		if err != nil {
+			return err
+		}
+		links = append(links, link)
+		ui.Println(link)
+	}
+	copyToClipboard("link", strings.Join(links, "\n"))
+	return nil
+}
+
+// permalink returns the link of file[:lines] at commit, as markdown with
+// --markdown
+func permalink(web, commit, arg string) (string, error) {
+	file, lines := splitLineSuffix(arg)
+	if info, err := os.Stat(file); err == nil && info.IsDir() {
+		return "", fmt.Errorf("'%s' is a directory, link a file", file)
+	}
+	repoPath, err := repoRelativePath(file)
+	if err != nil {
+		return "", err
+	}
+	if repoPath == "" {
+		return "", fmt.Errorf("'%s' is the repository itself, link a file", file)
+	}
+
+	content, err := gitOutput("cat-file", "blob", commit+":"+repoPath)
+	if err != nil {
+		return "", fmt.Errorf("'%s' isn't in the current commit", repoPath)
+	}
+	if lines != "" {
+		count := bytes.Count(content, []byte("\n"))
+		if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+			count++
+		}
+		if err := checkLines(lines, count); err != nil {
+			return "", fmt.Errorf("%s: %w", repoPath, err)
+		}
+	}
+	if gitCommand("diff", "--quiet", commit, "--", repoPath).Run() != nil {
+		ui.Warnf("%s has changes that aren't committed, the lines may not match the link", repoPath)
+	}
+
+	link := fileURL(web, commit, repoPath, lines)
+	if !linkMarkdown {
+		return link, nil
+	}
+	title := repoPath
+	if lines != "" {
+		title += ":" + lines
+	}
+	return fmt.Sprintf("[%s](%s)", title, link), nil
+}
+
+// checkLines validates lines, as "42" or "10-20", against the number of
+// lines of a file
+func checkLines(lines string, count int) error {
+	first, last, found := strings.Cut(lines, "-")
+	start, _ := strconv.Atoi(first)
+	end := start
+	if found {
+		end, _ = strconv.Atoi(last)
+	}
+	switch {
+	case start < 1 || end < start:
+		return fmt.Errorf("invalid line range %s", lines)
+	case end > count:
+		return fmt.Errorf("line %d is past the end of the file (%d lines)", end, count)
+	}
+	return nil
+}
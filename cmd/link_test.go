@@ -0,0 +1,16 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLines(t *testing.T) {
+	assert.NoError(t, checkLines("1", 1))
+	assert.NoError(t, checkLines("10-20", 20))
+	assert.ErrorContains(t, checkLines("21", 20), "line 21 is past the end of the file (20 lines)")
+	assert.ErrorContains(t, checkLines("5-30", 20), "line 30 is past the end")
+	assert.ErrorContains(t, checkLines("0", 20), "invalid line range 0")
+	assert.ErrorContains(t, checkLines("9-3", 20), "invalid line range 9-3")
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// HistoryPolicy is the lint_history section of .githelper.yaml
+type HistoryPolicy struct {
+	NoMerges         bool
+	NoFixups         bool
+	Conventional     bool
+	Types            []string
+	MaxSubjectLength int
+	Signed           bool
+}
+
+// defaultHistoryPolicy applies to the settings lint_history doesn't set
+var defaultHistoryPolicy = HistoryPolicy{
+	NoMerges:         true,
+	NoFixups:         true,
+	MaxSubjectLength: 72,
+}
+
+// fixupPrefixes mark commits meant to be squashed by 'git rebase --autosquash'
+var fixupPrefixes = []string{"fixup! ", "squash! ", "amend! "}
+
+var lintHistoryCmd = &cobra.Command{
+	Use:   "lint-history [range]",
+	Short: "Check that a range of commits follows the history policy",
+	Long: `Check every commit of a range against the history policy of the repository
+and exit with an error when one violates it, so CI can enforce the policy on
+pull requests.
+
+The range defaults to the commits not in the default branch of origin
+(origin/main..HEAD). The policy is read from lint_history in .githelper.yaml:
+
+  lint_history:
+    no_merges: true              # linear history, rebase instead of merging (default)
+    no_fixups: true              # no leftover fixup!/squash!/amend! commits (default)
+    conventional: true           # subjects like "feat(scope): summary"
+    types: [feat, fix, docs, chore]  # conventional types allowed (default any)
+    max_subject_length: 72       # 0 disables the check (default 72)
+    signed: true                 # valid GPG/SSH signatures required
+
+Example:
+  githelper lint-history
+  githelper lint-history main..feature
+  githelper lint-history origin/main..HEAD   # in CI`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLintHistory,
+}
+
+func init() {
+	rootCmd.AddCommand(lintHistoryCmd)
+}
+
+// HistoryCommit is a commit checked by lint-history
+type HistoryCommit struct {
+	Hash      string
+	Parents   int
+	Signature string
+	Author    string
+	Subject   string
+}
+
+func runLintHistory(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	var revRange string
+	if len(args) > 0 {
+		revRange = args[0]
+	} else {
+		branch, err := defaultBranch()
+		if err != nil {
+			return fmt.Errorf("failed to find the default branch, pass a range: %w", err)
+		}
+		revRange = "origin/" + branch + "..HEAD"
+	}
+
+	policy := loadHistoryPolicy()
+	commits, err := historyCommits(revRange, policy.Signed)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		ui.Successf("No commits in range %s", revRange)
+		return nil
+	}
+
+	ui.Stepf("📏 Policy: %s", describeHistoryPolicy(policy))
+	ui.Printf("🔍 Checking %d commit(s) in %s:\n\n", len(commits), revRange)
+	failed := 0
+	for _, c := range commits {
+		problems := lintCommit(c, policy)
+		status := "✅"
+		if len(problems) > 0 {
+			status = "❌"
+			failed++
+		}
+		ui.Printf("%s %s %-20s %s\n", status, c.Hash[:8], truncate(c.Author, 20), c.Subject)
+		for _, problem := range problems {
+			ui.Printf("      %s\n", problem)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d commit(s) violate the history policy", failed, len(commits))
+	}
+	ui.Success("\nThe history follows the policy!")
+	return nil
+}
+
+// loadHistoryPolicy reads lint_history from the configuration, falling back
+// to the defaults for unset values
+func loadHistoryPolicy() HistoryPolicy {
+	policy := defaultHistoryPolicy
+	if viper.IsSet("lint_history.no_merges") {
+		policy.NoMerges = viper.GetBool("lint_history.no_merges")
+	}
+	if viper.IsSet("lint_history.no_fixups") {
+		policy.NoFixups = viper.GetBool("lint_history.no_fixups")
+	}
+	if viper.IsSet("lint_history.max_subject_length") {
+		policy.MaxSubjectLength = viper.GetInt("lint_history.max_subject_length")
+	}
+	policy.Conventional = viper.GetBool("lint_history.conventional")
+	policy.Types = viper.GetStringSlice("lint_history.types")
+	policy.Signed = viper.GetBool("lint_history.signed")
+	return policy
+}
+
+func describeHistoryPolicy(policy HistoryPolicy) string {
+	var rules []string
+	if policy.NoMerges {
+		rules = append(rules, "no merges")
+	}
+	if policy.NoFixups {
+		rules = append(rules, "no fixups")
+	}
+	if policy.Conventional {
+		rule := "conventional commits"
+		if len(policy.Types) > 0 {
+			rule += " (" + strings.Join(policy.Types, ", ") + ")"
+		}
+		rules = append(rules, rule)
+	}
+	if policy.MaxSubjectLength > 0 {
+		rules = append(rules, fmt.Sprintf("subjects up to %d characters", policy.MaxSubjectLength))
+	}
+	if policy.Signed {
+		rules = append(rules, "signed")
+	}
+	if len(rules) == 0 {
+		return "none"
+	}
+	return strings.Join(rules, ", ")
+}
+
+// lintCommit returns the ways a commit violates the policy
+func lintCommit(c HistoryCommit, policy HistoryPolicy) []string {
+	var problems []string
+	merge := c.Parents > 1
+	if merge && policy.NoMerges {
+		problems = append(problems, "merge commit, rebase instead to keep the history linear")
+	}
+	if policy.Signed && !isValidSignature(c.Signature) {
+		problems = append(problems, "signature: "+signatureStatusText[c.Signature])
+	}
+	if merge {
+		// The subject of a merge is generated by git
+		return problems
+	}
+
+	for _, prefix := range fixupPrefixes {
+		if policy.NoFixups && strings.HasPrefix(c.Subject, prefix) {
+			problems = append(problems, fmt.Sprintf("%scommit left over, squash it with 'git rebase -i --autosquash'", prefix))
+			return problems
+		}
+	}
+	if policy.Conventional {
+		fields := parseConventionalHeader(c.Subject)
+		switch {
+		case fields.Type == "":
+			problems = append(problems, `not a conventional commit ("type(scope): summary")`)
+		case len(policy.Types) > 0 && !slices.Contains(policy.Types, fields.Type):
+			problems = append(problems, fmt.Sprintf("type '%s' isn't one of %s", fields.Type, strings.Join(policy.Types, ", ")))
+		}
+	}
+	if length := len([]rune(c.Subject)); policy.MaxSubjectLength > 0 && length > policy.MaxSubjectLength {
+		problems = append(problems, fmt.Sprintf("subject is %d characters, the limit is %d", length, policy.MaxSubjectLength))
+	}
+	return problems
+}
+
+// historyCommits lists the commits of a range; checking signatures is slow,
+// so they are only read when asked for
+func historyCommits(revRange string, signatures bool) ([]HistoryCommit, error) {
+	signature := ""
+	if signatures {
+		signature = "%G?"
+	}
+	format := "%H%x1f%P%x1f" + signature + "%x1f%an%x1f%s%x1e"
+	output, err := gitCommand("log", "--format="+format, revRange).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to list commits in %s: %s", revRange, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to list commits in %s: %w", revRange, err)
+	}
+
+	var commits []HistoryCommit
+	for _, record := range strings.Split(string(output), "\x1e") {
+		fields := strings.Split(strings.TrimSpace(record), "\x1f")
+		if len(fields) < 5 {
+			continue
+		}
+		commits = append(commits, HistoryCommit{
+			Hash:      fields[0],
+			Parents:   len(strings.Fields(fields[1])),
+			Signature: fields[2],
+			Author:    fields[3],
+			Subject:   fields[4],
+		})
+	}
+	return commits, nil
+}
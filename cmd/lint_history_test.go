@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintCommit(t *testing.T) {
+	policy := HistoryPolicy{NoMerges: true, NoFixups: true, Conventional: true, Types: []string{"feat", "fix"}, MaxSubjectLength: 20, Signed: true}
+
+	assert.Empty(t, lintCommit(HistoryCommit{Parents: 1, Signature: "G", Subject: "feat: add login"}, policy))
+	assert.Len(t, lintCommit(HistoryCommit{Parents: 2, Signature: "G", Subject: "Merge branch 'x'"}, policy), 1)
+	assert.Equal(t, []string{"signature: unsigned"}, lintCommit(HistoryCommit{Parents: 1, Signature: "N", Subject: "fix: typo"}, policy))
+	assert.Len(t, lintCommit(HistoryCommit{Parents: 1, Signature: "G", Subject: "fixup! feat: add login"}, policy), 1)
+	assert.Equal(t, []string{"type 'docs' isn't one of feat, fix"}, lintCommit(HistoryCommit{Parents: 1, Signature: "G", Subject: "docs: readme"}, policy))
+	assert.Equal(t, []string{`not a conventional commit ("type(scope): summary")`, "subject is 25 characters, the limit is 20"},
+		lintCommit(HistoryCommit{Parents: 1, Signature: "G", Subject: "Add a much longer subject"}, policy))
+
+	assert.Empty(t, lintCommit(HistoryCommit{Parents: 2, Subject: "Merge branch 'x'"}, HistoryPolicy{}))
+}
+
+func TestLoadHistoryPolicy(t *testing.T) {
+	defer viper.Reset()
+	assert.Equal(t, defaultHistoryPolicy, loadHistoryPolicy())
+
+	viper.Set("lint_history.no_merges", false)
+	viper.Set("lint_history.max_subject_length", 0)
+	viper.Set("lint_history.conventional", true)
+	policy := loadHistoryPolicy()
+	assert.False(t, policy.NoMerges)
+	assert.True(t, policy.NoFixups)
+	assert.True(t, policy.Conventional)
+	assert.Zero(t, policy.MaxSubjectLength)
+}
+
+func TestHistoryCommits(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "base")
+	runGit(t, tmpDir, "checkout", "-q", "-b", "feature")
+	runGit(t, tmpDir, "commit", "--allow-empty", "-m", "feat: one")
+	runGit(t, tmpDir, "checkout", "-q", "-b", "side", "HEAD~1")
+	runGit(t, tmpDir, "commit", "--allow-empty", "-m", "fix: two")
+	runGit(t, tmpDir, "checkout", "-q", "feature")
+	runGit(t, tmpDir, "merge", "-q", "--no-ff", "-m", "Merge side", "side")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	commits, err := historyCommits("HEAD~2..HEAD", false)
+	require.NoError(t, err)
+	require.Len(t, commits, 3)
+	assert.Equal(t, "Merge side", commits[0].Subject)
+	assert.Equal(t, 2, commits[0].Parents)
+	assert.Equal(t, "Test", commits[0].Author)
+	assert.Equal(t, 1, commits[1].Parents)
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/history"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	logAll    bool
+	logFailed bool
+	logLimit  int
+	logFormat string
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show what githelper did in this repository",
+	Long: `List the githelper commands that ran, newest first: when, with which
+arguments, how they ended and which branches and tags they created, moved or
+deleted.
+
+Every command is appended to ~/.githelper/history.jsonl. The log never leaves
+your machine; values of flags such as --token or --webhook-secret are
+replaced by *** before they're written. Set history: false in the config to
+stop recording.
+
+To undo a destructive command, see 'githelper rollback'.
+
+Example:
+  githelper log                 # Commands run in this repository
+  githelper log --all           # Commands run anywhere
+  githelper log --failed -n 5   # The last failed commands
+  githelper log --format json   # Machine-readable output`,
+	Args: cobra.NoArgs,
+	RunE: runLog,
+}
+
+func init() {
+	rootCmd.AddCommand(logCmd)
+	logCmd.Flags().BoolVar(&logAll, "all", false, "show commands from every repository")
+	logCmd.Flags().BoolVar(&logFailed, "failed", false, "only show commands that failed or were interrupted")
+	logCmd.Flags().IntVarP(&logLimit, "limit", "n", 20, "number of commands to show; 0 for all")
+	logCmd.Flags().StringVar(&logFormat, "format", "table", "output format: table, json")
+}
+
+// historySkip lists the commands not worth recording in the log
+var historySkip = map[string]bool{
+	"log":        true,
+	"help":       true,
+	"completion": true,
+	"__complete": true,
+	"version":    true,
+	"gen-docs":   true,
+}
+
+// historyRecording is the command being recorded and the refs before it ran
+type historyRecording struct {
+	entry      history.Entry
+	refsBefore map[string]string
+}
+
+// historyRun is nil when the running command isn't recorded
+var historyRun *historyRecording
+
+// startHistory notes the command about to run and the refs before it
+func startHistory(cmd *cobra.Command) {
+	if viper.IsSet("history") && !viper.GetBool("history") {
+		return
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if historySkip[c.Name()] {
+			return
+		}
+	}
+	historyRun = &historyRecording{
+		entry: history.Entry{
+			Time:    time.Now(),
+			Command: strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "),
+			Args:    history.Redact(os.Args[1:]),
+		},
+	}
+	if root, err := getRepoRoot(); err == nil {
+		historyRun.entry.Repo = root
+		historyRun.refsBefore = history.Refs(commandCtx)
+	}
+}
+
+// finishHistory appends the command that ran to the log. Failing to write
+// the log never fails the command.
+func finishHistory(exitCode int, err error) {
+	if historyRun == nil {
+		return
+	}
+	entry := historyRun.entry
+	entry.Duration = time.Since(entry.Time).Round(time.Millisecond)
+	entry.ExitCode = exitCode
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	// The command is over, and may have been interrupted
+	if entry.Repo != "" {
+		entry.Refs = history.Changes(historyRun.refsBefore, history.Refs(context.Background()))
+	}
+	historyRun = nil
+
+	file, fileErr := history.DefaultFile()
+	if fileErr == nil {
+		fileErr = history.Append(file, entry)
+	}
+	if fileErr != nil && viper.GetBool("debug") {
+		ui.Warnf("Failed to record the command in the log: %v", fileErr)
+	}
+}
+
+func runLog(cmd *cobra.Command, args []string) error {
+	file, err := history.DefaultFile()
+	if err != nil {
+		return err
+	}
+	entries, err := history.Read(file)
+	if err != nil {
+		return err
+	}
+
+	repo := ""
+	if !logAll {
+		if repo, err = getRepoRoot(); err != nil {
+			return fmt.Errorf("not in a git repository. Use --all to show commands from every repository")
+		}
+	}
+
+	// Newest first
+	var shown []history.Entry
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if repo != "" && entry.Repo != repo {
+			continue
+		}
+		if logFailed && entry.ExitCode == 0 {
+			continue
+		}
+		shown = append(shown, entry)
+		if logLimit > 0 && len(shown) == logLimit {
+			break
+		}
+	}
+
+	switch logFormat {
+	case "json":
+		if shown == nil {
+			shown = []history.Entry{}
+		}
+		encoder := json.NewEncoder(ui.Out())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(shown)
+	case "table":
+		printHistory(shown, logAll)
+		return nil
+	default:
+		return fmt.Errorf("invalid format '%s'. Use table or json", logFormat)
+	}
+}
+
+func printHistory(entries []history.Entry, showRepo bool) {
+	if len(entries) == 0 {
+		if showRepo {
+			ui.Println("No githelper commands recorded yet")
+		} else {
+			ui.Println("No githelper commands recorded in this repository")
+		}
+		return
+	}
+
+	for _, entry := range entries {
+		status := "✅"
+		if entry.ExitCode == 130 {
+			status = "🛑"
+		} else if entry.ExitCode != 0 {
+			status = "❌"
+		}
+		ui.Printf("%s %s  %s (%s)\n", status, entry.Time.Format("2006-01-02 15:04:05"),
+			strings.Join(append([]string{rootCmd.Name()}, entry.Args...), " "), formatDuration(entry.Duration))
+		if showRepo && entry.Repo != "" {
+			ui.Printf("   in %s\n", entry.Repo)
+		}
+		if entry.Error != "" {
+			ui.Printf("   error: %s\n", truncate(strings.ReplaceAll(entry.Error, "\n", " "), 100))
+		}
+		for _, ref := range entry.Refs {
+			ui.Printf("   %s\n", describeRefChange(ref))
+		}
+	}
+}
+
+// describeRefChange prints a ref change as "<ref>: <old> -> <new>"
+func describeRefChange(ref history.RefChange) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(ref.Name, "refs/heads/"), "refs/")
+	describe := func(value string) string {
+		if strings.HasPrefix(value, "refs/") {
+			return strings.TrimPrefix(value, "refs/heads/")
+		}
+		return shortSHA(value)
+	}
+	switch {
+	case ref.Old == "":
+		return fmt.Sprintf("+ %s: %s", name, describe(ref.New))
+	case ref.New == "":
+		return fmt.Sprintf("- %s: was %s", name, describe(ref.Old))
+	}
+	return fmt.Sprintf("~ %s: %s -> %s", name, describe(ref.Old), describe(ref.New))
+}
+
+// formatDuration prints a duration to a useful precision, e.g. 350ms or 1m5s
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(time.Second / 10).String()
+}
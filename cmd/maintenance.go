@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/git"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// maintenanceTasks are the tasks run by default, the ones git's incremental
+// strategy schedules: cheap enough to run often on large repositories
+var maintenanceTasks = []string{"commit-graph", "prefetch", "loose-objects", "incremental-repack"}
+
+// knownMaintenanceTasks are all the tasks 'git maintenance run' accepts
+var knownMaintenanceTasks = []string{"commit-graph", "prefetch", "loose-objects", "incremental-repack", "gc", "pack-refs"}
+
+// maintenanceConfig is set on enable, when not configured already
+var maintenanceConfig = []struct{ Key, Value string }{
+	{"maintenance.strategy", "incremental"},
+	{"core.commitGraph", "true"},
+	{"fetch.writeCommitGraph", "true"},
+	{"core.multiPackIndex", "true"},
+}
+
+var (
+	maintenanceTaskList  []string
+	maintenanceNoSched   bool
+	maintenanceScheduler string
+)
+
+var maintenanceCmd = &cobra.Command{
+	Use:   "maintenance",
+	Short: "Keep a large repository fast with background maintenance",
+	Long: `Set up and run git's maintenance tasks, which keep large repositories fast
+without the long pauses of a full gc:
+
+  commit-graph        speeds up log, merge-base and reachability checks
+  prefetch            fetches remotes in the background, so fetch is quick
+  loose-objects       packs loose objects
+  incremental-repack  merges small packs using a multi-pack-index
+
+Without a subcommand, shows the maintenance status of the repository.
+
+Example:
+  githelper maintenance                   # Show the status
+  githelper maintenance enable            # Register and schedule hourly tasks
+  githelper maintenance enable --no-schedule
+  githelper maintenance run               # Run the tasks now
+  githelper maintenance run --task gc
+  githelper maintenance disable`,
+	Args: cobra.NoArgs,
+	RunE: runMaintenanceStatus,
+}
+
+var maintenanceEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Configure maintenance and schedule it",
+	Long: `Configure the repository for large-repository maintenance (the incremental
+strategy, commit-graphs written on fetch and multi-pack-indexes), register it
+for maintenance and schedule the tasks with the system scheduler (cron,
+systemd timers, launchd or the Windows task scheduler).
+
+Settings you already configured are left alone.
+
+Example:
+  githelper maintenance enable
+  githelper maintenance enable --scheduler systemd-timer
+  githelper maintenance enable --no-schedule   # Only configure and register`,
+	Args: cobra.NoArgs,
+	RunE: runMaintenanceEnable,
+}
+
+var maintenanceRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run the maintenance tasks now",
+	Long: `Run maintenance tasks now and report how long each took and how the
+object database changed.
+
+Tasks: ` + strings.Join(knownMaintenanceTasks, ", ") + `
+
+Example:
+  githelper maintenance run
+  githelper maintenance run --task commit-graph --task loose-objects`,
+	Args: cobra.NoArgs,
+	RunE: runMaintenanceRun,
+}
+
+var maintenanceDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Stop scheduled maintenance of the repository",
+	Long: `Unregister the repository, so scheduled maintenance skips it. The schedule
+itself stays for other registered repositories; remove it with
+'git maintenance stop'.`,
+	Args: cobra.NoArgs,
+	RunE: runMaintenanceDisable,
+}
+
+func init() {
+	rootCmd.AddCommand(maintenanceCmd)
+	maintenanceCmd.AddCommand(maintenanceEnableCmd)
+	maintenanceCmd.AddCommand(maintenanceRunCmd)
+	maintenanceCmd.AddCommand(maintenanceDisableCmd)
+
+	maintenanceEnableCmd.Flags().BoolVar(&maintenanceNoSched, "no-schedule", false, "configure and register without scheduling")
+	maintenanceEnableCmd.Flags().StringVar(&maintenanceScheduler, "scheduler", "", "scheduler to use: auto, crontab, systemd-timer, launchctl or schtasks")
+	maintenanceEnableCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be configured")
+	maintenanceRunCmd.Flags().StringSliceVarP(&maintenanceTaskList, "task", "t", nil, "task to run, may be repeated (default: "+strings.Join(maintenanceTasks, ", ")+")")
+}
+
+func runMaintenanceStatus(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	root, err := getRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	ui.Step("🔧 Maintenance status:")
+	if isMaintenanceRegistered(root) {
+		ui.Println("  ✅ Registered for scheduled maintenance")
+	} else {
+		ui.Println("  ❌ Not registered for scheduled maintenance")
+	}
+	for _, setting := range maintenanceConfig {
+		value := gitConfigValue(setting.Key)
+		if value == "" {
+			value = "(not set)"
+		}
+		ui.Printf("  %-24s %s\n", setting.Key, value)
+	}
+	ui.Printf("  %-24s %s\n", "commit-graph", presence(gitPathExists("objects/info/commit-graph") ||
+		gitPathExists("objects/info/commit-graphs/commit-graph-chain")))
+	ui.Printf("  %-24s %s\n", "multi-pack-index", presence(gitPathExists("objects/pack/multi-pack-index")))
+
+	size, err := git.RepoSize(commandCtx, "")
+	if err != nil {
+		return err
+	}
+	ui.Step("\n📦 Objects:")
+	printObjectCounts(size)
+
+	if !isMaintenanceRegistered(root) {
+		ui.Step("\n💡 Run 'githelper maintenance enable' to keep the repository fast")
+	}
+	return nil
+}
+
+func runMaintenanceEnable(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	for _, setting := range maintenanceConfig {
+		current := gitConfigValue(setting.Key)
+		if current != "" {
+			ui.Printf("  %s is already set to %s\n", setting.Key, current)
+			continue
+		}
+		ui.Stepf("⚙️  Setting %s to %s", setting.Key, setting.Value)
+		if dryRun {
+			continue
+		}
+		if err := gitCommand("config", setting.Key, setting.Value).Run(); err != nil {
+			return fmt.Errorf("failed to set %s: %w", setting.Key, err)
+		}
+	}
+
+	gitArgs := []string{"maintenance", "start"}
+	if maintenanceScheduler != "" {
+		gitArgs = append(gitArgs, "--scheduler="+maintenanceScheduler)
+	}
+	if maintenanceNoSched {
+		gitArgs = []string{"maintenance", "register"}
+	}
+	if dryRun {
+		ui.Stepf("🔍 Dry run - would run 'git %s'", strings.Join(gitArgs, " "))
+		return nil
+	}
+
+	output, err := gitCommand(gitArgs...).CombinedOutput()
+	if err != nil {
+		message := strings.TrimSpace(string(output))
+		if maintenanceNoSched {
+			return fmt.Errorf("failed to register for maintenance: %s", message)
+		}
+		return fmt.Errorf("failed to schedule maintenance: %s\nPick another --scheduler, or pass --no-schedule and run 'githelper maintenance run' yourself", message)
+	}
+
+	if maintenanceNoSched {
+		ui.Success("Registered for maintenance. Run 'githelper maintenance run' to run the tasks")
+	} else {
+		ui.Success("Maintenance enabled: tasks run hourly in the background")
+	}
+	return nil
+}
+
+func runMaintenanceRun(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	tasks := maintenanceTaskList
+	if len(tasks) == 0 {
+		tasks = maintenanceTasks
+	}
+	for _, task := range tasks {
+		if !slices.Contains(knownMaintenanceTasks, task) {
+			return fmt.Errorf("unknown task '%s', use one of: %s", task, strings.Join(knownMaintenanceTasks, ", "))
+		}
+	}
+
+	before, err := git.RepoSize(commandCtx, "")
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		ui.Printf("🔧 Running %s...", task)
+		start := time.Now()
+		taskCmd := gitCommand("maintenance", "run", "--task="+task)
+		taskCmd.Stderr = os.Stderr
+		if err := taskCmd.Run(); err != nil {
+			ui.Println()
+			return fmt.Errorf("maintenance task %s failed: %w", task, err)
+		}
+		ui.Printf(" done in %s\n", time.Since(start).Round(time.Millisecond))
+	}
+
+	after, err := git.RepoSize(commandCtx, "")
+	if err != nil {
+		return err
+	}
+	ui.Step("\n📦 Objects:")
+	ui.Printf("  Loose objects: %d → %d (%s → %s)\n", before.LooseObjects, after.LooseObjects,
+		formatSize(before.LooseSize), formatSize(after.LooseSize))
+	ui.Printf("  Packs:         %d → %d (%s → %s)\n", before.Packs, after.Packs,
+		formatSize(before.PackSize), formatSize(after.PackSize))
+	ui.Success("Maintenance complete")
+	return nil
+}
+
+func runMaintenanceDisable(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	root, err := getRepoRoot()
+	if err != nil {
+		return err
+	}
+	if !isMaintenanceRegistered(root) {
+		ui.Info("The repository isn't registered for maintenance")
+		return nil
+	}
+	if output, err := gitCommand("maintenance", "unregister").CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to unregister: %s", strings.TrimSpace(string(output)))
+	}
+	ui.Success("Maintenance disabled for this repository")
+	return nil
+}
+
+// isMaintenanceRegistered reports whether the repository is listed in the
+// global maintenance.repo setting
+func isMaintenanceRegistered(root string) bool {
+	output, _ := gitCommand("config", "--global", "--get-all", "maintenance.repo").Output()
+	for _, repo := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if repo == root {
+			return true
+		}
+	}
+	return false
+}
+
+func gitConfigValue(key string) string {
+	output, _ := gitCommand("config", key).Output()
+	return strings.TrimSpace(string(output))
+}
+
+// gitPathExists reports whether a path inside the git directory exists
+func gitPathExists(path string) bool {
+	output, err := gitCommand("rev-parse", "--git-path", path).Output()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(strings.TrimSpace(string(output)))
+	return err == nil
+}
+
+func presence(present bool) string {
+	if present {
+		return "present"
+	}
+	return "missing"
+}
+
+func printObjectCounts(size *git.Size) {
+	ui.Printf("  Loose objects: %d (%s)\n", size.LooseObjects, formatSize(size.LooseSize))
+	ui.Printf("  Packed:        %d in %d pack(s) (%s)\n", size.PackedObjects, size.Packs, formatSize(size.PackSize))
+	if size.GarbageFiles > 0 {
+		ui.Printf("  Garbage files: %d (%s)\n", size.GarbageFiles, formatSize(size.GarbageSize))
+	}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceEnableWithoutSchedule(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "initial")
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+	root, err := getRepoRoot()
+	require.NoError(t, err)
+
+	runGit(t, tmpDir, "config", "core.commitGraph", "false")
+	maintenanceNoSched = true
+	defer func() { maintenanceNoSched = false }()
+	require.NoError(t, runMaintenanceEnable(nil, nil))
+
+	assert.Equal(t, "incremental", gitConfigValue("maintenance.strategy"))
+	assert.Equal(t, "true", gitConfigValue("fetch.writeCommitGraph"))
+	// Existing settings are kept
+	assert.Equal(t, "false", gitConfigValue("core.commitGraph"))
+	assert.True(t, isMaintenanceRegistered(root))
+
+	require.NoError(t, runMaintenanceDisable(nil, nil))
+	assert.False(t, isMaintenanceRegistered(root))
+}
@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/mirror"
+	"github.com/EndlessUphill/git-helper/internal/notify"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	mirrorFrom     string
+	mirrorTo       string
+	mirrorInterval time.Duration
+	mirrorListen   string
+	mirrorSecret   string
+	mirrorLogFile  string
+)
+
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Keep a destination repository in sync with a source",
+	Long: `Continuously mirror every branch and tag of one repository to another,
+e.g. from GitHub to a backup server or from a vendor's repository into your
+organization.
+
+Each sync fetches the source with --prune into a local bare mirror in
+~/.githelper/mirrors and pushes it to the destination with --mirror, so
+branches deleted at the source are deleted at the destination too. The
+destination must not be written to by anyone else.
+
+'githelper mirror' runs until interrupted, syncing on startup and then every
+--interval. With --listen it also accepts webhooks (e.g. a GitHub push
+webhook pointed at http://host:port/) that trigger an immediate sync; set
+--webhook-secret (or mirror.webhook_secret) to verify GitHub signatures.
+GET /status returns the state of the mirror as JSON.
+
+Use 'githelper mirror run-once' from cron or CI instead of a long-running
+process. Credentials come from your git credential helpers or SSH keys.
+
+Example:
+  githelper mirror --from acme/widget --to git@backup.example.com:widget.git
+  githelper mirror --from acme/widget --to ... --interval 5m --listen :8080
+  githelper mirror run-once --from acme/widget --to ...
+  githelper mirror status`,
+	Args: cobra.NoArgs,
+	RunE: runMirror,
+}
+
+var mirrorRunOnceCmd = &cobra.Command{
+	Use:   "run-once",
+	Short: "Sync the mirror once and exit (for cron and CI)",
+	Args:  cobra.NoArgs,
+	RunE:  runMirrorOnce,
+}
+
+var mirrorStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the last sync of every mirror",
+	Args:  cobra.NoArgs,
+	RunE:  runMirrorStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(mirrorCmd)
+	mirrorCmd.AddCommand(mirrorRunOnceCmd)
+	mirrorCmd.AddCommand(mirrorStatusCmd)
+
+	flags := mirrorCmd.PersistentFlags()
+	flags.StringVar(&mirrorFrom, "from", "", "source repository (owner/repo or URL)")
+	flags.StringVar(&mirrorTo, "to", "", "destination repository (owner/repo or URL)")
+	flags.StringVar(&mirrorLogFile, "log-file", "", "also append the log to this file")
+	mirrorCmd.Flags().DurationVar(&mirrorInterval, "interval", 15*time.Minute, "time between syncs")
+	mirrorCmd.Flags().StringVar(&mirrorListen, "listen", "", "address to accept webhooks on, e.g. :8080")
+	mirrorCmd.Flags().StringVar(&mirrorSecret, "webhook-secret", "", "secret to verify webhook signatures (default is mirror.webhook_secret)")
+}
+
+// mirrorsDir returns where the local mirrors and their state are kept
+func mirrorsDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".githelper", "mirrors"), nil
+}
+
+func newMirror() (*mirror.Mirror, error) {
+	if mirrorFrom == "" || mirrorTo == "" {
+		return nil, fmt.Errorf("both --from and --to are required")
+	}
+	from, err := normalizeRepoURL(mirrorFrom)
+	if err != nil {
+		return nil, err
+	}
+	to, err := normalizeRepoURL(mirrorTo)
+	if err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, fmt.Errorf("source and destination are the same repository")
+	}
+
+	dir, err := mirrorsDir()
+	if err != nil {
+		return nil, err
+	}
+	return mirror.New(from, to, dir), nil
+}
+
+func newMirrorLogger() (*log.Logger, func(), error) {
+	if mirrorLogFile == "" {
+		return log.New(ui.Out(), "", log.LstdFlags), func() {}, nil
+	}
+	file, err := os.OpenFile(mirrorLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	return log.New(io.MultiWriter(ui.Out(), file), "", log.LstdFlags), func() { file.Close() }, nil
+}
+
+// syncMirror runs one sync and logs its outcome. A long-running mirror
+// notifies the webhooks when syncs start failing and when they work again;
+// run-once reports every sync when the command ends.
+func syncMirror(ctx context.Context, m *mirror.Mirror, logger *log.Logger, reason string) error {
+	logger.Printf("🔄 Syncing %s -> %s (%s)", m.From, m.To, reason)
+	before, _ := m.State()
+	if notifyRun != nil {
+		notifyRun.summary.Repo = m.To
+	}
+	summary := notify.Summary{Operation: "mirror sync", Command: "githelper mirror", Repo: m.To}
+	if err := m.Sync(ctx); err != nil {
+		logger.Printf("❌ Sync failed: %v", err)
+		if reason != "run-once" && before.LastError == "" {
+			summary.Message = fmt.Sprintf("Syncing %s -> %s failed: %v", m.From, m.To, err)
+			sendNotification(summary)
+		}
+		return err
+	}
+	state, _ := m.State()
+	logger.Printf("✅ Sync completed in %s", state.Duration)
+	message := fmt.Sprintf("Synced %s -> %s", m.From, m.To)
+	notifyResult(message)
+	if reason != "run-once" && before.LastError != "" {
+		summary.Success, summary.Message, summary.Duration = true, message+" again", state.Duration
+		sendNotification(summary)
+	}
+	return nil
+}
+
+func runMirrorOnce(cmd *cobra.Command, args []string) error {
+	m, err := newMirror()
+	if err != nil {
+		return err
+	}
+	logger, closeLog, err := newMirrorLogger()
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return syncMirror(ctx, m, logger, "run-once")
+}
+
+func runMirror(cmd *cobra.Command, args []string) error {
+	m, err := newMirror()
+	if err != nil {
+		return err
+	}
+	if mirrorInterval < time.Minute {
+		return fmt.Errorf("--interval must be at least 1m")
+	}
+	logger, closeLog, err := newMirrorLogger()
+	if err != nil {
+		return err
+	}
+	defer closeLog()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Webhooks queue at most one extra sync while one is running
+	trigger := make(chan string, 1)
+
+	if mirrorListen != "" {
+		secret := mirrorSecret
+		if secret == "" {
+			secret = viper.GetString("mirror.webhook_secret")
+		}
+		server := &http.Server{
+			Addr:              mirrorListen,
+			Handler:           mirrorWebhookHandler(m, secret, trigger, logger),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Printf("❌ Webhook server stopped: %v", err)
+				stop()
+			}
+		}()
+		defer server.Shutdown(context.Background())
+		logger.Printf("👂 Listening for webhooks on %s", mirrorListen)
+	}
+
+	logger.Printf("🪞 Mirroring %s -> %s every %s (Ctrl+C to stop)", m.From, m.To, mirrorInterval)
+	syncMirror(ctx, m, logger, "startup")
+
+	ticker := time.NewTicker(mirrorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Println("👋 Stopping mirror")
+			return nil
+		case <-ticker.C:
+			syncMirror(ctx, m, logger, "interval")
+		case reason := <-trigger:
+			syncMirror(ctx, m, logger, reason)
+		}
+	}
+}
+
+// mirrorWebhookHandler triggers a sync on POST and reports the state on
+// GET /status
+func mirrorWebhookHandler(m *mirror.Mirror, secret string, trigger chan<- string, logger *log.Logger) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		state, err := m.State()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "use POST to trigger a sync", http.StatusMethodNotAllowed)
+			return
+		}
+		payload, err := io.ReadAll(io.LimitReader(r.Body, 10<<20))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		if secret != "" && !mirror.VerifySignature(secret, payload, r.Header.Get("X-Hub-Signature-256")) {
+			logger.Printf("⚠️  Rejected webhook from %s: invalid signature", r.RemoteAddr)
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-GitHub-Event") == "ping" {
+			fmt.Fprintln(w, "pong")
+			return
+		}
+
+		select {
+		case trigger <- "webhook":
+		default: // A sync is already queued
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintln(w, "sync queued")
+	})
+	return mux
+}
+
+func runMirrorStatus(cmd *cobra.Command, args []string) error {
+	dir, err := mirrorsDir()
+	if err != nil {
+		return err
+	}
+	states, err := mirror.States(dir)
+	if err != nil {
+		return err
+	}
+	if len(states) == 0 {
+		ui.Println("No mirrors have been synced yet")
+		return nil
+	}
+
+	for _, state := range states {
+		status := "✅"
+		if state.LastError != "" {
+			status = "❌"
+		}
+		ui.Printf("%s %s -> %s\n", status, state.From, state.To)
+		ui.Printf("   last sync:    %s (%s)\n", state.LastSync.Format("2006-01-02 15:04:05"), state.Duration)
+		if !state.LastSuccess.IsZero() {
+			ui.Printf("   last success: %s\n", state.LastSuccess.Format("2006-01-02 15:04:05"))
+		}
+		ui.Printf("   runs:         %d (%d failed)\n", state.Runs, state.Failures)
+		if state.LastError != "" {
+			ui.Printf("   error:        %s\n", state.LastError)
+		}
+	}
+	return nil
+}
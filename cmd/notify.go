@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/history"
+	"github.com/EndlessUphill/git-helper/internal/notify"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// notifyOperations are the long-running commands notify.webhooks and
+// --notify tell about, with the name of their operation
+var notifyOperations = map[string]string{
+	"clone":           "clone",
+	"bisect":          "bisect run",
+	"copy":            "repository copy",
+	"repo apply":      "batch settings update",
+	"repo archive":    "batch archive",
+	"repo unarchive":  "batch unarchive",
+	"repo transfer":   "batch transfer",
+	"mirror run-once": "mirror sync",
+	"clean":           "history rewrite",
+	"purge":           "history rewrite",
+	"rewrite-author":  "history rewrite",
+	"checks wait":     "checks wait",
+}
+
+// notifyTimeout bounds each webhook call, so a slow chat service doesn't
+// hold the terminal
+const notifyTimeout = 10 * time.Second
+
+// notifyRecording is a command whose outcome is still to be sent
+type notifyRecording struct {
+	summary notify.Summary
+	start   time.Time
+	// done is set by notifyResult: commands that succeed without doing
+	// anything, e.g. when the user cancels them, aren't worth a message
+	done bool
+}
+
+var notifyRun *notifyRecording
+
+// notifyDesktop is the --notify flag
+var notifyDesktop bool
+
+func init() {
+	for _, c := range []*cobra.Command{cloneCmd, copyCmd, checksWaitCmd, mirrorRunOnceCmd, cleanCmd, purgeCmd, rewriteAuthorCmd,
+		repoApplyCmd, repoArchiveCmd, repoUnarchiveCmd, repoTransferCmd} {
+		c.Flags().BoolVar(&notifyDesktop, "notify", false, "show a desktop notification when it finishes")
+	}
+	bisectCmd.Flags().BoolVar(&notifyDesktop, "notify", false, "with --run, show a desktop notification when the first bad commit is found")
+}
+
+// startNotify starts timing the command when it's one of notifyOperations
+// and someone is to be told when it ends: webhooks are configured, or a
+// desktop notification is asked for
+func startNotify(cmd *cobra.Command) {
+	notifyRun = nil
+	command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	operation, ok := notifyOperations[command]
+	if !ok || !(notifyDesktop || viper.GetBool("notify.desktop") || viper.IsSet("notify.webhooks")) {
+		return
+	}
+	if flag := cmd.Flags().Lookup("dry-run"); flag != nil && flag.Value.String() == "true" {
+		return
+	}
+	notifyRun = &notifyRecording{
+		summary: notify.Summary{
+			Operation: operation,
+			Command:   "githelper " + strings.Join(history.Redact(os.Args[1:]), " "),
+			Repo:      notifyRepo(),
+		},
+		start: time.Now(),
+	}
+}
+
+// notifyResult records what the command did, for the summary sent when it
+// succeeds
+func notifyResult(message string, details ...string) {
+	if notifyRun == nil {
+		return
+	}
+	notifyRun.summary.Message = message
+	notifyRun.summary.Details = details
+	notifyRun.done = true
+}
+
+// notifyRepo names the repository the command runs in: owner/name of
+// origin, or the directory of the clone
+func notifyRepo() string {
+	if originURL, err := getOriginURL(); err == nil {
+		if _, repoPath, err := github.ParseRepoURL(originURL); err == nil {
+			return repoPath
+		}
+	}
+	if root, err := getRepoRoot(); err == nil {
+		return filepath.Base(root)
+	}
+	return ""
+}
+
+// notifyWebhooks returns the webhooks of notify.webhooks, whose entries are
+// URLs, as 'githelper config set' writes them, or url/type/only_failures
+// maps
+func notifyWebhooks() ([]notify.Webhook, error) {
+	var items []interface{}
+	switch raw := viper.Get("notify.webhooks").(type) {
+	case nil:
+	case []interface{}:
+		items = raw
+	case []string:
+		for _, item := range raw {
+			items = append(items, item)
+		}
+	default:
+		return nil, fmt.Errorf("invalid notify.webhooks configuration: expected a list")
+	}
+
+	var webhooks []notify.Webhook
+	for _, item := range items {
+		var webhook notify.Webhook
+		if url, ok := item.(string); ok {
+			webhook.URL = url
+		} else {
+			v := viper.New()
+			v.Set("webhook", item)
+			if err := v.UnmarshalKey("webhook", &webhook); err != nil {
+				return nil, fmt.Errorf("invalid notify.webhooks entry: %w", err)
+			}
+		}
+		if webhook.URL == "" {
+			return nil, fmt.Errorf("invalid notify.webhooks entry: url is required")
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+// finishNotify tells about the outcome of the command that ran, when it
+// failed or recorded a result: with a desktop notification for --notify, and
+// to every webhook. Operations quicker than notify.min_duration are only
+// reported when --notify asks for it. The command is over, so failing to
+// notify only warns.
+func finishNotify(exitCode int, err error) {
+	if notifyRun == nil {
+		return
+	}
+	summary, done := notifyRun.summary, notifyRun.done
+	summary.Duration = time.Since(notifyRun.start)
+	notifyRun = nil
+	if err == nil && !done {
+		return
+	}
+	summary.Success = err == nil
+	if err != nil {
+		summary.Message = fmt.Sprintf("%v (exit code %d)", err, exitCode)
+	}
+
+	long := summary.Duration >= viper.GetDuration("notify.min_duration")
+	if notifyDesktop || (long && viper.GetBool("notify.desktop")) {
+		desktopNotification(summary)
+	}
+	if long && viper.IsSet("notify.webhooks") {
+		sendNotification(summary)
+	}
+}
+
+// desktopNotification rings the bell and shows a native notification, where
+// the platform has a tool for it
+func desktopNotification(summary notify.Summary) {
+	notify.Bell(ui.Status())
+	var lines []string
+	for _, line := range []string{summary.Repo, summary.Message} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	lines = append(lines, "Took "+summary.Duration.Round(time.Second).String())
+	if err := notify.Desktop(summary.Title(), strings.Join(lines, "\n")); err != nil && !errors.Is(err, notify.ErrUnsupported) {
+		ui.Warnf("%v", err)
+	}
+}
+
+// sendNotification posts a summary to every webhook of notify.webhooks
+func sendNotification(summary notify.Summary) {
+	webhooks, err := notifyWebhooks()
+	if err != nil {
+		ui.Warnf("%v", err)
+		return
+	}
+	client := &http.Client{Timeout: notifyTimeout}
+	for _, webhook := range webhooks {
+		if err := webhook.Send(context.Background(), client, summary); err != nil {
+			ui.Warnf("Failed to send the notification: %v", err)
+		}
+	}
+}
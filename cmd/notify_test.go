@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyWebhooks(t *testing.T) {
+	defer viper.Reset()
+	var mu sync.Mutex
+	received := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], string(body))
+		mu.Unlock()
+	}))
+	defer server.Close()
+	viper.Set("notify.webhooks", []interface{}{
+		server.URL + "/all",
+		map[string]interface{}{"url": server.URL + "/failures", "only_failures": true},
+	})
+
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	r.WithRemote()
+	dest := filepath.Join(t.TempDir(), "backup.git")
+	require.NoError(t, exec.Command("git", "init", "--quiet", "--bare", dest).Run())
+
+	_, _, err := execute(t, "mirror", "run-once", "--from", "file://"+r.Remote, "--to", "file://"+dest)
+	require.NoError(t, err)
+	finishNotify(0, nil)
+	require.Len(t, received["/all"], 1)
+	assert.Contains(t, received["/all"][0], `"operation":"mirror sync"`)
+	assert.Contains(t, received["/all"][0], `"success":true`)
+	assert.Contains(t, received["/all"][0], `"message":"Synced file://`)
+	assert.Empty(t, received["/failures"])
+
+	_, _, err = execute(t, "mirror", "run-once", "--from", "file://"+r.Remote, "--to", "file://"+filepath.Join(t.TempDir(), "missing.git"))
+	require.Error(t, err)
+	finishNotify(1, err)
+	require.Len(t, received["/all"], 2)
+	require.Len(t, received["/failures"], 1)
+	assert.Contains(t, received["/failures"][0], `"success":false`)
+
+	// Commands that aren't long operations, and quick ones, aren't reported
+	_, _, err = execute(t, "branch", "new", "Add", "search")
+	require.NoError(t, err)
+	finishNotify(0, nil)
+	viper.Set("notify.min_duration", "1h")
+	_, _, err = execute(t, "mirror", "run-once", "--from", "file://"+r.Remote, "--to", "file://"+dest)
+	require.NoError(t, err)
+	finishNotify(0, nil)
+	assert.Len(t, received["/all"], 2)
+}
+
+func TestNotifyWebhooksConfig(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("notify.webhooks", []interface{}{map[string]interface{}{"type": "slack"}})
+	_, err := notifyWebhooks()
+	assert.EqualError(t, err, "invalid notify.webhooks entry: url is required")
+
+	viper.Set("notify.webhooks", []string{"https://hooks.slack.com/services/T0/B0/x"})
+	webhooks, err := notifyWebhooks()
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, "slack", webhooks[0].Kind())
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var openPrint bool
+
+var openCmd = &cobra.Command{
+	Use:   "open [path[:line] | commit [rev] | pr [number] | issue [number]]",
+	Short: "Open the repository, a file, a commit, a pull request or an issue on GitHub",
+	Long: `Open the GitHub page of what you are working on, on github.com or the
+GitHub Enterprise host of origin.
+
+Without arguments the current branch is opened. A path opens the file or
+directory on the current branch, at a line or a range of lines when it ends
+with :line or :start-end. Branches that aren't pushed yet link to the
+default branch instead.
+
+'commit' opens a commit (HEAD by default), 'pr' the pull request of the
+current branch, or the page to create one, and 'issue' the issue the branch
+was started from with 'githelper start', or the list of issues.
+
+Use --print to get the URL instead, e.g. for an editor integration.
+
+Example:
+  githelper open                       # The current branch
+  githelper open cmd/root.go:42        # A file at a line
+  githelper open README.md:10-20       # A range of lines
+  githelper open commit HEAD~2
+  githelper open pr                    # The pull request of this branch
+  githelper open issue 12
+  githelper open --print cmd/root.go   # Only print the URL`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runOpen,
+}
+
+func init() {
+	rootCmd.AddCommand(openCmd)
+	openCmd.Flags().BoolVarP(&openPrint, "print", "p", false, "print the URL instead of opening it")
+}
+
+func runOpen(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	target, err := openTargetURL(args)
+	if err != nil {
+		return err
+	}
+	if openPrint {
+		ui.Println(target)
+		return nil
+	}
+	ui.Stepf("🌐 Opening %s", target)
+	return openBrowser(target)
+}
+
+// openTargetURL returns the GitHub URL of what the arguments of 'open' name
+func openTargetURL(args []string) (string, error) {
+	web, err := originWebURL()
+	if err != nil {
+		return "", err
+	}
+
+	kind := ""
+	if len(args) > 0 {
+		kind = args[0]
+	}
+	var rest []string
+	if len(args) > 1 {
+		rest = args[1:]
+	}
+
+	switch kind {
+	case "":
+		ref, err := openRef()
+		if err != nil {
+			return "", err
+		}
+		return web + "/tree/" + escapeURLPath(ref), nil
+	case "commit":
+		rev := "HEAD"
+		if len(rest) > 0 {
+			rev = rest[0]
+		}
+		return openCommitURL(web, rev)
+	case "pr":
+		return openPullRequestURL(web, rest)
+	case "issue":
+		return openIssueURL(web, rest)
+	}
+	if len(rest) > 0 {
+		return "", fmt.Errorf("too many arguments: only commit, pr and issue take a second one")
+	}
+
+	file, lines := splitLineSuffix(kind)
+	info, statErr := os.Stat(file)
+	if statErr != nil {
+		// Not a file: maybe a commit, such as a hash copied from a log
+		if lines == "" && gitCommand("rev-parse", "--verify", "-q", kind+"^{commit}").Run() == nil {
+			return openCommitURL(web, kind)
+		}
+		return "", fmt.Errorf("'%s' is not a file, a commit, 'pr' or 'issue'", kind)
+	}
+	repoPath, err := repoRelativePath(file)
+	if err != nil {
+		return "", err
+	}
+	ref, err := openRef()
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return web + "/tree/" + escapeURLPath(path.Join(ref, repoPath)), nil
+	}
+	return fileURL(web, ref, repoPath, lines), nil
+}
+
+// originWebURL returns the browser URL of the repository of origin
+func originWebURL() (string, error) {
+	originURL, err := getOriginURL()
+	if err != nil {
+		return "", fmt.Errorf("no origin remote found")
+	}
+	hostname, repoPath, err := github.ParseRepoURL(originURL)
+	if err != nil {
+		return "", fmt.Errorf("origin is not a GitHub repository: %w", err)
+	}
+	return github.Host{Name: hostname}.WebURL(repoPath), nil
+}
+
+// openRef returns the branch links point to: the current branch when origin
+// has it, the default branch otherwise
+func openRef() (string, error) {
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return "", err
+	}
+	if branch != "" && branch != "HEAD" && gitCommand("rev-parse", "--verify", "-q", "refs/remotes/origin/"+branch).Run() == nil {
+		return branch, nil
+	}
+	base, err := defaultBranch()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the default branch: %w", err)
+	}
+	if branch == "" || branch == "HEAD" {
+		ui.Warnf("HEAD is detached, linking to %s", base)
+	} else {
+		ui.Warnf("'%s' isn't on origin yet, linking to %s", branch, base)
+	}
+	return base, nil
+}
+
+// lineSuffix is the :line or :start-end at the end of a path
+var lineSuffix = regexp.MustCompile(`^(.+):(\d+)(?:-(\d+))?$`)
+
+// splitLineSuffix splits path:line or path:start-end into the path and the
+// lines, as "42" or "10-20"
+func splitLineSuffix(arg string) (string, string) {
+	match := lineSuffix.FindStringSubmatch(arg)
+	if match == nil {
+		return arg, ""
+	}
+	if match[3] == "" {
+		return match[1], match[2]
+	}
+	return match[1], match[2] + "-" + match[3]
+}
+
+// fileURL returns the URL of a file at ref, at lines when given. Markdown
+// is rendered on GitHub, so lines need the plain view.
+func fileURL(web, ref, file, lines string) string {
+	u := web + "/blob/" + escapeURLPath(path.Join(ref, file))
+	if lines == "" {
+		return u
+	}
+	ext := strings.ToLower(path.Ext(file))
+	if ext == ".md" || ext == ".markdown" {
+		u += "?plain=1"
+	}
+	start, end, found := strings.Cut(lines, "-")
+	if !found {
+		return u + "#L" + start
+	}
+	return u + "#L" + start + "-L" + end
+}
+
+// repoRelativePath returns the path of file from the top of the repository,
+// with forward slashes
+func repoRelativePath(file string) (string, error) {
+	if !filepath.IsAbs(file) {
+		prefix, err := gitOutput("rev-parse", "--show-prefix")
+		if err != nil {
+			return "", fmt.Errorf("failed to find the repository root: %w", err)
+		}
+		rel := path.Clean(strings.TrimSpace(string(prefix)) + filepath.ToSlash(file))
+		if rel == ".." || strings.HasPrefix(rel, "../") {
+			return "", fmt.Errorf("'%s' is outside the repository", file)
+		}
+		if rel == "." {
+			return "", nil
+		}
+		return rel, nil
+	}
+
+	top, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("failed to find the repository root: %w", err)
+	}
+	// The root is reported with symlinks resolved, as in /private/var on macOS
+	if resolved, err := filepath.EvalSymlinks(file); err == nil {
+		file = resolved
+	}
+	rel, err := filepath.Rel(strings.TrimSpace(string(top)), file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("'%s' is outside the repository", file)
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// escapeURLPath escapes each segment of a slash-separated path
+func escapeURLPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// openCommitURL returns the URL of a commit
+func openCommitURL(web, rev string) (string, error) {
+	output, err := gitOutput("rev-parse", "--verify", "-q", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("'%s' is not a commit", rev)
+	}
+	sha := strings.TrimSpace(string(output))
+	warnUnpushed(sha)
+	return web + "/commit/" + sha, nil
+}
+
+// warnUnpushed warns when no remote branch contains a commit, so its links
+// don't work yet
+func warnUnpushed(sha string) {
+	if pushed, _ := gitOutput("branch", "-r", "--contains", sha); len(pushed) == 0 {
+		ui.Warnf("%s isn't pushed yet, GitHub won't find it until it is", shortSHA(sha))
+	}
+}
+
+// openPullRequestURL returns the URL of a pull request, the one of the
+// current branch by default, or of the page to create it
+func openPullRequestURL(web string, args []string) (string, error) {
+	if len(args) > 0 {
+		number, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+		if err != nil || number <= 0 {
+			return "", fmt.Errorf("invalid pull request number '%s'", args[0])
+		}
+		return fmt.Sprintf("%s/pull/%d", web, number), nil
+	}
+
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return "", err
+	}
+	if branch == "" || branch == "HEAD" {
+		return "", fmt.Errorf("HEAD is detached. Give the pull request number")
+	}
+	client, owner, name, err := originClient()
+	if err != nil {
+		return "", err
+	}
+	number, err := client.FindPullRequest(context.Background(), owner, name, owner, branch)
+	if errors.Is(err, github.ErrNoPullRequest) {
+		ui.Infof("No open pull request for '%s', opening the page to create one", branch)
+		return web + "/compare/" + escapeURLPath(branch) + "?expand=1", nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to find pull request: %w", err)
+	}
+	return fmt.Sprintf("%s/pull/%d", web, number), nil
+}
+
+// openIssueURL returns the URL of an issue, the one the current branch was
+// started from by default, or of the list of issues
+func openIssueURL(web string, args []string) (string, error) {
+	if len(args) > 0 {
+		number, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+		if err != nil || number <= 0 {
+			return "", fmt.Errorf("invalid issue number '%s'", args[0])
+		}
+		return fmt.Sprintf("%s/issues/%d", web, number), nil
+	}
+
+	if branch, err := getCurrentBranch(); err == nil {
+		if number, issueURL := branchIssue(branch); issueURL != "" {
+			return issueURL, nil
+		} else if number > 0 {
+			return fmt.Sprintf("%s/issues/%d", web, number), nil
+		}
+	}
+	return web + "/issues", nil
+}
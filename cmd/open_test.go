@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitLineSuffix(t *testing.T) {
+	for arg, want := range map[string][2]string{
+		"cmd/root.go":      {"cmd/root.go", ""},
+		"cmd/root.go:42":   {"cmd/root.go", "42"},
+		"README.md:10-20":  {"README.md", "10-20"},
+		"notes:draft.txt":  {"notes:draft.txt", ""},
+		`C:\src\main.go:7`: {`C:\src\main.go`, "7"},
+	} {
+		file, lines := splitLineSuffix(arg)
+		assert.Equal(t, want, [2]string{file, lines}, arg)
+	}
+}
+
+func TestFileURL(t *testing.T) {
+	web := "https://github.example.com/acme/widgets"
+	assert.Equal(t, web+"/blob/main/cmd/root.go", fileURL(web, "main", "cmd/root.go", ""))
+	assert.Equal(t, web+"/blob/feat/x/cmd/root.go#L42", fileURL(web, "feat/x", "cmd/root.go", "42"))
+	assert.Equal(t, web+"/blob/main/README.md?plain=1#L10-L20", fileURL(web, "main", "README.md", "10-20"))
+	assert.Equal(t, web+"/blob/main/docs/my%20notes.txt", fileURL(web, "main", "docs/my notes.txt", ""))
+}
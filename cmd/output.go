@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/EndlessUphill/git-helper/internal/plain"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/viper"
+)
+
+var (
+	// plainOutput is the --plain flag
+	plainOutput bool
+	// quietOutput, noColor and machineOutput are --quiet, --no-color and
+	// --machine
+	quietOutput   bool
+	noColor       bool
+	machineOutput bool
+)
+
+// startOutput configures the printer for the command about to run
+func startOutput() {
+	err := ui.Configure(ui.Options{
+		Theme:   viper.GetString("theme"),
+		NoColor: noColor || viper.GetBool("no_color"),
+		Quiet:   quietOutput,
+		Machine: machineOutput,
+	})
+	if err != nil {
+		ui.Warnf("%v; using the default theme", err)
+	}
+	startPlainOutput()
+}
+
+// usePlainOutput reports whether output should be plain text: with --plain,
+// the no_emoji setting, or on a dumb terminal
+func usePlainOutput() bool {
+	return plainOutput || viper.GetBool("no_emoji") || os.Getenv("TERM") == "dumb"
+}
+
+// startPlainOutput filters everything the command and the programs it runs
+// print, so commands need no plain variant of their messages
+func startPlainOutput() {
+	if !usePlainOutput() {
+		return
+	}
+	if err := plain.Redirect(); err != nil {
+		ui.Warnf("Failed to switch to plain output: %v", err)
+	}
+}
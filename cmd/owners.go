@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/codeowners"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ownersBase      string
+	ownersDepth     int
+	ownersMinShare  float64
+	ownersMaxOwners int
+	ownersAll       bool
+)
+
+var ownersCmd = &cobra.Command{
+	Use:   "owners <path>...",
+	Short: "Show who owns files according to CODEOWNERS",
+	Long: `Show the owners of files and directories according to the CODEOWNERS file
+(.github/CODEOWNERS, CODEOWNERS or docs/CODEOWNERS), and the line that
+assigns them.
+
+Example:
+  githelper owners cmd/root.go
+  githelper owners internal/ README.md
+  githelper owners check              # Every changed file has an owner
+  githelper owners suggest            # Entries from blame statistics`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runOwners,
+}
+
+var ownersCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check that every file changed on the branch has an owner",
+	Long: `Check the CODEOWNERS file for lines GitHub rejects, and that every file
+changed on the current branch since it forked from the base branch has an
+owner. Fails when a file is unowned, so it can run in CI.
+
+Example:
+  githelper owners check
+  githelper owners check --base develop`,
+	Args: cobra.NoArgs,
+	RunE: runOwnersCheck,
+}
+
+var ownersSuggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggest CODEOWNERS entries from blame statistics",
+	Long: `Suggest CODEOWNERS entries for directories without owners, naming the
+authors who wrote the largest share of their current lines.
+
+Authors with a GitHub noreply email are written as @login, others by email
+address, which GitHub accepts for users with that verified email. Review the
+suggestions before adding them to CODEOWNERS.
+
+Example:
+  githelper owners suggest
+  githelper owners suggest --depth 2 --min-share 30
+  githelper owners suggest --all >> .github/CODEOWNERS`,
+	Args: cobra.NoArgs,
+	RunE: runOwnersSuggest,
+}
+
+func init() {
+	rootCmd.AddCommand(ownersCmd)
+	ownersCmd.AddCommand(ownersCheckCmd)
+	ownersCmd.AddCommand(ownersSuggestCmd)
+
+	ownersCheckCmd.Flags().StringVar(&ownersBase, "base", "", "branch to compare with (default: the default branch)")
+
+	ownersSuggestCmd.Flags().IntVar(&ownersDepth, "depth", 1, "directory depth of the suggested entries")
+	ownersSuggestCmd.Flags().Float64Var(&ownersMinShare, "min-share", 20, "minimum percentage of lines an author needs to be suggested")
+	ownersSuggestCmd.Flags().IntVar(&ownersMaxOwners, "max-owners", 2, "maximum number of owners per entry")
+	ownersSuggestCmd.Flags().BoolVar(&ownersAll, "all", false, "also suggest owners for directories that already have some")
+}
+
+// loadCodeowners returns the repository root and its parsed CODEOWNERS
+func loadCodeowners() (string, *codeowners.File, error) {
+	if err := checkGitRepo(); err != nil {
+		return "", nil, err
+	}
+	root, err := getRepoRoot()
+	if err != nil {
+		return "", nil, err
+	}
+	file, err := codeowners.Load(root)
+	if errors.Is(err, codeowners.ErrNotFound) {
+		return root, nil, fmt.Errorf("%w (looked in %s)", err, strings.Join(codeowners.Locations, ", "))
+	}
+	return root, file, err
+}
+
+func runOwners(cmd *cobra.Command, args []string) error {
+	root, file, err := loadCodeowners()
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range args {
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("%s is outside the repository", arg)
+		}
+
+		rule := file.Match(filepath.ToSlash(rel))
+		switch {
+		case rule == nil:
+			ui.Stepf("❓ %s: no owners", arg)
+		case len(rule.Owners) == 0:
+			ui.Stepf("❓ %s: no owners (%s:%d %s)", arg, file.Path, rule.Line, rule.Pattern)
+		default:
+			ui.Stepf("👤 %s: %s (%s:%d %s)", arg, strings.Join(rule.Owners, " "), file.Path, rule.Line, rule.Pattern)
+		}
+	}
+	return nil
+}
+
+func runOwnersCheck(cmd *cobra.Command, args []string) error {
+	_, file, err := loadCodeowners()
+	if err != nil {
+		return err
+	}
+
+	base := ownersBase
+	if base == "" {
+		if base, err = defaultBranch(); err != nil {
+			return err
+		}
+	}
+	mergeBase, err := gitCommand("merge-base", base, "HEAD").Output()
+	if err != nil {
+		// Fall back to the remote branch when there is no local one
+		if mergeBase, err = gitCommand("merge-base", "origin/"+base, "HEAD").Output(); err != nil {
+			return fmt.Errorf("failed to find where the branch forked from %s", base)
+		}
+	}
+	output, err := gitCommand("diff", "--name-only", "--diff-filter=d", strings.TrimSpace(string(mergeBase)), "HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list changed files: %w", err)
+	}
+
+	problems := 0
+	if len(file.Errors) > 0 {
+		ui.Errorf("%s has lines GitHub ignores:", file.Path)
+		for _, parseErr := range file.Errors {
+			ui.Printf("  %s\n", parseErr.Error())
+		}
+		problems += len(file.Errors)
+	}
+
+	var changed, unowned []string
+	for _, path := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if path == "" {
+			continue
+		}
+		changed = append(changed, path)
+		if len(file.Owners(path)) == 0 {
+			unowned = append(unowned, path)
+		}
+	}
+	if len(unowned) > 0 {
+		ui.Errorf("%d of %d changed file(s) have no owner:", len(unowned), len(changed))
+		for _, path := range unowned {
+			ui.Printf("  %s\n", path)
+		}
+		ui.Println("  Add entries to CODEOWNERS, see 'githelper owners suggest'")
+		problems += len(unowned)
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("CODEOWNERS check failed")
+	}
+	ui.Successf("All %d changed file(s) have owners", len(changed))
+	return nil
+}
+
+func runOwnersSuggest(cmd *cobra.Command, args []string) error {
+	root, file, err := loadCodeowners()
+	if err != nil && !errors.Is(err, codeowners.ErrNotFound) {
+		return err
+	}
+	if ownersDepth < 1 {
+		return fmt.Errorf("--depth must be at least 1")
+	}
+	if err := os.Chdir(root); err != nil {
+		return err
+	}
+
+	output, err := gitCommand("ls-files").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+	dirs := map[string][]string{}
+	for _, path := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		parts := strings.Split(path, "/")
+		if len(parts) < 2 {
+			continue // Files in the root are left to a catch-all entry
+		}
+		if len(parts) > ownersDepth+1 {
+			parts = parts[:ownersDepth+1]
+		}
+		dir := strings.Join(parts[:len(parts)-1], "/")
+		dirs[dir] = append(dirs[dir], path)
+	}
+
+	fmt.Fprintln(ui.Err(), "🔍 Collecting blame statistics...")
+	suggested := 0
+	for _, dir := range sortedKeys(dirs) {
+		files := dirs[dir]
+		if file != nil && !ownersAll && allOwned(file, files) {
+			continue
+		}
+		stats, err := collectBlameStats(files)
+		if err != nil {
+			return err
+		}
+		owners := suggestOwners(stats, ownersMinShare, ownersMaxOwners)
+		if len(owners) == 0 {
+			continue
+		}
+		ui.Printf("/%s/ %s\n", dir, strings.Join(owners, " "))
+		suggested++
+	}
+
+	if suggested == 0 {
+		fmt.Fprintln(ui.Err(), "✅ No suggestions, every directory has owners")
+	}
+	return nil
+}
+
+// allOwned reports whether every file has an owner
+func allOwned(file *codeowners.File, files []string) bool {
+	for _, path := range files {
+		if len(file.Owners(path)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// suggestOwners returns the CODEOWNERS handles of the authors owning at
+// least minShare percent of the lines, largest share first
+func suggestOwners(stats *BlameStats, minShare float64, maxOwners int) []string {
+	if stats.TotalLines == 0 {
+		return nil
+	}
+	var owners []string
+	seen := map[string]bool{}
+	for _, author := range stats.SortedAuthors() {
+		if len(owners) >= maxOwners || float64(author.Lines)*100/float64(stats.TotalLines) < minShare {
+			break
+		}
+		handle := ownerHandle(author.Email)
+		if handle == "" || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		owners = append(owners, handle)
+	}
+	return owners
+}
+
+// ownerHandle turns a commit email into a CODEOWNERS owner: @login for
+// GitHub noreply addresses, the email otherwise
+func ownerHandle(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return ""
+	}
+	if strings.EqualFold(domain, "users.noreply.github.com") {
+		if _, login, ok := strings.Cut(local, "+"); ok {
+			return "@" + login
+		}
+		return "@" + local
+	}
+	return email
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnerHandle(t *testing.T) {
+	assert.Equal(t, "@octocat", ownerHandle("12345+octocat@users.noreply.github.com"))
+	assert.Equal(t, "@octocat", ownerHandle("octocat@users.noreply.github.com"))
+	assert.Equal(t, "jane@example.com", ownerHandle("jane@example.com"))
+	assert.Equal(t, "", ownerHandle("not-an-email"))
+}
+
+func TestSuggestOwners(t *testing.T) {
+	stats := &BlameStats{TotalLines: 100, Authors: map[string]*AuthorStats{
+		"a@example.com":                {Email: "a@example.com", Lines: 60},
+		"1+b@users.noreply.github.com": {Email: "1+b@users.noreply.github.com", Lines: 25},
+		"c@example.com":                {Email: "c@example.com", Lines: 15},
+	}}
+	assert.Equal(t, []string{"a@example.com", "@b"}, suggestOwners(stats, 20, 2))
+	assert.Equal(t, []string{"a@example.com"}, suggestOwners(stats, 20, 1))
+	assert.Equal(t, []string{"a@example.com"}, suggestOwners(stats, 50, 3))
+}
+
+func TestOwnersCheck(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "initial")
+	runGit(t, tmpDir, "branch", "-M", "main")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.MkdirAll(".github", 0755))
+	require.NoError(t, os.WriteFile(".github/CODEOWNERS", []byte("/src/ @octo/dev\n"), 0644))
+	require.NoError(t, os.MkdirAll("src", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("src", "app.go"), []byte("package src\n"), 0644))
+	runGit(t, tmpDir, "checkout", "-b", "feature")
+	runGit(t, tmpDir, "add", ".")
+	runGit(t, tmpDir, "commit", "-m", "add app")
+
+	ownersBase = "main"
+	defer func() { ownersBase = "" }()
+	// .github/CODEOWNERS itself has no owner
+	assert.Error(t, runOwnersCheck(nil, nil))
+
+	require.NoError(t, os.WriteFile(".github/CODEOWNERS", []byte("/src/ @octo/dev\n/.github/ @octo/admins\n"), 0644))
+	runGit(t, tmpDir, "commit", "-am", "own .github")
+	assert.NoError(t, runOwnersCheck(nil, nil))
+}
@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	patchOutputDir   string
+	patchStdout      bool
+	patchCoverLetter bool
+	patchNo3Way      bool
+	patchContinue    bool
+	patchAbort       bool
+	patchSkip        bool
+)
+
+var patchCmd = &cobra.Command{
+	Use:   "patch",
+	Short: "Export and apply commits as patch files",
+	Long: `Export commits as email patches and apply patches, for projects that review
+patches by email or exchange them outside of pull requests.
+
+Example:
+  githelper patch export
+  githelper patch apply 0001-fix-typo.patch
+  githelper patch apply https://github.com/owner/repo/pull/42`,
+}
+
+var patchExportCmd = &cobra.Command{
+	Use:   "export [range]",
+	Short: "Write commits as patch files",
+	Long: `Write each commit of a range as a patch file with 'git format-patch', ready
+for 'git send-email' or 'githelper patch apply'.
+
+The range defaults to the commits not in the default branch of origin
+(origin/main..HEAD). A single commit exports the commits since it.
+
+Example:
+  githelper patch export                       # One file per commit
+  githelper patch export HEAD~3 -o patches/    # The last 3 commits
+  githelper patch export --cover-letter
+  githelper patch export --stdout > feature.mbox`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPatchExport,
+}
+
+var patchApplyCmd = &cobra.Command{
+	Use:   "apply <file|dir|url>...",
+	Short: "Apply patch files, mailboxes or GitHub pull requests",
+	Long: `Apply patches as commits with 'git am', in order. Each source can be:
+  - a patch file from 'git format-patch', or a plain diff
+  - an mbox holding several patches
+  - a directory, whose *.patch files are applied in name order
+  - a GitHub pull request or commit URL, fetched through the API
+  - any other http(s) URL serving a patch, such as a mailing list archive
+
+When a patch doesn't apply cleanly, a 3-way merge with the blobs it was made
+from is tried. If conflicts remain, resolve them, 'git add' the files and run
+'githelper patch apply --continue'; --skip drops the patch, --abort restores
+the branch. Plain diffs carry no commit information: they are applied to the
+working tree and index, and left for you to commit.
+
+Example:
+  githelper patch apply 0001-fix-typo.patch 0002-add-tests.patch
+  githelper patch apply patches/
+  githelper patch apply series.mbox
+  githelper patch apply https://github.com/owner/repo/pull/42
+  githelper patch apply https://github.com/owner/repo/commit/abc1234
+  githelper patch apply --continue`,
+	RunE: runPatchApply,
+}
+
+func init() {
+	rootCmd.AddCommand(patchCmd)
+	patchCmd.AddCommand(patchExportCmd)
+	patchCmd.AddCommand(patchApplyCmd)
+
+	patchExportCmd.Flags().StringVarP(&patchOutputDir, "output", "o", ".", "directory to write the patch files to")
+	patchExportCmd.Flags().BoolVar(&patchStdout, "stdout", false, "write all the patches to standard output as one mbox")
+	patchExportCmd.Flags().BoolVar(&patchCoverLetter, "cover-letter", false, "also write a cover letter to fill in")
+
+	patchApplyCmd.Flags().BoolVar(&patchNo3Way, "no-3way", false, "fail instead of trying a 3-way merge")
+	patchApplyCmd.Flags().BoolVar(&patchContinue, "continue", false, "continue after resolving conflicts")
+	patchApplyCmd.Flags().BoolVar(&patchAbort, "abort", false, "stop and restore the branch")
+	patchApplyCmd.Flags().BoolVar(&patchSkip, "skip", false, "skip the patch that failed")
+}
+
+func runPatchExport(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	var revRange string
+	if len(args) > 0 {
+		revRange = args[0]
+	} else {
+		branch, err := defaultBranch()
+		if err != nil {
+			return fmt.Errorf("failed to find the default branch, pass a range: %w", err)
+		}
+		revRange = "origin/" + branch + "..HEAD"
+	}
+
+	formatArgs := []string{"format-patch"}
+	if patchCoverLetter {
+		formatArgs = append(formatArgs, "--cover-letter")
+	}
+	if patchStdout {
+		formatCmd := gitCommand(append(formatArgs, "--stdout", revRange)...)
+		formatCmd.Stdout = os.Stdout
+		formatCmd.Stderr = os.Stderr
+		if err := formatCmd.Run(); err != nil {
+			return fmt.Errorf("failed to export %s: %w", revRange, err)
+		}
+		return nil
+	}
+
+	formatArgs = append(formatArgs, "-o", patchOutputDir, revRange)
+	output, err := gitCommand(formatArgs...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return fmt.Errorf("failed to export %s: %s", revRange, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return fmt.Errorf("failed to export %s: %w", revRange, err)
+	}
+	files := strings.Fields(string(output))
+	if len(files) == 0 {
+		ui.Successf("No commits in range %s", revRange)
+		return nil
+	}
+	ui.Stepf("📤 Exported %d patch(es) from %s:", len(files), revRange)
+	for _, file := range files {
+		ui.Printf("  %s\n", file)
+	}
+	if patchCoverLetter {
+		ui.Step("\n💡 Fill in the subject and blurb of the cover letter before sending")
+	}
+	return nil
+}
+
+func runPatchApply(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	resume := ""
+	for i, set := range []bool{patchContinue, patchAbort, patchSkip} {
+		if !set {
+			continue
+		}
+		flag := []string{"--continue", "--abort", "--skip"}[i]
+		if resume != "" {
+			return fmt.Errorf("%s and %s can't be combined", resume, flag)
+		}
+		resume = flag
+	}
+	if resume != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("%s takes no patches", resume)
+		}
+		return runGitAm(resume)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("requires at least 1 patch file, directory or URL")
+	}
+
+	// Fetch and expand every source first, so a bad one fails before
+	// anything is applied
+	var patches []string
+	tmpDir, err := makeTempDir("", "githelper-patch-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	for i, source := range args {
+		files, err := patchFiles(source, filepath.Join(tmpDir, fmt.Sprintf("%04d.patch", i)))
+		if err != nil {
+			return err
+		}
+		patches = append(patches, files...)
+	}
+	if len(patches) == 0 {
+		return fmt.Errorf("no patches found")
+	}
+
+	// Plain diffs leave uncommitted changes behind, which 'git am' refuses
+	var diffs int
+	for _, file := range patches {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if !isMailbox(content) {
+			diffs++
+		}
+	}
+	if diffs > 0 && diffs < len(patches) {
+		return fmt.Errorf("plain diffs can't be mixed with email patches, apply them separately")
+	}
+
+	if err := recordOperation("patch apply", false); err != nil {
+		return err
+	}
+	if diffs > 0 {
+		for _, file := range patches {
+			if err := applyDiff(file); err != nil {
+				return err
+			}
+		}
+		ui.Stepf("📝 Applied %d diff(s) to the index, they have no commit message: review and commit them", diffs)
+		return nil
+	}
+
+	amArgs := []string{"am"}
+	if !patchNo3Way {
+		amArgs = append(amArgs, "--3way")
+	}
+	amCmd := gitCommand(append(amArgs, patches...)...)
+	amCmd.Stdout = os.Stdout
+	amCmd.Stderr = os.Stderr
+	if err := amCmd.Run(); err != nil {
+		ui.Warn("\nA patch doesn't apply cleanly.")
+		ui.Println("Resolve the conflicts, 'git add' the files and run 'githelper patch apply --continue',")
+		ui.Println("or 'githelper patch apply --skip' to drop this patch, '--abort' to give up.")
+		return fmt.Errorf("failed to apply the patches")
+	}
+	ui.Success("Patches applied")
+	return nil
+}
+
+// patchFiles returns the patch files of a source: the file itself, the
+// *.patch files of a directory, or a URL downloaded to download
+func patchFiles(source, download string) ([]string, error) {
+	if strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") {
+		ui.Stepf("📥 Fetching %s", source)
+		patch, err := fetchPatch(source)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(download, []byte(patch), 0644); err != nil {
+			return nil, err
+		}
+		return []string{download}, nil
+	}
+
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", source, err)
+	}
+	if !info.IsDir() {
+		return []string{source}, nil
+	}
+	files, err := filepath.Glob(filepath.Join(source, "*.patch"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no *.patch files in %s", source)
+	}
+	sort.Strings(files)
+	// Cover letters from 'patch export --cover-letter' have no diff
+	return slices.DeleteFunc(files, func(file string) bool {
+		return strings.HasPrefix(filepath.Base(file), "0000-cover-letter")
+	}), nil
+}
+
+// fetchPatch downloads a patch: GitHub pull requests and commits through the
+// API of their host, other URLs as they are
+func fetchPatch(source string) (string, error) {
+	target, err := github.ParsePatchURL(source)
+	if errors.Is(err, github.ErrNotPatchURL) {
+		resp, err := http.Get(source)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch %s: %w", source, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("failed to fetch %s: %s", source, resp.Status)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch %s: %w", source, err)
+		}
+		return string(body), nil
+	}
+
+	host, err := resolveHost(target.Host)
+	if err != nil {
+		return "", err
+	}
+	// Public repositories can be read without a token
+	client, err := github.NewHostClient(host)
+	if err != nil {
+		return "", err
+	}
+	ctx := context.Background()
+	var patch string
+	if target.Number != 0 {
+		patch, err = client.PullRequestPatch(ctx, target.Owner, target.Repo, target.Number)
+	} else {
+		patch, err = client.CommitPatch(ctx, target.Owner, target.Repo, target.Commit)
+	}
+	if err != nil {
+		if errors.Is(err, github.ErrUnauthorized) {
+			return "", fmt.Errorf("not authorized to read %s, run 'githelper auth login --host %s'", source, target.Host)
+		}
+		return "", fmt.Errorf("failed to fetch %s: %w", source, err)
+	}
+	return patch, nil
+}
+
+// isMailbox reports whether a patch is in the email format of 'git
+// format-patch', as opposed to a plain diff
+func isMailbox(content []byte) bool {
+	for _, prefix := range []string{"From ", "From:", "Subject:", "Date:"} {
+		if bytes.HasPrefix(content, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyDiff applies a plain diff to the working tree and index, with a
+// 3-way merge unless --no-3way is set
+func applyDiff(file string) error {
+	applyArgs := []string{"apply", "--index"}
+	if !patchNo3Way {
+		applyArgs = append(applyArgs, "--3way")
+	}
+	applyCmd := gitCommand(append(applyArgs, file)...)
+	applyCmd.Stdout = os.Stdout
+	applyCmd.Stderr = os.Stderr
+	if err := applyCmd.Run(); err != nil {
+		return fmt.Errorf("failed to apply %s: %w", file, err)
+	}
+	return nil
+}
+
+// runGitAm resumes or aborts an interrupted 'git am'
+func runGitAm(flag string) error {
+	amCmd := gitCommand("am", flag)
+	amCmd.Stdout = os.Stdout
+	amCmd.Stderr = os.Stderr
+	if err := amCmd.Run(); err != nil {
+		return fmt.Errorf("git am %s failed: %w", flag, err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsMailbox(t *testing.T) {
+	assert.True(t, isMailbox([]byte("From 1234 Mon Sep 17 00:00:00 2001\nFrom: Test <test@example.com>\n")))
+	assert.True(t, isMailbox([]byte("Subject: [PATCH] Fix\n")))
+	assert.False(t, isMailbox([]byte("diff --git a/f b/f\n")))
+}
+
+func TestPatchExportApply(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+	runGit(t, tmpDir, "config", "user.name", "Test")
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+
+	runGit(t, tmpDir, "commit", "-m", "base")
+	runGit(t, tmpDir, "branch", "-M", "main")
+	runGit(t, tmpDir, "checkout", "-b", "feature")
+	for _, change := range []string{"first", "second"} {
+		require.NoError(t, os.WriteFile(change+".txt", []byte(change), 0644))
+		runGit(t, tmpDir, "add", ".")
+		runGit(t, tmpDir, "commit", "-m", "Add "+change)
+	}
+
+	patchOutputDir = filepath.Join(t.TempDir(), "patches")
+	patchCoverLetter = true
+	defer func() { patchCoverLetter = false }()
+	require.NoError(t, runPatchExport(patchExportCmd, []string{"main..feature"}))
+	files, err := patchFiles(patchOutputDir, "")
+	require.NoError(t, err)
+	require.Len(t, files, 2, "the cover letter is skipped")
+	assert.Equal(t, "0001-Add-first.patch", filepath.Base(files[0]))
+
+	runGit(t, tmpDir, "checkout", "main")
+	require.NoError(t, runPatchApply(patchApplyCmd, []string{patchOutputDir}))
+	output, err := exec.Command("git", "log", "--format=%s", "main").Output()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Add second", "Add first", "base"}, strings.Split(strings.TrimSpace(string(output)), "\n"))
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/plain"
+)
+
+// defaultEditor returns the editor used when neither $EDITOR nor git's
+// core.editor is set
+func defaultEditor() string {
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vim"
+}
+
+// editorCommand builds the command that opens file in the user's editor.
+// The editor may carry arguments, e.g. "code --wait".
+func editorCommand(file string) *exec.Cmd {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		if output, err := gitCommand("config", "core.editor").Output(); err == nil {
+			editor = strings.TrimSpace(string(output))
+		}
+	}
+	if editor == "" {
+		editor = defaultEditor()
+	}
+
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], file)...)
+	// The editor needs the terminal, even when --plain filters the output
+	cmd.Stdin = os.Stdin
+	cmd.Stdout, cmd.Stderr = plain.Terminal()
+	return cmd
+}
+
+// filePreviewCommand returns an fzf preview command printing the selected file
+func filePreviewCommand() string {
+	if _, err := exec.LookPath("bat"); err == nil {
+		return "bat --style=numbers --color=always {}"
+	}
+	if runtime.GOOS == "windows" {
+		return "type {}"
+	}
+	return "cat {}"
+}
+
+// shellQuote quotes s for the POSIX shell git uses to run filters and hooks.
+// Git for Windows ships its own sh, so this is portable.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runGit runs git in dir with a fixed identity so tests don't depend on
+// the machine's configuration or shell
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "'secrets.env'", shellQuote("secrets.env"))
+	assert.Equal(t, "'my file.txt'", shellQuote("my file.txt"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
+
+func TestGetCurrentTimestamp(t *testing.T) {
+	_, err := time.Parse("2006-01-02 15:04:05", getCurrentTimestamp())
+	assert.NoError(t, err)
+}
+
+func TestGetLargeFiles(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "assets"), 0755))
+	large := strings.Repeat("x", 4096)
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "assets", "big file.bin"), []byte(large), 0644))
+	runGit(t, tmpDir, "add", ".")
+	runGit(t, tmpDir, "commit", "-q", "-m", "add files")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	originalThreshold, originalNumFiles := threshold, numFiles
+	defer func() { threshold, numFiles = originalThreshold, originalNumFiles }()
+	threshold, numFiles = "", 10
+
+	files, err := getLargeFiles()
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "assets/big file.bin", files[0].Path)
+	assert.Equal(t, int64(4096), files[0].Size)
+	assert.Equal(t, "test.txt", files[1].Path)
+
+	threshold = "1KB"
+	files, err = getLargeFiles()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "assets/big file.bin", files[0].Path)
+}
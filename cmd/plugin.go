@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/plugin"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var pluginCmd = &cobra.Command{
+	Use:   "plugin",
+	Short: "List installed plugins",
+	Long: `Extend githelper with your own subcommands.
+
+Any executable named githelper-<name> on your PATH or in ~/.githelper/plugins
+becomes available as 'githelper <name>'. Global flags given before the name
+(--profile, --host, --debug, ...) are applied, and the resulting settings are
+passed to the plugin as environment variables:
+
+  GITHELPER_BIN              path of the githelper executable
+  GITHELPER_PLUGIN_NAME      name the plugin was invoked as
+  GITHELPER_CONFIG_FILE      config file in use
+  GITHELPER_PROFILE          active profile
+  GITHELPER_HOST             GitHub host in use
+  GITHELPER_YES              "true" when --yes was given
+  GITHELPER_NON_INTERACTIVE  "true" when prompts are disabled
+  GITHELPER_<KEY>            every non-secret setting, e.g. GITHELPER_DEFAULT_ORG
+
+An optional manifest next to the executable (githelper-<name>.yaml) adds a
+description and usage line, and can request the GitHub token:
+
+  description: Deploy the current branch
+  usage: githelper deploy <environment>
+  needs_token: true            # sets GITHELPER_GITHUB_TOKEN
+
+Example:
+  githelper plugin list
+  githelper --profile work deploy staging`,
+}
+
+var pluginListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the plugins found on PATH and in ~/.githelper/plugins",
+	Args:  cobra.NoArgs,
+	RunE:  runPluginList,
+}
+
+func init() {
+	rootCmd.AddCommand(pluginCmd)
+	pluginCmd.AddCommand(pluginListCmd)
+}
+
+func runPluginList(cmd *cobra.Command, args []string) error {
+	plugins := plugin.List()
+	if len(plugins) == 0 {
+		ui.Printf("No plugins found. Add githelper-<name> executables to your PATH or %s\n", plugin.Dir())
+		return nil
+	}
+
+	for _, p := range plugins {
+		shadowed := ""
+		if builtin, _, err := rootCmd.Find([]string{p.Name}); err == nil && builtin != rootCmd {
+			shadowed = " (shadowed by built-in command)"
+		}
+		ui.Printf("%-15s %s%s\n", p.Name, p.Manifest.Description, shadowed)
+		ui.Printf("%-15s %s\n", "", p.Path)
+		if p.Manifest.Usage != "" {
+			ui.Printf("%-15s usage: %s\n", "", p.Manifest.Usage)
+		}
+	}
+	return nil
+}
+
+// findPluginCommand checks whether args invoke a plugin rather than a
+// built-in command. Global flags may come before the plugin name; everything
+// after it belongs to the plugin.
+func findPluginCommand(args []string) (*plugin.Plugin, []string, bool) {
+	if builtin, _, err := rootCmd.Find(args); err == nil && builtin != rootCmd {
+		return nil, nil, false
+	}
+
+	flags := rootCmd.PersistentFlags()
+	flags.SetInterspersed(false)
+	defer flags.SetInterspersed(true)
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return nil, nil, false
+	}
+
+	found, ok := plugin.Find(flags.Arg(0))
+	if !ok {
+		return nil, nil, false
+	}
+	return found, flags.Args()[1:], true
+}
+
+// runPlugin runs a plugin with the settings exposed in its environment and
+// exits with the plugin's exit code
+func runPlugin(p *plugin.Plugin, args []string) error {
+	initConfig()
+
+	pluginCmd := exec.Command(p.Path, args...)
+	pluginCmd.Stdin = os.Stdin
+	pluginCmd.Stdout = os.Stdout
+	pluginCmd.Stderr = os.Stderr
+	pluginCmd.Env = append(os.Environ(), pluginEnv(p)...)
+
+	if err := pluginCmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
+		return fmt.Errorf("failed to run plugin %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+func pluginEnv(p *plugin.Plugin) []string {
+	var env []string
+
+	// Every non-secret setting, e.g. safety.protected_branches becomes
+	// GITHELPER_SAFETY_PROTECTED_BRANCHES
+	flat := make(map[string]interface{})
+	flattenSettings("", viper.AllSettings(), flat)
+	for key, value := range flat {
+		known, err := lookupConfigKey(splitConfigKey(key))
+		if err != nil || known.Secret {
+			continue
+		}
+		name := strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(key))
+		env = append(env, fmt.Sprintf("GITHELPER_%s=%s", name, formatSetting(value, false)))
+	}
+
+	executable, _ := os.Executable()
+	env = append(env,
+		"GITHELPER_BIN="+executable,
+		"GITHELPER_PLUGIN_NAME="+p.Name,
+		"GITHELPER_CONFIG_FILE="+viper.ConfigFileUsed(),
+		"GITHELPER_PROFILE="+activeProfile(),
+		"GITHELPER_HOST="+defaultHostName(),
+		"GITHELPER_YES="+strconv.FormatBool(assumeYes),
+		"GITHELPER_NON_INTERACTIVE="+strconv.FormatBool(!isInteractive()),
+	)
+
+	if p.Manifest.NeedsToken {
+		if host, err := resolveHost(""); err == nil && host.Token != "" {
+			env = append(env, "GITHELPER_GITHUB_TOKEN="+host.Token)
+		}
+	}
+	return env
+}
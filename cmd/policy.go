@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/codeowners"
+	"github.com/EndlessUphill/git-helper/internal/secrets"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultPolicyMaxFileSize is GitHub's hard limit: pushes with larger files
+// are rejected
+const defaultPolicyMaxFileSize = "100MB"
+
+// PolicyViolation is a commit the server would reject, and why
+type PolicyViolation struct {
+	// Commit is empty when the violation isn't tied to one commit
+	Commit string
+	Detail string
+}
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Check commits against the server's push rules",
+	Long: `Simulate the rules a server enforces on pushes, such as pre-receive hooks and
+push rulesets, so a push that would be rejected fails locally instead.
+
+Example:
+  githelper policy check`,
+}
+
+var policyCheckCmd = &cobra.Command{
+	Use:   "check [remote] [branch]",
+	Short: "Check the commits about to be pushed against the push policy",
+	Long: `Check the commits of a branch that the remote doesn't have yet against the
+push policy configured in the policy section of .githelper.yaml, and exit
+with an error when the server would reject the push:
+
+  policy:
+    max_file_size: 100MB          # largest file (default push.max_file_size or 100MB)
+    forbidden_paths:              # gitignore-style patterns no commit may touch
+      - "*.pem"
+      - .env
+      - vendor/**
+    email_domains: [example.com]  # allowed author and committer email domains
+    secrets: true                 # reject commits that add secrets (default)
+
+Example:
+  githelper policy check               # The current branch against origin
+  githelper policy check upstream main`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runPolicyCheck,
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policyCheckCmd)
+}
+
+func runPolicyCheck(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	remote, branch, err := pushTarget(args)
+	if err != nil {
+		return err
+	}
+	revRange, _ := pushRange(remote, branch)
+	commits, err := pushCommits(revRange)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		ui.Successf("%s/%s has every commit of %s, nothing to check", remote, branch, branch)
+		return nil
+	}
+	ui.Printf("🛡️  Checking %d commit(s) about to be pushed to %s/%s\n\n", len(commits), remote, branch)
+
+	limit := viper.GetString("policy.max_file_size")
+	if limit == "" {
+		limit = viper.GetString("push.max_file_size")
+	}
+	if limit == "" {
+		limit = defaultPolicyMaxFileSize
+	}
+	maxSize, err := parseSize(limit)
+	if err != nil {
+		return fmt.Errorf("invalid policy.max_file_size: %w", err)
+	}
+
+	rejected := 0
+	report := func(rule string, violations []PolicyViolation) {
+		if len(violations) == 0 {
+			ui.Successf("%s", rule)
+			return
+		}
+		rejected += len(violations)
+		ui.Errorf("%s:", rule)
+		for _, v := range violations {
+			if v.Commit == "" {
+				ui.Printf("  %s\n", v.Detail)
+			} else {
+				ui.Printf("  %s %s\n", v.Commit, v.Detail)
+			}
+		}
+	}
+
+	large, err := findLargeBlobs(maxSize+1, revRange...)
+	if err != nil {
+		return err
+	}
+	var violations []PolicyViolation
+	for _, file := range large {
+		violations = append(violations, PolicyViolation{Detail: fmt.Sprintf("%s is %s", file.Path, formatSize(file.Size))})
+	}
+	report("Files up to "+limit, violations)
+
+	if patterns := viper.GetStringSlice("policy.forbidden_paths"); len(patterns) > 0 {
+		violations, err := forbiddenPathViolations(revRange, patterns)
+		if err != nil {
+			return err
+		}
+		report("No forbidden paths", violations)
+	}
+
+	if domains := viper.GetStringSlice("policy.email_domains"); len(domains) > 0 {
+		violations, err := emailDomainViolations(revRange, domains)
+		if err != nil {
+			return err
+		}
+		report("Emails in "+strings.Join(domains, ", "), violations)
+	}
+
+	if !viper.IsSet("policy.secrets") || viper.GetBool("policy.secrets") {
+		violations, err := secretViolations(revRange)
+		if err != nil {
+			return err
+		}
+		report("No secrets", violations)
+	}
+
+	if rejected > 0 {
+		return fmt.Errorf("the push would be rejected: %d violation(s)", rejected)
+	}
+	ui.Success("\nThe push follows the policy")
+	return nil
+}
+
+// forbiddenPathViolations finds the commits adding or changing paths that
+// match the patterns; deleting them is allowed
+func forbiddenPathViolations(revRange, patterns []string) ([]PolicyViolation, error) {
+	args := append([]string{"log", "--format=commit %h", "--name-only", "--diff-filter=ACMR", "--no-renames"}, revRange...)
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+
+	var violations []PolicyViolation
+	commit := ""
+	for _, line := range strings.Split(string(output), "\n") {
+		if hash, ok := strings.CutPrefix(line, "commit "); ok {
+			commit = hash
+			continue
+		}
+		if line == "" {
+			continue
+		}
+		for _, pattern := range patterns {
+			if codeowners.MatchPattern(pattern, line) {
+				violations = append(violations, PolicyViolation{Commit: commit, Detail: fmt.Sprintf("%s matches %s", line, pattern)})
+				break
+			}
+		}
+	}
+	return violations, nil
+}
+
+// emailDomainViolations finds the commits whose author or committer email
+// isn't in one of the domains
+func emailDomainViolations(revRange, domains []string) ([]PolicyViolation, error) {
+	args := append([]string{"log", "--format=%h %ae %ce"}, revRange...)
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+
+	var violations []PolicyViolation
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		for i, role := range []string{"author", "committer"} {
+			email := fields[i+1]
+			if !emailInDomains(email, domains) {
+				violations = append(violations, PolicyViolation{Commit: fields[0], Detail: fmt.Sprintf("%s email %s", role, email)})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// emailInDomains reports whether an email belongs to one of the domains or
+// their subdomains
+func emailInDomains(email string, domains []string) bool {
+	_, domain, ok := strings.Cut(strings.ToLower(email), "@")
+	if !ok {
+		return false
+	}
+	for _, allowed := range domains {
+		allowed = strings.ToLower(strings.TrimPrefix(allowed, "@"))
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func secretViolations(revRange []string) ([]PolicyViolation, error) {
+	args := append([]string{"log", "-p", "--no-color", "--no-ext-diff", "--format=commit %h"}, revRange...)
+	patch, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commits: %w", err)
+	}
+	found, err := secrets.ScanDiff(bytes.NewReader(patch))
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan for secrets: %w", err)
+	}
+	var violations []PolicyViolation
+	for _, finding := range found {
+		violations = append(violations, PolicyViolation{
+			Commit: finding.Commit,
+			Detail: fmt.Sprintf("%s: %s (%s)", finding.Location(), finding.Rule.Description, finding.Match),
+		})
+	}
+	return violations, nil
+}
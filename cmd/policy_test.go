@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailInDomains(t *testing.T) {
+	domains := []string{"example.com", "@corp.example.org"}
+	assert.True(t, emailInDomains("alice@example.com", domains))
+	assert.True(t, emailInDomains("Bob@EU.Example.com", domains))
+	assert.True(t, emailInDomains("carol@corp.example.org", domains))
+	assert.False(t, emailInDomains("dave@gmail.com", domains))
+	assert.False(t, emailInDomains("eve@notexample.com", domains))
+	assert.False(t, emailInDomains("no-email", domains))
+}
+
+func TestPolicyViolations(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "base")
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "certs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "certs", "server.pem"), []byte("cert"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0644))
+	runGit(t, tmpDir, "add", ".")
+	runGit(t, tmpDir, "commit", "-m", "add files")
+	runGit(t, tmpDir, "rm", "-q", "certs/server.pem")
+	runGit(t, tmpDir, "commit", "-m", "remove cert")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+	output, err := exec.Command("git", "rev-parse", "--short", "HEAD~1").Output()
+	require.NoError(t, err)
+	added := string(output[:len(output)-1])
+
+	violations, err := forbiddenPathViolations([]string{"HEAD~2..HEAD"}, []string{"*.pem", "vendor/**"})
+	require.NoError(t, err)
+	assert.Equal(t, []PolicyViolation{{Commit: added, Detail: "certs/server.pem matches *.pem"}}, violations)
+
+	violations, err = emailDomainViolations([]string{"HEAD~2..HEAD"}, []string{"example.com"})
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+	violations, err = emailDomainViolations([]string{"HEAD~1..HEAD"}, []string{"corp.com"})
+	require.NoError(t, err)
+	assert.Len(t, violations, 2)
+}
@@ -0,0 +1,371 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	prWatch        bool
+	prInterval     time.Duration
+	prMergeMethod  string
+	prDeleteBranch bool
+)
+
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Work with the pull request of the current branch",
+	Long: `Show, merge and auto-merge the pull request of the current branch on GitHub.
+
+Subcommands take the pull request number as an optional argument; without it
+they use the open pull request whose head is the current branch.
+
+Example:
+  githelper pr status          # Reviews, checks and mergeability
+  githelper pr status --watch  # Refresh until the checks finish
+  githelper pr merge --method squash --delete-branch
+  githelper pr automerge --method squash --notify`,
+}
+
+var prStatusCmd = &cobra.Command{
+	Use:   "status [number]",
+	Short: "Show the reviews, checks and mergeability of a pull request",
+	Long: `Show the state of a pull request: the latest review of each reviewer, the
+reviewers still requested, the CI checks (check runs and commit statuses)
+and whether it can be merged.
+
+With --watch the status is refreshed until no check is pending. The command
+fails when a check failed, so it can gate scripts.
+
+Example:
+  githelper pr status
+  githelper pr status 42
+  githelper pr status --watch --interval 30s`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPRStatus,
+}
+
+var prMergeCmd = &cobra.Command{
+	Use:   "merge [number]",
+	Short: "Merge a pull request",
+	Long: `Merge a pull request with one of the merge methods the repository allows.
+
+Without --method, the only allowed method is used, or you are asked to pick
+one. The merge is refused when checks failed or GitHub reports conflicts,
+and needs a confirmation while checks are pending or reviews are missing.
+The pull request is only merged if nobody pushed to it in the meantime.
+
+Example:
+  githelper pr merge
+  githelper pr merge 42 --method rebase
+  githelper pr merge --method squash --delete-branch`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPRMerge,
+}
+
+func init() {
+	rootCmd.AddCommand(prCmd)
+	prCmd.AddCommand(prStatusCmd)
+	prCmd.AddCommand(prMergeCmd)
+
+	prStatusCmd.Flags().BoolVarP(&prWatch, "watch", "w", false, "refresh until no check is pending")
+	prStatusCmd.Flags().DurationVar(&prInterval, "interval", 10*time.Second, "time between refreshes with --watch")
+
+	prMergeCmd.Flags().StringVar(&prMergeMethod, "method", "", "merge method: merge, squash or rebase")
+	prMergeCmd.Flags().BoolVarP(&prDeleteBranch, "delete-branch", "d", false, "delete the branch locally and on GitHub after merging")
+}
+
+// currentPullRequest connects to origin and finds the pull request given as
+// argument, or the one of the current branch
+func currentPullRequest(args []string) (github.PullRequests, string, string, int, error) {
+	if err := checkGitRepo(); err != nil {
+		return nil, "", "", 0, err
+	}
+	client, owner, name, err := originClient()
+	if err != nil {
+		return nil, "", "", 0, err
+	}
+
+	if len(args) > 0 {
+		number, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+		if err != nil || number <= 0 {
+			return nil, "", "", 0, fmt.Errorf("invalid pull request number '%s'", args[0])
+		}
+		return client, owner, name, number, nil
+	}
+
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return nil, "", "", 0, err
+	}
+	if branch == "" || branch == "HEAD" {
+		return nil, "", "", 0, fmt.Errorf("HEAD is detached. Give the pull request number")
+	}
+	number, err := client.FindPullRequest(context.Background(), owner, name, owner, branch)
+	if errors.Is(err, github.ErrNoPullRequest) {
+		return nil, "", "", 0, fmt.Errorf("no open pull request for branch '%s'", branch)
+	} else if err != nil {
+		return nil, "", "", 0, fmt.Errorf("failed to find pull request: %w", err)
+	}
+	return client, owner, name, number, nil
+}
+
+func runPRStatus(cmd *cobra.Command, args []string) error {
+	client, owner, name, number, err := currentPullRequest(args)
+	if err != nil {
+		return err
+	}
+	if prWatch && prInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	for {
+		status, err := client.PullRequestStatus(context.Background(), owner, name, number)
+		if err != nil {
+			return fmt.Errorf("failed to get pull request: %w", err)
+		}
+		printPRStatus(ui.Out(), status)
+
+		_, pending, failed := status.CheckCounts()
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		if !prWatch || pending == 0 || status.State != "open" {
+			return nil
+		}
+		ui.Printf("\n⏳ %d check(s) pending, refreshing in %s (Ctrl+C to stop)\n\n", pending, prInterval)
+		time.Sleep(prInterval)
+	}
+}
+
+// printPRStatus renders a pull request status
+func printPRStatus(w io.Writer, status *github.PullRequestStatus) {
+	state := status.State
+	switch {
+	case status.Merged:
+		state = "merged"
+	case status.Draft && state == "open":
+		state = "draft"
+	}
+	fmt.Fprintf(w, "🔀 #%d %s [%s]\n", status.Number, status.Title, state)
+	fmt.Fprintf(w, "   %s → %s\n", status.Head, status.Base)
+	fmt.Fprintf(w, "   %s\n", status.URL)
+
+	fmt.Fprintln(w, "\n👀 Reviews:")
+	if len(status.Reviews) == 0 && len(status.RequestedReviewers) == 0 {
+		fmt.Fprintln(w, "  No reviews yet")
+	}
+	for _, review := range status.Reviews {
+		fmt.Fprintf(w, "  %s %s (%s)\n", reviewIcon(review.State), review.User, strings.ToLower(strings.ReplaceAll(review.State, "_", " ")))
+	}
+	for _, reviewer := range status.RequestedReviewers {
+		fmt.Fprintf(w, "  ⏳ %s (requested)\n", reviewer)
+	}
+
+	fmt.Fprintln(w)
+	printChecks(w, status.Checks)
+
+	fmt.Fprintf(w, "\n%s\n", mergeabilitySummary(status))
+	if status.AutoMerge != "" && !status.Merged {
+		fmt.Fprintf(w, "🤖 Auto-merge enabled (%s)\n", status.AutoMerge)
+	}
+}
+
+// printChecks renders the check counts and each check, with a link to the
+// failed ones
+func printChecks(w io.Writer, checks []github.Check) {
+	passed, pending, failed := github.CountChecks(checks)
+	fmt.Fprintf(w, "🧪 Checks: %d passed, %d pending, %d failed\n", passed, pending, failed)
+	if len(checks) == 0 {
+		fmt.Fprintln(w, "  No checks reported")
+	}
+	for _, check := range checks {
+		fmt.Fprintf(w, "  %s %s", checkIcon(check.State), check.Name)
+		if check.State == github.CheckFailure && check.URL != "" {
+			fmt.Fprintf(w, " - %s", check.URL)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func reviewIcon(state string) string {
+	switch state {
+	case "APPROVED":
+		return "✅"
+	case "CHANGES_REQUESTED":
+		return "❌"
+	case "DISMISSED":
+		return "➖"
+	}
+	return "💬"
+}
+
+func checkIcon(state string) string {
+	switch state {
+	case github.CheckSuccess:
+		return "✅"
+	case github.CheckSkipped:
+		return "⏭️ "
+	case github.CheckFailure:
+		return "❌"
+	}
+	return "⏳"
+}
+
+// mergeabilitySummary explains GitHub's mergeable state in one line
+func mergeabilitySummary(status *github.PullRequestStatus) string {
+	switch {
+	case status.Merged:
+		return "🎉 Merged"
+	case status.State != "open":
+		return "🚫 Closed"
+	case status.Mergeable == nil:
+		return "⏳ GitHub is still checking mergeability"
+	case !*status.Mergeable:
+		return "❌ Has conflicts with " + status.Base
+	}
+	switch status.MergeableState {
+	case "clean":
+		return "✅ Ready to merge"
+	case "blocked":
+		return "🚫 Blocked by branch protection (reviews or required checks)"
+	case "behind":
+		return "⚠️  Behind " + status.Base + "; update the branch before merging"
+	case "unstable":
+		return "⚠️  Mergeable, but some checks are not passing"
+	case "draft":
+		return "📝 Draft, mark it ready for review to merge"
+	}
+	return "✅ Mergeable"
+}
+
+func runPRMerge(cmd *cobra.Command, args []string) error {
+	client, owner, name, number, err := currentPullRequest(args)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	status, err := client.PullRequestStatus(ctx, owner, name, number)
+	if err != nil {
+		return fmt.Errorf("failed to get pull request: %w", err)
+	}
+	printPRStatus(ui.Out(), status)
+	ui.Println()
+
+	switch {
+	case status.Merged:
+		return fmt.Errorf("pull request #%d is already merged", number)
+	case status.State != "open":
+		return fmt.Errorf("pull request #%d is closed", number)
+	case status.Draft:
+		return fmt.Errorf("pull request #%d is a draft", number)
+	case status.Mergeable != nil && !*status.Mergeable:
+		return fmt.Errorf("pull request #%d has conflicts with %s. Run 'githelper sync' on the branch first", number, status.Base)
+	}
+	_, pending, failed := status.CheckCounts()
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed, fix them before merging", failed)
+	}
+
+	methods, err := client.MergeMethods(ctx, owner, name)
+	if err != nil {
+		return fmt.Errorf("failed to get allowed merge methods: %w", err)
+	}
+	method, err := chooseMergeMethod(prMergeMethod, methods)
+	if err != nil {
+		return err
+	}
+
+	if pending > 0 {
+		ui.Warnf("%d check(s) still pending", pending)
+	}
+	if status.MergeableState == "blocked" {
+		ui.Warn("Branch protection blocks the merge; it only succeeds if you can bypass it")
+	}
+	ui.Stepf("🔀 Merging #%d into %s with %s", number, status.Base, method)
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	sha, err := client.MergePullRequest(ctx, owner, name, number, method, status.HeadSHA)
+	if err != nil {
+		return fmt.Errorf("failed to merge pull request: %w", err)
+	}
+	ui.Successf("Merged #%d (%s)", number, shortSHA(sha))
+
+	if prDeleteBranch {
+		deletePRBranch(client, owner, name, status)
+	}
+	return nil
+}
+
+// chooseMergeMethod validates the requested method against the allowed ones,
+// or picks one when none was requested
+func chooseMergeMethod(requested string, allowed []string) (string, error) {
+	if len(allowed) == 0 {
+		return "", fmt.Errorf("the repository allows no merge method")
+	}
+	if requested != "" {
+		for _, method := range allowed {
+			if method == requested {
+				return method, nil
+			}
+		}
+		return "", fmt.Errorf("merge method '%s' is not allowed, use one of: %s", requested, strings.Join(allowed, ", "))
+	}
+	if len(allowed) == 1 {
+		return allowed[0], nil
+	}
+	if !isInteractive() {
+		return "", fmt.Errorf("pass --method, one of: %s", strings.Join(allowed, ", "))
+	}
+
+	ui.Println("Merge methods:")
+	for i, method := range allowed {
+		ui.Printf("%d. %s\n", i+1, method)
+	}
+	choice := readInput(fmt.Sprintf("Select a method (1-%d): ", len(allowed)))
+	index, err := strconv.Atoi(choice)
+	if err != nil || index < 1 || index > len(allowed) {
+		return "", fmt.Errorf("invalid selection")
+	}
+	return allowed[index-1], nil
+}
+
+// deletePRBranch deletes the merged branch on GitHub and locally, leaving
+// it for the user to clean up when that fails
+func deletePRBranch(client github.PullRequests, owner, name string, status *github.PullRequestStatus) {
+	if err := client.DeleteBranch(context.Background(), owner, name, status.Head); err != nil {
+		ui.Warnf("Failed to delete %s on GitHub: %v", status.Head, err)
+	} else {
+		ui.Stepf("🗑️  Deleted %s on GitHub", status.Head)
+	}
+
+	if gitCommand("rev-parse", "--verify", "-q", "refs/heads/"+status.Head).Run() != nil {
+		return
+	}
+	if branch, _ := getCurrentBranch(); branch == status.Head {
+		if output, err := gitCommand("checkout", status.Base).CombinedOutput(); err != nil {
+			ui.Warnf("Failed to switch to %s, keeping the local branch: %s", status.Base, strings.TrimSpace(string(output)))
+			return
+		}
+		gitCommand("pull", "--ff-only").Run()
+	}
+	// The merge happened on GitHub, so git may not see the branch as merged
+	if output, err := gitCommand("branch", "-D", status.Head).CombinedOutput(); err != nil {
+		ui.Warnf("Failed to delete the local branch: %s", strings.TrimSpace(string(output)))
+		return
+	}
+	ui.Stepf("🗑️  Deleted local branch %s", status.Head)
+}
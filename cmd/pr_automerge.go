@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/notify"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	prAutoMergeDisable  bool
+	prAutoMergeNotify   bool
+	prAutoMergeInterval time.Duration
+)
+
+var prAutoMergeCmd = &cobra.Command{
+	Use:   "automerge [number]",
+	Short: "Merge a pull request automatically once it is ready",
+	Long: `Enable GitHub auto-merge on a pull request: GitHub merges it with the chosen
+method as soon as the required reviews and checks pass. The repository must
+allow auto-merge (allow_auto_merge in 'githelper repo apply' settings).
+
+With --notify, githelper keeps polling the pull request and rings the
+terminal bell and shows a desktop notification when it is merged, closed or
+auto-merge is cancelled, e.g. by a new push from someone without write access.
+
+Example:
+  githelper pr automerge --method squash
+  githelper pr automerge 42 --method rebase --notify
+  githelper pr automerge --disable`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runPRAutoMerge,
+}
+
+func init() {
+	prCmd.AddCommand(prAutoMergeCmd)
+	prAutoMergeCmd.Flags().StringVar(&prMergeMethod, "method", "", "merge method: merge, squash or rebase")
+	prAutoMergeCmd.Flags().BoolVar(&prAutoMergeDisable, "disable", false, "cancel auto-merge")
+	prAutoMergeCmd.Flags().BoolVar(&prAutoMergeNotify, "notify", false, "wait and notify when the pull request is merged")
+	prAutoMergeCmd.Flags().DurationVar(&prAutoMergeInterval, "interval", 30*time.Second, "time between polls with --notify")
+}
+
+func runPRAutoMerge(cmd *cobra.Command, args []string) error {
+	client, owner, name, number, err := currentPullRequest(args)
+	if err != nil {
+		return err
+	}
+	if prAutoMergeNotify && prAutoMergeInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	ctx := context.Background()
+
+	status, err := client.PullRequestStatus(ctx, owner, name, number)
+	if err != nil {
+		return fmt.Errorf("failed to get pull request: %w", err)
+	}
+	switch {
+	case status.Merged:
+		ui.Stepf("🎉 #%d is already merged", number)
+		return nil
+	case status.State != "open":
+		return fmt.Errorf("pull request #%d is closed", number)
+	}
+
+	if prAutoMergeDisable {
+		if status.AutoMerge == "" {
+			ui.Infof("Auto-merge isn't enabled on #%d", number)
+			return nil
+		}
+		if err := client.DisableAutoMerge(ctx, owner, name, number); err != nil {
+			return fmt.Errorf("failed to disable auto-merge: %w", err)
+		}
+		ui.Successf("Auto-merge disabled on #%d", number)
+		return nil
+	}
+
+	if status.AutoMerge != "" && (prMergeMethod == "" || prMergeMethod == status.AutoMerge) {
+		ui.Infof("Auto-merge is already enabled on #%d (%s)", number, status.AutoMerge)
+	} else {
+		methods, err := client.MergeMethods(ctx, owner, name)
+		if err != nil {
+			return fmt.Errorf("failed to get allowed merge methods: %w", err)
+		}
+		method, err := chooseMergeMethod(prMergeMethod, methods)
+		if err != nil {
+			return err
+		}
+
+		err = client.EnableAutoMerge(ctx, owner, name, number, method)
+		switch {
+		case errors.Is(err, github.ErrReadyToMerge):
+			return fmt.Errorf("#%d is ready to merge now, run 'githelper pr merge --method %s'", number, method)
+		case errors.Is(err, github.ErrAutoMergeNotAllowed):
+			return fmt.Errorf("%w. Enable it in the repository settings, or with allow_auto_merge in 'githelper repo apply'", err)
+		case err != nil:
+			return fmt.Errorf("failed to enable auto-merge: %w", err)
+		}
+		ui.Stepf("🤖 Auto-merge enabled: #%d will be merged into %s with %s once it is ready", number, status.Base, method)
+	}
+
+	if !prAutoMergeNotify {
+		return nil
+	}
+	return notifyWhenMerged(client, owner, name, number)
+}
+
+// notifyWhenMerged polls a pull request until it is merged, closed or its
+// auto-merge is cancelled, then rings the bell and notifies the desktop
+func notifyWhenMerged(client github.PullRequests, owner, name string, number int) error {
+	ui.Stepf("⏳ Waiting for #%d to be merged, checking every %s (Ctrl+C to stop)", number, prAutoMergeInterval)
+	for {
+		time.Sleep(prAutoMergeInterval)
+		status, err := client.PullRequestStatus(context.Background(), owner, name, number)
+		if err != nil {
+			return fmt.Errorf("failed to get pull request: %w", err)
+		}
+
+		var message string
+		var result error
+		switch {
+		case status.Merged:
+			message = fmt.Sprintf("#%d %s was merged into %s", number, status.Title, status.Base)
+			ui.Stepf("🎉 %s", message)
+		case status.State != "open":
+			message = fmt.Sprintf("#%d %s was closed without merging", number, status.Title)
+			result = errors.New(message)
+		case status.AutoMerge == "":
+			message = fmt.Sprintf("Auto-merge of #%d %s was cancelled", number, status.Title)
+			result = errors.New(message)
+		default:
+			continue
+		}
+
+		notify.Bell(ui.Out())
+		if err := notify.Desktop(owner+"/"+name, message); err != nil && !errors.Is(err, notify.ErrUnsupported) {
+			ui.Warnf("%v", err)
+		}
+		return result
+	}
+}
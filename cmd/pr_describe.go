@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ai"
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	prDescribeBase      string
+	prDescribeDraft     bool
+	prDescribeKeepTitle bool
+)
+
+var prDescribeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "Draft the pull request title and description with AI",
+	Long: `Draft a title and a description (summary and test plan) for the pull request
+of the current branch from its commits and its diff against the base branch,
+then update the open pull request, or open one when there is none.
+
+The draft is printed and needs a confirmation before it replaces the current
+description. Without an OpenAI API key, or with --offline, the draft is made
+from the commit subjects and the changed files instead.
+
+Example:
+  githelper pr describe --dry-run     # Only print the draft
+  githelper pr describe --keep-title
+  githelper pr describe --base release/2.0 --draft`,
+	Args: cobra.NoArgs,
+	RunE: runPRDescribe,
+}
+
+func init() {
+	prCmd.AddCommand(prDescribeCmd)
+	prDescribeCmd.Flags().StringVar(&prDescribeBase, "base", "", "branch the pull request merges into (default: the default branch)")
+	prDescribeCmd.Flags().BoolVar(&prDescribeDraft, "draft", false, "open the pull request as a draft when there is none")
+	prDescribeCmd.Flags().BoolVar(&prDescribeKeepTitle, "keep-title", false, "only replace the description of an existing pull request")
+	prDescribeCmd.Flags().BoolVar(&offlineAI, "offline", false, "draft from the commits and diff stats without calling the AI provider")
+	prDescribeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the draft without changing the pull request")
+}
+
+func runPRDescribe(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return err
+	}
+	if branch == "" || branch == "HEAD" {
+		return fmt.Errorf("HEAD is detached. Switch to the branch of the pull request")
+	}
+	base := prDescribeBase
+	if base == "" {
+		if base, err = defaultBranch(); err != nil {
+			return fmt.Errorf("failed to find the default branch: %w", err)
+		}
+	}
+	if branch == base {
+		return fmt.Errorf("you are on %s, switch to the branch of the pull request", base)
+	}
+	baseRef := "origin/" + base
+	if gitCommand("rev-parse", "--verify", "-q", baseRef).Run() != nil {
+		baseRef = base
+	}
+
+	subjects, log, err := branchCommits(baseRef)
+	if err != nil {
+		return err
+	}
+	if len(subjects) == 0 {
+		return fmt.Errorf("%s has no commits that aren't in %s", branch, baseRef)
+	}
+	ui.Stepf("📝 Drafting a description from %d commit(s) on %s since %s", len(subjects), branch, baseRef)
+	desc, err := describeBranch(baseRef, subjects, log)
+	if err != nil {
+		return err
+	}
+
+	ui.Printf("\n%s\n\n%s\n", desc.Title, desc.Body())
+	if dryRun {
+		ui.Step("🔍 Dry run: the pull request was not changed")
+		return nil
+	}
+
+	client, owner, name, err := originClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	number, err := client.FindPullRequest(ctx, owner, name, owner, branch)
+	if errors.Is(err, github.ErrUnauthorized) {
+		return errors.New(i18n.T("error.not_authorized"))
+	} else if errors.Is(err, github.ErrNoPullRequest) {
+		if gitCommand("rev-parse", "--verify", "-q", "origin/"+branch).Run() != nil {
+			return fmt.Errorf("%s isn't on origin yet. Push it first with 'git push -u origin %s'", branch, branch)
+		}
+		ui.Stepf("🆕 Opening a pull request of %s into %s", branch, base)
+		if !confirmAction() {
+			ui.Error("Operation cancelled")
+			return nil
+		}
+		number, url, err := client.CreatePullRequest(ctx, owner, name, branch, base, desc.Title, desc.Body(), prDescribeDraft)
+		if err != nil {
+			return fmt.Errorf("failed to open pull request: %w", err)
+		}
+		ui.Successf("Opened #%d: %s", number, url)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to find pull request: %w", err)
+	}
+
+	title := desc.Title
+	if prDescribeKeepTitle {
+		title = ""
+		ui.Stepf("✏️  Replacing the description of #%d", number)
+	} else {
+		ui.Stepf("✏️  Replacing the title and description of #%d", number)
+	}
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+	url, err := client.UpdatePullRequest(ctx, owner, name, number, title, desc.Body())
+	if err != nil {
+		return fmt.Errorf("failed to update pull request: %w", err)
+	}
+	ui.Successf("Updated #%d: %s", number, url)
+	return nil
+}
+
+// branchCommits returns the subjects of the commits of HEAD that aren't in
+// base, oldest first, and their log with full messages
+func branchCommits(base string) ([]string, string, error) {
+	output, err := gitCommand("log", "--reverse", "--format=%s", base+"..HEAD").Output()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list commits since %s: %w", base, err)
+	}
+	var subjects []string
+	for _, subject := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if subject != "" {
+			subjects = append(subjects, subject)
+		}
+	}
+	log, err := gitCommand("log", "--reverse", "--format=%h %B", base+"..HEAD").Output()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read commits since %s: %w", base, err)
+	}
+	return subjects, strings.TrimSpace(string(log)), nil
+}
+
+// describeBranch drafts the pull request description with the AI provider,
+// and falls back to an offline draft when it cannot be reached
+func describeBranch(base string, subjects []string, log string) (*ai.PRDescription, error) {
+	if offlineAI {
+		return offlinePRDescription(base, subjects)
+	}
+	apiKey := viper.GetString("openai_api_key")
+	if apiKey == "" {
+		ui.Info("No OpenAI API key in config, drafting from the commits and changed files")
+		return offlinePRDescription(base, subjects)
+	}
+
+	diff, err := gitCommand("diff", base+"...HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff against %s: %w", base, err)
+	}
+	desc, err := ai.NewPRGenerator(apiKey).GeneratePRDescription(log, string(diff))
+	if err != nil {
+		ui.Warnf("AI generation failed: %v", err)
+		ui.Println("Falling back to a description based on the commits and changed files")
+		return offlinePRDescription(base, subjects)
+	}
+	return desc, nil
+}
+
+func offlinePRDescription(base string, subjects []string) (*ai.PRDescription, error) {
+	numstat, err := gitCommand("diff", "--numstat", base+"...HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff stats: %w", err)
+	}
+	nameStatus, err := gitCommand("diff", "--name-status", base+"...HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diff status: %w", err)
+	}
+	return ai.OfflinePRDescription(subjects, ai.ParseNumstat(string(numstat), string(nameStatus))), nil
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/github/githubtest"
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintPRStatus(t *testing.T) {
+	mergeable := true
+	status := &github.PullRequestStatus{
+		Number: 42, Title: "Add feature", State: "open",
+		Base: "main", Head: "feature", URL: "https://github.com/o/r/pull/42",
+		Mergeable: &mergeable, MergeableState: "blocked",
+		Reviews:            []github.Review{{User: "alice", State: "APPROVED"}, {User: "bob", State: "CHANGES_REQUESTED"}},
+		RequestedReviewers: []string{"@core"},
+		Checks: []github.Check{
+			{Name: "build", State: github.CheckSuccess},
+			{Name: "test", State: github.CheckFailure, URL: "https://ci/test"},
+		},
+	}
+
+	var out bytes.Buffer
+	printPRStatus(&out, status)
+	text := out.String()
+	assert.Contains(t, text, "#42 Add feature [open]")
+	assert.Contains(t, text, "✅ alice (approved)")
+	assert.Contains(t, text, "❌ bob (changes requested)")
+	assert.Contains(t, text, "⏳ @core (requested)")
+	assert.Contains(t, text, "Checks: 1 passed, 0 pending, 1 failed")
+	assert.Contains(t, text, "❌ test - https://ci/test")
+	assert.Contains(t, text, "Blocked by branch protection")
+}
+
+func TestChooseMergeMethod(t *testing.T) {
+	method, err := chooseMergeMethod("", []string{"squash"})
+	require.NoError(t, err)
+	assert.Equal(t, "squash", method)
+
+	method, err = chooseMergeMethod("rebase", []string{"merge", "rebase"})
+	require.NoError(t, err)
+	assert.Equal(t, "rebase", method)
+
+	_, err = chooseMergeMethod("merge", []string{"squash"})
+	assert.ErrorContains(t, err, "not allowed")
+
+	// Several methods and no terminal to ask
+	_, err = chooseMergeMethod("", []string{"merge", "squash"})
+	assert.ErrorContains(t, err, "pass --method")
+}
+
+func TestDescribeBranchOffline(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	runGit(t, tmpDir, "commit", "-m", "base")
+	runGit(t, tmpDir, "branch", "-M", "main")
+	runGit(t, tmpDir, "checkout", "-b", "feature")
+	require.NoError(t, os.WriteFile("cache.go", []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile("cache_test.go", []byte("package main\n"), 0644))
+	runGit(t, tmpDir, "add", ".")
+	runGit(t, tmpDir, "commit", "-m", "Add cache", "-m", "Keeps results in memory.")
+
+	subjects, log, err := branchCommits("main")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Add cache"}, subjects)
+	assert.Contains(t, log, "Keeps results in memory.")
+
+	offlineAI = true
+	defer func() { offlineAI = false }()
+	desc, err := describeBranch("main", subjects, log)
+	require.NoError(t, err)
+	assert.Equal(t, "Add cache", desc.Title)
+	assert.Contains(t, desc.Summary, "2 file(s) changed, +2 -0")
+	assert.Equal(t, "- Updated tests: `cache_test.go`", desc.TestPlan)
+}
+
+func TestPRMerge(t *testing.T) {
+	server := fakeGitHub(t)
+	repo := server.AddRepo("octo/app")
+	repo.MergeMethods = []string{"squash", "rebase"}
+
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	r.Git("remote", "add", "origin", "https://"+githubtest.Hostname+"/octo/app.git")
+	r.Checkout("feature")
+	head := r.Commit("Add feature", "feature.txt", "feature\n")
+	pr := repo.AddPull(githubtest.PullRequest{Title: "Add feature", Head: "feature", HeadSHA: head})
+
+	// Failed checks stop the merge
+	repo.CheckRuns[head] = []githubtest.CheckRun{{Name: "test", Status: "completed", Conclusion: "failure"}}
+	_, _, err := execute(t, "pr", "merge", "--method", "squash", "--yes")
+	assert.ErrorContains(t, err, "1 check(s) failed")
+	assert.False(t, pr.Merged)
+
+	repo.CheckRuns[head] = []githubtest.CheckRun{{Name: "test", Status: "completed", Conclusion: "success"}}
+	stdout, _, err := execute(t, "pr", "merge", "--method", "squash", "--delete-branch", "--yes")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Merged #1")
+	assert.True(t, pr.Merged)
+	assert.Equal(t, "squash", pr.MergeMethod)
+	assert.Equal(t, []string{"feature"}, repo.DeletedBranches)
+	assert.Equal(t, "main", r.Branch())
+	assert.False(t, r.Exists("refs/heads/feature"))
+}
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/hooks"
+	"github.com/EndlessUphill/git-helper/internal/secrets"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Outcomes of a precheck
+const (
+	precheckPass = "pass"
+	precheckWarn = "warn"
+	precheckFail = "fail"
+)
+
+// debugRules find statements left over from debugging; precheck.debug_patterns
+// adds to them
+var debugRules = []secrets.Rule{
+	{ID: "console", Description: "console output", Pattern: regexp.MustCompile(`\bconsole\.(log|debug|trace)\(`)},
+	{ID: "debugger", Description: "debugger statement", Pattern: regexp.MustCompile(`^\s*debugger;?\s*$`)},
+	{ID: "pdb", Description: "Python breakpoint", Pattern: regexp.MustCompile(`\b(pdb\.set_trace|breakpoint)\(\)|^\s*import i?pdb\b`)},
+	{ID: "pry", Description: "Ruby breakpoint", Pattern: regexp.MustCompile(`\b(binding\.pry|byebug)\b`)},
+	{ID: "dump", Description: "PHP dump", Pattern: regexp.MustCompile(`\b(var_dump|print_r)\(`)},
+	{ID: "dbg", Description: "Rust dbg!", Pattern: regexp.MustCompile(`\bdbg!\(`)},
+}
+
+// todoRule finds notes about unfinished work
+var todoRule = secrets.Rule{ID: "todo", Description: "TODO", Pattern: regexp.MustCompile(`\b(TODO|FIXME|XXX|HACK)\b`)}
+
+var (
+	precheckBase         string
+	precheckSkipCommands bool
+)
+
+var precheckCmd = &cobra.Command{
+	Use:   "precheck",
+	Short: "Check a branch is ready for a pull request",
+	Long: `Run the checks a reviewer would, before opening a pull request, and print a
+checklist. The branch is compared with its base, the default branch unless
+--base is given (origin/<base> when there is one):
+
+  - it merges into the base without conflicts
+  - the commit messages follow the history policy (see 'githelper lint-history')
+  - the diff adds no debug statements, such as console.log or breakpoint()
+  - the diff adds no secrets
+  - the TODOs the diff adds, as a warning
+  - the commands of precheck.commands pass, e.g. linters and tests
+
+The command fails when a check fails, so it can gate scripts. The commands
+of precheck.commands in the repository's .githelper.yaml come with the code,
+so they only run once you have read them and run 'githelper hooks trust'.
+
+  precheck:
+    commands: ["go vet ./...", "go test ./..."]
+    debug_patterns: ['log\.Printf\("DEBUG']
+
+Example:
+  githelper precheck
+  githelper precheck --base release/2.0
+  githelper precheck --skip-commands   # Only the checks of the diff`,
+	Args: cobra.NoArgs,
+	RunE: runPrecheck,
+}
+
+func init() {
+	rootCmd.AddCommand(precheckCmd)
+	precheckCmd.Flags().StringVar(&precheckBase, "base", "", "branch the pull request goes into (default: the default branch)")
+	precheckCmd.Flags().BoolVar(&precheckSkipCommands, "skip-commands", false, "don't run precheck.commands")
+}
+
+// precheckResult is an item of the precheck checklist
+type precheckResult struct {
+	Name    string
+	Outcome string
+	Details []string
+}
+
+func runPrecheck(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	base, err := precheckBaseRef()
+	if err != nil {
+		return err
+	}
+
+	commits, err := historyCommits(base+"..HEAD", loadHistoryPolicy().Signed)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		return fmt.Errorf("no commits to open a pull request with: HEAD is already in %s", base)
+	}
+	stat, err := gitOutput("diff", "--shortstat", base+"...HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to diff against %s: %w", base, err)
+	}
+	patch, err := gitOutput("diff", "--no-color", "--no-ext-diff", base+"...HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to diff against %s: %w", base, err)
+	}
+	ui.Printf("📋 Precheck against %s: %d commit(s), %s\n\n", base, len(commits), strings.TrimSpace(string(stat)))
+
+	results := []precheckResult{
+		checkMergesCleanly(base),
+		checkCommitMessages(commits),
+	}
+	debug, err := debugPatterns()
+	if err != nil {
+		return err
+	}
+	results = append(results,
+		checkAddedLines(patch, "No debug statements added", precheckFail, debug),
+		checkAddedSecrets(patch),
+		checkAddedLines(patch, "No TODOs added", precheckWarn, []secrets.Rule{todoRule}),
+		checkWorkingTree(),
+	)
+	for _, result := range results {
+		printPrecheckResult(result)
+	}
+	if !precheckSkipCommands {
+		if err := warnUntrustedRepoSetting("precheck.commands", "precheck commands"); err != nil {
+			return err
+		}
+		for _, line := range viper.GetStringSlice("precheck.commands") {
+			result := checkCommand(line)
+			printPrecheckResult(result)
+			results = append(results, result)
+		}
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Outcome == precheckFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	ui.Success("\nReady for review!")
+	return nil
+}
+
+// precheckBaseRef returns the branch to compare with: --base or the
+// default branch, on origin when it is there
+func precheckBaseRef() (string, error) {
+	base := precheckBase
+	if base == "" {
+		var err error
+		if base, err = defaultBranch(); err != nil {
+			return "", fmt.Errorf("failed to find the default branch, pass --base: %w", err)
+		}
+	}
+	if gitCommand("rev-parse", "--verify", "-q", "refs/remotes/origin/"+base).Run() == nil {
+		return "origin/" + base, nil
+	}
+	if gitCommand("rev-parse", "--verify", "-q", base+"^{commit}").Run() != nil {
+		return "", fmt.Errorf("branch '%s' does not exist", base)
+	}
+	return base, nil
+}
+
+func checkMergesCleanly(base string) precheckResult {
+	result := precheckResult{Name: "Merges cleanly into " + base, Outcome: precheckPass}
+	conflicts, _, err := predictConflicts(base, "HEAD")
+	switch {
+	case err != nil:
+		result.Outcome = precheckWarn
+		result.Details = []string{err.Error()}
+	case len(conflicts) > 0:
+		result.Outcome = precheckFail
+		result.Details = append(conflicts, fmt.Sprintf("rebase onto %s and resolve them ('githelper resolve')", base))
+	}
+	return result
+}
+
+func checkCommitMessages(commits []HistoryCommit) precheckResult {
+	result := precheckResult{Name: "Commit messages follow the history policy", Outcome: precheckPass}
+	policy := loadHistoryPolicy()
+	for _, c := range commits {
+		for _, problem := range lintCommit(c, policy) {
+			result.Outcome = precheckFail
+			result.Details = append(result.Details, fmt.Sprintf("%s %s: %s", c.Hash[:8], truncate(c.Subject, 40), problem))
+		}
+	}
+	return result
+}
+
+// checkAddedLines looks for the rules in the lines the patch adds; finding
+// one makes the check end with outcome
+func checkAddedLines(patch []byte, name, outcome string, rules []secrets.Rule) precheckResult {
+	result := precheckResult{Name: name, Outcome: precheckPass}
+	found, err := secrets.ScanDiffLines(bytes.NewReader(patch), func(line string) []secrets.Finding {
+		for _, rule := range rules {
+			if rule.Pattern.MatchString(line) {
+				return []secrets.Finding{{Rule: rule, Match: strings.TrimSpace(line)}}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return precheckResult{Name: name, Outcome: precheckWarn, Details: []string{err.Error()}}
+	}
+	for _, finding := range found {
+		result.Outcome = outcome
+		result.Details = append(result.Details, fmt.Sprintf("%s: %s", finding.Location(), truncate(finding.Match, 60)))
+	}
+	return result
+}
+
+func checkAddedSecrets(patch []byte) precheckResult {
+	result := precheckResult{Name: "No secrets added", Outcome: precheckPass}
+	found, err := secrets.ScanDiff(bytes.NewReader(patch))
+	if err != nil {
+		return precheckResult{Name: result.Name, Outcome: precheckWarn, Details: []string{err.Error()}}
+	}
+	for _, finding := range found {
+		result.Outcome = precheckFail
+		result.Details = append(result.Details, fmt.Sprintf("%s: %s (%s)", finding.Location(), finding.Rule.Description, finding.Match))
+	}
+	return result
+}
+
+func checkWorkingTree() precheckResult {
+	result := precheckResult{Name: "No uncommitted changes", Outcome: precheckPass}
+	output, err := gitOutput("status", "--porcelain", "--untracked-files=no")
+	if err == nil && len(bytes.TrimSpace(output)) > 0 {
+		result.Outcome = precheckWarn
+		result.Details = []string{"they aren't checked and won't be in the pull request"}
+	}
+	return result
+}
+
+// checkCommand runs a command of precheck.commands in a shell
+func checkCommand(line string) precheckResult {
+	ui.Stepf("🧪 Running %s...", line)
+	result := precheckResult{Name: line + " passes", Outcome: precheckPass}
+	command := hooks.Command(commandCtx, line)
+	// Commands are written for the top of the repository
+	if top, err := gitOutput("rev-parse", "--show-toplevel"); err == nil {
+		command.Dir = strings.TrimSpace(string(top))
+	}
+	output, err := command.CombinedOutput()
+	if err != nil {
+		result.Outcome = precheckFail
+		lines := splitLines(string(output))
+		if len(lines) > 20 {
+			lines = append([]string{"..."}, lines[len(lines)-20:]...)
+		}
+		result.Details = append(lines, err.Error())
+	}
+	return result
+}
+
+// debugPatterns returns debugRules and the patterns of precheck.debug_patterns
+func debugPatterns() ([]secrets.Rule, error) {
+	rules := append([]secrets.Rule(nil), debugRules...)
+	for _, pattern := range viper.GetStringSlice("precheck.debug_patterns") {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid precheck.debug_patterns entry '%s': %w", pattern, err)
+		}
+		rules = append(rules, secrets.Rule{ID: "custom", Description: pattern, Pattern: re})
+	}
+	return rules, nil
+}
+
+func printPrecheckResult(result precheckResult) {
+	icon := "✅"
+	switch result.Outcome {
+	case precheckWarn:
+		icon = "⚠️ "
+	case precheckFail:
+		icon = "❌"
+	}
+	ui.Printf("%s %s\n", icon, result.Name)
+	for _, detail := range result.Details {
+		ui.Printf("      %s\n", detail)
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/secrets"
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAddedLines(t *testing.T) {
+	patch := []byte(`diff --git a/app.js b/app.js
+--- a/app.js
++++ b/app.js
+@@ -10,2 +10,4 @@ function start() {
+   init();
++  console.log("here", state);
++  // Logs the state
+   run();
+`)
+	result := checkAddedLines(patch, "No debug statements added", precheckFail, debugRules)
+	assert.Equal(t, precheckFail, result.Outcome)
+	assert.Equal(t, []string{`app.js:11: console.log("here", state);`}, result.Details)
+
+	result = checkAddedLines(patch, "No TODOs added", precheckWarn, []secrets.Rule{todoRule})
+	assert.Equal(t, precheckPass, result.Outcome)
+	assert.Empty(t, result.Details)
+}
+
+func TestPrecheck(t *testing.T) {
+	defer viper.Reset()
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "app.js", "start();\n")
+	r.Checkout("feature")
+	r.Commit("Add search", "search.js", "search();\n")
+
+	stdout, _, err := execute(t, "precheck", "--base", "main")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Precheck against main: 1 commit(s), 1 file changed, 1 insertion(+)")
+	assert.Contains(t, stdout, "✅ Merges cleanly into main")
+	assert.Contains(t, stdout, "✅ No debug statements added")
+
+	viper.Set("precheck.commands", []string{"true", "echo lint failed; exit 3"})
+	r.Commit("Debug search", "search.js", "search();\nconsole.log(results); // TODO remove\n")
+	stdout, _, err = execute(t, "precheck", "--base", "main")
+	assert.EqualError(t, err, "2 of 8 checks failed")
+	assert.Contains(t, stdout, "❌ No debug statements added\n      search.js:2: console.log(results); // TODO remove\n")
+	assert.Contains(t, stdout, "⚠️  No TODOs added")
+	assert.Contains(t, stdout, "✅ true passes")
+	assert.Contains(t, stdout, "❌ echo lint failed; exit 3 passes\n      lint failed\n")
+
+	_, _, err = execute(t, "precheck", "--base", "main", "--skip-commands")
+	assert.EqualError(t, err, "1 of 6 checks failed")
+}
+
+func TestRepoPrecheckCommandsNeedTrust(t *testing.T) {
+	defer viper.Reset()
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", ".githelper.yaml", "precheck:\n  commands: [\"touch ran.txt\"]\n")
+	r.Checkout("feature")
+	r.Commit("Add search", "search.js", "search();\n")
+
+	_, stderr, err := execute(t, "precheck", "--base", "main")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "githelper hooks trust")
+	assert.NoFileExists(t, r.Path("ran.txt"))
+
+	_, _, err = execute(t, "hooks", "trust")
+	require.NoError(t, err)
+	stdout, _, err := execute(t, "precheck", "--base", "main")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "✅ touch ran.txt passes")
+	assert.FileExists(t, r.Path("ran.txt"))
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var preflightFetch bool
+
+var preflightCmd = &cobra.Command{
+	Use:   "preflight <target-branch>",
+	Short: "Predict merge conflicts with another branch",
+	Long: `Check whether the current branch would conflict with a target branch,
+without touching the working tree.
+
+The merge is done in memory with 'git merge-tree' (git 2.38 or newer), so you
+know which files conflict before opening a pull request or starting a rebase.
+If the target has no local branch, origin/<target-branch> is used.
+The command exits with an error when conflicts are found, so it can be used
+in scripts.
+
+Example:
+  githelper preflight main            # Would my branch merge cleanly into main?
+  githelper preflight main --fetch    # Fetch origin first
+  githelper preflight origin/release  # Compare with a remote-tracking branch`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPreflight,
+}
+
+func init() {
+	rootCmd.AddCommand(preflightCmd)
+	preflightCmd.Flags().BoolVar(&preflightFetch, "fetch", false, "fetch origin before checking")
+}
+
+func runPreflight(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	if preflightFetch {
+		ui.Step("🔄 Fetching origin...")
+		fetchCmd := gitCommand("fetch", "origin")
+		fetchCmd.Stderr = os.Stderr
+		if err := fetchCmd.Run(); err != nil {
+			return fmt.Errorf("failed to fetch origin: %w", err)
+		}
+	}
+
+	source, err := getCurrentBranch()
+	if err != nil {
+		return err
+	}
+	if source == "" || source == "HEAD" {
+		source = "HEAD"
+	}
+
+	target := args[0]
+	if gitCommand("rev-parse", "--verify", "-q", target+"^{commit}").Run() != nil {
+		if gitCommand("rev-parse", "--verify", "-q", "refs/remotes/origin/"+target).Run() != nil {
+			return fmt.Errorf("branch '%s' does not exist", target)
+		}
+		target = "origin/" + target
+	}
+
+	ui.Stepf("🔍 Checking whether %s merges cleanly into %s...", source, target)
+	ahead, behind, err := aheadBehind(source, target)
+	if err != nil {
+		return err
+	}
+	ui.Printf("  %s is %d commit(s) ahead and %d behind %s\n", source, ahead, behind, target)
+
+	if ahead == 0 {
+		ui.Successf("Nothing to merge: %s has no commits that %s doesn't", source, target)
+		return nil
+	}
+
+	conflicts, messages, err := predictConflicts(target, source)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) == 0 {
+		ui.Successf("No conflicts: %s merges cleanly into %s", source, target)
+		return nil
+	}
+
+	ui.Errorf("\n%d file(s) would conflict:", len(conflicts))
+	for _, file := range conflicts {
+		ui.Printf("  %s\n", file)
+	}
+	if len(messages) > 0 {
+		ui.Println("\nDetails:")
+		for _, message := range messages {
+			ui.Printf("  %s\n", message)
+		}
+	}
+	ui.Stepf("\n💡 Merge or rebase onto %s locally and resolve them ('githelper resolve') before opening a PR", target)
+	return fmt.Errorf("%d file(s) would conflict with %s", len(conflicts), target)
+}
+
+// predictConflicts merges source into target in memory and returns the
+// conflicted files and git's CONFLICT messages
+func predictConflicts(target, source string) ([]string, []string, error) {
+	output, err := gitCommand("merge-tree", "--write-tree", "--name-only", target, source).Output()
+	if err != nil {
+		// Exit code 1 means the merge has conflicts; anything else is a failure
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return nil, nil, fmt.Errorf("failed to simulate merge (needs git 2.38 or newer): %w", err)
+		}
+	}
+
+	// Output is the tree, the conflicted files, a blank line and the messages
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	var conflicts, messages []string
+	inMessages := false
+	for _, line := range lines[1:] {
+		switch {
+		case line == "":
+			inMessages = true
+		case !inMessages:
+			conflicts = append(conflicts, line)
+		case strings.HasPrefix(line, "CONFLICT"):
+			messages = append(messages, line)
+		}
+	}
+	return conflicts, messages, nil
+}
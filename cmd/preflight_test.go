@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredictConflicts(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "base")
+	runGit(t, tmpDir, "branch", "target")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.WriteFile("other.txt", []byte("new file"), 0644))
+	runGit(t, tmpDir, "add", "other.txt")
+	runGit(t, tmpDir, "commit", "-m", "add other")
+
+	conflicts, _, err := predictConflicts("target", "HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	require.NoError(t, os.WriteFile("test.txt", []byte("ours"), 0644))
+	runGit(t, tmpDir, "commit", "-am", "ours")
+	runGit(t, tmpDir, "checkout", "-q", "target")
+	require.NoError(t, os.WriteFile("test.txt", []byte("theirs"), 0644))
+	runGit(t, tmpDir, "commit", "-am", "theirs")
+	runGit(t, tmpDir, "checkout", "-q", "-")
+
+	conflicts, messages, err := predictConflicts("target", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test.txt"}, conflicts)
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0], "CONFLICT (content)")
+
+	// The working tree is untouched
+	content, err := os.ReadFile("test.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "ours", string(content))
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/EndlessUphill/git-helper/internal/config"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var profileName string
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Switch between GitHub identities",
+	Long: `Manage named profiles for working with several GitHub identities, such
+as a work and a personal account.
+
+Each profile in the config can set its own token, default organization, host,
+SSH or HTTPS preference and commit identity:
+
+  profiles:
+    work:
+      github_token: "work-token"
+      default_org: acme
+      host: github.acme.com
+      use_ssh: true
+      user_name: Jane Doe
+      user_email: jane@acme.com
+    personal:
+      default_org: jane
+      user_email: jane@example.com
+
+The active profile is stored as 'profile' in ~/.githelper.yaml. Override it
+for a single command with --profile.
+
+Example:
+  githelper profile list            # Show profiles, marking the active one
+  githelper profile use work        # Make work the active profile
+  githelper --profile personal copy https://github.com/jane/repo --dest jane/copy`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configured profiles",
+	Args:  cobra.NoArgs,
+	RunE:  runProfileList,
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make a profile the active one",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProfileUse,
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd, profileUseCmd)
+}
+
+// configuredProfiles returns the profiles section of the config
+func configuredProfiles() (map[string]config.Profile, error) {
+	var profiles map[string]config.Profile
+	if err := viper.UnmarshalKey("profiles", &profiles); err != nil {
+		return nil, fmt.Errorf("invalid profiles configuration: %w", err)
+	}
+	return profiles, nil
+}
+
+// activeProfile returns the name of the profile selected with --profile,
+// GITHELPER_PROFILE or the config
+func activeProfile() string {
+	if profileName != "" {
+		return profileName
+	}
+	return viper.GetString("profile")
+}
+
+// applyProfile layers the active profile's settings over the top-level ones.
+// They are merged into the config layer, so flags and environment variables
+// still take precedence.
+func applyProfile() error {
+	name := activeProfile()
+	if name == "" {
+		return nil
+	}
+
+	profiles, err := configuredProfiles()
+	if err != nil {
+		return err
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return fmt.Errorf("profile '%s' not found in the config", name)
+	}
+
+	settings := make(map[string]interface{})
+	if profile.GithubToken != "" {
+		settings["github_token"] = profile.GithubToken
+	}
+	if profile.DefaultOrg != "" {
+		settings["default_org"] = profile.DefaultOrg
+	}
+	if profile.Host != "" {
+		settings["default_host"] = profile.Host
+	}
+	if profile.UseSSH != nil {
+		settings["use_ssh"] = *profile.UseSSH
+	}
+	if err := viper.MergeConfigMap(settings); err != nil {
+		return err
+	}
+
+	// Git reads the commit identity from the environment, which every git
+	// process started by githelper inherits
+	if profile.UserName != "" {
+		os.Setenv("GIT_AUTHOR_NAME", profile.UserName)
+		os.Setenv("GIT_COMMITTER_NAME", profile.UserName)
+	}
+	if profile.UserEmail != "" {
+		os.Setenv("GIT_AUTHOR_EMAIL", profile.UserEmail)
+		os.Setenv("GIT_COMMITTER_EMAIL", profile.UserEmail)
+	}
+	return nil
+}
+
+func runProfileList(cmd *cobra.Command, args []string) error {
+	profiles, err := configuredProfiles()
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		ui.Println("No profiles configured. Add them under 'profiles' in ~/.githelper.yaml")
+		return nil
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	active := activeProfile()
+	for _, name := range names {
+		profile := profiles[name]
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+
+		details := ""
+		if profile.UserEmail != "" {
+			details += " " + profile.UserEmail
+		}
+		if profile.DefaultOrg != "" {
+			details += " org:" + profile.DefaultOrg
+		}
+		if profile.Host != "" {
+			details += " host:" + profile.Host
+		}
+		ui.Printf("%s %-15s%s\n", marker, name, details)
+	}
+	return nil
+}
+
+func runProfileUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	profiles, err := configuredProfiles()
+	if err != nil {
+		return err
+	}
+	if _, ok := profiles[name]; !ok {
+		return fmt.Errorf("profile '%s' not found in the config", name)
+	}
+
+	// Always write to the user config: the active identity is personal
+	configLocal = false
+	file, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	if err := writeConfigValue(file, []string{"profile"}, name); err != nil {
+		return err
+	}
+
+	ui.Successf("Switched to profile '%s'", name)
+	return nil
+}
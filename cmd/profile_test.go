@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyProfile(t *testing.T) {
+	defer viper.Reset()
+	for _, name := range []string{"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_NAME", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(name, "")
+	}
+
+	viper.SetConfigType("yaml")
+	assert.NoError(t, viper.ReadConfig(strings.NewReader(`
+github_token: personal-token
+default_org: me
+profile: personal
+profiles:
+  personal: {}
+  work:
+    github_token: work-token
+    default_org: acme
+    host: github.acme.com
+    use_ssh: false
+    user_email: me@acme.com
+`)))
+
+	profileName = "work"
+	defer func() { profileName = "" }()
+	assert.NoError(t, applyProfile())
+
+	assert.Equal(t, "work-token", viper.GetString("github_token"))
+	assert.Equal(t, "acme", viper.GetString("default_org"))
+	assert.Equal(t, "github.acme.com", viper.GetString("default_host"))
+	assert.False(t, viper.GetBool("use_ssh"))
+	assert.Equal(t, "me@acme.com", os.Getenv("GIT_AUTHOR_EMAIL"))
+	assert.Equal(t, "me@acme.com", os.Getenv("GIT_COMMITTER_EMAIL"))
+
+	profileName = "missing"
+	assert.Error(t, applyProfile())
+}
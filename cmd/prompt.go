@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/viper"
+)
+
+// nonInteractive disables every prompt so commands can run in scripts and CI
+var nonInteractive bool
+
+// preselectedFile answers file selection prompts (--select-file)
+var preselectedFile string
+
+// preselectedItem answers commit, branch and worktree selection prompts
+// (--select)
+var preselectedItem string
+
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// isInteractive reports whether prompts can be shown: not disabled with
+// --non-interactive (or GITHELPER_NON_INTERACTIVE / CI), and stdin is a terminal
+func isInteractive() bool {
+	if nonInteractive || viper.GetBool("non_interactive") || os.Getenv("CI") == "true" {
+		return false
+	}
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// /dev/null is a character device too, but nobody is typing into it
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return true
+}
+
+// readInput prints a prompt and reads a line of input. In non-interactive
+// mode it returns an empty answer, which every prompt treats as "cancel" or
+// "use the default".
+func readInput(prompt string) string {
+	ui.Print(prompt)
+	if !isInteractive() {
+		ui.Println()
+		return ""
+	}
+
+	line, _ := stdinReader.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// readSecret reads input like readInput without echoing it, where the
+// terminal allows it
+func readSecret(prompt string) string {
+	if !isInteractive() || runtime.GOOS == "windows" {
+		return readInput(prompt)
+	}
+	stty := func(arg string) error {
+		cmd := exec.Command("stty", arg)
+		cmd.Stdin = os.Stdin
+		return cmd.Run()
+	}
+	if stty("-echo") != nil {
+		return readInput(prompt)
+	}
+	// Echo comes back even if the command is interrupted at the prompt
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-commandCtx.Done():
+			stty("echo")
+		case <-done:
+		}
+	}()
+	defer func() {
+		close(done)
+		stty("echo")
+		ui.Println()
+	}()
+	return readInput(prompt)
+}
+
+func confirmAction() bool {
+	if assumeYes {
+		ui.Println(i18n.T("prompt.confirm_assumed"))
+		return true
+	}
+	response := readInput(i18n.T("prompt.confirm"))
+	if response == "" && !isInteractive() {
+		ui.Info(i18n.T("prompt.pass_yes"))
+	}
+	return i18n.Yes(response)
+}
+
+// preselect returns the first of items that matches --select, or an error
+// naming what was listed when none does
+func preselect[T any](items []T, what string, matches func(T) bool) (T, error) {
+	for _, item := range items {
+		if matches(item) {
+			return item, nil
+		}
+	}
+	var none T
+	return none, fmt.Errorf("no %s matching '%s' found", what, preselectedItem)
+}
+
+// sameCommit reports whether two hashes, either of them abbreviated, name
+// the same commit
+func sameCommit(hash, selection string) bool {
+	if len(hash) < 4 || len(selection) < 4 {
+		return false
+	}
+	return strings.HasPrefix(hash, selection) || strings.HasPrefix(selection, hash)
+}
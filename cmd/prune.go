@@ -2,10 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -29,14 +28,18 @@ Useful when:
 Example:
   githelper prune              # Interactive branch cleanup
   githelper prune --force      # Delete without confirmation
-  githelper prune --main dev   # Use 'dev' as main branch`,
+  githelper prune --main dev   # Use 'dev' as main branch
+
+The main branch defaults to the repository's default branch (see
+'githelper default-branch').`,
 	RunE: runPrune,
 }
 
 func init() {
 	rootCmd.AddCommand(pruneCmd)
-	pruneCmd.Flags().StringVar(&mainBranch, "main", "main", "main branch name")
+	pruneCmd.Flags().StringVar(&mainBranch, "main", "", "main branch name (default is the repository's default branch)")
 	pruneCmd.Flags().BoolVar(&force, "force", false, "delete without confirmation")
+	pruneCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the git commands without running them")
 }
 
 func runPrune(cmd *cobra.Command, args []string) error {
@@ -44,35 +47,45 @@ func runPrune(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if mainBranch == "" {
+		branch, err := defaultBranch()
+		if err != nil {
+			return err
+		}
+		mainBranch = branch
+	}
+
+	if dryRun {
+		defer startDryRun()()
+	}
+
 	// Fetch and prune
-	fmt.Println("🔄 Fetching and pruning remote branches...")
-	fetchCmd := exec.Command("git", "fetch", "-p")
-	fetchCmd.Stderr = os.Stderr
-	if err := fetchCmd.Run(); err != nil {
+	ui.Step("🔄 Fetching and pruning remote branches...")
+	if err := gitRun("fetch", "-p"); err != nil {
 		return fmt.Errorf("failed to fetch and prune: %w", err)
 	}
 
 	// Get merged branches
-	branches, err := getMergedBranches()
+	branches, err := getMergedBranches(mainBranch)
 	if err != nil {
 		return err
 	}
 
 	if len(branches) == 0 {
-		fmt.Println("✅ No merged branches to clean up!")
+		ui.Success("No merged branches to clean up!")
 		return nil
 	}
 
 	// Show branches to delete
-	fmt.Println("\nMerged branches to delete:")
+	ui.Println("\nMerged branches to delete:")
 	for _, branch := range branches {
-		fmt.Printf("- %s\n", branch)
+		ui.Printf("- %s\n", branch)
 	}
 
 	// Confirm deletion
-	if !force {
+	if !force && !dryRun {
 		if !confirmAction() {
-			fmt.Println("❌ Operation cancelled")
+			ui.Error("Operation cancelled")
 			return nil
 		}
 	}
@@ -80,23 +93,26 @@ func runPrune(cmd *cobra.Command, args []string) error {
 	// Delete branches
 	deleted := 0
 	for _, branch := range branches {
-		fmt.Printf("🗑️  Deleting branch '%s'...\n", branch)
-		deleteCmd := exec.Command("git", "branch", "-d", branch)
-		deleteCmd.Stderr = os.Stderr
-		if err := deleteCmd.Run(); err != nil {
-			fmt.Printf("⚠️  Failed to delete branch '%s': %v\n", branch, err)
+		ui.Stepf("🗑️  Deleting branch '%s'...", branch)
+		if err := gitRun("branch", "-d", branch); err != nil {
+			ui.Warnf("Failed to delete branch '%s': %v", branch, err)
 			continue
 		}
 		deleted++
 	}
 
-	fmt.Printf("✅ Successfully deleted %d merged branch(es)!\n", deleted)
+	if dryRun {
+		ui.Step("🔍 Dry run: nothing was changed")
+		return nil
+	}
+	ui.Successf("Successfully deleted %d merged branch(es)!", deleted)
 	return nil
 }
 
-func getMergedBranches() ([]string, error) {
-	cmd := exec.Command("git", "branch", "--merged", mainBranch)
-	output, err := cmd.Output()
+// getMergedBranches lists the local branches merged into main that prune may
+// delete
+func getMergedBranches(main string) ([]string, error) {
+	output, err := gitOutput("branch", "--merged", main)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list merged branches: %w", err)
 	}
@@ -105,8 +121,8 @@ func getMergedBranches() ([]string, error) {
 	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
 	for _, line := range lines {
 		branch := strings.TrimSpace(line)
-		// Skip current and main branches
-		if branch != "" && !strings.HasPrefix(branch, "*") && branch != mainBranch {
+		// Skip current, main and protected branches
+		if branch != "" && !strings.HasPrefix(branch, "*") && branch != main && !isProtectedBranch(branch) {
 			branches = append(branches, branch)
 		}
 	}
@@ -3,9 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -60,12 +60,12 @@ func runPruneRemotes(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(remotes) == 0 {
-		fmt.Println("No Git remotes configured.")
+		ui.Println("No Git remotes configured.")
 		return nil
 	}
 
 	// Check each remote
-	fmt.Println("🔍 Checking remotes...")
+	ui.Step("🔍 Checking remotes...")
 	for i := range remotes {
 		remotes[i].Reachable = checkRemote(remotes[i].Name)
 	}
@@ -73,26 +73,26 @@ func runPruneRemotes(cmd *cobra.Command, args []string) error {
 	// Show status
 	unreachable := listUnreachableRemotes(remotes)
 	if len(unreachable) == 0 {
-		fmt.Println("✅ All remotes are reachable!")
+		ui.Success("All remotes are reachable!")
 		return nil
 	}
 
 	if dryRun {
-		fmt.Println("\nThe following remotes would be removed:")
+		ui.Println("\nThe following remotes would be removed:")
 		for _, remote := range unreachable {
-			fmt.Printf("- %s (%s)\n", remote.Name, remote.URL)
+			ui.Printf("- %s (%s)\n", remote.Name, remote.URL)
 		}
 		return nil
 	}
 
 	// Confirm removal
 	if !forceMode {
-		fmt.Println("\n⚠️  The following remotes will be removed:")
+		ui.Warn("\nThe following remotes will be removed:")
 		for _, remote := range unreachable {
-			fmt.Printf("- %s (%s)\n", remote.Name, remote.URL)
+			ui.Printf("- %s (%s)\n", remote.Name, remote.URL)
 		}
 		if !confirmAction() {
-			fmt.Println("❌ Operation cancelled")
+			ui.Error("Operation cancelled")
 			return nil
 		}
 	}
@@ -101,19 +101,19 @@ func runPruneRemotes(cmd *cobra.Command, args []string) error {
 	removed := 0
 	for _, remote := range unreachable {
 		if err := removeRemote(remote.Name); err != nil {
-			fmt.Printf("⚠️  Failed to remove remote '%s': %v\n", remote.Name, err)
+			ui.Warnf("Failed to remove remote '%s': %v", remote.Name, err)
 			continue
 		}
 		removed++
-		fmt.Printf("🗑️  Removed remote '%s'\n", remote.Name)
+		ui.Stepf("🗑️  Removed remote '%s'", remote.Name)
 	}
 
-	fmt.Printf("\n✅ Removed %d unreachable remote(s)\n", removed)
+	ui.Successf("\nRemoved %d unreachable remote(s)", removed)
 	return nil
 }
 
 func getRemotes() ([]Remote, error) {
-	cmd := exec.Command("git", "remote", "-v")
+	cmd := gitCommand("remote", "-v")
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list remotes: %w", err)
@@ -141,7 +141,7 @@ func getRemotes() ([]Remote, error) {
 }
 
 func checkRemote(name string) bool {
-	cmd := exec.Command("git", "ls-remote", "--exit-code", name)
+	cmd := gitCommand("ls-remote", "--exit-code", name)
 	cmd.Stderr = os.Stderr
 	return cmd.Run() == nil
 }
@@ -157,7 +157,7 @@ func listUnreachableRemotes(remotes []Remote) []Remote {
 }
 
 func removeRemote(name string) error {
-	cmd := exec.Command("git", "remote", "remove", name)
+	cmd := gitCommand("remote", "remove", name)
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 } 
\ No newline at end of file
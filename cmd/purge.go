@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -14,28 +15,42 @@ var (
 )
 
 var purgeCmd = &cobra.Command{
-	Use:   "purge [file]",
+	Use:   "purge [path...]",
 	Short: "Remove sensitive files from git history",
 	Long: `Completely remove a file from git history.
 
 This command helps you remove sensitive files (like API keys) from your git history.
 It will:
 1. Let you select a file to remove
-2. Remove all traces of the file from git history
-3. Optionally force push the changes
+2. Show how many files, bytes and commits the removal affects
+3. Remove all traces of the file from git history
+4. Look for the secrets it contained in other files, commit messages and
+   the working tree (--no-scan skips this)
+5. List what to do to rotate them, with the steps for the AWS, Google Cloud,
+   GitHub and other credentials it recognizes (--open-docs opens their guides)
+6. Optionally force push the changes with a lease and print what your
+   teammates need to run (--push)
+
+Paths can be files, directories (everything in them is removed) or glob
+patterns such as '*.pem' or 'config/**/*.env'. Quote globs so your shell
+doesn't expand them; globs without a slash match at any depth.
 
 ⚠️  WARNING: This rewrites git history! Use with caution, especially on shared repositories.
 
 Example:
   githelper purge                  # Interactive file selection
   githelper purge config.json      # Remove specific file
-  githelper purge --force-push     # Also force push changes`,
+  githelper purge secrets/ '*.pem' # Remove a directory and all keys
+  githelper purge --push           # Also force push changes
+  githelper purge .env --push --notice NOTICE.md  # And write a notice for the team
+  githelper purge config.json --gc # Shrink the repository afterwards`,
 	RunE: runPurge,
 }
 
 func init() {
 	rootCmd.AddCommand(purgeCmd)
-	purgeCmd.Flags().BoolVar(&forcePush, "force-push", false, "force push changes after purging")
+	purgeCmd.Flags().BoolVar(&forcePush, "force-push", false, "same as --push")
+	purgeCmd.Flags().StringVar(&preselectedFile, "select-file", "", "file to remove without prompting")
 }
 
 func runPurge(cmd *cobra.Command, args []string) error {
@@ -43,64 +58,52 @@ func runPurge(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	var fileToPurge string
-	var err error
+	if len(args) == 0 && preselectedFile != "" {
+		args = []string{preselectedFile}
+	}
 
-	if len(args) > 0 {
-		fileToPurge = args[0]
-	} else {
+	if len(args) == 0 {
 		// Interactive file selection
-		fileToPurge, err = selectFile()
+		fileToPurge, err := selectFile()
 		if err != nil {
 			return err
 		}
 		if fileToPurge == "" {
 			return fmt.Errorf("no file selected")
 		}
+		args = []string{fileToPurge}
 	}
 
-	// Confirm action
-	fmt.Printf("\n⚠️  WARNING: This will permanently remove '%s' from git history!\n", fileToPurge)
-	fmt.Println("This action CANNOT be undone and will rewrite git history.")
-	if !confirmAction() {
-		fmt.Println("❌ Operation cancelled")
-		return nil
+	// Read what the files contained while it is still in history
+	var purged *purgedSecrets
+	if !purgeNoScan {
+		var err error
+		if purged, err = collectPurgedSecrets(args); err != nil {
+			return err
+		}
 	}
 
-	// Remove file from git history
-	fmt.Printf("\n🚨 Removing '%s' from git history...\n", fileToPurge)
-	filterCmd := exec.Command("git", "filter-branch", "--force",
-		"--index-filter", fmt.Sprintf("git rm --cached --ignore-unmatch %s", fileToPurge),
-		"--prune-empty", "--tag-name-filter", "cat", "--", "--all")
-	
-	filterCmd.Stdout = os.Stdout
-	filterCmd.Stderr = os.Stderr
-	
-	if err := filterCmd.Run(); err != nil {
-		return fmt.Errorf("failed to remove file from history: %w", err)
+	removed, err := removeFromHistory("purge", args)
+	if err != nil || !removed {
+		return err
 	}
 
-	// Force push if requested
-	if forcePush {
-		fmt.Println("\n🔄 Force pushing changes...")
-		pushCmd := exec.Command("git", "push", "origin", "--force", "--all")
-		pushCmd.Stdout = os.Stdout
-		pushCmd.Stderr = os.Stderr
-		if err := pushCmd.Run(); err != nil {
-			return fmt.Errorf("failed to force push: %w", err)
-		}
-	} else {
-		fmt.Println("\n⚠️  Changes are local only. To push them:")
-		fmt.Println("git push origin --force --all")
+	notifyResult("Files purged from git history", args...)
+	if purged == nil || checkSecretCopies(purged, args) {
+		ui.Success("\nFiles removed from git history!")
 	}
-
-	fmt.Println("✅ File removed from git history!")
-	return nil
+	if purged != nil {
+		remindRotation(purged, args)
+	}
+	if err := pushAfterRewrite(fmt.Sprintf("%s were removed from history", strings.Join(args, ", "))); err != nil {
+		return err
+	}
+	return gcAfterHistoryRewrite()
 }
 
 func selectFile() (string, error) {
 	// Try using fzf if available
-	if !noFzf {
+	if !noFzf && isInteractive() {
 		if _, err := exec.LookPath("fzf"); err == nil {
 			return selectFileWithFzf()
 		}
@@ -110,23 +113,14 @@ func selectFile() (string, error) {
 
 func selectFileWithFzf() (string, error) {
 	// Get list of files
-	lsCmd := exec.Command("git", "ls-files")
+	lsCmd := gitCommand("ls-files")
 	lsOutput, err := lsCmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to list files: %w", err)
 	}
 
-	// Check if bat is available for preview
-	batAvailable := false
-	if _, err := exec.LookPath("bat"); err == nil {
-		batAvailable = true
-	}
-
 	// Create fzf command with preview
-	previewCmd := "cat {}"
-	if batAvailable {
-		previewCmd = "bat --style=numbers --color=always {}"
-	}
+	previewCmd := filePreviewCommand()
 
 	fzfCmd := exec.Command("fzf",
 		"--height", "50%",
@@ -148,7 +142,7 @@ func selectFileWithFzf() (string, error) {
 
 func selectFileWithList() (string, error) {
 	// Get list of files
-	lsCmd := exec.Command("git", "ls-files")
+	lsCmd := gitCommand("ls-files")
 	output, err := lsCmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to list files: %w", err)
@@ -156,15 +150,13 @@ func selectFileWithList() (string, error) {
 
 	// Display files
 	files := strings.Split(strings.TrimSpace(string(output)), "\n")
-	fmt.Println("\nTracked files:")
+	ui.Println("\nTracked files:")
 	for i, file := range files {
-		fmt.Printf("%2d: %s\n", i+1, file)
+		ui.Printf("%2d: %s\n", i+1, file)
 	}
 
 	// Get user selection
-	fmt.Print("\nSelect file number (or press Enter to cancel): ")
-	var input string
-	fmt.Scanln(&input)
+	input := readInput("\nSelect file number (or press Enter to cancel): ")
 
 	if input == "" {
 		return "", nil
@@ -177,10 +169,3 @@ func selectFileWithList() (string, error) {
 
 	return files[index-1], nil
 }
-
-func confirmAction() bool {
-	fmt.Print("Are you sure you want to continue? [y/N]: ")
-	var response string
-	fmt.Scanln(&response)
-	return response == "y" || response == "Y"
-} 
\ No newline at end of file
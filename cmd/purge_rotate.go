@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+)
+
+var purgeOpenDocs bool
+
+func init() {
+	purgeCmd.Flags().BoolVar(&purgeOpenDocs, "open-docs", false, "open the rotation guide of every provider whose credentials were purged")
+}
+
+// rotationChecklist is what to do about the credentials in purged files, as
+// markdown task lists: one section per provider detected, then the steps
+// every leak needs
+func rotationChecklist(purged *purgedSecrets, patterns []string) []string {
+	var lines []string
+	for _, exposure := range purged.Exposures {
+		title := exposure.Provider.Name
+		if len(exposure.Credentials) > 0 {
+			title += " (" + strings.Join(exposure.Credentials, ", ") + ")"
+		}
+		lines = append(lines, title)
+		for _, step := range exposure.Provider.Steps {
+			lines = append(lines, "- [ ] "+step)
+		}
+		if exposure.Provider.Docs != "" {
+			lines = append(lines, "  Guide: "+exposure.Provider.Docs)
+		}
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, "Every leak")
+	if len(purged.Values) > 0 {
+		lines = append(lines, fmt.Sprintf("- [ ] Rotate every secret that was in %s, not only the ones above (%d found)", strings.Join(patterns, ", "), len(purged.Values)))
+	} else {
+		lines = append(lines, fmt.Sprintf("- [ ] Rotate every secret that was in %s", strings.Join(patterns, ", ")))
+	}
+	if purged.Since != "" {
+		lines = append(lines, fmt.Sprintf("- [ ] Check the access logs for use of them since %s, when they were first committed", purged.Since))
+	} else {
+		lines = append(lines, "- [ ] Check the access logs for use of them since they were first committed")
+	}
+	lines = append(lines,
+		"- [ ] Remove the secrets from CI caches, build artifacts and forks of the repository",
+		"- [ ] Keep new secrets out of the repository: add the files to .gitignore")
+	return lines
+}
+
+// remindRotation prints the rotation checklist after a purge, and opens the
+// guides with --open-docs
+func remindRotation(purged *purgedSecrets, patterns []string) {
+	if len(purged.Values) == 0 && len(purged.Exposures) == 0 {
+		return
+	}
+
+	ui.Warn("\n🔑 The purged secrets are still exposed: anyone who cloned or forked the repository has them. Rotate them:")
+	ui.Println()
+	for _, line := range rotationChecklist(purged, patterns) {
+		ui.Println(line)
+	}
+
+	if !purgeOpenDocs {
+		return
+	}
+	for _, exposure := range purged.Exposures {
+		if exposure.Provider.Docs == "" {
+			continue
+		}
+		if err := openBrowser(exposure.Provider.Docs); err != nil {
+			ui.Warnf("%v", err)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/progress"
+	"github.com/EndlessUphill/git-helper/internal/secrets"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+)
+
+// maxPurgedBlobSize is the largest version of a purged file whose secrets
+// are looked for elsewhere; bigger files are only matched by content
+const maxPurgedBlobSize = 1 << 20
+
+var purgeNoScan bool
+
+func init() {
+	purgeCmd.Flags().BoolVar(&purgeNoScan, "no-scan", false, "don't look for other copies of the secrets after purging")
+}
+
+// purgedSecrets is what a purge removes: every version of the files, and the
+// secrets found in them
+type purgedSecrets struct {
+	// Blobs are the versions of the files, with their path
+	Blobs map[string]string
+	// Values are the secrets in them, unredacted
+	Values []string
+	// Exposures are the providers whose credentials were in them
+	Exposures []secrets.Exposure
+	// Since is the date the files were first committed
+	Since string
+}
+
+// collectPurgedSecrets reads every version of the files matching patterns in
+// history, before they are removed
+func collectPurgedSecrets(patterns []string) (*purgedSecrets, error) {
+	args := append([]string{"log", "--format=", "--raw", "--no-abbrev"}, historyPathArgs(historyPathspecs(patterns))...)
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search history: %w", err)
+	}
+
+	// Raw lines are ":100644 100644 <old> <new> M\t<path>"
+	purged := &purgedSecrets{Blobs: map[string]string{}}
+	seen := map[string]bool{}
+	for _, line := range splitLines(string(output)) {
+		info, path, ok := strings.Cut(line, "\t")
+		fields := strings.Fields(info)
+		if !ok || len(fields) < 4 || strings.Trim(fields[3], "0") == "" {
+			continue
+		}
+		blob := fields[3]
+		if _, ok := purged.Blobs[blob]; ok {
+			continue
+		}
+		// Empty files are everywhere and reveal nothing
+		output, err := gitCommand("cat-file", "-s", blob).Output()
+		size, _ := strconv.Atoi(strings.TrimSpace(string(output)))
+		if err != nil || size == 0 {
+			continue
+		}
+		purged.Blobs[blob] = path
+		if size > maxPurgedBlobSize {
+			continue
+		}
+		content, err := gitCommand("cat-file", "blob", blob).Output()
+		if err != nil || bytes.IndexByte(content, 0) >= 0 {
+			continue
+		}
+		for _, value := range secrets.Values(string(content)) {
+			if !seen[value] {
+				seen[value] = true
+				purged.Values = append(purged.Values, value)
+			}
+		}
+		purged.addExposures(secrets.DetectProviders(string(content)))
+	}
+
+	args = append([]string{"log", "--format=%as", "--reverse"}, historyPathArgs(historyPathspecs(patterns))...)
+	if output, err := gitCommand(args...).Output(); err == nil {
+		purged.Since, _, _ = strings.Cut(string(output), "\n")
+	}
+	return purged, nil
+}
+
+// addExposures merges the exposures of another version of the files
+func (p *purgedSecrets) addExposures(exposures []secrets.Exposure) {
+	for _, exposure := range exposures {
+		merged := false
+		for i := range p.Exposures {
+			if p.Exposures[i].Provider.ID != exposure.Provider.ID {
+				continue
+			}
+			for _, credential := range exposure.Credentials {
+				if !slices.Contains(p.Exposures[i].Credentials, credential) {
+					p.Exposures[i].Credentials = append(p.Exposures[i].Credentials, credential)
+				}
+			}
+			merged = true
+		}
+		if !merged {
+			p.Exposures = append(p.Exposures, exposure)
+		}
+	}
+}
+
+// findSecretCopies looks for the purged files and their secrets where the
+// purge didn't reach: other files and commit messages in the history of
+// every branch and tag, and the working tree
+func findSecretCopies(purged *purgedSecrets, patterns []string) ([]string, error) {
+	var copies []string
+
+	// The same content under another name
+	args := append([]string{"rev-list", "--objects"}, historyRevs...)
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	for _, line := range splitLines(string(output)) {
+		sha, path, ok := strings.Cut(line, " ")
+		if original, purgedBlob := purged.Blobs[sha]; ok && purgedBlob {
+			copies = append(copies, fmt.Sprintf("%s has the same content as %s", path, original))
+		}
+	}
+
+	if len(purged.Values) == 0 {
+		return copies, nil
+	}
+
+	// Other files that add one of the secrets
+	quoted := make([]string, len(purged.Values))
+	for i, value := range purged.Values {
+		quoted[i] = regexp.QuoteMeta(value)
+	}
+	args = append([]string{"log", "-p", "--format=commit %H", "--no-color", "--no-ext-diff", "-G", strings.Join(quoted, "|")}, historyRevs...)
+	output, err = gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search history: %w", err)
+	}
+	found, err := secrets.ScanDiffFor(bytes.NewReader(output), purged.Values)
+	if err != nil {
+		return nil, err
+	}
+	for _, finding := range found {
+		copies = append(copies, fmt.Sprintf("%s %s (%s)", shortSHA(finding.Commit), finding.Location(), finding.Match))
+	}
+
+	// Commit messages
+	args = []string{"log", "--format=%h %s", "-F"}
+	for _, value := range purged.Values {
+		args = append(args, "--grep="+value)
+	}
+	output, err = gitCommand(append(args, historyRevs...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search commit messages: %w", err)
+	}
+	for _, commit := range splitLines(string(output)) {
+		for _, value := range purged.Values {
+			commit = strings.ReplaceAll(commit, value, secrets.Redact(value))
+		}
+		copies = append(copies, fmt.Sprintf("the message of %s", commit))
+	}
+
+	// Changed and untracked files in the working tree; the others are in
+	// history already. The purged files themselves are left there untracked.
+	output, err = gitCommand("ls-files", "--modified", "--others", "--exclude-standard").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+	changed := map[string]bool{}
+	for _, file := range splitLines(string(output)) {
+		changed[file] = true
+	}
+	args = []string{"grep", "--untracked", "-I", "-n", "-F"}
+	for _, value := range purged.Values {
+		args = append(args, "-e", value)
+	}
+	args = append(args, "--", ".")
+	for _, pathspec := range historyPathspecs(patterns) {
+		args = append(args, strings.Replace(pathspec, ":(", ":(exclude,", 1))
+	}
+	// grep exits with 1 when nothing matches
+	output, _ = gitCommand(args...).Output()
+	for _, line := range splitLines(string(output)) {
+		file, rest, _ := strings.Cut(line, ":")
+		number, text, _ := strings.Cut(rest, ":")
+		if !changed[file] {
+			continue
+		}
+		for _, value := range purged.Values {
+			if strings.Contains(text, value) {
+				copies = append(copies, fmt.Sprintf("%s:%s in the working tree (%s)", file, number, secrets.Redact(value)))
+			}
+		}
+	}
+	return copies, nil
+}
+
+// checkSecretCopies reports where the purged secrets are still found. It
+// returns true only when it looked and found no copies.
+func checkSecretCopies(purged *purgedSecrets, patterns []string) bool {
+	ind := progress.Start(ui.Status(), "🔍 Looking for other copies of the secrets")
+	copies, err := findSecretCopies(purged, patterns)
+	if err != nil {
+		ind.Fail()
+		ui.Warnf("Could not look for other copies of the secrets: %v", err)
+		return false
+	}
+	ind.Done()
+	if len(copies) == 0 {
+		return true
+	}
+
+	ui.Warnf("\nThe files are gone, but %d copies of what they contained are still there:", len(copies))
+	for _, c := range copies {
+		ui.Printf("  %s\n", c)
+	}
+	ui.Println("\nPurge those too, and rotate the secrets: anyone with a clone or a fork still has them.")
+	return false
+}
@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/secrets"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultMaxPushFileSize is the largest file push accepts unless
+// push.max_file_size says otherwise; GitHub warns above 50MB
+const defaultMaxPushFileSize = "50MB"
+
+var (
+	pushForce       bool
+	pushNoVerify    bool
+	pushMaxFileSize string
+)
+
+var pushCmd = &cobra.Command{
+	Use:   "push [remote] [branch]",
+	Short: "Push the current branch with safety checks",
+	Long: `Push a branch after checking what is about to be published.
+
+Before pushing, githelper:
+- lists the commits that will be published
+- refuses new branches whose names break branch_policy ('githelper branch')
+- refuses files larger than --max-file-size (push.max_file_size, default 50MB)
+- scans the added lines for secrets such as API keys, tokens and private keys
+- asks before pushing directly to a protected branch (safety.protected_branches)
+- sets the upstream when the branch is new on the remote
+
+--force never overwrites work you haven't seen: it uses --force-with-lease
+and --force-if-includes, and refuses protected branches.
+Mark a line with 'githelper:allow-secret' to accept a false positive, or skip
+the checks entirely with --no-verify.
+
+After a history rewrite, --rewritten force pushes every branch and tag the
+remote has in its old version: branches first, then tags, each with a lease
+so nothing pushed in the meantime is lost. It then prints the commands your
+teammates need to run; --notice also writes them to a markdown file.
+
+Example:
+  githelper push                  # Push the current branch to origin
+  githelper push upstream         # Push to another remote
+  githelper push --force          # After a rebase or squash
+  githelper push --dry-run        # Only show what would be pushed
+  githelper push --rewritten      # After clean, purge or rewrite-author`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runPush,
+}
+
+func init() {
+	rootCmd.AddCommand(pushCmd)
+	pushCmd.Flags().BoolVarP(&pushForce, "force", "f", false, "overwrite the remote branch (with --force-with-lease)")
+	pushCmd.Flags().BoolVar(&pushNoVerify, "no-verify", false, "skip the branch name, large file and secret checks")
+	pushCmd.Flags().StringVar(&pushMaxFileSize, "max-file-size", "", "largest file allowed (default is push.max_file_size or 50MB)")
+	pushCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be pushed without pushing")
+}
+
+func runPush(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	if pushRewritten {
+		remote := defaultRewriteRemote()
+		if len(args) > 0 {
+			remote = args[0]
+		}
+		return pushRewrittenHistory(remote, "")
+	}
+
+	remote, branch, err := pushTarget(args)
+	if err != nil {
+		return err
+	}
+
+	// Compare with the remote-tracking branch as last fetched; it is also
+	// the lease for --force-with-lease
+	remoteRef := fmt.Sprintf("refs/remotes/%s/%s", remote, branch)
+	revRange, isNew := pushRange(remote, branch)
+
+	behind := 0
+	if !isNew {
+		output, err := gitCommand("rev-list", "--count", branch+".."+remoteRef).Output()
+		if err != nil {
+			return fmt.Errorf("failed to compare with %s/%s: %w", remote, branch, err)
+		}
+		behind, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+	}
+
+	commits, err := pushCommits(revRange)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 && behind == 0 && !isNew {
+		ui.Successf("%s/%s is up to date", remote, branch)
+		return nil
+	}
+	if behind > 0 && !pushForce {
+		return fmt.Errorf("%s/%s has %d commit(s) that %s doesn't. Run 'githelper sync' first, or pass --force to overwrite them",
+			remote, branch, behind, branch)
+	}
+
+	// New branches must be named after the policy, when there is one
+	if isNew && !pushNoVerify && viper.IsSet("branch_policy") {
+		if err := loadBranchPolicy().Check(branch); err != nil {
+			return fmt.Errorf("%w. Rename it with 'githelper rename-branch' or pass --no-verify", err)
+		}
+	}
+
+	// Protected branches can't be force pushed, and direct pushes need a confirmation
+	if pushForce {
+		if err := guardOperation("push", branch); err != nil {
+			return err
+		}
+	}
+
+	if !pushNoVerify {
+		if err := checkPushContents(revRange); err != nil {
+			return err
+		}
+	}
+
+	// Summary
+	target := fmt.Sprintf("%s/%s", remote, branch)
+	if isNew {
+		target += " (new branch)"
+	}
+	ui.Stepf("\n📤 Publishing %d commit(s) to %s:", len(commits), target)
+	for _, commit := range commits {
+		ui.Printf("  %s\n", commit)
+	}
+	if behind > 0 {
+		ui.Warnf("\nThis overwrites %d commit(s) on %s/%s:", behind, remote, branch)
+		overwritten, _ := pushCommits([]string{branch + ".." + remoteRef})
+		for _, commit := range overwritten {
+			ui.Printf("  %s\n", commit)
+		}
+	}
+
+	if dryRun {
+		ui.Step("\n🔍 Dry run - nothing was pushed")
+		return nil
+	}
+
+	if isProtectedBranch(branch) {
+		ui.Warnf("\n%s is a protected branch", branch)
+	}
+	if behind > 0 || isProtectedBranch(branch) {
+		if !confirmAction() {
+			ui.Error("Push cancelled")
+			return nil
+		}
+	}
+
+	pushArgs := []string{"push"}
+	if pushForce {
+		pushArgs = append(pushArgs, "--force-with-lease", "--force-if-includes")
+	}
+	if !hasUpstream(branch) {
+		pushArgs = append(pushArgs, "--set-upstream")
+	}
+	pushArgs = append(pushArgs, remote, branch)
+
+	ui.Stepf("\n🚀 git %s", strings.Join(pushArgs, " "))
+	gitPush := gitCommand(pushArgs...)
+	gitPush.Stdout = os.Stdout
+	gitPush.Stderr = os.Stderr
+	if err := gitPush.Run(); err != nil {
+		if pushForce {
+			return fmt.Errorf("push failed. If the remote changed since your last fetch, the lease protected it; fetch and review before retrying: %w", err)
+		}
+		return fmt.Errorf("push failed: %w", err)
+	}
+
+	ui.Success("Pushed successfully!")
+	return nil
+}
+
+// pushTarget returns the remote and branch of [remote] [branch] arguments,
+// origin and the current branch by default
+func pushTarget(args []string) (string, string, error) {
+	remote := "origin"
+	if len(args) > 0 {
+		remote = args[0]
+	}
+	if err := gitCommand("remote", "get-url", remote).Run(); err != nil {
+		return "", "", fmt.Errorf("remote '%s' does not exist", remote)
+	}
+
+	if len(args) > 1 {
+		branch := args[1]
+		if err := gitCommand("rev-parse", "--verify", "-q", "refs/heads/"+branch).Run(); err != nil {
+			return "", "", fmt.Errorf("branch '%s' does not exist", branch)
+		}
+		return remote, branch, nil
+	}
+	current, err := getCurrentBranch()
+	if err != nil {
+		return "", "", err
+	}
+	if current == "" || current == "HEAD" {
+		return "", "", fmt.Errorf("HEAD is detached. Check out a branch before pushing")
+	}
+	return remote, current, nil
+}
+
+// pushCommits returns the one-line summaries of the commits in revRange
+func pushCommits(revRange []string) ([]string, error) {
+	args := append([]string{"log", "--format=%h %s"}, revRange...)
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
+	}
+	return strings.Split(strings.TrimSpace(string(output)), "\n"), nil
+}
+
+// pushRange returns the rev-list arguments selecting the commits of branch
+// that remote doesn't have as last fetched, and whether the branch is new on
+// the remote
+func pushRange(remote, branch string) ([]string, bool) {
+	remoteRef := fmt.Sprintf("refs/remotes/%s/%s", remote, branch)
+	if gitCommand("rev-parse", "--verify", "-q", remoteRef).Run() != nil {
+		return []string{branch, "--not", "--remotes=" + remote}, true
+	}
+	return []string{remoteRef + ".." + branch}, false
+}
+
+func hasUpstream(branch string) bool {
+	return gitCommand("rev-parse", "--abbrev-ref", branch+"@{upstream}").Run() == nil
+}
+
+// checkPushContents refuses large files and secrets in the commits that are
+// about to be pushed
+func checkPushContents(revRange []string) error {
+	limit := pushMaxFileSize
+	if limit == "" {
+		limit = viper.GetString("push.max_file_size")
+	}
+	if limit == "" {
+		limit = defaultMaxPushFileSize
+	}
+	maxSize, err := parseSize(limit)
+	if err != nil {
+		return fmt.Errorf("invalid max file size: %w", err)
+	}
+
+	ui.Step("🔍 Checking for large files and secrets...")
+	large, err := findLargeBlobs(maxSize+1, revRange...)
+	if err != nil {
+		return err
+	}
+
+	logArgs := append([]string{"log", "-p", "--no-color", "--no-ext-diff", "--format=commit %h"}, revRange...)
+	patch, err := gitCommand(logArgs...).Output()
+	if err != nil {
+		return fmt.Errorf("failed to read commits: %w", err)
+	}
+	found, err := secrets.ScanDiff(bytes.NewReader(patch))
+	if err != nil {
+		return fmt.Errorf("failed to scan for secrets: %w", err)
+	}
+
+	if len(large) == 0 && len(found) == 0 {
+		return nil
+	}
+
+	if len(large) > 0 {
+		ui.Errorf("\nFiles larger than %s:", limit)
+		for _, file := range large {
+			ui.Printf("  %-50s %s\n", file.Path, formatSize(file.Size))
+		}
+		ui.Println("  Use Git LFS for large files, or remove them with 'githelper clean'")
+	}
+	if len(found) > 0 {
+		ui.Error("\nPossible secrets:")
+		for _, finding := range found {
+			ui.Printf("  %s %s: %s (%s)\n", finding.Commit, finding.Location(), finding.Rule.Description, finding.Match)
+		}
+		ui.Println("  Remove them from the commits (e.g. 'git commit --amend' or 'githelper purge') and rotate them")
+		ui.Printf("  For false positives, add '%s' to the line\n", secrets.AllowMarker)
+	}
+	return fmt.Errorf("push blocked by safety checks. Pass --no-verify to push anyway")
+}
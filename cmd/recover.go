@@ -6,6 +6,8 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -19,15 +21,23 @@ This command helps you recover lost work by:
 2. Letting you select a commit to restore to
 3. Resetting your branch back to that commit
 
+With --grep, it lists only the commits whose message or changes contain the
+text, among the reflogs of every branch and the dangling commits nothing
+refers to anymore (such as dropped stashes), so you can find a lost change
+by what it did.
+
 ⚠️  WARNING: This will reset your current branch! Make sure to commit or stash changes.
 
 Example:
-  githelper recover    # Interactive commit selection`,
+  githelper recover    # Interactive commit selection
+  githelper recover --grep "retry backoff"  # Find the commit that changed it
+  githelper recover --select HEAD@{2} --yes  # Skip the picker`,
 	RunE: runRecover,
 }
 
 func init() {
 	rootCmd.AddCommand(recoverCmd)
+	recoverCmd.Flags().StringVar(&preselectedItem, "select", "", "commit (hash or reflog entry like HEAD@{2}) to recover without prompting")
 }
 
 
@@ -38,17 +48,23 @@ func runRecover(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check for uncommitted changes
-	statusCmd := exec.Command("git", "status", "--porcelain")
+	statusCmd := gitCommand("status", "--porcelain")
 	status, err := statusCmd.Output()
 	if err != nil {
-		return fmt.Errorf("failed to check git status: %w", err)
+		return fmt.Errorf(i18n.T("error.git_status"), err)
 	}
 	if len(status) > 0 {
 		return fmt.Errorf("you have uncommitted changes. Please commit or stash them first")
 	}
 
-	fmt.Println("🔍 Searching for lost commits...")
-	commit, err := selectCommitFromReflog()
+	var commit string
+	if recoverGrep != "" {
+		ui.Stepf("🔍 Searching lost commits for '%s'...", recoverGrep)
+		commit, err = selectCommitByContent(recoverGrep)
+	} else {
+		ui.Step("🔍 Searching for lost commits...")
+		commit, err = selectCommitFromReflog()
+	}
 	if err != nil {
 		return err
 	}
@@ -57,29 +73,45 @@ func runRecover(cmd *cobra.Command, args []string) error {
 	}
 
 	// Confirm action
-	fmt.Printf("\n⚠️  WARNING: This will reset your branch to commit: %s\n", commit)
-	fmt.Println("This action will modify your current branch!")
+	ui.Warnf("\nWARNING: This will reset your branch to commit: %s", commit)
+	ui.Println("This action will modify your current branch!")
 	if !confirmAction() {
-		fmt.Println("❌ Operation cancelled")
+		ui.Error("Operation cancelled")
 		return nil
 	}
 
+	if err := recordOperation("recover", false); err != nil {
+		return err
+	}
+
 	// Reset to selected commit
-	fmt.Printf("\n⏪ Resetting to commit: %s\n", commit)
-	resetCmd := exec.Command("git", "reset", "--hard", commit)
+	ui.Stepf("\n⏪ Resetting to commit: %s", commit)
+	resetCmd := gitCommand("reset", "--hard", commit)
 	resetCmd.Stdout = os.Stdout
 	resetCmd.Stderr = os.Stderr
 	if err := resetCmd.Run(); err != nil {
 		return fmt.Errorf("failed to reset to commit: %w", err)
 	}
 
-	fmt.Println("✅ Successfully reset to selected commit!")
+	ui.Success("Successfully reset to selected commit!")
+	copyToClipboard("commit hash", commit)
 	return nil
 }
 
 func selectCommitFromReflog() (string, error) {
+	if preselectedItem != "" {
+		entries, err := getReflogEntries()
+		if err != nil {
+			return "", err
+		}
+		entry, err := preselect(entries, "reflog entry", func(e ReflogEntry) bool {
+			return e.Action == preselectedItem || sameCommit(e.Hash, preselectedItem)
+		})
+		return entry.Hash, err
+	}
+
 	// Try using fzf if available
-	if !noFzf {
+	if !noFzf && isInteractive() {
 		if _, err := exec.LookPath("fzf"); err == nil {
 			return selectCommitWithFzfFromReflog()
 		}
@@ -88,7 +120,7 @@ func selectCommitFromReflog() (string, error) {
 }
 
 func getReflogEntries() ([]ReflogEntry, error) {
-	reflogCmd := exec.Command("git", "reflog", "--pretty=%H %gd %gs")
+	reflogCmd := gitCommand("reflog", "--pretty=%H %gd %gs")
 	output, err := reflogCmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reflog: %w", err)
@@ -154,21 +186,19 @@ func selectCommitWithListFromReflog() (string, error) {
 		return "", err
 	}
 
-	fmt.Println("\nRecent git actions:")
+	ui.Println("\nRecent git actions:")
 	for i, entry := range entries {
 		if i >= 20 { // Show only last 20 entries
 			break
 		}
-		fmt.Printf("%2d: %s %s: %s\n", 
+		ui.Printf("%2d: %s %s: %s\n", 
 			i+1,
 			entry.Hash[:8],
 			entry.Action,
 			entry.Description)
 	}
 
-	fmt.Print("\nSelect action number (or press Enter to cancel): ")
-	var input string
-	fmt.Scanln(&input)
+	input := readInput("\nSelect action number (or press Enter to cancel): ")
 
 	if input == "" {
 		return "", nil
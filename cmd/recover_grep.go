@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+)
+
+var recoverGrep string
+
+func init() {
+	recoverCmd.Flags().StringVar(&recoverGrep, "grep", "", "find lost commits whose message or changes contain this text")
+}
+
+// lostCommit is a commit found by 'recover --grep'
+type lostCommit struct {
+	Hash    string
+	Date    string
+	Subject string
+	// Source is where it was found: a reflog entry, or "dangling"
+	Source string
+}
+
+// lostCommitCandidates returns the commits in every reflog, newest first in
+// each, then the ones nothing refers to anymore, such as dropped stashes.
+// sources tells where each was found.
+func lostCommitCandidates() ([]string, map[string]string, error) {
+	var hashes []string
+	sources := map[string]string{}
+	add := func(hash, source string) {
+		if _, ok := sources[hash]; !ok {
+			sources[hash] = source
+			hashes = append(hashes, hash)
+		}
+	}
+
+	output, err := gitCommand("reflog", "show", "--all", "--format=%H %gd: %gs").Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get reflog: %w", err)
+	}
+	for _, line := range splitLines(string(output)) {
+		hash, source, _ := strings.Cut(line, " ")
+		add(hash, source)
+	}
+
+	// With --no-reflogs, commits only the reflogs know are unreachable too;
+	// they are in the list already
+	output, err = gitCommand("fsck", "--unreachable", "--no-reflogs", "--no-progress").Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to look for dangling commits: %w", err)
+	}
+	for _, line := range splitLines(string(output)) {
+		if hash, ok := strings.CutPrefix(line, "unreachable commit "); ok {
+			add(hash, "dangling")
+		}
+	}
+	return hashes, sources, nil
+}
+
+// logCommits runs git log on exactly the given commits with extra options
+// and returns the hashes it lists
+func logCommits(hashes []string, options ...string) ([]string, error) {
+	args := append([]string{"log", "--stdin", "--no-walk=unsorted", "--format=%H"}, options...)
+	logCmd := gitCommand(args...)
+	logCmd.Stdin = strings.NewReader(strings.Join(hashes, "\n") + "\n")
+	output, err := logCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search commits: %w", err)
+	}
+	return splitLines(string(output)), nil
+}
+
+// findLostCommits returns the commits of the reflogs and the dangling ones
+// whose message or diff contains text, ignoring case
+func findLostCommits(text string) ([]lostCommit, error) {
+	hashes, sources, err := lostCommitCandidates()
+	if err != nil || len(hashes) == 0 {
+		return nil, err
+	}
+
+	matched := map[string]bool{}
+	for _, search := range [][]string{
+		{"--regexp-ignore-case", "--fixed-strings", "--grep=" + text},
+		// Merges, such as stashes, are compared with their first parent
+		{"--regexp-ignore-case", "--diff-merges=first-parent", "-S" + text},
+	} {
+		found, err := logCommits(hashes, search...)
+		if err != nil {
+			return nil, err
+		}
+		for _, hash := range found {
+			matched[hash] = true
+		}
+	}
+
+	var commits []lostCommit
+	for _, hash := range hashes {
+		if !matched[hash] {
+			continue
+		}
+		output, err := gitCommand("show", "-s", "--format=%as%x00%s", hash).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read commit %s: %w", shortSHA(hash), err)
+		}
+		date, subject, _ := strings.Cut(strings.TrimSpace(string(output)), "\x00")
+		commits = append(commits, lostCommit{Hash: hash, Date: date, Subject: subject, Source: sources[hash]})
+	}
+	return commits, nil
+}
+
+// selectCommitByContent asks which of the commits containing text to
+// recover. A single match is selected right away.
+func selectCommitByContent(text string) (string, error) {
+	commits, err := findLostCommits(text)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case len(commits) == 0:
+		return "", fmt.Errorf("no commit in the reflog or among dangling commits contains '%s'", text)
+	case preselectedItem != "":
+		c, err := preselect(commits, "commit containing '"+text+"'", func(c lostCommit) bool {
+			return sameCommit(c.Hash, preselectedItem)
+		})
+		return c.Hash, err
+	case len(commits) == 1:
+		c := commits[0]
+		ui.Printf("Found %s %s %s (%s)\n", shortSHA(c.Hash), c.Date, c.Subject, c.Source)
+		return c.Hash, nil
+	}
+
+	if !noFzf && isInteractive() {
+		if _, err := exec.LookPath("fzf"); err == nil {
+			var input strings.Builder
+			for _, c := range commits {
+				fmt.Fprintf(&input, "%s %s %s (%s)\n", shortSHA(c.Hash), c.Date, c.Subject, c.Source)
+			}
+			fzfCmd := exec.Command("fzf",
+				"--height", "50%",
+				"--reverse",
+				"--preview", "git show --color=always {1}",
+				"--preview-window", "right:50%",
+				"--ansi")
+			fzfCmd.Stdin = strings.NewReader(input.String())
+			fzfCmd.Stderr = os.Stderr
+			output, err := fzfCmd.Output()
+			if err != nil {
+				return "", nil // User cancelled
+			}
+			return strings.Fields(string(output))[0], nil
+		}
+	}
+
+	ui.Printf("\n%d commits contain '%s':\n", len(commits), text)
+	for i, c := range commits {
+		ui.Printf("%2d: %s %s %s (%s)\n", i+1, shortSHA(c.Hash), c.Date, c.Subject, c.Source)
+	}
+	input := readInput("\nSelect commit number (or press Enter to cancel): ")
+	if input == "" {
+		return "", nil
+	}
+	var index int
+	if _, err := fmt.Sscanf(input, "%d", &index); err != nil || index < 1 || index > len(commits) {
+		return "", fmt.Errorf("invalid selection")
+	}
+	return commits[index-1].Hash, nil
+}
@@ -2,15 +2,19 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	fixLineEndings bool
-	cleanUntracked bool
+	refreshIndexOnly bool
+	fixLineEndings   bool
+	resetChanges     bool
+	cleanUntracked   bool
 )
 
 var refreshCmd = &cobra.Command{
@@ -18,107 +22,266 @@ var refreshCmd = &cobra.Command{
 	Short: "Fix Git index and line ending issues",
 	Long: `Fix issues where Git shows files as modified when they haven't changed.
 
-This command helps fix common Git index issues:
-1. Refreshes Git's index
-2. Optionally fixes line ending issues
-3. Optionally removes untracked files
+Each step is a separate flag, and only the index refresh runs by default:
+  --index-only  Refresh the index: files whose timestamps changed but whose
+                content didn't stop showing as modified (the default)
+  --eol         Renormalize line endings: re-add tracked files so the index
+                follows .gitattributes, and set core.autocrlf to false
+  --reset       Discard the uncommitted changes to tracked files
+  --clean       Remove untracked files and directories
+
+--reset and --clean list the files they are about to delete and ask for
+confirmation. --dry-run lists the files every step would touch without
+changing anything. Files given as arguments limit the steps to them.
 
 Common scenarios this fixes:
 - Git shows files as modified but you haven't changed them
 - Line ending (CRLF/LF) issues causing false modifications
 - Need to clean up and start fresh
 
+To remove only build artifacts, in every worktree, use 'githelper clean-workdir'.
+To fix line endings for every clone with .gitattributes, use 'githelper eol fix'.
+
 Example:
-  githelper refresh              # Refresh all files
-  githelper refresh file.txt     # Refresh specific file
-  githelper refresh --crlf       # Fix line ending issues
-  githelper refresh --clean      # Also remove untracked files`,
+  githelper refresh                      # Refresh the index
+  githelper refresh --eol --dry-run      # Show the files with line ending changes
+  githelper refresh --eol                # Fix line ending issues
+  githelper refresh --reset file.txt     # Discard the changes to a file
+  githelper refresh --reset --clean      # Start fresh from HEAD`,
 	RunE: runRefresh,
 }
 
 func init() {
 	rootCmd.AddCommand(refreshCmd)
-	refreshCmd.Flags().BoolVar(&fixLineEndings, "crlf", false, "fix line ending issues")
+	refreshCmd.Flags().BoolVar(&refreshIndexOnly, "index-only", false, "only refresh the index (the default)")
+	refreshCmd.Flags().BoolVar(&fixLineEndings, "eol", false, "renormalize line endings")
+	refreshCmd.Flags().BoolVar(&fixLineEndings, "crlf", false, "renormalize line endings")
+	refreshCmd.Flags().MarkDeprecated("crlf", "use --eol instead")
+	refreshCmd.Flags().BoolVar(&resetChanges, "reset", false, "discard uncommitted changes to tracked files")
 	refreshCmd.Flags().BoolVar(&cleanUntracked, "clean", false, "remove untracked files and directories")
+	refreshCmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the files each step would touch without changing anything")
 }
 
 func runRefresh(cmd *cobra.Command, args []string) error {
 	if err := checkGitRepo(); err != nil {
 		return err
 	}
+	if refreshIndexOnly && (fixLineEndings || resetChanges || cleanUntracked) {
+		return fmt.Errorf("--index-only can't be combined with --eol, --reset or --clean")
+	}
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	// Reset first: it would undo the renormalized index otherwise
+	if resetChanges {
+		files, err := changedTrackedFiles(paths)
+		if err != nil {
+			return err
+		}
+		if err := refreshStep("Discard uncommitted changes", "🗑️", files, true, func() error {
+			// Uncommitted changes are discarded; keep them for rollback
+			if err := recordOperation("refresh", false); err != nil {
+				return err
+			}
+			return discardChanges(args)
+		}); err != nil {
+			return err
+		}
+	}
 
-	// Check for uncommitted changes that would be lost
 	if cleanUntracked {
-		statusCmd := exec.Command("git", "status", "--porcelain")
-		status, err := statusCmd.Output()
+		files, err := untrackedToClean(paths)
 		if err != nil {
-			return fmt.Errorf("failed to check git status: %w", err)
+			return err
 		}
-		if len(status) > 0 {
-			fmt.Println("⚠️  WARNING: This will remove all untracked files and directories!")
-			if !confirmAction() {
-				fmt.Println("❌ Operation cancelled")
-				return nil
+		if err := refreshStep("Remove untracked files", "🧹", files, true, func() error {
+			cleanCmd := gitCommand(append([]string{"clean", "-fd", "--"}, paths...)...)
+			cleanCmd.Stderr = os.Stderr
+			if err := cleanCmd.Run(); err != nil {
+				return fmt.Errorf("failed to clean untracked files: %w", err)
 			}
+			return nil
+		}); err != nil {
+			return err
 		}
 	}
 
-	// Fix line endings if requested
 	if fixLineEndings {
-		fmt.Println("🔧 Fixing line endings...")
-		if err := fixCRLFIssues(); err != nil {
+		files, err := renormalizedFiles(paths)
+		if err != nil {
+			return err
+		}
+		if err := refreshStep("Renormalize line endings", "🔧", files, false, func() error {
+			return fixCRLFIssues(paths)
+		}); err != nil {
 			return err
 		}
 	}
 
-	// Reset index for specified files or all files
-	fmt.Println("🔄 Refreshing Git index...")
-	checkoutArgs := []string{"checkout", "--"}
-	if len(args) > 0 {
-		checkoutArgs = append(checkoutArgs, args...)
-	} else {
-		checkoutArgs = append(checkoutArgs, ".")
+	if dryRun {
+		ui.Step("🔄 Would refresh the index")
+		ui.Step("\n🔍 Dry run - nothing was changed")
+		return nil
+	}
+	ui.Step("🔄 Refreshing Git index...")
+	// update-index exits non-zero when files still differ, which is expected
+	gitCommand("update-index", "-q", "--really-refresh").Run()
+
+	modified, err := changedTrackedFiles(paths)
+	if err != nil {
+		return err
+	}
+	if len(modified) > 0 {
+		ui.Infof("%d file(s) have real changes", len(modified))
 	}
+	ui.Success("Git index refreshed successfully!")
+	return nil
+}
 
-	checkoutCmd := exec.Command("git", checkoutArgs...)
-	checkoutCmd.Stderr = os.Stderr
-	if err := checkoutCmd.Run(); err != nil {
-		return fmt.Errorf("failed to refresh index: %w", err)
+// refreshStep lists the files a step touches, then runs it unless this is
+// a dry run, after confirmation for destructive steps. Steps without files
+// are skipped.
+func refreshStep(title, icon string, files []string, destructive bool, run func() error) error {
+	if len(files) == 0 {
+		ui.Printf("%s %s: nothing to do\n", icon, title)
+		return nil
+	}
+	ui.Printf("%s %s, %d file(s):\n", icon, title, len(files))
+	printFileList(ui.Out(), files, 20)
+	if dryRun {
+		return nil
+	}
+	if destructive && !confirmAction() {
+		ui.Step("⏭️  Skipped")
+		return nil
 	}
+	return run()
+}
 
-	// Clean untracked files if requested
-	if cleanUntracked {
-		fmt.Println("🧹 Removing untracked files...")
-		cleanCmd := exec.Command("git", "clean", "-fd")
-		cleanCmd.Stderr = os.Stderr
-		if err := cleanCmd.Run(); err != nil {
-			return fmt.Errorf("failed to clean untracked files: %w", err)
+func printFileList(w io.Writer, files []string, limit int) {
+	for i, file := range files {
+		if i == limit {
+			fmt.Fprintf(w, "  ... and %d more\n", len(files)-limit)
+			break
+		}
+		fmt.Fprintf(w, "  %s\n", file)
+	}
+}
+
+// changedTrackedFiles lists the tracked files that differ from HEAD, staged
+// or not
+func changedTrackedFiles(paths []string) ([]string, error) {
+	output, err := gitCommand(append([]string{"diff", "--name-only", "HEAD", "--"}, paths...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list changed files: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// untrackedToClean lists what 'git clean -fd' would remove
+func untrackedToClean(paths []string) ([]string, error) {
+	output, err := gitCommand(append([]string{"clean", "-nd", "--"}, paths...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if file, ok := strings.CutPrefix(line, "Would remove "); ok {
+			files = append(files, file)
 		}
 	}
+	return files, nil
+}
 
-	// Reset to HEAD
-	resetCmd := exec.Command("git", "reset", "--hard", "HEAD")
+// discardChanges resets the index and working tree to HEAD, only for the
+// given paths when there are some
+func discardChanges(paths []string) error {
+	gitArgs := []string{"reset", "--hard", "HEAD"}
+	if len(paths) > 0 {
+		gitArgs = append([]string{"restore", "--source=HEAD", "--staged", "--worktree", "--"}, paths...)
+	}
+	resetCmd := gitCommand(gitArgs...)
 	resetCmd.Stderr = os.Stderr
 	if err := resetCmd.Run(); err != nil {
-		return fmt.Errorf("failed to reset to HEAD: %w", err)
+		return fmt.Errorf("failed to discard changes: %w", err)
 	}
-
-	fmt.Println("✅ Git index refreshed successfully!")
 	return nil
 }
 
-func fixCRLFIssues() error {
+// renormalizedFiles returns the files whose index entry 'git add
+// --renormalize' would change, found by renormalizing a copy of the index.
+// config holds "key=value" settings to renormalize with.
+func renormalizedFiles(paths []string, config ...string) ([]string, error) {
+	output, err := gitCommand("rev-parse", "--git-path", "index").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the index: %w", err)
+	}
+	index, err := os.ReadFile(strings.TrimSpace(string(output)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the index: %w", err)
+	}
+	tmp, err := os.CreateTemp("", "githelper-index-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(index)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	writeTree := func(env ...string) (string, error) {
+		cmd := gitCommand("write-tree")
+		cmd.Env = append(os.Environ(), env...)
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to read the index, resolve conflicts first: %w", err)
+		}
+		return strings.TrimSpace(string(output)), nil
+	}
+	before, err := writeTree()
+	if err != nil {
+		return nil, err
+	}
+	var addArgs []string
+	for _, setting := range config {
+		addArgs = append(addArgs, "-c", setting)
+	}
+	addArgs = append(addArgs, "add", "--renormalize", "--")
+	addCmd := gitCommand(append(addArgs, paths...)...)
+	addCmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+tmp.Name())
+	if output, err := addCmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to renormalize: %s", strings.TrimSpace(string(output)))
+	}
+	after, err := writeTree("GIT_INDEX_FILE=" + tmp.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	output, err = gitCommand("diff-tree", "-r", "--name-only", before, after).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare the index: %w", err)
+	}
+	return strings.Fields(string(output)), nil
+}
+
+func fixCRLFIssues(paths []string) error {
 	// Disable autocrlf
-	configCmd := exec.Command("git", "config", "core.autocrlf", "false")
+	configCmd := gitCommand("config", "core.autocrlf", "false")
 	if err := configCmd.Run(); err != nil {
 		return fmt.Errorf("failed to configure line endings: %w", err)
 	}
 
-	// Re-normalize all files
-	normalizeCmd := exec.Command("git", "add", "--renormalize", ".")
+	// Re-normalize the files
+	normalizeCmd := gitCommand(append([]string{"add", "--renormalize", "--"}, paths...)...)
 	if err := normalizeCmd.Run(); err != nil {
 		return fmt.Errorf("failed to renormalize files: %w", err)
 	}
 
 	return nil
-} 
\ No newline at end of file
+}
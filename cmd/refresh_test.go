@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshListings(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.WriteFile("crlf.txt", []byte("a\r\nb\r\n"), 0644))
+	runGit(t, tmpDir, "add", ".")
+	runGit(t, tmpDir, "commit", "-m", "base")
+
+	require.NoError(t, os.WriteFile(".gitattributes", []byte("* text=auto\n"), 0644))
+	require.NoError(t, os.WriteFile("test.txt", []byte("changed"), 0644))
+	require.NoError(t, os.MkdirAll("tmp", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("tmp", "junk"), []byte("junk"), 0644))
+
+	changed, err := changedTrackedFiles([]string{"."})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test.txt"}, changed)
+
+	untracked, err := untrackedToClean([]string{"."})
+	require.NoError(t, err)
+	assert.Equal(t, []string{".gitattributes", "tmp/"}, untracked)
+
+	// Renormalizing also stages modified files; the index is left alone
+	renormalized, err := renormalizedFiles([]string{"."})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"crlf.txt", "test.txt"}, renormalized)
+	staged, err := exec.Command("git", "diff", "--cached", "--name-only").Output()
+	require.NoError(t, err)
+	assert.Empty(t, strings.TrimSpace(string(staged)))
+
+	require.NoError(t, discardChanges([]string{"test.txt"}))
+	changed, err = changedTrackedFiles([]string{"."})
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+}
@@ -0,0 +1,441 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// checksumsAsset is the name of the checksums file release upload attaches
+const checksumsAsset = "SHA256SUMS"
+
+var (
+	releaseClobber   bool
+	releaseChecksums bool
+	releaseParallel  int
+	releaseDir       string
+	releaseNoVerify  bool
+)
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Upload and download the files of GitHub releases",
+	Long: `Attach files to a GitHub release and download them, several at a time.
+
+Example:
+  githelper release upload v1.2.0 dist/*
+  githelper release download v1.2.0 '*linux*'`,
+}
+
+var releaseUploadCmd = &cobra.Command{
+	Use:   "upload <tag> <file>...",
+	Short: "Attach files to a release",
+	Long: `Upload files as assets of the published release of a tag, several in
+parallel.
+
+Files already attached with the same name and size are skipped, so running
+the command again after an interruption uploads only what is missing.
+Unfinished uploads are replaced. A file that exists with another size is an
+error unless --clobber replaces it.
+
+With --checksums, the SHA-256 of the files are added to a SHA256SUMS asset,
+which 'githelper release download' verifies downloads against.
+
+Example:
+  githelper release upload v1.2.0 dist/app-linux dist/app-darwin
+  githelper release upload v1.2.0 dist/* --checksums
+  githelper release upload v1.2.0 app.tar.gz --clobber`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runReleaseUpload,
+}
+
+var releaseDownloadCmd = &cobra.Command{
+	Use:   "download <tag> [pattern]",
+	Short: "Download the files of a release",
+	Long: `Download the assets of the published release of a tag whose names match a
+glob pattern (all by default), several in parallel.
+
+Downloads go to a .part file first and resume where they stopped when run
+again. Files already downloaded are skipped. When the release has a
+SHA256SUMS or *checksums.txt asset, every file is verified against it.
+
+Example:
+  githelper release download v1.2.0
+  githelper release download v1.2.0 '*linux-amd64*' --dir bin`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runReleaseDownload,
+}
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+	releaseCmd.AddCommand(releaseUploadCmd)
+	releaseCmd.AddCommand(releaseDownloadCmd)
+
+	releaseUploadCmd.Flags().BoolVar(&releaseClobber, "clobber", false, "replace assets that already exist")
+	releaseUploadCmd.Flags().BoolVar(&releaseChecksums, "checksums", false, "add the SHA-256 of the files to a "+checksumsAsset+" asset")
+	releaseUploadCmd.Flags().IntVarP(&releaseParallel, "parallel", "j", 4, "number of files to transfer at once")
+	releaseDownloadCmd.Flags().StringVarP(&releaseDir, "dir", "D", ".", "directory to download to")
+	releaseDownloadCmd.Flags().BoolVar(&releaseNoVerify, "no-verify", false, "don't verify the files against the release checksums")
+	releaseDownloadCmd.Flags().IntVarP(&releaseParallel, "parallel", "j", 4, "number of files to transfer at once")
+}
+
+func runReleaseUpload(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	tag, files := args[0], args[1:]
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a file", file)
+		}
+	}
+
+	client, owner, name, err := originClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	release, err := client.Release(ctx, owner, name, tag)
+	if err != nil {
+		return fmt.Errorf("failed to get release: %w", err)
+	}
+	existing := map[string]github.Asset{}
+	for _, asset := range release.Assets {
+		existing[asset.Name] = asset
+	}
+
+	sums := map[string]string{}
+	if releaseChecksums {
+		ui.Step("🔐 Computing checksums...")
+		for _, file := range files {
+			sum, err := fileSHA256(file)
+			if err != nil {
+				return err
+			}
+			sums[filepath.Base(file)] = sum
+		}
+	}
+
+	ui.Stepf("📤 Uploading %d file(s) to %s/%s %s", len(files), owner, name, tag)
+	failed := transferAll(files, releaseParallel, func(file string) (string, error) {
+		assetName := filepath.Base(file)
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", err
+		}
+		if asset, ok := existing[assetName]; ok {
+			switch {
+			case asset.State == "uploaded" && !releaseClobber && asset.Size == info.Size():
+				return fmt.Sprintf("⏭️  %s is already uploaded", assetName), nil
+			case asset.State == "uploaded" && !releaseClobber:
+				return "", fmt.Errorf("%s already exists with another size, use --clobber to replace it", assetName)
+			}
+			if err := client.DeleteAsset(ctx, owner, name, asset.ID); err != nil {
+				return "", fmt.Errorf("failed to replace %s: %w", assetName, err)
+			}
+		}
+		if err := uploadReleaseFile(client, owner, name, release.ID, assetName, file); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("✅ %s (%s)", assetName, formatSize(info.Size())), nil
+	})
+
+	if failed > 0 {
+		return fmt.Errorf("%d upload(s) failed, run the command again to upload the rest", failed)
+	}
+	if releaseChecksums {
+		if err := updateReleaseChecksums(client, owner, name, release, sums); err != nil {
+			return err
+		}
+	}
+	ui.Stepf("🎉 Release: %s", release.URL)
+	return nil
+}
+
+func uploadReleaseFile(client *github.Client, owner, name string, releaseID int64, assetName, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := client.UploadAsset(context.Background(), owner, name, releaseID, assetName, f); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", assetName, err)
+	}
+	return nil
+}
+
+// updateReleaseChecksums merges sums into the checksums asset of a release
+func updateReleaseChecksums(client *github.Client, owner, name string, release *github.Release, sums map[string]string) error {
+	ctx := context.Background()
+	for _, asset := range release.Assets {
+		if asset.Name != checksumsAsset {
+			continue
+		}
+		if asset.State == "uploaded" {
+			content, err := downloadReleaseText(client, owner, name, asset)
+			if err != nil {
+				return err
+			}
+			for file, sum := range parseChecksums(content) {
+				if _, ok := sums[file]; !ok {
+					sums[file] = sum
+				}
+			}
+		}
+		if err := client.DeleteAsset(ctx, owner, name, asset.ID); err != nil {
+			return fmt.Errorf("failed to replace %s: %w", checksumsAsset, err)
+		}
+	}
+
+	dir, err := makeTempDir("", "githelper-release-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, checksumsAsset)
+	if err := os.WriteFile(file, []byte(formatChecksums(sums)), 0644); err != nil {
+		return err
+	}
+	if err := uploadReleaseFile(client, owner, name, release.ID, checksumsAsset, file); err != nil {
+		return err
+	}
+	ui.Stepf("🔐 %s lists %d file(s)", checksumsAsset, len(sums))
+	return nil
+}
+
+func runReleaseDownload(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	tag, pattern := args[0], "*"
+	if len(args) > 1 {
+		pattern = args[1]
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern '%s': %w", pattern, err)
+	}
+
+	client, owner, name, err := originClient()
+	if err != nil {
+		return err
+	}
+	release, err := client.Release(context.Background(), owner, name, tag)
+	if err != nil {
+		return fmt.Errorf("failed to get release: %w", err)
+	}
+
+	var assets []github.Asset
+	sums := map[string]string{}
+	for _, asset := range release.Assets {
+		if asset.State != "uploaded" {
+			continue
+		}
+		if isChecksumsAsset(asset.Name) && !releaseNoVerify {
+			content, err := downloadReleaseText(client, owner, name, asset)
+			if err != nil {
+				return err
+			}
+			for file, sum := range parseChecksums(content) {
+				sums[file] = sum
+			}
+		}
+		if matched, _ := path.Match(pattern, asset.Name); matched {
+			assets = append(assets, asset)
+		}
+	}
+	if len(assets) == 0 {
+		return fmt.Errorf("no asset of %s matches '%s'", tag, pattern)
+	}
+	if err := os.MkdirAll(releaseDir, 0755); err != nil {
+		return err
+	}
+
+	ui.Stepf("📥 Downloading %d file(s) from %s/%s %s", len(assets), owner, name, tag)
+	failed := transferAll(assets, releaseParallel, func(asset github.Asset) (string, error) {
+		return downloadReleaseAsset(client, owner, name, asset, sums[asset.Name])
+	})
+	if failed > 0 {
+		return fmt.Errorf("%d download(s) failed, run the command again to resume", failed)
+	}
+	if len(sums) == 0 && !releaseNoVerify {
+		ui.Step("💡 The release has no checksums to verify the files against")
+	}
+	return nil
+}
+
+// downloadReleaseAsset downloads an asset into the download directory,
+// resuming from its .part file, and verifies it against sum when known
+func downloadReleaseAsset(client *github.Client, owner, name string, asset github.Asset, sum string) (string, error) {
+	dest := filepath.Join(releaseDir, asset.Name)
+	if info, err := os.Stat(dest); err == nil && info.Size() == asset.Size {
+		if sum == "" {
+			return fmt.Sprintf("⏭️  %s is already downloaded", asset.Name), nil
+		}
+		if actual, err := fileSHA256(dest); err == nil && actual == sum {
+			return fmt.Sprintf("⏭️  %s is already downloaded and verified", asset.Name), nil
+		}
+	}
+
+	part := dest + ".part"
+	var offset int64
+	if info, err := os.Stat(part); err == nil && info.Size() < asset.Size {
+		offset = info.Size()
+	}
+	content, start, err := client.DownloadAsset(context.Background(), owner, name, asset.ID, offset)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	defer content.Close()
+
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return "", err
+	}
+	if err := f.Truncate(start); err != nil {
+		f.Close()
+		return "", err
+	}
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		f.Close()
+		return "", err
+	}
+	_, err = io.Copy(f, content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+
+	info, err := os.Stat(part)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() != asset.Size {
+		return "", fmt.Errorf("%s is incomplete: %s of %s", asset.Name, formatSize(info.Size()), formatSize(asset.Size))
+	}
+	verified := ""
+	if sum != "" {
+		actual, err := fileSHA256(part)
+		if err != nil {
+			return "", err
+		}
+		if actual != sum {
+			os.Remove(part)
+			return "", fmt.Errorf("%s doesn't match its checksum, the download was removed", asset.Name)
+		}
+		verified = ", verified"
+	}
+	if err := os.Rename(part, dest); err != nil {
+		return "", err
+	}
+
+	resumed := ""
+	if start > 0 {
+		resumed = fmt.Sprintf(", resumed at %s", formatSize(start))
+	}
+	return fmt.Sprintf("✅ %s (%s%s%s)", asset.Name, formatSize(asset.Size), resumed, verified), nil
+}
+
+func downloadReleaseText(client *github.Client, owner, name string, asset github.Asset) (string, error) {
+	content, _, err := client.DownloadAsset(context.Background(), owner, name, asset.ID, 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	return string(data), nil
+}
+
+// transferAll runs transfer for the items on parallel workers, printing the
+// line each returns or its error, and returns the number that failed
+func transferAll[T any](items []T, parallel int, transfer func(T) (string, error)) int {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		jobs   = make(chan T)
+		failed int
+	)
+	for i := 0; i < max(parallel, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				line, err := transfer(item)
+				mu.Lock()
+				if err != nil {
+					failed++
+					ui.Errorf("%v", err)
+				} else {
+					ui.Println(line)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, item := range items {
+		jobs <- item
+	}
+	close(jobs)
+	wg.Wait()
+	return failed
+}
+
+func fileSHA256(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// isChecksumsAsset reports whether an asset lists the checksums of the
+// others, as written by release upload or goreleaser
+func isChecksumsAsset(name string) bool {
+	return name == checksumsAsset || strings.HasSuffix(name, "checksums.txt")
+}
+
+// parseChecksums parses sha256sum output: a hash and a file name per line,
+// the name marked with * in binary mode
+func parseChecksums(content string) map[string]string {
+	sums := map[string]string{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || len(fields[0]) != sha256.Size*2 {
+			continue
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+func formatChecksums(sums map[string]string) string {
+	var b strings.Builder
+	for _, name := range sortedKeys(sums) {
+		fmt.Fprintf(&b, "%s  %s\n", sums[name], name)
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksums(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "hello.txt")
+	require.NoError(t, os.WriteFile(file, []byte("hello\n"), 0644))
+	sum, err := fileSHA256(file)
+	require.NoError(t, err)
+	assert.Equal(t, "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03", sum)
+
+	content := "# comment\n" +
+		"5891B5B522D5DF086D0FF0B110FBD9D21BB4FC7163AF34D08286A2E846F6BE03 *hello.txt\n" +
+		"abc  short.txt\n"
+	sums := parseChecksums(content)
+	assert.Equal(t, map[string]string{"hello.txt": sum}, sums)
+
+	sums["app.tar.gz"] = strings.Repeat("0", 64)
+	formatted := formatChecksums(sums)
+	assert.Equal(t, strings.Repeat("0", 64)+"  app.tar.gz\n"+sum+"  hello.txt\n", formatted)
+	assert.Equal(t, sums, parseChecksums(formatted))
+}
+
+func TestIsChecksumsAsset(t *testing.T) {
+	assert.True(t, isChecksumsAsset("SHA256SUMS"))
+	assert.True(t, isChecksumsAsset("app_1.2.0_checksums.txt"))
+	assert.False(t, isChecksumsAsset("app.tar.gz"))
+}
+
+func TestTransferAllCountsFailures(t *testing.T) {
+	failed := transferAll([]int{1, 2, 3, 4, 5}, 2, func(n int) (string, error) {
+		if n%2 == 0 {
+			return "", errors.New("even")
+		}
+		return "ok", nil
+	})
+	assert.Equal(t, 2, failed)
+}
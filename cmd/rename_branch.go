@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/EndlessUphill/git-helper/internal/git"
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	renameKeepOld   bool
+	renameLocalOnly bool
+)
+
+var renameBranchCmd = &cobra.Command{
+	Use:   "rename-branch <old> <new>",
+	Short: "Rename a branch locally and on GitHub",
+	Long: `Rename a branch, typically the default branch (e.g. master to main), everywhere
+it is used.
+
+This command:
+1. Renames the local branch
+2. Pushes the new branch to origin and tracks it
+3. Makes it the default branch on GitHub if the old one was, and updates origin/HEAD
+4. Moves the branch protection rules to the new branch
+5. Retargets open pull requests to the new branch
+6. Deletes the old branch from origin after confirmation
+
+Example:
+  githelper rename-branch master main             # Rename the default branch
+  githelper rename-branch master main --dry-run   # Show the plan
+  githelper rename-branch dev develop --keep-old  # Leave the old branch on origin
+  githelper rename-branch old new --local-only    # Only rename the local branch`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRenameBranch,
+}
+
+func init() {
+	rootCmd.AddCommand(renameBranchCmd)
+	renameBranchCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be done without doing it")
+	renameBranchCmd.Flags().BoolVar(&renameKeepOld, "keep-old", false, "don't delete the old branch from origin")
+	renameBranchCmd.Flags().BoolVar(&renameLocalOnly, "local-only", false, "only rename the local branch")
+}
+
+func runRenameBranch(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	oldName, newName := args[0], args[1]
+
+	if err := gitCommand("check-ref-format", "--branch", newName).Run(); err != nil {
+		return fmt.Errorf("'%s' is not a valid branch name", newName)
+	}
+
+	hasOld := gitCommand("rev-parse", "--verify", "-q", "refs/heads/"+oldName).Run() == nil
+	hasNew := gitCommand("rev-parse", "--verify", "-q", "refs/heads/"+newName).Run() == nil
+	switch {
+	case hasOld && hasNew:
+		return fmt.Errorf("branch '%s' already exists", newName)
+	case !hasOld && !hasNew:
+		return fmt.Errorf("branch '%s' does not exist", oldName)
+	}
+
+	// Connect before changing anything, so a missing token doesn't leave the
+	// rename half done
+	var client *github.Client
+	var owner, name string
+	if !renameLocalOnly {
+		var err error
+		client, owner, name, err = originClient()
+		if err != nil {
+			return fmt.Errorf("%w. Pass --local-only to only rename the local branch", err)
+		}
+	}
+
+	if dryRun {
+		ui.Step("🔍 Dry run - the following would be done:")
+		if hasOld {
+			ui.Printf("  rename local branch %s to %s\n", oldName, newName)
+		}
+		if !renameLocalOnly {
+			ui.Printf("  push %s to origin\n", newName)
+			ui.Printf("  make %s the default branch of %s/%s if %s is\n", newName, owner, name, oldName)
+			ui.Printf("  move branch protection from %s to %s\n", oldName, newName)
+			ui.Printf("  retarget open pull requests from %s to %s\n", oldName, newName)
+			if !renameKeepOld {
+				ui.Printf("  delete %s from origin\n", oldName)
+			}
+		}
+		return nil
+	}
+
+	if hasOld {
+		ui.Stepf("🏷️  Renaming %s to %s...", oldName, newName)
+		renameCmd := gitCommand("branch", "-m", oldName, newName)
+		renameCmd.Stderr = os.Stderr
+		if err := renameCmd.Run(); err != nil {
+			return fmt.Errorf("failed to rename branch: %w", err)
+		}
+	} else {
+		ui.Infof("%s is already renamed to %s locally", oldName, newName)
+	}
+	if renameLocalOnly {
+		ui.Success("Branch renamed!")
+		return nil
+	}
+
+	ui.Stepf("📤 Pushing %s to origin...", newName)
+	pushCmd := gitCommand("push", "--set-upstream", "origin", newName)
+	pushCmd.Stdout = os.Stdout
+	pushCmd.Stderr = os.Stderr
+	if err := pushCmd.Run(); err != nil {
+		return fmt.Errorf("failed to push %s: %w", newName, err)
+	}
+
+	ctx := context.Background()
+	repo := owner + "/" + name
+
+	current, err := client.DefaultBranch(ctx, owner, name)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", repo, err)
+	}
+	if current == oldName {
+		ui.Stepf("🏠 Making %s the default branch of %s...", newName, repo)
+		if err := client.SetDefaultBranch(ctx, owner, name, newName); err != nil {
+			return fmt.Errorf("failed to change the default branch: %w", err)
+		}
+		if _, err := git.SetDefaultBranch(commandCtx, "origin", ""); err != nil {
+			ui.Warnf("%v", err)
+		}
+	}
+
+	ui.Step("🛡️  Moving branch protection...")
+	moved, err := client.MoveBranchProtection(ctx, owner, name, oldName, newName)
+	if err != nil {
+		return fmt.Errorf("failed to move branch protection: %w", err)
+	}
+	if moved {
+		ui.Printf("  protection rules of %s now apply to %s\n", oldName, newName)
+	} else {
+		ui.Printf("  %s is not protected\n", oldName)
+	}
+
+	ui.Step("🔀 Retargeting open pull requests...")
+	retargeted, err := client.RetargetPullRequests(ctx, owner, name, oldName, newName)
+	for _, number := range retargeted {
+		ui.Printf("  #%d now targets %s\n", number, newName)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retarget pull requests: %w", err)
+	}
+	if len(retargeted) == 0 {
+		ui.Printf("  no open pull requests target %s\n", oldName)
+	}
+
+	if !renameKeepOld {
+		ui.Printf("\n🗑️  Delete %s from origin? Pull requests and links to it are already moved.\n", oldName)
+		if confirmAction() {
+			deleteCmd := gitCommand("push", "origin", "--delete", oldName)
+			deleteCmd.Stderr = os.Stderr
+			if err := deleteCmd.Run(); err != nil {
+				ui.Warnf("Failed to delete %s from origin: %v", oldName, err)
+			}
+		} else {
+			ui.Infof("Keeping %s on origin. Delete it later with 'git push origin --delete %s'", oldName, oldName)
+		}
+	}
+
+	if viper.GetString("default_branch") == oldName {
+		ui.Warnf("\nThe default_branch setting is still %s. Update it with 'githelper config set --local default_branch %s'", oldName, newName)
+	}
+
+	ui.Successf("\nRenamed %s to %s!", oldName, newName)
+	ui.Println("\nOther clones can switch with:")
+	ui.Printf("  git branch -m %s %s\n", oldName, newName)
+	ui.Println("  git fetch origin --prune")
+	ui.Printf("  git branch -u origin/%s %s\n", newName, newName)
+	ui.Println("  git remote set-head origin --auto")
+	return nil
+}
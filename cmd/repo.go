@@ -0,0 +1,445 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	repoApplyRepos   []string
+	repoApplyCreate  bool
+	repoListFile     string
+	repoTransferTo   string
+	repoTransferTeam []string
+)
+
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Manage GitHub repositories",
+	Long: `Manage the settings and lifecycle of GitHub repositories.
+
+Commands that change repositories take them as owner/name arguments, from a
+file with one repository per line (--file, "-" for standard input), or
+default to the origin repository.
+
+Example:
+  githelper repo apply settings.yaml --dry-run
+  githelper repo archive octo/old-app octo/old-lib
+  githelper repo transfer --to new-org --file repos.txt`,
+}
+
+var repoArchiveCmd = &cobra.Command{
+	Use:   "archive [owner/name...]",
+	Short: "Archive repositories",
+	Long: `Archive repositories, making them read-only. Archived repositories can be
+unarchived later with 'githelper repo unarchive'.
+
+Example:
+  githelper repo archive octo/old-app
+  githelper repo archive --file stale-repos.txt --dry-run`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRepoSetArchived(args, true)
+	},
+}
+
+var repoUnarchiveCmd = &cobra.Command{
+	Use:   "unarchive [owner/name...]",
+	Short: "Unarchive repositories",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRepoSetArchived(args, false)
+	},
+}
+
+var repoTransferCmd = &cobra.Command{
+	Use:   "transfer [owner/name...] --to <owner>",
+	Short: "Transfer repositories to another user or organization",
+	Long: `Transfer repositories to another user or organization. GitHub redirects
+the old URLs, and the origin remote of this clone is updated when it is
+transferred. Transferring to a user needs their acceptance.
+
+Example:
+  githelper repo transfer --to new-org
+  githelper repo transfer octo/app octo/lib --to new-org --team core
+  cat repos.txt | githelper repo transfer --file - --to new-org`,
+	RunE: runRepoTransfer,
+}
+
+var repoApplyCmd = &cobra.Command{
+	Use:   "apply <settings.yaml>",
+	Short: "Apply declarative settings to repositories",
+	Long: `Make one or more repositories match a settings file: general settings,
+topics, merge options, default branch, branch protection and labels.
+
+The changes are shown first and applied after confirmation; --dry-run only
+shows them. Settings missing from the file are left unchanged, except branch
+protection, where every rule of a listed branch is declared.
+
+The repositories come from --repo, the repositories list of the file, or
+the origin repository.
+
+Example settings.yaml:
+  repositories: [octo/app, octo/lib]
+  settings:
+    description: Our app
+    topics: [go, cli]
+    allow_merge_commit: false
+    allow_squash_merge: true
+    delete_branch_on_merge: true
+    default_branch: main
+  branch_protection:
+    main:
+      required_status_checks: [build, test]
+      required_approving_reviews: 1
+      dismiss_stale_reviews: true
+    old-main: null        # remove protection
+  labels:
+    - {name: bug, color: d73a4a, description: Something isn't working}
+  prune_labels: false     # delete labels that aren't listed
+
+Example:
+  githelper repo apply settings.yaml --dry-run
+  githelper repo apply settings.yaml --repo octo/other
+  githelper repo apply settings.yaml --create   # Create missing repositories`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRepoApply,
+}
+
+func init() {
+	rootCmd.AddCommand(repoCmd)
+	repoCmd.AddCommand(repoApplyCmd)
+	repoApplyCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the changes without applying them")
+	repoApplyCmd.Flags().StringSliceVar(&repoApplyRepos, "repo", nil, "repository to apply to as owner/name (repeatable, overrides the file)")
+	repoApplyCmd.Flags().BoolVar(&repoApplyCreate, "create", false, "create repositories that don't exist")
+
+	for _, cmd := range []*cobra.Command{repoArchiveCmd, repoUnarchiveCmd, repoTransferCmd} {
+		repoCmd.AddCommand(cmd)
+		cmd.Flags().StringVarP(&repoListFile, "file", "f", "", "file with one owner/name per line (- for standard input)")
+		cmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the repositories without changing them")
+	}
+	repoTransferCmd.Flags().StringVar(&repoTransferTo, "to", "", "user or organization to transfer to")
+	repoTransferCmd.Flags().StringSliceVar(&repoTransferTeam, "team", nil, "team slug of the new organization to give access (repeatable)")
+	repoTransferCmd.MarkFlagRequired("to")
+}
+
+// repoTargets returns a client for the given owner/name repositories, or
+// for the origin repository when none are given
+func repoTargets(repos []string) (*github.Client, []string, error) {
+	for _, repo := range repos {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return nil, nil, fmt.Errorf("invalid repository '%s', use owner/name", repo)
+		}
+	}
+
+	if len(repos) == 0 {
+		client, owner, name, err := originClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, []string{owner + "/" + name}, nil
+	}
+
+	host, err := resolveHost("")
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := newHostClient(host)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, repos, nil
+}
+
+// readRepoList reads owner/name lines from a file, or standard input for
+// "-", skipping blank lines and # comments
+func readRepoList(path string, stdin io.Reader) ([]string, error) {
+	var content []byte
+	var err error
+	if path == "-" {
+		content, err = io.ReadAll(stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository list: %w", err)
+	}
+
+	var repos []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			repos = append(repos, line)
+		}
+	}
+	return repos, nil
+}
+
+// bulkRepoTargets resolves the repositories of the archive and transfer
+// commands from the arguments and --file
+func bulkRepoTargets(args []string) (*github.Client, []string, error) {
+	repos := args
+	if repoListFile != "" {
+		listed, err := readRepoList(repoListFile, os.Stdin)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(listed) == 0 {
+			return nil, nil, fmt.Errorf("%s lists no repositories", repoListFile)
+		}
+		repos = append(repos, listed...)
+	}
+	return repoTargets(repos)
+}
+
+// repoPlan is what 'repo apply' does to one repository
+type repoPlan struct {
+	Repo    string
+	Create  bool
+	Changes []github.RepoChange
+}
+
+func runRepoApply(cmd *cobra.Command, args []string) error {
+	spec, err := github.LoadRepoSpec(args[0])
+	if err != nil {
+		return err
+	}
+	if len(repoApplyRepos) > 0 {
+		spec.Repositories = repoApplyRepos
+	}
+
+	client, repos, err := repoTargets(spec.Repositories)
+	if err != nil {
+		return fmt.Errorf("%w. List the repositories in the file or pass --repo", err)
+	}
+	spec.Repositories = repos
+	ctx := context.Background()
+
+	var plans []repoPlan
+	total := 0
+	for _, repo := range spec.Repositories {
+		owner, name, _ := strings.Cut(repo, "/")
+		ui.Stepf("🔍 Checking %s...", repo)
+		plan := repoPlan{Repo: repo}
+		plan.Changes, err = client.PlanRepo(ctx, owner, name, *spec)
+		if errors.Is(err, github.ErrRepoNotFound) {
+			if !repoApplyCreate {
+				return fmt.Errorf("%s not found. Pass --create to create it", repo)
+			}
+			plan.Create = true
+		} else if err != nil {
+			return fmt.Errorf("failed to check %s: %w", repo, err)
+		}
+		plans = append(plans, plan)
+		total += len(plan.Changes)
+		if plan.Create {
+			total++
+		}
+	}
+
+	ui.Println()
+	for _, plan := range plans {
+		printRepoPlan(plan)
+	}
+	if total == 0 {
+		ui.Success("Everything is up to date")
+		return nil
+	}
+	if dryRun {
+		ui.Stepf("\n🔍 Dry run - %d change(s) not applied", total)
+		return nil
+	}
+
+	ui.Printf("\n%d change(s) will be applied\n", total)
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	failed := 0
+	for _, plan := range plans {
+		if err := applyRepoPlan(ctx, client, spec, plan); err != nil {
+			ui.Errorf("%s: %v", plan.Repo, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d repository(ies) not fully updated", failed)
+	}
+	ui.Success("Settings applied!")
+	notifyResult(fmt.Sprintf("Applied %d change(s) from %s", total, args[0]), spec.Repositories...)
+	return nil
+}
+
+func printRepoPlan(plan repoPlan) {
+	if !plan.Create && len(plan.Changes) == 0 {
+		ui.Successf("%s is up to date", plan.Repo)
+		return
+	}
+	ui.Stepf("📝 %s:", plan.Repo)
+	if plan.Create {
+		ui.Println("  + create repository, then apply every setting")
+	}
+	for _, change := range plan.Changes {
+		ui.Printf("  ~ %s\n", change.Summary)
+		for _, detail := range change.Details {
+			ui.Printf("      %s\n", detail)
+		}
+	}
+}
+
+// applyRepoPlan creates the repository if needed and applies its changes,
+// stopping at the first error
+func applyRepoPlan(ctx context.Context, client *github.Client, spec *github.RepoSpec, plan repoPlan) error {
+	owner, name, _ := strings.Cut(plan.Repo, "/")
+	if plan.Create {
+		user, _, err := client.CurrentUser(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get current user: %w", err)
+		}
+		isOrg := !strings.EqualFold(owner, user)
+		ui.Stepf("🏗️  Creating %s...", plan.Repo)
+		if err := client.CreateRepository(ctx, name, owner, isOrg, spec.Settings.RepoConfig()); err != nil {
+			return fmt.Errorf("failed to create repository: %w", err)
+		}
+		if plan.Changes, err = client.PlanRepo(ctx, owner, name, *spec); err != nil {
+			return err
+		}
+	}
+
+	for _, change := range plan.Changes {
+		ui.Stepf("🔧 %s: %s", plan.Repo, change.Summary)
+		if err := change.Apply(ctx); err != nil {
+			return fmt.Errorf("failed to %s: %w", change.Summary, err)
+		}
+	}
+	return nil
+}
+
+func runRepoSetArchived(args []string, archived bool) error {
+	client, repos, err := bulkRepoTargets(args)
+	if err != nil {
+		return err
+	}
+	action, done := "archive", "Archived"
+	if !archived {
+		action, done = "unarchive", "Unarchived"
+	}
+
+	ui.Stepf("📦 %d repository(ies) will be %sd:", len(repos), action)
+	for _, repo := range repos {
+		ui.Printf("  %s\n", repo)
+	}
+	if dryRun {
+		ui.Step("\n🔍 Dry run - no repositories were changed")
+		return nil
+	}
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	ctx := context.Background()
+	failed := 0
+	for _, repo := range repos {
+		owner, name, _ := strings.Cut(repo, "/")
+		changed, err := client.SetArchived(ctx, owner, name, archived)
+		switch {
+		case err != nil:
+			ui.Errorf("%s: %v", repo, err)
+			failed++
+		case !changed:
+			ui.Infof("%s was already %sd", repo, action)
+		default:
+			ui.Successf("%s %s", done, repo)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to %s %d repository(ies)", action, failed)
+	}
+	notifyResult(fmt.Sprintf("%s %d repository(ies)", done, len(repos)), repos...)
+	return nil
+}
+
+func runRepoTransfer(cmd *cobra.Command, args []string) error {
+	client, repos, err := bulkRepoTargets(args)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	var teamIDs []int64
+	for _, slug := range repoTransferTeam {
+		id, err := client.TeamID(ctx, repoTransferTo, slug)
+		if err != nil {
+			return fmt.Errorf("team '%s' not found in %s: %w", slug, repoTransferTo, err)
+		}
+		teamIDs = append(teamIDs, id)
+	}
+
+	ui.Stepf("🚚 %d repository(ies) will be transferred to %s:", len(repos), repoTransferTo)
+	for _, repo := range repos {
+		_, name, _ := strings.Cut(repo, "/")
+		ui.Printf("  %s → %s/%s\n", repo, repoTransferTo, name)
+	}
+	if dryRun {
+		ui.Step("\n🔍 Dry run - no repositories were transferred")
+		return nil
+	}
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	failed := 0
+	for _, repo := range repos {
+		owner, name, _ := strings.Cut(repo, "/")
+		if strings.EqualFold(owner, repoTransferTo) {
+			ui.Infof("%s already belongs to %s", repo, repoTransferTo)
+			continue
+		}
+		if err := client.TransferRepository(ctx, owner, name, repoTransferTo, teamIDs); err != nil {
+			ui.Errorf("%s: %v", repo, err)
+			failed++
+			continue
+		}
+		ui.Successf("Transferred %s to %s", repo, repoTransferTo)
+		updateTransferredOrigin(repo, repoTransferTo+"/"+name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to transfer %d repository(ies)", failed)
+	}
+	notifyResult(fmt.Sprintf("Transferred %d repository(ies) to %s", len(repos), repoTransferTo), repos...)
+	return nil
+}
+
+// updateTransferredOrigin points origin to the new location when this clone
+// is one of the transferred repositories
+func updateTransferredOrigin(from, to string) {
+	originURL, err := getOriginURL()
+	if err != nil {
+		return
+	}
+	hostname, repoPath, err := github.ParseRepoURL(originURL)
+	if err != nil || !strings.EqualFold(strings.TrimSuffix(repoPath, ".git"), from) {
+		return
+	}
+
+	host, err := resolveHost(hostname)
+	if err != nil {
+		return
+	}
+	ssh := !strings.HasPrefix(originURL, "https://") && !strings.HasPrefix(originURL, "http://")
+	newURL := host.CloneURL(to, ssh)
+	if err := gitCommand("remote", "set-url", "origin", newURL).Run(); err != nil {
+		ui.Warnf("Failed to update origin, run: git remote set-url origin %s", newURL)
+		return
+	}
+	ui.Stepf("🔗 origin now points to %s", newURL)
+}
@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRepoList(t *testing.T) {
+	input := "# stale repositories\nocto/app\n\n  octo/lib  # archived in 2023\n"
+	repos, err := readRepoList("-", strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"octo/app", "octo/lib"}, repos)
+}
+
+func TestRepoTargetsValidatesNames(t *testing.T) {
+	for _, repo := range []string{"app", "/app", "octo/", "octo/app/extra"} {
+		_, _, err := repoTargets([]string{repo})
+		assert.ErrorContains(t, err, "use owner/name", repo)
+	}
+}
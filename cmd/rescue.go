@@ -3,9 +3,10 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -19,6 +20,9 @@ This command helps when you're stuck in a "detached HEAD" state:
 2. Shows recent commits for reference
 3. Creates a new branch from current position
 
+Other commands warn when HEAD is detached with commits that are on no
+branch, and offer to create the branch before they run.
+
 Useful when:
 - You checked out a specific commit without -b
 - You're in "detached HEAD" state
@@ -49,8 +53,8 @@ func runRescue(cmd *cobra.Command, args []string) error {
 	}
 
 	// Show current position
-	fmt.Println("🔍 Current HEAD position:")
-	showCmd := exec.Command("git", "log", "--oneline", "-n", "1")
+	ui.Step("🔍 Current HEAD position:")
+	showCmd := gitCommand("log", "--oneline", "-n", "1")
 	showCmd.Stdout = os.Stdout
 	showCmd.Stderr = os.Stderr
 	if err := showCmd.Run(); err != nil {
@@ -58,8 +62,8 @@ func runRescue(cmd *cobra.Command, args []string) error {
 	}
 
 	// Show recent commits
-	fmt.Println("\n📜 Recent commits:")
-	logCmd := exec.Command("git", "log", "--oneline", "-n", "5")
+	ui.Step("\n📜 Recent commits:")
+	logCmd := gitCommand("log", "--oneline", "-n", "5")
 	logCmd.Stdout = os.Stdout
 	logCmd.Stderr = os.Stderr
 	if err := logCmd.Run(); err != nil {
@@ -78,27 +82,124 @@ func runRescue(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create new branch
-	fmt.Printf("\n🌱 Creating new branch '%s' from current position...\n", branchName)
-	checkoutCmd := exec.Command("git", "checkout", "-b", branchName)
-	checkoutCmd.Stderr = os.Stderr
-	if err := checkoutCmd.Run(); err != nil {
-		return fmt.Errorf("failed to create branch: %w", err)
+	ui.Stepf("\n🌱 Creating new branch '%s' from current position...", branchName)
+	if err := createRescueBranch(branchName, true); err != nil {
+		return err
+	}
+
+	ui.Successf("Successfully created branch '%s'!", branchName)
+	ui.Println("\nYou can now continue working on this branch.")
+	return nil
+}
+
+// detachedGuardSkip lists the commands that don't warn about commits left on
+// a detached HEAD: rescue saves them itself and the others never touch HEAD
+var detachedGuardSkip = map[string]bool{
+	"rescue":     true,
+	"explain":    true,
+	"watch":      true,
+	"version":    true,
+	"help":       true,
+	"completion": true,
+	"__complete": true,
+}
+
+// guardDetachedHead warns when HEAD is detached with commits that are on no
+// branch, which a command moving HEAD would leave behind, and offers to save
+// them on a rescue branch first. Each HEAD commit is warned about once.
+func guardDetachedHead(cmd *cobra.Command) {
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		if detachedGuardSkip[c.Name()] {
+			return
+		}
+	}
+	if detached, err := isDetachedHead(); err != nil || !detached {
+		return
+	}
+	orphans := orphanedCommits()
+	if len(orphans) == 0 {
+		return
+	}
+	head, err := gitCommand("rev-parse", "HEAD").Output()
+	if err != nil {
+		return
+	}
+	marker, err := gitCommand("rev-parse", "--path-format=absolute", "--git-path", "githelper/detached-warned").Output()
+	if err != nil {
+		return
+	}
+	markerFile := strings.TrimSpace(string(marker))
+	if warned, err := os.ReadFile(markerFile); err == nil && string(warned) == string(head) {
+		return
 	}
+	os.MkdirAll(filepath.Dir(markerFile), 0755)
+	os.WriteFile(markerFile, head, 0644)
 
-	fmt.Printf("✅ Successfully created branch '%s'!\n", branchName)
-	fmt.Println("\nYou can now continue working on this branch.")
+	ui.Warnf("HEAD is detached with %d commit(s) that are on no branch:", len(orphans))
+	for i, commit := range orphans {
+		if i == 5 {
+			ui.Printf("    ... and %d more\n", len(orphans)-i)
+			break
+		}
+		ui.Printf("    %s %s\n", shortSHA(commit), commitSubject(commit))
+	}
+	ui.Println("   Checking out anything else leaves them behind.")
+	if !isInteractive() {
+		ui.Step("💡 Save them with 'githelper rescue <branch>'")
+		return
+	}
+
+	msg, _ := gitCommand("log", "-1", "--pretty=%B").Output()
+	suggestion := generateBranchName(string(msg))
+	name := readInput(fmt.Sprintf("Save them on a new branch? Enter a name, Enter for '%s' or '-' to skip: ", suggestion))
+	if name == "-" {
+		ui.Step("💡 Save them later with 'githelper rescue <branch>'")
+		ui.Println()
+		return
+	}
+	if name == "" {
+		name = suggestion
+	}
+	if err := createRescueBranch(name, false); err != nil {
+		ui.Warnf("%v", err)
+		ui.Println()
+		return
+	}
+	ui.Printf("✅ Saved them on branch '%s', HEAD stays detached\n\n", name)
+}
+
+// orphanedCommits returns the commits reachable from HEAD but from no branch,
+// tag or remote branch, newest first
+func orphanedCommits() []string {
+	output, err := gitCommand("rev-list", "HEAD", "--not", "--branches", "--tags", "--remotes").Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(output))
+}
+
+// createRescueBranch creates a branch at HEAD, switching to it when checkout
+// is set
+func createRescueBranch(name string, checkout bool) error {
+	args := []string{"branch", name}
+	if checkout {
+		args = []string{"checkout", "-b", name}
+	}
+	if output, err := gitCommand(args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create branch: %s", strings.TrimSpace(string(output)))
+	}
 	return nil
 }
 
 func isDetachedHead() (bool, error) {
 	// Get current HEAD reference
-	refCmd := exec.Command("git", "symbolic-ref", "-q", "HEAD")
+	refCmd := gitCommand("symbolic-ref", "-q", "HEAD")
 	err := refCmd.Run()
 	
 	// If the command fails, we're in detached HEAD
 	if err != nil {
 		// Verify it's actually a detached HEAD and not some other error
-		headCmd := exec.Command("git", "rev-parse", "--verify", "HEAD")
+		headCmd := gitCommand("rev-parse", "--verify", "HEAD")
 		if headCmd.Run() == nil {
 			return true, nil
 		}
@@ -110,7 +211,7 @@ func isDetachedHead() (bool, error) {
 
 func getBranchNameInteractive() string {
 	// Get current commit message for suggestion
-	msgCmd := exec.Command("git", "log", "-1", "--pretty=%B")
+	msgCmd := gitCommand("log", "-1", "--pretty=%B")
 	msg, err := msgCmd.Output()
 	if err != nil {
 		msg = []byte("")
@@ -119,11 +220,8 @@ func getBranchNameInteractive() string {
 	// Generate suggestion from commit message
 	suggestion := generateBranchName(string(msg))
 
-	fmt.Printf("\nSuggested branch name: %s\n", suggestion)
-	fmt.Print("Enter branch name (or press Enter to use suggestion): ")
-	
-	var input string
-	fmt.Scanln(&input)
+	ui.Printf("\nSuggested branch name: %s\n", suggestion)
+	input := readInput("Enter branch name (or press Enter to use suggestion): ")
 	
 	if input == "" {
 		return suggestion
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateBranchName(t *testing.T) {
+	assert.Equal(t, "add-login-page", generateBranchName("feat: Add login page!\n\nDetails"))
+	assert.Equal(t, "branch-2fa-support", generateBranchName("2FA support"))
+	assert.Equal(t, "branch-", generateBranchName(""))
+}
+
+func TestOrphanedCommits(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	runGit(t, tmpDir, "commit", "-m", "initial")
+	runGit(t, tmpDir, "branch", "-M", "main")
+	runGit(t, tmpDir, "checkout", "-q", "--detach")
+	assert.Empty(t, orphanedCommits())
+
+	runGit(t, tmpDir, "commit", "-q", "--allow-empty", "-m", "first")
+	runGit(t, tmpDir, "commit", "-q", "--allow-empty", "-m", "second")
+	orphans := orphanedCommits()
+	require.Len(t, orphans, 2)
+	head, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	require.NoError(t, err)
+	assert.Equal(t, string(head[:len(head)-1]), orphans[0])
+
+	// A rescue branch keeps them, HEAD stays detached
+	require.NoError(t, createRescueBranch("saved", false))
+	assert.Empty(t, orphanedCommits())
+	detached, err := isDetachedHead()
+	require.NoError(t, err)
+	assert.True(t, detached)
+	assert.Error(t, createRescueBranch("saved", false))
+}
+
+func TestGuardDetachedHeadWarnsOnce(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+	nonInteractive = true
+	defer func() { nonInteractive = false }()
+
+	runGit(t, tmpDir, "commit", "-m", "initial")
+	runGit(t, tmpDir, "checkout", "-q", "--detach")
+	runGit(t, tmpDir, "commit", "-q", "--allow-empty", "-m", "lost work")
+
+	guardDetachedHead(switchCmd)
+	assert.True(t, gitPathExists("githelper/detached-warned"))
+	// Non-interactive runs only warn
+	assert.Len(t, orphanedCommits(), 1)
+
+	// Skipped commands don't mark HEAD as warned about
+	os.Remove(".git/githelper/detached-warned")
+	guardDetachedHead(rescueCmd)
+	assert.False(t, gitPathExists("githelper/detached-warned"))
+}
@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -26,11 +28,30 @@ Example:
 	RunE: runResolve,
 }
 
+var resolveChoice string
+
 func init() {
 	rootCmd.AddCommand(resolveCmd)
+	resolveCmd.Flags().StringVar(&resolveChoice, "choose", "", "resolve without prompting: ours or theirs")
+	resolveCmd.Flags().StringVar(&preselectedFile, "select-file", "", "file to resolve without prompting")
 }
 
 func runResolve(cmd *cobra.Command, args []string) error {
+	if err := resolveConflict(args); err != nil {
+		return err
+	}
+
+	// Resolving the last conflict from restoring a sync stash completes the sync
+	if !hasConflicts() {
+		if stash, err := pendingSyncStash(); err == nil && stash != "" {
+			return finishSyncStash()
+		}
+	}
+	return nil
+}
+
+// resolveConflict resolves one conflicted file, the given one or a selected one
+func resolveConflict(args []string) error {
 	// Check if there are any conflicts
 	if !hasConflicts() {
 		return fmt.Errorf("no merge conflicts found")
@@ -39,6 +60,10 @@ func runResolve(cmd *cobra.Command, args []string) error {
 	var fileToResolve string
 	var err error
 
+	if len(args) == 0 && preselectedFile != "" {
+		args = []string{preselectedFile}
+	}
+
 	if len(args) > 0 {
 		// Verify the specified file has conflicts
 		fileToResolve = args[0]
@@ -58,7 +83,7 @@ func runResolve(cmd *cobra.Command, args []string) error {
 
 	// Show diff and get resolution choice
 	if err := showConflictDiff(fileToResolve); err != nil {
-		fmt.Println("⚠️  Failed to show diff, continuing anyway...")
+		ui.Warn("Failed to show diff, continuing anyway...")
 	}
 
 	choice := getResolutionChoice(fileToResolve)
@@ -75,31 +100,31 @@ func runResolve(cmd *cobra.Command, args []string) error {
 	}
 
 	// Checkout the chosen version
-	checkoutCmd := exec.Command("git", "checkout", checkoutFlag, fileToResolve)
+	checkoutCmd := gitCommand("checkout", checkoutFlag, fileToResolve)
 	checkoutCmd.Stderr = os.Stderr
 	if err := checkoutCmd.Run(); err != nil {
 		return fmt.Errorf("failed to checkout version: %w", err)
 	}
 
 	// Stage the resolved file
-	addCmd := exec.Command("git", "add", fileToResolve)
+	addCmd := gitCommand("add", fileToResolve)
 	addCmd.Stderr = os.Stderr
 	if err := addCmd.Run(); err != nil {
 		return fmt.Errorf("failed to stage resolved file: %w", err)
 	}
 
-	fmt.Printf("✅ Conflict in '%s' resolved!\n", fileToResolve)
+	ui.Successf("Conflict in '%s' resolved!", fileToResolve)
 	return nil
 }
 
 func hasConflicts() bool {
-	cmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
+	cmd := gitCommand("diff", "--name-only", "--diff-filter=U")
 	output, err := cmd.Output()
 	return err == nil && len(output) > 0
 }
 
 func isFileConflicted(file string) bool {
-	cmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
+	cmd := gitCommand("diff", "--name-only", "--diff-filter=U")
 	output, err := cmd.Output()
 	if err != nil {
 		return false
@@ -116,7 +141,7 @@ func isFileConflicted(file string) bool {
 
 func selectConflictedFile() (string, error) {
 	// Try using fzf if available
-	if !noFzf {
+	if !noFzf && isInteractive() {
 		if _, err := exec.LookPath("fzf"); err == nil {
 			return selectConflictedFileWithFzf()
 		}
@@ -126,7 +151,7 @@ func selectConflictedFile() (string, error) {
 
 func selectConflictedFileWithFzf() (string, error) {
 	// Get list of conflicted files
-	diffCmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
+	diffCmd := gitCommand("diff", "--name-only", "--diff-filter=U")
 	diffOutput, err := diffCmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to list conflicted files: %w", err)
@@ -163,21 +188,19 @@ func selectConflictedFileWithFzf() (string, error) {
 
 func selectConflictedFileWithList() (string, error) {
 	// Get list of conflicted files
-	diffCmd := exec.Command("git", "diff", "--name-only", "--diff-filter=U")
+	diffCmd := gitCommand("diff", "--name-only", "--diff-filter=U")
 	output, err := diffCmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to list conflicted files: %w", err)
 	}
 
 	files := strings.Split(strings.TrimSpace(string(output)), "\n")
-	fmt.Println("\nConflicted files:")
+	ui.Println("\nConflicted files:")
 	for i, file := range files {
-		fmt.Printf("%2d: %s\n", i+1, file)
+		ui.Printf("%2d: %s\n", i+1, file)
 	}
 
-	fmt.Print("\nSelect file number (or press Enter to cancel): ")
-	var input string
-	fmt.Scanln(&input)
+	input := readInput("\nSelect file number (or press Enter to cancel): ")
 
 	if input == "" {
 		return "", nil
@@ -192,26 +215,36 @@ func selectConflictedFileWithList() (string, error) {
 }
 
 func showConflictDiff(file string) error {
-	diffCmd := exec.Command("git", "diff", file)
-	
+	diffCmd := gitCommand("diff", file)
+	diffCmd.Stderr = os.Stderr
+
 	// Use bat if available
 	if _, err := exec.LookPath("bat"); err == nil {
-		diffCmd = exec.Command("sh", "-c", fmt.Sprintf("git diff %s | bat --style=numbers --color=always --language=diff", file))
+		diff, err := diffCmd.Output()
+		if err != nil {
+			return err
+		}
+		batCmd := exec.Command("bat", "--style=numbers", "--color=always", "--language=diff")
+		batCmd.Stdin = bytes.NewReader(diff)
+		batCmd.Stdout = os.Stdout
+		batCmd.Stderr = os.Stderr
+		return batCmd.Run()
 	}
-	
+
 	diffCmd.Stdout = os.Stdout
-	diffCmd.Stderr = os.Stderr
 	return diffCmd.Run()
 }
 
 func getResolutionChoice(file string) string {
-	fmt.Printf("\nResolving conflicts in '%s'\n", file)
-	fmt.Println("Choose resolution:")
-	fmt.Println("  (o)urs   - Keep our version (current branch)")
-	fmt.Println("  (t)heirs - Keep their version (merging branch)")
+	if resolveChoice != "" {
+		return strings.ToLower(resolveChoice)
+	}
+
+	ui.Printf("\nResolving conflicts in '%s'\n", file)
+	ui.Println("Choose resolution:")
+	ui.Println("  (o)urs   - Keep our version (current branch)")
+	ui.Println("  (t)heirs - Keep their version (merging branch)")
 	
-	fmt.Print("\nYour choice [o/t]: ")
-	var choice string
-	fmt.Scanln(&choice)
+	choice := readInput("\nYour choice [o/t]: ")
 	return strings.ToLower(choice)
 } 
\ No newline at end of file
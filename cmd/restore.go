@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -21,15 +22,22 @@ It will:
 2. Let you select the commit to restore
 3. Create a new branch from that commit
 
-Example: githelper restore`,
+Example:
+  githelper restore                                   # Interactive commit selection
+  githelper restore --select 1a2b3c4d --name feature  # Skip the prompts`,
 	RunE: runRestore,
 }
 
-var noFzf bool
+var (
+	noFzf             bool
+	restoreBranchName string
+)
 
 func init() {
 	rootCmd.AddCommand(restoreCmd)
 	restoreCmd.Flags().BoolVar(&noFzf, "no-fzf", false, "disable fzf usage even if available")
+	restoreCmd.Flags().StringVar(&preselectedItem, "select", "", "commit to restore without prompting")
+	restoreCmd.Flags().StringVar(&restoreBranchName, "name", "", "name of the restored branch")
 }
 
 func runRestore(cmd *cobra.Command, args []string) error {
@@ -38,10 +46,10 @@ func runRestore(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	fmt.Println("🔍 Searching for git history...")
+	ui.Step("🔍 Searching for git history...")
 
 	// Get git reflog
-	reflogCmd := exec.Command("git", "reflog")
+	reflogCmd := gitCommand("reflog")
 	reflogOutput, err := reflogCmd.Output()
 	if err != nil {
 		return fmt.Errorf("failed to get git reflog: %w", err)
@@ -54,28 +62,31 @@ func runRestore(cmd *cobra.Command, args []string) error {
 	}
 
 	// Let user select a commit
-	commit := selectCommit(entries)
+	commit, err := selectCommit(entries)
+	if err != nil {
+		return err
+	}
 	if commit == "" {
-		fmt.Println("❌ No commit selected")
+		ui.Error("No commit selected")
 		return nil
 	}
 
 	// Get branch name from user
 	branchName := getBranchName()
 	if branchName == "" {
-		fmt.Println("❌ No branch name provided")
+		ui.Error("No branch name provided")
 		return nil
 	}
 
 	// Create new branch
-	checkoutCmd := exec.Command("git", "checkout", "-b", branchName, commit)
+	checkoutCmd := gitCommand("checkout", "-b", branchName, commit)
 	checkoutCmd.Stdout = os.Stdout
 	checkoutCmd.Stderr = os.Stderr
 	if err := checkoutCmd.Run(); err != nil {
 		return fmt.Errorf("failed to create branch: %w", err)
 	}
 
-	fmt.Printf("✅ Branch '%s' restored successfully!\n", branchName)
+	ui.Successf("Branch '%s' restored successfully!", branchName)
 	return nil
 }
 
@@ -95,13 +106,19 @@ func parseReflog(reflog string) []ReflogEntry {
 	return entries
 }
 
-func selectCommit(entries []ReflogEntry) string {
-	if !noFzf {
+func selectCommit(entries []ReflogEntry) (string, error) {
+	if preselectedItem != "" {
+		entry, err := preselect(entries, "reflog entry", func(e ReflogEntry) bool {
+			return sameCommit(e.Hash, preselectedItem)
+		})
+		return entry.Hash, err
+	}
+	if !noFzf && isInteractive() {
 		if _, err := exec.LookPath("fzf"); err == nil {
-			return selectCommitWithFzf(entries)
+			return selectCommitWithFzf(entries), nil
 		}
 	}
-	return selectCommitWithList(entries)
+	return selectCommitWithList(entries), nil
 }
 
 func selectCommitWithFzf(entries []ReflogEntry) string {
@@ -146,17 +163,15 @@ func selectCommitWithFzf(entries []ReflogEntry) string {
 }
 
 func selectCommitWithList(entries []ReflogEntry) string {
-	fmt.Println("\nRecent git actions:")
+	ui.Println("\nRecent git actions:")
 	for i, entry := range entries {
 		if i >= 20 { // Show only last 20 entries
 			break
 		}
-		fmt.Printf("%2d: %s - %s\n", i+1, entry.Hash[:8], entry.Description)
+		ui.Printf("%2d: %s - %s\n", i+1, entry.Hash[:8], entry.Description)
 	}
 
-	fmt.Print("\nSelect commit number (or press Enter to cancel): ")
-	var input string
-	fmt.Scanln(&input)
+	input := readInput("\nSelect commit number (or press Enter to cancel): ")
 
 	if input == "" {
 		return ""
@@ -171,8 +186,9 @@ func selectCommitWithList(entries []ReflogEntry) string {
 }
 
 func getBranchName() string {
-	fmt.Print("Enter a name for the restored branch: ")
-	var branchName string
-	fmt.Scanln(&branchName)
+	if restoreBranchName != "" {
+		return restoreBranchName
+	}
+	branchName := readInput("Enter a name for the restored branch: ")
 	return strings.TrimSpace(branchName)
 } 
\ No newline at end of file
@@ -0,0 +1,404 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	revertPR       int
+	revertMainline int
+	revertReason   string
+	revertEdit     bool
+)
+
+// RevertPlan is what githelper revert undoes, in one commit
+type RevertPlan struct {
+	// Commits to revert, newest first
+	Commits []string
+	// Mainline is the parent kept when reverting a merge commit, 0 otherwise
+	Mainline int
+	Subject  string
+	Body     string
+}
+
+var revertCmd = &cobra.Command{
+	Use:   "revert [commit|range]...",
+	Short: "Revert merge commits, ranges or a whole pull request in one commit",
+	Long: `Undo commits with a single new commit and a message explaining what was
+reverted, for the cases where plain 'git revert' is painful:
+
+  - a merge commit: you pick the parent to keep (git revert -m) from a list
+    showing what each side brought in
+  - a range such as v1.2..v1.3 or several commits
+  - a merged pull request (--pr): its merge commit, its squashed commit or
+    all its rebased commits, depending on how it was merged
+
+History isn't rewritten, so this is safe on shared branches; the branch is
+recorded for 'githelper rollback' anyway. Merge commits can only be reverted
+on their own.
+
+Example:
+  githelper revert 1a2b3c4                  # Pick the parent if it's a merge
+  githelper revert 1a2b3c4 --mainline 1
+  githelper revert v1.2..v1.3 --reason "breaks the login page"
+  githelper revert --pr 42 --edit`,
+	RunE: runRevert,
+}
+
+func init() {
+	rootCmd.AddCommand(revertCmd)
+	revertCmd.Flags().IntVar(&revertPR, "pr", 0, "revert the merged pull request with this number")
+	revertCmd.Flags().IntVar(&revertMainline, "mainline", 0, "parent number to keep when reverting a merge commit")
+	revertCmd.Flags().StringVar(&revertReason, "reason", "", "why the changes are reverted, added to the commit message")
+	revertCmd.Flags().BoolVarP(&revertEdit, "edit", "e", false, "edit the commit message before committing")
+	addSigningFlags(revertCmd)
+}
+
+func runRevert(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	if revertPR == 0 && len(args) == 0 {
+		return fmt.Errorf("give the commits or ranges to revert, or --pr")
+	}
+	if revertPR != 0 && len(args) > 0 {
+		return fmt.Errorf("--pr can't be combined with commits")
+	}
+	if hasChanges, err := hasUncommittedChanges(); err != nil {
+		return err
+	} else if hasChanges {
+		return fmt.Errorf("you have uncommitted changes. Please commit or stash them first")
+	}
+
+	var plan *RevertPlan
+	var err error
+	if revertPR != 0 {
+		plan, err = pullRequestRevertPlan(revertPR)
+	} else {
+		plan, err = commitsRevertPlan(args)
+	}
+	if err != nil {
+		return err
+	}
+	if revertReason != "" {
+		plan.Body += "\n\nReason: " + revertReason
+	}
+
+	message := plan.Subject + "\n\n" + plan.Body
+	ui.Printf("↩️  Reverting %d commit(s) in one commit:\n\n", len(plan.Commits))
+	for _, line := range strings.Split(message, "\n") {
+		ui.Printf("    %s\n", line)
+	}
+	ui.Println()
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+	if revertEdit {
+		if message, err = editMessage(message); err != nil {
+			return err
+		}
+		if strings.TrimSpace(message) == "" {
+			ui.Error("Empty commit message, operation cancelled")
+			return nil
+		}
+	}
+
+	if err := recordOperation("revert", false); err != nil {
+		return err
+	}
+	return applyRevert(plan, message)
+}
+
+// applyRevert reverts the commits of a plan without committing, then commits
+// them all at once
+func applyRevert(plan *RevertPlan, message string) error {
+	args := []string{"revert", "--no-commit"}
+	if plan.Mainline > 0 {
+		args = append(args, "-m", strconv.Itoa(plan.Mainline))
+	}
+	if output, err := gitCommand(append(args, plan.Commits...)...).CombinedOutput(); err != nil {
+		if !hasConflicts() {
+			gitCommand("revert", "--abort").Run()
+			return fmt.Errorf("failed to revert: %s", strings.TrimSpace(string(output)))
+		}
+		file, _ := gitCommand("rev-parse", "--path-format=absolute", "--git-path", "githelper/REVERT_MSG").Output()
+		msgFile := strings.TrimSpace(string(file))
+		os.MkdirAll(filepath.Dir(msgFile), 0755)
+		os.WriteFile(msgFile, []byte(message+"\n"), 0644)
+		ui.Warn("Later changes conflict with the revert")
+		ui.Step("👉 Resolve the conflicts with 'githelper resolve' and run 'git revert --continue' until it's done,")
+		ui.Printf("   then commit with 'git commit -F %s'\n", msgFile)
+		ui.Println("   or give up with 'git revert --abort'")
+		return fmt.Errorf("revert stopped on conflicts")
+	}
+
+	if gitCommand("diff", "--cached", "--quiet").Run() == nil {
+		gitCommand("revert", "--quit").Run()
+		return fmt.Errorf("nothing to revert: the changes are already undone on this branch")
+	}
+	commitArgs := append([]string{"commit", "-q", "-m", message}, commitSigningArgs()...)
+	if output, err := gitCommand(commitArgs...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit the revert: %s", strings.TrimSpace(string(output)))
+	}
+	commit, _ := gitCommand("rev-parse", "--short", "HEAD").Output()
+	ui.Successf("Reverted in %s", strings.TrimSpace(string(commit)))
+	return nil
+}
+
+// commitsRevertPlan plans the revert of commits and ranges given as
+// arguments
+func commitsRevertPlan(args []string) (*RevertPlan, error) {
+	var commits []string
+	seen := map[string]bool{}
+	for _, arg := range args {
+		var revs []string
+		if strings.Contains(arg, "..") {
+			output, err := gitCommand("rev-list", "--topo-order", arg, "--").Output()
+			if err != nil {
+				return nil, fmt.Errorf("invalid range '%s'", arg)
+			}
+			revs = strings.Fields(string(output))
+			if len(revs) == 0 {
+				return nil, fmt.Errorf("range '%s' has no commits", arg)
+			}
+		} else {
+			output, err := gitCommand("rev-parse", "--verify", "-q", arg+"^{commit}").Output()
+			if err != nil {
+				return nil, fmt.Errorf("unknown commit '%s'", arg)
+			}
+			revs = []string{strings.TrimSpace(string(output))}
+		}
+		for _, rev := range revs {
+			if !seen[rev] {
+				seen[rev] = true
+				commits = append(commits, rev)
+			}
+		}
+	}
+	if err := sortNewestFirst(commits); err != nil {
+		return nil, err
+	}
+
+	for _, commit := range commits {
+		if parents := commitParents(commit); len(parents) > 1 {
+			if len(commits) > 1 {
+				return nil, fmt.Errorf("%s is a merge commit, revert it on its own", shortSHA(commit))
+			}
+			mainline := revertMainline
+			if mainline == 0 {
+				var err error
+				if mainline, err = chooseMainline(commit, parents); err != nil {
+					return nil, err
+				}
+			}
+			if mainline < 1 || mainline > len(parents) {
+				return nil, fmt.Errorf("%s has %d parents, --mainline must be between 1 and %d", shortSHA(commit), len(parents), len(parents))
+			}
+			return mergeRevertPlan(commit, parents, mainline), nil
+		}
+	}
+
+	plan := &RevertPlan{Commits: commits, Body: "This reverts the following commits:\n" + commitList(commits)}
+	if len(commits) == 1 {
+		plan.Subject = fmt.Sprintf("Revert \"%s\"", commitSubject(commits[0]))
+		plan.Body = fmt.Sprintf("This reverts commit %s.", commits[0])
+	} else {
+		plan.Subject = fmt.Sprintf("Revert %d commits from %s to %s", len(commits), shortSHA(commits[len(commits)-1]), shortSHA(commits[0]))
+	}
+	return plan, nil
+}
+
+// mergeRevertPlan plans the revert of a merge commit keeping one parent
+func mergeRevertPlan(merge string, parents []string, mainline int) *RevertPlan {
+	kept := parents[mainline-1]
+	merged := mergedCommits(merge, kept)
+	return &RevertPlan{
+		Commits:  []string{merge},
+		Mainline: mainline,
+		Subject:  fmt.Sprintf("Revert \"%s\"", commitSubject(merge)),
+		Body: fmt.Sprintf("This reverts merge commit %s,\nkeeping parent %d (%s) and undoing the %d commit(s) it merged:\n%s",
+			merge, mainline, shortSHA(kept), len(merged), commitList(merged)),
+	}
+}
+
+// chooseMainline asks which parent of a merge commit to keep
+func chooseMainline(merge string, parents []string) (int, error) {
+	ui.Printf("🔀 %s is a merge commit. Which parent do you want to keep?\n", shortSHA(merge))
+	for i, parent := range parents {
+		ui.Printf("%2d: %s %s (undoes %d commit(s) from the other side)\n", i+1, shortSHA(parent), commitSubject(parent), len(mergedCommits(merge, parent)))
+	}
+	input := readInput("\nSelect parent number [1]: ")
+	if input == "" {
+		ui.Println("Keeping parent 1, the branch the merge was made on")
+		return 1, nil
+	}
+	number, err := strconv.Atoi(input)
+	if err != nil || number < 1 || number > len(parents) {
+		return 0, fmt.Errorf("invalid selection")
+	}
+	return number, nil
+}
+
+// pullRequestRevertPlan plans the revert of a merged pull request from how it
+// landed on its base branch
+func pullRequestRevertPlan(number int) (*RevertPlan, error) {
+	client, owner, name, err := originClient()
+	if err != nil {
+		return nil, err
+	}
+	ui.Stepf("🔍 Looking up pull request #%d...", number)
+	pr, err := client.PullRequestCommits(context.Background(), owner, name, number)
+	if errors.Is(err, github.ErrUnauthorized) {
+		return nil, errors.New(i18n.T("error.not_authorized"))
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get pull request #%d: %w", number, err)
+	}
+	if !pr.Merged || pr.MergeCommit == "" {
+		return nil, fmt.Errorf("pull request #%d isn't merged, there is nothing to revert", number)
+	}
+
+	if gitCommand("cat-file", "-e", pr.MergeCommit+"^{commit}").Run() != nil {
+		ui.Stepf("🔄 Fetching %s...", pr.Base)
+		gitCommand("fetch", "-q", "origin", pr.Base).Run()
+		if gitCommand("cat-file", "-e", pr.MergeCommit+"^{commit}").Run() != nil {
+			return nil, fmt.Errorf("the merge commit %s of #%d isn't in this clone", shortSHA(pr.MergeCommit), number)
+		}
+	}
+	// The pull request commits tell a rebase merge from a squash merge
+	gitCommand("fetch", "-q", "origin", fmt.Sprintf("pull/%d/head", number)).Run()
+
+	commits, mainline, how := pullRequestMergedCommits(pr.MergeCommit, pr.Commits)
+	plan := &RevertPlan{
+		Commits:  commits,
+		Mainline: mainline,
+		Subject:  fmt.Sprintf("Revert \"%s\" (#%d)", pr.Title, number),
+		Body:     fmt.Sprintf("This reverts pull request #%d, which was %s as:\n%s", number, how, commitList(commits)),
+	}
+	if pr.URL != "" {
+		plan.Body = fmt.Sprintf("This reverts pull request #%d (%s), which was %s as:\n%s", number, pr.URL, how, commitList(commits))
+	}
+	return plan, nil
+}
+
+// pullRequestMergedCommits returns the commits a pull request left on its
+// base branch, newest first, the mainline to revert them with and how it was
+// merged: "merged" with a merge commit, "squashed" into one commit or
+// "rebased" as copies of its commits
+func pullRequestMergedCommits(mergeCommit string, prCommits []string) ([]string, int, string) {
+	if len(commitParents(mergeCommit)) > 1 {
+		return []string{mergeCommit}, 1, "merged"
+	}
+	// A rebase merge leaves a copy of the last commit, with the same patch
+	if len(prCommits) > 1 {
+		last := prCommits[len(prCommits)-1]
+		if id := patchID(mergeCommit); id != "" && id == patchID(last) {
+			output, err := gitCommand("rev-list", "--first-parent", "-n", strconv.Itoa(len(prCommits)), mergeCommit).Output()
+			if err == nil {
+				return strings.Fields(string(output)), 0, "rebased"
+			}
+		}
+	}
+	return []string{mergeCommit}, 0, "squashed"
+}
+
+// patchID returns the stable patch ID of a commit, empty when unknown
+func patchID(commit string) string {
+	show, err := gitCommand("show", "--format=", commit).Output()
+	if err != nil {
+		return ""
+	}
+	cmd := gitCommand("patch-id", "--stable")
+	cmd.Stdin = strings.NewReader(string(show))
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// commitParents returns the parents of a commit
+func commitParents(commit string) []string {
+	output, err := gitCommand("rev-list", "--parents", "-n", "1", commit).Output()
+	if err != nil {
+		return nil
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields[1:]
+}
+
+// mergedCommits returns the commits a merge brought in on top of one of its
+// parents, newest first
+func mergedCommits(merge, parent string) []string {
+	output, err := gitCommand("rev-list", merge+"^@", "--not", parent).Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(output))
+}
+
+// sortNewestFirst orders commits as reverting them needs: children before
+// their parents. History is walked only until all of them are found.
+func sortNewestFirst(commits []string) error {
+	if len(commits) < 2 {
+		return nil
+	}
+	wanted := map[string]bool{}
+	for _, commit := range commits {
+		wanted[commit] = true
+	}
+	cmd := gitCommand(append([]string{"rev-list", "--topo-order"}, commits...)...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to sort commits: %w", err)
+	}
+	sorted := commits[:0:0]
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() && len(sorted) < len(commits) {
+		if wanted[scanner.Text()] {
+			sorted = append(sorted, scanner.Text())
+		}
+	}
+	cmd.Process.Kill()
+	cmd.Wait()
+	if len(sorted) != len(commits) {
+		return fmt.Errorf("failed to sort commits")
+	}
+	copy(commits, sorted)
+	return nil
+}
+
+// commitList formats commits as a markdown list, showing the first 20
+func commitList(commits []string) string {
+	var list strings.Builder
+	for i, commit := range commits {
+		if i == 20 {
+			fmt.Fprintf(&list, "- ... and %d more\n", len(commits)-i)
+			break
+		}
+		fmt.Fprintf(&list, "- %s %s\n", shortSHA(commit), commitSubject(commit))
+	}
+	return strings.TrimSuffix(list.String(), "\n")
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func revParse(t *testing.T, rev string) string {
+	t.Helper()
+	output, err := exec.Command("git", "rev-parse", rev).Output()
+	require.NoError(t, err)
+	return strings.TrimSpace(string(output))
+}
+
+func commitFileChange(t *testing.T, dir, file, content, message string) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	runGit(t, dir, "add", file)
+	runGit(t, dir, "commit", "-m", message)
+	return revParse(t, "HEAD")
+}
+
+func TestRevert(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+	runGit(t, tmpDir, "config", "user.name", "Test")
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+	assumeYes = true
+	defer func() { assumeYes = false }()
+
+	runGit(t, tmpDir, "commit", "-m", "base")
+	runGit(t, tmpDir, "branch", "-M", "main")
+	runGit(t, tmpDir, "checkout", "-b", "feature")
+	commitFileChange(t, tmpDir, "a.txt", "a", "add a")
+	commitFileChange(t, tmpDir, "b.txt", "b", "add b")
+	runGit(t, tmpDir, "checkout", "main")
+	commitFileChange(t, tmpDir, "c.txt", "c", "add c")
+	runGit(t, tmpDir, "merge", "--no-ff", "-m", "Merge feature", "feature")
+
+	// Keeping main undoes the two feature commits
+	revertMainline = 1
+	require.NoError(t, runRevert(revertCmd, []string{"HEAD"}))
+	revertMainline = 0
+	assert.NoFileExists(t, "a.txt")
+	assert.NoFileExists(t, "b.txt")
+	assert.FileExists(t, "c.txt")
+	message, err := exec.Command("git", "log", "-1", "--format=%B").Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(message), `Revert "Merge feature"`)
+	assert.Contains(t, string(message), "undoing the 2 commit(s) it merged")
+
+	// A range is reverted in one commit
+	d := commitFileChange(t, tmpDir, "d.txt", "d", "add d")
+	commitFileChange(t, tmpDir, "e.txt", "e", "add e")
+	revertReason = "not needed"
+	defer func() { revertReason = "" }()
+	require.NoError(t, runRevert(revertCmd, []string{d + "~1..HEAD"}))
+	assert.NoFileExists(t, "d.txt")
+	assert.NoFileExists(t, "e.txt")
+	message, err = exec.Command("git", "log", "-1", "--format=%B").Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(message), "Revert 2 commits from "+shortSHA(d))
+	assert.Contains(t, string(message), "Reason: not needed")
+
+	// Separate commits are reverted newest first, whatever the order given
+	plan, err := commitsRevertPlan([]string{d, "HEAD~1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{revParse(t, "HEAD~1"), d}, plan.Commits)
+}
+
+func TestPullRequestMergedCommits(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	runGit(t, tmpDir, "commit", "-m", "base")
+	runGit(t, tmpDir, "branch", "-M", "main")
+	runGit(t, tmpDir, "checkout", "-b", "pr")
+	pr1 := commitFileChange(t, tmpDir, "a.txt", "a", "add a")
+	pr2 := commitFileChange(t, tmpDir, "b.txt", "b", "add b")
+
+	// Rebased: copies of both commits on main
+	runGit(t, tmpDir, "checkout", "-b", "rebased", "main")
+	commitFileChange(t, tmpDir, "c.txt", "c", "add c")
+	runGit(t, tmpDir, "cherry-pick", pr1, pr2)
+	commits, mainline, how := pullRequestMergedCommits(revParse(t, "HEAD"), []string{pr1, pr2})
+	assert.Equal(t, "rebased", how)
+	assert.Equal(t, 0, mainline)
+	assert.Equal(t, []string{revParse(t, "HEAD"), revParse(t, "HEAD~1")}, commits)
+
+	// Squashed: one commit with both changes
+	runGit(t, tmpDir, "checkout", "-b", "squashed", "main")
+	runGit(t, tmpDir, "merge", "--squash", "pr")
+	runGit(t, tmpDir, "commit", "-m", "squash")
+	commits, _, how = pullRequestMergedCommits(revParse(t, "HEAD"), []string{pr1, pr2})
+	assert.Equal(t, "squashed", how)
+	assert.Equal(t, []string{revParse(t, "HEAD")}, commits)
+
+	// Merged: the merge commit, keeping the first parent
+	runGit(t, tmpDir, "checkout", "-b", "merged", "main")
+	runGit(t, tmpDir, "merge", "--no-ff", "-m", "merge", "pr")
+	commits, mainline, how = pullRequestMergedCommits(revParse(t, "HEAD"), []string{pr1, pr2})
+	assert.Equal(t, "merged", how)
+	assert.Equal(t, 1, mainline)
+	assert.Equal(t, []string{revParse(t, "HEAD")}, commits)
+}
@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/runner"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rewordMessage string
+	rewordGrep    string
+	rewordReplace string
+	rewordRange   string
+)
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword [commit]",
+	Short: "Change the message of past commits",
+	Long: `Change the message of a past commit on the current branch without an
+interactive rebase.
+
+Give a commit and a new message with -m, or edit the current message in your
+editor. With --grep, every message in a range matching a regular expression
+is changed at once: matches are replaced with --replace (default: removed).
+The range defaults to the commits that haven't been pushed.
+
+Only the message changes; trees, authors and dates stay the same, so there
+are never conflicts. The commit and everything after it get new hashes:
+commits that are already pushed need a confirmation and a force push.
+'githelper rollback' undoes a reword.
+
+Example:
+  githelper reword HEAD~2                          # Edit the message in your editor
+  githelper reword abc1234 -m "Fix typo in parser"
+  githelper reword --grep '\[JIRA-[0-9]+\] ?'      # Remove ticket numbers
+  githelper reword --grep 'teh' --replace 'the'    # Fix a typo everywhere
+  githelper reword --grep 'WIP' --range main..HEAD # Limit to a range`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReword,
+}
+
+func init() {
+	rootCmd.AddCommand(rewordCmd)
+	rewordCmd.Flags().StringVarP(&rewordMessage, "message", "m", "", "new commit message")
+	rewordCmd.Flags().StringVar(&rewordGrep, "grep", "", "regular expression to replace in every message of the range")
+	rewordCmd.Flags().StringVar(&rewordReplace, "replace", "", "replacement for --grep matches; $1 refers to groups")
+	rewordCmd.Flags().StringVar(&rewordRange, "range", "", "commits to change with --grep (default: unpushed commits)")
+	rewordCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the new messages without rewriting")
+}
+
+// rewordChange is a commit and its new message
+type rewordChange struct {
+	Commit  string
+	Subject string
+	Message string
+}
+
+func runReword(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return err
+	}
+	if branch == "" || branch == "HEAD" {
+		return fmt.Errorf("HEAD is detached. Check out a branch to reword its commits")
+	}
+
+	var changes []rewordChange
+	switch {
+	case rewordGrep != "" && len(args) > 0:
+		return fmt.Errorf("give a commit or --grep, not both")
+	case rewordGrep != "":
+		if changes, err = grepRewordChanges(rewordGrep, rewordReplace, rewordRange); err != nil {
+			return err
+		}
+	case len(args) > 0:
+		change, err := singleRewordChange(args[0], rewordMessage)
+		if err != nil {
+			return err
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	default:
+		return fmt.Errorf("give the commit to reword, or --grep")
+	}
+
+	if len(changes) == 0 {
+		ui.Success("No messages change")
+		return nil
+	}
+
+	ui.Stepf("\n✏️  %d commit(s) will be reworded:", len(changes))
+	var published []string
+	for _, change := range changes {
+		ui.Printf("  %s %s\n", shortSHA(change.Commit), change.Subject)
+		ui.Printf("  %s → %s\n", strings.Repeat(" ", 7), firstLine(change.Message))
+		if isPublished(change.Commit) {
+			published = append(published, shortSHA(change.Commit))
+		}
+	}
+
+	if dryRun {
+		ui.Step("\n🔍 Dry run - no commits were changed")
+		return nil
+	}
+
+	if hasChanges, err := hasUncommittedChanges(); err != nil {
+		return err
+	} else if hasChanges {
+		return fmt.Errorf("you have uncommitted changes. Please commit or stash them first")
+	}
+
+	if len(published) > 0 {
+		if err := guardOperation("reword", branch); err != nil {
+			return err
+		}
+		ui.Warnf("\n%s already pushed; you will need to force push afterwards", strings.Join(published, ", "))
+		if !confirmAction() {
+			ui.Error("Operation cancelled")
+			return nil
+		}
+	} else if rewordGrep != "" && !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	if err := recordOperation("reword", false); err != nil {
+		return err
+	}
+	if err := applyRewordChanges(changes); err != nil {
+		return err
+	}
+
+	ui.Success("Commits reworded!")
+	if len(published) > 0 {
+		ui.Warn("\nTo update the remote:")
+		ui.Println("githelper push --force")
+	}
+	return nil
+}
+
+// singleRewordChange returns the new message of one commit, from message or
+// the editor, or nil when it doesn't change
+func singleRewordChange(rev, message string) (*rewordChange, error) {
+	commit, err := gitCommand("rev-parse", "--verify", "-q", rev+"^{commit}").Output()
+	if err != nil {
+		return nil, fmt.Errorf("commit '%s' does not exist", rev)
+	}
+	sha := strings.TrimSpace(string(commit))
+	if err := gitCommand("merge-base", "--is-ancestor", sha, "HEAD").Run(); err != nil {
+		return nil, fmt.Errorf("%s is not on the current branch", shortSHA(sha))
+	}
+
+	current, err := gitCommand("log", "-1", "--format=%B", sha).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit message: %w", err)
+	}
+
+	if message == "" {
+		if !isInteractive() {
+			return nil, fmt.Errorf("pass the new message with -m when running non-interactively")
+		}
+		if message, err = editCommitMessage(string(current)); err != nil {
+			return nil, err
+		}
+	}
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, fmt.Errorf("empty message, reword cancelled")
+	}
+	if message == strings.TrimSpace(string(current)) {
+		return nil, nil
+	}
+	return &rewordChange{Commit: sha, Subject: firstLine(string(current)), Message: message}, nil
+}
+
+// grepRewordChanges replaces pattern in the message of every commit in
+// revRange (default: commits not on any remote)
+func grepRewordChanges(pattern, replacement, revRange string) ([]rewordChange, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --grep pattern: %w", err)
+	}
+
+	logArgs := []string{"log", "-z", "--format=%H%x00%B"}
+	if revRange != "" {
+		logArgs = append(logArgs, revRange)
+	} else {
+		logArgs = append(logArgs, "HEAD", "--not", "--remotes")
+	}
+	output, err := gitCommand(logArgs...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commits: %w", err)
+	}
+
+	// Records are "<sha>\0<message>\0"
+	fields := strings.Split(string(output), "\x00")
+	var changes []rewordChange
+	for i := 0; i+1 < len(fields); i += 2 {
+		sha, current := strings.TrimSpace(fields[i]), strings.TrimSpace(fields[i+1])
+		if sha == "" {
+			continue
+		}
+		if err := gitCommand("merge-base", "--is-ancestor", sha, "HEAD").Run(); err != nil {
+			return nil, fmt.Errorf("%s is not on the current branch; --range must end at HEAD", shortSHA(sha))
+		}
+		message := strings.TrimSpace(re.ReplaceAllString(current, replacement))
+		if message == current {
+			continue
+		}
+		if message == "" {
+			return nil, fmt.Errorf("the new message of %s would be empty", shortSHA(sha))
+		}
+		changes = append(changes, rewordChange{Commit: sha, Subject: firstLine(current), Message: message})
+	}
+	return changes, nil
+}
+
+// applyRewordChanges rewrites the current branch from the oldest changed
+// commit, replacing the messages of the changed commits
+func applyRewordChanges(changes []rewordChange) error {
+	dir, err := makeTempDir("", "githelper-reword-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, change := range changes {
+		if err := os.WriteFile(filepath.Join(dir, change.Commit), []byte(change.Message+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to write message: %w", err)
+		}
+	}
+
+	// Rewrite from the oldest changed commit: the last one in topological
+	// order, since no other changed commit can be an ancestor of its parents
+	output, err := gitCommand("rev-list", "--topo-order", "HEAD").Output()
+	if err != nil {
+		return fmt.Errorf("failed to list commits: %w", err)
+	}
+	changed := map[string]bool{}
+	for _, change := range changes {
+		changed[change.Commit] = true
+	}
+	oldest := ""
+	for _, sha := range strings.Fields(string(output)) {
+		if changed[sha] {
+			oldest = sha
+		}
+	}
+	revs := []string{"HEAD", "--not", oldest + "^@"}
+
+	msgFilter := fmt.Sprintf(`if [ -f %[1]s/"$GIT_COMMIT" ]; then cat %[1]s/"$GIT_COMMIT"; else cat; fi`, shellQuote(dir))
+	args := append([]string{"filter-branch", "--force", "--msg-filter", msgFilter, "--"}, revs...)
+	var stderr bytes.Buffer
+	filterCmd := runner.Git(args...).WithEnv("FILTER_BRANCH_SQUELCH_WARNING=1").WithOutput(nil, &stderr)
+	if err := commandRunner.Run(commandCtx, filterCmd); err != nil {
+		return fmt.Errorf("failed to reword commits: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// editCommitMessage opens message in the editor and returns it without
+// comment lines, keeping blank lines between paragraphs
+func editCommitMessage(message string) (string, error) {
+	tmpfile, err := os.CreateTemp("", "COMMIT_EDITMSG")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	content := message + "\n# Edit the commit message. Lines starting with '#' are ignored,\n# and an empty message cancels the reword.\n"
+	if _, err := tmpfile.WriteString(content); err != nil {
+		return "", fmt.Errorf("failed to write to temporary file: %w", err)
+	}
+	tmpfile.Close()
+
+	cmd := editorCommand(tmpfile.Name())
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to open editor: %w", err)
+	}
+
+	edited, err := os.Open(tmpfile.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited message: %w", err)
+	}
+	defer edited.Close()
+	stripCmd := gitCommand("stripspace", "--strip-comments")
+	stripCmd.Stdin = edited
+	output, err := stripCmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to clean up message: %w", err)
+	}
+	return string(output), nil
+}
+
+// isPublished reports whether commit is on a remote-tracking branch
+func isPublished(commit string) bool {
+	output, err := gitCommand("branch", "-r", "--contains", commit).Output()
+	return err == nil && len(bytes.TrimSpace(output)) > 0
+}
+
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return line
+}
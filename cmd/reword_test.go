@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/runner"
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commitSubjects(t *testing.T) []string {
+	t.Helper()
+	output, err := exec.Command("git", "log", "--format=%s").Output()
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
+
+func TestReword(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "[PROJ-1] first")
+	runGit(t, tmpDir, "commit", "--allow-empty", "-m", "secnod", "-m", "Body stays.")
+	runGit(t, tmpDir, "commit", "--allow-empty", "-m", "[PROJ-2] third")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	change, err := singleRewordChange("HEAD~1", "second\n\nBody stays.")
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	assert.Equal(t, "secnod", change.Subject)
+	require.NoError(t, applyRewordChanges([]rewordChange{*change}))
+	assert.Equal(t, []string{"[PROJ-2] third", "second", "[PROJ-1] first"}, commitSubjects(t))
+
+	body, err := exec.Command("git", "log", "-1", "--format=%b", "HEAD~1").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "Body stays.", strings.TrimSpace(string(body)))
+
+	// Unchanged messages are not rewritten
+	change, err = singleRewordChange("HEAD", "[PROJ-2] third")
+	require.NoError(t, err)
+	assert.Nil(t, change)
+
+	changes, err := grepRewordChanges(`\[PROJ-[0-9]+\] ?`, "", "HEAD")
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+	require.NoError(t, applyRewordChanges(changes))
+	assert.Equal(t, []string{"third", "second", "first"}, commitSubjects(t))
+}
+
+func TestRewordGitInvocations(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	typo := r.Commit("secnod", "second.txt", "second\n")
+	r.Commit("third", "third.txt", "third\n")
+	head := r.Head()
+
+	recorder := &runner.Recorder{Passthrough: runner.ReadOnly, Next: runner.Exec{}}
+	originalRunner := commandRunner
+	defer func() { commandRunner = originalRunner }()
+	commandRunner = recorder
+
+	_, _, err := execute(t, "reword", typo, "-m", "second")
+	require.NoError(t, err)
+	commands := recorder.Commands()
+	require.Len(t, commands, 1)
+	args := commands[0].Args
+	assert.Equal(t, []string{"filter-branch", "--force", "--msg-filter"}, args[:3])
+	assert.Equal(t, []string{"--", "HEAD", "--not", typo + "^@"}, args[4:])
+	assert.Equal(t, []string{"FILTER_BRANCH_SQUELCH_WARNING=1"}, commands[0].Env)
+	assert.Equal(t, head, r.Head(), "recorded commands don't run")
+}
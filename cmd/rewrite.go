@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/runner"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+)
+
+// historyRevs are the refs history rewrites touch
+var historyRevs = []string{"--branches", "--tags"}
+
+// historyPathArgs limit a walk of historyRevs to commits touching paths.
+// --full-history keeps the commits of merged branches that git would
+// otherwise simplify away, such as a file added and removed again on a
+// branch; the rewrite changes those too.
+func historyPathArgs(pathspecs []string) []string {
+	args := append([]string{"--full-history"}, historyRevs...)
+	return append(append(args, "--"), pathspecs...)
+}
+
+// historyPathspec turns a path given to clean or purge into a git pathspec.
+// Files and directories match literally (a directory matches everything in
+// it); patterns with *, ? or [ are globs, and globs without a slash match at
+// any depth, like in .gitignore.
+func historyPathspec(pattern string) string {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return ":(literal)" + strings.TrimSuffix(pattern, "/")
+	}
+	if !strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+		return ":(glob)**/" + pattern
+	}
+	return ":(glob)" + pattern
+}
+
+func historyPathspecs(patterns []string) []string {
+	pathspecs := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		pathspecs[i] = historyPathspec(pattern)
+	}
+	return pathspecs
+}
+
+// HistoryPreview summarizes what removing paths from history affects
+type HistoryPreview struct {
+	// Files are the matching paths with the total size of all their versions
+	Files     []LargeFile
+	TotalSize int64
+	Commits   int
+}
+
+// previewHistoryRemoval finds the files matching patterns anywhere in
+// history, their size and the number of commits that touch them
+func previewHistoryRemoval(patterns []string) (*HistoryPreview, error) {
+	pathspecs := historyPathspecs(patterns)
+
+	args := append([]string{"log", "--format=", "--name-only"}, historyPathArgs(pathspecs)...)
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search history: %w", err)
+	}
+	matched := map[string]bool{}
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" {
+			matched[line] = true
+		}
+	}
+
+	blobs, err := historyBlobs("🔍 Measuring the files in history", 0, historyRevs...)
+	if err != nil {
+		return nil, err
+	}
+	sizes := map[string]int64{}
+	preview := &HistoryPreview{}
+	for _, blob := range blobs {
+		if matched[blob.Path] {
+			sizes[blob.Path] += blob.Size
+			preview.TotalSize += blob.Size
+		}
+	}
+	for file := range matched {
+		preview.Files = append(preview.Files, LargeFile{Path: file, Size: sizes[file]})
+	}
+	sort.Slice(preview.Files, func(i, j int) bool {
+		if preview.Files[i].Size != preview.Files[j].Size {
+			return preview.Files[i].Size > preview.Files[j].Size
+		}
+		return preview.Files[i].Path < preview.Files[j].Path
+	})
+
+	args = append([]string{"rev-list", "--count"}, historyPathArgs(pathspecs)...)
+	output, err = gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count commits: %w", err)
+	}
+	preview.Commits, _ = strconv.Atoi(strings.TrimSpace(string(output)))
+	return preview, nil
+}
+
+func printHistoryPreview(preview *HistoryPreview) {
+	const shown = 20
+	ui.Stepf("\n📊 %d file(s), %s in history, touched by %d commit(s):", len(preview.Files), formatSize(preview.TotalSize), preview.Commits)
+	for i, file := range preview.Files {
+		if i == shown {
+			ui.Printf("  ... and %d more\n", len(preview.Files)-shown)
+			break
+		}
+		ui.Printf("  %-50s %s\n", file.Path, formatSize(file.Size))
+	}
+}
+
+// removeFromHistory previews, confirms and removes the files matching
+// patterns from every branch and tag. It reports whether history was
+// rewritten.
+func removeFromHistory(operation string, patterns []string) (bool, error) {
+	// Rewriting history touches every branch
+	branches, err := getLocalBranches()
+	if err != nil {
+		return false, err
+	}
+	if err := guardOperation(operation, branches...); err != nil {
+		return false, err
+	}
+
+	preview, err := previewHistoryRemoval(patterns)
+	if err != nil {
+		return false, err
+	}
+	if len(preview.Files) == 0 {
+		return false, fmt.Errorf("nothing in history matches %s", strings.Join(patterns, ", "))
+	}
+	printHistoryPreview(preview)
+
+	// Confirm action
+	ui.Warnf("\nWARNING: This will permanently remove %s from git history!", strings.Join(patterns, ", "))
+	ui.Println("This will rewrite git history. Until you push, 'githelper rollback' can restore it.")
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return false, nil
+	}
+
+	if err := backupBeforeRewrite(); err != nil {
+		return false, err
+	}
+	if err := recordOperation(operation, true); err != nil {
+		return false, err
+	}
+
+	quoted := make([]string, 0, len(patterns))
+	for _, pathspec := range historyPathspecs(patterns) {
+		quoted = append(quoted, shellQuote(pathspec))
+	}
+	ui.Println()
+	args := append([]string{"filter-branch", "--force",
+		"--index-filter", "git rm -r --cached --ignore-unmatch --quiet -- " + strings.Join(quoted, " "),
+		"--prune-empty", "--tag-name-filter", "cat", "--"}, historyRevs...)
+	filterCmd := runner.Git(args...).WithEnv("FILTER_BRANCH_SQUELCH_WARNING=1")
+	if err := gitRunProgress(fmt.Sprintf("🗑️  Removing %s from history", strings.Join(patterns, ", ")), filterCmd); err != nil {
+		return false, fmt.Errorf("failed to remove files from history: %w", err)
+	}
+	return true, nil
+}
+
+// largestDirectories adds up the files in every directory, at any depth
+func largestDirectories(files []LargeFile) []LargeFile {
+	sizes := map[string]int64{}
+	for _, file := range files {
+		for dir := path.Dir(file.Path); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			sizes[dir] += file.Size
+		}
+	}
+	dirs := make([]LargeFile, 0, len(sizes))
+	for _, dir := range sortedKeys(sizes) {
+		dirs = append(dirs, LargeFile{Path: dir + "/", Size: sizes[dir]})
+	}
+	return dirs
+}
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	authorOldEmails []string
+	authorNewName   string
+	authorNewEmail  string
+	authorMailmap   bool
+)
+
+var rewriteAuthorCmd = &cobra.Command{
+	Use:   "rewrite-author",
+	Short: "Rewrite author and committer identities in history",
+	Long: `Replace the author and committer name and email of commits across every
+branch and tag, e.g. after moving to a new company email domain.
+
+Either give the old email(s) and the new identity, or pass --mailmap to apply
+every mapping in the repository's .mailmap (and mailmap.file), so history
+matches what 'git log' already shows.
+
+githelper previews which identities change and in how many commits, then
+backs up and records the repository so 'githelper rollback' can undo it.
+
+⚠️  WARNING: This rewrites git history! Every commit from the first changed
+one onward gets a new hash.
+
+Example:
+  githelper rewrite-author --old-email jane@old.com --new-email jane@new.com
+  githelper rewrite-author --old-email j@old.com --old-email jane@old.com \
+    --new-name "Jane Doe" --new-email jane@new.com
+  githelper rewrite-author --mailmap             # Apply .mailmap to history
+  githelper rewrite-author --mailmap --dry-run   # Only show the changes
+  githelper rewrite-author --mailmap --push      # Then force push and tell the team`,
+	Args: cobra.NoArgs,
+	RunE: runRewriteAuthor,
+}
+
+func init() {
+	rootCmd.AddCommand(rewriteAuthorCmd)
+	rewriteAuthorCmd.Flags().StringArrayVar(&authorOldEmails, "old-email", nil, "email to replace (repeatable)")
+	rewriteAuthorCmd.Flags().StringVar(&authorNewName, "new-name", "", "new name (default keeps the name)")
+	rewriteAuthorCmd.Flags().StringVar(&authorNewEmail, "new-email", "", "new email (default keeps the email)")
+	rewriteAuthorCmd.Flags().BoolVar(&authorMailmap, "mailmap", false, "apply the mappings in .mailmap")
+	rewriteAuthorCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the changes without rewriting history")
+	rewriteAuthorCmd.Flags().BoolVar(&noBackup, "no-backup", false, "don't create a backup bundle before rewriting history")
+}
+
+// Identity is a name and email as recorded in a commit
+type Identity struct {
+	Name  string
+	Email string
+}
+
+func (i Identity) String() string {
+	return fmt.Sprintf("%s <%s>", i.Name, i.Email)
+}
+
+// identityMapping replaces identities; an empty Name in From matches any
+// name, and an empty field in To keeps the old value
+type identityMapping struct {
+	From Identity
+	To   Identity
+}
+
+// rewriteIdentity applies the first matching mapping to id
+func rewriteIdentity(mappings []identityMapping, id Identity) (Identity, bool) {
+	for _, m := range mappings {
+		if m.From.Email != id.Email || (m.From.Name != "" && m.From.Name != id.Name) {
+			continue
+		}
+		rewritten := id
+		if m.To.Name != "" {
+			rewritten.Name = m.To.Name
+		}
+		if m.To.Email != "" {
+			rewritten.Email = m.To.Email
+		}
+		return rewritten, rewritten != id
+	}
+	return id, false
+}
+
+func runRewriteAuthor(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	var mappings []identityMapping
+	var err error
+	switch {
+	case authorMailmap && len(authorOldEmails) > 0:
+		return fmt.Errorf("use either --mailmap or --old-email, not both")
+	case authorMailmap:
+		if mappings, err = mailmapMappings(); err != nil {
+			return err
+		}
+		if len(mappings) == 0 {
+			ui.Success(".mailmap doesn't change any identity in history")
+			return nil
+		}
+	case len(authorOldEmails) > 0:
+		if authorNewName == "" && authorNewEmail == "" {
+			return fmt.Errorf("give --new-name and/or --new-email")
+		}
+		for _, email := range authorOldEmails {
+			mappings = append(mappings, identityMapping{
+				From: Identity{Email: email},
+				To:   Identity{Name: authorNewName, Email: authorNewEmail},
+			})
+		}
+	default:
+		return fmt.Errorf("give --old-email with --new-name/--new-email, or --mailmap")
+	}
+
+	// Preview
+	changes, commits, err := previewIdentityChanges(mappings)
+	if err != nil {
+		return err
+	}
+	if commits == 0 {
+		ui.Success("No commits match; nothing to rewrite")
+		return nil
+	}
+	ui.Stepf("\n📊 %d commit(s) will change:", commits)
+	for _, change := range changes {
+		ui.Printf("  %s → %s (%d commit(s))\n", change.From, change.To, change.Commits)
+	}
+
+	if dryRun {
+		ui.Step("\n🔍 Dry run - history was not rewritten")
+		return nil
+	}
+
+	branches, err := getLocalBranches()
+	if err != nil {
+		return err
+	}
+	if err := guardOperation("rewrite-author", branches...); err != nil {
+		return err
+	}
+
+	ui.Warn("\nWARNING: This will rewrite git history! Until you push, 'githelper rollback' can restore it.")
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	if err := backupBeforeRewrite(); err != nil {
+		return err
+	}
+	if err := recordOperation("rewrite-author", true); err != nil {
+		return err
+	}
+
+	ui.Step("\n✍️  Rewriting authors...")
+	filterArgs := append([]string{"filter-branch", "--force",
+		"--env-filter", identityEnvFilter(mappings),
+		"--tag-name-filter", "cat", "--"}, historyRevs...)
+	if err := gitRunShown(filterArgs...); err != nil {
+		return fmt.Errorf("failed to rewrite authors: %w", err)
+	}
+
+	ui.Success("\nAuthors rewritten!")
+	notifyResult(fmt.Sprintf("Authors rewritten by %d mapping(s)", len(mappings)))
+	return pushAfterRewrite("Commit authors were rewritten")
+}
+
+// identityChange is one identity being replaced and how often
+type identityChange struct {
+	From, To Identity
+	Commits  int
+}
+
+// previewIdentityChanges applies the mappings to every commit without
+// rewriting anything, and returns the changed identities and the number of
+// commits that change
+func previewIdentityChanges(mappings []identityMapping) ([]identityChange, int, error) {
+	args := append([]string{"log", "--no-mailmap", "--format=%an%x00%ae%x00%cn%x00%ce"}, historyRevs...)
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	counts := map[[2]Identity]int{}
+	commits := 0
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\x00")
+		if len(fields) != 4 {
+			continue
+		}
+		author := Identity{fields[0], fields[1]}
+		committer := Identity{fields[2], fields[3]}
+		changed := false
+		if to, ok := rewriteIdentity(mappings, author); ok {
+			counts[[2]Identity{author, to}]++
+			changed = true
+		}
+		if to, ok := rewriteIdentity(mappings, committer); ok && committer != author {
+			counts[[2]Identity{committer, to}]++
+			changed = true
+		}
+		if changed {
+			commits++
+		}
+	}
+
+	var changes []identityChange
+	for pair, n := range counts {
+		changes = append(changes, identityChange{From: pair[0], To: pair[1], Commits: n})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Commits != changes[j].Commits {
+			return changes[i].Commits > changes[j].Commits
+		}
+		return changes[i].From.String() < changes[j].From.String()
+	})
+	return changes, commits, nil
+}
+
+// mailmapMappings returns a mapping for every identity in history that
+// .mailmap changes
+func mailmapMappings() ([]identityMapping, error) {
+	args := append([]string{"log", "--no-mailmap", "--format=%an <%ae>%n%cn <%ce>"}, historyRevs...)
+	output, err := gitCommand(args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	seen := map[string]bool{}
+	var contacts []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line != "" && !seen[line] {
+			seen[line] = true
+			contacts = append(contacts, line)
+		}
+	}
+	if len(contacts) == 0 {
+		return nil, nil
+	}
+
+	checkCmd := gitCommand(append([]string{"check-mailmap"}, contacts...)...)
+	mapped, err := checkCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .mailmap: %w", err)
+	}
+	results := strings.Split(strings.TrimRight(string(mapped), "\n"), "\n")
+	if len(results) != len(contacts) {
+		return nil, fmt.Errorf("unexpected output from git check-mailmap")
+	}
+
+	var mappings []identityMapping
+	for i, contact := range contacts {
+		if results[i] == contact {
+			continue
+		}
+		mappings = append(mappings, identityMapping{From: parseIdentity(contact), To: parseIdentity(results[i])})
+	}
+	return mappings, nil
+}
+
+// parseIdentity splits "Name <email>"
+func parseIdentity(contact string) Identity {
+	name, email, _ := strings.Cut(contact, " <")
+	return Identity{Name: name, Email: strings.TrimSuffix(email, ">")}
+}
+
+// identityEnvFilter builds the filter-branch --env-filter script applying
+// the mappings to the author and the committer
+func identityEnvFilter(mappings []identityMapping) string {
+	var script strings.Builder
+	for _, role := range []string{"AUTHOR", "COMMITTER"} {
+		for i, m := range mappings {
+			keyword := "elif"
+			if i == 0 {
+				keyword = "if"
+			}
+			condition := fmt.Sprintf(`[ "$GIT_%s_EMAIL" = %s ]`, role, shellQuote(m.From.Email))
+			if m.From.Name != "" {
+				condition += fmt.Sprintf(` && [ "$GIT_%s_NAME" = %s ]`, role, shellQuote(m.From.Name))
+			}
+			fmt.Fprintf(&script, "%s %s; then\n", keyword, condition)
+			if m.To.Name != "" {
+				fmt.Fprintf(&script, "  GIT_%s_NAME=%s\n", role, shellQuote(m.To.Name))
+			}
+			if m.To.Email != "" {
+				fmt.Fprintf(&script, "  GIT_%s_EMAIL=%s\n", role, shellQuote(m.To.Email))
+			}
+		}
+		script.WriteString("fi\n")
+	}
+	script.WriteString("export GIT_AUTHOR_NAME GIT_AUTHOR_EMAIL GIT_COMMITTER_NAME GIT_COMMITTER_EMAIL\n")
+	return script.String()
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteIdentity(t *testing.T) {
+	mappings := []identityMapping{
+		{From: Identity{Name: "J", Email: "j@old.com"}, To: Identity{Name: "Jane", Email: "jane@new.com"}},
+		{From: Identity{Email: "jane@old.com"}, To: Identity{Email: "jane@new.com"}},
+	}
+
+	id, ok := rewriteIdentity(mappings, Identity{"Jane", "jane@old.com"})
+	assert.True(t, ok)
+	assert.Equal(t, Identity{"Jane", "jane@new.com"}, id)
+
+	id, ok = rewriteIdentity(mappings, Identity{"J", "j@old.com"})
+	assert.True(t, ok)
+	assert.Equal(t, Identity{"Jane", "jane@new.com"}, id)
+
+	// The name has to match when the mapping gives one
+	_, ok = rewriteIdentity(mappings, Identity{"Someone", "j@old.com"})
+	assert.False(t, ok)
+}
+
+func TestRewriteAuthorHistory(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "by test")
+	runGit(t, tmpDir, "commit", "--allow-empty", "-m", "by old", "--author", "Old Name <old@example.com>")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.WriteFile(".mailmap", []byte("New Name <new@example.com> <old@example.com>\n"), 0644))
+	mappings, err := mailmapMappings()
+	require.NoError(t, err)
+	require.Len(t, mappings, 1)
+	assert.Equal(t, Identity{"Old Name", "old@example.com"}, mappings[0].From)
+	assert.Equal(t, Identity{"New Name", "new@example.com"}, mappings[0].To)
+	os.Remove(".mailmap")
+
+	changes, commits, err := previewIdentityChanges(mappings)
+	require.NoError(t, err)
+	assert.Equal(t, 1, commits)
+	require.Len(t, changes, 1)
+	assert.Equal(t, 1, changes[0].Commits)
+
+	// The generated filter rewrites the same commits
+	filterCmd := exec.Command("git", "filter-branch", "--force", "--env-filter", identityEnvFilter(mappings), "--", "--branches")
+	filterCmd.Env = append(os.Environ(), "FILTER_BRANCH_SQUELCH_WARNING=1")
+	output, err := filterCmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	log, err := exec.Command("git", "log", "--no-mailmap", "--format=%an <%ae>").Output()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"New Name <new@example.com>", "Test <test@example.com>"}, strings.Split(strings.TrimSpace(string(log)), "\n"))
+}
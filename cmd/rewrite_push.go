@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/runner"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	rewritePush   bool
+	rewriteNotice string
+	pushRewritten bool
+)
+
+func init() {
+	for _, c := range []*cobra.Command{cleanCmd, purgeCmd, rewriteAuthorCmd} {
+		c.Flags().BoolVar(&rewritePush, "push", false, "force push the rewritten branches and tags with a lease and print what teammates need to run")
+		c.Flags().StringVar(&rewriteNotice, "notice", "", "with --push, also write the instructions for teammates to this markdown file")
+	}
+	pushCmd.Flags().BoolVar(&pushRewritten, "rewritten", false, "push every branch and tag a history rewrite changed, with a lease")
+	pushCmd.Flags().StringVar(&rewriteNotice, "notice", "", "with --rewritten, also write the instructions for teammates to this markdown file")
+}
+
+// rewrittenRef is a branch or tag that differs from its copy on the remote
+type rewrittenRef struct {
+	// Name is the full ref name, e.g. refs/heads/main
+	Name string
+	// Old is the commit or tag on the remote, New the local one
+	Old, New string
+}
+
+// Short returns the branch or tag name without refs/heads/ or refs/tags/
+func (r rewrittenRef) Short() string {
+	if name, ok := strings.CutPrefix(r.Name, "refs/heads/"); ok {
+		return name
+	}
+	return strings.TrimPrefix(r.Name, "refs/tags/")
+}
+
+// IsTag reports whether the ref is a tag
+func (r rewrittenRef) IsTag() bool {
+	return strings.HasPrefix(r.Name, "refs/tags/")
+}
+
+// pushAfterRewrite runs the guided push when --push or rewrite.push asks for
+// it, and otherwise tells how to run it later. summary says what the rewrite
+// did, for the notice to teammates.
+func pushAfterRewrite(summary string) error {
+	if !rewritePush && !forcePush && !viper.GetBool("rewrite.push") {
+		ui.Warn("\nChanges are local only. To push them and get instructions for your teammates:")
+		ui.Println("githelper push --rewritten")
+		return nil
+	}
+	ui.Println()
+	return pushRewrittenHistory(defaultRewriteRemote(), summary)
+}
+
+// defaultRewriteRemote is the remote rewritten history goes to,
+// rewrite.remote or origin
+func defaultRewriteRemote() string {
+	if viper.IsSet("rewrite.remote") {
+		return viper.GetString("rewrite.remote")
+	}
+	return "origin"
+}
+
+// remoteRefs returns the branches and tags of remote with what they point
+// to, as the remote has them now
+func remoteRefs(remote string) (map[string]string, error) {
+	output, err := gitCommand("ls-remote", "--heads", "--tags", remote).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach remote '%s': %w", remote, err)
+	}
+	refs := map[string]string{}
+	for _, line := range splitLines(string(output)) {
+		sha, name, ok := strings.Cut(line, "\t")
+		if ok && !strings.HasSuffix(name, "^{}") {
+			refs[name] = sha
+		}
+	}
+	return refs, nil
+}
+
+// rewrittenRefs compares the local branches and tags with remote. It returns
+// the ones that differ, and the branches remote changed since they were last
+// fetched, which a force push would overwrite unseen. Branches and tags
+// remote doesn't have are left out: they were never shared.
+func rewrittenRefs(remote string) ([]rewrittenRef, []string, error) {
+	remoteShas, err := remoteRefs(remote)
+	if err != nil {
+		return nil, nil, err
+	}
+	output, err := gitCommand("for-each-ref", "--format=%(refname) %(objectname)", "refs/heads", "refs/tags").Output()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list refs: %w", err)
+	}
+
+	var refs []rewrittenRef
+	var stale []string
+	for _, line := range splitLines(string(output)) {
+		name, sha, _ := strings.Cut(line, " ")
+		old, ok := remoteShas[name]
+		if !ok || old == sha {
+			continue
+		}
+		ref := rewrittenRef{Name: name, Old: old, New: sha}
+		if !ref.IsTag() {
+			tracking := fmt.Sprintf("refs/remotes/%s/%s", remote, ref.Short())
+			if fetched, err := gitCommand("rev-parse", "--verify", "-q", tracking).Output(); err == nil && strings.TrimSpace(string(fetched)) != old {
+				stale = append(stale, ref.Short())
+				continue
+			}
+		}
+		refs = append(refs, ref)
+	}
+	return refs, stale, nil
+}
+
+// pushRefsWithLease force pushes refs to remote, each only if the remote
+// still has the commit it was compared with
+func pushRefsWithLease(remote, title string, refs []rewrittenRef) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	args := []string{"push", "--progress"}
+	for _, ref := range refs {
+		args = append(args, fmt.Sprintf("--force-with-lease=%s:%s", ref.Name, ref.Old))
+	}
+	args = append(args, remote)
+	for _, ref := range refs {
+		args = append(args, ref.Name+":"+ref.Name)
+	}
+	if dryRun {
+		ui.Printf("Would run: git %s\n", strings.Join(args, " "))
+		return nil
+	}
+	return gitRunProgress(title, runner.Git(args...))
+}
+
+// pushRewrittenHistory is the guided push after a history rewrite: it checks
+// the remote, force pushes the rewritten branches and then the tags with a
+// lease, and prints what teammates need to run
+func pushRewrittenHistory(remote, summary string) error {
+	url, err := gitCommand("remote", "get-url", remote).Output()
+	if err != nil {
+		return fmt.Errorf("remote '%s' does not exist", remote)
+	}
+	ui.Stepf("🔍 Comparing with %s (%s)...", remote, strings.TrimSpace(string(url)))
+	refs, stale, err := rewrittenRefs(remote)
+	if err != nil {
+		return err
+	}
+	if len(stale) > 0 {
+		return fmt.Errorf("%s changed on %s since your last fetch and pushing would lose those commits. Fetch and check them first, then run 'githelper push --rewritten'",
+			strings.Join(stale, ", "), remote)
+	}
+	if len(refs) == 0 {
+		ui.Successf("%s already has the rewritten history", remote)
+		return nil
+	}
+
+	var branches, tags []rewrittenRef
+	var branchNames []string
+	for _, ref := range refs {
+		if ref.IsTag() {
+			tags = append(tags, ref)
+		} else {
+			branches = append(branches, ref)
+			branchNames = append(branchNames, ref.Short())
+		}
+	}
+	ui.Stepf("\n📤 %d branch(es) and %d tag(s) on %s will be replaced:", len(branches), len(tags), remote)
+	for _, ref := range refs {
+		kind := "branch"
+		if ref.IsTag() {
+			kind = "tag"
+		}
+		ui.Printf("  %-6s %-30s %s → %s\n", kind, ref.Short(), shortSHA(ref.Old), shortSHA(ref.New))
+	}
+	if remotes, err := gitOutput("remote"); err == nil {
+		for _, other := range splitLines(string(remotes)) {
+			if other != remote {
+				ui.Warnf("%s may still have the old history; push to it with 'githelper push %s --rewritten'", other, other)
+			}
+		}
+	}
+
+	if !dryRun {
+		if err := guardOperation("push", branchNames...); err != nil {
+			return err
+		}
+		ui.Warnf("\nThis force pushes to %s. Everyone else has to reset their clones afterwards.", remote)
+		if !confirmAction() {
+			ui.Error("Push cancelled")
+			return nil
+		}
+	}
+
+	if err := pushRefsWithLease(remote, fmt.Sprintf("🚀 Pushing %d branch(es) to %s", len(branches), remote), branches); err != nil {
+		return fmt.Errorf("failed to push the branches. If %s changed since it was compared, the lease protected it; fetch and review before retrying: %w", remote, err)
+	}
+	if err := pushRefsWithLease(remote, fmt.Sprintf("🏷️  Pushing %d tag(s) to %s", len(tags), remote), tags); err != nil {
+		return fmt.Errorf("failed to push the tags: %w", err)
+	}
+
+	instructions := teammateInstructions(remote, branches)
+	ui.Step("\n📋 Send this to everyone with a clone; they need to run it before doing anything else:")
+	ui.Println()
+	for _, line := range instructions {
+		ui.Println(line)
+	}
+	if len(branches) > 0 {
+		ui.Println("\nCommits they haven't pushed yet are based on the old history. They can move them over with:")
+		for _, ref := range branches {
+			ui.Printf("git rebase --onto %s/%s %s %s\n", remote, ref.Short(), shortSHA(ref.Old), ref.Short())
+		}
+	}
+	copyToClipboard("instructions", strings.Join(instructions, "\n")+"\n")
+
+	if rewriteNotice != "" {
+		notice := rewriteNoticeMarkdown(repoName(), remote, summary, refs, time.Now())
+		if dryRun {
+			ui.Printf("Would write %s\n", rewriteNotice)
+		} else if err := os.WriteFile(rewriteNotice, []byte(notice), 0644); err != nil {
+			return fmt.Errorf("failed to write notice: %w", err)
+		} else {
+			ui.Successf("Wrote the notice for your teammates to %s", rewriteNotice)
+		}
+	}
+
+	if dryRun {
+		ui.Step("\n🔍 Dry run - nothing was pushed")
+		return nil
+	}
+	ui.Successf("Pushed the rewritten history to %s", remote)
+	return nil
+}
+
+// teammateInstructions are the commands that bring a clone onto the
+// rewritten branches, throwing away its copy of the old history
+func teammateInstructions(remote string, branches []rewrittenRef) []string {
+	lines := []string{fmt.Sprintf("git fetch %s --prune --tags --force", remote)}
+	for _, ref := range branches {
+		lines = append(lines, fmt.Sprintf("git switch %s && git reset --hard %s/%s", ref.Short(), remote, ref.Short()))
+	}
+	return lines
+}
+
+// rewriteNoticeMarkdown is the announcement of a history rewrite for
+// teammates, with the replaced refs and what to run
+func rewriteNoticeMarkdown(repo, remote, summary string, refs []rewrittenRef, when time.Time) string {
+	var branches []rewrittenRef
+	for _, ref := range refs {
+		if !ref.IsTag() {
+			branches = append(branches, ref)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# The history of %s was rewritten\n\n", repo)
+	if summary != "" {
+		fmt.Fprintf(&b, "%s. ", summary)
+	}
+	fmt.Fprintf(&b, "The branches and tags below were force pushed to `%s` on %s.\n\n", remote, when.Format("2006-01-02"))
+	b.WriteString("| Ref | Old | New |\n|-----|-----|-----|\n")
+	for _, ref := range refs {
+		name := ref.Short()
+		if ref.IsTag() {
+			name = "tag " + name
+		}
+		fmt.Fprintf(&b, "| `%s` | `%s` | `%s` |\n", name, shortSHA(ref.Old), shortSHA(ref.New))
+	}
+
+	b.WriteString("\n## What you need to do\n\nRun this in your clone before anything else:\n\n```bash\n")
+	for _, line := range teammateInstructions(remote, branches) {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("```\n")
+	if len(branches) > 0 {
+		b.WriteString("\nIf you have commits on these branches that aren't pushed yet, move them onto the new history instead of resetting:\n\n```bash\n")
+		for _, ref := range branches {
+			fmt.Fprintf(&b, "git rebase --onto %s/%s %s %s\n", remote, ref.Short(), shortSHA(ref.Old), ref.Short())
+		}
+		b.WriteString("```\n")
+	}
+	b.WriteString("\nDon't merge or push the old history again: it would bring back what the rewrite removed.\n")
+	return b.String()
+}
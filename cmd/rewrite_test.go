@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryPathspec(t *testing.T) {
+	assert.Equal(t, ":(literal)secrets.env", historyPathspec("secrets.env"))
+	assert.Equal(t, ":(literal)vendor", historyPathspec("vendor/"))
+	assert.Equal(t, ":(glob)**/*.zip", historyPathspec("*.zip"))
+	assert.Equal(t, ":(glob)assets/**/*.psd", historyPathspec("assets/**/*.psd"))
+}
+
+func TestPreviewHistoryRemoval(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "base")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join("build", "out"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("build", "app.bin"), make([]byte, 100), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join("build", "out", "lib.bin"), make([]byte, 50), 0644))
+	require.NoError(t, os.WriteFile("video.mp4", make([]byte, 10), 0644))
+	runGit(t, tmpDir, "add", ".")
+	runGit(t, tmpDir, "commit", "-m", "add build")
+	require.NoError(t, os.WriteFile(filepath.Join("build", "app.bin"), make([]byte, 200), 0644))
+	runGit(t, tmpDir, "commit", "-am", "rebuild")
+
+	preview, err := previewHistoryRemoval([]string{"build/"})
+	require.NoError(t, err)
+	assert.Equal(t, []LargeFile{{"build/app.bin", 300}, {"build/out/lib.bin", 50}}, preview.Files)
+	assert.Equal(t, int64(350), preview.TotalSize)
+	assert.Equal(t, 2, preview.Commits)
+
+	preview, err = previewHistoryRemoval([]string{"*.bin", "*.mp4"})
+	require.NoError(t, err)
+	assert.Len(t, preview.Files, 3)
+	assert.Equal(t, 2, preview.Commits)
+
+	preview, err = previewHistoryRemoval([]string{"missing/"})
+	require.NoError(t, err)
+	assert.Empty(t, preview.Files)
+}
+
+func TestPreviewHistoryRemovalOnMergedBranches(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	r.Git("checkout", "--quiet", "-b", "feature")
+	r.Commit("Add config", "secret.env", "TOKEN=abc\n")
+	r.Git("rm", "--quiet", "secret.env")
+	r.Commit("Remove config")
+	r.Checkout("main")
+	r.Commit("Other work", "other.txt", "other\n")
+	r.Git("merge", "--quiet", "--no-ff", "-m", "Merge feature", "feature")
+	r.Git("branch", "--quiet", "-d", "feature")
+
+	// The merge matches main for secret.env, so git's default history
+	// simplification would skip the whole branch
+	preview, err := previewHistoryRemoval([]string{"secret.env"})
+	require.NoError(t, err)
+	assert.Equal(t, []LargeFile{{"secret.env", 10}}, preview.Files)
+	assert.Equal(t, 2, preview.Commits)
+}
+
+func TestLargestDirectories(t *testing.T) {
+	dirs := largestDirectories([]LargeFile{
+		{"a/b/one", 10},
+		{"a/two", 5},
+		{"c/three", 1},
+		{"top", 100},
+	})
+	assert.Equal(t, []LargeFile{{"a/", 15}, {"a/b/", 10}, {"c/", 1}}, dirs)
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/journal"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollbackList  bool
+	rollbackClear bool
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback [id]",
+	Short: "Restore the repository to before the last destructive operation",
+	Long: `Restore branches, tags and uncommitted changes to the state they were in
+before githelper last rewrote history.
+
+Before clean, purge, squash, undo, recover and refresh change anything,
+githelper records the commits of the affected refs in a journal and keeps
+them alive under refs/githelper/backup/. Rollback moves the refs back, checks
+out the branch you were on and reapplies the uncommitted changes it saved.
+
+Rolling back only changes your local repository. If the operation was force
+pushed, push again after rolling back.
+
+Example:
+  githelper rollback             # Undo the last recorded operation
+  githelper rollback --list      # Show the recorded operations
+  githelper rollback 20240102T150405.000
+  githelper rollback --clear     # Forget the journal and delete the backup refs`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRollback,
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+	rollbackCmd.Flags().BoolVarP(&rollbackList, "list", "l", false, "list the recorded operations")
+	rollbackCmd.Flags().BoolVar(&rollbackClear, "clear", false, "delete the journal and all backup refs")
+	rollbackCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be restored without changing anything")
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	j, err := journal.Open(commandCtx)
+	if err != nil {
+		return err
+	}
+
+	if rollbackList {
+		return listJournal(j)
+	}
+
+	if rollbackClear {
+		ui.Warn("This will delete the journal and every backup ref; recorded operations can no longer be rolled back.")
+		if !confirmAction() {
+			ui.Error("Operation cancelled")
+			return nil
+		}
+		if err := j.Clear(commandCtx); err != nil {
+			return err
+		}
+		ui.Success("Journal cleared")
+		return nil
+	}
+
+	var entry *journal.Entry
+	if len(args) > 0 {
+		entry, err = j.Find(args[0])
+	} else {
+		entry, err = j.Last()
+	}
+	if errors.Is(err, journal.ErrEmpty) {
+		ui.Println("Nothing to roll back: no destructive operations recorded in this repository")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	ui.Stepf("⏪ Rolling back '%s' from %s", entry.Operation, entry.Time.Format("2006-01-02 15:04:05"))
+	printJournalEntry(entry)
+
+	if dryRun {
+		ui.Step("\n🔍 Dry run - nothing was changed")
+		return nil
+	}
+
+	// Restoring resets the working tree, so refuse to discard current work
+	status, err := gitCommand("status", "--porcelain", "--untracked-files=no").Output()
+	if err != nil {
+		return fmt.Errorf(i18n.T("error.git_status"), err)
+	}
+	if len(status) > 0 {
+		return fmt.Errorf("you have uncommitted changes. Commit or stash them before rolling back")
+	}
+
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	if err := j.Restore(commandCtx, entry); err != nil {
+		return fmt.Errorf("failed to roll back: %w", err)
+	}
+
+	ui.Successf("Rolled back '%s'", entry.Operation)
+	return nil
+}
+
+func listJournal(j *journal.Journal) error {
+	entries, err := j.Entries()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		ui.Println("No destructive operations recorded in this repository")
+		return nil
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		status := ""
+		if entry.RolledBack {
+			status = " (rolled back)"
+		}
+		ui.Printf("%s  %-8s %s  %d ref(s)%s\n", entry.ID, entry.Operation,
+			entry.Time.Format("2006-01-02 15:04:05"), len(entry.Refs), status)
+	}
+	return nil
+}
+
+func printJournalEntry(entry *journal.Entry) {
+	for _, name := range sortedKeys(entry.Refs) {
+		ui.Printf("  %s -> %s\n", strings.TrimPrefix(name, "refs/"), shortSHA(entry.Refs[name]))
+	}
+	if entry.WorkTree != "" {
+		ui.Println("  + uncommitted changes")
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// recordOperation journals the current branch, and every branch and tag when
+// allRefs is set, before a destructive operation so it can be rolled back
+func recordOperation(operation string, allRefs bool) error {
+	j, err := journal.Open(commandCtx)
+	if err != nil {
+		return err
+	}
+
+	var refs []string
+	if allRefs {
+		refs = []string{"refs/heads/", "refs/tags/"}
+	}
+	entry, err := j.Record(commandCtx, operation, refs...)
+	if err != nil {
+		return fmt.Errorf("failed to record state for rollback: %w", err)
+	}
+	ui.Stepf("📒 Recorded current state (undo with 'githelper rollback %s')", entry.ID)
+	return nil
+}
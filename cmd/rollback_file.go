@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/checkpoint"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rollbackFileCommit bool
+	rollbackFileRevert bool
+)
+
+// FileRevision is a commit that changed a file
+type FileRevision struct {
+	Commit  string
+	Date    string
+	Author  string
+	Subject string
+	// Path is the name of the file in that commit, which differs from the
+	// current one when the file was renamed since
+	Path string
+}
+
+var rollbackFileCmd = &cobra.Command{
+	Use:   "rollback-file <path> [revision]",
+	Short: "Bring a file back to a past version",
+	Long: `Pick one of the commits that changed a file, following renames, and bring the
+file back to its version in that commit. The picker previews what each commit
+changed in the file; give the revision to skip it.
+
+By default the version is only written to the working tree, so you can look
+at it and commit it yourself. --commit commits it right away; --revert
+instead undoes only the changes the picked commit made to the file, keeping
+everything that came after, and commits that.
+
+Uncommitted changes to the file are saved as a 'githelper watch' checkpoint
+before being overwritten.
+
+Example:
+  githelper rollback-file config/app.yaml
+  githelper rollback-file config/app.yaml 1a2b3c4
+  githelper rollback-file src/parser.go --commit
+  githelper rollback-file src/parser.go --revert`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runRollbackFile,
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackFileCmd)
+	rollbackFileCmd.Flags().BoolVar(&rollbackFileCommit, "commit", false, "commit the restored version")
+	rollbackFileCmd.Flags().BoolVar(&rollbackFileRevert, "revert", false, "commit the reverse of the picked commit's changes to the file")
+	rollbackFileCmd.Flags().BoolVar(&noFzf, "no-fzf", false, "disable fzf usage even if available")
+	addSigningFlags(rollbackFileCmd)
+}
+
+func runRollbackFile(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	if rollbackFileCommit && rollbackFileRevert {
+		return fmt.Errorf("--commit and --revert can't be combined")
+	}
+	file := args[0]
+
+	revisions, err := fileRevisions(file)
+	if err != nil {
+		return err
+	}
+	if len(revisions) == 0 {
+		return fmt.Errorf("no commit changed '%s'", file)
+	}
+
+	var revision *FileRevision
+	if len(args) > 1 {
+		commit, err := gitCommand("rev-parse", "--verify", "-q", args[1]+"^{commit}").Output()
+		if err != nil {
+			return fmt.Errorf("unknown revision '%s'", args[1])
+		}
+		for i := range revisions {
+			if revisions[i].Commit == strings.TrimSpace(string(commit)) {
+				revision = &revisions[i]
+				break
+			}
+		}
+		if revision == nil {
+			return fmt.Errorf("%s didn't change '%s'", args[1], file)
+		}
+	} else {
+		if revision, err = selectFileRevision(revisions); err != nil {
+			return err
+		}
+		if revision == nil {
+			ui.Error("No revision selected")
+			return nil
+		}
+	}
+
+	modified, err := fileModified(file)
+	if err != nil {
+		return err
+	}
+	if modified && (rollbackFileCommit || rollbackFileRevert) {
+		return fmt.Errorf("'%s' has uncommitted changes. Please commit or stash them first", file)
+	}
+
+	if rollbackFileRevert {
+		return revertFileChanges(file, revision)
+	}
+
+	ui.Stepf("⏪ Restoring '%s' to its version in %s %s (%s, %s)", file, shortSHA(revision.Commit), revision.Subject, revision.Author, revision.Date)
+	if modified {
+		ui.Warn("The file has uncommitted changes, which will be overwritten")
+		if !confirmAction() {
+			ui.Error("Operation cancelled")
+			return nil
+		}
+		if store, err := checkpoint.Open(commandCtx); err == nil {
+			branch, _ := gitCommand("symbolic-ref", "-q", "--short", "HEAD").Output()
+			if cp, err := store.Save(commandCtx, strings.TrimSpace(string(branch))); err != nil {
+				return fmt.Errorf("failed to save the uncommitted changes: %w", err)
+			} else if cp != nil {
+				ui.Stepf("📍 Saved them as checkpoint %s ('githelper watch restore %s %s' brings them back)", shortSHA(cp.Commit), shortSHA(cp.Commit), file)
+			}
+		}
+	}
+	if err := writeFileVersion(file, revision); err != nil {
+		return err
+	}
+
+	if !rollbackFileCommit {
+		ui.Successf("Restored '%s'. Review it with 'git diff %s' and commit it when ready", file, file)
+		return nil
+	}
+	message := fmt.Sprintf("Revert %s to %s\n\nRestore the version from %s (%s).", file, shortSHA(revision.Commit), revision.Commit, revision.Subject)
+	if err := commitFile(file, message); err != nil {
+		return err
+	}
+	ui.Successf("Committed '%s' as of %s", file, shortSHA(revision.Commit))
+	return nil
+}
+
+// fileRevisions lists the commits that changed a file, newest first,
+// following renames and leaving out the commit that deleted it
+func fileRevisions(file string) ([]FileRevision, error) {
+	output, err := gitCommand("log", "--follow", "--diff-filter=d", "--name-only", "--date=short",
+		"--format=%x00%H%x09%ad%x09%an%x09%s", "--", file).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history of '%s': %w", file, err)
+	}
+	return parseFileRevisions(string(output)), nil
+}
+
+func parseFileRevisions(output string) []FileRevision {
+	var revisions []FileRevision
+	for _, entry := range strings.Split(output, "\x00") {
+		lines := strings.Split(strings.TrimSpace(entry), "\n")
+		fields := strings.SplitN(lines[0], "\t", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		revision := FileRevision{Commit: fields[0], Date: fields[1], Author: fields[2], Subject: fields[3]}
+		for _, line := range lines[1:] {
+			if line = strings.TrimSpace(line); line != "" {
+				revision.Path = line
+			}
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions
+}
+
+// fileModified reports whether a file differs from HEAD, in the index or the
+// working tree
+func fileModified(file string) (bool, error) {
+	output, err := gitCommand("status", "--porcelain", "--", file).Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to check '%s': %w", file, err)
+	}
+	return len(bytes.TrimSpace(output)) > 0, nil
+}
+
+// writeFileVersion writes the content of a file in a revision to the file,
+// keeping its permissions when it exists
+func writeFileVersion(file string, revision *FileRevision) error {
+	content, err := gitCommand("cat-file", "blob", revision.Commit+":"+revision.Path).Output()
+	if err != nil {
+		return fmt.Errorf("failed to read '%s' in %s: %w", revision.Path, shortSHA(revision.Commit), err)
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(file); err == nil {
+		mode = info.Mode().Perm()
+	} else if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create the directory of '%s': %w", file, err)
+	}
+	if err := os.WriteFile(file, content, mode); err != nil {
+		return fmt.Errorf("failed to write '%s': %w", file, err)
+	}
+	return nil
+}
+
+// revertFileChanges commits the reverse of the changes a revision made to
+// the file
+func revertFileChanges(file string, revision *FileRevision) error {
+	ui.Stepf("↩️  Reverting the changes %s %s made to '%s'", shortSHA(revision.Commit), revision.Subject, file)
+	root, err := getRepoRoot()
+	if err != nil {
+		return err
+	}
+	prefix, err := gitCommand("rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return fmt.Errorf("failed to find '%s' in the repository: %w", file, err)
+	}
+	if current := path.Clean(strings.TrimSpace(string(prefix)) + filepath.ToSlash(file)); current != revision.Path {
+		return fmt.Errorf("'%s' was called '%s' in %s. Use --commit to restore that version instead", file, revision.Path, shortSHA(revision.Commit))
+	}
+	patch, err := gitCommand("show", "--format=", "--binary", revision.Commit, "--", ":(top)"+revision.Path).Output()
+	if err != nil {
+		return fmt.Errorf("failed to read the changes of %s: %w", shortSHA(revision.Commit), err)
+	}
+
+	// git apply ignores the paths outside the current directory
+	applyCmd := gitCommand("apply", "-R", "--index", "--3way")
+	applyCmd.Dir = root
+	applyCmd.Stdin = bytes.NewReader(patch)
+	if output, err := applyCmd.CombinedOutput(); err != nil {
+		if hasConflicts() {
+			ui.Warn("Later changes conflict with the revert")
+			ui.Stepf("👉 Resolve '%s' with 'githelper resolve', then commit it", file)
+			return nil
+		}
+		return fmt.Errorf("failed to revert the changes: %s", strings.TrimSpace(string(output)))
+	}
+
+	message := fmt.Sprintf("Revert \"%s\" in %s\n\nThis reverts the changes commit %s made to %s.", revision.Subject, file, revision.Commit, file)
+	if err := commitFile(file, message); err != nil {
+		return err
+	}
+	ui.Successf("Reverted the changes of %s to '%s'", shortSHA(revision.Commit), file)
+	return nil
+}
+
+// commitFile commits a file, and only that file, with message
+func commitFile(file, message string) error {
+	if output, err := gitCommand("add", "--", file).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to stage '%s': %s", file, strings.TrimSpace(string(output)))
+	}
+	args := append([]string{"commit", "-q", "-m", message}, commitSigningArgs()...)
+	args = append(args, "--", file)
+	if output, err := gitCommand(args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to commit '%s': %s", file, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func selectFileRevision(revisions []FileRevision) (*FileRevision, error) {
+	if !noFzf && isInteractive() {
+		if _, err := exec.LookPath("fzf"); err == nil {
+			return selectFileRevisionWithFzf(revisions)
+		}
+	}
+	return selectFileRevisionWithList(revisions)
+}
+
+func selectFileRevisionWithFzf(revisions []FileRevision) (*FileRevision, error) {
+	var input strings.Builder
+	for i, r := range revisions {
+		fmt.Fprintf(&input, "%d\t%s %s %-15s %s\t%s\t%s\n", i, shortSHA(r.Commit), r.Date, truncate(r.Author, 15), r.Subject, r.Commit, r.Path)
+	}
+
+	// Show what the commit changed in the file
+	previewCmd := "git show --color=always --format='%h %s%n%an, %ad%n' {3} -- ':(top)'{4}"
+
+	fzfCmd := exec.Command("fzf",
+		"--height", "60%",
+		"--reverse",
+		"--delimiter", "\t",
+		"--with-nth", "2",
+		"--preview", previewCmd,
+		"--preview-window", "right:60%")
+
+	fzfCmd.Stdin = strings.NewReader(input.String())
+	fzfCmd.Stderr = os.Stderr
+
+	output, err := fzfCmd.Output()
+	if err != nil {
+		return nil, nil // User cancelled
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &index); err != nil || index < 0 || index >= len(revisions) {
+		return nil, fmt.Errorf("invalid selection")
+	}
+	return &revisions[index], nil
+}
+
+func selectFileRevisionWithList(revisions []FileRevision) (*FileRevision, error) {
+	ui.Println("\nRevisions:")
+	for i, r := range revisions {
+		if i >= 30 {
+			ui.Printf("    ... %d older, pass the revision as argument\n", len(revisions)-i)
+			break
+		}
+		ui.Printf("%2d: %s %s %s (%s)\n", i+1, shortSHA(r.Commit), r.Date, r.Subject, r.Author)
+	}
+
+	input := readInput("\nSelect revision number (or press Enter to cancel): ")
+
+	if input == "" {
+		return nil, nil
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(input, "%d", &index); err != nil || index < 1 || index > len(revisions) || index > 30 {
+		return nil, fmt.Errorf("invalid selection")
+	}
+	return &revisions[index-1], nil
+}
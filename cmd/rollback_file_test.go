@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollbackFile(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+	runGit(t, tmpDir, "config", "user.name", "Test")
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+
+	var commits []string
+	for i, content := range []string{"a\nb\nc\nd\ne\nf\n", "a\nB\nc\nd\ne\nf\n", "a\nB\nc\nd\ne\nF\n"} {
+		require.NoError(t, os.WriteFile("config.txt", []byte(content), 0644))
+		runGit(t, tmpDir, "add", ".")
+		runGit(t, tmpDir, "commit", "-m", []string{"add config", "change b", "change f"}[i])
+		sha, err := exec.Command("git", "rev-parse", "HEAD").Output()
+		require.NoError(t, err)
+		commits = append(commits, strings.TrimSpace(string(sha)))
+	}
+	runGit(t, tmpDir, "mv", "config.txt", "settings.txt")
+	runGit(t, tmpDir, "commit", "-m", "rename")
+
+	revisions, err := fileRevisions("settings.txt")
+	require.NoError(t, err)
+	require.Len(t, revisions, 4)
+	assert.Equal(t, "rename", revisions[0].Subject)
+	assert.Equal(t, "settings.txt", revisions[0].Path)
+	assert.Equal(t, commits[0], revisions[3].Commit)
+	assert.Equal(t, "config.txt", revisions[3].Path)
+
+	// Restore the first version, from before the rename, and commit it
+	rollbackFileCommit = true
+	defer func() { rollbackFileCommit = false }()
+	require.NoError(t, runRollbackFile(rollbackFileCmd, []string{"settings.txt", commits[0]}))
+	data, err := os.ReadFile("settings.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\nc\nd\ne\nf\n", string(data))
+	subject, err := exec.Command("git", "log", "-1", "--format=%s").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "Revert settings.txt to "+shortSHA(commits[0]), strings.TrimSpace(string(subject)))
+	rollbackFileCommit = false
+
+	// Revert only "change b" in a file that wasn't renamed
+	runGit(t, tmpDir, "reset", "--hard", commits[2])
+	rollbackFileRevert = true
+	defer func() { rollbackFileRevert = false }()
+	require.NoError(t, runRollbackFile(rollbackFileCmd, []string{"config.txt", commits[1]}))
+	data, err = os.ReadFile("config.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\nc\nd\ne\nF\n", string(data))
+	status, err := exec.Command("git", "status", "--porcelain").Output()
+	require.NoError(t, err)
+	assert.Empty(t, strings.TrimSpace(string(status)))
+}
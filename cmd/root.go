@@ -3,7 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -14,17 +19,39 @@ var rootCmd = &cobra.Command{
 	Long: `GitHelper is a command-line tool that simplifies complex GitHub workflows
 that are not straightforward with basic Git commands. It provides various
 utilities to manage repositories, branches, and common Git operations.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		startOutput()
+		startCommandContext(cmd)
+		startHistory(cmd)
+		guardDetachedHead(cmd)
+		startNotify(cmd)
+		return startHooks(cmd)
+	},
 }
 
-// Execute executes the root command
+// Execute executes the root command, or the plugin providing an unknown
+// subcommand
 func Execute() error {
-	return rootCmd.Execute()
+	if found, args, ok := findPluginCommand(os.Args[1:]); ok {
+		return runPlugin(found, args)
+	}
+	applyCommandGroups(rootCmd)
+	return executeWithInterrupts()
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().Bool("debug", false, "enable debug logging")
 	rootCmd.PersistentFlags().String("config", "", "config file (default is $HOME/.githelper.yaml)")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "answer yes to confirmations and skip safety checks (for automation)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "profile to use (see 'githelper profile')")
+	rootCmd.PersistentFlags().StringVar(&hostName, "host", "", "GitHub host to use (default is github.com or default_host from the config)")
+	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "never prompt; fail or use defaults instead (auto-enabled without a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", false, "plain text output without emoji, colors or box drawing (for screen readers and logs)")
+	rootCmd.PersistentFlags().BoolVarP(&quietOutput, "quiet", "q", false, "only print results, warnings and errors")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "don't color the output (also NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVar(&machineOutput, "machine", false, "print status messages to stderr as JSON lines, leaving stdout to results")
+	rootCmd.PersistentFlags().DurationVar(&commandTimeout, "timeout", 0, "stop the command if it runs longer than this (e.g. 10m); 0 for no limit")
 }
 
 func initConfig() {
@@ -33,10 +60,10 @@ func initConfig() {
 	// Always show config file location in debug mode
 	if debug {
 		if cfgFile := rootCmd.PersistentFlags().Lookup("config").Value.String(); cfgFile != "" {
-			fmt.Printf("Using config file specified by flag: %s\n", cfgFile)
+			ui.Printf("Using config file specified by flag: %s\n", cfgFile)
 		} else {
 			home, _ := os.UserHomeDir()
-			fmt.Printf("No config file specified, will look in: %s/.githelper.yaml\n", home)
+			ui.Printf("No config file specified, will look in: %s/.githelper.yaml\n", home)
 		}
 	}
 
@@ -61,11 +88,11 @@ func initConfig() {
 		
 		// Add debug line to show where we're looking
 		if debug {
-			fmt.Printf("Looking for config file at: %s/.githelper.yaml\n", home)
+			ui.Printf("Looking for config file at: %s/.githelper.yaml\n", home)
 			if _, err := os.Stat(fmt.Sprintf("%s/.githelper.yaml", home)); err != nil {
-				fmt.Printf("Config file status: %v\n", err)
+				ui.Printf("Config file status: %v\n", err)
 			} else {
-				fmt.Println("Config file exists")
+				ui.Println("Config file exists")
 			}
 		}
 	}
@@ -78,7 +105,7 @@ func initConfig() {
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			if debug {
-				fmt.Println("No config file found")
+				ui.Println("No config file found")
 			}
 		} else {
 			fmt.Fprintln(os.Stderr, "Error reading config file:", err)
@@ -86,17 +113,157 @@ func initConfig() {
 		}
 	}
 
+	// Repository settings (e.g. protected branches) override the user config
+	if repoConfig := repoConfigFile(); repoConfig != "" {
+		if err := mergeConfigFile(repoConfig); err != nil {
+			fmt.Fprintln(os.Stderr, "Error reading repository config file:", err)
+			os.Exit(1)
+		}
+		if debug {
+			ui.Printf("Merged repository config file: %s\n", repoConfig)
+		}
+	}
+
+	// The active profile overrides the identity settings
+	if err := applyProfile(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	if debug && activeProfile() != "" {
+		ui.Printf("Using profile: %s\n", activeProfile())
+	}
+
+	// Messages follow the language setting, or else the locale
+	i18n.SetLanguage(i18n.Detect(viper.GetString("language")))
+
 	if debug {
-		fmt.Printf("Using config file: %s\n", viper.ConfigFileUsed())
-		fmt.Printf("GitHub token present: %v\n", viper.GetString("github_token") != "")
-		fmt.Printf("OpenAI API key present: %v\n", viper.GetString("openai_api_key") != "")
-		fmt.Printf("Config values: %+v\n", viper.AllSettings())
+		ui.Printf("Using config file: %s\n", viper.ConfigFileUsed())
+		ui.Printf("GitHub token present: %v\n", viper.GetString("github_token") != "")
+		ui.Printf("OpenAI API key present: %v\n", viper.GetString("openai_api_key") != "")
+		ui.Printf("Config values: %+v\n", viper.AllSettings())
 	}
 
 	if debug {
-		fmt.Printf("Final config state:\n")
-		fmt.Printf("Config file used: %s\n", viper.ConfigFileUsed())
-		fmt.Printf("All settings: %#v\n", viper.AllSettings())
-		fmt.Printf("GitHub token length: %d\n", len(viper.GetString("github_token")))
+		ui.Printf("Final config state:\n")
+		ui.Printf("Config file used: %s\n", viper.ConfigFileUsed())
+		ui.Printf("All settings: %#v\n", viper.AllSettings())
+		ui.Printf("GitHub token length: %d\n", len(viper.GetString("github_token")))
+		// Every API request reports the rate limit quota left
+		github.Debug = os.Stderr
 	}
-}
\ No newline at end of file
+}
+
+// repoConfigFile returns the .githelper.yaml at the root of the current
+// repository, if there is one
+func repoConfigFile() string {
+	root, err := getRepoRoot()
+	if err != nil {
+		return ""
+	}
+	path := filepath.Join(root, ".githelper.yaml")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// getRepoRoot returns the top-level directory of the current repository
+func getRepoRoot() (string, error) {
+	output, err := gitCommand("rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find repository root: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// repoConfigKeys are the settings a repository's .githelper.yaml may set.
+// They only describe or restrict work in that repository. Anything else in
+// the file, such as hosts or tokens, is ignored: a cloned repository must not
+// be able to send the user's token to another server.
+var repoConfigKeys = []string{
+	"safety",
+	"default_branch",
+	"commit_template",
+	"commit_conventions",
+	"branch_policy",
+	"lint_history",
+	"policy",
+	"clean_workdir",
+	"sparse_profiles",
+	"precheck.debug_patterns",
+}
+
+// repoTrustedKeys are the settings of a repository's .githelper.yaml that run
+// commands. They are only used once the user trusted them with 'githelper
+// hooks trust'.
+var repoTrustedKeys = []string{
+	"hooks",
+	"aliases",
+	"workflows",
+	"precheck.commands",
+}
+
+// mergeConfigFile merges the settings of a repository's .githelper.yaml
+// allowed by repoConfigKeys and repoTrustedKeys over the user config
+func mergeConfigFile(path string) error {
+	settings, err := readConfigSettings(path)
+	if err != nil {
+		return err
+	}
+
+	keys := repoConfigKeys
+	trusted, err := repoCommandsTrusted()
+	if err != nil {
+		return err
+	}
+	if trusted {
+		keys = append(append([]string(nil), keys...), repoTrustedKeys...)
+	}
+	return viper.MergeConfigMap(pickSettings(settings, keys))
+}
+
+// repoConfigAllows reports whether a repository's .githelper.yaml may set
+// the key
+func repoConfigAllows(key string) bool {
+	for _, allowed := range append(append([]string(nil), repoConfigKeys...), repoTrustedKeys...) {
+		if key == allowed || strings.HasPrefix(key, allowed+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// pickSettings returns the settings under the given keys, which may be
+// dotted paths such as precheck.commands
+func pickSettings(settings map[string]interface{}, keys []string) map[string]interface{} {
+	picked := make(map[string]interface{})
+	for _, key := range keys {
+		path := strings.Split(key, ".")
+		value, ok := interface{}(settings), true
+		for _, segment := range path {
+			section, isMap := value.(map[string]interface{})
+			if !isMap {
+				ok = false
+				break
+			}
+			if value, ok = section[segment]; !ok {
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		target := picked
+		for _, segment := range path[:len(path)-1] {
+			next, isMap := target[segment].(map[string]interface{})
+			if !isMap {
+				next = make(map[string]interface{})
+				target[segment] = next
+			}
+			target = next
+		}
+		target[path[len(path)-1]] = value
+	}
+	return picked
+}
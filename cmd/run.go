@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/EndlessUphill/git-helper/internal/config"
+	"github.com/EndlessUphill/git-helper/internal/git"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var (
+	workflowParams []string
+)
+
+var runWorkflowCmd = &cobra.Command{
+	Use:   "run [name] [key=value...]",
+	Short: "Run an alias or custom workflow from the config",
+	Long: `Run named sequences of git and githelper steps defined in .githelper.yaml.
+
+An alias is a single githelper command line; a workflow is a list of steps,
+each starting with 'git' or 'githelper', run in order until one fails.
+Workflow steps can use parameters as {{.name}}:
+
+  aliases:
+    undo-last: undo --soft
+  workflows:
+    ship:
+      description: Commit, push and open a pull request
+      params:
+        - name: type
+          default: feat
+      steps:
+        - githelper commit --ai --type {{.type}}
+        - git push -u origin HEAD
+        - githelper pr create
+
+Workflows in the repository's .githelper.yaml are shared with the team; they
+are available once you have read them and run 'githelper hooks trust'.
+Without a name, the available aliases and workflows are listed.
+
+Example:
+  githelper run                      # List aliases and workflows
+  githelper run ship                 # Run the ship workflow
+  githelper run ship type=fix        # Override a parameter
+  githelper run ship --dry-run       # Show the steps without running them`,
+	RunE: runWorkflow,
+}
+
+func init() {
+	rootCmd.AddCommand(runWorkflowCmd)
+	runWorkflowCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the steps without running them")
+	runWorkflowCmd.Flags().StringArrayVarP(&workflowParams, "param", "p", nil, "workflow parameter as key=value")
+}
+
+func runWorkflow(cmd *cobra.Command, args []string) error {
+	aliases, workflows, err := configuredWorkflows()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		listWorkflows(aliases, workflows)
+		return nil
+	}
+
+	name := args[0]
+	workflow, ok := workflows[name]
+	if !ok {
+		alias, isAlias := aliases[name]
+		if !isAlias {
+			return fmt.Errorf("no alias or workflow named '%s'. Run 'githelper run' to list them", name)
+		}
+		workflow = config.Workflow{Steps: []string{"githelper " + alias}}
+	}
+
+	params, err := workflowParamValues(workflow, append(workflowParams, args[1:]...))
+	if err != nil {
+		return err
+	}
+	steps, err := expandWorkflowSteps(workflow.Steps, params)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		ui.Stepf("🔍 Dry run - '%s' would run:", name)
+		for i, step := range steps {
+			ui.Printf("%2d: %s\n", i+1, formatArgs(step))
+		}
+		return nil
+	}
+
+	for i, step := range steps {
+		ui.Printf("\n▶️  [%d/%d] %s\n", i+1, len(steps), formatArgs(step))
+		if err := runWorkflowStep(step); err != nil {
+			if i+1 < len(steps) {
+				ui.Stepf("⏹️  Skipped the remaining %d step(s)", len(steps)-i-1)
+			}
+			return fmt.Errorf("step %d (%s) failed: %w", i+1, formatArgs(step), err)
+		}
+	}
+
+	ui.Successf("\n'%s' completed (%d step(s))", name, len(steps))
+	return nil
+}
+
+func configuredWorkflows() (map[string]string, map[string]config.Workflow, error) {
+	var aliases map[string]string
+	if err := viper.UnmarshalKey("aliases", &aliases); err != nil {
+		return nil, nil, fmt.Errorf("invalid aliases configuration: %w", err)
+	}
+	var workflows map[string]config.Workflow
+	if err := viper.UnmarshalKey("workflows", &workflows); err != nil {
+		return nil, nil, fmt.Errorf("invalid workflows configuration: %w", err)
+	}
+	return aliases, workflows, nil
+}
+
+func listWorkflows(aliases map[string]string, workflows map[string]config.Workflow) {
+	if len(aliases) == 0 && len(workflows) == 0 {
+		ui.Println("No aliases or workflows configured. Add them to .githelper.yaml (see 'githelper run --help')")
+		return
+	}
+
+	if len(workflows) > 0 {
+		ui.Println("Workflows:")
+		for _, name := range sortedKeys(workflows) {
+			workflow := workflows[name]
+			ui.Printf("  %-15s %s (%d step(s))\n", name, workflow.Description, len(workflow.Steps))
+			for _, param := range workflow.Params {
+				ui.Printf("  %-15s   %s=%s\n", "", param.Name, param.Default)
+			}
+		}
+	}
+	if len(aliases) > 0 {
+		ui.Println("Aliases:")
+		for _, name := range sortedKeys(aliases) {
+			ui.Printf("  %-15s githelper %s\n", name, aliases[name])
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// workflowParamValues combines the parameter defaults with key=value arguments
+func workflowParamValues(workflow config.Workflow, args []string) (map[string]string, error) {
+	values := make(map[string]string)
+	declared := make(map[string]bool)
+	for _, param := range workflow.Params {
+		declared[param.Name] = true
+		values[param.Name] = param.Default
+	}
+
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid parameter '%s'. Use key=value", arg)
+		}
+		if len(declared) > 0 && !declared[key] {
+			return nil, fmt.Errorf("unknown parameter '%s'", key)
+		}
+		values[key] = value
+	}
+
+	for _, param := range workflow.Params {
+		if param.Required && values[param.Name] == "" {
+			return nil, fmt.Errorf("parameter '%s' is required. Pass %s=<value>", param.Name, param.Name)
+		}
+	}
+	return values, nil
+}
+
+// expandWorkflowSteps fills in the parameters and splits each step into its
+// arguments. Only git and githelper steps are allowed.
+func expandWorkflowSteps(steps []string, params map[string]string) ([][]string, error) {
+	var expanded [][]string
+	for i, step := range steps {
+		tmpl, err := template.New("step").Option("missingkey=error").Parse(step)
+		if err != nil {
+			return nil, fmt.Errorf("invalid step %d: %w", i+1, err)
+		}
+		var line strings.Builder
+		if err := tmpl.Execute(&line, params); err != nil {
+			return nil, fmt.Errorf("invalid step %d: %w", i+1, err)
+		}
+
+		args, err := splitArgs(line.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid step %d: %w", i+1, err)
+		}
+		if len(args) == 0 {
+			continue
+		}
+		if args[0] != "git" && args[0] != "githelper" {
+			return nil, fmt.Errorf("step %d must start with 'git' or 'githelper', got '%s'", i+1, args[0])
+		}
+		expanded = append(expanded, args)
+	}
+	return expanded, nil
+}
+
+// splitArgs splits a command line on spaces, honouring single and double quotes
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in '%s'", line)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// formatArgs joins arguments for display, quoting those with spaces
+func formatArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = arg
+		if arg == "" || strings.ContainsAny(arg, " \t") {
+			quoted[i] = fmt.Sprintf("%q", arg)
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+// runWorkflowStep runs a step. githelper steps run this executable with the
+// global flags given to 'githelper run'.
+func runWorkflowStep(step []string) error {
+	name, args := step[0], step[1:]
+	if name == "githelper" {
+		executable, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		name = executable
+		args = append(globalFlagArgs(), args...)
+	}
+
+	// Steps stop with the run, and get the time git gets to exit cleanly
+	stepCmd := exec.CommandContext(commandCtx, name, args...)
+	stepCmd.Cancel = func() error { return stepCmd.Process.Signal(os.Interrupt) }
+	stepCmd.WaitDelay = git.WaitDelay
+	stepCmd.Stdin = os.Stdin
+	stepCmd.Stdout = os.Stdout
+	stepCmd.Stderr = os.Stderr
+	return stepCmd.Run()
+}
+
+// globalFlagArgs returns the global flags set on the command line
+func globalFlagArgs() []string {
+	var args []string
+	rootCmd.PersistentFlags().Visit(func(flag *pflag.Flag) {
+		args = append(args, fmt.Sprintf("--%s=%s", flag.Name, flag.Value.String()))
+	})
+	return args
+}
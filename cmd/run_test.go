@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitArgs(t *testing.T) {
+	args, err := splitArgs(`git log -1 --format="%s by %an" 'single quoted'`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"git", "log", "-1", "--format=%s by %an", "single quoted"}, args)
+
+	_, err = splitArgs(`git commit -m "unterminated`)
+	assert.Error(t, err)
+}
+
+func TestWorkflowParamValues(t *testing.T) {
+	workflow := config.Workflow{Params: []config.WorkflowParam{
+		{Name: "type", Default: "feat"},
+		{Name: "env", Required: true},
+	}}
+
+	values, err := workflowParamValues(workflow, []string{"env=staging"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"type": "feat", "env": "staging"}, values)
+
+	_, err = workflowParamValues(workflow, nil)
+	assert.Error(t, err)
+
+	_, err = workflowParamValues(workflow, []string{"env=prod", "other=1"})
+	assert.Error(t, err)
+}
+
+func TestExpandWorkflowSteps(t *testing.T) {
+	steps, err := expandWorkflowSteps([]string{
+		"githelper commit --type {{.type}}",
+		"git push -u origin HEAD",
+	}, map[string]string{"type": "fix"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"githelper", "commit", "--type", "fix"},
+		{"git", "push", "-u", "origin", "HEAD"},
+	}, steps)
+
+	_, err = expandWorkflowSteps([]string{"rm -rf /"}, nil)
+	assert.Error(t, err)
+
+	_, err = expandWorkflowSteps([]string{"git checkout {{.missing}}"}, map[string]string{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/EndlessUphill/git-helper/internal/progress"
+	"github.com/EndlessUphill/git-helper/internal/runner"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+)
+
+// commandRunner runs the git commands that change the repository. --dry-run
+// and tests replace it with a runner.Recorder.
+var commandRunner runner.Runner = runner.Exec{}
+
+// gitRun runs git through commandRunner, showing its errors
+func gitRun(args ...string) error {
+	return commandRunner.Run(commandCtx, runner.Git(args...).WithOutput(nil, os.Stderr))
+}
+
+// gitRunShown runs git through commandRunner, showing all its output
+func gitRunShown(args ...string) error {
+	return commandRunner.Run(commandCtx, runner.Git(args...).WithOutput(os.Stdout, os.Stderr))
+}
+
+// gitRunProgress runs a git command through commandRunner, showing its
+// progress under title
+func gitRunProgress(title string, cmd runner.Command) error {
+	return progress.Run(ui.Status(), title, func(stderr io.Writer) error {
+		return commandRunner.Run(commandCtx, cmd.WithOutput(nil, stderr))
+	})
+}
+
+// gitOutput runs git through commandRunner and returns its output
+func gitOutput(args ...string) ([]byte, error) {
+	return commandRunner.Output(commandCtx, runner.Git(args...))
+}
+
+// startDryRun makes commandRunner print the git commands that would change
+// the repository instead of running them, until the returned func is called
+func startDryRun() func() {
+	previous := commandRunner
+	commandRunner = runner.NewDryRun(ui.Out())
+	return func() { commandRunner = previous }
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchRegex  bool
+	searchAuthor string
+	searchPaths  []string
+	searchSince  string
+	searchUntil  string
+	searchAll    bool
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <string|regex>",
+	Short: "Search history for commits that added or removed text",
+	Long: `Find the commits that introduced or removed a piece of code.
+
+This command wraps git's "pickaxe" search (git log -S / -G):
+1. Finds commits whose diff adds or removes the text
+2. Lets you browse them with a diff preview
+3. Shows the full diff of the commit you pick
+
+By default the text must change its number of occurrences (-S); with --regex
+any added or removed line matching the regular expression counts (-G).
+
+Example:
+  githelper search "getRepoSize"                  # Where was it added/removed?
+  githelper search --regex "Timeout\s*=" --path cmd/
+  githelper search "TODO" --author alice --since "2 weeks ago"
+  githelper search "getRepoSize" --select 1a2b3c4d  # Show this result without prompting`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	flags := searchCmd.Flags()
+	flags.BoolVarP(&searchRegex, "regex", "r", false, "treat the search as a regular expression matched against changed lines")
+	flags.StringVar(&searchAuthor, "author", "", "only commits by this author")
+	flags.StringSliceVar(&searchPaths, "path", nil, "only changes under these paths")
+	flags.StringVar(&searchSince, "since", "", "only commits after this date")
+	flags.StringVar(&searchUntil, "until", "", "only commits before this date")
+	flags.BoolVar(&searchAll, "all", false, "search all branches, not just the current one")
+	flags.StringVar(&preselectedItem, "select", "", "commit among the results to show without prompting")
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	output, err := gitCommand(searchLogArgs(args[0])...).Output()
+	if err != nil {
+		return fmt.Errorf("failed to search history: %w", err)
+	}
+
+	commits := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(commits) == 0 || commits[0] == "" {
+		ui.Printf("No commits found changing '%s'\n", args[0])
+		return nil
+	}
+	ui.Stepf("🔍 Found %d commit(s) changing '%s'", len(commits), args[0])
+
+	commit, err := selectSearchResult(commits, args[0])
+	if err != nil {
+		return err
+	}
+	if commit == "" {
+		return nil
+	}
+
+	showArgs := []string{"show", commit}
+	if len(searchPaths) > 0 {
+		showArgs = append(showArgs, "--")
+		showArgs = append(showArgs, searchPaths...)
+	}
+	showCmd := gitCommand(showArgs...)
+	showCmd.Stdin = os.Stdin
+	showCmd.Stdout = os.Stdout
+	showCmd.Stderr = os.Stderr
+	return showCmd.Run()
+}
+
+// searchLogArgs builds the git log arguments for a pickaxe search, printing
+// one commit per line. Options must come before the "--" of the paths.
+func searchLogArgs(query string) []string {
+	args := []string{"log", "--format=%h %ad %an: %s", "--date=short"}
+	if searchRegex {
+		args = append(args, "-G"+query)
+	} else {
+		args = append(args, "-S"+query)
+	}
+	if searchAll {
+		args = append(args, "--all")
+	}
+	if searchAuthor != "" {
+		args = append(args, "--author", searchAuthor)
+	}
+	if searchSince != "" {
+		args = append(args, "--since", searchSince)
+	}
+	if searchUntil != "" {
+		args = append(args, "--until", searchUntil)
+	}
+	if len(searchPaths) > 0 {
+		args = append(args, "--")
+		args = append(args, searchPaths...)
+	}
+	return args
+}
+
+func selectSearchResult(commits []string, query string) (string, error) {
+	if preselectedItem != "" {
+		commit, err := preselect(commits, "commit changing '"+query+"'", func(commit string) bool {
+			return sameCommit(strings.Fields(commit)[0], preselectedItem)
+		})
+		if err != nil {
+			return "", err
+		}
+		return strings.Fields(commit)[0], nil
+	}
+	if !noFzf && isInteractive() {
+		if _, err := exec.LookPath("fzf"); err == nil {
+			return selectSearchResultWithFzf(commits, query)
+		}
+	}
+	return selectSearchResultWithList(commits)
+}
+
+func selectSearchResultWithFzf(commits []string, query string) (string, error) {
+	// Only show the parts of the diff that match the search
+	pickaxe := "-S"
+	if searchRegex {
+		pickaxe = "-G"
+	}
+	previewCmd := fmt.Sprintf("git show --color=always %s {1}", shellQuote(pickaxe+query))
+	if len(searchPaths) > 0 {
+		previewCmd += " --"
+		for _, path := range searchPaths {
+			previewCmd += " " + shellQuote(path)
+		}
+	}
+
+	fzfCmd := exec.Command("fzf",
+		"--ansi",
+		"--height", "80%",
+		"--reverse",
+		"--preview", previewCmd,
+		"--preview-window", "right:60%")
+
+	fzfCmd.Stdin = strings.NewReader(strings.Join(commits, "\n"))
+	fzfCmd.Stderr = os.Stderr
+
+	output, err := fzfCmd.Output()
+	if err != nil {
+		return "", nil // User cancelled
+	}
+
+	selected := strings.TrimSpace(string(output))
+	if selected == "" {
+		return "", nil
+	}
+	return strings.Fields(selected)[0], nil
+}
+
+func selectSearchResultWithList(commits []string) (string, error) {
+	ui.Println()
+	for i, commit := range commits {
+		ui.Printf("%2d: %s\n", i+1, commit)
+	}
+
+	input := readInput("\nSelect commit number to show (or press Enter to cancel): ")
+
+	if input == "" {
+		return "", nil
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(input, "%d", &index); err != nil || index < 1 || index > len(commits) {
+		return "", fmt.Errorf("invalid selection")
+	}
+	return strings.Fields(commits[index-1])[0], nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchLogArgs(t *testing.T) {
+	defer func() {
+		searchRegex, searchAll, searchAuthor, searchSince, searchPaths = false, false, "", "", nil
+	}()
+
+	format := []string{"log", "--format=%h %ad %an: %s", "--date=short"}
+	tests := []struct {
+		name   string
+		setup  func()
+		expect []string
+	}{
+		{
+			name:   "pickaxe",
+			setup:  func() {},
+			expect: append(format, "-SgetRepoSize"),
+		},
+		{
+			name:   "regex on all branches",
+			setup:  func() { searchRegex, searchAll = true, true },
+			expect: append(format, "-GgetRepoSize", "--all"),
+		},
+		{
+			name:   "paths come last",
+			setup:  func() { searchAuthor, searchSince, searchPaths = "alice", "2 weeks ago", []string{"cmd/", "my dir"} },
+			expect: append(format, "-SgetRepoSize", "--author", "alice", "--since", "2 weeks ago", "--", "cmd/", "my dir"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searchRegex, searchAll, searchAuthor, searchSince, searchPaths = false, false, "", "", nil
+			tt.setup()
+			assert.Equal(t, tt.expect, searchLogArgs("getRepoSize"))
+		})
+	}
+}
+
+func TestSearchWithPath(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Add size", "cmd/size.go", "func getRepoSize() {}\n")
+	r.Commit("Add docs", "README.md", "getRepoSize\n")
+
+	stdout, _, err := execute(t, "search", "getRepoSize", "--path", "cmd/")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, " 1: "+r.Git("rev-parse", "--short", "HEAD~1"))
+	assert.Contains(t, stdout, "Add size")
+	assert.NotContains(t, stdout, "Add docs")
+	assert.NotContains(t, stdout, "Author:")
+}
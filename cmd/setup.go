@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/ai"
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// setupAttempts is how many times setup asks again for an entry that
+// doesn't check out
+const setupAttempts = 3
+
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Configure githelper step by step",
+	Long: `Walk through the settings githelper needs, checking each one as you go.
+
+Setup asks for:
+1. The GitHub host and a login, with the browser device flow or a token
+2. Whether to clone with SSH or HTTPS, after testing your SSH key
+3. Your default organization, which must exist on the host
+4. An OpenAI API key for --ai, which is tried against the API
+5. The branch name git uses for new repositories
+It also tells you whether fzf is installed for the interactive pickers.
+
+Press Enter to keep the value shown in brackets. Settings are written to
+~/.githelper.yaml (or --config) at the end, so stopping halfway changes
+nothing but a login you completed. Run it again at any time to change them.
+
+Example:
+  githelper setup`,
+	Args: cobra.NoArgs,
+	RunE: runSetup,
+}
+
+func init() {
+	rootCmd.AddCommand(setupCmd)
+}
+
+// setupValue is a setting setup writes to the config file
+type setupValue struct {
+	key   string
+	value interface{}
+}
+
+func runSetup(cmd *cobra.Command, args []string) error {
+	if !isInteractive() {
+		return fmt.Errorf("setup needs a terminal. In scripts, use 'githelper config set' and 'githelper auth login --with-token'")
+	}
+	file, err := configFilePath()
+	if err != nil {
+		return err
+	}
+
+	ui.Step("👋 Let's set up githelper. Press Enter to keep the value in [brackets].")
+	ui.Printf("   Settings will be saved to %s\n", file)
+	var values []setupValue
+
+	// 1. Host and login
+	ui.Println("\n1️⃣  GitHub")
+	hostName := defaultHostName()
+	if answer := readInput(fmt.Sprintf("GitHub host [%s]: ", hostName)); answer != "" {
+		hostName = strings.ToLower(answer)
+	}
+	if hostName != defaultHostName() {
+		values = append(values, setupValue{"default_host", hostName})
+	}
+	host, err := resolveHost(hostName)
+	if err != nil {
+		return err
+	}
+	host, err = setupLogin(host)
+	if err != nil {
+		return err
+	}
+	client, err := github.NewHostClient(host)
+	if err != nil {
+		return err
+	}
+
+	// 2. SSH or HTTPS
+	ui.Println("\n2️⃣  Clone URLs")
+	useSSH := viper.GetBool("use_ssh")
+	sshWorks := checkSSHAuth(host.Name)
+	if sshWorks {
+		ui.Successf("Your SSH key is accepted by %s", host.Name)
+	} else {
+		ui.Infof("No SSH key accepted by %s; HTTPS URLs will work without one", host.Name)
+	}
+	if !viper.IsSet("use_ssh") {
+		useSSH = sshWorks
+	}
+	current := "https"
+	if useSSH {
+		current = "ssh"
+	}
+	for {
+		answer := strings.ToLower(readInput(fmt.Sprintf("Clone with ssh or https? [%s]: ", current)))
+		if answer == "" {
+			answer = current
+		}
+		if answer == "ssh" || answer == "https" {
+			useSSH = answer == "ssh"
+			break
+		}
+		ui.Error("Answer ssh or https")
+	}
+	if useSSH && !sshWorks {
+		ui.Warnf("Add your SSH key to %s before cloning: https://%s/settings/keys", host.Name, host.Name)
+	}
+	values = append(values, setupValue{"use_ssh", useSSH})
+
+	// 3. Default organization
+	ui.Println("\n3️⃣  Default organization")
+	if org, ok := setupDefaultOrg(client); ok {
+		values = append(values, setupValue{"default_org", org})
+	}
+
+	// 4. AI provider
+	ui.Println("\n4️⃣  AI (commit messages, PR descriptions, explain)")
+	if key, ok := setupAIKey(); ok {
+		values = append(values, setupValue{"openai_api_key", key})
+	}
+
+	// 5. Branch name for new repositories
+	ui.Println("\n5️⃣  Default branch")
+	setupInitBranch()
+
+	// fzf only needs to be installed
+	ui.Step("\n🔎 Interactive pickers")
+	if path, err := exec.LookPath("fzf"); err == nil {
+		ui.Successf("fzf found at %s", path)
+	} else {
+		ui.Info("fzf is not installed; pickers fall back to numbered lists")
+		ui.Println("   Install it for fuzzy search: https://github.com/junegunn/fzf#installation")
+	}
+
+	for _, v := range values {
+		if err := writeConfigValue(file, splitConfigKey(v.key), v.value); err != nil {
+			return err
+		}
+	}
+	ui.Successf("\nSaved %d setting(s) to %s", len(values), file)
+	ui.Println("   Check everything with 'githelper version --env'")
+	return nil
+}
+
+// setupLogin makes sure there's a working token for host, logging in when
+// there isn't, and returns the host with it
+func setupLogin(host github.Host) (github.Host, error) {
+	ctx := commandCtx
+	if host.Token != "" {
+		client, err := github.NewHostClient(host)
+		if err != nil {
+			return host, err
+		}
+		if login, _, err := client.CurrentUser(ctx); err == nil {
+			ui.Successf("Logged in to %s as %s", host.Name, login)
+			if answer := readInput("Log in again with another account? [y/N]: "); answer != "y" && answer != "Y" {
+				return host, nil
+			}
+		} else {
+			ui.Warnf("The token for %s doesn't work: %v", host.Name, err)
+		}
+	}
+
+	// The device flow needs an OAuth app, which Enterprise hosts may not have
+	_, clientErr := oauthClientID(host.Name)
+	prompt := "Log in with the browser (b), paste a token (t) or skip (s)? [b]: "
+	if clientErr != nil {
+		prompt = "Paste a token (t) or skip (s)? [t]: "
+	}
+	for attempt := 0; attempt < setupAttempts; attempt++ {
+		method := strings.ToLower(readInput(prompt))
+		if method == "" {
+			method = "b"
+			if clientErr != nil {
+				method = "t"
+			}
+		}
+
+		var token string
+		var err error
+		switch method {
+		case "s":
+			ui.Info("Skipped; log in later with 'githelper auth login'")
+			return host, nil
+		case "b":
+			token, err = deviceFlowLogin(ctx, host.Name)
+		case "t":
+			ui.Printf("   Create one at https://%s/settings/tokens with the repo scope\n", host.Name)
+			if token = readSecret("Token: "); token == "" {
+				err = fmt.Errorf("no token entered")
+			}
+		default:
+			ui.Error("Answer b, t or s")
+			continue
+		}
+		if err == nil {
+			var login string
+			if login, err = setupStoreToken(ctx, host, token); err == nil {
+				host.Token = token
+				ui.Successf("Logged in as %s", login)
+				return host, nil
+			}
+		}
+		if ctx.Err() != nil {
+			return host, ctx.Err()
+		}
+		ui.Errorf("%v", err)
+	}
+	ui.Info("Continuing without a login; run 'githelper auth login' later")
+	return host, nil
+}
+
+// setupStoreToken verifies and stores a token, reporting where it went
+func setupStoreToken(ctx context.Context, host github.Host, token string) (string, error) {
+	login, source, err := storeVerifiedToken(ctx, host, token)
+	if err != nil {
+		return "", err
+	}
+	ui.Stepf("🔒 Token saved to %s", source)
+	return login, nil
+}
+
+// checkSSHAuth reports whether the SSH key of the user is accepted by host.
+// GitHub answers "successfully authenticated" and closes the connection.
+func checkSSHAuth(host string) bool {
+	ctx, cancel := context.WithTimeout(commandCtx, 10*time.Second)
+	defer cancel()
+	output, _ := exec.CommandContext(ctx, "ssh", "-T", "-o", "BatchMode=yes", "-o", "ConnectTimeout=5",
+		"-o", "StrictHostKeyChecking=accept-new", "git@"+host).CombinedOutput()
+	return strings.Contains(string(output), "successfully authenticated")
+}
+
+// setupDefaultOrg asks for the default organization and checks it exists.
+// It returns false when the setting stays as it is.
+func setupDefaultOrg(client *github.Client) (string, bool) {
+	current := viper.GetString("default_org")
+	prompt := "Default organization or user (Enter to skip): "
+	if current != "" {
+		prompt = fmt.Sprintf("Default organization or user [%s]: ", current)
+	}
+	for attempt := 0; attempt < setupAttempts; attempt++ {
+		org := readInput(prompt)
+		if org == "" || org == current {
+			return "", false
+		}
+		kind, err := client.AccountType(commandCtx, org)
+		switch {
+		case err == nil:
+			ui.Successf("Found %s %s", strings.ToLower(kind), org)
+			return org, true
+		case errors.Is(err, github.ErrAccountNotFound):
+			ui.Errorf("No user or organization named %s", org)
+		default:
+			ui.Warnf("Couldn't check %s: %v", org, err)
+			if answer := readInput("Use it anyway? [y/N]: "); answer == "y" || answer == "Y" {
+				return org, true
+			}
+		}
+	}
+	return "", false
+}
+
+// setupAIKey asks for an OpenAI API key and tries it. It returns false when
+// the setting stays as it is.
+func setupAIKey() (string, bool) {
+	current := viper.GetString("openai_api_key")
+	prompt := "OpenAI API key (Enter to skip, AI features then work offline): "
+	if current != "" {
+		prompt = "OpenAI API key (Enter to keep the current one): "
+	}
+	for attempt := 0; attempt < setupAttempts; attempt++ {
+		key := readSecret(prompt)
+		if key == "" {
+			return "", false
+		}
+		err := ai.CheckKey(commandCtx, key)
+		switch {
+		case err == nil:
+			ui.Success("The key works")
+			return key, true
+		case errors.Is(err, ai.ErrInvalidKey):
+			ui.Error("OpenAI rejected the key")
+		default:
+			ui.Warnf("%v", err)
+			if answer := readInput("Save it anyway? [y/N]: "); answer == "y" || answer == "Y" {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// setupInitBranch sets the branch name git uses for new repositories. It's
+// git's own setting, so every tool creating repositories agrees on it.
+func setupInitBranch() {
+	current := "master"
+	if output, err := gitCommand("config", "--global", "init.defaultBranch").Output(); err == nil {
+		current = strings.TrimSpace(string(output))
+	}
+	for attempt := 0; attempt < setupAttempts; attempt++ {
+		name := readInput(fmt.Sprintf("Branch name for new repositories [%s]: ", current))
+		if name == "" || name == current {
+			return
+		}
+		if err := gitCommand("check-ref-format", "--branch", name).Run(); err != nil {
+			ui.Errorf("'%s' is not a valid branch name", name)
+			continue
+		}
+		if output, err := gitCommand("config", "--global", "init.defaultBranch", name).CombinedOutput(); err != nil {
+			ui.Warnf("Failed to set init.defaultBranch: %s", strings.TrimSpace(string(output)))
+			return
+		}
+		ui.Successf("New repositories start on %s (git config --global init.defaultBranch)", name)
+		return
+	}
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/runner"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// signatureGroups orders the signature states from worst to best in the
+// 'verify --keys' report
+var signatureGroups = []struct {
+	Icon     string
+	Title    string
+	Statuses string
+}{
+	{"❌", "Unsigned", "N"},
+	{"❌", "Bad signature", "B"},
+	{"❓", "Unknown key", "E"},
+	{"⌛", "Expired or revoked key", "XYR"},
+	{"✅", "Good signature", "GU"},
+}
+
+var (
+	resignSince string
+	resignKey   string
+)
+
+var resignCmd = &cobra.Command{
+	Use:   "resign",
+	Short: "Sign a range of commits with your signing key",
+	Long: `Rewrite the commits after --since, signing each one with the configured key
+(user.signingkey, with gpg.format for SSH or X.509 keys) or --key. Commit
+contents, messages and authors are kept; merges are recreated.
+
+--since defaults to the upstream of the current branch, so only unpushed
+commits are signed. Commits that are already pushed need a confirmation and a
+force push afterwards. The state is recorded first, so 'githelper rollback'
+can undo it.
+
+Example:
+  githelper resign                         # Sign the unpushed commits
+  githelper resign --since main            # Sign the commits of this branch
+  githelper resign --since HEAD~3 --key ABCD1234
+  githelper resign --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runResign,
+}
+
+func init() {
+	rootCmd.AddCommand(resignCmd)
+	resignCmd.Flags().StringVar(&resignSince, "since", "@{upstream}", "sign the commits after this ref")
+	resignCmd.Flags().StringVar(&resignKey, "key", "", "key to sign with (default: user.signingkey)")
+	resignCmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the commits without signing them")
+}
+
+// printSignatureReport lists the commits with an invalid signature grouped by
+// status, counts the valid ones, and lists the keys that signed them
+func printSignatureReport(commits []CommitSignature, revRange string) {
+	ui.Stepf("🔍 Signatures of %d commit(s) in %s:", len(commits), revRange)
+
+	for _, group := range signatureGroups {
+		var matching []CommitSignature
+		for _, c := range commits {
+			if strings.Contains(group.Statuses, c.Status) {
+				matching = append(matching, c)
+			}
+		}
+		if len(matching) == 0 {
+			continue
+		}
+		ui.Printf("\n%s %s: %d commit(s)\n", group.Icon, group.Title, len(matching))
+		if group.Statuses == "GU" {
+			continue
+		}
+		for _, c := range matching {
+			key := ""
+			if c.Key != "" {
+				key = " [" + c.Key + "]"
+			}
+			ui.Printf("  %s %-20s %s%s\n", c.Hash[:8], truncate(c.Author, 20), c.Subject, key)
+		}
+	}
+
+	if requireSignoff {
+		var missing []CommitSignature
+		for _, c := range commits {
+			if !c.SignedOff {
+				missing = append(missing, c)
+			}
+		}
+		if len(missing) > 0 {
+			ui.Printf("\n❌ Missing Signed-off-by: %d commit(s)\n", len(missing))
+			for _, c := range missing {
+				ui.Printf("  %s %-20s %s\n", c.Hash[:8], truncate(c.Author, 20), c.Subject)
+			}
+		}
+	}
+
+	if keys := signingKeys(commits); len(keys) > 0 {
+		ui.Step("\n🔑 Keys:")
+		for _, key := range keys {
+			signer := key.Signer
+			if signer == "" {
+				signer = "unknown"
+			}
+			ui.Printf("  %s (%s): %d commit(s)\n", key.Key, signer, key.Commits)
+		}
+	}
+}
+
+// SigningKey is a key that signed commits of a range
+type SigningKey struct {
+	Key     string
+	Signer  string
+	Commits int
+}
+
+// signingKeys returns the keys that signed the commits, the most used first
+func signingKeys(commits []CommitSignature) []SigningKey {
+	byKey := map[string]*SigningKey{}
+	for _, c := range commits {
+		if c.Key == "" {
+			continue
+		}
+		key, ok := byKey[c.Key]
+		if !ok {
+			key = &SigningKey{Key: c.Key}
+			byKey[c.Key] = key
+		}
+		if key.Signer == "" {
+			key.Signer = c.Signer
+		}
+		key.Commits++
+	}
+
+	var keys []SigningKey
+	for _, key := range byKey {
+		keys = append(keys, *key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Commits != keys[j].Commits {
+			return keys[i].Commits > keys[j].Commits
+		}
+		return keys[i].Key < keys[j].Key
+	})
+	return keys
+}
+
+func runResign(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return err
+	}
+	if resignKey == "" && gitConfigValue("user.signingkey") == "" && gitConfigValue("gpg.format") == "ssh" {
+		return fmt.Errorf("no signing key configured: set user.signingkey or pass --key")
+	}
+
+	base, err := gitCommand("rev-parse", "--verify", "-q", resignSince+"^{commit}").Output()
+	if err != nil {
+		if resignSince == "@{upstream}" {
+			return fmt.Errorf("%s has no upstream, pass --since", branch)
+		}
+		return fmt.Errorf("commit '%s' does not exist", resignSince)
+	}
+	since := strings.TrimSpace(string(base))
+	revRange := since + "..HEAD"
+	commits, err := getCommitSignatures(revRange)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		ui.Successf("No commits after %s", resignSince)
+		return nil
+	}
+
+	ui.Stepf("✍️  %d commit(s) will be signed:", len(commits))
+	var published []string
+	for _, c := range commits {
+		ui.Printf("  %s %-24s %s\n", c.Hash[:8], signatureStatusText[c.Status], c.Subject)
+		if isPublished(c.Hash) {
+			published = append(published, shortSHA(c.Hash))
+		}
+	}
+	if dryRun {
+		ui.Step("\n🔍 Dry run - no commits were changed")
+		return nil
+	}
+
+	if hasChanges, err := hasUncommittedChanges(); err != nil {
+		return err
+	} else if hasChanges {
+		return fmt.Errorf("you have uncommitted changes. Please commit or stash them first")
+	}
+	if len(published) > 0 {
+		if err := guardOperation("resign", branch); err != nil {
+			return err
+		}
+		ui.Warnf("\n%s already pushed; you will need to force push afterwards", strings.Join(published, ", "))
+		if !confirmAction() {
+			ui.Error("Operation cancelled")
+			return nil
+		}
+	}
+
+	if err := recordOperation("resign", false); err != nil {
+		return err
+	}
+	sign := "--gpg-sign"
+	if resignKey != "" {
+		sign += "=" + resignKey
+	}
+	// --force-rebase rewrites every commit, even when none would move
+	var output bytes.Buffer
+	rebaseCmd := runner.Git("rebase", "--force-rebase", "--rebase-merges", sign, since).WithOutput(&output, &output)
+	if err := commandRunner.Run(commandCtx, rebaseCmd); err != nil {
+		commandRunner.Run(commandCtx, runner.Git("rebase", "--abort"))
+		return fmt.Errorf("failed to sign commits: %s", strings.TrimSpace(output.String()))
+	}
+
+	signed, err := getCommitSignatures(revRange)
+	if err != nil {
+		return err
+	}
+	valid := 0
+	for _, c := range signed {
+		if isValidSignature(c.Status) {
+			valid++
+		}
+	}
+	ui.Printf("✅ Signed %d commit(s)", len(signed))
+	if valid < len(signed) {
+		ui.Printf(", %d of them can't be verified: check 'githelper verify %s --keys'", len(signed)-valid, revRange)
+	}
+	ui.Println()
+	if len(published) > 0 {
+		ui.Warn("\nTo update the remote:")
+		ui.Println("githelper push --force")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningKeys(t *testing.T) {
+	commits := []CommitSignature{
+		{Status: "G", Key: "AAA", Signer: "Alice"},
+		{Status: "N"},
+		{Status: "E", Key: "BBB"},
+		{Status: "G", Key: "AAA", Signer: "Alice"},
+	}
+	assert.Equal(t, []SigningKey{
+		{Key: "AAA", Signer: "Alice", Commits: 2},
+		{Key: "BBB", Commits: 1},
+	}, signingKeys(commits))
+}
+
+func TestResign(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	keyDir := t.TempDir()
+	key := filepath.Join(keyDir, "key")
+	require.NoError(t, exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).Run())
+	publicKey, err := os.ReadFile(key + ".pub")
+	require.NoError(t, err)
+	allowed := filepath.Join(keyDir, "allowed_signers")
+	require.NoError(t, os.WriteFile(allowed, append([]byte("test@example.com "), publicKey...), 0644))
+	runGit(t, tmpDir, "config", "user.name", "Test")
+	runGit(t, tmpDir, "config", "user.email", "test@example.com")
+	runGit(t, tmpDir, "config", "gpg.format", "ssh")
+	runGit(t, tmpDir, "config", "gpg.ssh.allowedSignersFile", allowed)
+
+	runGit(t, tmpDir, "commit", "-m", "base")
+	runGit(t, tmpDir, "tag", "base")
+	for _, name := range []string{"one", "two"} {
+		require.NoError(t, os.WriteFile(name+".txt", []byte(name), 0644))
+		runGit(t, tmpDir, "add", ".")
+		runGit(t, tmpDir, "commit", "-m", name)
+	}
+
+	resignSince = "base"
+	resignKey = key + ".pub"
+	defer func() { resignSince, resignKey = "@{upstream}", "" }()
+	require.NoError(t, runResign(resignCmd, nil))
+
+	commits, err := getCommitSignatures("base..HEAD")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	for _, c := range commits {
+		assert.Equal(t, "G", c.Status, c.Subject)
+	}
+	assert.Equal(t, "two", commits[0].Subject)
+}
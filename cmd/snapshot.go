@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/snapshot"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var snapshotMessage string
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save and restore checkpoints of your work",
+	Long: `Save named checkpoints of the working tree while experimenting, and return
+to any of them later.
+
+A snapshot records the checked out branch and commit, staged and unstaged
+changes and untracked files (ignored files are left out). Creating one
+doesn't change anything, so you can keep working right away. Snapshots are
+kept under refs/githelper/snapshots/ and in the git directory; they are never
+pushed.
+
+Example:
+  githelper snapshot create before-refactor -m "tests passing"
+  githelper snapshot list
+  githelper snapshot restore before-refactor
+  githelper snapshot delete before-refactor`,
+}
+
+var snapshotCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Save the current state as a snapshot",
+	Long: `Save the checked out commit, uncommitted changes and untracked files as a
+snapshot. The name defaults to the current date and time.
+
+Example:
+  githelper snapshot create
+  githelper snapshot create before-refactor -m "tests passing"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSnapshotCreate,
+}
+
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the snapshots",
+	Args:  cobra.NoArgs,
+	RunE:  runSnapshotList,
+}
+
+var snapshotRestoreCmd = &cobra.Command{
+	Use:   "restore <name>",
+	Short: "Return to a snapshot",
+	Long: `Return the working tree to a snapshot: its branch is reset to the commit it
+was on and checked out, and the uncommitted changes and untracked files are
+brought back. Current untracked files are removed.
+
+The current state is saved as a snapshot first, and the branches are
+recorded for 'githelper rollback', so restoring never loses work.
+
+Example:
+  githelper snapshot restore before-refactor`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSnapshotRestore,
+}
+
+var snapshotDeleteCmd = &cobra.Command{
+	Use:   "delete <name>...",
+	Short: "Delete snapshots",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runSnapshotDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotRestoreCmd)
+	snapshotCmd.AddCommand(snapshotDeleteCmd)
+
+	snapshotCreateCmd.Flags().StringVarP(&snapshotMessage, "message", "m", "", "description of the snapshot")
+	snapshotRestoreCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the snapshot without restoring it")
+}
+
+func openSnapshots() (*snapshot.Store, error) {
+	if err := checkGitRepo(); err != nil {
+		return nil, err
+	}
+	return snapshot.Open(commandCtx)
+}
+
+func runSnapshotCreate(cmd *cobra.Command, args []string) error {
+	store, err := openSnapshots()
+	if err != nil {
+		return err
+	}
+	name := time.Now().Format("20060102-150405")
+	if len(args) > 0 {
+		name = args[0]
+	}
+
+	snap, err := store.Create(commandCtx, name, snapshotMessage)
+	if err != nil {
+		return err
+	}
+	ui.Stepf("📸 Saved snapshot '%s'", snap.Name)
+	printSnapshot(snap)
+	return nil
+}
+
+func runSnapshotList(cmd *cobra.Command, args []string) error {
+	store, err := openSnapshots()
+	if err != nil {
+		return err
+	}
+	snaps, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(snaps) == 0 {
+		ui.Println("No snapshots. Create one with 'githelper snapshot create'")
+		return nil
+	}
+
+	width := 0
+	for _, snap := range snaps {
+		width = max(width, len(snap.Name))
+	}
+	for i := len(snaps) - 1; i >= 0; i-- {
+		snap := snaps[i]
+		ui.Printf("%-*s  %-8s  %s%s", width, snap.Name, timeAgo(snap.Time), snapshotWhere(&snap), snapshotChanges(&snap))
+		if snap.Message != "" {
+			ui.Printf("  %s", snap.Message)
+		}
+		ui.Println()
+	}
+	return nil
+}
+
+func runSnapshotRestore(cmd *cobra.Command, args []string) error {
+	store, err := openSnapshots()
+	if err != nil {
+		return err
+	}
+	snap, err := store.Get(commandCtx, args[0])
+	if err != nil {
+		return err
+	}
+
+	ui.Stepf("⏪ Restoring snapshot '%s' from %s", snap.Name, snap.Time.Format("2006-01-02 15:04:05"))
+	printSnapshot(snap)
+	if dryRun {
+		ui.Step("\n🔍 Dry run - nothing was changed")
+		return nil
+	}
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	current, err := store.Create(commandCtx, "before-restore-"+time.Now().Format("20060102-150405"), "before restoring "+snap.Name)
+	if err != nil {
+		return fmt.Errorf("failed to save the current state: %w", err)
+	}
+	ui.Stepf("📸 Saved the current state as snapshot '%s'", current.Name)
+	if err := recordOperation("snapshot restore", true); err != nil {
+		return err
+	}
+
+	if err := store.Restore(commandCtx, snap); err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	ui.Successf("Restored snapshot '%s'", snap.Name)
+	return nil
+}
+
+func runSnapshotDelete(cmd *cobra.Command, args []string) error {
+	store, err := openSnapshots()
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		if err := store.Delete(commandCtx, name); err != nil {
+			if errors.Is(err, snapshot.ErrNotFound) {
+				ui.Warnf("%v", err)
+				continue
+			}
+			return err
+		}
+		ui.Stepf("🗑️  Deleted snapshot '%s'", name)
+	}
+	return nil
+}
+
+func printSnapshot(snap *snapshot.Snapshot) {
+	ui.Printf("  %s%s\n", snapshotWhere(snap), snapshotChanges(snap))
+	if snap.Message != "" {
+		ui.Printf("  %s\n", snap.Message)
+	}
+}
+
+// snapshotWhere describes the commit a snapshot was on, e.g. "main@1a2b3c4"
+func snapshotWhere(snap *snapshot.Snapshot) string {
+	if snap.Branch == "" {
+		return "detached@" + shortSHA(snap.Commit)
+	}
+	return snap.Branch + "@" + shortSHA(snap.Commit)
+}
+
+// snapshotChanges describes the uncommitted work saved in a snapshot
+func snapshotChanges(snap *snapshot.Snapshot) string {
+	changes := ""
+	if snap.WorkTree != "" {
+		changes += " +changes"
+	}
+	if snap.Untracked > 0 {
+		changes += fmt.Sprintf(" +%d untracked", snap.Untracked)
+	}
+	return changes
+}
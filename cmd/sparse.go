@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// sparseProfileKey is the worktree config key naming the profile a
+// worktree's sparse checkout came from
+const sparseProfileKey = "githelper.sparseProfile"
+
+var sparseCmd = &cobra.Command{
+	Use:   "sparse",
+	Short: "Check out parts of a monorepo with named profiles",
+	Long: `Check out only the directories you work on, using named sparse-checkout
+profiles from ~/.githelper.yaml or the repository's .githelper.yaml:
+
+  sparse_profiles:
+    backend: [services/api, libs/go]
+    frontend: [web, libs/ts]
+
+Profiles use git's cone mode: each directory is checked out with everything
+below it, along with the files at the top of the repository. They apply to
+the current worktree only, so each worktree can use its own.
+
+Example:
+  githelper sparse list                      # Show profiles, marking the active one
+  githelper sparse use backend               # Check out services/api and libs/go
+  githelper sparse status                    # Show the active cone
+  githelper sparse disable                   # Check out everything again
+  githelper worktree create api-fix --sparse backend`,
+}
+
+var sparseListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the sparse-checkout profiles",
+	Args:  cobra.NoArgs,
+	RunE:  runSparseList,
+}
+
+var sparseUseCmd = &cobra.Command{
+	Use:   "use <profile>",
+	Short: "Check out the directories of a profile in this worktree",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSparseUse,
+}
+
+var sparseStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the active profile and cone of this worktree",
+	Args:  cobra.NoArgs,
+	RunE:  runSparseStatus,
+}
+
+var sparseDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Check out the whole repository again",
+	Args:  cobra.NoArgs,
+	RunE:  runSparseDisable,
+}
+
+func init() {
+	rootCmd.AddCommand(sparseCmd)
+	sparseCmd.AddCommand(sparseListCmd, sparseUseCmd, sparseStatusCmd, sparseDisableCmd)
+}
+
+// configuredSparseProfiles returns the sparse_profiles section of the config
+func configuredSparseProfiles() (map[string][]string, error) {
+	var profiles map[string][]string
+	if err := viper.UnmarshalKey("sparse_profiles", &profiles); err != nil {
+		return nil, fmt.Errorf("invalid sparse_profiles configuration: %w", err)
+	}
+	return profiles, nil
+}
+
+// sparseProfileDirs returns the directories of a profile, cleaned up for
+// cone mode
+func sparseProfileDirs(name string) ([]string, error) {
+	profiles, err := configuredSparseProfiles()
+	if err != nil {
+		return nil, err
+	}
+	dirs, ok := profiles[name]
+	if !ok {
+		if len(profiles) == 0 {
+			return nil, fmt.Errorf("sparse profile '%s' not found. Add it under 'sparse_profiles' in .githelper.yaml", name)
+		}
+		return nil, fmt.Errorf("sparse profile '%s' not found. Use one of: %s", name, strings.Join(sortedKeys(profiles), ", "))
+	}
+
+	var cleaned []string
+	for _, dir := range dirs {
+		dir = path.Clean(strings.Trim(strings.TrimSpace(dir), "/"))
+		if dir == "." || dir == ".." || strings.HasPrefix(dir, "../") || strings.HasPrefix(dir, "-") {
+			return nil, fmt.Errorf("sparse profile '%s': '%s' is not a directory of the repository", name, dir)
+		}
+		cleaned = append(cleaned, dir)
+	}
+	if len(cleaned) == 0 {
+		return nil, fmt.Errorf("sparse profile '%s' has no directories", name)
+	}
+	sort.Strings(cleaned)
+	return cleaned, nil
+}
+
+// activeSparseProfile returns the profile the current worktree uses, or ""
+func activeSparseProfile() string {
+	output, err := gitCommand("config", "--get", sparseProfileKey).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// applySparseProfile narrows the current worktree to the directories of a
+// profile and remembers the profile in the worktree's config
+func applySparseProfile(name string) ([]string, error) {
+	dirs, err := sparseProfileDirs(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Directories missing from HEAD are usually typos; git accepts them
+	for _, dir := range dirs {
+		if gitCommand("rev-parse", "--verify", "-q", "HEAD:"+dir).Run() != nil {
+			ui.Warnf("'%s' doesn't exist in HEAD", dir)
+		}
+	}
+
+	if err := gitRun(append([]string{"sparse-checkout", "set", "--cone"}, dirs...)...); err != nil {
+		return nil, fmt.Errorf("failed to set the sparse checkout: %w", err)
+	}
+	// sparse-checkout turned on per-worktree config
+	if err := gitRun("config", "--worktree", sparseProfileKey, name); err != nil {
+		return nil, fmt.Errorf("failed to record the sparse profile: %w", err)
+	}
+	return dirs, nil
+}
+
+func runSparseList(cmd *cobra.Command, args []string) error {
+	profiles, err := configuredSparseProfiles()
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		ui.Println("No sparse profiles configured. Add them under 'sparse_profiles' in .githelper.yaml")
+		return nil
+	}
+
+	active := ""
+	if checkGitRepo() == nil {
+		active = activeSparseProfile()
+	}
+	for _, name := range sortedKeys(profiles) {
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		ui.Printf("%s %-15s %s\n", marker, name, strings.Join(profiles[name], ", "))
+	}
+	return nil
+}
+
+func runSparseUse(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	name := args[0]
+
+	ui.Stepf("🌿 Applying sparse profile '%s'...", name)
+	dirs, err := applySparseProfile(name)
+	if err != nil {
+		return err
+	}
+	ui.Successf("Checked out %s", strings.Join(dirs, ", "))
+	return nil
+}
+
+// sparseStatus describes the sparse checkout of the current worktree
+type sparseStatus struct {
+	Enabled bool
+	Cone    bool
+	Profile string
+	// Dirs are the directories of the cone, or the patterns outside cone mode
+	Dirs []string
+	// Present and Total count the tracked files checked out and in all
+	Present int
+	Total   int
+}
+
+func getSparseStatus() (*sparseStatus, error) {
+	status := &sparseStatus{Profile: activeSparseProfile()}
+	if output, err := gitCommand("config", "--bool", "--get", "core.sparseCheckout").Output(); err == nil {
+		status.Enabled = strings.TrimSpace(string(output)) == "true"
+	}
+	if output, err := gitCommand("config", "--bool", "--get", "core.sparseCheckoutCone").Output(); err == nil {
+		status.Cone = strings.TrimSpace(string(output)) == "true"
+	}
+
+	if status.Enabled {
+		output, err := gitCommand("sparse-checkout", "list").Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the sparse checkout: %w", err)
+		}
+		status.Dirs = splitLines(string(output))
+	}
+
+	// -t tags files outside the sparse checkout with S (skip-worktree)
+	output, err := gitCommand("ls-files", "-t").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	for _, line := range splitLines(string(output)) {
+		status.Total++
+		if !strings.HasPrefix(line, "S ") {
+			status.Present++
+		}
+	}
+	return status, nil
+}
+
+func splitLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func runSparseStatus(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	status, err := getSparseStatus()
+	if err != nil {
+		return err
+	}
+
+	if !status.Enabled {
+		ui.Println("Sparse checkout: off (the whole repository is checked out)")
+		return nil
+	}
+	profile := status.Profile
+	if profile == "" {
+		profile = "none (set with 'git sparse-checkout')"
+	}
+	mode := "cone"
+	if !status.Cone {
+		mode = "patterns"
+	}
+	ui.Printf("Sparse checkout: on (%s mode)\n", mode)
+	ui.Printf("Profile:         %s\n", profile)
+	ui.Printf("Files:           %d of %d checked out\n", status.Present, status.Total)
+	ui.Println("Directories:")
+	for _, dir := range status.Dirs {
+		ui.Printf("  %s\n", dir)
+	}
+
+	// The profile may have changed in the config since it was applied
+	if status.Profile != "" && status.Cone {
+		dirs, err := sparseProfileDirs(status.Profile)
+		if err != nil {
+			ui.Warn(err.Error())
+		} else if strings.Join(dirs, "\n") != strings.Join(status.Dirs, "\n") {
+			ui.Warnf("Profile '%s' changed in the config. Run 'githelper sparse use %s' to update the checkout", status.Profile, status.Profile)
+		}
+	}
+	return nil
+}
+
+func runSparseDisable(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	ui.Step("🌳 Checking out the whole repository...")
+	if err := gitRun("sparse-checkout", "disable"); err != nil {
+		return fmt.Errorf("failed to disable the sparse checkout: %w", err)
+	}
+	if activeSparseProfile() != "" {
+		gitRun("config", "--worktree", "--unset", sparseProfileKey)
+	}
+	ui.Success("Sparse checkout disabled")
+	return nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparseProfiles(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("sparse_profiles", map[string]interface{}{
+		"backend": []string{"services/api/", "libs/go"},
+		"broken":  []string{"../outside"},
+	})
+
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Monorepo", "services/api/main.go", "package main\n", "libs/go/lib.go", "package lib\n",
+		"web/index.html", "<html>\n", "README.md", "readme\n")
+
+	dirs, err := sparseProfileDirs("backend")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"libs/go", "services/api"}, dirs)
+	_, err = sparseProfileDirs("broken")
+	assert.ErrorContains(t, err, "not a directory of the repository")
+	_, err = sparseProfileDirs("frontend")
+	assert.ErrorContains(t, err, "Use one of: backend, broken")
+
+	_, _, err = execute(t, "sparse", "use", "backend")
+	require.NoError(t, err)
+	assert.FileExists(t, r.Path("services/api/main.go"))
+	assert.FileExists(t, r.Path("README.md"), "top-level files stay")
+	assert.NoFileExists(t, r.Path("web/index.html"))
+
+	status, err := getSparseStatus()
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.True(t, status.Cone)
+	assert.Equal(t, "backend", status.Profile)
+	assert.Equal(t, []string{"libs/go", "services/api"}, status.Dirs)
+	assert.Equal(t, 3, status.Present)
+	assert.Equal(t, 4, status.Total)
+
+	stdout, _, err := execute(t, "sparse", "list")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "* backend")
+
+	// A new worktree checks out its own profile, leaving this one alone
+	r.Git("branch", "dev")
+	viper.Set("sparse_profiles.web", []string{"web"})
+	_, _, err = execute(t, "worktree", "create", "dev", "--sparse", "web")
+	require.NoError(t, err)
+	worktree := filepath.Join(r.Dir, "..", "dev")
+	assert.FileExists(t, filepath.Join(worktree, "web", "index.html"))
+	assert.NoFileExists(t, filepath.Join(worktree, "services", "api", "main.go"))
+	require.NoError(t, os.Chdir(r.Dir))
+	assert.Equal(t, "backend", activeSparseProfile())
+
+	_, _, err = execute(t, "sparse", "disable")
+	require.NoError(t, err)
+	assert.FileExists(t, r.Path("web/index.html"))
+	assert.Empty(t, activeSparseProfile())
+}
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ai"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	splitBy string
+)
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split staged changes into several smaller commits",
+	Long: `Break one large set of staged changes into several focused commits.
+
+This command helps you keep history readable by:
+1. Analyzing the staged diff
+2. Grouping the changes by file, package (directory) or topic
+3. Proposing a commit message for each group (manual or AI-generated)
+4. Committing each group separately after your confirmation
+
+Groups you skip stay staged so you can commit them yourself.
+
+Example:
+  githelper split               # Group by topic (docs, tests, config, code)
+  githelper split --by dir      # One commit per directory
+  githelper split --by file     # One commit per file
+  githelper split --ai          # Generate messages with AI`,
+	RunE: runSplit,
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+	splitCmd.Flags().StringVar(&splitBy, "by", "topic", "group changes by: file, dir, topic")
+	splitCmd.Flags().BoolVarP(&useAI, "ai", "a", false, "use AI to generate commit messages")
+}
+
+// ChangeGroup is a set of staged files that will be committed together
+type ChangeGroup struct {
+	Name  string
+	Type  string
+	Scope string
+	Files []string
+}
+
+func runSplit(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	files, err := getStagedFiles()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no staged changes found. Use 'git add' to stage changes")
+	}
+
+	groups, err := groupChanges(files, splitBy)
+	if err != nil {
+		return err
+	}
+	if len(groups) < 2 {
+		ui.Info("All staged changes belong to a single group, nothing to split.")
+		ui.Println("Use 'githelper commit' to commit them.")
+		return nil
+	}
+
+	ui.Printf("🔍 Staged changes can be split into %d commits:\n\n", len(groups))
+	for i, group := range groups {
+		ui.Printf("%2d: %s (%d file(s))\n", i+1, group.Name, len(group.Files))
+		for _, file := range group.Files {
+			ui.Printf("      %s\n", file)
+		}
+	}
+	ui.Println()
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	// Save the exact staged state so partially staged files are preserved
+	patchFile, err := saveStagedPatch()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(patchFile)
+
+	if err := gitCommand("reset", "-q").Run(); err != nil {
+		return fmt.Errorf("failed to unstage changes: %w", err)
+	}
+
+	var skipped []ChangeGroup
+	committed := 0
+	for i, group := range groups {
+		ui.Stepf("\n📦 Group %d/%d: %s", i+1, len(groups), group.Name)
+		if err := stageGroup(patchFile, group.Files); err != nil {
+			restoreStagedPatch(patchFile, append(skipped, groups[i:]...))
+			return err
+		}
+
+		message, err := splitCommitMessage(group)
+		if err != nil {
+			restoreStagedPatch(patchFile, append(skipped, groups[i:]...))
+			return err
+		}
+
+		ui.Printf("Proposed message: %s\n", message)
+		response := readInput("Commit this group? [Y/n/e(dit)]: ")
+
+		switch strings.ToLower(response) {
+		case "n", "no":
+			if err := gitCommand("reset", "-q").Run(); err != nil {
+				restoreStagedPatch(patchFile, append(skipped, groups[i:]...))
+				return fmt.Errorf("failed to unstage group: %w", err)
+			}
+			skipped = append(skipped, group)
+			continue
+		case "e", "edit":
+			message, err = editMessage(message + "\n")
+			if err != nil {
+				restoreStagedPatch(patchFile, append(skipped, groups[i:]...))
+				return err
+			}
+		}
+
+		if err := makeCommit(message); err != nil {
+			restoreStagedPatch(patchFile, append(skipped, groups[i:]...))
+			return fmt.Errorf("failed to commit group '%s': %w", group.Name, err)
+		}
+		committed++
+	}
+
+	if len(skipped) > 0 {
+		restoreStagedPatch(patchFile, skipped)
+		ui.Infof("\n%d skipped group(s) left staged", len(skipped))
+	}
+
+	ui.Successf("Created %d commit(s)!", committed)
+	return nil
+}
+
+func getStagedFiles() ([]string, error) {
+	cmd := gitCommand("diff", "--cached", "--name-only")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged files: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// groupChanges groups staged files by file, directory or topic
+func groupChanges(files []string, by string) ([]ChangeGroup, error) {
+	index := make(map[string]*ChangeGroup)
+	var order []string
+
+	for _, file := range files {
+		var key, changeType, scope string
+		switch by {
+		case "file":
+			key = file
+			changeType = ai.ChangeType(file)
+			scope = scopeFromPath(file)
+		case "dir", "package":
+			key = path.Dir(file)
+			changeType = ai.ChangeType(file)
+			scope = scopeFromPath(file)
+		case "topic":
+			changeType = ai.ChangeType(file)
+			scope = ""
+			if changeType == "feat" {
+				scope = scopeFromPath(file)
+			}
+			key = changeType
+			if scope != "" {
+				key = fmt.Sprintf("%s(%s)", changeType, scope)
+			}
+		default:
+			return nil, fmt.Errorf("invalid grouping '%s'. Use file, dir or topic", by)
+		}
+
+		group, ok := index[key]
+		if !ok {
+			group = &ChangeGroup{Name: key, Type: changeType, Scope: scope}
+			index[key] = group
+			order = append(order, key)
+		}
+		group.Files = append(group.Files, file)
+	}
+
+	sort.Strings(order)
+	groups := make([]ChangeGroup, 0, len(order))
+	for _, key := range order {
+		groups = append(groups, *index[key])
+	}
+	return groups, nil
+}
+
+// scopeFromPath uses the innermost directory of a path as the commit scope
+func scopeFromPath(file string) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return ""
+	}
+	return path.Base(dir)
+}
+
+func saveStagedPatch() (string, error) {
+	output, err := gitCommand("diff", "--cached", "--binary").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged diff: %w", err)
+	}
+
+	tmpfile, err := os.CreateTemp("", "githelper-split-*.patch")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer tmpfile.Close()
+
+	if _, err := tmpfile.Write(output); err != nil {
+		return "", fmt.Errorf("failed to write staged diff: %w", err)
+	}
+	return tmpfile.Name(), nil
+}
+
+func stageGroup(patchFile string, files []string) error {
+	args := []string{"apply", "--cached"}
+	for _, file := range files {
+		args = append(args, "--include", escapeGlob(file))
+	}
+	args = append(args, patchFile)
+
+	cmd := gitCommand(args...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to stage group: %w", err)
+	}
+	return nil
+}
+
+// escapeGlob escapes the wildcards in a path, as git apply --include
+// matches its argument as a pattern
+func escapeGlob(path string) string {
+	var escaped strings.Builder
+	for _, r := range path {
+		if strings.ContainsRune(`\*?[`, r) {
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
+
+func restoreStagedPatch(patchFile string, groups []ChangeGroup) {
+	gitCommand("reset", "-q").Run()
+	for _, group := range groups {
+		if err := stageGroup(patchFile, group.Files); err != nil {
+			ui.Warnf("Failed to restage %s: %v", group.Name, err)
+		}
+	}
+}
+
+func splitCommitMessage(group ChangeGroup) (string, error) {
+	if useAI {
+		// Only the group is staged, so the diff and the offline fallback
+		// describe it alone
+		diff, err := getDetailedDiff()
+		if err != nil {
+			return "", err
+		}
+		return generateAIMessage(diff)
+	}
+
+	subject := "update " + strings.Join(group.Files, ", ")
+	if len(group.Files) > 3 {
+		subject = fmt.Sprintf("update %d files", len(group.Files))
+	}
+	if group.Scope != "" {
+		return fmt.Sprintf("%s(%s): %s", group.Type, group.Scope, subject), nil
+	}
+	return fmt.Sprintf("%s: %s", group.Type, subject), nil
+}
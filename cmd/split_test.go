@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupChanges(t *testing.T) {
+	files := []string{
+		"cmd/split.go",
+		"cmd/split_test.go",
+		"README.md",
+		"go.mod",
+		"internal/ai/commit.go",
+	}
+
+	tests := []struct {
+		name       string
+		by         string
+		wantGroups int
+		wantErr    bool
+	}{
+		{name: "by file", by: "file", wantGroups: 5},
+		{name: "by dir", by: "dir", wantGroups: 3},
+		{name: "by topic", by: "topic", wantGroups: 5},
+		{name: "invalid grouping", by: "author", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := groupChanges(files, tt.by)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, groups, tt.wantGroups)
+		})
+	}
+}
+
+func TestEscapeGlob(t *testing.T) {
+	assert.Equal(t, "cmd/split.go", escapeGlob("cmd/split.go"))
+	assert.Equal(t, `notes\[1].md`, escapeGlob("notes[1].md"))
+	assert.Equal(t, `a\*b\?c\\d`, escapeGlob(`a*b?c\d`))
+}
+
+func TestSplitPathsWithWildcards(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	r.WriteFile("notes[1].md", "notes\n")
+	r.WriteFile("main.go", "package main\n")
+	r.Git("add", ".")
+
+	_, _, err := execute(t, "split", "--yes")
+	require.NoError(t, err)
+	assert.Len(t, r.Log("HEAD"), 3)
+	assert.Empty(t, r.Status())
+	assert.Equal(t, "notes[1].md", r.Git("log", "-1", "--format=", "--name-only", "--", "notes[1].md"))
+}
+
+func TestSplitRestoresFailedGroup(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	// Refuse commits of the docs group
+	r.WriteFile(".git/hooks/pre-commit", "#!/bin/sh\ngit diff --cached --name-only | grep -q README.md && exit 1\nexit 0\n")
+	require.NoError(t, os.Chmod(r.Path(".git/hooks/pre-commit"), 0755))
+	r.WriteFile("README.md", "readme\n")
+	r.WriteFile("main.go", "package main\n")
+	r.Git("add", ".")
+
+	_, _, err := execute(t, "split", "--yes")
+	assert.ErrorContains(t, err, "failed to commit group 'docs'")
+	assert.Equal(t, []string{"Base"}, r.Log("HEAD"))
+	assert.Equal(t, "README.md\nmain.go", r.Git("diff", "--cached", "--name-only"), "the failed group and the ones after it are staged again")
+}
@@ -3,11 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 
 	"github.com/EndlessUphill/git-helper/internal/ai"
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -34,8 +34,15 @@ Useful when:
 Example:
   githelper squash 3                    # Squash last 3 commits
   githelper squash 5 -m "New feature"   # Squash with custom message
-  githelper squash 3 --ai               # Generate message with AI`,
-	Args: cobra.ExactArgs(1),
+  githelper squash 3 --ai               # Generate message with AI
+  githelper squash 3 --signoff -S       # Sign off and GPG-sign the result
+  githelper squash -i                   # Pick which of the last 10 commits to combine
+  githelper squash 6 -i --select 1,3,5  # Combine HEAD, HEAD~2 and HEAD~4
+
+With --interactive the commits to combine don't have to be consecutive: they
+are squashed into the oldest one selected with a rebase, and the commits in
+between are kept after it.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runSquash,
 }
 
@@ -43,6 +50,8 @@ func init() {
 	rootCmd.AddCommand(squashCmd)
 	squashCmd.Flags().StringVarP(&message, "message", "m", "", "custom commit message for squashed commit")
 	squashCmd.Flags().BoolVar(&useAI, "ai", false, "use AI to generate commit message")
+	squashCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show the git commands without running them")
+	addSigningFlags(squashCmd)
 }
 
 func runSquash(cmd *cobra.Command, args []string) error {
@@ -50,6 +59,13 @@ func runSquash(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if squashInteractive {
+		return runInteractiveSquash(args)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("give the number of commits to squash, or --interactive to pick them")
+	}
+
 	// Parse number of commits
 	numCommits, err := strconv.Atoi(args[0])
 	if err != nil || numCommits < 2 {
@@ -57,8 +73,8 @@ func runSquash(cmd *cobra.Command, args []string) error {
 	}
 
 	// Show commits that will be squashed
-	fmt.Printf("🔍 Last %d commits to be squashed:\n\n", numCommits)
-	logCmd := exec.Command("git", "log", "-n", strconv.Itoa(numCommits), "--oneline")
+	ui.Printf("🔍 Last %d commits to be squashed:\n\n", numCommits)
+	logCmd := gitCommand("log", "-n", strconv.Itoa(numCommits), "--oneline")
 	logCmd.Stdout = os.Stdout
 	logCmd.Stderr = os.Stderr
 	if err := logCmd.Run(); err != nil {
@@ -66,10 +82,12 @@ func runSquash(cmd *cobra.Command, args []string) error {
 	}
 
 	// Confirm action
-	fmt.Printf("\n⚠️  This will squash the above %d commits into one!\n", numCommits)
-	if !confirmAction() {
-		fmt.Println("❌ Operation cancelled")
-		return nil
+	if !dryRun {
+		ui.Warnf("\nThis will squash the above %d commits into one!", numCommits)
+		if !confirmAction() {
+			ui.Error("Operation cancelled")
+			return nil
+		}
 	}
 
 	// Get commit messages for AI or default message
@@ -98,30 +116,35 @@ func runSquash(cmd *cobra.Command, args []string) error {
 		finalMessage = fmt.Sprintf("squash: %s", createDefaultMessage(commitMessages))
 	}
 
+	if dryRun {
+		defer startDryRun()()
+	} else if err := recordOperation("squash", false); err != nil {
+		return err
+	}
+
 	// Perform soft reset
-	fmt.Printf("\n🔄 Resetting last %d commits...\n", numCommits)
-	resetCmd := exec.Command("git", "reset", "--soft", fmt.Sprintf("HEAD~%d", numCommits))
-	resetCmd.Stderr = os.Stderr
-	if err := resetCmd.Run(); err != nil {
+	ui.Stepf("\n🔄 Resetting last %d commits...", numCommits)
+	if err := gitRun("reset", "--soft", fmt.Sprintf("HEAD~%d", numCommits)); err != nil {
 		return fmt.Errorf("failed to reset commits: %w", err)
 	}
 
 	// Create new commit
-	fmt.Println("📝 Creating new squashed commit...")
-	commitCmd := exec.Command("git", "commit", "-m", finalMessage)
-	commitCmd.Stdout = os.Stdout
-	commitCmd.Stderr = os.Stderr
-	if err := commitCmd.Run(); err != nil {
+	ui.Step("📝 Creating new squashed commit...")
+	commitArgs := append([]string{"commit", "-m", finalMessage}, commitSigningArgs()...)
+	if err := gitRunShown(commitArgs...); err != nil {
 		return fmt.Errorf("failed to create squashed commit: %w", err)
 	}
 
-	fmt.Printf("✅ Successfully squashed %d commits!\n", numCommits)
+	if dryRun {
+		ui.Step("🔍 Dry run: nothing was changed")
+		return nil
+	}
+	ui.Successf("Successfully squashed %d commits!", numCommits)
 	return nil
 }
 
 func getCommitMessages(num int) (string, error) {
-	cmd := exec.Command("git", "log", "-n", strconv.Itoa(num), "--format=%B")
-	output, err := cmd.Output()
+	output, err := gitOutput("log", "-n", strconv.Itoa(num), "--format=%B")
 	if err != nil {
 		return "", fmt.Errorf("failed to get commit messages: %w", err)
 	}
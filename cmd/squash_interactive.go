@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+)
+
+// defaultInteractiveSquash is how many recent commits 'squash --interactive'
+// offers without a number
+const defaultInteractiveSquash = 10
+
+var (
+	squashInteractive bool
+	squashSelect      []string
+)
+
+// squashCommit is one of the recent commits offered by squash --interactive
+type squashCommit struct {
+	SHA     string
+	Subject string
+}
+
+func init() {
+	squashCmd.Flags().BoolVarP(&squashInteractive, "interactive", "i", false, "choose which of the last commits to combine")
+	squashCmd.Flags().StringSliceVar(&squashSelect, "select", nil, "commits to combine with --interactive, as hashes or positions (1 is HEAD), without prompting")
+}
+
+// recentCommits returns the last n commits of HEAD, newest first
+func recentCommits(n int) ([]squashCommit, error) {
+	output, err := gitOutput("log", "-n", strconv.Itoa(n), "--format=%H %s")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits: %w", err)
+	}
+	var commits []squashCommit
+	for _, line := range splitLines(string(output)) {
+		sha, subject, _ := strings.Cut(line, " ")
+		commits = append(commits, squashCommit{SHA: sha, Subject: subject})
+	}
+	return commits, nil
+}
+
+// resolveSquashSelection turns hashes or positions in commits (1 is the
+// newest) into the selected commits, newest first
+func resolveSquashSelection(commits []squashCommit, selection []string) ([]squashCommit, error) {
+	chosen := map[string]bool{}
+	for _, item := range selection {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if from, to, ok := strings.Cut(item, "-"); ok {
+			start, err1 := strconv.Atoi(from)
+			end, err2 := strconv.Atoi(to)
+			if err1 != nil || err2 != nil || start < 1 || end > len(commits) || start > end {
+				return nil, fmt.Errorf("invalid range '%s', use positions from 1 to %d", item, len(commits))
+			}
+			for i := start; i <= end; i++ {
+				chosen[commits[i-1].SHA] = true
+			}
+			continue
+		}
+		if n, err := strconv.Atoi(item); err == nil && len(item) < 4 {
+			if n < 1 || n > len(commits) {
+				return nil, fmt.Errorf("invalid position %d, use positions from 1 to %d", n, len(commits))
+			}
+			chosen[commits[n-1].SHA] = true
+			continue
+		}
+		found := false
+		for _, c := range commits {
+			if strings.HasPrefix(c.SHA, item) {
+				chosen[c.SHA] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("commit '%s' is not one of the last %d commits", item, len(commits))
+		}
+	}
+
+	var selected []squashCommit
+	for _, c := range commits {
+		if chosen[c.SHA] {
+			selected = append(selected, c)
+		}
+	}
+	return selected, nil
+}
+
+// pickSquashCommits asks which commits to combine, with fzf when installed
+func pickSquashCommits(commits []squashCommit) ([]squashCommit, error) {
+	if _, err := exec.LookPath("fzf"); err == nil && isInteractive() {
+		var input strings.Builder
+		for _, c := range commits {
+			fmt.Fprintf(&input, "%s %s\n", shortSHA(c.SHA), c.Subject)
+		}
+		fzfCmd := exec.Command("fzf",
+			"--multi",
+			"--height", "50%",
+			"--reverse",
+			"--header", "TAB selects the commits to combine",
+			"--preview", "git show --stat --color=always {1}",
+			"--preview-window", "right:50%")
+		fzfCmd.Stdin = strings.NewReader(input.String())
+		fzfCmd.Stderr = os.Stderr
+		output, err := fzfCmd.Output()
+		if err != nil {
+			return nil, nil // User cancelled
+		}
+		var hashes []string
+		for _, line := range splitLines(string(output)) {
+			hashes = append(hashes, strings.Fields(line)[0])
+		}
+		return resolveSquashSelection(commits, hashes)
+	}
+
+	ui.Println("\nRecent commits, newest first:")
+	for i, c := range commits {
+		ui.Printf("%3d. %s %s\n", i+1, shortSHA(c.SHA), c.Subject)
+	}
+	input := readInput("\nCommits to combine, e.g. '1 3 5' or '2-4' (Enter to cancel): ")
+	if input == "" {
+		return nil, nil
+	}
+	return resolveSquashSelection(commits, strings.FieldsFunc(input, func(r rune) bool { return r == ' ' || r == ',' }))
+}
+
+// squashPlan returns the rebase todo that combines the selected commits
+// into the oldest of them, keeping the others in their order. after is run
+// right after the combined commit, e.g. to set its message. commits and
+// selected are newest first.
+func squashPlan(commits, selected []squashCommit, after string) []string {
+	chosen := map[string]bool{}
+	for _, c := range selected {
+		chosen[c.SHA] = true
+	}
+	oldest := selected[len(selected)-1]
+
+	var todo []string
+	started := false
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+		if c.SHA == oldest.SHA {
+			started = true
+			todo = append(todo, "pick "+c.SHA+" "+c.Subject)
+			for j := len(selected) - 2; j >= 0; j-- {
+				todo = append(todo, "fixup "+selected[j].SHA+" "+selected[j].Subject)
+			}
+			todo = append(todo, "exec "+after)
+		} else if started && !chosen[c.SHA] {
+			todo = append(todo, "pick "+c.SHA+" "+c.Subject)
+		}
+	}
+	return todo
+}
+
+func runInteractiveSquash(args []string) error {
+	n := defaultInteractiveSquash
+	if len(args) > 0 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 2 {
+			return fmt.Errorf("please provide a valid number of commits (minimum 2)")
+		}
+	}
+	commits, err := recentCommits(n)
+	if err != nil {
+		return err
+	}
+
+	var selected []squashCommit
+	if len(squashSelect) > 0 {
+		selected, err = resolveSquashSelection(commits, squashSelect)
+	} else {
+		selected, err = pickSquashCommits(commits)
+	}
+	if err != nil {
+		return err
+	}
+	if len(selected) < 2 {
+		return fmt.Errorf("select at least 2 commits to squash")
+	}
+
+	oldest := selected[len(selected)-1]
+	base := oldest.SHA + "^"
+	if gitCommand("rev-parse", "--verify", "-q", base).Run() != nil {
+		base = "--root"
+	}
+	revRange := "HEAD"
+	if base != "--root" {
+		revRange = base + "..HEAD"
+	}
+	if output, err := gitCommand("rev-list", "--merges", revRange).Output(); err == nil && len(strings.TrimSpace(string(output))) > 0 {
+		return fmt.Errorf("there are merge commits after %s, which squash --interactive can't keep", shortSHA(oldest.SHA))
+	}
+
+	ui.Printf("🔍 %d commits will be squashed into one:\n\n", len(selected))
+	for _, c := range selected {
+		ui.Printf("  %s %s\n", shortSHA(c.SHA), c.Subject)
+	}
+	kept := 0
+	for _, c := range commits {
+		if c.SHA == oldest.SHA {
+			break
+		}
+		kept++
+	}
+	if kept -= len(selected) - 1; kept > 0 {
+		ui.Printf("\n%d commit(s) in between are kept, after the squashed one\n", kept)
+	}
+
+	if !dryRun {
+		if hasChanges, err := hasUncommittedChanges(); err != nil {
+			return err
+		} else if hasChanges {
+			return fmt.Errorf("you have uncommitted changes. Please commit or stash them first")
+		}
+		ui.Warnf("\nThis will squash the above %d commits into one!", len(selected))
+		if !confirmAction() {
+			ui.Error("Operation cancelled")
+			return nil
+		}
+	}
+
+	var hashes []string
+	for _, c := range selected {
+		hashes = append(hashes, c.SHA)
+	}
+	finalMessage := message
+	if finalMessage == "" {
+		output, err := gitOutput(append([]string{"show", "-s", "--format=%B"}, hashes...)...)
+		if err != nil {
+			return fmt.Errorf("failed to get commit messages: %w", err)
+		}
+		if useAI {
+			if finalMessage, err = generateSquashMessage(string(output)); err != nil {
+				return fmt.Errorf("failed to generate commit message: %w", err)
+			}
+		} else {
+			finalMessage = fmt.Sprintf("squash: %s", createDefaultMessage(string(output)))
+		}
+	}
+
+	dir, err := makeTempDir("", "githelper-squash-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	messageFile := filepath.Join(dir, "message")
+	todoFile := filepath.Join(dir, "todo")
+
+	amend := []string{"git", "commit", "--amend", "--quiet", "-F", shellQuote(messageFile)}
+	for _, arg := range commitSigningArgs() {
+		amend = append(amend, shellQuote(arg))
+	}
+	todo := squashPlan(commits, selected, strings.Join(amend, " "))
+	if err := os.WriteFile(messageFile, []byte(finalMessage+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := os.WriteFile(todoFile, []byte(strings.Join(todo, "\n")+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write rebase plan: %w", err)
+	}
+
+	if dryRun {
+		defer startDryRun()()
+		ui.Println("\nRebase plan:")
+		for _, line := range todo {
+			ui.Printf("  %s\n", line)
+		}
+	} else if err := recordOperation("squash", false); err != nil {
+		return err
+	}
+
+	// git hands the todo file to the sequence editor, which replaces it
+	// with the plan
+	ui.Step("\n🔄 Rebasing to combine the commits...")
+	rebaseArgs := []string{"-c", "sequence.editor=cp " + shellQuote(todoFile),
+		"rebase", "--interactive", "--no-autosquash", base}
+	if err := gitRunShown(rebaseArgs...); err != nil {
+		return fmt.Errorf("failed to squash the commits: %w. Resolve the conflicts and run 'git rebase --continue', or 'git rebase --abort' to go back", err)
+	}
+
+	if dryRun {
+		ui.Step("🔍 Dry run: nothing was changed")
+		return nil
+	}
+	ui.Successf("Successfully squashed %d commits!", len(selected))
+	return nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSquashSelection(t *testing.T) {
+	commits := []squashCommit{
+		{"aaa1111", "Fourth"},
+		{"bbb2222", "Third"},
+		{"ccc3333", "Second"},
+		{"ddd4444", "First"},
+	}
+
+	selected, err := resolveSquashSelection(commits, []string{"3", "1"})
+	require.NoError(t, err)
+	assert.Equal(t, []squashCommit{commits[0], commits[2]}, selected, "newest first")
+
+	selected, err = resolveSquashSelection(commits, []string{"2-4"})
+	require.NoError(t, err)
+	assert.Equal(t, commits[1:], selected)
+
+	selected, err = resolveSquashSelection(commits, []string{"ddd4", " bbb2222 "})
+	require.NoError(t, err)
+	assert.Equal(t, []squashCommit{commits[1], commits[3]}, selected)
+
+	_, err = resolveSquashSelection(commits, []string{"5"})
+	assert.ErrorContains(t, err, "invalid position 5")
+	_, err = resolveSquashSelection(commits, []string{"3-2"})
+	assert.ErrorContains(t, err, "invalid range")
+	_, err = resolveSquashSelection(commits, []string{"fff9"})
+	assert.ErrorContains(t, err, "is not one of the last 4 commits")
+}
+
+func TestSquashPlan(t *testing.T) {
+	commits := []squashCommit{
+		{"a", "Fourth"},
+		{"b", "Third"},
+		{"c", "Second"},
+		{"d", "First"},
+	}
+	todo := squashPlan(commits, []squashCommit{commits[0], commits[2]}, "git commit --amend")
+	assert.Equal(t, []string{
+		"pick c Second",
+		"fixup a Fourth",
+		"exec git commit --amend",
+		"pick b Third",
+	}, todo)
+}
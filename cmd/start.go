@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	startBase     string
+	startPrefix   string
+	startName     string
+	startWorktree bool
+)
+
+var startCmd = &cobra.Command{
+	Use:   "start <issue-number>",
+	Short: "Create a branch to work on a GitHub issue",
+	Long: `Start working on an issue of the origin repository.
+
+This command:
+1. Fetches the issue title from GitHub
+2. Names the branch after it, e.g. 42-fix-login-redirect
+3. Creates the branch from the latest default branch (or --base)
+4. Switches to it, or creates a worktree for it with --worktree
+5. Records the issue on the branch for later commands
+
+The issue is stored in the branch.<name>.githelper-issue git config.
+
+Example:
+  githelper start 42
+  githelper start 42 --prefix fix/        # fix/42-login-redirect-loop
+  githelper start 42 --worktree           # Work on it in ../42-...
+  githelper start 42 --base release/2.x --name hotfix-login`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStart,
+}
+
+func init() {
+	rootCmd.AddCommand(startCmd)
+	startCmd.Flags().StringVar(&startBase, "base", "", "branch to start from (default: the default branch)")
+	startCmd.Flags().StringVar(&startPrefix, "prefix", "", "prefix of the branch name, e.g. feature/")
+	startCmd.Flags().StringVar(&startName, "name", "", "branch name instead of one generated from the issue title")
+	startCmd.Flags().BoolVar(&startWorktree, "worktree", false, "create the branch in a new worktree next to the repository")
+}
+
+func runStart(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	number, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil || number <= 0 {
+		return fmt.Errorf("invalid issue number '%s'", args[0])
+	}
+
+	client, owner, name, err := originClient()
+	if err != nil {
+		return err
+	}
+	issue, err := client.GetIssue(context.Background(), owner, name, number)
+	if errors.Is(err, github.ErrNotAnIssue) {
+		return fmt.Errorf("#%d %w", number, err)
+	} else if err != nil {
+		return fmt.Errorf("failed to get issue #%d: %w", number, err)
+	}
+	ui.Stepf("📋 #%d %s", issue.Number, issue.Title)
+	if issue.State != "open" {
+		ui.Warnf("The issue is %s", issue.State)
+	}
+
+	branch := startName
+	if branch == "" {
+		branch = issueBranchName(issue.Number, issue.Title, startPrefix)
+	}
+	if err := gitCommand("check-ref-format", "--branch", branch).Run(); err != nil {
+		return fmt.Errorf("'%s' is not a valid branch name", branch)
+	}
+	if gitCommand("rev-parse", "--verify", "-q", "refs/heads/"+branch).Run() == nil {
+		return fmt.Errorf("branch '%s' already exists. Use 'githelper switch %s'", branch, branch)
+	}
+
+	startPoint, err := issueStartPoint(startBase)
+	if err != nil {
+		return err
+	}
+
+	if startWorktree {
+		worktreePath := filepath.Join("..", branch)
+		ui.Stepf("🌱 Creating worktree for '%s' from %s...", branch, startPoint)
+		createCmd := gitCommand("worktree", "add", "--no-track", "-b", branch, worktreePath, startPoint)
+		createCmd.Stdout = os.Stdout
+		createCmd.Stderr = os.Stderr
+		if err := createCmd.Run(); err != nil {
+			return fmt.Errorf("failed to create worktree: %w", err)
+		}
+		defer ui.Stepf("👉 cd %s", worktreePath)
+	} else {
+		if hasChanges, err := hasUncommittedChanges(); err != nil {
+			return err
+		} else if hasChanges {
+			ui.Info("Your uncommitted changes come along to the new branch")
+		}
+		ui.Stepf("🌱 Creating branch '%s' from %s...", branch, startPoint)
+		if output, err := gitCommand("switch", "--no-track", "-c", branch, startPoint).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to create branch: %s", strings.TrimSpace(string(output)))
+		}
+	}
+
+	if err := recordBranchIssue(branch, issue.Number, issue.URL); err != nil {
+		return err
+	}
+	ui.Successf("Ready to work on #%d on '%s'", issue.Number, branch)
+	return nil
+}
+
+// issueBranchName names a branch after an issue: prefix, number and a slug
+// of the title, e.g. feature/42-fix-login-redirect
+func issueBranchName(number int, title, prefix string) string {
+	slug := branchSlug(title)
+	name := strconv.Itoa(number)
+	if slug != "" {
+		name += "-" + slug
+	}
+	return prefix + name
+}
+
+// issueStartPoint returns where a new branch starts: the remote-tracking
+// branch of base after fetching it, or the local branch without a remote
+func issueStartPoint(base string) (string, error) {
+	if base == "" {
+		var err error
+		if base, err = defaultBranch(); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := getOriginURL(); err == nil {
+		ui.Stepf("📥 Fetching %s...", base)
+		if err := gitCommand("fetch", "--quiet", "origin", base).Run(); err != nil {
+			ui.Warnf("Failed to fetch %s, starting from the local branch", base)
+		} else if gitCommand("rev-parse", "--verify", "-q", "refs/remotes/origin/"+base).Run() == nil {
+			return "origin/" + base, nil
+		}
+	}
+
+	if gitCommand("rev-parse", "--verify", "-q", base+"^{commit}").Run() != nil {
+		return "", fmt.Errorf("base branch '%s' does not exist", base)
+	}
+	return base, nil
+}
+
+// recordBranchIssue links a branch to the issue it works on
+func recordBranchIssue(branch string, number int, url string) error {
+	key := "branch." + branch + ".githelper-issue"
+	if err := gitCommand("config", key, strconv.Itoa(number)).Run(); err != nil {
+		return fmt.Errorf("failed to record issue: %w", err)
+	}
+	if url != "" {
+		if err := gitCommand("config", key+"-url", url).Run(); err != nil {
+			return fmt.Errorf("failed to record issue: %w", err)
+		}
+	}
+	return nil
+}
+
+// branchIssue returns the issue recorded for a branch by 'githelper start',
+// or 0 when there is none
+func branchIssue(branch string) (int, string) {
+	key := "branch." + branch + ".githelper-issue"
+	output, err := gitCommand("config", "--get", key).Output()
+	if err != nil {
+		return 0, ""
+	}
+	number, err := strconv.Atoi(strings.TrimSpace(string(output)))
+	if err != nil {
+		return 0, ""
+	}
+	url, _ := gitCommand("config", "--get", key+"-url").Output()
+	return number, strings.TrimSpace(string(url))
+}
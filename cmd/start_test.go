@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/github/githubtest"
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueBranchName(t *testing.T) {
+	assert.Equal(t, "42-fix-login-redirect", issueBranchName(42, "Fix login redirect", ""))
+	assert.Equal(t, "feature/7-add-dark-mode", issueBranchName(7, "feat: Add dark mode!", "feature/"))
+	assert.Equal(t, "9-404-on-settings-page", issueBranchName(9, "404 on settings page", ""))
+	assert.Equal(t, "3", issueBranchName(3, "???", ""))
+}
+
+func TestBranchIssue(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	number, _ := branchIssue("42-fix")
+	assert.Equal(t, 0, number)
+
+	require.NoError(t, recordBranchIssue("42-fix", 42, "https://github.com/o/r/issues/42"))
+	number, url := branchIssue("42-fix")
+	assert.Equal(t, 42, number)
+	assert.Equal(t, "https://github.com/o/r/issues/42", url)
+}
+
+func TestStartFromIssue(t *testing.T) {
+	server := fakeGitHub(t)
+	repo := server.AddRepo("octo/app")
+	issue := repo.AddIssue("Fix login redirect", "bug")
+	pr := repo.AddPull(githubtest.PullRequest{Title: "Some change", Head: "change"})
+
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	r.Git("remote", "add", "origin", "https://"+githubtest.Hostname+"/octo/app.git")
+
+	_, _, err := execute(t, "start", strconv.Itoa(pr.Number), "--base", "main")
+	assert.ErrorContains(t, err, "is a pull request, not an issue")
+
+	_, _, err = execute(t, "start", strconv.Itoa(issue.Number), "--base", "main", "--prefix", "fix/")
+	require.NoError(t, err)
+	assert.Equal(t, "fix/1-fix-login-redirect", r.Branch())
+	number, url := branchIssue("fix/1-fix-login-redirect")
+	assert.Equal(t, issue.Number, number)
+	assert.Equal(t, "https://github.test/octo/app/issues/1", url)
+}
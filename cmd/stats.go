@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statsSince  string
+	statsUntil  string
+	statsFormat string
+	statsTop    int
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [path]",
+	Short: "Show contribution and activity statistics",
+	Long: `Summarize who has been working on what over a time range.
+
+This command reports per author:
+1. Number of commits and lines added/removed
+2. Number of active days, with a sparkline of daily activity
+3. The files they changed the most
+
+It also lists the hottest files in the range and flags the ones that only a
+single person has touched (bus-factor risks).
+
+Example:
+  githelper stats                         # Last 30 days
+  githelper stats --since "3 months ago"  # Custom time range
+  githelper stats internal/               # Only changes under a path
+  githelper stats --format json           # Machine-readable output`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStats,
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().StringVar(&statsSince, "since", "30 days ago", "start of the time range")
+	statsCmd.Flags().StringVar(&statsUntil, "until", "", "end of the time range")
+	statsCmd.Flags().StringVar(&statsFormat, "format", "table", "output format: table, json")
+	statsCmd.Flags().IntVarP(&statsTop, "top", "n", 5, "number of hot files to show")
+}
+
+// AuthorActivity is the contribution summary of one author
+type AuthorActivity struct {
+	Name       string         `json:"name"`
+	Email      string         `json:"email"`
+	Commits    int            `json:"commits"`
+	Added      int            `json:"added"`
+	Deleted    int            `json:"deleted"`
+	ActiveDays int            `json:"active_days"`
+	HotFiles   []string       `json:"hot_files"`
+	Days       map[string]int `json:"-"`
+	Files      map[string]int `json:"-"`
+}
+
+// FileActivity is how often a file changed and by how many people
+type FileActivity struct {
+	Path    string `json:"path"`
+	Commits int    `json:"commits"`
+	Authors int    `json:"authors"`
+}
+
+// ActivityReport is the result of the stats command
+type ActivityReport struct {
+	Since    string            `json:"since"`
+	Until    string            `json:"until,omitempty"`
+	Commits  int               `json:"commits"`
+	Authors  []*AuthorActivity `json:"authors"`
+	HotFiles []FileActivity    `json:"hot_files"`
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+	if statsTop < 0 {
+		return fmt.Errorf("--top can't be negative")
+	}
+
+	path := ""
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	report, err := collectActivity(statsSince, statsUntil, path)
+	if err != nil {
+		return err
+	}
+
+	switch statsFormat {
+	case "json":
+		encoder := json.NewEncoder(ui.Out())
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case "table":
+		printActivityReport(report)
+		return nil
+	default:
+		return fmt.Errorf("invalid format '%s'. Use table or json", statsFormat)
+	}
+}
+
+func collectActivity(since, until, path string) (*ActivityReport, error) {
+	logArgs := []string{"log", "--no-merges", "--numstat", "--date=short",
+		"--format=%x1e%H%x1f%an%x1f%ae%x1f%ad"}
+	if since != "" {
+		logArgs = append(logArgs, "--since", since)
+	}
+	if until != "" {
+		logArgs = append(logArgs, "--until", until)
+	}
+	if path != "" {
+		logArgs = append(logArgs, "--", path)
+	}
+
+	output, err := gitCommand(logArgs...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	report := &ActivityReport{Since: since, Until: until}
+	authors := make(map[string]*AuthorActivity)
+	files := make(map[string]*FileActivity)
+	fileAuthors := make(map[string]map[string]bool)
+
+	for _, record := range strings.Split(string(output), "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		header := strings.Split(lines[0], "\x1f")
+		if len(header) != 4 {
+			continue
+		}
+		report.Commits++
+
+		author, ok := authors[header[2]]
+		if !ok {
+			author = &AuthorActivity{
+				Name:  header[1],
+				Email: header[2],
+				Days:  make(map[string]int),
+				Files: make(map[string]int),
+			}
+			authors[header[2]] = author
+		}
+		author.Commits++
+		author.Days[header[3]]++
+
+		for _, line := range lines[1:] {
+			fields := strings.Split(line, "\t")
+			if len(fields) != 3 {
+				continue
+			}
+			added, _ := strconv.Atoi(fields[0])
+			deleted, _ := strconv.Atoi(fields[1])
+			file := fields[2]
+
+			author.Added += added
+			author.Deleted += deleted
+			author.Files[file]++
+
+			if files[file] == nil {
+				files[file] = &FileActivity{Path: file}
+				fileAuthors[file] = make(map[string]bool)
+			}
+			files[file].Commits++
+			fileAuthors[file][author.Email] = true
+		}
+	}
+
+	for _, author := range authors {
+		author.ActiveDays = len(author.Days)
+		author.HotFiles = topKeys(author.Files, 3)
+		report.Authors = append(report.Authors, author)
+	}
+	sort.Slice(report.Authors, func(i, j int) bool {
+		if report.Authors[i].Commits != report.Authors[j].Commits {
+			return report.Authors[i].Commits > report.Authors[j].Commits
+		}
+		return report.Authors[i].Name < report.Authors[j].Name
+	})
+
+	for file, activity := range files {
+		activity.Authors = len(fileAuthors[file])
+		report.HotFiles = append(report.HotFiles, *activity)
+	}
+	sort.Slice(report.HotFiles, func(i, j int) bool {
+		if report.HotFiles[i].Commits != report.HotFiles[j].Commits {
+			return report.HotFiles[i].Commits > report.HotFiles[j].Commits
+		}
+		return report.HotFiles[i].Path < report.HotFiles[j].Path
+	})
+	if len(report.HotFiles) > statsTop {
+		report.HotFiles = report.HotFiles[:statsTop]
+	}
+
+	return report, nil
+}
+
+func printActivityReport(report *ActivityReport) {
+	if report.Commits == 0 {
+		ui.Printf("No commits since %s\n", report.Since)
+		return
+	}
+
+	start, end := activityRange(report)
+	ui.Printf("📊 %d commit(s) by %d author(s) since %s\n\n", report.Commits, len(report.Authors), report.Since)
+	ui.Printf("%-22s %7s %9s %9s %5s  %s\n", "AUTHOR", "COMMITS", "ADDED", "REMOVED", "DAYS", "ACTIVITY")
+	for _, author := range report.Authors {
+		ui.Printf("%-22s %7d %9s %9s %5d  %s\n",
+			truncate(author.Name, 22),
+			author.Commits,
+			"+"+strconv.Itoa(author.Added),
+			"-"+strconv.Itoa(author.Deleted),
+			author.ActiveDays,
+			sparkline(dailyCounts(author.Days, start, end), 30))
+	}
+
+	ui.Step("\n🔥 Hot files:")
+	for _, file := range report.HotFiles {
+		warning := ""
+		if file.Authors == 1 {
+			warning = "  ⚠️  single author"
+		}
+		ui.Printf("  %3d commit(s) %2d author(s)  %s%s\n", file.Commits, file.Authors, file.Path, warning)
+	}
+
+	ui.Step("\n📁 Most changed files per author:")
+	for _, author := range report.Authors {
+		ui.Printf("  %s: %s\n", author.Name, strings.Join(author.HotFiles, ", "))
+	}
+}
+
+// activityRange returns the first and last active day across all authors
+func activityRange(report *ActivityReport) (time.Time, time.Time) {
+	var start, end time.Time
+	for _, author := range report.Authors {
+		for day := range author.Days {
+			t, err := time.Parse("2006-01-02", day)
+			if err != nil {
+				continue
+			}
+			if start.IsZero() || t.Before(start) {
+				start = t
+			}
+			if t.After(end) {
+				end = t
+			}
+		}
+	}
+	return start, end
+}
+
+func dailyCounts(days map[string]int, start, end time.Time) []int {
+	var counts []int
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		counts = append(counts, days[day.Format("2006-01-02")])
+	}
+	return counts
+}
+
+// sparkline renders values as a line of block characters, resampled to at
+// most width characters
+func sparkline(values []int, width int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	if len(values) > width {
+		resampled := make([]int, width)
+		for i, v := range values {
+			resampled[i*width/len(values)] += v
+		}
+		values = resampled
+	}
+
+	max := 0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	ticks := []rune("▁▂▃▄▅▆▇█")
+	var line strings.Builder
+	for _, v := range values {
+		if max == 0 || v == 0 {
+			line.WriteRune(' ')
+			continue
+		}
+		line.WriteRune(ticks[(v*(len(ticks)-1))/max])
+	}
+	return line.String()
+}
+
+// topKeys returns the n keys with the highest counts
+func topKeys(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
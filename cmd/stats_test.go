@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectActivity(t *testing.T) {
+	defer func() { statsTop = 5 }()
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Add main", "main.go", "a\nb\n", "docs/guide.md", "guide\n")
+	r.Commit("Change main", "main.go", "a\nc\nd\n")
+	r.WriteFile("main.go", "a\n")
+	r.Git("add", "main.go")
+	r.Git("commit", "--quiet", "--author", "Bob <bob@example.com>", "-m", "Trim main")
+
+	statsTop = 5
+	report, err := collectActivity("", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Commits)
+	require.Len(t, report.Authors, 2)
+
+	test := report.Authors[0]
+	assert.Equal(t, "test@example.com", test.Email)
+	assert.Equal(t, 2, test.Commits)
+	assert.Equal(t, 3+2, test.Added)
+	assert.Equal(t, 1, test.Deleted)
+	assert.Equal(t, 1, test.ActiveDays)
+	assert.Equal(t, []string{"main.go", "docs/guide.md"}, test.HotFiles)
+
+	bob := report.Authors[1]
+	assert.Equal(t, "Bob", bob.Name)
+	assert.Equal(t, 1, bob.Commits)
+	assert.Equal(t, 0, bob.Added)
+	assert.Equal(t, 2, bob.Deleted)
+
+	assert.Equal(t, []FileActivity{
+		{Path: "main.go", Commits: 3, Authors: 2},
+		{Path: "docs/guide.md", Commits: 1, Authors: 1},
+	}, report.HotFiles)
+
+	t.Run("path", func(t *testing.T) {
+		report, err := collectActivity("", "", "docs")
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Commits)
+		assert.Equal(t, []FileActivity{{Path: "docs/guide.md", Commits: 1, Authors: 1}}, report.HotFiles)
+	})
+
+	t.Run("top", func(t *testing.T) {
+		statsTop = 1
+		report, err := collectActivity("", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, []FileActivity{{Path: "main.go", Commits: 3, Authors: 2}}, report.HotFiles)
+	})
+}
+
+func TestStatsNegativeTop(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Add main", "main.go", "a\n")
+
+	_, _, err := execute(t, "stats", "--top", "-1")
+	assert.ErrorContains(t, err, "--top can't be negative")
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		width  int
+		expect string
+	}{
+		{"empty", nil, 10, ""},
+		{"idle days are blank", []int{0, 1, 0, 7}, 10, " ▂ █"},
+		{"resampled to the width", []int{1, 1, 2, 2}, 2, "▄█"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, sparkline(tt.values, tt.width))
+		})
+	}
+}
+
+func TestTopKeys(t *testing.T) {
+	counts := map[string]int{"a.go": 1, "b.go": 3, "c.go": 3, "d.go": 2}
+	assert.Equal(t, []string{"b.go", "c.go", "d.go"}, topKeys(counts, 3))
+	assert.Equal(t, []string{"b.go", "c.go", "d.go", "a.go"}, topKeys(counts, 10))
+}
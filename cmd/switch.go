@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -34,7 +35,8 @@ Useful when:
 Example:
   githelper switch           # Interactive branch selection
   githelper switch --all    # Show all branches (including remote)
-  githelper switch --sort=name  # Sort by branch name`,
+  githelper switch --sort=name  # Sort by branch name
+  githelper switch --select main  # Skip the picker`,
 	RunE: runSwitch,
 }
 
@@ -50,6 +52,7 @@ func init() {
 	rootCmd.AddCommand(branchSwitchCmd)
 	branchSwitchCmd.Flags().BoolVar(&showAll, "all", false, "show all branches (including remote)")
 	branchSwitchCmd.Flags().StringVar(&sortBy, "sort", "date", "sort by: date, name")
+	branchSwitchCmd.Flags().StringVar(&preselectedItem, "select", "", "branch to switch to without prompting")
 }
 
 func runSwitch(cmd *cobra.Command, args []string) error {
@@ -84,15 +87,15 @@ func runSwitch(cmd *cobra.Command, args []string) error {
 	}
 
 	// Switch to branch
-	fmt.Printf("🔄 Switching to branch '%s'...\n", selected)
-	checkoutCmd := exec.Command("git", "checkout", selected)
+	ui.Stepf("🔄 Switching to branch '%s'...", selected)
+	checkoutCmd := gitCommand("checkout", selected)
 	checkoutCmd.Stdout = os.Stdout
 	checkoutCmd.Stderr = os.Stderr
 	if err := checkoutCmd.Run(); err != nil {
 		return fmt.Errorf("failed to switch branch: %w", err)
 	}
 
-	fmt.Printf("✅ Switched to branch '%s'\n", selected)
+	ui.Successf("Switched to branch '%s'", selected)
 	return nil
 }
 
@@ -104,7 +107,7 @@ func getBranches() ([]Branch, error) {
 		args = []string{"branch", "--format", "%(refname:short) %(objectname) %(committerdate:iso) %(contents:subject)"}
 	}
 
-	cmd := exec.Command("git", args...)
+	cmd := gitCommand(args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list branches: %w", err)
@@ -149,7 +152,13 @@ func getBranches() ([]Branch, error) {
 }
 
 func selectBranch(branches []Branch) (string, error) {
-	if !noFzf {
+	if preselectedItem != "" {
+		branch, err := preselect(branches, "branch", func(b Branch) bool {
+			return b.Name == preselectedItem
+		})
+		return branch.Name, err
+	}
+	if !noFzf && isInteractive() {
 		if _, err := exec.LookPath("fzf"); err == nil {
 			return selectBranchWithFzf(branches)
 		}
@@ -193,18 +202,16 @@ func selectBranchWithFzf(branches []Branch) (string, error) {
 }
 
 func selectBranchWithList(branches []Branch) (string, error) {
-	fmt.Println("\nAvailable branches:")
+	ui.Println("\nAvailable branches:")
 	for i, branch := range branches {
-		fmt.Printf("%2d: %s (%s) - %s\n",
+		ui.Printf("%2d: %s (%s) - %s\n",
 			i+1,
 			branch.Name,
 			branch.LastCommitDate.Format("2006-01-02"),
 			branch.LastCommitMsg)
 	}
 
-	fmt.Print("\nSelect branch number (or press Enter to cancel): ")
-	var input string
-	fmt.Scanln(&input)
+	input := readInput("\nSelect branch number (or press Enter to cancel): ")
 
 	if input == "" {
 		return "", nil
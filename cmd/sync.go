@@ -1,15 +1,25 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/progress"
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 var (
-	noStash bool
+	noStash      bool
+	syncStrategy string
+	syncContinue bool
 )
 
 var syncCmd = &cobra.Command{
@@ -19,19 +29,33 @@ var syncCmd = &cobra.Command{
 
 This command helps when your push is rejected due to remote changes:
 1. Stashes your working changes (optional)
-2. Pulls remote changes with rebase
-3. Restores your working changes
+2. Fetches origin and compares your branch with the remote branch
+3. Fast-forwards when only the remote has new commits
+4. When both sides have new commits, asks how to combine them:
+   rebase  replay your commits on top of the remote branch
+   merge   merge the remote branch into yours
+   reset   discard your commits and match the remote (keeps a backup/ branch)
+   branch  move your commits to <branch>-diverged-<time> and match the remote
+5. Restores your working changes
 
-Useful when:
-- Push is rejected due to remote changes
-- You want to update local branch without merge commits
-- You have local changes you don't want to lose
+If your restored changes conflict with the synced branch, the stash is kept
+until the conflicts are resolved. Resolve them with 'githelper resolve' (in
+these conflicts "ours" is the synced branch and "theirs" is your changes) and
+the stash is dropped after the last one, or fix them by hand, 'git add' the
+files and run 'githelper sync --continue'.
+
+Pass --strategy (or set sync.strategy) to choose without a prompt, e.g. in
+scripts. reset and branch can be undone with 'githelper rollback'. The
+branches they save are named after the current branch; reset is refused when
+syncing with another branch, as it would replace the current one.
 
 Example:
-  githelper sync              # Sync current branch
-  githelper sync main        # Sync specific branch
-  githelper sync --no-stash  # Skip stashing (if working tree is clean)
-  githelper sync --force     # Force sync even with uncommitted changes`,
+  githelper sync                     # Sync current branch
+  githelper sync main                # Sync with origin/main
+  githelper sync --strategy rebase   # Rebase when diverged, without asking
+  githelper sync --no-stash          # Skip stashing (if working tree is clean)
+  githelper sync --continue          # Finish restoring changes after conflicts
+  githelper sync --force             # Force sync even with uncommitted changes`,
 	RunE: runSync,
 }
 
@@ -39,6 +63,8 @@ func init() {
 	rootCmd.AddCommand(syncCmd)
 	syncCmd.Flags().BoolVar(&noStash, "no-stash", false, "skip stashing changes")
 	syncCmd.Flags().BoolVar(&force, "force", false, "force sync even with uncommitted changes")
+	syncCmd.Flags().StringVarP(&syncStrategy, "strategy", "s", "", "how to sync diverged branches: rebase, merge, reset or branch")
+	syncCmd.Flags().BoolVar(&syncContinue, "continue", false, "drop the stash once conflicts from restoring it are resolved")
 }
 
 func runSync(cmd *cobra.Command, args []string) error {
@@ -46,90 +72,414 @@ func runSync(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Get current branch if not specified
-	branch := "HEAD"
+	if syncContinue {
+		return finishSyncStash()
+	}
+	if sha, err := pendingSyncStash(); err != nil {
+		return err
+	} else if sha != "" {
+		return errors.New(i18n.T("sync.pending_stash"))
+	}
+
+	strategy := syncStrategy
+	if strategy == "" {
+		strategy = viper.GetString("sync.strategy")
+	}
+	if strategy != "" && !isSyncStrategy(strategy) {
+		return fmt.Errorf(i18n.T("sync.invalid_strategy"), strategy, strings.Join(syncStrategies, ", "))
+	}
+
+	// Sync with the remote branch of the same name unless one is given
+	current, err := getCurrentBranch()
+	if err != nil {
+		return err
+	}
+	if current == "" || current == "HEAD" {
+		return errors.New(i18n.T("sync.detached_head"))
+	}
+	branch := current
 	if len(args) > 0 {
 		branch = args[0]
 	}
+	if strategy == "reset" && branch != current {
+		return fmt.Errorf(i18n.T("sync.reset_other_branch"), current, branch)
+	}
 
 	// Check for uncommitted changes
 	hasChanges, err := hasUncommittedChanges()
 	if err != nil {
 		return err
 	}
+	var stash string
 
 	if hasChanges && !noStash {
 		// Stash changes if needed
-		fmt.Println("📦 Stashing local changes...")
-		if err := stashChanges(); err != nil {
+		ui.Step(i18n.T("sync.stashing"))
+		if stash, err = stashChanges(); err != nil {
 			return err
 		}
-		defer func() {
-			if err := popStash(); err != nil {
-				fmt.Printf("⚠️  Failed to restore stashed changes: %v\n", err)
-				fmt.Println("Your changes are still in the stash. Use 'git stash pop' to restore them.")
-			}
-		}()
 	} else if hasChanges {
 		if !force {
-			return fmt.Errorf("you have uncommitted changes. Use --force to proceed anyway, or commit/stash your changes")
+			return errors.New(i18n.T("sync.uncommitted_changes"))
 		}
-		fmt.Println("⚠️  Proceeding with uncommitted changes (forced)")
+		ui.Warn(i18n.T("sync.forced"))
 	}
 
+	if err := syncBranch(current, branch, strategy); err != nil {
+		if stash != "" {
+			ui.Warn("\n" + i18n.T("sync.incomplete"))
+			ui.Println(i18n.T("sync.incomplete_hint"))
+		}
+		return err
+	}
+
+	if stash != "" {
+		return restoreStash(stash)
+	}
+	return nil
+}
+
+// syncBranch brings the current branch up to date with origin/<branch>,
+// asking how to combine them when both sides have new commits
+func syncBranch(current, branch, strategy string) error {
 	// Fetch remote changes
-	fmt.Println("🔄 Fetching remote changes...")
-	fetchCmd := exec.Command("git", "fetch", "origin")
-	fetchCmd.Stderr = os.Stderr
-	if err := fetchCmd.Run(); err != nil {
-		return fmt.Errorf("failed to fetch remote changes: %w", err)
+	fetchCmd := gitCommand("fetch", "--progress", "origin")
+	if err := progress.Git(ui.Status(), i18n.T("sync.fetching"), fetchCmd); err != nil {
+		return fmt.Errorf(i18n.T("sync.fetch_failed"), err)
+	}
+
+	remoteRef := "origin/" + branch
+	if err := gitCommand("rev-parse", "--verify", "-q", "refs/remotes/"+remoteRef).Run(); err != nil {
+		ui.Info(i18n.T("sync.no_remote_branch", remoteRef))
+		return nil
+	}
+
+	ahead, behind, err := aheadBehind("HEAD", remoteRef)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case ahead == 0 && behind == 0:
+		ui.Success(i18n.T("sync.up_to_date", remoteRef))
+		return nil
+	case ahead > 0 && behind == 0:
+		ui.Success(i18n.T("sync.ahead", remoteRef, ahead))
+		return nil
+	case ahead == 0:
+		ui.Step(i18n.T("sync.fast_forwarding", behind, remoteRef))
+		mergeCmd := gitCommand("merge", "--ff-only", "--quiet", remoteRef)
+		mergeCmd.Stderr = os.Stderr
+		if err := mergeCmd.Run(); err != nil {
+			return fmt.Errorf(i18n.T("sync.fast_forward_failed"), err)
+		}
+		ui.Success(i18n.T("sync.done"))
+		return nil
+	}
+
+	ui.Step("\n" + i18n.T("sync.diverged", remoteRef))
+	printCommitList(i18n.T("sync.local_commits", ahead), remoteRef+"..HEAD")
+	printCommitList(i18n.T("sync.remote_commits", behind), "HEAD.."+remoteRef)
+
+	if strategy == "" {
+		if !isInteractive() {
+			return fmt.Errorf(i18n.T("sync.diverged_pass_strategy"), strings.Join(syncStrategies, ", "))
+		}
+		strategy, err = selectSyncStrategy(remoteRef)
+		if err != nil {
+			return err
+		}
+		if strategy == "" {
+			ui.Error(i18n.T("sync.cancelled"))
+			return nil
+		}
 	}
 
-	// Pull with rebase
-	fmt.Println("📥 Pulling remote changes with rebase...")
-	pullCmd := exec.Command("git", "pull", "--rebase", "origin", branch)
-	pullCmd.Stdout = os.Stdout
-	pullCmd.Stderr = os.Stderr
-	if err := pullCmd.Run(); err != nil {
-		if hasChanges && !noStash {
-			fmt.Println("\n⚠️  Rebase failed. Your original changes are safe in the stash.")
-			fmt.Println("Resolve the conflicts and run 'git stash pop' to restore your changes.")
+	return applySyncStrategy(strategy, current, branch, remoteRef)
+}
+
+var syncStrategies = []string{"rebase", "merge", "reset", "branch"}
+
+func isSyncStrategy(strategy string) bool {
+	for _, s := range syncStrategies {
+		if s == strategy {
+			return true
 		}
-		return fmt.Errorf("failed to pull with rebase: %w", err)
 	}
+	return false
+}
 
-	fmt.Println("✅ Successfully synchronized with remote!")
+func selectSyncStrategy(remoteRef string) (string, error) {
+	ui.Println("\n" + i18n.T("sync.choose_strategy"))
+	ui.Println(i18n.T("sync.strategy_rebase", remoteRef))
+	ui.Println(i18n.T("sync.strategy_merge", remoteRef))
+	ui.Println(i18n.T("sync.strategy_reset", remoteRef))
+	ui.Println(i18n.T("sync.strategy_branch", remoteRef))
+
+	input := readInput("\n" + i18n.T("sync.select_strategy"))
+	if input == "" {
+		return "", nil
+	}
+	var index int
+	if _, err := fmt.Sscanf(input, "%d", &index); err != nil || index < 1 || index > len(syncStrategies) {
+		return "", errors.New(i18n.T("sync.invalid_selection"))
+	}
+	return syncStrategies[index-1], nil
+}
+
+// applySyncStrategy combines the current branch with remoteRef, the remote
+// branch of branch. The commits reset and branch set aside are saved on a
+// branch named after the current one.
+func applySyncStrategy(strategy, current, branch, remoteRef string) error {
+	switch strategy {
+	case "rebase":
+		ui.Step(i18n.T("sync.rebasing", remoteRef))
+		rebaseCmd := gitCommand("rebase", remoteRef)
+		rebaseCmd.Stdout = os.Stdout
+		rebaseCmd.Stderr = os.Stderr
+		if err := rebaseCmd.Run(); err != nil {
+			ui.Warn("\n" + i18n.T("sync.rebase_conflicts"))
+			return fmt.Errorf(i18n.T("sync.rebase_failed"), err)
+		}
+
+	case "merge":
+		ui.Step(i18n.T("sync.merging", remoteRef))
+		mergeCmd := gitCommand("merge", "--no-edit", remoteRef)
+		mergeCmd.Stdout = os.Stdout
+		mergeCmd.Stderr = os.Stderr
+		if err := mergeCmd.Run(); err != nil {
+			ui.Warn("\n" + i18n.T("sync.merge_conflicts"))
+			return fmt.Errorf(i18n.T("sync.merge_failed"), err)
+		}
+
+	case "reset", "branch":
+		// Resetting onto another branch would throw away the current one
+		if strategy == "reset" && branch != current {
+			return fmt.Errorf(i18n.T("sync.reset_other_branch"), current, branch)
+		}
+		prefix := "backup/" + current
+		if strategy == "branch" {
+			prefix = current + "-diverged"
+		}
+		saved := fmt.Sprintf("%s-%s", prefix, time.Now().Format("20060102-150405"))
+		if err := gitCommand("branch", saved, "HEAD").Run(); err != nil {
+			return fmt.Errorf(i18n.T("sync.create_branch_failed"), saved, err)
+		}
+		if err := recordOperation("sync", false); err != nil {
+			return err
+		}
+
+		ui.Step(i18n.T("sync.resetting", remoteRef))
+		resetCmd := gitCommand("reset", "--hard", "--quiet", remoteRef)
+		resetCmd.Stderr = os.Stderr
+		if err := resetCmd.Run(); err != nil {
+			return fmt.Errorf(i18n.T("sync.reset_failed"), remoteRef, err)
+		}
+		if strategy == "branch" {
+			ui.Step(i18n.T("sync.commits_moved", saved))
+		} else {
+			ui.Step(i18n.T("sync.commits_saved", saved))
+		}
+	}
+
+	ui.Success(i18n.T("sync.done"))
 	return nil
 }
 
+// aheadBehind counts the commits only in local and only in remote
+func aheadBehind(local, remote string) (int, int, error) {
+	output, err := gitCommand("rev-list", "--left-right", "--count", local+"..."+remote).Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf(i18n.T("sync.compare_failed"), remote, err)
+	}
+	var ahead, behind int
+	if _, err := fmt.Sscanf(string(output), "%d %d", &ahead, &behind); err != nil {
+		return 0, 0, fmt.Errorf(i18n.T("sync.compare_failed"), remote, err)
+	}
+	return ahead, behind, nil
+}
+
+func printCommitList(title, revRange string) {
+	ui.Printf("  %s\n", title)
+	output, _ := gitCommand("log", "--format=    %h %s", "-n", "10", revRange).Output()
+	ui.Print(string(output))
+}
+
 func hasUncommittedChanges() (bool, error) {
-	statusCmd := exec.Command("git", "status", "--porcelain")
+	statusCmd := gitCommand("status", "--porcelain")
 	output, err := statusCmd.Output()
 	if err != nil {
-		return false, fmt.Errorf("failed to check git status: %w", err)
+		return false, fmt.Errorf(i18n.T("error.git_status"), err)
 	}
 	return len(output) > 0, nil
 }
 
-func stashChanges() error {
-	stashCmd := exec.Command("git", "stash", "save", "--include-untracked", 
-		fmt.Sprintf("Automatic stash by githelper sync at %s", getCurrentTimestamp()))
+// stashChanges stashes the working changes and returns the stash commit
+func stashChanges() (string, error) {
+	stashCmd := gitCommand("stash", "save", "--include-untracked", 
+		i18n.T("sync.stash_message", getCurrentTimestamp()))
 	stashCmd.Stderr = os.Stderr
-	return stashCmd.Run()
+	if err := stashCmd.Run(); err != nil {
+		return "", fmt.Errorf(i18n.T("sync.stash_failed"), err)
+	}
+	output, err := gitCommand("rev-parse", "--verify", "-q", "refs/stash").Output()
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("sync.stash_not_found"), err)
+	}
+	return strings.TrimSpace(string(output)), nil
 }
 
-func popStash() error {
-	fmt.Println("📦 Restoring your local changes...")
-	popCmd := exec.Command("git", "stash", "pop")
+// restoreStash pops the stash created by sync. When the changes conflict
+// with the synced branch, the stash is kept and the user is guided through
+// resolving the conflicts; it is dropped only once none are left.
+func restoreStash(stash string) error {
+	ui.Step(i18n.T("sync.restoring"))
+	ref, err := stashRef(stash)
+	if err != nil {
+		return err
+	}
+	popCmd := gitCommand("stash", "pop", "--quiet", ref)
 	popCmd.Stdout = os.Stdout
 	popCmd.Stderr = os.Stderr
-	return popCmd.Run()
+	popErr := popCmd.Run()
+	if popErr == nil {
+		return nil
+	}
+
+	conflicts := conflictedFiles()
+	if len(conflicts) == 0 {
+		// Nothing was applied, e.g. an untracked file is in the way
+		ui.Println(i18n.T("sync.restore_failed_hint", ref))
+		return fmt.Errorf(i18n.T("sync.restore_failed"), ref, popErr)
+	}
+
+	file, err := syncStashFile()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf(i18n.T("sync.save_state_failed"), err)
+	}
+	if err := os.WriteFile(file, []byte(stash+"\n"), 0644); err != nil {
+		return fmt.Errorf(i18n.T("sync.save_state_failed"), err)
+	}
+
+	ui.Warn("\n" + i18n.T("sync.stash_conflicts"))
+	for _, conflict := range conflicts {
+		ui.Printf("  %s\n", conflict)
+	}
+	ui.Println(i18n.T("sync.stash_kept"))
+	ui.Println(i18n.T("sync.ours_theirs"))
+
+	if isInteractive() && readInput("\n" + i18n.T("sync.resolve_now")) != "n" {
+		for hasConflicts() {
+			if err := resolveConflict(nil); err != nil {
+				ui.Warnf("%v", err)
+				break
+			}
+		}
+		if !hasConflicts() {
+			return finishSyncStash()
+		}
+	}
+
+	ui.Println("\n" + i18n.T("sync.resolve_later"))
+	ui.Println(i18n.T("sync.resolve_later_continue"))
+	return nil
 }
 
-func getCurrentTimestamp() string {
-	output, err := exec.Command("date", "+%Y-%m-%d %H:%M:%S").Output()
+// finishSyncStash drops the stash of a sync whose restore conflicted, once
+// every conflict is resolved
+func finishSyncStash() error {
+	stash, err := pendingSyncStash()
+	if err != nil {
+		return err
+	}
+	if stash == "" {
+		return errors.New(i18n.T("sync.nothing_pending"))
+	}
+	if conflicts := conflictedFiles(); len(conflicts) > 0 {
+		return fmt.Errorf(i18n.T("sync.still_conflicts"), strings.Join(conflicts, ", "))
+	}
+
+	// 'git stash pop' leaves the changes unstaged when it succeeds; do the same
+	if err := gitCommand("reset", "--quiet").Run(); err != nil {
+		return fmt.Errorf(i18n.T("sync.unstage_failed"), err)
+	}
+	if ref, err := stashRef(stash); err == nil {
+		if err := gitCommand("stash", "drop", "--quiet", ref).Run(); err != nil {
+			return fmt.Errorf(i18n.T("sync.drop_failed"), ref, err)
+		}
+	}
+	file, err := syncStashFile()
 	if err != nil {
-		return "unknown"
+		return err
+	}
+	if err := os.Remove(file); err != nil {
+		return fmt.Errorf(i18n.T("sync.clear_state_failed"), err)
 	}
-	return string(output)
+
+	ui.Success(i18n.T("sync.restored"))
+	return nil
+}
+
+// pendingSyncStash returns the stash left by a sync whose restore
+// conflicted, or "" if there is none. State for a stash that no longer
+// exists is cleared.
+func pendingSyncStash() (string, error) {
+	file, err := syncStashFile()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("sync.read_state_failed"), err)
+	}
+	stash := strings.TrimSpace(string(data))
+	if _, err := stashRef(stash); err != nil {
+		os.Remove(file)
+		return "", nil
+	}
+	return stash, nil
+}
+
+func syncStashFile() (string, error) {
+	output, err := gitCommand("rev-parse", "--path-format=absolute", "--git-path", "githelper/sync-stash").Output()
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("error.git_dir"), err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// stashRef finds the stash@{n} entry of a stash commit; other stashes may
+// have been pushed since
+func stashRef(stash string) (string, error) {
+	output, err := gitCommand("stash", "list", "--format=%H").Output()
+	if err != nil {
+		return "", fmt.Errorf(i18n.T("sync.list_stashes_failed"), err)
+	}
+	for i, sha := range strings.Fields(string(output)) {
+		if sha == stash {
+			return fmt.Sprintf("stash@{%d}", i), nil
+		}
+	}
+	return "", errors.New(i18n.T("sync.stash_gone", shortSHA(stash)))
+}
+
+func conflictedFiles() []string {
+	output, err := gitCommand("diff", "--name-only", "--diff-filter=U").Output()
+	if err != nil {
+		return nil
+	}
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(string(output)), "\n")
+}
+
+func getCurrentTimestamp() string {
+	return time.Now().Format("2006-01-02 15:04:05")
 } 
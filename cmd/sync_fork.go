@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -39,7 +41,7 @@ Example:
 func init() {
 	rootCmd.AddCommand(syncForkCmd)
 	syncForkCmd.Flags().StringVar(&upstreamURL, "upstream", "", "upstream repository URL or path (user/repo)")
-	syncForkCmd.Flags().StringVar(&mainBranch, "branch", "main", "main branch name (main or master)")
+	syncForkCmd.Flags().StringVar(&mainBranch, "branch", "", "upstream branch to sync with (default is upstream's default branch)")
 }
 
 func runSyncFork(cmd *cobra.Command, args []string) error {
@@ -60,13 +62,21 @@ func runSyncFork(cmd *cobra.Command, args []string) error {
 	}
 
 	// Fetch upstream
-	fmt.Println("🔄 Fetching upstream changes...")
-	fetchCmd := exec.Command("git", "fetch", "upstream")
+	ui.Step("🔄 Fetching upstream changes...")
+	fetchCmd := gitCommand("fetch", "upstream")
 	fetchCmd.Stderr = os.Stderr
 	if err := fetchCmd.Run(); err != nil {
 		return fmt.Errorf("failed to fetch upstream: %w", err)
 	}
 
+	if mainBranch == "" {
+		branch, err := defaultBranchOf("upstream")
+		if err != nil {
+			return err
+		}
+		mainBranch = branch
+	}
+
 	// Get current branch
 	currentBranch, err := getCurrentBranch()
 	if err != nil {
@@ -74,33 +84,33 @@ func runSyncFork(cmd *cobra.Command, args []string) error {
 	}
 
 	// Rebase on upstream
-	fmt.Printf("📥 Rebasing on upstream/%s...\n", mainBranch)
-	rebaseCmd := exec.Command("git", "rebase", fmt.Sprintf("upstream/%s", mainBranch))
+	ui.Stepf("📥 Rebasing on upstream/%s...", mainBranch)
+	rebaseCmd := gitCommand("rebase", fmt.Sprintf("upstream/%s", mainBranch))
 	rebaseCmd.Stdout = os.Stdout
 	rebaseCmd.Stderr = os.Stderr
 	if err := rebaseCmd.Run(); err != nil {
-		fmt.Println("\n⚠️  Rebase failed. Please resolve conflicts and run:")
-		fmt.Println("git rebase --continue")
-		fmt.Println("Then run this command again")
+		ui.Warn("\nRebase failed. Please resolve conflicts and run:")
+		ui.Println("git rebase --continue")
+		ui.Println("Then run this command again")
 		return fmt.Errorf("rebase failed: %w", err)
 	}
 
 	// Push to origin
-	fmt.Printf("📤 Pushing to origin/%s...\n", currentBranch)
-	pushCmd := exec.Command("git", "push", "origin", currentBranch, "--force-with-lease")
+	ui.Stepf("📤 Pushing to origin/%s...", currentBranch)
+	pushCmd := gitCommand("push", "origin", currentBranch, "--force-with-lease")
 	pushCmd.Stdout = os.Stdout
 	pushCmd.Stderr = os.Stderr
 	if err := pushCmd.Run(); err != nil {
 		return fmt.Errorf("failed to push to origin: %w", err)
 	}
 
-	fmt.Printf("✅ Successfully synced fork with upstream/%s!\n", mainBranch)
+	ui.Successf("Successfully synced fork with upstream/%s!", mainBranch)
 	return nil
 }
 
 func setupUpstream() error {
 	// Check if upstream remote exists
-	remoteCmd := exec.Command("git", "remote")
+	remoteCmd := gitCommand("remote")
 	output, err := remoteCmd.Output()
 	if err != nil {
 		return fmt.Errorf("failed to list remotes: %w", err)
@@ -127,11 +137,13 @@ func setupUpstream() error {
 			if upstreamURL == "" {
 				return fmt.Errorf("could not detect upstream repository. Please specify with --upstream")
 			}
+		} else if upstreamURL, err = expandRepoURL(upstreamURL, false); err != nil {
+			return err
 		}
 
 		// Add upstream remote
-		fmt.Printf("🔗 Adding upstream remote: %s\n", upstreamURL)
-		addCmd := exec.Command("git", "remote", "add", "upstream", upstreamURL)
+		ui.Stepf("🔗 Adding upstream remote: %s", upstreamURL)
+		addCmd := gitCommand("remote", "add", "upstream", upstreamURL)
 		addCmd.Stderr = os.Stderr
 		if err := addCmd.Run(); err != nil {
 			return fmt.Errorf("failed to add upstream remote: %w", err)
@@ -142,7 +154,7 @@ func setupUpstream() error {
 }
 
 func getOriginURL() (string, error) {
-	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd := gitCommand("remote", "get-url", "origin")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -151,17 +163,25 @@ func getOriginURL() (string, error) {
 }
 
 func detectUpstreamURL(originURL string) string {
+	// Ask the API which repository the fork was created from
+	if hostname, repoPath, err := github.ParseRepoURL(originURL); err == nil {
+		if host, err := resolveHost(hostname); err == nil && host.Token != "" {
+			if client, err := github.NewHostClient(host); err == nil {
+				owner, name, _ := strings.Cut(repoPath, "/")
+				if parent, err := client.GetParent(context.Background(), owner, name); err == nil {
+					return host.CloneURL(parent, strings.HasPrefix(originURL, "git@"))
+				}
+			}
+		}
+	}
+
 	// Handle SSH format: git@github.com:user/repo.git
 	if strings.HasPrefix(originURL, "git@") {
-		parts := strings.Split(originURL, ":")
-		if len(parts) != 2 {
-			return ""
-		}
-		repoPath := strings.TrimSuffix(parts[1], ".git")
-		if strings.Count(repoPath, "/") != 1 {
+		hostname, repoPath, err := github.ParseRepoURL(originURL)
+		if err != nil {
 			return ""
 		}
-		return fmt.Sprintf("https://github.com/%s.git", repoPath)
+		return fmt.Sprintf("https://%s/%s.git", hostname, repoPath)
 	}
 
 	// Handle HTTPS format: https://github.com/user/repo.git
@@ -179,7 +199,7 @@ func detectUpstreamURL(originURL string) string {
 }
 
 func getCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd := gitCommand("rev-parse", "--abbrev-ref", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current branch: %w", err)
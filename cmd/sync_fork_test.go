@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectUpstreamURL(t *testing.T) {
+	server := fakeGitHub(t)
+	server.AddRepo("octocat/app").Parent = "upstream/app"
+
+	assert.Equal(t, "https://github.test/upstream/app.git", detectUpstreamURL("https://github.test/octocat/app.git"))
+	assert.Equal(t, "git@github.test:upstream/app.git", detectUpstreamURL("git@github.test:octocat/app.git"))
+}
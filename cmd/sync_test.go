@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAheadBehind(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "base")
+	runGit(t, tmpDir, "branch", "other")
+	runGit(t, tmpDir, "commit", "--allow-empty", "-m", "local 1")
+	runGit(t, tmpDir, "commit", "--allow-empty", "-m", "local 2")
+	runGit(t, tmpDir, "checkout", "-q", "other")
+	runGit(t, tmpDir, "commit", "--allow-empty", "-m", "remote")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	ahead, behind, err := aheadBehind("HEAD", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, 0, ahead)
+	assert.Equal(t, 0, behind)
+
+	runGit(t, tmpDir, "checkout", "-q", "-")
+	ahead, behind, err = aheadBehind("HEAD", "other")
+	require.NoError(t, err)
+	assert.Equal(t, 2, ahead)
+	assert.Equal(t, 1, behind)
+}
+
+func TestIsSyncStrategy(t *testing.T) {
+	for _, strategy := range syncStrategies {
+		assert.True(t, isSyncStrategy(strategy))
+	}
+	assert.False(t, isSyncStrategy("squash"))
+}
+
+func TestRestoreStashKeepsStashOnConflict(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	runGit(t, tmpDir, "commit", "-m", "base")
+
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	require.NoError(t, os.WriteFile("test.txt", []byte("local change"), 0644))
+	stash, err := stashChanges()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile("test.txt", []byte("remote change"), 0644))
+	runGit(t, tmpDir, "commit", "-am", "remote")
+
+	require.NoError(t, restoreStash(stash))
+	assert.Equal(t, []string{"test.txt"}, conflictedFiles())
+	pending, err := pendingSyncStash()
+	require.NoError(t, err)
+	assert.Equal(t, stash, pending)
+
+	// Not finished while conflicts remain
+	assert.Error(t, finishSyncStash())
+
+	runGit(t, tmpDir, "checkout", "--theirs", "test.txt")
+	runGit(t, tmpDir, "add", "test.txt")
+	require.NoError(t, finishSyncStash())
+
+	content, err := os.ReadFile("test.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "local change", string(content))
+	_, err = stashRef(stash)
+	assert.Error(t, err)
+	pending, err = pendingSyncStash()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestRestoreStashFailureNamesTheStash(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+
+	r.WriteFile("notes.txt", "local notes\n")
+	stash, err := stashChanges()
+	require.NoError(t, err)
+	// A newer stash moves it to stash@{1}
+	r.WriteFile("other.txt", "newer\n")
+	r.Git("stash", "push", "--quiet", "--include-untracked", "-m", "newer")
+	// An untracked file in the way stops the pop without conflicts
+	r.WriteFile("notes.txt", "in the way\n")
+
+	err = restoreStash(stash)
+	assert.ErrorContains(t, err, "still in stash@{1}")
+	ref, err := stashRef(stash)
+	require.NoError(t, err)
+	assert.Equal(t, "stash@{1}", ref, "the stash is kept")
+	assert.Equal(t, "in the way\n", r.ReadFile("notes.txt"))
+}
+
+// formatVerb matches the fmt verbs in a message
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// TestSyncMessagesAreTranslated keeps sync fully translated: its errors and
+// output come from the catalog, and every key it uses is in it
+func TestSyncMessagesAreTranslated(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "sync.go", nil, 0)
+	require.NoError(t, err)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		fun, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, _ := fun.X.(*ast.Ident)
+		if pkg == nil {
+			return true
+		}
+		literal, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || literal.Kind != token.STRING {
+			return true
+		}
+		message, _ := strconv.Unquote(literal.Value)
+		switch {
+		case pkg.Name == "i18n" && fun.Sel.Name == "T":
+			assert.NotEqual(t, message, i18n.T(message), "%s is not in the catalog", message)
+		case !strings.ContainsFunc(formatVerb.ReplaceAllString(message, ""), unicode.IsLetter):
+			// Only formatting, such as "  %s\n"
+		case pkg.Name == "ui" || pkg.Name == "errors" || (pkg.Name == "fmt" && fun.Sel.Name == "Errorf"):
+			assert.Failf(t, "untranslated message", "%s.%s(%q)", pkg.Name, fun.Sel.Name, message)
+		}
+		return true
+	})
+}
@@ -0,0 +1,478 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tagMessage string
+	tagSign    bool
+	tagPush    bool
+	tagRemote  string
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage tags",
+	Long: `List, create, delete and move tags with fewer surprises.
+
+This command helps you manage tags:
+- List tags sorted by semantic version
+- Create annotated or signed tags
+- Delete tags locally and on the remote
+- Move a tag to another commit
+- Find tags left pointing at rewritten or unreachable history
+
+Example:
+  githelper tag list                       # Semver-sorted tag list
+  githelper tag create v1.2.0 -m "Release" # Annotated tag
+  githelper tag create v1.2.0 --sign       # Signed tag
+  githelper tag delete v1.2.0 --push       # Delete locally and on origin
+  githelper tag move v1.2.0 HEAD --push    # Retag and update the remote
+  githelper tag cleanup                    # Tags no branch can reach`,
+}
+
+var (
+	tagListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List tags sorted by semantic version",
+		RunE:  runTagList,
+	}
+
+	tagCreateCmd = &cobra.Command{
+		Use:   "create <tag> [commit]",
+		Short: "Create an annotated or signed tag",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE:  runTagCreate,
+	}
+
+	tagDeleteCmd = &cobra.Command{
+		Use:   "delete <tag>...",
+		Short: "Delete tags locally and optionally on the remote",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runTagDelete,
+	}
+
+	tagMoveCmd = &cobra.Command{
+		Use:     "move <tag> [commit]",
+		Aliases: []string{"retag"},
+		Short:   "Move an existing tag to another commit",
+		Args:    cobra.RangeArgs(1, 2),
+		RunE:    runTagMove,
+	}
+
+	tagCleanupCmd = &cobra.Command{
+		Use:   "cleanup",
+		Short: "Find tags that point to unreachable or rewritten history",
+		RunE:  runTagCleanup,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagListCmd)
+	tagCmd.AddCommand(tagCreateCmd)
+	tagCmd.AddCommand(tagDeleteCmd)
+	tagCmd.AddCommand(tagMoveCmd)
+	tagCmd.AddCommand(tagCleanupCmd)
+
+	tagCmd.PersistentFlags().StringVar(&tagRemote, "remote", "origin", "remote to push tag changes to")
+	tagCreateCmd.Flags().StringVarP(&tagMessage, "message", "m", "", "tag message (defaults to the tag name)")
+	tagCreateCmd.Flags().BoolVarP(&tagSign, "sign", "s", false, "create a GPG/SSH-signed tag")
+	tagCreateCmd.Flags().BoolVar(&tagPush, "push", false, "push the tag to the remote")
+	tagDeleteCmd.Flags().BoolVar(&tagPush, "push", false, "also delete the tag on the remote")
+	tagMoveCmd.Flags().BoolVar(&tagPush, "push", false, "force-update the tag on the remote")
+	tagCleanupCmd.Flags().BoolVar(&tagPush, "push", false, "also delete the tags on the remote")
+}
+
+// Tag is a tag and the commit it points to
+type Tag struct {
+	Name      string
+	Commit    string
+	Annotated bool
+	Date      string
+	Subject   string
+}
+
+func runTagList(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	tags, err := getTags()
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		ui.Println("No tags found.")
+		return nil
+	}
+
+	sortTagsBySemver(tags)
+	for _, tag := range tags {
+		kind := "lightweight"
+		if tag.Annotated {
+			kind = "annotated"
+		}
+		ui.Printf("%-20s %s  %-10s %-11s %s\n", tag.Name, tag.Commit[:8], tag.Date, kind, tag.Subject)
+	}
+	return nil
+}
+
+func runTagCreate(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	name := args[0]
+	message := tagMessage
+	if message == "" {
+		message = name
+	}
+
+	tagArgs := []string{"tag", "-a", "-m", message}
+	if tagSign {
+		tagArgs = []string{"tag", "-s", "-m", message}
+	}
+	tagArgs = append(tagArgs, name)
+	if len(args) > 1 {
+		tagArgs = append(tagArgs, args[1])
+	}
+
+	ui.Stepf("🏷️  Creating tag '%s'...", name)
+	createCmd := gitCommand(tagArgs...)
+	createCmd.Stderr = os.Stderr
+	if err := createCmd.Run(); err != nil {
+		return fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	if tagPush {
+		if err := pushTagRefs(false, "refs/tags/"+name); err != nil {
+			return err
+		}
+	}
+
+	ui.Successf("Tag '%s' created!", name)
+	return nil
+}
+
+func runTagDelete(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	ui.Println("Tags to delete:")
+	for _, tag := range args {
+		ui.Printf("- %s\n", tag)
+	}
+	if tagPush {
+		ui.Warnf("\nThe tags will also be deleted on '%s'!", tagRemote)
+	}
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	return deleteTags(args)
+}
+
+func deleteTags(tags []string) error {
+	deleteCmd := gitCommand(append([]string{"tag", "-d"}, tags...)...)
+	deleteCmd.Stdout = os.Stdout
+	deleteCmd.Stderr = os.Stderr
+	if err := deleteCmd.Run(); err != nil {
+		return fmt.Errorf("failed to delete tags: %w", err)
+	}
+
+	if tagPush {
+		var refs []string
+		for _, tag := range tags {
+			refs = append(refs, ":refs/tags/"+tag)
+		}
+		if err := pushTagRefs(false, refs...); err != nil {
+			return err
+		}
+	}
+
+	ui.Successf("Deleted %d tag(s)", len(tags))
+	return nil
+}
+
+func runTagMove(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	name := args[0]
+	target := "HEAD"
+	if len(args) > 1 {
+		target = args[1]
+	}
+
+	tag, err := getTag(name)
+	if err != nil {
+		return err
+	}
+
+	ui.Stepf("🏷️  Moving tag '%s' from %s to %s", name, tag.Commit[:8], target)
+	if tagPush {
+		ui.Warnf("Anyone who already fetched '%s' from '%s' will keep the old tag!", name, tagRemote)
+	}
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	// Keep the tag annotated, with its original message
+	moveArgs := []string{"tag", "-f", name, target}
+	if tag.Annotated {
+		message, err := gitCommand("for-each-ref", "refs/tags/"+name, "--format=%(contents)").Output()
+		if err != nil {
+			return fmt.Errorf("failed to read tag message: %w", err)
+		}
+		moveArgs = []string{"tag", "-f", "-a", "-m", strings.TrimSpace(string(message)), name, target}
+	}
+
+	moveCmd := gitCommand(moveArgs...)
+	moveCmd.Stderr = os.Stderr
+	if err := moveCmd.Run(); err != nil {
+		return fmt.Errorf("failed to move tag: %w", err)
+	}
+
+	if tagPush {
+		if err := pushTagRefs(true, "refs/tags/"+name); err != nil {
+			return err
+		}
+	}
+
+	ui.Successf("Tag '%s' now points to %s", name, target)
+	return nil
+}
+
+func runTagCleanup(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	ui.Step("🔍 Looking for tags that no branch can reach...")
+	tags, err := getTags()
+	if err != nil {
+		return err
+	}
+
+	reachable, err := getReachableTags()
+	if err != nil {
+		return err
+	}
+
+	var orphaned []string
+	for _, tag := range tags {
+		if !reachable[tag.Name] {
+			orphaned = append(orphaned, tag.Name)
+			ui.Printf("- %s (%s %s)\n", tag.Name, tag.Commit[:8], tag.Subject)
+		}
+	}
+
+	if hasOriginalRefs() {
+		ui.Info("\nrefs/original/ still holds the pre-rewrite history from filter-branch.")
+		ui.Println("Once you're happy with the rewrite, remove it with:")
+		ui.Println(`git for-each-ref --format="delete %(refname)" refs/original/ | git update-ref --stdin`)
+	}
+
+	if len(orphaned) == 0 {
+		ui.Success("All tags are reachable from a branch!")
+		return nil
+	}
+
+	ui.Warnf("\n%d tag(s) point to commits that are not on any branch.", len(orphaned))
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+	return deleteTags(orphaned)
+}
+
+func getTags() ([]Tag, error) {
+	output, err := gitCommand("for-each-ref", "refs/tags",
+		"--format=%(refname:short)%1f%(objecttype)%1f%(objectname)%1f%(*objectname)%1f%(creatordate:short)%1f%(contents:subject)").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	var tags []Tag
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 6 {
+			continue
+		}
+		tag := Tag{Name: fields[0], Commit: fields[2], Date: fields[4], Subject: fields[5]}
+		if fields[1] == "tag" {
+			tag.Annotated = true
+			tag.Commit = fields[3]
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func getTag(name string) (*Tag, error) {
+	tags, err := getTags()
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		if tag.Name == name {
+			return &tag, nil
+		}
+	}
+	return nil, fmt.Errorf("tag not found: %s", name)
+}
+
+// getReachableTags returns the tags that point into the history of a local or remote branch
+func getReachableTags() (map[string]bool, error) {
+	output, err := gitCommand("log", "--branches", "--remotes", "--simplify-by-decoration",
+		"--decorate-refs=refs/tags/", "--decorate=short", "--format=%D").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check tag reachability: %w", err)
+	}
+
+	reachable := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		for _, ref := range strings.Split(line, ",") {
+			ref = strings.TrimSpace(ref)
+			if name, ok := strings.CutPrefix(ref, "tag: "); ok {
+				reachable[name] = true
+			}
+		}
+	}
+	return reachable, nil
+}
+
+func hasOriginalRefs() bool {
+	output, err := gitCommand("for-each-ref", "refs/original/").Output()
+	return err == nil && len(strings.TrimSpace(string(output))) > 0
+}
+
+func pushTagRefs(forceUpdate bool, refs ...string) error {
+	pushArgs := []string{"push", tagRemote}
+	if forceUpdate {
+		pushArgs = append(pushArgs, "--force")
+	}
+	pushArgs = append(pushArgs, refs...)
+
+	ui.Stepf("📤 Pushing tag changes to %s...", tagRemote)
+	pushCmd := gitCommand(pushArgs...)
+	pushCmd.Stdout = os.Stdout
+	pushCmd.Stderr = os.Stderr
+	if err := pushCmd.Run(); err != nil {
+		return fmt.Errorf("failed to push tags: %w", err)
+	}
+	return nil
+}
+
+// sortTagsBySemver orders tags newest version first; tags that aren't
+// semantic versions go last, alphabetically
+func sortTagsBySemver(tags []Tag) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		return compareSemver(tags[i].Name, tags[j].Name) > 0
+	})
+}
+
+// compareSemver compares two version tags such as v1.2.3 or 1.2.3-rc.1.
+// Non-semver tags sort below every version.
+func compareSemver(a, b string) int {
+	va, okA := parseSemver(a)
+	vb, okB := parseSemver(b)
+	switch {
+	case !okA && !okB:
+		return strings.Compare(b, a)
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+
+	for i := 0; i < 3; i++ {
+		if va.parts[i] != vb.parts[i] {
+			if va.parts[i] > vb.parts[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+
+	// A release is newer than any of its pre-releases
+	switch {
+	case va.pre == vb.pre:
+		return 0
+	case va.pre == "":
+		return 1
+	case vb.pre == "":
+		return -1
+	}
+	return comparePrerelease(va.pre, vb.pre)
+}
+
+type semver struct {
+	parts [3]int
+	pre   string
+}
+
+func parseSemver(tag string) (semver, bool) {
+	var v semver
+	version := strings.TrimPrefix(tag, "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, v.pre, _ = strings.Cut(version, "-")
+
+	parts := strings.Split(version, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.parts[i] = n
+	}
+	return v, true
+}
+
+func comparePrerelease(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				if na > nb {
+					return 1
+				}
+				return -1
+			}
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		default:
+			if c := strings.Compare(pa[i], pb[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	switch {
+	case len(pa) > len(pb):
+		return 1
+	case len(pa) < len(pb):
+		return -1
+	}
+	return 0
+}
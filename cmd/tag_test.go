@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortTagsBySemver(t *testing.T) {
+	tags := []Tag{
+		{Name: "v1.2.0"},
+		{Name: "nightly"},
+		{Name: "v1.10.0"},
+		{Name: "v1.10.0-rc.2"},
+		{Name: "v1.10.0-rc.10"},
+		{Name: "v1.9"},
+		{Name: "v2.0.0-beta"},
+	}
+
+	sortTagsBySemver(tags)
+
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	assert.Equal(t, []string{
+		"v2.0.0-beta",
+		"v1.10.0",
+		"v1.10.0-rc.10",
+		"v1.10.0-rc.2",
+		"v1.9",
+		"v1.2.0",
+		"nightly",
+	}, names)
+}
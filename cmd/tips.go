@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/auth"
+	"github.com/EndlessUphill/git-helper/internal/git"
+	"github.com/EndlessUphill/git-helper/internal/journal"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// largeRepoSize is the packed size from which clean and maintenance help
+	largeRepoSize = 1 << 30
+	// largeStagedLines is the size of a staged change worth splitting
+	largeStagedLines = 400
+	// staleBranches is how many merged branches make prune worth it
+	staleBranches = 3
+)
+
+var tipsCmd = &cobra.Command{
+	Use:   "tips",
+	Short: "Suggest commands that help with the repository right now",
+	Long: `Look at the current repository and suggest the githelper commands that fit
+its state: an unfinished merge or rebase, commits on a detached HEAD, staged
+changes, a branch behind or ahead of its upstream, merged branches piling up,
+a large history, or a rewrite you may want to roll back.
+
+Tips are worked out from the local repository, without calling the GitHub
+API.
+
+Example:
+  githelper tips`,
+	Args: cobra.NoArgs,
+	RunE: runTips,
+}
+
+func init() {
+	rootCmd.AddCommand(tipsCmd)
+}
+
+// tip is a command suggested for the state of the repository
+type tip struct {
+	Reason  string
+	Command string
+}
+
+func runTips(cmd *cobra.Command, args []string) error {
+	tips := collectTips()
+	if len(tips) == 0 {
+		ui.Step("✨ Nothing to suggest, the repository is in good shape")
+		ui.Println("   See 'githelper --help' for everything githelper can do")
+		return nil
+	}
+
+	ui.Step("💡 Tips:")
+	lastGroup := ""
+	for _, t := range tips {
+		if group := tipGroup(t); group != lastGroup {
+			ui.Printf("\n  %s\n", group)
+			lastGroup = group
+		}
+		ui.Printf("  • %s\n      %s\n", t.Reason, t.Command)
+	}
+	return nil
+}
+
+// collectTips inspects the repository in the current directory, most
+// pressing tips first
+func collectTips() []tip {
+	var tips []tip
+	if !hasGitHubLogin() {
+		tips = append(tips, tip{"You're not logged in to GitHub", "githelper setup"})
+	}
+	if checkGitRepo() != nil {
+		return append(tips, tip{"You're not in a git repository", "githelper clone <owner/repo>"})
+	}
+
+	for name := range operationsInProgress() {
+		tips = append(tips, tip{fmt.Sprintf("A %s is in progress", name), "githelper explain"})
+	}
+	if files := conflictedFiles(); len(files) > 0 {
+		tips = append(tips, tip{fmt.Sprintf("%d file(s) have conflicts", len(files)), "githelper resolve"})
+	}
+	if detached, err := isDetachedHead(); err == nil && detached {
+		if orphans := orphanedCommits(); len(orphans) > 0 {
+			tips = append(tips, tip{fmt.Sprintf("HEAD is detached with %d commit(s) on no branch", len(orphans)), "githelper rescue"})
+		}
+	}
+	if entry := recentRewrite(); entry != nil {
+		tips = append(tips, tip{fmt.Sprintf("'%s' rewrote history %s", entry.Operation, timeAgo(entry.Time)), "githelper rollback --dry-run"})
+	}
+
+	if files, lines := stagedChanges(); files > 0 {
+		command := "githelper commit"
+		if viper.GetString("openai_api_key") != "" {
+			command = "githelper commit --ai"
+		}
+		tips = append(tips, tip{fmt.Sprintf("%d file(s) are staged", files), command})
+		if lines >= largeStagedLines && files > 1 {
+			tips = append(tips, tip{fmt.Sprintf("The staged change is large (%d lines)", lines), "githelper split"})
+		}
+	}
+
+	if branch, err := getCurrentBranch(); err == nil && branch != "" {
+		if !hasUpstream(branch) {
+			if main, err := defaultBranch(); err == nil && main != branch {
+				tips = append(tips, tip{fmt.Sprintf("%s isn't pushed yet", branch), "githelper push"})
+			}
+		} else if ahead, behind, err := aheadBehind(branch, branch+"@{upstream}"); err == nil {
+			switch {
+			case behind > 0:
+				tips = append(tips, tip{fmt.Sprintf("%s is %d commit(s) behind its upstream", branch, behind), "githelper sync"})
+			case ahead > 0:
+				tips = append(tips, tip{fmt.Sprintf("%s has %d commit(s) to push", branch, ahead), "githelper push"})
+			}
+		}
+	}
+
+	if main, err := defaultBranch(); err == nil {
+		if merged, err := getMergedBranches(main); err == nil && len(merged) >= staleBranches {
+			tips = append(tips, tip{fmt.Sprintf("%d branches are merged into %s", len(merged), main), "githelper prune"})
+		}
+	}
+
+	if output, err := gitCommand("count-objects", "-v").Output(); err == nil {
+		if size := git.ParseCountObjects(string(output)); size.PackSize+size.LooseSize >= largeRepoSize {
+			tips = append(tips,
+				tip{fmt.Sprintf("The history takes %s", formatSize(size.PackSize+size.LooseSize)), "githelper clean"},
+				tip{"Large repositories stay fast with background maintenance", "githelper maintenance enable"})
+		}
+	}
+	return tips
+}
+
+// tipGroup returns the help section of the command a tip suggests
+func tipGroup(t tip) string {
+	name := strings.Fields(strings.TrimPrefix(t.Command, "githelper "))[0]
+	for _, group := range commandGroups {
+		for _, command := range group.Commands {
+			if command == name {
+				return strings.TrimSuffix(group.Title, ":")
+			}
+		}
+	}
+	return "Other"
+}
+
+// hasGitHubLogin reports whether there's a token for the default host
+func hasGitHubLogin() bool {
+	_, err := hostCredential(defaultHostName())
+	return !errors.Is(err, auth.ErrNoToken)
+}
+
+// recentRewrite returns the last destructive operation of the past day that
+// wasn't rolled back
+func recentRewrite() *journal.Entry {
+	j, err := journal.Open(commandCtx)
+	if err != nil {
+		return nil
+	}
+	entry, err := j.Last()
+	if err != nil || time.Since(entry.Time) > 24*time.Hour {
+		return nil
+	}
+	return entry
+}
+
+// stagedChanges counts the staged files and changed lines
+func stagedChanges() (files, lines int) {
+	output, err := gitCommand("diff", "--cached", "--numstat").Output()
+	if err != nil {
+		return 0, 0
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		files++
+		// Binary files show "-"
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		lines += added + deleted
+	}
+	return files, lines
+}
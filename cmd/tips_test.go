@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectTips(t *testing.T) {
+	tmpDir, cleanup := setupGitRepo(t)
+	defer cleanup()
+	originalWd, _ := os.Getwd()
+	defer os.Chdir(originalWd)
+	os.Chdir(tmpDir)
+
+	// test.txt is staged
+	tips := collectTips()
+	assert.Contains(t, tips, tip{"1 file(s) are staged", "githelper commit"})
+	assert.Equal(t, "AI Assistance", tipGroup(tip{Command: "githelper commit"}))
+	assert.Equal(t, "Recovery and Safety Nets", tipGroup(tip{Command: "githelper rollback --dry-run"}))
+}
@@ -2,9 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -22,9 +21,10 @@ Two modes available:
 - Soft (default): Keeps changes staged in your working directory
 - Hard: Completely removes the changes
 
-Example: githelper undo        # soft reset of last commit
-         githelper undo --hard # hard reset of last commit
-         githelper undo -n 3   # undo last 3 commits`,
+Example: githelper undo           # soft reset of last commit
+         githelper undo --hard    # hard reset of last commit
+         githelper undo -n 3      # undo last 3 commits
+         githelper undo --dry-run # show the git commands without running them`,
 	RunE: runUndo,
 }
 
@@ -33,6 +33,7 @@ func init() {
 	flags := undoCmd.Flags()
 	flags.BoolVar(&hardReset, "hard", false, "completely remove changes (hard reset)")
 	flags.IntVarP(&numCommits, "num", "n", 1, "number of commits to undo")
+	flags.BoolVar(&dryRun, "dry-run", false, "show the git commands without running them")
 }
 
 func runUndo(cmd *cobra.Command, args []string) error {
@@ -41,10 +42,25 @@ func runUndo(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Confirm with user before proceeding
-	if !confirmUndo() {
-		fmt.Println("❌ Undo operation cancelled")
-		return nil
+	branch, err := getCurrentBranch()
+	if err != nil {
+		return err
+	}
+	if err := guardOperation("undo", branch); err != nil {
+		return err
+	}
+
+	if dryRun {
+		defer startDryRun()()
+	} else {
+		// Confirm with user before proceeding
+		if !confirmUndo() {
+			ui.Error("Undo operation cancelled")
+			return nil
+		}
+		if err := recordOperation("undo", false); err != nil {
+			return err
+		}
 	}
 
 	// Determine reset type
@@ -54,41 +70,34 @@ func runUndo(cmd *cobra.Command, args []string) error {
 	}
 
 	// Reset local commits
-	resetCmd := exec.Command("git", "reset", resetType, fmt.Sprintf("HEAD~%d", numCommits))
-	resetCmd.Stdout = os.Stdout
-	resetCmd.Stderr = os.Stderr
-	if err := resetCmd.Run(); err != nil {
+	if err := gitRunShown("reset", resetType, fmt.Sprintf("HEAD~%d", numCommits)); err != nil {
 		return fmt.Errorf("failed to reset commits: %w", err)
 	}
 
 	// Force push to remote
-	pushCmd := exec.Command("git", "push", "origin", "HEAD", "--force-with-lease")
-	pushCmd.Stdout = os.Stdout
-	pushCmd.Stderr = os.Stderr
-	if err := pushCmd.Run(); err != nil {
+	if err := gitRunShown("push", "origin", "HEAD", "--force-with-lease"); err != nil {
 		return fmt.Errorf("failed to force push: %w", err)
 	}
 
 	// Print success message
-	if hardReset {
-		fmt.Printf("✅ Successfully removed last %d commit(s) and pushed changes\n", numCommits)
+	if dryRun {
+		ui.Step("🔍 Dry run: nothing was changed")
+	} else if hardReset {
+		ui.Successf("Successfully removed last %d commit(s) and pushed changes", numCommits)
 	} else {
-		fmt.Printf("✅ Successfully undid last %d commit(s) while keeping changes locally\n", numCommits)
+		ui.Successf("Successfully undid last %d commit(s) while keeping changes locally", numCommits)
 	}
 
 	return nil
 }
 
 func confirmUndo() bool {
-	fmt.Printf("⚠️  Warning: This will undo the last %d commit(s) ", numCommits)
+	ui.Printf("⚠️  Warning: This will undo the last %d commit(s) ", numCommits)
 	if hardReset {
-		fmt.Print("and remove all changes")
+		ui.Print("and remove all changes")
 	} else {
-		fmt.Print("but keep changes locally")
+		ui.Print("but keep changes locally")
 	}
-	fmt.Print("\nAre you sure you want to continue? [y/N]: ")
-
-	var response string
-	fmt.Scanln(&response)
-	return response == "y" || response == "Y"
+	ui.Println()
+	return confirmAction()
 } 
\ No newline at end of file
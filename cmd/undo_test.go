@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/runner"
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUndoGitInvocations(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	r.Commits("work", 2)
+	head := r.Head()
+
+	recorder := &runner.Recorder{Passthrough: runner.ReadOnly, Next: runner.Exec{}}
+	originalRunner := commandRunner
+	defer func() { commandRunner = originalRunner }()
+	commandRunner = recorder
+	assumeYes, hardReset, numCommits = true, true, 2
+	defer func() { assumeYes, hardReset, numCommits = false, false, 1 }()
+
+	require.NoError(t, runUndo(undoCmd, nil))
+	assert.Equal(t, []string{
+		"git reset --hard HEAD~2",
+		"git push origin HEAD --force-with-lease",
+	}, recorder.Invocations())
+	assert.Equal(t, head, r.Head(), "recorded commands don't run")
+}
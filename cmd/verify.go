@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	requireSignoff   bool
+	requireSignature bool
+	verifyKeys       bool
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [range]",
+	Short: "Report unsigned or not signed-off commits",
+	Long: `Check a range of commits against signing policies.
+
+This command helps teams with DCO or signed-commit policies by:
+1. Listing every commit in the range
+2. Checking its GPG/SSH signature status
+3. Checking for a Signed-off-by trailer
+4. Exiting with an error when a commit violates the policy
+
+The range defaults to the commits not yet pushed (@{upstream}..HEAD).
+
+With --keys, the commits are grouped by signature status instead: unsigned,
+bad signature, unknown key (signed with a key that isn't in your keyring or
+allowed signers file), expired or revoked key, and good. The keys used are
+listed with their owner and how many commits they signed, which helps when
+migrating a project to signed commits. Unpushed commits can be signed with
+'githelper resign'.
+
+Example:
+  githelper verify                         # Verify unpushed commits
+  githelper verify main..HEAD              # Verify a branch
+  githelper verify --signoff               # Also require DCO sign-off
+  githelper verify --signature=false --signoff  # Only check sign-off
+  githelper verify v1.0.0..HEAD --keys     # Group by signature status and key`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().BoolVar(&requireSignature, "signature", true, "require a valid GPG/SSH signature")
+	verifyCmd.Flags().BoolVar(&requireSignoff, "signoff", false, "require a Signed-off-by trailer")
+	verifyCmd.Flags().BoolVar(&verifyKeys, "keys", false, "group the commits by signature status and list the keys that signed them")
+}
+
+// CommitSignature is the signing state of a single commit
+type CommitSignature struct {
+	Hash   string
+	Status string
+	Key    string
+	// Signer is the name of the key owner, when the key is known
+	Signer    string
+	Author    string
+	Subject   string
+	SignedOff bool
+}
+
+// signatureStatusText describes git's %G? placeholder values
+var signatureStatusText = map[string]string{
+	"G": "good",
+	"B": "bad",
+	"U": "good, unknown validity",
+	"X": "good, expired",
+	"Y": "good, expired key",
+	"R": "good, revoked key",
+	"E": "unknown key",
+	"N": "unsigned",
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	if err := checkGitRepo(); err != nil {
+		return err
+	}
+
+	revRange := "@{upstream}..HEAD"
+	if len(args) > 0 {
+		revRange = args[0]
+	}
+
+	commits, err := getCommitSignatures(revRange)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		ui.Successf("No commits in range %s", revRange)
+		return nil
+	}
+
+	problems := make(map[string][]string)
+	for _, c := range commits {
+		if requireSignature && !isValidSignature(c.Status) {
+			problems[c.Hash] = append(problems[c.Hash], "signature: "+signatureStatusText[c.Status])
+		}
+		if requireSignoff && !c.SignedOff {
+			problems[c.Hash] = append(problems[c.Hash], "missing Signed-off-by")
+		}
+	}
+
+	if verifyKeys {
+		printSignatureReport(commits, revRange)
+	} else {
+		ui.Printf("🔍 Verifying %d commit(s) in %s:\n\n", len(commits), revRange)
+		for _, c := range commits {
+			status := "✅"
+			if len(problems[c.Hash]) > 0 {
+				status = "❌"
+			}
+			ui.Printf("%s %s %-20s %s\n", status, c.Hash[:8], truncate(c.Author, 20), c.Subject)
+			for _, problem := range problems[c.Hash] {
+				ui.Printf("      %s\n", problem)
+			}
+		}
+	}
+
+	if failed := len(problems); failed > 0 {
+		if verifyKeys && requireSignature {
+			ui.Step("\n💡 Sign unpushed commits with 'githelper resign'")
+		}
+		return fmt.Errorf("%d of %d commit(s) do not meet the signing policy", failed, len(commits))
+	}
+	ui.Success("\nAll commits meet the signing policy!")
+	return nil
+}
+
+func getCommitSignatures(revRange string) ([]CommitSignature, error) {
+	format := "%H%x1f%G?%x1f%GK%x1f%an%x1f%s%x1f%(trailers:key=Signed-off-by,valueonly,separator=%x2C)%x1f%GS%x1e"
+	cmd := gitCommand("log", "--format="+format, revRange)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("failed to list commits in %s: %s", revRange, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to list commits in %s: %w", revRange, err)
+	}
+	return parseCommitSignatures(string(output)), nil
+}
+
+// parseCommitSignatures parses the records written by getCommitSignatures'
+// log format
+func parseCommitSignatures(output string) []CommitSignature {
+	var commits []CommitSignature
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.Split(strings.TrimSpace(record), "\x1f")
+		if len(fields) < 7 {
+			continue
+		}
+		commits = append(commits, CommitSignature{
+			Hash:      fields[0],
+			Status:    fields[1],
+			Key:       fields[2],
+			Author:    fields[3],
+			Subject:   fields[4],
+			SignedOff: strings.TrimSpace(fields[5]) != "",
+			Signer:    fields[6],
+		})
+	}
+	return commits
+}
+
+func isValidSignature(status string) bool {
+	return status == "G" || status == "U"
+}
+
+// truncate shortens s to max characters, ending it with "…" when it is cut
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
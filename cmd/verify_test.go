@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/testutil"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitSigningArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		expect []string
+	}{
+		{"no flags", nil, nil},
+		{"signoff", []string{"-s"}, []string{"--signoff"}},
+		{"default key", []string{"-S"}, []string{"--gpg-sign"}},
+		{"key shorthand", []string{"-S=ABCD1234"}, []string{"--gpg-sign=ABCD1234"}},
+		{"key", []string{"--gpg-sign=ABCD1234", "--signoff"}, []string{"--signoff", "--gpg-sign=ABCD1234"}},
+	}
+	defer func() { signOff, gpgSign = false, "" }()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signOff, gpgSign = false, ""
+			cmd := &cobra.Command{}
+			addSigningFlags(cmd)
+			require.NoError(t, cmd.ParseFlags(tt.args))
+			assert.Equal(t, tt.expect, commitSigningArgs())
+		})
+	}
+
+	t.Run("key after a space is an argument", func(t *testing.T) {
+		signOff, gpgSign = false, ""
+		cmd := &cobra.Command{}
+		addSigningFlags(cmd)
+		require.NoError(t, cmd.ParseFlags([]string{"-S", "ABCD1234"}))
+		assert.Equal(t, []string{"--gpg-sign"}, commitSigningArgs())
+		assert.Equal(t, []string{"ABCD1234"}, cmd.Flags().Args())
+	})
+}
+
+func TestParseCommitSignatures(t *testing.T) {
+	record := func(fields ...string) string {
+		return strings.Join(fields, "\x1f") + "\x1e\n"
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		expect []CommitSignature
+	}{
+		{"empty", "", nil},
+		{
+			name: "signed and signed off",
+			output: record("aaaa", "G", "ABCD1234", "Alice", "Add login", "Alice <alice@example.com>", "Alice Example <alice@example.com>") +
+				record("bbbb", "N", "", "Bob", "Fix typo", "", ""),
+			expect: []CommitSignature{
+				{Hash: "aaaa", Status: "G", Key: "ABCD1234", Author: "Alice", Subject: "Add login", SignedOff: true, Signer: "Alice Example <alice@example.com>"},
+				{Hash: "bbbb", Status: "N", Author: "Bob", Subject: "Fix typo"},
+			},
+		},
+		{
+			name:   "truncated records are skipped",
+			output: "cccc\x1fG\x1f\x1e\n",
+			expect: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, parseCommitSignatures(tt.output))
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	r := testutil.NewRepo(t)
+	r.Chdir()
+	r.Commit("Base", "base.txt", "base\n")
+	r.Commit("Add feature\n\nSigned-off-by: Test <test@example.com>", "feature.txt", "feature\n")
+	r.Commit("Fix feature", "feature.txt", "fixed\n")
+
+	commits, err := getCommitSignatures("HEAD~2..HEAD")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "Fix feature", commits[0].Subject)
+	assert.False(t, commits[0].SignedOff)
+	assert.Equal(t, "Add feature", commits[1].Subject)
+	assert.True(t, commits[1].SignedOff)
+	assert.Equal(t, "N", commits[1].Status)
+
+	stdout, _, err := execute(t, "verify", "HEAD~2..HEAD", "--signature=false", "--signoff")
+	assert.ErrorContains(t, err, "1 of 2 commit(s) do not meet the signing policy")
+	assert.Contains(t, stdout, "missing Signed-off-by")
+
+	_, _, err = execute(t, "verify", "HEAD~2..HEAD~1", "--signature=false", "--signoff")
+	assert.NoError(t, err)
+
+	_, _, err = execute(t, "verify", "HEAD~2..HEAD~1")
+	assert.ErrorContains(t, err, "do not meet the signing policy")
+
+	stdout, _, err = execute(t, "verify", "HEAD~2..HEAD", "--keys", "--signoff")
+	assert.ErrorContains(t, err, "2 of 2 commit(s) do not meet the signing policy")
+	assert.Contains(t, stdout, "Unsigned: 2 commit(s)")
+	assert.Contains(t, stdout, "Missing Signed-off-by: 1 commit(s)")
+	assert.Contains(t, stdout, "githelper resign")
+	assert.NotContains(t, stdout, "Verifying")
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s      string
+		max    int
+		expect string
+	}{
+		{"Alice", 20, "Alice"},
+		{"Bartholomew Example", 10, "Bartholom…"},
+		{"José Ñúñez", 10, "José Ñúñez"},
+		{"José Ñúñez García", 10, "José Ñúñe…"},
+		{"李小龍李小龍", 4, "李小龍…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			assert.Equal(t, tt.expect, truncate(tt.s, tt.max))
+		})
+	}
+}
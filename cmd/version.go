@@ -1,22 +1,158 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/auth"
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/EndlessUphill/git-helper/internal/i18n"
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/EndlessUphill/git-helper/internal/version"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+var (
+	versionCheck bool
+	versionEnv   bool
+)
+
+// describeSuffix is what git describe adds after the tag of a build:
+// -<commits>-g<hash> and -dirty
+var describeSuffix = regexp.MustCompile(`(-\d+-g[0-9a-f]+)?(-dirty)?$`)
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print version information",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("GitHelper %s\n", version.Version)
-		fmt.Printf("Commit: %s\n", version.CommitHash)
-		fmt.Printf("Built: %s\n", version.BuildDate)
-	},
+	Long: `Print the version of githelper.
+
+--check asks GitHub for the latest release and tells you if it's newer.
+--env also reports the tools and settings githelper depends on: the git
+version, whether fzf and bat are installed, the config file and profile in
+use, and which GitHub hosts and AI provider are configured. Paste it into bug
+reports; tokens and keys are never printed.
+
+Example:
+  githelper version            # Version, commit and build date
+  githelper version --check    # Is there a newer release?
+  githelper version --env      # Environment report for bug reports`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
 }
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
-} 
\ No newline at end of file
+	versionCmd.Flags().BoolVar(&versionCheck, "check", false, "check GitHub for a newer release")
+	versionCmd.Flags().BoolVar(&versionEnv, "env", false, "report git, tools and configured providers")
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	ui.Printf("GitHelper %s\n", version.Version)
+	ui.Printf("Commit: %s\n", version.CommitHash)
+	ui.Printf("Built: %s\n", version.BuildDate)
+
+	if versionEnv {
+		ui.Println()
+		printEnvironment()
+	}
+	if versionCheck {
+		ui.Println()
+		return checkLatestVersion()
+	}
+	return nil
+}
+
+// checkLatestVersion compares the running version with the latest release
+func checkLatestVersion() error {
+	host, err := resolveHost(github.DefaultHost)
+	if err != nil {
+		return err
+	}
+	// A token only raises the rate limit, releases are public
+	client, err := github.NewHostClient(host)
+	if err != nil {
+		return err
+	}
+	owner, name, _ := strings.Cut(version.Repository, "/")
+	latest, err := client.LatestRelease(commandCtx, owner, name)
+	if errors.Is(err, github.ErrUnauthorized) {
+		return errors.New(i18n.T("error.not_authorized"))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check the latest release: %w", err)
+	}
+
+	current := describeSuffix.ReplaceAllString(version.Version, "")
+	switch {
+	case version.Version == "dev":
+		ui.Stepf("🔧 Development build; the latest release is %s", latest.Tag)
+	case compareSemver(latest.Tag, current) > 0:
+		ui.Stepf("⬆️  %s is available (you have %s)", latest.Tag, version.Version)
+		ui.Printf("   Release notes: %s\n", latest.URL)
+		ui.Println("   Update with 'make install' in your checkout, or download it from the release page")
+	default:
+		ui.Successf("Up to date (latest release is %s)", latest.Tag)
+	}
+	return nil
+}
+
+// printEnvironment reports what githelper finds on this machine, without
+// secrets
+func printEnvironment() {
+	ui.Printf("OS: %s/%s (%s)\n", runtime.GOOS, runtime.GOARCH, runtime.Version())
+
+	if output, err := gitCommand("--version").Output(); err == nil {
+		ui.Printf("Git: %s\n", strings.TrimPrefix(strings.TrimSpace(string(output)), "git version "))
+	} else {
+		ui.Println("Git: ❌ not found")
+	}
+	for _, tool := range []struct{ name, missing string }{
+		{"fzf", "interactive pickers fall back to numbered prompts"},
+		{"bat", "previews use plain cat"},
+		{"git-lfs", "archive --lfs is unavailable"},
+	} {
+		if path, err := exec.LookPath(tool.name); err == nil {
+			ui.Printf("%s: ✅ %s\n", tool.name, path)
+		} else {
+			ui.Printf("%s: ❌ not found (%s)\n", tool.name, tool.missing)
+		}
+	}
+
+	if file := viper.ConfigFileUsed(); file != "" {
+		ui.Printf("Config: %s\n", file)
+	} else {
+		ui.Println("Config: none")
+	}
+	ui.Printf("Language: %s\n", i18n.Language())
+	if profile := activeProfile(); profile != "" {
+		ui.Printf("Profile: %s\n", profile)
+	}
+
+	ui.Println("GitHub hosts:")
+	hosts, err := knownHosts()
+	if err != nil {
+		ui.Printf("  ⚠️  %v\n", err)
+	}
+	for _, name := range hosts {
+		credential, err := hostCredential(name)
+		switch {
+		case errors.Is(err, auth.ErrNoToken):
+			ui.Printf("  %s: ❌ no token\n", name)
+		case err != nil:
+			ui.Printf("  %s: ⚠️  %v\n", name, err)
+		default:
+			ui.Printf("  %s: ✅ token from %s\n", name, credential.Source)
+		}
+	}
+
+	if viper.GetString("openai_api_key") != "" {
+		ui.Println("AI provider: ✅ OpenAI (key configured)")
+	} else {
+		ui.Println("AI provider: ❌ none (set openai_api_key; --ai falls back to offline messages)")
+	}
+}
@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/checkpoint"
+	"github.com/EndlessUphill/git-helper/internal/ui"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultWatchInterval is the time between checkpoints unless watch.interval
+// says otherwise
+const defaultWatchInterval = 5 * time.Minute
+
+// watchPollInterval is how often --on-save looks for changes
+const watchPollInterval = 2 * time.Second
+
+var (
+	watchInterval time.Duration
+	watchOnSave   bool
+	watchOnce     bool
+	watchBranch   string
+	watchLimit    int
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Save checkpoints of your work automatically",
+	Long: `Watch the working tree and save a checkpoint of it every few minutes, or on
+every save with --on-save, so work between real commits can't get lost.
+
+A checkpoint records every file of the working tree, untracked files
+included and ignored files excluded, as a commit on a shadow ref per branch
+(refs/githelper/checkpoints/<branch>). Nothing is saved when nothing changed.
+Your branch, staging area and files are never touched, and the shadow refs
+are never pushed.
+
+The interval defaults to watch.interval, or 5m. Stop watching with Ctrl+C.
+
+Example:
+  githelper watch                   # A checkpoint every 5 minutes
+  githelper watch --interval 1m
+  githelper watch --on-save         # A checkpoint as soon as files change
+  githelper watch --once            # One checkpoint, e.g. from an editor hook
+  githelper watch list
+  githelper watch restore           # Pick a checkpoint to bring back`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+var watchListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the checkpoints of the current branch",
+	Args:  cobra.NoArgs,
+	RunE:  runWatchList,
+}
+
+var watchRestoreCmd = &cobra.Command{
+	Use:   "restore [checkpoint] [path...]",
+	Short: "Bring back the files of a checkpoint",
+	Long: `Write the files of a checkpoint back into the working tree, all of them or
+only the given paths. The checkpoint is picked from a list when none is given.
+
+The current working tree is saved as a checkpoint first, so a restore can be
+undone by restoring that one. Untracked files created since are left alone,
+and nothing is staged.
+
+Example:
+  githelper watch restore
+  githelper watch restore 1a2b3c4
+  githelper watch restore 1a2b3c4 cmd/watch.go`,
+	RunE: runWatchRestore,
+}
+
+var watchClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the checkpoints of the current branch",
+	Args:  cobra.NoArgs,
+	RunE:  runWatchClear,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.AddCommand(watchListCmd)
+	watchCmd.AddCommand(watchRestoreCmd)
+	watchCmd.AddCommand(watchClearCmd)
+
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 0, "time between checkpoints (default: watch.interval or 5m)")
+	watchCmd.Flags().BoolVar(&watchOnSave, "on-save", false, "save a checkpoint as soon as files change")
+	watchCmd.Flags().BoolVar(&watchOnce, "once", false, "save one checkpoint and exit")
+	for _, c := range []*cobra.Command{watchListCmd, watchRestoreCmd, watchClearCmd} {
+		c.Flags().StringVarP(&watchBranch, "branch", "b", "", "branch of the checkpoints (default: the current branch)")
+	}
+	watchListCmd.Flags().IntVarP(&watchLimit, "limit", "n", 20, "number of checkpoints to show (0 for all)")
+	watchRestoreCmd.Flags().BoolVar(&noFzf, "no-fzf", false, "disable fzf usage even if available")
+}
+
+// openCheckpoints opens the checkpoint store and returns the branch the
+// checkpoints belong to, "" when HEAD is detached
+func openCheckpoints() (*checkpoint.Store, string, error) {
+	if err := checkGitRepo(); err != nil {
+		return nil, "", err
+	}
+	store, err := checkpoint.Open(commandCtx)
+	if err != nil {
+		return nil, "", err
+	}
+	if watchBranch != "" {
+		return store, watchBranch, nil
+	}
+	branch, _ := gitCommand("symbolic-ref", "-q", "--short", "HEAD").Output()
+	return store, strings.TrimSpace(string(branch)), nil
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	store, _, err := openCheckpoints()
+	if err != nil {
+		return err
+	}
+	interval := watchInterval
+	if interval == 0 {
+		interval = defaultWatchInterval
+		if configured := viper.GetString("watch.interval"); configured != "" {
+			if interval, err = time.ParseDuration(configured); err != nil {
+				return fmt.Errorf("invalid watch.interval '%s': %w", configured, err)
+			}
+		}
+	}
+	if watchOnSave {
+		interval = watchPollInterval
+	}
+	if interval < time.Second {
+		return fmt.Errorf("the interval must be at least 1s")
+	}
+
+	if watchOnce {
+		return saveCheckpoint(store, true)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if watchOnSave {
+		ui.Step("👀 Saving a checkpoint whenever files change (Ctrl+C to stop)")
+	} else {
+		ui.Stepf("👀 Saving a checkpoint every %s when files changed (Ctrl+C to stop)", interval)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := saveCheckpoint(store, false); err != nil {
+			ui.Warnf("%v", err)
+		}
+		select {
+		case <-ctx.Done():
+			ui.Step("\n👋 Stopped watching. Browse the checkpoints with 'githelper watch list'")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// saveCheckpoint saves a checkpoint of the current branch, saying so when
+// nothing changed only if verbose
+func saveCheckpoint(store *checkpoint.Store, verbose bool) error {
+	branch, _ := gitCommand("symbolic-ref", "-q", "--short", "HEAD").Output()
+	cp, err := store.Save(commandCtx, strings.TrimSpace(string(branch)))
+	if err != nil {
+		return err
+	}
+	if cp == nil {
+		if verbose {
+			ui.Step("✨ Nothing changed since the last checkpoint")
+		}
+		return nil
+	}
+	ui.Stepf("📍 %s Checkpoint %s%s", cp.Time.Format("15:04:05"), shortSHA(cp.Commit), checkpointChanges(cp))
+	return nil
+}
+
+// checkpointChanges describes how a checkpoint differs from the commit it
+// was made on
+func checkpointChanges(cp *checkpoint.Checkpoint) string {
+	output, err := gitCommand("diff", "--shortstat", cp.Head, cp.Commit).Output()
+	if err != nil || len(strings.TrimSpace(string(output))) == 0 {
+		return ""
+	}
+	return " (" + strings.TrimSpace(string(output)) + ")"
+}
+
+func runWatchList(cmd *cobra.Command, args []string) error {
+	store, branch, err := openCheckpoints()
+	if err != nil {
+		return err
+	}
+	checkpoints, err := store.List(commandCtx, branch, watchLimit)
+	if err != nil {
+		return err
+	}
+	if len(checkpoints) == 0 {
+		ui.Println("No checkpoints. Start saving them with 'githelper watch'")
+		return nil
+	}
+	for _, cp := range checkpoints {
+		ui.Printf("%s  %s  %-8s  on %s%s\n", shortSHA(cp.Commit), cp.Time.Format("2006-01-02 15:04"), timeAgo(cp.Time), shortSHA(cp.Head), checkpointChanges(&cp))
+	}
+	return nil
+}
+
+func runWatchRestore(cmd *cobra.Command, args []string) error {
+	store, branch, err := openCheckpoints()
+	if err != nil {
+		return err
+	}
+
+	var cp *checkpoint.Checkpoint
+	var paths []string
+	if len(args) > 0 {
+		if cp, err = store.Find(commandCtx, branch, args[0]); err != nil {
+			return err
+		}
+		paths = args[1:]
+	} else {
+		checkpoints, err := store.List(commandCtx, branch, watchLimit)
+		if err != nil {
+			return err
+		}
+		if len(checkpoints) == 0 {
+			return fmt.Errorf("no checkpoints to restore")
+		}
+		if cp, err = selectCheckpoint(checkpoints); err != nil {
+			return err
+		}
+		if cp == nil {
+			ui.Error("Operation cancelled")
+			return nil
+		}
+	}
+
+	target := "all files"
+	if len(paths) > 0 {
+		target = strings.Join(paths, ", ")
+	}
+	ui.Stepf("⏪ Restoring %s from checkpoint %s (%s)", target, shortSHA(cp.Commit), cp.Time.Format("2006-01-02 15:04:05"))
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+
+	if current, err := store.Save(commandCtx, branch); err != nil {
+		return fmt.Errorf("failed to save the current state: %w", err)
+	} else if current != nil {
+		ui.Stepf("📍 Saved the current state as checkpoint %s", shortSHA(current.Commit))
+	}
+	if err := store.Restore(commandCtx, cp, paths); err != nil {
+		return err
+	}
+	ui.Successf("Restored checkpoint %s", shortSHA(cp.Commit))
+	return nil
+}
+
+func runWatchClear(cmd *cobra.Command, args []string) error {
+	store, branch, err := openCheckpoints()
+	if err != nil {
+		return err
+	}
+	checkpoints, err := store.List(commandCtx, branch, 0)
+	if err != nil {
+		return err
+	}
+	if len(checkpoints) == 0 {
+		ui.Println("No checkpoints to delete")
+		return nil
+	}
+	ui.Stepf("🗑️  Deleting %d checkpoint(s) of %s", len(checkpoints), checkpoint.Ref(branch))
+	if !confirmAction() {
+		ui.Error("Operation cancelled")
+		return nil
+	}
+	if err := store.Clear(commandCtx, branch); err != nil {
+		return err
+	}
+	ui.Success("Checkpoints deleted")
+	return nil
+}
+
+func selectCheckpoint(checkpoints []checkpoint.Checkpoint) (*checkpoint.Checkpoint, error) {
+	if !noFzf && isInteractive() {
+		if _, err := exec.LookPath("fzf"); err == nil {
+			return selectCheckpointWithFzf(checkpoints)
+		}
+	}
+	return selectCheckpointWithList(checkpoints)
+}
+
+func selectCheckpointWithFzf(checkpoints []checkpoint.Checkpoint) (*checkpoint.Checkpoint, error) {
+	var input strings.Builder
+	for i, cp := range checkpoints {
+		fmt.Fprintf(&input, "%d\t%s\t%s %s\t%s\n", i, shortSHA(cp.Commit), cp.Time.Format("2006-01-02 15:04"), timeAgo(cp.Time), cp.Commit)
+	}
+
+	// Show what the checkpoint changed compared to the working tree
+	previewCmd := "git diff --stat {4} && git diff --color=always {4}"
+
+	fzfCmd := exec.Command("fzf",
+		"--height", "50%",
+		"--reverse",
+		"--delimiter", "\t",
+		"--with-nth", "2,3",
+		"--preview", previewCmd,
+		"--preview-window", "right:60%")
+
+	fzfCmd.Stdin = strings.NewReader(input.String())
+	fzfCmd.Stderr = os.Stderr
+
+	output, err := fzfCmd.Output()
+	if err != nil {
+		return nil, nil // User cancelled
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(strings.TrimSpace(string(output)), "%d", &index); err != nil || index < 0 || index >= len(checkpoints) {
+		return nil, fmt.Errorf("invalid selection")
+	}
+	return &checkpoints[index], nil
+}
+
+func selectCheckpointWithList(checkpoints []checkpoint.Checkpoint) (*checkpoint.Checkpoint, error) {
+	ui.Println("\nCheckpoints:")
+	for i, cp := range checkpoints {
+		ui.Printf("%2d: %s %s (%s)%s\n", i+1, shortSHA(cp.Commit), cp.Time.Format("2006-01-02 15:04"), timeAgo(cp.Time), checkpointChanges(&cp))
+	}
+
+	input := readInput("\nSelect checkpoint number (or press Enter to cancel): ")
+
+	if input == "" {
+		return nil, nil
+	}
+
+	var index int
+	if _, err := fmt.Sscanf(input, "%d", &index); err != nil || index < 1 || index > len(checkpoints) {
+		return nil, fmt.Errorf("invalid selection")
+	}
+	return &checkpoints[index-1], nil
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/EndlessUphill/git-helper/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -63,6 +64,8 @@ var (
 	}
 )
 
+var worktreeSparse string
+
 func init() {
 	rootCmd.AddCommand(worktreeCmd)
 	worktreeCmd.AddCommand(switchCmd)
@@ -70,6 +73,10 @@ func init() {
 	worktreeCmd.AddCommand(removeCmd)
 	worktreeCmd.AddCommand(cleanupCmd)
 	worktreeCmd.AddCommand(pullCmd)
+	createCmd.Flags().StringVar(&worktreeSparse, "sparse", "", "check out only the directories of this sparse profile")
+	for _, cmd := range []*cobra.Command{switchCmd, pullCmd} {
+		cmd.Flags().StringVar(&preselectedItem, "select", "", "worktree (path or directory name) to use without prompting")
+	}
 }
 
 func runWorktreeSwitch(cmd *cobra.Command, args []string) error {
@@ -81,7 +88,7 @@ func runWorktreeSwitch(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no worktree selected")
 	}
 
-	fmt.Printf("🔄 Switching to worktree: %s\n", worktree)
+	ui.Stepf("🔄 Switching to worktree: %s", worktree)
 	if err := os.Chdir(worktree); err != nil {
 		return fmt.Errorf("failed to change directory: %w", err)
 	}
@@ -89,7 +96,7 @@ func runWorktreeSwitch(cmd *cobra.Command, args []string) error {
 	// Print the new working directory
 	pwd, err := os.Getwd()
 	if err == nil {
-		fmt.Printf("✅ Now in: %s\n", pwd)
+		ui.Successf("Now in: %s", pwd)
 	}
 	return nil
 }
@@ -98,8 +105,20 @@ func runWorktreeCreate(cmd *cobra.Command, args []string) error {
 	branch := args[0]
 	worktreePath := filepath.Join("..", branch)
 
-	fmt.Printf("🌱 Creating worktree for branch '%s'...\n", branch)
-	createCmd := exec.Command("git", "worktree", "add", worktreePath, branch)
+	if worktreeSparse != "" {
+		// Fail on an unknown profile before creating anything
+		if _, err := sparseProfileDirs(worktreeSparse); err != nil {
+			return err
+		}
+	}
+
+	ui.Stepf("🌱 Creating worktree for branch '%s'...", branch)
+	addArgs := []string{"worktree", "add", worktreePath, branch}
+	if worktreeSparse != "" {
+		// Check out the profile only, not the whole tree first
+		addArgs = []string{"worktree", "add", "--no-checkout", worktreePath, branch}
+	}
+	createCmd := gitCommand(addArgs...)
 	createCmd.Stdout = os.Stdout
 	createCmd.Stderr = os.Stderr
 	if err := createCmd.Run(); err != nil {
@@ -111,27 +130,42 @@ func runWorktreeCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to change directory: %w", err)
 	}
 
-	fmt.Printf("✅ Worktree created and switched to: %s\n", worktreePath)
+	if worktreeSparse != "" {
+		ui.Stepf("🌿 Applying sparse profile '%s'...", worktreeSparse)
+		if _, err := applySparseProfile(worktreeSparse); err != nil {
+			return err
+		}
+		if err := gitRun("checkout"); err != nil {
+			return fmt.Errorf("failed to check out the worktree: %w", err)
+		}
+	}
+
+	ui.Successf("Worktree created and switched to: %s", worktreePath)
 	return nil
 }
 
 func runWorktreeRemove(cmd *cobra.Command, args []string) error {
 	worktree := args[0]
 
-	fmt.Printf("🗑️  Removing worktree: %s\n", worktree)
-	removeCmd := exec.Command("git", "worktree", "remove", worktree)
+	ui.Stepf("🗑️  Removing worktree: %s", worktree)
+	removeCmd := gitCommand("worktree", "remove", worktree)
 	removeCmd.Stderr = os.Stderr
 	if err := removeCmd.Run(); err != nil {
 		return fmt.Errorf("failed to remove worktree: %w", err)
 	}
 
-	fmt.Printf("✅ Worktree removed: %s\n", worktree)
+	ui.Successf("Worktree removed: %s", worktree)
 	return nil
 }
 
 func runWorktreeCleanup(cmd *cobra.Command, args []string) error {
+	main, err := defaultBranch()
+	if err != nil {
+		return err
+	}
+
 	// Get merged branches
-	mergedCmd := exec.Command("git", "branch", "--merged", "main")
+	mergedCmd := gitCommand("branch", "--merged", main)
 	mergedOutput, err := mergedCmd.Output()
 	if err != nil {
 		return fmt.Errorf("failed to get merged branches: %w", err)
@@ -140,19 +174,19 @@ func runWorktreeCleanup(cmd *cobra.Command, args []string) error {
 	branches := strings.Split(strings.TrimSpace(string(mergedOutput)), "\n")
 	for _, branch := range branches {
 		branch = strings.TrimSpace(branch)
-		if branch == "main" || branch == "*" || branch == "" {
+		if branch == main || branch == "*" || branch == "" {
 			continue
 		}
 
 		worktreePath := filepath.Join("..", branch)
 		if _, err := os.Stat(worktreePath); err == nil {
-			fmt.Printf("🗑️  Removing worktree for merged branch: %s\n", branch)
-			removeCmd := exec.Command("git", "worktree", "remove", worktreePath)
+			ui.Stepf("🗑️  Removing worktree for merged branch: %s", branch)
+			removeCmd := gitCommand("worktree", "remove", worktreePath)
 			removeCmd.Run() // Ignore errors for cleanup
 		}
 	}
 
-	fmt.Println("✅ Cleanup complete!")
+	ui.Success("Cleanup complete!")
 	return nil
 }
 
@@ -165,7 +199,7 @@ func runWorktreePull(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no worktree selected")
 	}
 
-	fmt.Printf("🔄 Pulling updates in worktree: %s\n", worktree)
+	ui.Stepf("🔄 Pulling updates in worktree: %s", worktree)
 	
 	// Change to the selected worktree
 	if err := os.Chdir(worktree); err != nil {
@@ -173,41 +207,36 @@ func runWorktreePull(cmd *cobra.Command, args []string) error {
 	}
 
 	// Pull updates
-	pullCmd := exec.Command("git", "pull")
+	pullCmd := gitCommand("pull")
 	pullCmd.Stdout = os.Stdout
 	pullCmd.Stderr = os.Stderr
 	if err := pullCmd.Run(); err != nil {
 		return fmt.Errorf("failed to pull updates: %w", err)
 	}
 
-	fmt.Println("✅ Updates pulled successfully!")
+	ui.Success("Updates pulled successfully!")
 	return nil
 }
 
 func selectWorktree() (string, error) {
-	// Get worktree list
-	listCmd := exec.Command("git", "worktree", "list", "--porcelain")
-	output, err := listCmd.Output()
+	worktrees, err := worktreePaths()
 	if err != nil {
-		return "", fmt.Errorf("failed to list worktrees: %w", err)
-	}
-
-	var worktrees []string
-	lines := strings.Split(string(output), "\n")
-	for i := 0; i < len(lines); i++ {
-		line := strings.TrimSpace(lines[i])
-		if strings.HasPrefix(line, "worktree ") {
-			path := strings.TrimPrefix(line, "worktree ")
-			worktrees = append(worktrees, path)
-		}
+		return "", err
 	}
 
 	if len(worktrees) == 0 {
 		return "", fmt.Errorf("no worktrees found")
 	}
 
+	if preselectedItem != "" {
+		abs, _ := filepath.Abs(preselectedItem)
+		return preselect(worktrees, "worktree", func(worktree string) bool {
+			return worktree == abs || filepath.Base(worktree) == preselectedItem
+		})
+	}
+
 	// Try using fzf if available
-	if !noFzf {
+	if !noFzf && isInteractive() {
 		if _, err := exec.LookPath("fzf"); err == nil {
 			return selectWorktreeWithFzf(worktrees)
 		}
@@ -215,6 +244,33 @@ func selectWorktree() (string, error) {
 	return selectWorktreeWithList(worktrees)
 }
 
+// worktreePaths returns the paths of the worktrees of the repository, the
+// main one first; bare and missing worktrees are left out
+func worktreePaths() ([]string, error) {
+	output, err := gitCommand("worktree", "list", "--porcelain").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	var worktrees []string
+	for _, block := range strings.Split(strings.TrimSpace(string(output)), "\n\n") {
+		path := ""
+		usable := true
+		for _, line := range strings.Split(block, "\n") {
+			switch {
+			case strings.HasPrefix(line, "worktree "):
+				path = strings.TrimPrefix(line, "worktree ")
+			case line == "bare", strings.HasPrefix(line, "prunable"):
+				usable = false
+			}
+		}
+		if path != "" && usable {
+			worktrees = append(worktrees, path)
+		}
+	}
+	return worktrees, nil
+}
+
 func selectWorktreeWithFzf(worktrees []string) (string, error) {
 	// Create input for fzf
 	var input strings.Builder
@@ -223,7 +279,7 @@ func selectWorktreeWithFzf(worktrees []string) (string, error) {
 	}
 
 	// Create preview command that shows git status
-	previewCmd := "cd {} && git status"
+	previewCmd := "git -C {} status"
 
 	fzfCmd := exec.Command("fzf",
 		"--height", "50%",
@@ -243,14 +299,12 @@ func selectWorktreeWithFzf(worktrees []string) (string, error) {
 }
 
 func selectWorktreeWithList(worktrees []string) (string, error) {
-	fmt.Println("\nAvailable worktrees:")
+	ui.Println("\nAvailable worktrees:")
 	for i, worktree := range worktrees {
-		fmt.Printf("%2d: %s\n", i+1, worktree)
+		ui.Printf("%2d: %s\n", i+1, worktree)
 	}
 
-	fmt.Print("\nSelect worktree number (or press Enter to cancel): ")
-	var input string
-	fmt.Scanln(&input)
+	input := readInput("\nSelect worktree number (or press Enter to cancel): ")
 
 	if input == "" {
 		return "", nil
@@ -0,0 +1,51 @@
+package ai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Cache stores generated commit messages keyed by the hash of the diff they
+// were generated from, so regenerating for the same staged state is free.
+type Cache struct {
+	dir string
+}
+
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// DefaultCacheDir returns ~/.githelper/cache/ai
+func DefaultCacheDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".githelper", "cache", "ai"), nil
+}
+
+// DiffKey returns the cache key for a diff
+func DiffKey(diff string) string {
+	sum := sha256.Sum256([]byte(diff))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) Get(diff string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(c.dir, DiffKey(diff)))
+	if err != nil {
+		return "", false
+	}
+	message := strings.TrimSpace(string(data))
+	return message, message != ""
+}
+
+func (c *Cache) Put(diff, message string) error {
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return os.WriteFile(filepath.Join(c.dir, DiffKey(diff)), []byte(message), 0600)
+}
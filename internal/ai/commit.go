@@ -15,6 +15,9 @@ type openAIClient interface {
 
 type CommitGenerator struct {
 	client openAIClient
+	// Conventions are the commit message rules of the repository; nil uses
+	// DefaultConventions
+	Conventions *Conventions
 }
 
 func NewCommitGenerator(apiKey string) *CommitGenerator {
@@ -24,18 +27,18 @@ func NewCommitGenerator(apiKey string) *CommitGenerator {
 }
 
 func (g *CommitGenerator) GenerateCommitMessage(diff string) (string, error) {
-	prompt := fmt.Sprintf(`Generate a conventional commit message for the following git diff:
+	conventions := DefaultConventions()
+	if g.Conventions != nil {
+		conventions = *g.Conventions
+	}
+	prompt := fmt.Sprintf(`Generate a commit message for the following git diff:
 
 %s
 
 The commit message should:
-1. Follow the format: <type>(<optional scope>): <description>
-2. Use one of these types: feat, fix, docs, style, refactor, test, chore
-3. Be concise but descriptive
-4. Focus on the "what" and "why" rather than the "how"
-5. Use imperative mood ("add" not "added")
+%s
 
-Return only the commit message without any additional text.`, diff)
+Return only the commit message without any additional text.`, diff, conventions.Prompt())
 
 	resp, err := g.client.CreateChatCompletion(
 		context.Background(),
@@ -0,0 +1,263 @@
+package ai
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Conventions describe how a repository writes its commit messages, so that
+// generated messages match its history
+type Conventions struct {
+	// Conventional is set when subjects look like "type(scope): summary"
+	Conventional bool
+	// Types and Scopes are the conventional types and scopes to use, the
+	// most frequent first
+	Types  []string
+	Scopes []string
+	// Mood is "imperative", "past" or "present", as in Add, Added or Adds
+	Mood string
+	// Lowercase is set when summaries start with a lowercase letter
+	Lowercase bool
+	// Emoji is "unicode" or "shortcode" when subjects start with an emoji,
+	// as in ✨ or :sparkles:
+	Emoji string
+	// EmojiChoices are the emoji to choose from, each with what it stands
+	// for, e.g. "✨ Introduce new features"
+	EmojiChoices []string
+	// TicketFormat places ticket IDs in the subject, e.g. "[%s] " at the
+	// start or " (%s)" at the end
+	TicketFormat string
+	TicketPrefix bool
+	// Ticket is the ticket the change belongs to, if known
+	Ticket string
+	// Examples are recent subjects shown to the model
+	Examples []string
+	// Instructions are extra rules, from the configuration
+	Instructions string
+}
+
+// MaxConventionExamples is the number of recent subjects InferConventions
+// keeps as examples
+const MaxConventionExamples = 8
+
+// maxConventionScopes is the number of scopes listed in the prompt
+const maxConventionScopes = 12
+
+// DefaultConventions are the rules used when the history doesn't show any
+func DefaultConventions() Conventions {
+	return Conventions{
+		Conventional: true,
+		Types:        []string{"feat", "fix", "docs", "style", "refactor", "test", "chore"},
+		Mood:         "imperative",
+		Lowercase:    true,
+	}
+}
+
+var (
+	conventionalSubject = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?!?:\s*(.*)$`)
+	emojiShortcode      = regexp.MustCompile(`^:[a-z0-9_+-]+:\s*`)
+	ticketID            = regexp.MustCompile(`[A-Z][A-Z0-9]+-[0-9]+`)
+)
+
+// InferConventions works out the conventions of a repository from the
+// subjects of its recent commits, newest first. A style counts when most
+// subjects follow it.
+func InferConventions(subjects []string) Conventions {
+	var c Conventions
+	types, scopes := map[string]int{}, map[string]int{}
+	emoji, tickets, moods := map[string]int{}, map[string]int{}, map[string]int{}
+	conventional, lowercase, total := 0, 0, 0
+
+	for _, subject := range subjects {
+		subject = strings.TrimSpace(subject)
+		if subject == "" || strings.HasPrefix(subject, "Merge ") || strings.HasPrefix(subject, "Revert ") ||
+			strings.HasPrefix(subject, "fixup! ") || strings.HasPrefix(subject, "squash! ") {
+			continue
+		}
+		total++
+		if len(c.Examples) < MaxConventionExamples {
+			c.Examples = append(c.Examples, subject)
+		}
+
+		rest := subject
+		if style, stripped := leadingEmoji(rest); style != "" {
+			emoji[style]++
+			rest = stripped
+		}
+		if format, prefix, stripped := ticketPlacement(rest); format != "" {
+			if prefix {
+				format = "prefix:" + format
+			}
+			tickets[format]++
+			rest = stripped
+		}
+		if matches := conventionalSubject.FindStringSubmatch(rest); matches != nil {
+			conventional++
+			types[strings.ToLower(matches[1])]++
+			if matches[2] != "" {
+				scopes[matches[2]]++
+			}
+			rest = matches[3]
+		}
+
+		word, _, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		if r, _ := utf8.DecodeRuneInString(word); unicode.IsLower(r) {
+			lowercase++
+		}
+		moods[mood(word)]++
+	}
+	if total == 0 {
+		return DefaultConventions()
+	}
+
+	most := func(n int) bool { return n*2 > total }
+	c.Conventional = most(conventional)
+	if c.Conventional {
+		c.Types = byFrequency(types, 0)
+		c.Scopes = byFrequency(scopes, maxConventionScopes)
+	}
+	c.Lowercase = most(lowercase)
+	if style := byFrequency(emoji, 1); len(style) > 0 && most(emoji[style[0]]) {
+		c.Emoji = style[0]
+	}
+	if format := byFrequency(tickets, 1); len(format) > 0 && most(tickets[format[0]]) {
+		c.TicketFormat, c.TicketPrefix = strings.CutPrefix(format[0], "prefix:")
+	}
+	c.Mood = byFrequency(moods, 1)[0]
+	return c
+}
+
+// leadingEmoji reports the emoji a subject starts with, if any, and returns
+// the subject without it
+func leadingEmoji(subject string) (string, string) {
+	if match := emojiShortcode.FindString(subject); match != "" {
+		return "shortcode", subject[len(match):]
+	}
+	r, size := utf8.DecodeRuneInString(subject)
+	if unicode.Is(unicode.So, r) || r >= 0x1F000 && r <= 0x1FAFF || r >= 0x2600 && r <= 0x27BF {
+		rest := strings.TrimPrefix(subject[size:], "\uFE0F")
+		return "unicode", strings.TrimSpace(rest)
+	}
+	return "", subject
+}
+
+// ticketPlacement finds a ticket ID at the start or end of a subject and
+// returns how it is written there, e.g. "[%s] ", whether it is at the start,
+// and the subject without it
+func ticketPlacement(subject string) (string, bool, string) {
+	loc := ticketID.FindStringIndex(subject)
+	if loc == nil {
+		return "", false, subject
+	}
+	before, after := subject[:loc[0]], subject[loc[1]:]
+	if strings.Trim(before, "[(# ") == "" {
+		closing := len(after) - len(strings.TrimLeft(after, "]):# "))
+		return before + "%s" + after[:closing], true, after[closing:]
+	}
+	if strings.Trim(after, "]) ") == "" {
+		i := strings.LastIndexFunc(before, func(r rune) bool { return !strings.ContainsRune("[(#- ", r) })
+		return before[i+1:] + "%s" + after, false, before[:i+1]
+	}
+	return "", false, subject
+}
+
+// mood classifies the first word of a summary
+func mood(word string) string {
+	word = strings.ToLower(strings.TrimRight(word, ".,:;!"))
+	switch {
+	case len(word) > 3 && strings.HasSuffix(word, "ed"):
+		return "past"
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return "present"
+	default:
+		return "imperative"
+	}
+}
+
+// byFrequency returns the keys of counts, the most frequent first, keeping
+// at most max of them when max > 0
+func byFrequency(counts map[string]int, max int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if max > 0 && len(keys) > max {
+		keys = keys[:max]
+	}
+	return keys
+}
+
+// Rules turns the conventions into instructions for the model
+func (c Conventions) Rules() []string {
+	var rules []string
+	if c.Conventional {
+		rules = append(rules, "Follow the format: <type>(<optional scope>): <description>")
+		if len(c.Types) > 0 {
+			rules = append(rules, "Use one of these types: "+strings.Join(c.Types, ", "))
+		}
+		if len(c.Scopes) > 0 {
+			rules = append(rules, "Prefer these scopes when one fits: "+strings.Join(c.Scopes, ", "))
+		}
+	} else {
+		rules = append(rules, `Write a plain summary line, without a conventional commit prefix such as "feat:"`)
+	}
+	switch {
+	case len(c.EmojiChoices) > 0:
+		rules = append(rules, "Start the subject with the emoji from this list that fits the change best:\n   "+
+			strings.Join(c.EmojiChoices, "\n   "))
+	case c.Emoji == "unicode":
+		rules = append(rules, "Start the subject with an emoji that fits the change, such as ✨ or 🐛")
+	case c.Emoji == "shortcode":
+		rules = append(rules, "Start the subject with a gitmoji shortcode that fits the change, such as :sparkles: or :bug:")
+	}
+	if c.TicketFormat != "" && c.Ticket != "" {
+		where := "end"
+		if c.TicketPrefix {
+			where = "start"
+		}
+		rules = append(rules, fmt.Sprintf("Put the ticket at the %s of the subject as %q", where, fmt.Sprintf(c.TicketFormat, c.Ticket)))
+	}
+	rules = append(rules, "Be concise but descriptive", `Focus on the "what" and "why" rather than the "how"`)
+	switch c.Mood {
+	case "past":
+		rules = append(rules, `Use the past tense ("added" not "add")`)
+	case "present":
+		rules = append(rules, `Use the present tense ("adds" not "add")`)
+	default:
+		rules = append(rules, `Use imperative mood ("add" not "added")`)
+	}
+	if c.Lowercase {
+		rules = append(rules, "Start the description with a lowercase letter")
+	} else {
+		rules = append(rules, "Start the description with a capital letter")
+	}
+	if c.Instructions != "" {
+		rules = append(rules, strings.TrimSpace(c.Instructions))
+	}
+	return rules
+}
+
+// Prompt returns the rules as a numbered list, followed by the examples
+func (c Conventions) Prompt() string {
+	var prompt strings.Builder
+	for i, rule := range c.Rules() {
+		fmt.Fprintf(&prompt, "%d. %s\n", i+1, rule)
+	}
+	if len(c.Examples) > 0 {
+		prompt.WriteString("\nRecent commit messages of the repository, to match their style:\n")
+		for _, example := range c.Examples {
+			prompt.WriteString("- " + example + "\n")
+		}
+	}
+	return strings.TrimRight(prompt.String(), "\n")
+}
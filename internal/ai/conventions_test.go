@@ -0,0 +1,86 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferConventions(t *testing.T) {
+	tests := []struct {
+		name     string
+		subjects []string
+		want     Conventions
+	}{
+		{
+			name: "conventional with scopes",
+			subjects: []string{
+				"feat(api): add search endpoint", "fix(api): handle empty query", "fix(cli): exit on error",
+				"Merge pull request #12 from acme/search", "docs: describe search", "Add forgotten file",
+			},
+			want: Conventions{
+				Conventional: true, Types: []string{"fix", "docs", "feat"}, Scopes: []string{"api", "cli"},
+				Mood: "imperative", Lowercase: true,
+			},
+		},
+		{
+			name:     "gitmoji and tickets in brackets",
+			subjects: []string{":sparkles: [WEB-12] Added search", ":bug: [WEB-13] Fixed crash", ":memo: Updated docs"},
+			want:     Conventions{Emoji: "shortcode", TicketFormat: "[%s] ", TicketPrefix: true, Mood: "past"},
+		},
+		{
+			name:     "unicode emoji and tickets at the end",
+			subjects: []string{"✨ Adds search (WEB-12)", "🐛 Fixes crash (WEB-13)", "♻️ Cleans up"},
+			want:     Conventions{Emoji: "unicode", TicketFormat: " (%s)", Mood: "present"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := InferConventions(tt.subjects)
+			got.Examples = nil
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.Equal(t, DefaultConventions(), InferConventions([]string{"Merge branch 'main'"}))
+	c := InferConventions([]string{"a: 1", "a: 2", "a: 3", "a: 4", "a: 5", "a: 6", "a: 7", "a: 8", "a: 9"})
+	assert.Len(t, c.Examples, MaxConventionExamples)
+}
+
+func TestConventionsPrompt(t *testing.T) {
+	c := Conventions{Emoji: "shortcode", TicketFormat: "[%s] ", TicketPrefix: true, Ticket: "WEB-7", Mood: "past",
+		Examples: []string{":bug: [WEB-13] Fixed crash"}, Instructions: "Keep it short"}
+	prompt := c.Prompt()
+	assert.Contains(t, prompt, "1. Write a plain summary line")
+	assert.Contains(t, prompt, ":sparkles:")
+	assert.Contains(t, prompt, `Put the ticket at the start of the subject as "[WEB-7] "`)
+	assert.Contains(t, prompt, `Use the past tense`)
+	assert.Contains(t, prompt, "Start the description with a capital letter")
+	assert.Contains(t, prompt, "Keep it short\n\nRecent commit messages")
+	assert.Contains(t, prompt, "- :bug: [WEB-13] Fixed crash")
+
+	prompt = DefaultConventions().Prompt()
+	assert.Contains(t, prompt, "1. Follow the format: <type>(<optional scope>): <description>\n2. Use one of these types: feat, fix")
+	assert.NotContains(t, prompt, "ticket")
+}
+
+func TestGenerateCommitMessageWithConventions(t *testing.T) {
+	mockClient := &mockOpenAIClient{}
+	conventions := Conventions{Mood: "imperative", Scopes: []string{"api"}, Conventional: true}
+	generator := &CommitGenerator{client: mockClient, Conventions: &conventions}
+
+	mockClient.On("CreateChatCompletion", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+		return strings.Contains(req.Messages[0].Content, "Prefer these scopes when one fits: api")
+	})).Return(openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "fix(api): handle nil\n"}}},
+	}, nil)
+
+	msg, err := generator.GenerateCommitMessage("diff")
+	require.NoError(t, err)
+	assert.Equal(t, "fix(api): handle nil", msg)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+type Explainer struct {
+	client openAIClient
+}
+
+func NewExplainer(apiKey string) *Explainer {
+	return &Explainer{
+		client: openai.NewClient(apiKey),
+	}
+}
+
+// Explain explains a git problem in plain English and suggests the commands
+// that fix it. problem is the error message or the detected repository
+// state, status the output of git status and commands the githelper
+// commands that may be suggested.
+func (e *Explainer) Explain(problem, status, commands string) (string, error) {
+	prompt := fmt.Sprintf(`A git user needs help with this problem:
+
+%s
+
+The output of "git status" in their repository:
+
+%s
+
+Besides git, they can use githelper, which has these commands:
+
+%s
+
+Explain in plain English, in a few sentences and without jargon, what the
+problem means and how it happened. Then list the exact commands that fix it,
+one per line, each followed by a short comment starting with "#", preferring
+githelper commands when one fits. Warn before any command that can lose work.`, problem, status, commands)
+
+	resp, err := e.client.CreateChatCompletion(
+		context.Background(),
+		openai.ChatCompletionRequest{
+			Model: openai.GPT4,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: prompt,
+				},
+			},
+			Temperature: 0.2,
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate explanation: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("failed to generate explanation: empty response")
+	}
+
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,33 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplain(t *testing.T) {
+	mockClient := &mockOpenAIClient{}
+	explainer := &Explainer{client: mockClient}
+
+	mockClient.On("CreateChatCompletion", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+		content := req.Messages[0].Content
+		return strings.Contains(content, "! [rejected] main -> main (fetch first)") &&
+			strings.Contains(content, "## main...origin/main [ahead 1, behind 2]") &&
+			strings.Contains(content, "sync: Safely sync local and remote changes")
+	})).Return(openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{
+			{Message: openai.ChatCompletionMessage{Content: "  Someone pushed first.\n\ngithelper sync  # merge their commits\n"}},
+		},
+	}, nil)
+
+	explanation, err := explainer.Explain("! [rejected] main -> main (fetch first)",
+		"## main...origin/main [ahead 1, behind 2]", "sync: Safely sync local and remote changes")
+	require.NoError(t, err)
+	assert.Equal(t, "Someone pushed first.\n\ngithelper sync  # merge their commits", explanation)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,27 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ErrInvalidKey is returned when the AI provider rejects an API key
+var ErrInvalidKey = errors.New("the API key was rejected")
+
+// CheckKey makes a cheap request to the AI provider to tell whether apiKey
+// works
+func CheckKey(ctx context.Context, apiKey string) error {
+	_, err := openai.NewClient(apiKey).ListModels(ctx)
+	if err == nil {
+		return nil
+	}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode == http.StatusUnauthorized {
+		return ErrInvalidKey
+	}
+	return fmt.Errorf("failed to reach the AI provider: %w", err)
+}
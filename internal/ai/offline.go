@@ -0,0 +1,162 @@
+package ai
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FileStat describes a single changed file in a diff
+type FileStat struct {
+	Path    string
+	Status  string
+	Added   int
+	Deleted int
+}
+
+// ParseNumstat parses the output of `git diff --numstat` combined with the
+// status letters from `git diff --name-status`.
+func ParseNumstat(numstat, nameStatus string) []FileStat {
+	statuses := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(nameStatus), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		statuses[fields[len(fields)-1]] = fields[0][:1]
+	}
+
+	var stats []FileStat
+	for _, line := range strings.Split(strings.TrimSpace(numstat), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 3 {
+			continue
+		}
+		// Binary files report "-" for both counts
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		path := fields[len(fields)-1]
+		status := statuses[path]
+		if status == "" {
+			status = "M"
+		}
+		stats = append(stats, FileStat{Path: path, Status: status, Added: added, Deleted: deleted})
+	}
+	return stats
+}
+
+// ChangeType maps a file path to the conventional commit type it most likely belongs to
+func ChangeType(file string) string {
+	base := path.Base(file)
+	ext := path.Ext(file)
+
+	switch {
+	case strings.HasSuffix(base, "_test.go") || strings.Contains(file, "/testdata/") ||
+		strings.Contains(base, ".test.") || strings.Contains(base, ".spec."):
+		return "test"
+	case ext == ".md" || ext == ".txt" || ext == ".rst" || strings.HasPrefix(file, "doc/") || strings.HasPrefix(file, "docs/"):
+		return "docs"
+	case base == "go.mod" || base == "go.sum" || base == "Makefile" || base == "Dockerfile" ||
+		base == "package.json" || base == ".gitignore" || strings.HasPrefix(file, ".github/") ||
+		ext == ".yaml" || ext == ".yml" || ext == ".toml" || ext == ".nix":
+		return "chore"
+	default:
+		return "feat"
+	}
+}
+
+// OfflineCommitMessage derives a conventional commit message from file paths
+// and diff stats without calling any AI provider. The result is deterministic
+// for a given set of changes.
+func OfflineCommitMessage(stats []FileStat) string {
+	if len(stats) == 0 {
+		return "chore: update files"
+	}
+
+	// Pick the type that accounts for the most changed lines
+	weights := make(map[string]int)
+	for _, stat := range stats {
+		weights[ChangeType(stat.Path)] += stat.Added + stat.Deleted + 1
+	}
+	types := make([]string, 0, len(weights))
+	for t := range weights {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if weights[types[i]] != weights[types[j]] {
+			return weights[types[i]] > weights[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	changeType := types[0]
+
+	allAdded, allDeleted := true, true
+	for _, stat := range stats {
+		allAdded = allAdded && stat.Status == "A"
+		allDeleted = allDeleted && stat.Status == "D"
+	}
+
+	verb := "update"
+	switch {
+	case allAdded:
+		verb = "add"
+	case allDeleted:
+		verb = "remove"
+	}
+	// Modifying existing code is usually a fix or refactor rather than a feature
+	if changeType == "feat" && !allAdded {
+		changeType = "refactor"
+		if totalDeleted(stats) == 0 {
+			changeType = "feat"
+		}
+	}
+
+	object := fmt.Sprintf("%d files", len(stats))
+	if len(stats) == 1 {
+		object = path.Base(stats[0].Path)
+	}
+
+	header := fmt.Sprintf("%s: %s %s", changeType, verb, object)
+	if scope := commonScope(stats); scope != "" {
+		header = fmt.Sprintf("%s(%s): %s %s", changeType, scope, verb, object)
+	}
+
+	if len(stats) == 1 {
+		return header
+	}
+
+	var body strings.Builder
+	body.WriteString(header)
+	body.WriteString("\n\n")
+	for _, stat := range stats {
+		fmt.Fprintf(&body, "- %s (+%d/-%d)\n", stat.Path, stat.Added, stat.Deleted)
+	}
+	return strings.TrimSpace(body.String())
+}
+
+func totalDeleted(stats []FileStat) int {
+	total := 0
+	for _, stat := range stats {
+		total += stat.Deleted
+	}
+	return total
+}
+
+// commonScope returns the innermost directory shared by all changed files
+func commonScope(stats []FileStat) string {
+	common := strings.Split(path.Dir(stats[0].Path), "/")
+	for _, stat := range stats[1:] {
+		parts := strings.Split(path.Dir(stat.Path), "/")
+		n := 0
+		for n < len(common) && n < len(parts) && common[n] == parts[n] {
+			n++
+		}
+		common = common[:n]
+	}
+	if len(common) == 0 || (len(common) == 1 && common[0] == ".") {
+		return ""
+	}
+	return common[len(common)-1]
+}
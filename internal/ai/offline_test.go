@@ -0,0 +1,56 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNumstat(t *testing.T) {
+	numstat := "10\t2\tcmd/commit.go\n-\t-\tlogo.png\n"
+	nameStatus := "M\tcmd/commit.go\nA\tlogo.png\n"
+
+	stats := ParseNumstat(numstat, nameStatus)
+	assert.Equal(t, []FileStat{
+		{Path: "cmd/commit.go", Status: "M", Added: 10, Deleted: 2},
+		{Path: "logo.png", Status: "A"},
+	}, stats)
+}
+
+func TestOfflineCommitMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats []FileStat
+		want  string
+	}{
+		{
+			name:  "no changes",
+			stats: nil,
+			want:  "chore: update files",
+		},
+		{
+			name:  "single new file",
+			stats: []FileStat{{Path: "cmd/split.go", Status: "A", Added: 40}},
+			want:  "feat(cmd): add split.go",
+		},
+		{
+			name:  "docs change",
+			stats: []FileStat{{Path: "README.md", Status: "M", Added: 3, Deleted: 1}},
+			want:  "docs: update README.md",
+		},
+		{
+			name: "refactor across package",
+			stats: []FileStat{
+				{Path: "internal/ai/commit.go", Status: "M", Added: 5, Deleted: 5},
+				{Path: "internal/ai/cache.go", Status: "M", Added: 1, Deleted: 3},
+			},
+			want: "refactor(ai): update 2 files\n\n- internal/ai/commit.go (+5/-5)\n- internal/ai/cache.go (+1/-3)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, OfflineCommitMessage(tt.stats))
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// MaxPRDiff is the size above which the diff sent for a pull request
+// description is cut, to stay within the model's context
+const MaxPRDiff = 40000
+
+// PRDescription is a drafted pull request title and body
+type PRDescription struct {
+	Title    string
+	Summary  string
+	TestPlan string
+}
+
+// Body renders the summary and test plan as a markdown pull request body
+func (d *PRDescription) Body() string {
+	return fmt.Sprintf("## Summary\n\n%s\n\n## Test plan\n\n%s\n", strings.TrimSpace(d.Summary), strings.TrimSpace(d.TestPlan))
+}
+
+type PRGenerator struct {
+	client openAIClient
+}
+
+func NewPRGenerator(apiKey string) *PRGenerator {
+	return &PRGenerator{
+		client: openai.NewClient(apiKey),
+	}
+}
+
+// GeneratePRDescription drafts a pull request description from the commit
+// log and the diff of a branch
+func (g *PRGenerator) GeneratePRDescription(commits, diff string) (*PRDescription, error) {
+	prompt := fmt.Sprintf(`Write a pull request description for a branch with these commits:
+
+%s
+
+and this diff:
+
+%s
+
+Answer in exactly this format:
+TITLE: <one line, imperative mood, under 70 characters>
+## Summary
+<what the change does and why, as a few markdown bullet points>
+## Test plan
+<how the change can be verified, as markdown bullet points>
+
+Return only the description without any additional text.`, commits, TruncateDiff(diff, MaxPRDiff))
+
+	resp, err := g.client.CreateChatCompletion(
+		context.Background(),
+		openai.ChatCompletionRequest{
+			Model: openai.GPT4,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: prompt,
+				},
+			},
+			Temperature: 0.7,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate pull request description: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("failed to generate pull request description: empty response")
+	}
+
+	return ParsePRDescription(resp.Choices[0].Message.Content)
+}
+
+// ParsePRDescription splits a generated description into its title, summary
+// and test plan
+func ParsePRDescription(text string) (*PRDescription, error) {
+	desc := &PRDescription{}
+	var section *strings.Builder
+	var summary, testPlan strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		trimmed := strings.TrimSpace(line)
+		heading := strings.ToLower(strings.TrimLeft(trimmed, "# "))
+		switch {
+		case desc.Title == "" && strings.HasPrefix(strings.ToUpper(trimmed), "TITLE:"):
+			desc.Title = strings.TrimSpace(trimmed[len("TITLE:"):])
+		case strings.HasPrefix(trimmed, "#") && heading == "summary":
+			section = &summary
+		case strings.HasPrefix(trimmed, "#") && (heading == "test plan" || heading == "testing"):
+			section = &testPlan
+		case section != nil:
+			section.WriteString(line + "\n")
+		}
+	}
+	desc.Summary = strings.TrimSpace(summary.String())
+	desc.TestPlan = strings.TrimSpace(testPlan.String())
+
+	if desc.Title == "" || desc.Summary == "" {
+		return nil, fmt.Errorf("unexpected pull request description format: %s", text)
+	}
+	return desc, nil
+}
+
+// TruncateDiff cuts a diff at a line boundary once it exceeds max bytes
+func TruncateDiff(diff string, max int) string {
+	if len(diff) <= max {
+		return diff
+	}
+	cut := strings.LastIndex(diff[:max], "\n")
+	if cut < 0 {
+		cut = max
+	}
+	return diff[:cut] + "\n[... diff truncated ...]\n"
+}
+
+// OfflinePRDescription derives a pull request description from the commit
+// subjects and diff stats of a branch without calling any AI provider
+func OfflinePRDescription(subjects []string, stats []FileStat) *PRDescription {
+	desc := &PRDescription{}
+	if len(subjects) == 1 {
+		desc.Title = subjects[0]
+	} else {
+		desc.Title = OfflineCommitMessage(stats)
+	}
+
+	var summary strings.Builder
+	for _, subject := range subjects {
+		summary.WriteString("- " + subject + "\n")
+	}
+	added, deleted := 0, 0
+	for _, stat := range stats {
+		added += stat.Added
+		deleted += stat.Deleted
+	}
+	fmt.Fprintf(&summary, "\n%d file(s) changed, +%d -%d", len(stats), added, deleted)
+	desc.Summary = summary.String()
+
+	var tests []string
+	for _, stat := range stats {
+		if ChangeType(stat.Path) == "test" {
+			tests = append(tests, "`"+stat.Path+"`")
+		}
+	}
+	if len(tests) > 0 {
+		desc.TestPlan = "- Updated tests: " + strings.Join(tests, ", ")
+	} else {
+		desc.TestPlan = "- Describe how the change was tested"
+	}
+	return desc
+}
@@ -0,0 +1,66 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePRDescription(t *testing.T) {
+	mockClient := &mockOpenAIClient{}
+	generator := &PRGenerator{client: mockClient}
+
+	mockClient.On("CreateChatCompletion", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+		return strings.Contains(req.Messages[0].Content, "abc123 Add retries") &&
+			strings.Contains(req.Messages[0].Content, "+retries := 3")
+	})).Return(openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{
+			{
+				Message: openai.ChatCompletionMessage{
+					Content: "TITLE: Retry failed uploads\n\n## Summary\n- Retry uploads three times\n\n## Test plan\n- Run the upload tests\n",
+				},
+			},
+		},
+	}, nil)
+
+	desc, err := generator.GeneratePRDescription("abc123 Add retries", "+retries := 3")
+	require.NoError(t, err)
+	assert.Equal(t, &PRDescription{
+		Title:    "Retry failed uploads",
+		Summary:  "- Retry uploads three times",
+		TestPlan: "- Run the upload tests",
+	}, desc)
+	assert.Equal(t, "## Summary\n\n- Retry uploads three times\n\n## Test plan\n\n- Run the upload tests\n", desc.Body())
+	mockClient.AssertExpectations(t)
+}
+
+func TestParsePRDescription(t *testing.T) {
+	_, err := ParsePRDescription("Just some text")
+	assert.Error(t, err)
+
+	desc, err := ParsePRDescription("Title: Fix login\n# Summary\nFixes the login loop.\n### Testing\nManual.")
+	require.NoError(t, err)
+	assert.Equal(t, "Fix login", desc.Title)
+	assert.Equal(t, "Fixes the login loop.", desc.Summary)
+	assert.Equal(t, "Manual.", desc.TestPlan)
+}
+
+func TestTruncateDiff(t *testing.T) {
+	assert.Equal(t, "short", TruncateDiff("short", 10))
+	assert.Equal(t, "line one\n[... diff truncated ...]\n", TruncateDiff("line one\nline two\n", 12))
+}
+
+func TestOfflinePRDescription(t *testing.T) {
+	stats := []FileStat{
+		{Path: "cmd/upload.go", Status: "M", Added: 10, Deleted: 2},
+		{Path: "cmd/upload_test.go", Status: "M", Added: 5},
+	}
+	desc := OfflinePRDescription([]string{"Retry failed uploads"}, stats)
+	assert.Equal(t, "Retry failed uploads", desc.Title)
+	assert.Equal(t, "- Retry failed uploads\n\n2 file(s) changed, +15 -2", desc.Summary)
+	assert.Equal(t, "- Updated tests: `cmd/upload_test.go`", desc.TestPlan)
+}
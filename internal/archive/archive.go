@@ -0,0 +1,239 @@
+// Package archive packs a repository export (git bundle, LFS objects and
+// GitHub metadata) into a single .tar.gz file and unpacks it again.
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Layout of an archive
+const (
+	ManifestFile = "manifest.json"
+	BundleFile   = "repo.bundle"
+	LFSDir       = "lfs"
+	MetadataDir  = "metadata"
+)
+
+var ErrUnsafePath = errors.New("archive entry escapes the target directory")
+
+// Manifest describes the contents of an archive
+type Manifest struct {
+	Name      string    `json:"name"`
+	Remote    string    `json:"remote,omitempty"`
+	Head      string    `json:"head,omitempty"`
+	Created   time.Time `json:"created"`
+	CreatedBy string    `json:"created_by"`
+	LFS       bool      `json:"lfs"`
+	Metadata  []string  `json:"metadata,omitempty"`
+}
+
+// WriteManifest saves the manifest in dir
+func WriteManifest(dir string, manifest Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ManifestFile), data, 0644)
+}
+
+// ReadManifest loads the manifest from dir
+func ReadManifest(dir string) (Manifest, error) {
+	var manifest Manifest
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return manifest, fmt.Errorf("not a githelper archive: %w", err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("invalid archive manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// Pack writes the contents of dir to a gzip-compressed tarball at file
+func Pack(dir, file string) error {
+	return writeArchive(file, func(tw *tar.Writer) error {
+		return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil || rel == "." {
+				return err
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			return addEntry(tw, path, rel, info)
+		})
+	})
+}
+
+// PackFiles writes the listed files, relative to root, to a gzip-compressed
+// tarball at file. Entries that aren't regular files are skipped.
+func PackFiles(root string, files []string, file string) error {
+	return writeArchive(file, func(tw *tar.Writer) error {
+		for _, name := range files {
+			path := filepath.Join(root, name)
+			info, err := os.Lstat(path)
+			if err != nil {
+				return err
+			}
+			if err := addEntry(tw, path, name, info); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeArchive creates a tarball at file with the entries written by fill,
+// removing it when anything fails
+func writeArchive(file string, fill func(tw *tar.Writer) error) (err error) {
+	out, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(file)
+		}
+	}()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	if err := fill(tw); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return gz.Close()
+}
+
+// addEntry writes the file or directory at path to the tarball as name
+func addEntry(tw *tar.Writer, path, name string, info fs.FileInfo) error {
+	if !info.Mode().IsRegular() && !info.IsDir() {
+		return nil
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.ToSlash(name)
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	_, err = io.Copy(tw, src)
+	return err
+}
+
+// Unpack extracts a tarball created by Pack into dir
+func Unpack(file, dir string) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer in.Close()
+
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("%w: %s", ErrUnsafePath, header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := writeFile(target, tr, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader, mode fs.FileMode) error {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// CopyDir copies the files below src into dst, creating directories as needed
+func CopyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		return writeFile(target, in, 0644)
+	})
+}
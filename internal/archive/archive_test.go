@@ -0,0 +1,78 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackUnpack(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, BundleFile), []byte("bundle"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, LFSDir, "objects", "ab"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, LFSDir, "objects", "ab", "abcd"), []byte("lfs"), 0644))
+	manifest := Manifest{Name: "widget", Remote: "https://github.com/acme/widget.git", Created: time.Now().UTC(), LFS: true}
+	require.NoError(t, WriteManifest(src, manifest))
+
+	file := filepath.Join(t.TempDir(), "widget.tar.gz")
+	require.NoError(t, Pack(src, file))
+
+	dst := t.TempDir()
+	require.NoError(t, Unpack(file, dst))
+
+	data, err := os.ReadFile(filepath.Join(dst, LFSDir, "objects", "ab", "abcd"))
+	require.NoError(t, err)
+	assert.Equal(t, "lfs", string(data))
+
+	restored, err := ReadManifest(dst)
+	require.NoError(t, err)
+	assert.Equal(t, manifest.Name, restored.Name)
+	assert.Equal(t, manifest.Remote, restored.Remote)
+	assert.True(t, restored.LFS)
+}
+
+func TestUnpackRejectsUnsafePaths(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "evil.tar.gz")
+	out, err := os.Create(file)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../escape", Mode: 0644, Size: 1, Typeflag: tar.TypeReg}))
+	_, err = tw.Write([]byte("x"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, out.Close())
+
+	dst := t.TempDir()
+	assert.ErrorIs(t, Unpack(file, dst), ErrUnsafePath)
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(dst), "escape"))
+}
+
+func TestReadManifestMissing(t *testing.T) {
+	_, err := ReadManifest(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestPackFiles(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "notes"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "notes", "todo.md"), []byte("todo"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "skipped.txt"), []byte("skipped"), 0644))
+
+	file := filepath.Join(t.TempDir(), "files.tar.gz")
+	require.NoError(t, PackFiles(root, []string{"notes/todo.md"}, file))
+
+	dst := t.TempDir()
+	require.NoError(t, Unpack(file, dst))
+	data, err := os.ReadFile(filepath.Join(dst, "notes", "todo.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "todo", string(data))
+	assert.NoFileExists(t, filepath.Join(dst, "skipped.txt"))
+}
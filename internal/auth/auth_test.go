@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zalando/go-keyring"
+)
+
+func TestDeviceFlow(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client-id", r.Form.Get("client_id"))
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/login/device/code":
+			w.Write([]byte(`{"device_code":"dev","user_code":"ABCD-1234","verification_uri":"https://github.com/login/device","expires_in":900,"interval":5}`))
+		case "/login/oauth/access_token":
+			assert.Equal(t, "dev", r.Form.Get("device_code"))
+			polls++
+			if polls < 2 {
+				w.Write([]byte(`{"error":"authorization_pending"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"gho_token"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	flow := NewDeviceFlow("github.com", "client-id")
+	flow.BaseURL = server.URL
+
+	code, err := flow.RequestCode(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "ABCD-1234", code.UserCode)
+
+	// Don't wait between polls in the test
+	code.Interval = 0
+	token, err := flow.WaitForToken(context.Background(), code)
+	assert.NoError(t, err)
+	assert.Equal(t, "gho_token", token)
+	assert.Equal(t, 2, polls)
+}
+
+func TestDeviceFlowDenied(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":"access_denied"}`))
+	}))
+	defer server.Close()
+
+	flow := NewDeviceFlow("github.com", "client-id")
+	flow.BaseURL = server.URL
+
+	_, err := flow.WaitForToken(context.Background(), &DeviceCode{DeviceCode: "dev"})
+	assert.ErrorIs(t, err, ErrAccessDenied)
+}
+
+func TestStoreKeyring(t *testing.T) {
+	keyring.MockInit()
+	store := &Store{file: filepath.Join(t.TempDir(), "credentials.yaml")}
+
+	_, err := store.Get("github.com")
+	assert.ErrorIs(t, err, ErrNoToken)
+
+	source, err := store.Set("github.com", "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, SourceKeyring, source)
+
+	credential, err := store.Get("github.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", credential.Token)
+	assert.Equal(t, SourceKeyring, credential.Source)
+
+	assert.NoError(t, store.Delete("github.com"))
+	_, err = store.Get("github.com")
+	assert.ErrorIs(t, err, ErrNoToken)
+}
+
+func TestStoreFileFallback(t *testing.T) {
+	keyring.MockInitWithError(keyring.ErrUnsupportedPlatform)
+	store := &Store{file: filepath.Join(t.TempDir(), "credentials.yaml")}
+
+	source, err := store.Set("github.example.com", "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, SourceFile, source)
+	assert.Equal(t, []string{"github.example.com"}, store.Hosts())
+
+	info, err := os.Stat(store.file)
+	assert.NoError(t, err)
+	if filepath.Separator == '/' {
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	credential, err := store.Get("github.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", credential.Token)
+	assert.Equal(t, SourceFile, credential.Source)
+
+	assert.NoError(t, store.Delete("github.example.com"))
+	assert.Empty(t, store.Hosts())
+}
+
+func TestLookupPrefersConfiguredToken(t *testing.T) {
+	credential, err := Lookup(context.Background(), "github.com", "from-config")
+	assert.NoError(t, err)
+	assert.Equal(t, "from-config", credential.Token)
+	assert.Equal(t, SourceConfig, credential.Source)
+}
@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultClientID is the public client ID of git-helper's OAuth app on
+// github.com. Client IDs aren't secret, so it ships in the source.
+const DefaultClientID = ""
+
+// ClientID is the OAuth app used for the device flow. Builds can replace it
+// with -ldflags; users can override it with oauth_client_id in the config.
+var ClientID = DefaultClientID
+
+// DefaultScopes are requested when logging in
+var DefaultScopes = []string{"repo", "read:org", "gist"}
+
+var (
+	ErrAccessDenied = errors.New("authorization was denied")
+	ErrExpired      = errors.New("device code expired, please try again")
+)
+
+// DeviceCode is the code the user enters at the verification URL
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// DeviceFlow implements GitHub's OAuth device authorization flow
+type DeviceFlow struct {
+	// BaseURL is the web URL of the host, e.g. https://github.com
+	BaseURL  string
+	ClientID string
+	Scopes   []string
+	client   *http.Client
+}
+
+// NewDeviceFlow creates a device flow for the given host
+func NewDeviceFlow(host, clientID string) *DeviceFlow {
+	return &DeviceFlow{
+		BaseURL:  "https://" + host,
+		ClientID: clientID,
+		Scopes:   DefaultScopes,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// RequestCode starts the flow and returns the code to show to the user
+func (f *DeviceFlow) RequestCode(ctx context.Context) (*DeviceCode, error) {
+	form := url.Values{
+		"client_id": {f.ClientID},
+		"scope":     {strings.Join(f.Scopes, " ")},
+	}
+
+	var code DeviceCode
+	if err := f.post(ctx, "/login/device/code", form, &code); err != nil {
+		return nil, fmt.Errorf("failed to request device code: %w", err)
+	}
+	if code.DeviceCode == "" {
+		return nil, fmt.Errorf("failed to request device code: empty response")
+	}
+	if code.Interval == 0 {
+		code.Interval = 5
+	}
+	return &code, nil
+}
+
+// WaitForToken polls until the user authorizes the device and returns the token
+func (f *DeviceFlow) WaitForToken(ctx context.Context, code *DeviceCode) (string, error) {
+	interval := time.Duration(code.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	form := url.Values{
+		"client_id":   {f.ClientID},
+		"device_code": {code.DeviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+	}
+
+	for {
+		if code.ExpiresIn > 0 && time.Now().After(deadline) {
+			return "", ErrExpired
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(interval):
+		}
+
+		var response struct {
+			AccessToken string `json:"access_token"`
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+			Interval    int    `json:"interval"`
+		}
+		if err := f.post(ctx, "/login/oauth/access_token", form, &response); err != nil {
+			return "", fmt.Errorf("failed to get access token: %w", err)
+		}
+
+		switch response.Error {
+		case "":
+			return response.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			if response.Interval > 0 {
+				interval = time.Duration(response.Interval) * time.Second
+			} else {
+				interval += 5 * time.Second
+			}
+		case "expired_token":
+			return "", ErrExpired
+		case "access_denied":
+			return "", ErrAccessDenied
+		default:
+			return "", fmt.Errorf("failed to get access token: %s", response.Description)
+		}
+	}
+}
+
+func (f *DeviceFlow) post(ctx context.Context, path string, form url.Values, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.BaseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/EndlessUphill/git-helper/internal/git"
+	"github.com/zalando/go-keyring"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SourceGH            Source = "gh CLI"
+	SourceGitCredential Source = "git credential helper"
+)
+
+// ghToken returns the token the GitHub CLI uses for host: from its
+// environment variables, its keyring entry or its hosts.yml
+func ghToken(host string) (string, bool) {
+	envVars := []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	if host == "github.com" {
+		envVars = []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	}
+	for _, name := range envVars {
+		if token := os.Getenv(name); token != "" {
+			return token, true
+		}
+	}
+
+	var user string
+	data, err := os.ReadFile(filepath.Join(ghConfigDir(), "hosts.yml"))
+	if err == nil {
+		var hosts map[string]struct {
+			User       string `yaml:"user"`
+			OAuthToken string `yaml:"oauth_token"`
+		}
+		if yaml.Unmarshal(data, &hosts) == nil {
+			if entry, ok := hosts[host]; ok {
+				if entry.OAuthToken != "" {
+					return entry.OAuthToken, true
+				}
+				user = entry.User
+			}
+		}
+	}
+
+	// Newer gh versions keep the token in the keyring under "gh:<host>"
+	accounts := []string{""}
+	if user != "" {
+		accounts = append(accounts, user)
+	}
+	for _, account := range accounts {
+		if token, err := keyring.Get("gh:"+host, account); err == nil && token != "" {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+func ghConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("AppData"); dir != "" {
+			return filepath.Join(dir, "GitHub CLI")
+		}
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "gh")
+}
+
+// gitCredentialToken asks git's credential helpers (e.g. Git Credential
+// Manager or the macOS keychain helper) for the HTTPS password of host,
+// without letting them prompt
+func gitCredentialToken(ctx context.Context, host string) (string, bool) {
+	cmd := git.Command(ctx, "credential", "fill")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=https\nhost=%s\n\n", host))
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", false
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		if password, ok := strings.CutPrefix(scanner.Text(), "password="); ok && password != "" {
+			return password, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zalando/go-keyring"
+)
+
+// isolate points gh and git at empty configuration so the machine's real
+// credentials don't leak into the tests
+func isolate(t *testing.T) string {
+	keyring.MockInit()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir)
+	t.Setenv("GH_CONFIG_DIR", filepath.Join(dir, "gh"))
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(dir, "gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, name := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"} {
+		t.Setenv(name, "")
+	}
+	return dir
+}
+
+func TestGHToken(t *testing.T) {
+	dir := isolate(t)
+
+	_, ok := ghToken("github.com")
+	assert.False(t, ok)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "gh"), 0700))
+	hosts := "github.com:\n    user: octocat\n    oauth_token: gho_file\n    git_protocol: https\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "gh", "hosts.yml"), []byte(hosts), 0600))
+
+	token, ok := ghToken("github.com")
+	assert.True(t, ok)
+	assert.Equal(t, "gho_file", token)
+
+	t.Setenv("GH_TOKEN", "gho_env")
+	token, _ = ghToken("github.com")
+	assert.Equal(t, "gho_env", token)
+
+	_, ok = ghToken("github.example.com")
+	assert.False(t, ok)
+}
+
+func TestGHTokenFromKeyring(t *testing.T) {
+	isolate(t)
+	assert.NoError(t, keyring.Set("gh:github.example.com", "", "gho_keyring"))
+
+	token, ok := ghToken("github.example.com")
+	assert.True(t, ok)
+	assert.Equal(t, "gho_keyring", token)
+}
+
+func TestLookupFallsBackToGitCredential(t *testing.T) {
+	dir := isolate(t)
+
+	_, err := Lookup(context.Background(), "github.com", "")
+	assert.ErrorIs(t, err, ErrNoToken)
+
+	gitconfig := "[credential]\n\thelper = \"!f() { echo username=x-access-token; echo password=from-helper; }; f\"\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "gitconfig"), []byte(gitconfig), 0600))
+
+	credential, err := Lookup(context.Background(), "github.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "from-helper", credential.Token)
+	assert.Equal(t, SourceGitCredential, credential.Source)
+
+	// A token stored with 'auth login' comes first
+	store, err := NewStore()
+	assert.NoError(t, err)
+	_, err = store.Set("github.com", "stored")
+	assert.NoError(t, err)
+
+	credential, err = Lookup(context.Background(), "github.com", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "stored", credential.Token)
+}
@@ -0,0 +1,179 @@
+// Package auth finds and stores the access tokens githelper uses to talk to
+// GitHub hosts.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/zalando/go-keyring"
+	"gopkg.in/yaml.v3"
+)
+
+// keyringService is the service name tokens are stored under in the OS keychain
+const keyringService = "githelper"
+
+var ErrNoToken = errors.New("no token found")
+
+// Source describes where a token was found
+type Source string
+
+const (
+	SourceConfig  Source = "config file"
+	SourceKeyring Source = "keychain"
+	SourceFile    Source = "credentials file"
+)
+
+// Credential is a token for a host and where it came from
+type Credential struct {
+	Host   string
+	Token  string
+	Source Source
+}
+
+// Store saves tokens in the OS keychain, falling back to a YAML file readable
+// only by the user when no keychain is available (e.g. headless Linux)
+type Store struct {
+	file string
+}
+
+// NewStore creates a store backed by ~/.githelper/credentials.yaml
+func NewStore() (*Store, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return &Store{file: filepath.Join(home, ".githelper", "credentials.yaml")}, nil
+}
+
+// Get returns the stored token for host
+func (s *Store) Get(host string) (Credential, error) {
+	if token, err := keyring.Get(keyringService, host); err == nil && token != "" {
+		return Credential{Host: host, Token: token, Source: SourceKeyring}, nil
+	}
+
+	tokens, err := s.readFile()
+	if err != nil {
+		return Credential{}, err
+	}
+	if token := tokens[host]; token != "" {
+		return Credential{Host: host, Token: token, Source: SourceFile}, nil
+	}
+	return Credential{}, ErrNoToken
+}
+
+// Set stores the token for host and reports where it was saved
+func (s *Store) Set(host, token string) (Source, error) {
+	if err := keyring.Set(keyringService, host, token); err == nil {
+		// Don't leave an older plaintext copy behind
+		s.deleteFromFile(host)
+		return SourceKeyring, nil
+	}
+
+	tokens, err := s.readFile()
+	if err != nil {
+		return "", err
+	}
+	tokens[host] = token
+	if err := s.writeFile(tokens); err != nil {
+		return "", err
+	}
+	return SourceFile, nil
+}
+
+// Delete removes the stored token for host from both backends
+func (s *Store) Delete(host string) error {
+	keyringErr := keyring.Delete(keyringService, host)
+	fileErr := s.deleteFromFile(host)
+	if keyringErr != nil && fileErr != nil {
+		return ErrNoToken
+	}
+	return nil
+}
+
+// Hosts lists the hosts with a token in the credentials file. The keychain
+// can't be enumerated, so hosts only stored there are not included.
+func (s *Store) Hosts() []string {
+	tokens, err := s.readFile()
+	if err != nil {
+		return nil
+	}
+	hosts := make([]string, 0, len(tokens))
+	for host := range tokens {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+func (s *Store) deleteFromFile(host string) error {
+	tokens, err := s.readFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[host]; !ok {
+		return ErrNoToken
+	}
+	delete(tokens, host)
+	return s.writeFile(tokens)
+}
+
+func (s *Store) readFile() (map[string]string, error) {
+	tokens := make(map[string]string)
+	data, err := os.ReadFile(s.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return tokens, nil
+		}
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.file, err)
+	}
+	return tokens, nil
+}
+
+func (s *Store) writeFile(tokens map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(s.file), 0700); err != nil {
+		return fmt.Errorf("failed to create credentials directory: %w", err)
+	}
+	data, err := yaml.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.file, data, 0600); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the token for host, trying in order: the token from the
+// config (or environment), the one saved by 'githelper auth login', the GitHub
+// CLI's credentials and finally git's credential helpers
+func Lookup(ctx context.Context, host, configured string) (Credential, error) {
+	if configured != "" {
+		return Credential{Host: host, Token: configured, Source: SourceConfig}, nil
+	}
+
+	store, err := NewStore()
+	if err != nil {
+		return Credential{}, err
+	}
+	if credential, err := store.Get(host); err == nil {
+		return credential, nil
+	} else if !errors.Is(err, ErrNoToken) {
+		return Credential{}, err
+	}
+
+	if token, ok := ghToken(host); ok {
+		return Credential{Host: host, Token: token, Source: SourceGH}, nil
+	}
+	if token, ok := gitCredentialToken(ctx, host); ok {
+		return Credential{Host: host, Token: token, Source: SourceGitCredential}, nil
+	}
+	return Credential{}, ErrNoToken
+}
@@ -0,0 +1,234 @@
+// Package checkpoint records the working tree as commits on a shadow ref per
+// branch, so work between real commits can be brought back.
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/git"
+)
+
+// RefPrefix is the namespace of the shadow refs, one per branch
+const RefPrefix = "refs/githelper/checkpoints/"
+
+// DetachedRef names the shadow ref used while HEAD is detached
+const DetachedRef = "detached"
+
+var ErrNotFound = errors.New("checkpoint not found")
+
+// Checkpoint is a recorded state of the working tree
+type Checkpoint struct {
+	Commit string
+	Time   time.Time
+	// Head is the commit checked out when the checkpoint was made
+	Head string
+}
+
+// Store creates and restores the checkpoints of a working tree
+type Store struct {
+	root string
+	// index is a private index file, so checkpoints never touch the
+	// staging area
+	index string
+}
+
+// Open opens the checkpoints of the repository in the current directory
+func Open(ctx context.Context) (*Store, error) {
+	output, err := git.Command(ctx, "rev-parse", "--show-toplevel", "--path-format=absolute", "--git-path", "githelper/checkpoint-index").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git directory: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("checkpoints need a working tree")
+	}
+	return &Store{root: lines[0], index: lines[1]}, nil
+}
+
+// Ref returns the shadow ref of a branch, or of a detached HEAD for ""
+func Ref(branch string) string {
+	if branch == "" {
+		branch = DetachedRef
+	}
+	return RefPrefix + branch
+}
+
+// Save records the working tree, untracked files included and ignored files
+// excluded, as a new checkpoint of branch. It returns nil when nothing changed
+// since the last checkpoint, or since HEAD when there is none.
+func (s *Store) Save(ctx context.Context, branch string) (*Checkpoint, error) {
+	head, err := s.output(ctx, "rev-parse", "--verify", "-q", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("nothing to checkpoint: the repository has no commits")
+	}
+	tree, err := s.writeTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := Ref(branch)
+	parent, _ := s.output(ctx, "rev-parse", "--verify", "-q", ref)
+	previous := head
+	if parent != "" {
+		previous = parent
+	}
+	if previousTree, err := s.output(ctx, "rev-parse", previous+"^{tree}"); err == nil && previousTree == tree {
+		return nil, nil
+	}
+
+	args := []string{"commit-tree", tree, "-m", "checkpoint " + head}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	commit, err := s.output(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+	// The old value guards against another watcher moving the ref meanwhile
+	if err := s.git(ctx, "update-ref", "-m", "checkpoint", ref, commit, parent); err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", ref, err)
+	}
+	return &Checkpoint{Commit: commit, Time: time.Now(), Head: head}, nil
+}
+
+// writeTree stages the whole working tree into the private index and writes
+// it as a tree
+func (s *Store) writeTree(ctx context.Context) (string, error) {
+	if _, err := os.Stat(s.index); os.IsNotExist(err) {
+		// Starting from the real index reuses its file stats, so the first
+		// 'git add' doesn't hash every file
+		if err := os.MkdirAll(filepath.Dir(s.index), 0755); err != nil {
+			return "", fmt.Errorf("failed to create checkpoint index: %w", err)
+		}
+		if real, err := s.output(ctx, "rev-parse", "--path-format=absolute", "--git-path", "index"); err == nil {
+			copyFile(real, s.index)
+		}
+	}
+	if err := s.git(ctx, "add", "-A", "--", "."); err != nil {
+		return "", fmt.Errorf("failed to record the working tree: %w", err)
+	}
+	tree, err := s.output(ctx, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("failed to record the working tree: %w", err)
+	}
+	return tree, nil
+}
+
+// List returns the checkpoints of branch, newest first, at most limit of them
+// when limit is positive
+func (s *Store) List(ctx context.Context, branch string, limit int) ([]Checkpoint, error) {
+	ref := Ref(branch)
+	if _, err := s.output(ctx, "rev-parse", "--verify", "-q", ref); err != nil {
+		return nil, nil
+	}
+	args := []string{"log", "--first-parent", "--format=%H %ct %s"}
+	if limit > 0 {
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+	output, err := s.output(ctx, append(args, ref)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+	var checkpoints []Checkpoint
+	for _, line := range strings.Split(output, "\n") {
+		// <commit> <time> checkpoint <head>
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		seconds, _ := strconv.ParseInt(fields[1], 10, 64)
+		checkpoints = append(checkpoints, Checkpoint{Commit: fields[0], Time: time.Unix(seconds, 0), Head: fields[3]})
+	}
+	return checkpoints, nil
+}
+
+// Find returns the checkpoint of branch whose commit starts with id
+func (s *Store) Find(ctx context.Context, branch, id string) (*Checkpoint, error) {
+	checkpoints, err := s.List(ctx, branch, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, cp := range checkpoints {
+		if id != "" && strings.HasPrefix(cp.Commit, id) {
+			return &cp, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+}
+
+// Restore writes the files of a checkpoint into the working tree, all of them
+// or only paths, relative to the current directory. Untracked files created
+// since are left alone and the index isn't changed.
+func (s *Store) Restore(ctx context.Context, cp *Checkpoint, paths []string) error {
+	if len(paths) == 0 {
+		paths = []string{":/"}
+	}
+	args := append([]string{"restore", "--source=" + cp.Commit, "--worktree", "--"}, paths...)
+	if output, err := git.Command(ctx, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restore checkpoint: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Clear deletes the checkpoints of branch
+func (s *Store) Clear(ctx context.Context, branch string) error {
+	if err := s.git(ctx, "update-ref", "-d", Ref(branch)); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", Ref(branch), err)
+	}
+	return nil
+}
+
+// command runs git in the repository root with the private index and a fixed
+// identity, so checkpoints work before user.name is configured
+func (s *Store) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := git.Command(ctx, args...)
+	cmd.Dir = s.root
+	cmd.Env = append(os.Environ(),
+		"GIT_INDEX_FILE="+s.index,
+		"GIT_AUTHOR_NAME=githelper", "GIT_AUTHOR_EMAIL=githelper@localhost",
+		"GIT_COMMITTER_NAME=githelper", "GIT_COMMITTER_EMAIL=githelper@localhost")
+	return cmd
+}
+
+// output runs git and returns its trimmed output
+func (s *Store) output(ctx context.Context, args ...string) (string, error) {
+	output, err := s.command(ctx, args...).Output()
+	return strings.Trim(string(output), "\n"), err
+}
+
+func (s *Store) git(ctx context.Context, args ...string) error {
+	output, err := s.command(ctx, args...).CombinedOutput()
+	if err != nil {
+		if len(output) > 0 {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return err
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
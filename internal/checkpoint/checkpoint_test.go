@@ -0,0 +1,91 @@
+package checkpoint
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGit(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", args...).CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestSaveAndRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	runGit(t, "init", "-q", "-b", "main")
+	writeFile(t, filepath.Join(dir, ".gitignore"), "*.log\n")
+	writeFile(t, filepath.Join(dir, "file.txt"), "one")
+	runGit(t, "add", ".")
+	runGit(t, "commit", "-q", "-m", "one")
+	head := runGit(t, "rev-parse", "HEAD")
+
+	store, err := Open(ctx)
+	require.NoError(t, err)
+
+	// A clean working tree has nothing to save
+	cp, err := store.Save(ctx, "main")
+	require.NoError(t, err)
+	assert.Nil(t, cp)
+
+	writeFile(t, filepath.Join(dir, "file.txt"), "two")
+	writeFile(t, filepath.Join(dir, "notes.md"), "notes")
+	writeFile(t, filepath.Join(dir, "debug.log"), "ignored")
+	first, err := store.Save(ctx, "main")
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	assert.Equal(t, head, first.Head)
+	assert.Equal(t, "notes", runGit(t, "show", first.Commit+":notes.md"))
+	assert.Error(t, exec.Command("git", "cat-file", "-e", first.Commit+":debug.log").Run())
+	// The real index is left alone
+	assert.Empty(t, runGit(t, "diff", "--cached", "--name-only"))
+
+	cp, err = store.Save(ctx, "main")
+	require.NoError(t, err)
+	assert.Nil(t, cp, "unchanged since the last checkpoint")
+
+	writeFile(t, filepath.Join(dir, "file.txt"), "three")
+	second, err := store.Save(ctx, "main")
+	require.NoError(t, err)
+	require.NotNil(t, second)
+
+	checkpoints, err := store.List(ctx, "main", 0)
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 2)
+	assert.Equal(t, second.Commit, checkpoints[0].Commit)
+	assert.Equal(t, head, checkpoints[1].Head)
+
+	found, err := store.Find(ctx, "main", first.Commit[:7])
+	require.NoError(t, err)
+	require.NoError(t, store.Restore(ctx, found, []string{"file.txt"}))
+	data, err := os.ReadFile(filepath.Join(dir, "file.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "two", string(data))
+
+	_, err = store.Find(ctx, "main", "0000000")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Clear(ctx, "main"))
+	checkpoints, err = store.List(ctx, "main", 0)
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+}
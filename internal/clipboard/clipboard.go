@@ -0,0 +1,62 @@
+// Package clipboard copies text to the system clipboard with the tool each
+// platform provides.
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnsupported is returned when no clipboard tool is available
+var ErrUnsupported = errors.New("no clipboard tool found (install wl-clipboard, xclip or xsel)")
+
+// Copy puts text on the clipboard: with pbcopy on macOS, clip on Windows,
+// and wl-copy, xclip or xsel on Linux and the BSDs. command, when given, is
+// the program and arguments to use instead; it reads the text from stdin.
+func Copy(text string, command ...string) error {
+	candidates := [][]string{command}
+	if len(command) == 0 {
+		candidates = commands(runtime.GOOS, os.Getenv)
+	}
+	for _, candidate := range candidates {
+		if _, err := exec.LookPath(candidate[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(candidate[0], candidate[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to copy with %s: %s", candidate[0], strings.TrimSpace(string(output)))
+		}
+		return nil
+	}
+	return ErrUnsupported
+}
+
+// commands returns the programs that copy stdin to the clipboard on goos, in
+// the order they are tried
+func commands(goos string, getenv func(string) string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		var tools [][]string
+		if getenv("WAYLAND_DISPLAY") != "" {
+			tools = append(tools, []string{"wl-copy"})
+		}
+		tools = append(tools,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"})
+		if goos == "linux" && getenv("WSL_DISTRO_NAME") != "" {
+			// The Windows clipboard, from WSL
+			tools = append(tools, []string{"clip.exe"})
+		}
+		return tools
+	}
+	return nil
+}
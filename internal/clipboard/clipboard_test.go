@@ -0,0 +1,39 @@
+package clipboard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommands(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(name string) string { return vars[name] }
+	}
+
+	assert.Equal(t, [][]string{{"pbcopy"}}, commands("darwin", env(nil)))
+	assert.Equal(t, [][]string{{"clip"}}, commands("windows", env(nil)))
+	assert.Equal(t, [][]string{
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}, commands("linux", env(nil)))
+
+	tools := commands("linux", env(map[string]string{"WAYLAND_DISPLAY": "wayland-0", "WSL_DISTRO_NAME": "Ubuntu"}))
+	assert.Equal(t, []string{"wl-copy"}, tools[0])
+	assert.Equal(t, []string{"clip.exe"}, tools[len(tools)-1])
+
+	assert.Empty(t, commands("plan9", env(nil)))
+}
+
+func TestCopyWithCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "clipboard")
+	require.NoError(t, Copy("feat/ABC-1-login", "sh", "-c", "cat > "+out))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "feat/ABC-1-login", string(data))
+
+	assert.ErrorIs(t, Copy("text", "no-such-clipboard-tool"), ErrUnsupported)
+}
@@ -0,0 +1,180 @@
+// Package codeowners parses GitHub CODEOWNERS files and finds the owners of
+// paths.
+package codeowners
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ErrNotFound is returned when a repository has no CODEOWNERS file
+var ErrNotFound = errors.New("no CODEOWNERS file found")
+
+// Locations are where GitHub looks for the CODEOWNERS file, in order
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule is a line of a CODEOWNERS file. A rule without owners makes the
+// matching paths unowned.
+type Rule struct {
+	Pattern string
+	Owners  []string
+	Line    int
+	re      *regexp.Regexp
+}
+
+// ParseError is a line GitHub would reject
+type ParseError struct {
+	Line    int
+	Message string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// File is a parsed CODEOWNERS file
+type File struct {
+	Path   string
+	Rules  []Rule
+	Errors []ParseError
+}
+
+var ownerPattern = regexp.MustCompile(`^(@[A-Za-z0-9-]+(/[A-Za-z0-9._-]+)?|[^@\s]+@[^@\s]+)$`)
+
+// Load finds and parses the CODEOWNERS file of the repository at root
+func Load(root string) (*File, error) {
+	for _, location := range Locations {
+		path := filepath.Join(root, location)
+		file, err := os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		parsed, err := Parse(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", location, err)
+		}
+		parsed.Path = location
+		return parsed, nil
+	}
+	return nil, ErrNotFound
+}
+
+// Parse reads CODEOWNERS rules. Invalid lines are skipped and reported in
+// the Errors of the result.
+func Parse(r io.Reader) (*File, error) {
+	file := &File{}
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 && (i == 0 || line[i-1] != '\\') {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		rule := Rule{Pattern: strings.ReplaceAll(fields[0], `\#`, "#"), Owners: fields[1:], Line: lineNumber}
+		if message := checkPattern(rule.Pattern); message != "" {
+			file.Errors = append(file.Errors, ParseError{Line: lineNumber, Message: message})
+			continue
+		}
+		valid := true
+		for _, owner := range rule.Owners {
+			if !ownerPattern.MatchString(owner) {
+				file.Errors = append(file.Errors, ParseError{Line: lineNumber, Message: fmt.Sprintf("invalid owner '%s'", owner)})
+				valid = false
+			}
+		}
+		if !valid {
+			continue
+		}
+		rule.re = compilePattern(rule.Pattern)
+		file.Rules = append(file.Rules, rule)
+	}
+	return file, scanner.Err()
+}
+
+// checkPattern returns why GitHub rejects a pattern, or ""
+func checkPattern(pattern string) string {
+	switch {
+	case strings.HasPrefix(pattern, "!"):
+		return "negated patterns are not supported"
+	case strings.ContainsAny(pattern, "[]"):
+		return "character ranges are not supported"
+	}
+	return ""
+}
+
+// compilePattern turns a gitignore-style pattern into a regular expression
+// over slash-separated paths relative to the repository root
+func compilePattern(pattern string) *regexp.Regexp {
+	trimmed := strings.TrimSuffix(pattern, "/")
+	// A slash at the start or in the middle anchors the pattern to the root
+	anchored := strings.Contains(trimmed, "/")
+	trimmed = strings.TrimPrefix(trimmed, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(trimmed); i++ {
+		switch {
+		case strings.HasPrefix(trimmed[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(trimmed[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case trimmed[i] == '*':
+			expr.WriteString("[^/]*")
+		case trimmed[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(trimmed[i : i+1]))
+		}
+	}
+	// Directories own everything below them, but "dir/*" only its direct files
+	if !strings.HasSuffix(trimmed, "/*") && trimmed != "*" {
+		expr.WriteString("(?:/.*)?")
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String())
+}
+
+// MatchPattern reports whether a gitignore-style pattern, as used in
+// CODEOWNERS, matches a slash-separated path relative to the repository root
+func MatchPattern(pattern, path string) bool {
+	return compilePattern(pattern).MatchString(strings.TrimPrefix(path, "/"))
+}
+
+// Match returns the rule that applies to path, the last matching one, or
+// nil when no rule matches
+func (f *File) Match(path string) *Rule {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "/")
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if f.Rules[i].re.MatchString(path) {
+			return &f.Rules[i]
+		}
+	}
+	return nil
+}
+
+// Owners returns the owners of path, or nil when it has none
+func (f *File) Owners(path string) []string {
+	if rule := f.Match(path); rule != nil && len(rule.Owners) > 0 {
+		return rule.Owners
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package codeowners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = `# Default owners
+*                   @octo/core
+
+*.md                docs@example.com
+/cmd/               @alice @bob
+internal/**/auth    @octo/security
+/docs/*             @carol
+/vendor/
+!secret.txt         @nobody
+/build/             not-an-owner
+`
+
+func TestParse(t *testing.T) {
+	file, err := Parse(strings.NewReader(sample))
+	require.NoError(t, err)
+	assert.Len(t, file.Rules, 6)
+	require.Len(t, file.Errors, 2)
+	assert.Equal(t, 9, file.Errors[0].Line)
+	assert.Contains(t, file.Errors[1].Error(), "invalid owner 'not-an-owner'")
+}
+
+func TestOwners(t *testing.T) {
+	file, err := Parse(strings.NewReader(sample))
+	require.NoError(t, err)
+
+	tests := map[string][]string{
+		"main.go":                         {"@octo/core"},
+		"cmd/root.go":                     {"@alice", "@bob"},
+		"cmd/sub/dir/file.go":             {"@alice", "@bob"},
+		"README.md":                       {"docs@example.com"},
+		"pkg/README.md":                   {"docs@example.com"},
+		"internal/auth/token.go":          {"@octo/security"},
+		"internal/github/auth/token.go":   {"@octo/security"},
+		"docs/guide.txt":                  {"@carol"},
+		"docs/nested/guide.txt":           {"@octo/core"},
+		"vendor/github.com/x/y.go":        nil,
+		"src/cmd/not-anchored-to-root.go": {"@octo/core"},
+	}
+	for path, owners := range tests {
+		assert.Equal(t, owners, file.Owners(path), path)
+	}
+
+	rule := file.Match("cmd/root.go")
+	require.NotNil(t, rule)
+	assert.Equal(t, 5, rule.Line)
+}
+
+func TestMatchPattern(t *testing.T) {
+	assert.True(t, MatchPattern("*.pem", "certs/server.pem"))
+	assert.True(t, MatchPattern(".env", ".env"))
+	assert.True(t, MatchPattern(".env", "app/.env"))
+	assert.True(t, MatchPattern("vendor/**", "vendor/github.com/x/y.go"))
+	assert.False(t, MatchPattern("vendor/**", "src/vendor/y.go"))
+	assert.False(t, MatchPattern(".env", ".env.example"))
+}
@@ -11,6 +11,72 @@ type Config struct {
 	DefaultOrg  string `mapstructure:"default_org"`
 	Debug       bool   `mapstructure:"debug"`
 	OpenAIAPIKey string `mapstructure:"openai_api_key"`
+	CommitTemplate CommitTemplate `mapstructure:"commit_template"`
+	DefaultHost string `mapstructure:"default_host"`
+	Hosts map[string]HostConfig `mapstructure:"hosts"`
+	OAuthClientID string `mapstructure:"oauth_client_id"`
+	Profile string `mapstructure:"profile"`
+	Profiles map[string]Profile `mapstructure:"profiles"`
+	Aliases map[string]string `mapstructure:"aliases"`
+	Workflows map[string]Workflow `mapstructure:"workflows"`
+	SparseProfiles map[string][]string `mapstructure:"sparse_profiles"`
+	Hooks map[string]CommandHooks `mapstructure:"hooks"`
+}
+
+// CommandHooks are the shell commands run around a githelper command, keyed
+// by command path such as "squash" or "pr merge" in the hooks section; "*"
+// applies to every command
+type CommandHooks struct {
+	Pre     []string `mapstructure:"pre"`
+	Post    []string `mapstructure:"post"`
+	OnError []string `mapstructure:"on_error"`
+}
+
+// Empty reports whether no hook is set
+func (h CommandHooks) Empty() bool {
+	return len(h.Pre) == 0 && len(h.Post) == 0 && len(h.OnError) == 0
+}
+
+// Workflow is a named sequence of git and githelper steps run by
+// 'githelper run'
+type Workflow struct {
+	Description string          `mapstructure:"description"`
+	Params      []WorkflowParam `mapstructure:"params"`
+	Steps       []string        `mapstructure:"steps"`
+}
+
+// WorkflowParam is a value substituted into workflow steps as {{.name}}
+type WorkflowParam struct {
+	Name        string `mapstructure:"name"`
+	Default     string `mapstructure:"default"`
+	Description string `mapstructure:"description"`
+	Required    bool   `mapstructure:"required"`
+}
+
+// Profile is a named GitHub identity whose settings override the top-level
+// ones while it is active
+type Profile struct {
+	GithubToken string `mapstructure:"github_token"`
+	DefaultOrg  string `mapstructure:"default_org"`
+	Host        string `mapstructure:"host"`
+	UseSSH      *bool  `mapstructure:"use_ssh"`
+	UserName    string `mapstructure:"user_name"`
+	UserEmail   string `mapstructure:"user_email"`
+}
+
+// HostConfig holds the settings of a GitHub Enterprise Server (or github.com)
+// host, keyed by hostname in the hosts section
+type HostConfig struct {
+	APIURL    string `mapstructure:"api_url"`
+	UploadURL string `mapstructure:"upload_url"`
+	Token     string `mapstructure:"token"`
+	ClientID  string `mapstructure:"client_id"`
+}
+
+// CommitTemplate customizes the header of generated commit messages
+type CommitTemplate struct {
+	Format        string `mapstructure:"format"`
+	TicketPattern string `mapstructure:"ticket_pattern"`
 }
 
 func LoadConfig(cfgFile string) (*Config, error) {
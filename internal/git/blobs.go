@@ -0,0 +1,268 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// scanBatch is the number of lines handed to a worker at once
+const scanBatch = 4096
+
+// blobCacheFile is where LargeBlobs keeps its last result, in the git
+// directory
+const blobCacheFile = "githelper/large-blobs"
+
+// Blob is a version of a file in history
+type Blob struct {
+	ID   string
+	Size int64
+	// Path is one of the paths the blob has in history
+	Path string
+}
+
+// LargeBlobs returns the blobs of at least minSize bytes reachable from the
+// rev-list arguments revs, largest first. Sizes come from a single pass over
+// the object database, so only the large blobs are looked up in history.
+// report, when not nil, is called with the phase and the number of objects
+// read so far.
+//
+// The result is cached in the git directory until a ref changes, and reused
+// for any minSize at least as large.
+func LargeBlobs(ctx context.Context, minSize int64, report func(phase string, read int64), revs ...string) ([]Blob, error) {
+	key, err := blobCacheKey(ctx, revs)
+	if err != nil {
+		return nil, err
+	}
+	cache, _ := gitPath(ctx, blobCacheFile)
+	if blobs, ok := readBlobCache(cache, key, minSize); ok {
+		return blobs, nil
+	}
+
+	if report == nil {
+		report = func(string, int64) {}
+	}
+	sizes, err := blobSizes(ctx, minSize, report)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := blobPaths(ctx, sizes, report, revs)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(blobs, func(i, j int) bool {
+		if blobs[i].Size != blobs[j].Size {
+			return blobs[i].Size > blobs[j].Size
+		}
+		return blobs[i].Path < blobs[j].Path
+	})
+
+	if cache != "" {
+		// Best effort: the cache only saves time
+		writeBlobCache(cache, key, minSize, blobs)
+	}
+	return blobs, nil
+}
+
+// blobSizes reads the size of every blob in the object database, keeping
+// those of at least minSize bytes
+func blobSizes(ctx context.Context, minSize int64, report func(string, int64)) (map[string]int64, error) {
+	cmd := Command(ctx, "cat-file", "--batch-all-objects", "--unordered", "--batch-check=%(objecttype) %(objectname) %(objectsize)")
+	sizes := map[string]int64{}
+	var mu sync.Mutex
+	var read atomic.Int64
+	err := scanCommand(cmd, func(lines []string) {
+		found := map[string]int64{}
+		for _, line := range lines {
+			fields := strings.Fields(line)
+			if len(fields) != 3 || fields[0] != "blob" {
+				continue
+			}
+			if size, err := strconv.ParseInt(fields[2], 10, 64); err == nil && size >= minSize {
+				found[fields[1]] = size
+			}
+		}
+		mu.Lock()
+		for id, size := range found {
+			sizes[id] = size
+		}
+		mu.Unlock()
+		report("Sizing objects", read.Add(int64(len(lines))))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object sizes: %w", err)
+	}
+	return sizes, nil
+}
+
+// blobPaths walks history to find which of the blobs are reachable from revs,
+// and under which path
+func blobPaths(ctx context.Context, sizes map[string]int64, report func(string, int64), revs []string) ([]Blob, error) {
+	if len(sizes) == 0 {
+		return nil, nil
+	}
+	cmd := Command(ctx, append([]string{"rev-list", "--objects"}, revs...)...)
+	var blobs []Blob
+	var mu sync.Mutex
+	var read atomic.Int64
+	err := scanCommand(cmd, func(lines []string) {
+		var found []Blob
+		for _, line := range lines {
+			// <object> <path>, commits have no path
+			id, path, ok := strings.Cut(line, " ")
+			if !ok {
+				continue
+			}
+			if size, ok := sizes[id]; ok {
+				found = append(found, Blob{ID: id, Size: size, Path: path})
+			}
+		}
+		mu.Lock()
+		blobs = append(blobs, found...)
+		mu.Unlock()
+		report("Finding paths", read.Add(int64(len(lines))))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git objects: %w", err)
+	}
+	return blobs, nil
+}
+
+// scanCommand runs cmd and hands its output lines, in batches, to parse on
+// one worker per CPU
+func scanCommand(cmd *exec.Cmd, parse func(lines []string)) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	batches := make(chan []string, runtime.NumCPU())
+	var workers sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for batch := range batches {
+				parse(batch)
+			}
+		}()
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	batch := make([]string, 0, scanBatch)
+	for scanner.Scan() {
+		batch = append(batch, scanner.Text())
+		if len(batch) == scanBatch {
+			batches <- batch
+			batch = make([]string, 0, scanBatch)
+		}
+	}
+	if len(batch) > 0 {
+		batches <- batch
+	}
+	close(batches)
+	workers.Wait()
+
+	if err := scanner.Err(); err != nil {
+		io.Copy(io.Discard, stdout)
+		cmd.Wait()
+		return err
+	}
+	return cmd.Wait()
+}
+
+// blobCacheKey identifies the state of history: the value of every ref and
+// the revisions scanned
+func blobCacheKey(ctx context.Context, revs []string) (string, error) {
+	refs, err := Command(ctx, "for-each-ref", "--format=%(objectname) %(refname)").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list refs: %w", err)
+	}
+	hash := sha256.New()
+	hash.Write(refs)
+	hash.Write([]byte(strings.Join(revs, "\x00")))
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// readBlobCache returns the cached blobs of at least minSize bytes when the
+// cache was made for key with a smaller or equal minimum size
+func readBlobCache(file, key string, minSize int64) ([]Blob, bool) {
+	if file == "" {
+		return nil, false
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	// <key> <min size>
+	if !scanner.Scan() {
+		return nil, false
+	}
+	cachedKey, cachedMin, _ := strings.Cut(scanner.Text(), " ")
+	if min, err := strconv.ParseInt(cachedMin, 10, 64); cachedKey != key || err != nil || min > minSize {
+		return nil, false
+	}
+	blobs := []Blob{}
+	for scanner.Scan() {
+		// <object> <size> <path>
+		fields := strings.SplitN(scanner.Text(), " ", 3)
+		if len(fields) != 3 {
+			return nil, false
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		if size >= minSize {
+			blobs = append(blobs, Blob{ID: fields[0], Size: size, Path: fields[2]})
+		}
+	}
+	return blobs, scanner.Err() == nil
+}
+
+func writeBlobCache(file, key string, minSize int64, blobs []Blob) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	var content strings.Builder
+	fmt.Fprintf(&content, "%s %d\n", key, minSize)
+	for _, blob := range blobs {
+		if !strings.Contains(blob.Path, "\n") {
+			fmt.Fprintf(&content, "%s %d %s\n", blob.ID, blob.Size, blob.Path)
+		}
+	}
+	// Written aside and renamed, so a concurrent reader never sees half of it
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content.String()), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+func gitPath(ctx context.Context, path string) (string, error) {
+	output, err := Command(ctx, "rev-parse", "--path-format=absolute", "--git-path", path).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
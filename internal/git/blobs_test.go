@@ -0,0 +1,56 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLargeBlobs(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "assets"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "assets", "big.bin"), []byte(strings.Repeat("x", 5000)), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "small.txt"), []byte("small"), 0644))
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	// Unreachable blobs aren't reported
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "loose.bin"), []byte(strings.Repeat("y", 9000)), 0644))
+	runGit(t, dir, "hash-object", "-w", "loose.bin")
+	chdir(t, dir)
+
+	var phases []string
+	blobs, err := LargeBlobs(context.Background(), 0, func(phase string, read int64) {
+		phases = append(phases, phase)
+	}, "--all")
+	require.NoError(t, err)
+	require.Len(t, blobs, 2)
+	assert.Equal(t, "assets/big.bin", blobs[0].Path)
+	assert.Equal(t, int64(5000), blobs[0].Size)
+	assert.Equal(t, "small.txt", blobs[1].Path)
+	assert.Contains(t, phases, "Sizing objects")
+	assert.Contains(t, phases, "Finding paths")
+
+	// A larger minimum is answered from the cache
+	phases = nil
+	blobs, err = LargeBlobs(context.Background(), 1000, func(phase string, read int64) {
+		phases = append(phases, phase)
+	}, "--all")
+	require.NoError(t, err)
+	require.Len(t, blobs, 1)
+	assert.Equal(t, "assets/big.bin", blobs[0].Path)
+	assert.Empty(t, phases)
+
+	// A new commit makes the cache stale
+	runGit(t, dir, "add", "loose.bin")
+	runGit(t, dir, "commit", "-q", "-m", "loose")
+	blobs, err = LargeBlobs(context.Background(), 1000, nil, "--all")
+	require.NoError(t, err)
+	require.Len(t, blobs, 2)
+	assert.Equal(t, "loose.bin", blobs[0].Path)
+}
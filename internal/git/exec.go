@@ -0,0 +1,27 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// WaitDelay is how long git gets to exit once interrupted before it's killed
+const WaitDelay = 5 * time.Second
+
+// Command returns a git command bound to ctx. When ctx is cancelled, by
+// Ctrl+C or a timeout, git is interrupted rather than killed so it can remove
+// its lock files, and killed if it hasn't exited WaitDelay later.
+func Command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Cancel = func() error {
+		// Windows can't deliver an interrupt
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = WaitDelay
+	return cmd
+}
@@ -0,0 +1,122 @@
+// Package git answers questions about the repository in the current
+// directory that several commands share.
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// fallbackBranches are tried in order when the remote doesn't say which
+// branch is its default
+var fallbackBranches = []string{"main", "master", "trunk", "develop"}
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[string]string{}
+)
+
+// DefaultBranch returns the default branch of remote (usually "origin") for
+// the repository in the current directory. It uses, in order:
+//  1. refs/remotes/<remote>/HEAD, as set by clone or 'git remote set-head'
+//  2. the remote's HEAD from 'git ls-remote --symref', which is then saved
+//     as refs/remotes/<remote>/HEAD so the network is asked only once
+//  3. the first of main, master, trunk and develop that exists
+//
+// Results are cached for the life of the process.
+func DefaultBranch(ctx context.Context, remote string) (string, error) {
+	root, err := Command(ctx, "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository")
+	}
+	key := strings.TrimSpace(string(root)) + "\x00" + remote
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	if branch, ok := cache[key]; ok {
+		return branch, nil
+	}
+
+	branch, err := resolveDefaultBranch(ctx, remote)
+	if err != nil {
+		return "", err
+	}
+	cache[key] = branch
+	return branch, nil
+}
+
+func resolveDefaultBranch(ctx context.Context, remote string) (string, error) {
+	prefix := "refs/remotes/" + remote + "/"
+	if output, err := Command(ctx, "symbolic-ref", "-q", prefix+"HEAD").Output(); err == nil {
+		return strings.TrimPrefix(strings.TrimSpace(string(output)), prefix), nil
+	}
+
+	hasRemote := Command(ctx, "remote", "get-url", remote).Run() == nil
+	if hasRemote {
+		if branch := remoteHead(ctx, remote); branch != "" {
+			// Best effort: the cache is only an optimization
+			Command(ctx, "remote", "set-head", remote, branch).Run()
+			return branch, nil
+		}
+	}
+
+	for _, branch := range fallbackBranches {
+		if refExists(ctx, "refs/heads/"+branch) || (hasRemote && refExists(ctx, prefix+branch)) {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine the default branch of '%s'. Set it with 'githelper default-branch set <branch>'", remote)
+}
+
+// remoteHead asks the remote which branch its HEAD points at
+func remoteHead(ctx context.Context, remote string) string {
+	output, err := Command(ctx, "ls-remote", "--symref", remote, "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		// ref: refs/heads/main	HEAD
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 3 && fields[0] == "ref:" && fields[2] == "HEAD" {
+			return strings.TrimPrefix(fields[1], "refs/heads/")
+		}
+	}
+	return ""
+}
+
+// SetDefaultBranch points refs/remotes/<remote>/HEAD at branch, or at the
+// remote's current default branch when branch is empty
+func SetDefaultBranch(ctx context.Context, remote, branch string) (string, error) {
+	args := []string{"remote", "set-head", remote}
+	if branch == "" {
+		args = append(args, "--auto")
+	} else {
+		if !refExists(ctx, "refs/remotes/"+remote+"/"+branch) {
+			return "", fmt.Errorf("%s/%s does not exist. Fetch %s first", remote, branch, remote)
+		}
+		args = append(args, branch)
+	}
+	if output, err := Command(ctx, args...).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to set the default branch: %s", strings.TrimSpace(string(output)))
+	}
+
+	cacheMu.Lock()
+	cache = map[string]string{}
+	cacheMu.Unlock()
+
+	prefix := "refs/remotes/" + remote + "/"
+	output, err := Command(ctx, "symbolic-ref", "-q", prefix+"HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %sHEAD: %w", prefix, err)
+	}
+	return strings.TrimPrefix(strings.TrimSpace(string(output)), prefix), nil
+}
+
+func refExists(ctx context.Context, ref string) bool {
+	return Command(ctx, "rev-parse", "--verify", "-q", ref).Run() == nil
+}
@@ -0,0 +1,118 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	originalWd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(originalWd) })
+}
+
+func TestDefaultBranchFromRemote(t *testing.T) {
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	local := filepath.Join(root, "local")
+	runGit(t, root, "init", "-q", "--bare", "-b", "trunk", remote)
+	runGit(t, root, "init", "-q", "-b", "trunk", local)
+	runGit(t, local, "commit", "-q", "--allow-empty", "-m", "initial")
+	runGit(t, local, "push", "-q", remote, "trunk")
+	// A remote added by hand has no origin/HEAD
+	runGit(t, local, "remote", "add", "origin", remote)
+	runGit(t, local, "fetch", "-q", "origin")
+	chdir(t, local)
+
+	branch, err := DefaultBranch(context.Background(), "origin")
+	require.NoError(t, err)
+	assert.Equal(t, "trunk", branch)
+	// The answer is saved as origin/HEAD
+	assert.Equal(t, "refs/remotes/origin/trunk", runGit(t, local, "symbolic-ref", "refs/remotes/origin/HEAD"))
+}
+
+func TestDefaultBranchFallback(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "feature", dir)
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "initial")
+	chdir(t, dir)
+
+	_, err := DefaultBranch(context.Background(), "origin")
+	assert.Error(t, err)
+
+	runGit(t, dir, "branch", "master")
+	branch, err := DefaultBranch(context.Background(), "origin")
+	require.NoError(t, err)
+	assert.Equal(t, "master", branch)
+}
+
+func TestSetDefaultBranch(t *testing.T) {
+	root := t.TempDir()
+	remote := filepath.Join(root, "remote.git")
+	local := filepath.Join(root, "local")
+	runGit(t, root, "init", "-q", "--bare", "-b", "main", remote)
+	runGit(t, root, "init", "-q", "-b", "main", local)
+	runGit(t, local, "commit", "-q", "--allow-empty", "-m", "initial")
+	runGit(t, local, "branch", "develop")
+	runGit(t, local, "remote", "add", "origin", remote)
+	runGit(t, local, "push", "-q", "origin", "main", "develop")
+	chdir(t, local)
+
+	branch, err := SetDefaultBranch(context.Background(), "origin", "")
+	require.NoError(t, err)
+	assert.Equal(t, "main", branch)
+
+	branch, err = SetDefaultBranch(context.Background(), "origin", "develop")
+	require.NoError(t, err)
+	assert.Equal(t, "develop", branch)
+	branch, err = DefaultBranch(context.Background(), "origin")
+	require.NoError(t, err)
+	assert.Equal(t, "develop", branch)
+
+	_, err = SetDefaultBranch(context.Background(), "origin", "missing")
+	assert.Error(t, err)
+}
+
+func TestCommandInterruptedByContext(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", dir)
+	chdir(t, dir)
+
+	output, err := Command(context.Background(), "rev-parse", "--is-inside-work-tree").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "true\n", string(output))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	// Reading objects from stdin blocks until the command is interrupted
+	cmd := Command(ctx, "cat-file", "--batch")
+	stdin, err := cmd.StdinPipe()
+	require.NoError(t, err)
+	defer stdin.Close()
+	start := time.Now()
+	assert.Error(t, cmd.Run())
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), WaitDelay)
+}
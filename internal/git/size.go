@@ -0,0 +1,151 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Size is the disk space a repository uses, in bytes
+type Size struct {
+	// LooseObjects and LooseSize are the objects stored one per file
+	LooseObjects int64
+	LooseSize    int64
+	// PackedObjects, Packs and PackSize are the objects stored in packs
+	PackedObjects int64
+	Packs         int64
+	PackSize      int64
+	// GarbageFiles and GarbageSize are files in the object store git
+	// doesn't recognize, such as temporary packs left by an interrupted fetch
+	GarbageFiles int64
+	GarbageSize  int64
+	// GitDir is the whole git directory: objects, index, refs, logs and hooks
+	GitDir int64
+	// WorkTree is the tracked files checked out, 0 in a bare repository or
+	// when measured from inside the git directory. Untracked and ignored
+	// files aren't counted.
+	WorkTree int64
+}
+
+// Objects returns the bytes used by the object store
+func (s *Size) Objects() int64 {
+	return s.LooseSize + s.PackSize + s.GarbageSize
+}
+
+// Total returns the bytes used by the git directory and the working tree
+func (s *Size) Total() int64 {
+	return s.GitDir + s.WorkTree
+}
+
+// RepoSize measures the repository in dir, or in the current directory when
+// dir is empty. Objects are measured with 'git count-objects' and the working
+// tree from the files in the index, so nothing is walked twice and large
+// untracked directories such as node_modules don't slow it down.
+func RepoSize(ctx context.Context, dir string) (*Size, error) {
+	output, err := gitIn(ctx, dir, "count-objects", "-v")
+	if err != nil {
+		return nil, fmt.Errorf("failed to count objects: %w", err)
+	}
+	size := ParseCountObjects(string(output))
+
+	// The common directory holds the git directories of linked worktrees too
+	paths, err := gitIn(ctx, dir, "rev-parse", "--path-format=absolute", "--git-common-dir", "--is-inside-work-tree")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git directory: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(paths)), "\n")
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("failed to find git directory")
+	}
+	// The objects were counted already
+	size.GitDir = size.Objects() + dirSize(lines[0], filepath.Join(lines[0], "objects"))
+
+	if lines[1] == "true" {
+		if size.WorkTree, err = workTreeSize(ctx, dir); err != nil {
+			return nil, err
+		}
+	}
+	return size, nil
+}
+
+// ParseCountObjects reads the output of 'git count-objects -v', whose sizes
+// are in KiB
+func ParseCountObjects(output string) *Size {
+	counts := map[string]int64{}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+			counts[key] = n
+		}
+	}
+	return &Size{
+		LooseObjects:  counts["count"],
+		LooseSize:     counts["size"] * 1024,
+		PackedObjects: counts["in-pack"],
+		Packs:         counts["packs"],
+		PackSize:      counts["size-pack"] * 1024,
+		GarbageFiles:  counts["garbage"],
+		GarbageSize:   counts["size-garbage"] * 1024,
+	}
+}
+
+// workTreeSize adds up the size of the files in the index that are checked
+// out
+func workTreeSize(ctx context.Context, dir string) (int64, error) {
+	root, err := gitIn(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find the working tree: %w", err)
+	}
+	top := strings.TrimSpace(string(root))
+	output, err := gitIn(ctx, top, "ls-files", "-z")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list files: %w", err)
+	}
+	var total int64
+	for _, file := range bytes.Split(output, []byte{0}) {
+		if len(file) == 0 {
+			continue
+		}
+		// Sparse or deleted files simply aren't there
+		if info, err := os.Lstat(filepath.Join(top, string(file))); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+	}
+	return total, nil
+}
+
+// dirSize adds up the size of the files under dir, skipping the directory
+// skip
+func dirSize(dir, skip string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if path == skip {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info, err := entry.Info(); err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+func gitIn(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := Command(ctx, args...)
+	cmd.Dir = dir
+	return cmd.Output()
+}
@@ -0,0 +1,47 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCountObjects(t *testing.T) {
+	size := ParseCountObjects("count: 12\nsize: 48\nin-pack: 3050\npacks: 2\nsize-pack: 1024\nprune-packable: 0\ngarbage: 1\nsize-garbage: 3\n")
+	assert.Equal(t, int64(12), size.LooseObjects)
+	assert.Equal(t, int64(3050), size.PackedObjects)
+	assert.Equal(t, int64(2), size.Packs)
+	assert.Equal(t, int64(1024*1024), size.PackSize)
+	assert.Equal(t, int64((48+1024+3)*1024), size.Objects())
+}
+
+func TestRepoSize(t *testing.T) {
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data.bin"), make([]byte, 100000), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello\n"), 0644))
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-q", "-m", "initial")
+	// Untracked files aren't part of the working tree size
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "node_modules"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "node_modules", "big"), make([]byte, 500000), 0644))
+
+	size, err := RepoSize(context.Background(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100006), size.WorkTree)
+	assert.Equal(t, int64(4), size.LooseObjects) // two blobs, a tree and a commit
+	assert.Greater(t, size.GitDir, size.Objects())
+	assert.Equal(t, size.GitDir+size.WorkTree, size.Total())
+
+	// A bare clone has no working tree
+	bare := filepath.Join(t.TempDir(), "bare.git")
+	runGit(t, dir, "clone", "-q", "--bare", dir, bare)
+	size, err = RepoSize(context.Background(), bare)
+	require.NoError(t, err)
+	assert.Zero(t, size.WorkTree)
+	assert.Greater(t, size.GitDir, int64(0))
+}
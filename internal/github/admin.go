@@ -0,0 +1,53 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// SetArchived archives or unarchives owner/name. It reports false when the
+// repository already was in that state.
+func (c *Client) SetArchived(ctx context.Context, owner, name string, archived bool) (bool, error) {
+	repo, resp, err := c.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, ErrRepoNotFound
+		}
+		return false, err
+	}
+	if repo.GetArchived() == archived {
+		return false, nil
+	}
+
+	if _, _, err := c.client.Repositories.Edit(ctx, owner, name, &github.Repository{Archived: github.Bool(archived)}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// TeamID returns the ID of a team of an organization from its slug
+func (c *Client) TeamID(ctx context.Context, org, slug string) (int64, error) {
+	team, _, err := c.client.Teams.GetTeamBySlug(ctx, org, slug)
+	if err != nil {
+		return 0, err
+	}
+	return team.GetID(), nil
+}
+
+// TransferRepository moves owner/name to newOwner, giving the teams access
+// when newOwner is an organization. GitHub finishes the transfer in the
+// background.
+func (c *Client) TransferRepository(ctx context.Context, owner, name, newOwner string, teamIDs []int64) error {
+	_, resp, err := c.client.Repositories.Transfer(ctx, owner, name, github.TransferRequest{NewOwner: newOwner, TeamID: teamIDs})
+	var accepted *github.AcceptedError
+	if errors.As(err, &accepted) {
+		return nil
+	}
+	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+		return ErrRepoNotFound
+	}
+	return err
+}
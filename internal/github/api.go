@@ -0,0 +1,38 @@
+package github
+
+import "context"
+
+// Repositories creates repositories and reads what commands need of them
+type Repositories interface {
+	CreateRepository(ctx context.Context, name, owner string, isOrg bool, config RepoConfig) error
+	GetParent(ctx context.Context, owner, name string) (string, error)
+	DefaultBranch(ctx context.Context, owner, name string) (string, error)
+}
+
+// PullRequests finds, opens, updates and merges pull requests
+type PullRequests interface {
+	FindPullRequest(ctx context.Context, owner, name, headOwner, branch string) (int, error)
+	PullRequestStatus(ctx context.Context, owner, name string, number int) (*PullRequestStatus, error)
+	CreatePullRequest(ctx context.Context, owner, name, head, base, title, body string, draft bool) (int, string, error)
+	UpdatePullRequest(ctx context.Context, owner, name string, number int, title, body string) (string, error)
+	MergeMethods(ctx context.Context, owner, name string) ([]string, error)
+	MergePullRequest(ctx context.Context, owner, name string, number int, method, headSHA string) (string, error)
+	EnableAutoMerge(ctx context.Context, owner, name string, number int, method string) error
+	DisableAutoMerge(ctx context.Context, owner, name string, number int) error
+	DeleteBranch(ctx context.Context, owner, name, branch string) error
+}
+
+// Issues reads issues
+type Issues interface {
+	GetIssue(ctx context.Context, owner, name string, number int) (*Issue, error)
+}
+
+// API is the part of the GitHub API the repository, pull request and issue
+// commands use. Client implements it; tests can swap in their own.
+type API interface {
+	Repositories
+	PullRequests
+	Issues
+}
+
+var _ API = (*Client)(nil)
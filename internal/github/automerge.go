@@ -0,0 +1,74 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/go-github/v53/github"
+)
+
+var (
+	// ErrAutoMergeNotAllowed is returned when the repository doesn't allow
+	// auto-merge
+	ErrAutoMergeNotAllowed = errors.New("auto-merge is not allowed in this repository")
+	// ErrReadyToMerge is returned when auto-merge can't be enabled because
+	// the pull request can already be merged
+	ErrReadyToMerge = errors.New("the pull request can be merged now")
+)
+
+const enableAutoMergeMutation = `mutation($id: ID!, $method: PullRequestMergeMethod!) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method}) { clientMutationId }
+}`
+
+const disableAutoMergeMutation = `mutation($id: ID!) {
+  disablePullRequestAutoMerge(input: {pullRequestId: $id}) { clientMutationId }
+}`
+
+// EnableAutoMerge makes GitHub merge a pull request with method (merge,
+// squash or rebase) once its required reviews and checks pass
+func (c *Client) EnableAutoMerge(ctx context.Context, owner, name string, number int, method string) error {
+	id, err := c.pullRequestNodeID(ctx, owner, name, number)
+	if err != nil {
+		return err
+	}
+	variables := map[string]interface{}{"id": id, "method": strings.ToUpper(method)}
+	return autoMergeError(c.graphQL(ctx, enableAutoMergeMutation, variables, nil))
+}
+
+// DisableAutoMerge cancels the auto-merge of a pull request
+func (c *Client) DisableAutoMerge(ctx context.Context, owner, name string, number int) error {
+	id, err := c.pullRequestNodeID(ctx, owner, name, number)
+	if err != nil {
+		return err
+	}
+	return autoMergeError(c.graphQL(ctx, disableAutoMergeMutation, map[string]interface{}{"id": id}, nil))
+}
+
+func (c *Client) pullRequestNodeID(ctx context.Context, owner, name string, number int) (string, error) {
+	pr, _, err := c.client.PullRequests.Get(ctx, owner, name, number)
+	if err != nil {
+		if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+			return "", ErrUnauthorized
+		}
+		return "", err
+	}
+	return pr.GetNodeID(), nil
+}
+
+// autoMergeError maps the GraphQL errors of the auto-merge mutations to
+// ErrAutoMergeNotAllowed and ErrReadyToMerge
+func autoMergeError(err error) error {
+	var gqlErrs GraphQLErrors
+	if !errors.As(err, &gqlErrs) {
+		return err
+	}
+	message := strings.ToLower(gqlErrs.Error())
+	switch {
+	case strings.Contains(message, "auto merge is not allowed"):
+		return ErrAutoMergeNotAllowed
+	case strings.Contains(message, "clean status"):
+		return ErrReadyToMerge
+	}
+	return err
+}
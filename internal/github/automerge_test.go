@@ -0,0 +1,68 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphQLURL(t *testing.T) {
+	for base, want := range map[string]string{
+		"https://api.github.com/":            "https://api.github.com/graphql",
+		"https://github.example.com/api/v3/": "https://github.example.com/api/graphql",
+	} {
+		parsed, err := url.Parse(base)
+		require.NoError(t, err)
+		assert.Equal(t, want, graphQLURL(parsed))
+	}
+}
+
+// autoMergeServer serves a pull request and answers GraphQL requests with
+// the given errors
+func autoMergeServer(t *testing.T, gqlErrors []GraphQLError, requests *[]map[string]interface{}) *Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/octo/app/pulls/7", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number": 7, "node_id": "PR_kwDO7"}`))
+	})
+	mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		*requests = append(*requests, body)
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": nil, "errors": gqlErrors})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewHostClient(Host{Name: "github.example.com", APIURL: server.URL + "/api/v3/", UploadURL: server.URL + "/api/uploads/", Token: "token"})
+	require.NoError(t, err)
+	return client
+}
+
+func TestEnableAutoMerge(t *testing.T) {
+	var requests []map[string]interface{}
+	client := autoMergeServer(t, nil, &requests)
+
+	require.NoError(t, client.EnableAutoMerge(context.Background(), "octo", "app", 7, "squash"))
+	require.Len(t, requests, 1)
+	assert.Contains(t, requests[0]["query"], "enablePullRequestAutoMerge")
+	assert.Equal(t, map[string]interface{}{"id": "PR_kwDO7", "method": "SQUASH"}, requests[0]["variables"])
+}
+
+func TestAutoMergeErrors(t *testing.T) {
+	var requests []map[string]interface{}
+	client := autoMergeServer(t, []GraphQLError{{Message: "Pull request is in clean status"}}, &requests)
+	assert.ErrorIs(t, client.EnableAutoMerge(context.Background(), "octo", "app", 7, "merge"), ErrReadyToMerge)
+
+	client = autoMergeServer(t, []GraphQLError{{Type: "UNPROCESSABLE", Message: "Pull request Auto merge is not allowed for this repository"}}, &requests)
+	assert.ErrorIs(t, client.EnableAutoMerge(context.Background(), "octo", "app", 7, "merge"), ErrAutoMergeNotAllowed)
+
+	client = autoMergeServer(t, []GraphQLError{{Message: "Something else"}}, &requests)
+	err := client.DisableAutoMerge(context.Background(), "octo", "app", 7)
+	assert.EqualError(t, err, "Something else")
+}
@@ -0,0 +1,154 @@
+package github
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// DefaultBranch returns the default branch of owner/name
+func (c *Client) DefaultBranch(ctx context.Context, owner, name string) (string, error) {
+	repo, _, err := c.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+			return "", ErrUnauthorized
+		}
+		return "", err
+	}
+	return repo.GetDefaultBranch(), nil
+}
+
+// SetDefaultBranch makes branch the default branch of owner/name
+func (c *Client) SetDefaultBranch(ctx context.Context, owner, name, branch string) error {
+	_, _, err := c.client.Repositories.Edit(ctx, owner, name, &github.Repository{DefaultBranch: github.String(branch)})
+	return err
+}
+
+// RetargetPullRequests changes the base of every open pull request from one
+// branch to another and returns their numbers
+func (c *Client) RetargetPullRequests(ctx context.Context, owner, name, from, to string) ([]int, error) {
+	var retargeted []int
+	opts := &github.PullRequestListOptions{State: "open", Base: from, ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		prs, resp, err := c.client.PullRequests.List(ctx, owner, name, opts)
+		if err != nil {
+			return retargeted, err
+		}
+		for _, pr := range prs {
+			update := &github.PullRequest{Base: &github.PullRequestBranch{Ref: github.String(to)}}
+			if _, _, err := c.client.PullRequests.Edit(ctx, owner, name, pr.GetNumber(), update); err != nil {
+				return retargeted, err
+			}
+			retargeted = append(retargeted, pr.GetNumber())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return retargeted, nil
+}
+
+// MoveBranchProtection copies the protection rules of one branch to another
+// and removes them from the first. It reports false when the branch wasn't
+// protected.
+func (c *Client) MoveBranchProtection(ctx context.Context, owner, name, from, to string) (bool, error) {
+	protection, resp, err := c.client.Repositories.GetBranchProtection(ctx, owner, name, from)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if _, _, err := c.client.Repositories.UpdateBranchProtection(ctx, owner, name, to, protectionRequest(protection)); err != nil {
+		return false, err
+	}
+	if _, err := c.client.Repositories.RemoveBranchProtection(ctx, owner, name, from); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// protectionRequest turns the protection read from a branch into the request
+// that applies the same rules to another branch
+func protectionRequest(p *github.Protection) *github.ProtectionRequest {
+	req := &github.ProtectionRequest{}
+	if p.EnforceAdmins != nil {
+		req.EnforceAdmins = p.EnforceAdmins.Enabled
+	}
+
+	if checks := p.GetRequiredStatusChecks(); checks != nil {
+		// The API accepts either contexts or checks, not both
+		req.RequiredStatusChecks = &github.RequiredStatusChecks{Strict: checks.Strict, Checks: checks.Checks}
+		if len(checks.Checks) == 0 {
+			req.RequiredStatusChecks = &github.RequiredStatusChecks{Strict: checks.Strict, Contexts: checks.Contexts}
+		}
+	}
+
+	if reviews := p.GetRequiredPullRequestReviews(); reviews != nil {
+		req.RequiredPullRequestReviews = &github.PullRequestReviewsEnforcementRequest{
+			DismissStaleReviews:          reviews.DismissStaleReviews,
+			RequireCodeOwnerReviews:      reviews.RequireCodeOwnerReviews,
+			RequiredApprovingReviewCount: reviews.RequiredApprovingReviewCount,
+			RequireLastPushApproval:      github.Bool(reviews.RequireLastPushApproval),
+		}
+		if d := reviews.DismissalRestrictions; d != nil {
+			users, teams, apps := actorNames(d.Users, d.Teams, d.Apps)
+			req.RequiredPullRequestReviews.DismissalRestrictionsRequest = &github.DismissalRestrictionsRequest{
+				Users: &users, Teams: &teams, Apps: &apps,
+			}
+		}
+		if b := reviews.BypassPullRequestAllowances; b != nil {
+			users, teams, apps := actorNames(b.Users, b.Teams, b.Apps)
+			req.RequiredPullRequestReviews.BypassPullRequestAllowancesRequest = &github.BypassPullRequestAllowancesRequest{
+				Users: users, Teams: teams, Apps: apps,
+			}
+		}
+	}
+
+	if r := p.GetRestrictions(); r != nil {
+		users, teams, apps := actorNames(r.Users, r.Teams, r.Apps)
+		req.Restrictions = &github.BranchRestrictionsRequest{Users: users, Teams: teams, Apps: apps}
+	}
+
+	if p.RequireLinearHistory != nil {
+		req.RequireLinearHistory = github.Bool(p.RequireLinearHistory.Enabled)
+	}
+	if p.AllowForcePushes != nil {
+		req.AllowForcePushes = github.Bool(p.AllowForcePushes.Enabled)
+	}
+	if p.AllowDeletions != nil {
+		req.AllowDeletions = github.Bool(p.AllowDeletions.Enabled)
+	}
+	if p.RequiredConversationResolution != nil {
+		req.RequiredConversationResolution = github.Bool(p.RequiredConversationResolution.Enabled)
+	}
+	if p.BlockCreations != nil {
+		req.BlockCreations = p.BlockCreations.Enabled
+	}
+	if p.LockBranch != nil {
+		req.LockBranch = p.LockBranch.Enabled
+	}
+	if p.AllowForkSyncing != nil {
+		req.AllowForkSyncing = p.AllowForkSyncing.Enabled
+	}
+	return req
+}
+
+// actorNames returns the user logins and team and app slugs the API expects
+// in requests
+func actorNames(users []*github.User, teams []*github.Team, apps []*github.App) ([]string, []string, []string) {
+	userNames, teamNames, appNames := []string{}, []string{}, []string{}
+	for _, user := range users {
+		userNames = append(userNames, user.GetLogin())
+	}
+	for _, team := range teams {
+		teamNames = append(teamNames, team.GetSlug())
+	}
+	for _, app := range apps {
+		appNames = append(appNames, app.GetSlug())
+	}
+	return userNames, teamNames, appNames
+}
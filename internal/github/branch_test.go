@@ -0,0 +1,57 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtectionRequest(t *testing.T) {
+	protection := &github.Protection{
+		RequiredStatusChecks: &github.RequiredStatusChecks{
+			Strict:   true,
+			Contexts: []string{"ci"},
+			Checks:   []*github.RequiredStatusCheck{{Context: "ci"}},
+		},
+		RequiredPullRequestReviews: &github.PullRequestReviewsEnforcement{
+			RequiredApprovingReviewCount: 2,
+			RequireCodeOwnerReviews:      true,
+			DismissalRestrictions: &github.DismissalRestrictions{
+				Teams: []*github.Team{{Slug: github.String("maintainers")}},
+			},
+		},
+		EnforceAdmins:        &github.AdminEnforcement{Enabled: true},
+		Restrictions:         &github.BranchRestrictions{Users: []*github.User{{Login: github.String("octocat")}}},
+		RequireLinearHistory: &github.RequireLinearHistory{Enabled: true},
+		AllowForcePushes:     &github.AllowForcePushes{Enabled: false},
+	}
+
+	req := protectionRequest(protection)
+	assert.True(t, req.EnforceAdmins)
+	require.NotNil(t, req.RequiredStatusChecks)
+	assert.True(t, req.RequiredStatusChecks.Strict)
+	assert.Len(t, req.RequiredStatusChecks.Checks, 1)
+	assert.Empty(t, req.RequiredStatusChecks.Contexts)
+
+	require.NotNil(t, req.RequiredPullRequestReviews)
+	assert.Equal(t, 2, req.RequiredPullRequestReviews.RequiredApprovingReviewCount)
+	assert.True(t, req.RequiredPullRequestReviews.RequireCodeOwnerReviews)
+	assert.Equal(t, []string{"maintainers"}, *req.RequiredPullRequestReviews.DismissalRestrictionsRequest.Teams)
+	assert.Nil(t, req.RequiredPullRequestReviews.BypassPullRequestAllowancesRequest)
+
+	assert.Equal(t, []string{"octocat"}, req.Restrictions.Users)
+	assert.Equal(t, []string{}, req.Restrictions.Teams)
+	assert.True(t, *req.RequireLinearHistory)
+	assert.False(t, *req.AllowForcePushes)
+	assert.Nil(t, req.AllowDeletions)
+}
+
+func TestProtectionRequestMinimal(t *testing.T) {
+	req := protectionRequest(&github.Protection{})
+	assert.False(t, req.EnforceAdmins)
+	assert.Nil(t, req.RequiredStatusChecks)
+	assert.Nil(t, req.RequiredPullRequestReviews)
+	assert.Nil(t, req.Restrictions)
+}
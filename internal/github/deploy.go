@@ -0,0 +1,166 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// liveSearchDepth is how many deployments of an environment are searched
+// for the one that is live
+const liveSearchDepth = 30
+
+// Deployment is a deployment of a commit to an environment
+type Deployment struct {
+	ID          int64
+	Environment string
+	SHA         string
+	Ref         string
+	Creator     string
+	Created     time.Time
+	// State is the latest status: success, failure, error, inactive,
+	// in_progress, queued or pending
+	State string
+	// URL is where the deployed application can be reached, when reported
+	URL string
+}
+
+// EnvironmentStatus is what is deployed to an environment
+type EnvironmentStatus struct {
+	Name string
+	// Live is the latest successful deployment, nil when none succeeded
+	Live *Deployment
+	// Latest is the most recent deployment, which may still be running or
+	// may have failed; nil when the environment was never deployed to
+	Latest *Deployment
+}
+
+// Environments returns the deployment environments of a repository with
+// what is deployed to them, sorted by name. Environments that only appear in
+// deployments, without being configured in the repository settings, are
+// included.
+func (c *Client) Environments(ctx context.Context, owner, name string) ([]EnvironmentStatus, error) {
+	names := map[string]bool{}
+
+	opts := &github.EnvironmentListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		result, resp, err := c.client.Repositories.ListEnvironments(ctx, owner, name, opts)
+		if err != nil {
+			var errResp *github.ErrorResponse
+			if errors.As(err, &errResp) && errResp.Response.StatusCode == 401 {
+				return nil, ErrUnauthorized
+			}
+			// Environments aren't available on every plan; deployments are
+			if errors.As(err, &errResp) && errResp.Response.StatusCode == 404 {
+				break
+			}
+			return nil, err
+		}
+		for _, env := range result.Environments {
+			names[env.GetName()] = true
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	recent, _, err := c.client.Repositories.ListDeployments(ctx, owner, name,
+		&github.DeploymentsListOptions{ListOptions: github.ListOptions{PerPage: 100}})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range recent {
+		names[deployment.GetEnvironment()] = true
+	}
+
+	envs := make([]EnvironmentStatus, 0, len(names))
+	for env := range names {
+		status, err := c.EnvironmentStatus(ctx, owner, name, env)
+		if err != nil {
+			return nil, err
+		}
+		envs = append(envs, *status)
+	}
+	sort.Slice(envs, func(i, j int) bool { return envs[i].Name < envs[j].Name })
+	return envs, nil
+}
+
+// EnvironmentStatus returns the live and the latest deployment of an
+// environment
+func (c *Client) EnvironmentStatus(ctx context.Context, owner, name, env string) (*EnvironmentStatus, error) {
+	status := &EnvironmentStatus{Name: env}
+	opts := &github.DeploymentsListOptions{Environment: env, ListOptions: github.ListOptions{PerPage: liveSearchDepth}}
+	deployments, _, err := c.client.Repositories.ListDeployments(ctx, owner, name, opts)
+	if err != nil {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == 401 {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	// Deployments are listed newest first; the first successful one is live
+	for _, d := range deployments {
+		deployment, err := c.deployment(ctx, owner, name, d)
+		if err != nil {
+			return nil, err
+		}
+		if status.Latest == nil {
+			status.Latest = deployment
+		}
+		if deployment.State == "success" {
+			status.Live = deployment
+			break
+		}
+	}
+	return status, nil
+}
+
+// Deployments returns the most recent deployments to an environment with
+// their states, newest first
+func (c *Client) Deployments(ctx context.Context, owner, name, env string, limit int) ([]Deployment, error) {
+	opts := &github.DeploymentsListOptions{Environment: env, ListOptions: github.ListOptions{PerPage: limit}}
+	list, _, err := c.client.Repositories.ListDeployments(ctx, owner, name, opts)
+	if err != nil {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == 401 {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	deployments := make([]Deployment, 0, len(list))
+	for _, d := range list {
+		deployment, err := c.deployment(ctx, owner, name, d)
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, *deployment)
+	}
+	return deployments, nil
+}
+
+// deployment converts a deployment, looking up its latest status
+func (c *Client) deployment(ctx context.Context, owner, name string, d *github.Deployment) (*Deployment, error) {
+	deployment := &Deployment{
+		ID:          d.GetID(),
+		Environment: d.GetEnvironment(),
+		SHA:         d.GetSHA(),
+		Ref:         d.GetRef(),
+		Creator:     d.GetCreator().GetLogin(),
+		Created:     d.GetCreatedAt().Time,
+		State:       "pending",
+	}
+	statuses, _, err := c.client.Repositories.ListDeploymentStatuses(ctx, owner, name, d.GetID(), &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(statuses) > 0 {
+		deployment.State = statuses[0].GetState()
+		deployment.URL = statuses[0].GetEnvironmentURL()
+	}
+	return deployment, nil
+}
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deployServer serves a production environment whose newest deployment
+// failed, and a staging environment that only appears in deployments
+func deployServer(t *testing.T) *Client {
+	deployments := map[string]string{
+		"production": `[{"id": 3, "sha": "ccc", "ref": "main", "environment": "production", "creator": {"login": "alice"}},
+			{"id": 2, "sha": "bbb", "ref": "main", "environment": "production", "creator": {"login": "bob"}}]`,
+		"staging": `[{"id": 1, "sha": "aaa", "ref": "feature", "environment": "staging"}]`,
+	}
+	states := map[string]string{"1": "in_progress", "2": "success", "3": "failure"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/octo/app/environments", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"total_count": 1, "environments": [{"name": "production"}]}`))
+	})
+	mux.HandleFunc("/api/v3/repos/octo/app/deployments", func(w http.ResponseWriter, r *http.Request) {
+		if env := r.URL.Query().Get("environment"); env != "" {
+			w.Write([]byte(deployments[env]))
+			return
+		}
+		w.Write([]byte(deployments["staging"]))
+	})
+	mux.HandleFunc("/api/v3/repos/octo/app/deployments/{id}/statuses", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `[{"state": %q, "environment_url": "https://app.example.com"}]`, states[r.PathValue("id")])
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewHostClient(Host{Name: "github.example.com", APIURL: server.URL + "/api/v3/", UploadURL: server.URL + "/api/uploads/", Token: "token"})
+	require.NoError(t, err)
+	return client
+}
+
+func TestEnvironments(t *testing.T) {
+	client := deployServer(t)
+
+	envs, err := client.Environments(context.Background(), "octo", "app")
+	require.NoError(t, err)
+	require.Len(t, envs, 2)
+
+	production := envs[0]
+	assert.Equal(t, "production", production.Name)
+	require.NotNil(t, production.Latest)
+	assert.Equal(t, "ccc", production.Latest.SHA)
+	assert.Equal(t, "failure", production.Latest.State)
+	require.NotNil(t, production.Live)
+	assert.Equal(t, "bbb", production.Live.SHA)
+	assert.Equal(t, "bob", production.Live.Creator)
+	assert.Equal(t, "https://app.example.com", production.Live.URL)
+
+	staging := envs[1]
+	assert.Equal(t, "staging", staging.Name)
+	assert.Nil(t, staging.Live)
+	require.NotNil(t, staging.Latest)
+	assert.Equal(t, "in_progress", staging.Latest.State)
+}
+
+func TestDeployments(t *testing.T) {
+	client := deployServer(t)
+
+	deployments, err := client.Deployments(context.Background(), "octo", "app", "production", 10)
+	require.NoError(t, err)
+	require.Len(t, deployments, 2)
+	assert.Equal(t, []string{"failure", "success"}, []string{deployments[0].State, deployments[1].State})
+}
@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// Repository event kinds
+const (
+	EventPush        = "push"
+	EventPullRequest = "pull_request"
+	EventRelease     = "release"
+	EventIssue       = "issue"
+)
+
+// Event is something that happened on a repository: a push, or a pull
+// request, release or issue that was opened, closed, merged or published
+type Event struct {
+	ID    string
+	Kind  string
+	Actor string
+	// Summary says what happened, e.g. "pushed 3 commits to main"
+	Summary string
+	Created time.Time
+}
+
+// RepoEvents returns the recent events of a repository, newest first. Other
+// kinds of events, such as stars and comments, are left out.
+func (c *Client) RepoEvents(ctx context.Context, owner, name string, limit int) ([]Event, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var events []Event
+	for {
+		page, resp, err := c.client.Activity.ListRepositoryEvents(ctx, owner, name, opts)
+		if err != nil {
+			if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+				return nil, ErrUnauthorized
+			}
+			return nil, err
+		}
+		for _, e := range page {
+			event, ok := repoEvent(e)
+			if !ok {
+				continue
+			}
+			events = append(events, event)
+			if limit > 0 && len(events) >= limit {
+				return events, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return events, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// repoEvent describes an event of the API, if it is of a kind Event covers
+func repoEvent(e *github.Event) (Event, bool) {
+	event := Event{
+		ID:      e.GetID(),
+		Actor:   e.GetActor().GetLogin(),
+		Created: e.GetCreatedAt().Time,
+	}
+	payload, err := e.ParsePayload()
+	if err != nil {
+		return event, false
+	}
+
+	switch p := payload.(type) {
+	case *github.PushEvent:
+		event.Kind = EventPush
+		commits := "commits"
+		if p.GetSize() == 1 {
+			commits = "commit"
+		}
+		event.Summary = fmt.Sprintf("pushed %d %s to %s", p.GetSize(), commits, strings.TrimPrefix(p.GetRef(), "refs/heads/"))
+	case *github.PullRequestEvent:
+		event.Kind = EventPullRequest
+		action := p.GetAction()
+		if action == "closed" && p.GetPullRequest().GetMerged() {
+			action = "merged"
+		}
+		event.Summary = fmt.Sprintf("%s pull request #%d %s", action, p.GetPullRequest().GetNumber(), p.GetPullRequest().GetTitle())
+	case *github.ReleaseEvent:
+		event.Kind = EventRelease
+		release := p.GetRelease()
+		event.Summary = fmt.Sprintf("%s release %s", p.GetAction(), release.GetTagName())
+		if name := release.GetName(); name != "" && name != release.GetTagName() {
+			event.Summary += " " + name
+		}
+	case *github.IssuesEvent:
+		event.Kind = EventIssue
+		event.Summary = fmt.Sprintf("%s issue #%d %s", p.GetAction(), p.GetIssue().GetNumber(), p.GetIssue().GetTitle())
+	default:
+		return event, false
+	}
+	return event, true
+}
@@ -0,0 +1,40 @@
+package github
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoEvent(t *testing.T) {
+	event := func(kind, payload string) *github.Event {
+		raw := json.RawMessage(payload)
+		return &github.Event{
+			ID:         github.String("1"),
+			Type:       github.String(kind),
+			Actor:      &github.User{Login: github.String("octocat")},
+			RawPayload: &raw,
+		}
+	}
+
+	e, ok := repoEvent(event("PushEvent", `{"ref": "refs/heads/main", "size": 3}`))
+	assert.True(t, ok)
+	assert.Equal(t, EventPush, e.Kind)
+	assert.Equal(t, "octocat", e.Actor)
+	assert.Equal(t, "pushed 3 commits to main", e.Summary)
+
+	e, _ = repoEvent(event("PullRequestEvent", `{"action": "closed", "pull_request": {"number": 12, "title": "Fix login", "merged": true}}`))
+	assert.Equal(t, "merged pull request #12 Fix login", e.Summary)
+
+	e, _ = repoEvent(event("ReleaseEvent", `{"action": "published", "release": {"tag_name": "v1.2.0", "name": "Spring"}}`))
+	assert.Equal(t, "published release v1.2.0 Spring", e.Summary)
+
+	e, _ = repoEvent(event("IssuesEvent", `{"action": "opened", "issue": {"number": 5, "title": "Crash on start"}}`))
+	assert.Equal(t, EventIssue, e.Kind)
+	assert.Equal(t, "opened issue #5 Crash on start", e.Summary)
+
+	_, ok = repoEvent(event("WatchEvent", `{"action": "started"}`))
+	assert.False(t, ok, "stars are left out")
+}
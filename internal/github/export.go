@@ -0,0 +1,118 @@
+package github
+
+import (
+	"context"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// Metadata is the GitHub data of a repository that is not stored in git
+type Metadata struct {
+	Repository   *github.Repository          `json:"repository"`
+	Issues       []*github.Issue             `json:"issues"`
+	PullRequests []*github.PullRequest       `json:"pull_requests"`
+	Comments     []*github.IssueComment      `json:"comments"`
+	Releases     []*github.RepositoryRelease `json:"releases"`
+	Labels       []*github.Label             `json:"labels"`
+	Milestones   []*github.Milestone         `json:"milestones"`
+}
+
+// ExportMetadata fetches the repository settings, issues, pull requests,
+// comments, releases, labels and milestones of owner/name
+func (c *Client) ExportMetadata(ctx context.Context, owner, name string) (*Metadata, error) {
+	metadata := &Metadata{}
+	var err error
+
+	metadata.Repository, _, err = c.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	// The issues endpoint also returns pull requests; keep only real issues
+	issueOpts := &github.IssueListByRepoOptions{State: "all", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		issues, resp, err := c.client.Issues.ListByRepo(ctx, owner, name, issueOpts)
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			if !issue.IsPullRequest() {
+				metadata.Issues = append(metadata.Issues, issue)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		issueOpts.Page = resp.NextPage
+	}
+
+	prOpts := &github.PullRequestListOptions{State: "all", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		prs, resp, err := c.client.PullRequests.List(ctx, owner, name, prOpts)
+		if err != nil {
+			return nil, err
+		}
+		metadata.PullRequests = append(metadata.PullRequests, prs...)
+		if resp.NextPage == 0 {
+			break
+		}
+		prOpts.Page = resp.NextPage
+	}
+
+	commentOpts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := c.client.Issues.ListComments(ctx, owner, name, 0, commentOpts)
+		if err != nil {
+			return nil, err
+		}
+		metadata.Comments = append(metadata.Comments, comments...)
+		if resp.NextPage == 0 {
+			break
+		}
+		commentOpts.Page = resp.NextPage
+	}
+
+	listOpts := &github.ListOptions{PerPage: 100}
+	for {
+		releases, resp, err := c.client.Repositories.ListReleases(ctx, owner, name, listOpts)
+		if err != nil {
+			return nil, err
+		}
+		metadata.Releases = append(metadata.Releases, releases...)
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+
+	listOpts = &github.ListOptions{PerPage: 100}
+	for {
+		labels, resp, err := c.client.Issues.ListLabels(ctx, owner, name, listOpts)
+		if err != nil {
+			return nil, err
+		}
+		metadata.Labels = append(metadata.Labels, labels...)
+		if resp.NextPage == 0 {
+			break
+		}
+		listOpts.Page = resp.NextPage
+	}
+
+	milestoneOpts := &github.MilestoneListOptions{State: "all", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		milestones, resp, err := c.client.Issues.ListMilestones(ctx, owner, name, milestoneOpts)
+		if err != nil {
+			return nil, err
+		}
+		metadata.Milestones = append(metadata.Milestones, milestones...)
+		if resp.NextPage == 0 {
+			break
+		}
+		milestoneOpts.Page = resp.NextPage
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,94 @@
+package github
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// Gist is the summary of a gist
+type Gist struct {
+	ID          string
+	Description string
+	Public      bool
+	URL         string
+	CloneURL    string
+	Files       []string
+	Updated     time.Time
+}
+
+func gistSummary(gist *github.Gist) Gist {
+	summary := Gist{
+		ID:          gist.GetID(),
+		Description: gist.GetDescription(),
+		Public:      gist.GetPublic(),
+		URL:         gist.GetHTMLURL(),
+		CloneURL:    gist.GetGitPullURL(),
+		Updated:     gist.GetUpdatedAt().Time,
+	}
+	for name := range gist.Files {
+		summary.Files = append(summary.Files, string(name))
+	}
+	sort.Strings(summary.Files)
+	return summary
+}
+
+// CreateGist creates a gist from file names and contents
+func (c *Client) CreateGist(ctx context.Context, description string, public bool, files map[string]string) (*Gist, error) {
+	gistFiles := make(map[github.GistFilename]github.GistFile, len(files))
+	for name, content := range files {
+		gistFiles[github.GistFilename(name)] = github.GistFile{Content: github.String(content)}
+	}
+
+	gist, _, err := c.client.Gists.Create(ctx, &github.Gist{
+		Description: github.String(description),
+		Public:      github.Bool(public),
+		Files:       gistFiles,
+	})
+	if err != nil {
+		if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	summary := gistSummary(gist)
+	return &summary, nil
+}
+
+// ListGists returns the gists of the authenticated user, most recently
+// updated first
+func (c *Client) ListGists(ctx context.Context, limit int) ([]Gist, error) {
+	opts := &github.GistListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var gists []Gist
+	for {
+		page, resp, err := c.client.Gists.List(ctx, "", opts)
+		if err != nil {
+			if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+				return nil, ErrUnauthorized
+			}
+			return nil, err
+		}
+		for _, gist := range page {
+			gists = append(gists, gistSummary(gist))
+			if limit > 0 && len(gists) >= limit {
+				return gists, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return gists, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// GetGist fetches a gist by ID
+func (c *Client) GetGist(ctx context.Context, id string) (*Gist, error) {
+	gist, _, err := c.client.Gists.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	summary := gistSummary(gist)
+	return &summary, nil
+}
@@ -0,0 +1,719 @@
+// Package githubtest is a fake GitHub API for tests. Server keeps
+// repositories, pull requests and issues in memory and serves the REST and
+// GraphQL endpoints githelper uses, so commands run without a real token.
+package githubtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+)
+
+// Hostname is the host name of the fake server in clone URLs, e.g.
+// https://github.test/octo/app.git
+const Hostname = "github.test"
+
+// Repo is a repository on the server
+type Repo struct {
+	Owner         string
+	Name          string
+	Private       bool
+	Description   string
+	Topics        []string
+	HasIssues     bool
+	HasWiki       bool
+	DefaultBranch string
+	// Parent is the owner/name of the repository this one is a fork of
+	Parent string
+	// MergeMethods are the allowed merge methods; nil allows all three
+	MergeMethods   []string
+	AllowAutoMerge bool
+	Pulls          map[int]*PullRequest
+	Issues         map[int]*Issue
+	// CheckRuns are the check runs of each commit
+	CheckRuns map[string][]CheckRun
+	// DeletedBranches are the branches deleted through the API, in order
+	DeletedBranches []string
+	// Events are the events of the repository, newest first
+	Events []Event
+	// Labels are the issue labels, in the order they were created
+	Labels []github.Label
+}
+
+// FullName returns owner/name
+func (r *Repo) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// PullRequest is a pull request of a Repo
+type PullRequest struct {
+	Number  int
+	Title   string
+	Body    string
+	Head    string
+	HeadSHA string
+	Base    string
+	// State is open or closed; a merged pull request is closed
+	State  string
+	Draft  bool
+	Merged bool
+	// Mergeable is nil while GitHub computes it
+	Mergeable *bool
+	// MergeMethod is how the pull request was merged
+	MergeMethod string
+	// AutoMerge is the method of an enabled auto-merge
+	AutoMerge string
+}
+
+// Issue is an issue of a Repo
+type Issue struct {
+	Number int
+	Title  string
+	State  string
+	Labels []string
+}
+
+// CheckRun is a check run on a commit, e.g. {"build", "completed", "success"}
+type CheckRun struct {
+	Name       string
+	Status     string
+	Conclusion string
+}
+
+// Event is an event of a Repo, e.g. {Type: "PushEvent", Actor: "octocat",
+// Payload: {"ref": "refs/heads/main", "size": 1}}
+type Event struct {
+	Type    string
+	Actor   string
+	Payload map[string]interface{}
+	Created time.Time
+}
+
+// Request is a request the server received
+type Request struct {
+	Method string
+	Path   string
+}
+
+// Server is a fake GitHub Enterprise Server
+type Server struct {
+	*httptest.Server
+	// Token is the token requests must carry; "" accepts any
+	Token string
+	// Login is the authenticated user, who owns repositories created without
+	// an organization
+	Login string
+
+	mu       sync.Mutex
+	repos    map[string]*Repo
+	requests []Request
+}
+
+// NewServer starts a fake server that requires the token "test-token" and
+// stops it when the test ends
+func NewServer(t testing.TB) *Server {
+	s := &Server{Token: "test-token", Login: "octocat", repos: map[string]*Repo{}}
+	mux := http.NewServeMux()
+	handle := func(pattern string, handler func(w http.ResponseWriter, r *http.Request)) {
+		method, path, _ := strings.Cut(pattern, " ")
+		mux.HandleFunc(method+" /api/v3"+path, handler)
+	}
+	handle("GET /user", s.authorized(s.user))
+	handle("POST /user/repos", s.authorized(s.createRepo))
+	handle("POST /orgs/{org}/repos", s.authorized(s.createRepo))
+	handle("GET /repos/{owner}/{repo}", s.withRepo(s.getRepo))
+	handle("PUT /repos/{owner}/{repo}/topics", s.withRepo(s.replaceTopics))
+	handle("GET /repos/{owner}/{repo}/pulls", s.withRepo(s.listPulls))
+	handle("POST /repos/{owner}/{repo}/pulls", s.withRepo(s.createPull))
+	handle("GET /repos/{owner}/{repo}/pulls/{number}", s.withPull(s.getPull))
+	handle("PATCH /repos/{owner}/{repo}/pulls/{number}", s.withPull(s.editPull))
+	handle("PUT /repos/{owner}/{repo}/pulls/{number}/merge", s.withPull(s.mergePull))
+	handle("GET /repos/{owner}/{repo}/pulls/{number}/reviews", s.withPull(s.emptyList))
+	handle("GET /repos/{owner}/{repo}/pulls/{number}/commits", s.withPull(s.pullCommits))
+	handle("GET /repos/{owner}/{repo}/commits/{ref}/check-runs", s.withRepo(s.checkRuns))
+	handle("GET /repos/{owner}/{repo}/commits/{ref}/status", s.withRepo(s.combinedStatus))
+	handle("DELETE /repos/{owner}/{repo}/git/refs/heads/{branch...}", s.withRepo(s.deleteBranch))
+	handle("GET /repos/{owner}/{repo}/issues/{number}", s.withRepo(s.getIssue))
+	handle("GET /repos/{owner}/{repo}/events", s.withRepo(s.listEvents))
+	handle("GET /repos/{owner}/{repo}/labels", s.withRepo(s.listLabels))
+	handle("POST /repos/{owner}/{repo}/labels", s.withRepo(s.createLabel))
+	handle("PATCH /repos/{owner}/{repo}/labels/{name}", s.withRepo(s.editLabel))
+	handle("DELETE /repos/{owner}/{repo}/labels/{name}", s.withRepo(s.deleteLabel))
+	mux.HandleFunc("POST /api/graphql", s.authorized(s.graphQL))
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{Method: r.Method, Path: strings.TrimPrefix(r.URL.Path, "/api/v3")})
+		s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Host returns the host settings that point a client at the server
+func (s *Server) Host() github.Host {
+	return github.Host{
+		Name:      Hostname,
+		APIURL:    s.URL + "/api/v3/",
+		UploadURL: s.URL + "/api/uploads/",
+		Token:     s.Token,
+	}
+}
+
+// Client returns a client of the server
+func (s *Server) Client(t testing.TB) *github.Client {
+	client, err := github.NewHostClient(s.Host())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+// AddRepo adds a repository, defaulting its branch to main, and returns it
+func (s *Server) AddRepo(fullName string) *Repo {
+	owner, name, _ := strings.Cut(fullName, "/")
+	repo := newRepo(owner, name)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.repos[strings.ToLower(fullName)] = repo
+	return repo
+}
+
+func newRepo(owner, name string) *Repo {
+	return &Repo{
+		Owner:         owner,
+		Name:          name,
+		DefaultBranch: "main",
+		Pulls:         map[int]*PullRequest{},
+		Issues:        map[int]*Issue{},
+		CheckRuns:     map[string][]CheckRun{},
+	}
+}
+
+// Repo returns a repository, or nil when there is none
+func (s *Server) Repo(fullName string) *Repo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.repos[strings.ToLower(fullName)]
+}
+
+// AddPull adds an open pull request, numbered after the pull requests and
+// issues already there
+func (r *Repo) AddPull(pr PullRequest) *PullRequest {
+	pr.Number = r.nextNumber()
+	if pr.State == "" {
+		pr.State = "open"
+	}
+	if pr.Base == "" {
+		pr.Base = r.DefaultBranch
+	}
+	r.Pulls[pr.Number] = &pr
+	return &pr
+}
+
+// AddIssue adds an open issue, numbered like AddPull
+func (r *Repo) AddIssue(title string, labels ...string) *Issue {
+	issue := &Issue{Number: r.nextNumber(), Title: title, State: "open", Labels: labels}
+	r.Issues[issue.Number] = issue
+	return issue
+}
+
+func (r *Repo) nextNumber() int {
+	return len(r.Pulls) + len(r.Issues) + 1
+}
+
+// Requests returns the requests received so far, e.g.
+// {"PUT", "/repos/octo/app/pulls/1/merge"}
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.Token {
+			writeError(w, http.StatusUnauthorized, "Bad credentials")
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		next(w, r)
+	}
+}
+
+func (s *Server) withRepo(next func(w http.ResponseWriter, r *http.Request, repo *Repo)) http.HandlerFunc {
+	return s.authorized(func(w http.ResponseWriter, r *http.Request) {
+		repo := s.repos[strings.ToLower(r.PathValue("owner")+"/"+r.PathValue("repo"))]
+		if repo == nil {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		next(w, r, repo)
+	})
+}
+
+func (s *Server) withPull(next func(w http.ResponseWriter, r *http.Request, repo *Repo, pr *PullRequest)) http.HandlerFunc {
+	return s.withRepo(func(w http.ResponseWriter, r *http.Request, repo *Repo) {
+		number, _ := strconv.Atoi(r.PathValue("number"))
+		pr := repo.Pulls[number]
+		if pr == nil {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		next(w, r, repo, pr)
+	})
+}
+
+func (s *Server) user(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"login": s.Login})
+}
+
+func (s *Server) createRepo(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name        string `json:"name"`
+		Private     bool   `json:"private"`
+		Description string `json:"description"`
+		HasIssues   bool   `json:"has_issues"`
+		HasWiki     bool   `json:"has_wiki"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	owner := r.PathValue("org")
+	if owner == "" {
+		owner = s.Login
+	}
+	key := strings.ToLower(owner + "/" + body.Name)
+	if s.repos[key] != nil {
+		writeError(w, http.StatusUnprocessableEntity, "name already exists on this account")
+		return
+	}
+	repo := newRepo(owner, body.Name)
+	repo.Private, repo.Description = body.Private, body.Description
+	repo.HasIssues, repo.HasWiki = body.HasIssues, body.HasWiki
+	s.repos[key] = repo
+	writeJSON(w, http.StatusCreated, s.repoJSON(repo))
+}
+
+// allows reports whether the repository allows a merge method
+func (r *Repo) allows(method string) bool {
+	if r.MergeMethods == nil {
+		return method == "merge" || method == "squash" || method == "rebase"
+	}
+	for _, allowed := range r.MergeMethods {
+		if allowed == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) repoJSON(repo *Repo) map[string]interface{} {
+	result := map[string]interface{}{
+		"name":               repo.Name,
+		"full_name":          repo.FullName(),
+		"owner":              map[string]interface{}{"login": repo.Owner},
+		"private":            repo.Private,
+		"description":        repo.Description,
+		"topics":             repo.Topics,
+		"has_issues":         repo.HasIssues,
+		"has_wiki":           repo.HasWiki,
+		"default_branch":     repo.DefaultBranch,
+		"fork":               repo.Parent != "",
+		"allow_merge_commit": repo.allows("merge"),
+		"allow_squash_merge": repo.allows("squash"),
+		"allow_rebase_merge": repo.allows("rebase"),
+		"allow_auto_merge":   repo.AllowAutoMerge,
+		"html_url":           s.webURL(repo.FullName()),
+	}
+	if repo.Parent != "" {
+		result["parent"] = map[string]interface{}{"full_name": repo.Parent}
+	}
+	return result
+}
+
+func (s *Server) webURL(path string) string {
+	return "https://" + Hostname + "/" + path
+}
+
+func (s *Server) getRepo(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	writeJSON(w, http.StatusOK, s.repoJSON(repo))
+}
+
+func (s *Server) replaceTopics(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	var body struct {
+		Names []string `json:"names"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	repo.Topics = body.Names
+	writeJSON(w, http.StatusOK, map[string]interface{}{"names": body.Names})
+}
+
+func (s *Server) pullJSON(repo *Repo, pr *PullRequest) map[string]interface{} {
+	result := map[string]interface{}{
+		"number":    pr.Number,
+		"node_id":   pullNodeID(repo, pr),
+		"title":     pr.Title,
+		"body":      pr.Body,
+		"state":     pr.State,
+		"draft":     pr.Draft,
+		"merged":    pr.Merged,
+		"mergeable": pr.Mergeable,
+		"html_url":  s.webURL(fmt.Sprintf("%s/pull/%d", repo.FullName(), pr.Number)),
+		"user":      map[string]interface{}{"login": s.Login},
+		"head":      map[string]interface{}{"ref": pr.Head, "sha": pr.HeadSHA, "label": repo.Owner + ":" + pr.Head},
+		"base":      map[string]interface{}{"ref": pr.Base},
+	}
+	switch {
+	case pr.Draft:
+		result["mergeable_state"] = "draft"
+	case pr.Mergeable != nil && !*pr.Mergeable:
+		result["mergeable_state"] = "dirty"
+	default:
+		result["mergeable_state"] = "clean"
+	}
+	if pr.AutoMerge != "" {
+		result["auto_merge"] = map[string]interface{}{"merge_method": pr.AutoMerge}
+	}
+	if pr.Merged {
+		result["merge_commit_sha"] = mergeCommitSHA(pr)
+	}
+	return result
+}
+
+func pullNodeID(repo *Repo, pr *PullRequest) string {
+	return fmt.Sprintf("PR_%s_%d", repo.FullName(), pr.Number)
+}
+
+// mergeCommitSHA makes up a merge commit from the pull request
+func mergeCommitSHA(pr *PullRequest) string {
+	return fmt.Sprintf("%040x", pr.Number)
+}
+
+func (s *Server) listPulls(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	state := r.URL.Query().Get("state")
+	if state == "" {
+		state = "open"
+	}
+	head := r.URL.Query().Get("head")
+
+	var numbers []int
+	for number, pr := range repo.Pulls {
+		if state != "all" && pr.State != state {
+			continue
+		}
+		if head != "" && head != repo.Owner+":"+pr.Head {
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+	// Newest first, as GitHub lists them
+	sort.Sort(sort.Reverse(sort.IntSlice(numbers)))
+	pulls := []map[string]interface{}{}
+	for _, number := range numbers {
+		pulls = append(pulls, s.pullJSON(repo, repo.Pulls[number]))
+	}
+	writeJSON(w, http.StatusOK, pulls)
+}
+
+func (s *Server) createPull(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	var body struct {
+		Title string `json:"title"`
+		Head  string `json:"head"`
+		Base  string `json:"base"`
+		Body  string `json:"body"`
+		Draft bool   `json:"draft"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Title == "" || body.Head == "" || body.Base == "" {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	for _, pr := range repo.Pulls {
+		if pr.State == "open" && pr.Head == body.Head && pr.Base == body.Base {
+			writeError(w, http.StatusUnprocessableEntity, "A pull request already exists for "+repo.Owner+":"+body.Head)
+			return
+		}
+	}
+	pr := repo.AddPull(PullRequest{Title: body.Title, Body: body.Body, Head: body.Head, Base: body.Base, Draft: body.Draft})
+	writeJSON(w, http.StatusCreated, s.pullJSON(repo, pr))
+}
+
+func (s *Server) getPull(w http.ResponseWriter, r *http.Request, repo *Repo, pr *PullRequest) {
+	writeJSON(w, http.StatusOK, s.pullJSON(repo, pr))
+}
+
+func (s *Server) editPull(w http.ResponseWriter, r *http.Request, repo *Repo, pr *PullRequest) {
+	var body struct {
+		Title *string `json:"title"`
+		Body  *string `json:"body"`
+		State *string `json:"state"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	if body.Title != nil {
+		pr.Title = *body.Title
+	}
+	if body.Body != nil {
+		pr.Body = *body.Body
+	}
+	if body.State != nil {
+		pr.State = *body.State
+	}
+	writeJSON(w, http.StatusOK, s.pullJSON(repo, pr))
+}
+
+func (s *Server) mergePull(w http.ResponseWriter, r *http.Request, repo *Repo, pr *PullRequest) {
+	var body struct {
+		SHA         string `json:"sha"`
+		MergeMethod string `json:"merge_method"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	if body.MergeMethod == "" {
+		body.MergeMethod = "merge"
+	}
+	switch {
+	case pr.State != "open" || pr.Draft || (pr.Mergeable != nil && !*pr.Mergeable):
+		writeError(w, http.StatusMethodNotAllowed, "Pull Request is not mergeable")
+	case !repo.allows(body.MergeMethod):
+		writeError(w, http.StatusMethodNotAllowed, body.MergeMethod+" merges are not allowed on this repository")
+	case body.SHA != "" && body.SHA != pr.HeadSHA:
+		writeError(w, http.StatusConflict, "Head branch was modified. Review and try the merge again.")
+	default:
+		pr.State, pr.Merged, pr.MergeMethod, pr.AutoMerge = "closed", true, body.MergeMethod, ""
+		writeJSON(w, http.StatusOK, map[string]interface{}{"sha": mergeCommitSHA(pr), "merged": true, "message": "Pull Request successfully merged"})
+	}
+}
+
+func (s *Server) emptyList(w http.ResponseWriter, r *http.Request, repo *Repo, pr *PullRequest) {
+	writeJSON(w, http.StatusOK, []interface{}{})
+}
+
+func (s *Server) pullCommits(w http.ResponseWriter, r *http.Request, repo *Repo, pr *PullRequest) {
+	commits := []map[string]interface{}{}
+	if pr.HeadSHA != "" {
+		commits = append(commits, map[string]interface{}{"sha": pr.HeadSHA})
+	}
+	writeJSON(w, http.StatusOK, commits)
+}
+
+func (s *Server) checkRuns(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	runs := []map[string]interface{}{}
+	for _, run := range repo.CheckRuns[r.PathValue("ref")] {
+		runs = append(runs, map[string]interface{}{"name": run.Name, "status": run.Status, "conclusion": run.Conclusion})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"total_count": len(runs), "check_runs": runs})
+}
+
+func (s *Server) combinedStatus(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"state": "success", "statuses": []interface{}{}})
+}
+
+func (s *Server) deleteBranch(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	repo.DeletedBranches = append(repo.DeletedBranches, r.PathValue("branch"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	number, _ := strconv.Atoi(r.PathValue("number"))
+	// Pull requests are issues too
+	if pr := repo.Pulls[number]; pr != nil {
+		result := s.pullJSON(repo, pr)
+		result["pull_request"] = map[string]interface{}{"url": s.URL + r.URL.Path}
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+	issue := repo.Issues[number]
+	if issue == nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	labels := []map[string]interface{}{}
+	for _, label := range issue.Labels {
+		labels = append(labels, map[string]interface{}{"name": label})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"number":   issue.Number,
+		"title":    issue.Title,
+		"state":    issue.State,
+		"labels":   labels,
+		"html_url": s.webURL(fmt.Sprintf("%s/issues/%d", repo.FullName(), issue.Number)),
+	})
+}
+
+func (s *Server) listEvents(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	events := []map[string]interface{}{}
+	for i, event := range repo.Events {
+		events = append(events, map[string]interface{}{
+			"id":         strconv.Itoa(len(repo.Events) - i),
+			"type":       event.Type,
+			"actor":      map[string]interface{}{"login": event.Actor},
+			"repo":       map[string]interface{}{"name": repo.FullName()},
+			"payload":    event.Payload,
+			"created_at": event.Created.UTC().Format(time.RFC3339),
+		})
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+// label returns the index of a label, matching names like GitHub ignoring
+// case, or -1
+func (r *Repo) label(name string) int {
+	for i, label := range r.Labels {
+		if strings.EqualFold(label.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func labelJSON(label github.Label) map[string]interface{} {
+	return map[string]interface{}{"name": label.Name, "color": label.Color, "description": label.Description}
+}
+
+func (s *Server) listLabels(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	labels := []map[string]interface{}{}
+	for _, label := range repo.Labels {
+		labels = append(labels, labelJSON(label))
+	}
+	writeJSON(w, http.StatusOK, labels)
+}
+
+func (s *Server) createLabel(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	var body struct {
+		Name        string `json:"name"`
+		Color       string `json:"color"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	if repo.label(body.Name) >= 0 {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed: already_exists")
+		return
+	}
+	label := github.Label{Name: body.Name, Color: body.Color, Description: body.Description}
+	repo.Labels = append(repo.Labels, label)
+	writeJSON(w, http.StatusCreated, labelJSON(label))
+}
+
+func (s *Server) editLabel(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	i := repo.label(r.PathValue("name"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	var body struct {
+		Name        *string `json:"name"`
+		Color       *string `json:"color"`
+		Description *string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	label := &repo.Labels[i]
+	if body.Name != nil {
+		label.Name = *body.Name
+	}
+	if body.Color != nil {
+		label.Color = *body.Color
+	}
+	if body.Description != nil {
+		label.Description = *body.Description
+	}
+	writeJSON(w, http.StatusOK, labelJSON(*label))
+}
+
+func (s *Server) deleteLabel(w http.ResponseWriter, r *http.Request, repo *Repo) {
+	i := repo.label(r.PathValue("name"))
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	repo.Labels = append(repo.Labels[:i], repo.Labels[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// graphQL serves the auto-merge mutations
+func (s *Server) graphQL(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+	id, _ := body.Variables["id"].(string)
+	repo, pr := s.pullByNodeID(id)
+	if pr == nil {
+		writeGraphQLError(w, fmt.Sprintf("Could not resolve to a node with the global id of '%s'", id))
+		return
+	}
+
+	switch {
+	case strings.Contains(body.Query, "enablePullRequestAutoMerge"):
+		if !repo.AllowAutoMerge {
+			writeGraphQLError(w, "Pull request Auto merge is not allowed for this repository")
+			return
+		}
+		if pr.Mergeable != nil && *pr.Mergeable && len(repo.CheckRuns[pr.HeadSHA]) == 0 {
+			writeGraphQLError(w, "Pull request is in clean status")
+			return
+		}
+		method, _ := body.Variables["method"].(string)
+		pr.AutoMerge = strings.ToLower(method)
+	case strings.Contains(body.Query, "disablePullRequestAutoMerge"):
+		pr.AutoMerge = ""
+	default:
+		writeGraphQLError(w, "unsupported query")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{}})
+}
+
+func (s *Server) pullByNodeID(id string) (*Repo, *PullRequest) {
+	for _, repo := range s.repos {
+		for _, pr := range repo.Pulls {
+			if pullNodeID(repo, pr) == id {
+				return repo, pr
+			}
+		}
+	}
+	return nil, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"message": message})
+}
+
+func writeGraphQLError(w http.ResponseWriter, message string) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"errors": []map[string]interface{}{{"message": message}}})
+}
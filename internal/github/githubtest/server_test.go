@@ -0,0 +1,150 @@
+package githubtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/EndlessUphill/git-helper/internal/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositories(t *testing.T) {
+	server := NewServer(t)
+	client := server.Client(t)
+	ctx := context.Background()
+
+	config := github.RepoConfig{Private: true, Description: "Copy", Topics: []string{"go"}}
+	require.NoError(t, client.CreateRepository(ctx, "app", "octo-org", true, config))
+	repo := server.Repo("octo-org/app")
+	require.NotNil(t, repo)
+	assert.True(t, repo.Private)
+	assert.Equal(t, []string{"go"}, repo.Topics)
+
+	err := client.CreateRepository(ctx, "app", "octo-org", true, config)
+	assert.ErrorIs(t, err, github.ErrRepoExists)
+
+	require.NoError(t, client.CreateRepository(ctx, "tools", "", false, github.RepoConfig{}))
+	assert.NotNil(t, server.Repo("octocat/tools"))
+
+	server.AddRepo("octocat/fork").Parent = "upstream/app"
+	parent, err := client.GetParent(ctx, "octocat", "fork")
+	require.NoError(t, err)
+	assert.Equal(t, "upstream/app", parent)
+	_, err = client.GetParent(ctx, "octocat", "tools")
+	assert.ErrorContains(t, err, "not a fork")
+}
+
+func TestUnauthorized(t *testing.T) {
+	server := NewServer(t)
+	server.AddRepo("octo/app")
+	host := server.Host()
+	host.Token = "wrong"
+	client, err := github.NewHostClient(host)
+	require.NoError(t, err)
+
+	_, err = client.FindPullRequest(context.Background(), "octo", "app", "octo", "feature")
+	assert.ErrorIs(t, err, github.ErrUnauthorized)
+}
+
+func TestPullRequests(t *testing.T) {
+	server := NewServer(t)
+	repo := server.AddRepo("octo/app")
+	repo.MergeMethods = []string{"squash"}
+	client := server.Client(t)
+	ctx := context.Background()
+
+	_, err := client.FindPullRequest(ctx, "octo", "app", "octo", "feature")
+	assert.ErrorIs(t, err, github.ErrNoPullRequest)
+
+	number, url, err := client.CreatePullRequest(ctx, "octo", "app", "feature", "main", "Add feature", "Body", false)
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.test/octo/app/pull/1", url)
+	found, err := client.FindPullRequest(ctx, "octo", "app", "octo", "feature")
+	require.NoError(t, err)
+	assert.Equal(t, number, found)
+
+	_, err = client.UpdatePullRequest(ctx, "octo", "app", number, "", "New body")
+	require.NoError(t, err)
+	assert.Equal(t, "Add feature", repo.Pulls[number].Title)
+	assert.Equal(t, "New body", repo.Pulls[number].Body)
+
+	repo.Pulls[number].HeadSHA = "abc123"
+	repo.CheckRuns["abc123"] = []CheckRun{{Name: "build", Status: "completed", Conclusion: "success"}}
+	status, err := client.PullRequestStatus(ctx, "octo", "app", number)
+	require.NoError(t, err)
+	assert.Equal(t, "feature", status.Head)
+	assert.Equal(t, []github.Check{{Name: "build", State: github.CheckSuccess}}, status.Checks)
+
+	methods, err := client.MergeMethods(ctx, "octo", "app")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"squash"}, methods)
+	_, err = client.MergePullRequest(ctx, "octo", "app", number, "merge", "abc123")
+	assert.Error(t, err)
+	_, err = client.MergePullRequest(ctx, "octo", "app", number, "squash", "outdated")
+	assert.ErrorContains(t, err, "Head branch was modified")
+	_, err = client.MergePullRequest(ctx, "octo", "app", number, "squash", "abc123")
+	require.NoError(t, err)
+	assert.True(t, repo.Pulls[number].Merged)
+
+	require.NoError(t, client.DeleteBranch(ctx, "octo", "app", "feature/x"))
+	assert.Equal(t, []string{"feature/x"}, repo.DeletedBranches)
+	assert.Contains(t, server.Requests(), Request{Method: "PUT", Path: "/repos/octo/app/pulls/1/merge"})
+}
+
+func TestAutoMerge(t *testing.T) {
+	server := NewServer(t)
+	repo := server.AddRepo("octo/app")
+	pr := repo.AddPull(PullRequest{Title: "Add feature", Head: "feature", HeadSHA: "abc123"})
+	client := server.Client(t)
+	ctx := context.Background()
+
+	err := client.EnableAutoMerge(ctx, "octo", "app", pr.Number, "squash")
+	assert.ErrorIs(t, err, github.ErrAutoMergeNotAllowed)
+
+	repo.AllowAutoMerge = true
+	require.NoError(t, client.EnableAutoMerge(ctx, "octo", "app", pr.Number, "squash"))
+	assert.Equal(t, "squash", pr.AutoMerge)
+	require.NoError(t, client.DisableAutoMerge(ctx, "octo", "app", pr.Number))
+	assert.Empty(t, pr.AutoMerge)
+}
+
+func TestIssues(t *testing.T) {
+	server := NewServer(t)
+	repo := server.AddRepo("octo/app")
+	issue := repo.AddIssue("Fix login", "bug")
+	pr := repo.AddPull(PullRequest{Title: "Fix it", Head: "fix"})
+	client := server.Client(t)
+
+	got, err := client.GetIssue(context.Background(), "octo", "app", issue.Number)
+	require.NoError(t, err)
+	assert.Equal(t, "Fix login", got.Title)
+	assert.Equal(t, []string{"bug"}, got.Labels)
+
+	_, err = client.GetIssue(context.Background(), "octo", "app", pr.Number)
+	assert.ErrorIs(t, err, github.ErrNotAnIssue)
+}
+
+func TestLabels(t *testing.T) {
+	server := NewServer(t)
+	repo := server.AddRepo("octo/app")
+	repo.Labels = []github.Label{{Name: "Bug", Color: "ff0000"}, {Name: "wontfix", Color: "ffffff"}}
+	client := server.Client(t)
+	ctx := context.Background()
+
+	labels, err := client.ListLabels(ctx, "octo", "app")
+	require.NoError(t, err)
+	assert.Equal(t, repo.Labels, labels)
+
+	desired := []github.Label{{Name: "bug", Color: "d73a4a", Description: "Something isn't working"}, {Name: "good first issue", Color: "7057ff"}}
+	for _, change := range github.PlanLabels(labels, desired, true) {
+		require.NoError(t, client.ApplyLabelChange(ctx, "octo", "app", change))
+	}
+	assert.Equal(t, []github.Label{
+		{Name: "bug", Color: "d73a4a", Description: "Something isn't working"},
+		{Name: "good first issue", Color: "7057ff"},
+	}, repo.Labels)
+
+	err = client.ApplyLabelChange(ctx, "octo", "app", github.LabelChange{Action: github.LabelCreate, Label: github.Label{Name: "BUG"}})
+	assert.ErrorContains(t, err, "already_exists")
+}
@@ -0,0 +1,66 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// GraphQLError is an error reported in the errors of a GraphQL response
+type GraphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// GraphQLErrors are the errors of a failed GraphQL request
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// graphQL runs a GraphQL query or mutation and decodes its data into result,
+// for the features the REST API lacks
+func (c *Client) graphQL(ctx context.Context, query string, variables map[string]interface{}, result interface{}) error {
+	body := map[string]interface{}{"query": query, "variables": variables}
+	req, err := c.client.NewRequest("POST", graphQLURL(c.client.BaseURL), body)
+	if err != nil {
+		return err
+	}
+
+	response := struct {
+		Data   interface{}   `json:"data"`
+		Errors GraphQLErrors `json:"errors"`
+	}{Data: result}
+	if _, err := c.client.Do(ctx, req, &response); err != nil {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == 401 {
+			return ErrUnauthorized
+		}
+		return err
+	}
+	if len(response.Errors) > 0 {
+		return response.Errors
+	}
+	return nil
+}
+
+// graphQLURL returns the GraphQL endpoint next to a REST API base URL:
+// https://api.github.com/graphql, or https://<host>/api/graphql for
+// GitHub Enterprise Server
+func graphQLURL(base *url.URL) string {
+	endpoint := *base
+	if strings.HasSuffix(endpoint.Path, "/api/v3/") {
+		endpoint.Path = strings.TrimSuffix(endpoint.Path, "v3/") + "graphql"
+	} else {
+		endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + "/graphql"
+	}
+	return endpoint.String()
+}
@@ -0,0 +1,142 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v53/github"
+	"golang.org/x/oauth2"
+)
+
+// DefaultHost is the public GitHub host
+const DefaultHost = "github.com"
+
+var ErrInvalidRepoURL = errors.New("invalid repository URL")
+
+// Host describes a GitHub instance: github.com or a GitHub Enterprise Server
+type Host struct {
+	// Name is the hostname used in clone URLs, e.g. github.example.com
+	Name string
+	// APIURL overrides the REST API base URL (default https://<name>/api/v3/)
+	APIURL string
+	// UploadURL overrides the upload API base URL (default https://<name>/api/uploads/)
+	UploadURL string
+	// Token is the access token for this host
+	Token string
+}
+
+// IsEnterprise reports whether the host is a GitHub Enterprise Server
+func (h Host) IsEnterprise() bool {
+	return h.Name != "" && h.Name != DefaultHost
+}
+
+// APIBaseURL returns the REST API base URL of the host
+func (h Host) APIBaseURL() string {
+	if h.APIURL != "" {
+		return h.APIURL
+	}
+	if !h.IsEnterprise() {
+		return "https://api.github.com/"
+	}
+	return fmt.Sprintf("https://%s/api/v3/", h.Name)
+}
+
+// UploadBaseURL returns the upload API base URL of the host
+func (h Host) UploadBaseURL() string {
+	if h.UploadURL != "" {
+		return h.UploadURL
+	}
+	if !h.IsEnterprise() {
+		return "https://uploads.github.com/"
+	}
+	return fmt.Sprintf("https://%s/api/uploads/", h.Name)
+}
+
+// CloneURL returns the SSH or HTTPS clone URL of owner/repo on this host
+func (h Host) CloneURL(repo string, ssh bool) string {
+	if ssh {
+		return fmt.Sprintf("git@%s:%s.git", h.hostname(), repo)
+	}
+	return fmt.Sprintf("https://%s/%s.git", h.hostname(), repo)
+}
+
+// WebURL returns the browser URL of owner/repo on this host
+func (h Host) WebURL(repo string) string {
+	return fmt.Sprintf("https://%s/%s", h.hostname(), repo)
+}
+
+func (h Host) hostname() string {
+	if h.Name == "" {
+		return DefaultHost
+	}
+	return h.Name
+}
+
+// ParseRepoURL splits a clone URL into its host and owner/repo path. It
+// accepts HTTPS (https://host/owner/repo), scp-like SSH
+// (git@host:owner/repo.git) and ssh:// URLs.
+func ParseRepoURL(raw string) (string, string, error) {
+	var host, path string
+	switch {
+	case strings.Contains(raw, "://"):
+		u, err := url.Parse(raw)
+		if err != nil {
+			return "", "", fmt.Errorf("%w: %s", ErrInvalidRepoURL, raw)
+		}
+		host, path = u.Hostname(), u.Path
+	case strings.Contains(raw, "@") && strings.Contains(raw, ":"):
+		_, rest, _ := strings.Cut(raw, "@")
+		host, path, _ = strings.Cut(rest, ":")
+	default:
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidRepoURL, raw)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || strings.Count(path, "/") != 1 {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidRepoURL, raw)
+	}
+	return host, path, nil
+}
+
+// NewHostClient creates a client for the given host, using the Enterprise
+// API endpoints when the host is not github.com. Without a token the client
+// makes anonymous requests, which only read public data.
+func NewHostClient(host Host) (*Client, error) {
+	tc := &http.Client{Transport: newRetryTransport(nil)}
+	if host.Token != "" {
+		ts := oauth2.StaticTokenSource(
+			&oauth2.Token{AccessToken: host.Token},
+		)
+		tc = oauth2.NewClient(context.Background(), ts)
+		tc.Transport = newRetryTransport(tc.Transport)
+	}
+
+	if !host.IsEnterprise() && host.APIURL == "" {
+		return &Client{client: github.NewClient(tc)}, nil
+	}
+
+	client, err := github.NewEnterpriseClient(host.APIBaseURL(), host.UploadBaseURL(), tc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API URL for %s: %w", host.Name, err)
+	}
+	return &Client{client: client}, nil
+}
+
+// GetParent returns the owner/repo name of the repository a fork was created from
+func (c *Client) GetParent(ctx context.Context, owner, name string) (string, error) {
+	repo, _, err := c.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+			return "", ErrUnauthorized
+		}
+		return "", err
+	}
+	if repo.GetParent() == nil {
+		return "", fmt.Errorf("%s/%s is not a fork", owner, name)
+	}
+	return repo.GetParent().GetFullName(), nil
+}
@@ -0,0 +1,52 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRepoURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		host string
+		repo string
+	}{
+		{"https://github.com/user/repo", "github.com", "user/repo"},
+		{"https://github.com/user/repo.git", "github.com", "user/repo"},
+		{"git@github.com:user/repo.git", "github.com", "user/repo"},
+		{"git@github.example.com:team/app.git", "github.example.com", "team/app"},
+		{"ssh://git@github.example.com:2222/team/app.git", "github.example.com", "team/app"},
+		{"https://github.example.com/team/app/", "github.example.com", "team/app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			host, repo, err := ParseRepoURL(tt.url)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.host, host)
+			assert.Equal(t, tt.repo, repo)
+		})
+	}
+
+	for _, url := range []string{"user/repo", "https://github.com/user", "https://github.com/a/b/c"} {
+		_, _, err := ParseRepoURL(url)
+		assert.ErrorIs(t, err, ErrInvalidRepoURL, url)
+	}
+}
+
+func TestHostURLs(t *testing.T) {
+	public := Host{Name: DefaultHost}
+	assert.False(t, public.IsEnterprise())
+	assert.Equal(t, "https://api.github.com/", public.APIBaseURL())
+	assert.Equal(t, "git@github.com:user/repo.git", public.CloneURL("user/repo", true))
+
+	enterprise := Host{Name: "github.example.com"}
+	assert.True(t, enterprise.IsEnterprise())
+	assert.Equal(t, "https://github.example.com/api/v3/", enterprise.APIBaseURL())
+	assert.Equal(t, "https://github.example.com/api/uploads/", enterprise.UploadBaseURL())
+	assert.Equal(t, "https://github.example.com/team/app.git", enterprise.CloneURL("team/app", false))
+
+	enterprise.APIURL = "https://api.example.com/"
+	assert.Equal(t, "https://api.example.com/", enterprise.APIBaseURL())
+}
@@ -0,0 +1,150 @@
+package github
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// Inbox item kinds
+const (
+	InboxReview       = "review"
+	InboxNotification = "notification"
+)
+
+// InboxItem is a pull request awaiting review or a notification thread
+type InboxItem struct {
+	Kind string
+	// ThreadID identifies the notification thread, empty for review requests
+	ThreadID string
+	Repo     string
+	// Number is the issue or pull request number, 0 for other subjects
+	Number int
+	// Type is PullRequest, Issue, Release, Commit, Discussion...
+	Type    string
+	Title   string
+	Reason  string
+	URL     string
+	Unread  bool
+	Updated time.Time
+}
+
+// ReviewRequests returns the open pull requests whose review is requested
+// from the authenticated user or one of their teams
+func (c *Client) ReviewRequests(ctx context.Context, limit int) ([]InboxItem, error) {
+	opts := &github.SearchOptions{Sort: "updated", Order: "desc", ListOptions: github.ListOptions{PerPage: 100}}
+	var items []InboxItem
+	for {
+		result, resp, err := c.client.Search.Issues(ctx, "is:open is:pr review-requested:@me archived:false", opts)
+		if err != nil {
+			if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+				return nil, ErrUnauthorized
+			}
+			return nil, err
+		}
+		for _, issue := range result.Issues {
+			items = append(items, InboxItem{
+				Kind:    InboxReview,
+				Repo:    repoFromAPIURL(issue.GetRepositoryURL()),
+				Number:  issue.GetNumber(),
+				Type:    "PullRequest",
+				Title:   issue.GetTitle(),
+				Reason:  "review_requested",
+				URL:     issue.GetHTMLURL(),
+				Unread:  true,
+				Updated: issue.GetUpdatedAt().Time,
+			})
+			if limit > 0 && len(items) >= limit {
+				return items, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return items, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// Notifications returns the notification threads of the authenticated
+// user, only the unread ones unless all is set
+func (c *Client) Notifications(ctx context.Context, all bool, limit int) ([]InboxItem, error) {
+	opts := &github.NotificationListOptions{All: all, ListOptions: github.ListOptions{PerPage: 50}}
+	var items []InboxItem
+	for {
+		threads, resp, err := c.client.Activity.ListNotifications(ctx, opts)
+		if err != nil {
+			if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+				return nil, ErrUnauthorized
+			}
+			return nil, err
+		}
+		for _, thread := range threads {
+			items = append(items, notificationItem(thread))
+			if limit > 0 && len(items) >= limit {
+				return items, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return items, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func notificationItem(thread *github.Notification) InboxItem {
+	subject := thread.GetSubject()
+	item := InboxItem{
+		Kind:     InboxNotification,
+		ThreadID: thread.GetID(),
+		Repo:     thread.GetRepository().GetFullName(),
+		Type:     subject.GetType(),
+		Title:    subject.GetTitle(),
+		Reason:   thread.GetReason(),
+		URL:      thread.GetRepository().GetHTMLURL(),
+		Unread:   thread.GetUnread(),
+		Updated:  thread.GetUpdatedAt().Time,
+	}
+
+	// The subject URL points to the API; link issues and pull requests to
+	// their page and everything else to the repository
+	apiURL := subject.GetURL()
+	number, err := strconv.Atoi(apiURL[strings.LastIndex(apiURL, "/")+1:])
+	if err != nil {
+		return item
+	}
+	switch item.Type {
+	case "PullRequest":
+		item.Number = number
+		item.URL += "/pull/" + strconv.Itoa(number)
+	case "Issue":
+		item.Number = number
+		item.URL += "/issues/" + strconv.Itoa(number)
+	}
+	return item
+}
+
+// repoFromAPIURL turns https://api.github.com/repos/owner/name into owner/name
+func repoFromAPIURL(apiURL string) string {
+	if _, repo, ok := strings.Cut(apiURL, "/repos/"); ok {
+		return repo
+	}
+	return apiURL
+}
+
+// MarkThreadRead marks a notification thread as read
+func (c *Client) MarkThreadRead(ctx context.Context, threadID string) error {
+	_, err := c.client.Activity.MarkThreadRead(ctx, threadID)
+	return err
+}
+
+// PullRequestBranch returns the head branch of a pull request
+func (c *Client) PullRequestBranch(ctx context.Context, owner, name string, number int) (string, error) {
+	pr, _, err := c.client.PullRequests.Get(ctx, owner, name, number)
+	if err != nil {
+		return "", err
+	}
+	return pr.GetHead().GetRef(), nil
+}
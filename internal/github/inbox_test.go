@@ -0,0 +1,37 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationItem(t *testing.T) {
+	thread := &github.Notification{
+		ID:     github.String("7"),
+		Reason: github.String("mention"),
+		Unread: github.Bool(true),
+		Repository: &github.Repository{
+			FullName: github.String("octo/app"),
+			HTMLURL:  github.String("https://github.com/octo/app"),
+		},
+		Subject: &github.NotificationSubject{
+			Title: github.String("Fix login"),
+			Type:  github.String("PullRequest"),
+			URL:   github.String("https://api.github.com/repos/octo/app/pulls/12"),
+		},
+	}
+	item := notificationItem(thread)
+	assert.Equal(t, 12, item.Number)
+	assert.Equal(t, "https://github.com/octo/app/pull/12", item.URL)
+	assert.Equal(t, "7", item.ThreadID)
+
+	thread.Subject.Type = github.String("Release")
+	thread.Subject.URL = github.String("https://api.github.com/repos/octo/app/releases/99")
+	item = notificationItem(thread)
+	assert.Equal(t, 0, item.Number)
+	assert.Equal(t, "https://github.com/octo/app", item.URL)
+
+	assert.Equal(t, "octo/app", repoFromAPIURL("https://api.github.com/repos/octo/app"))
+}
@@ -0,0 +1,45 @@
+package github
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// ErrNotAnIssue is returned when an issue number belongs to a pull request
+var ErrNotAnIssue = errors.New("is a pull request, not an issue")
+
+// Issue is the summary of a GitHub issue
+type Issue struct {
+	Number int
+	Title  string
+	State  string
+	URL    string
+	Labels []string
+}
+
+// GetIssue fetches an issue of owner/name
+func (c *Client) GetIssue(ctx context.Context, owner, name string, number int) (*Issue, error) {
+	issue, _, err := c.client.Issues.Get(ctx, owner, name, number)
+	if err != nil {
+		if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	if issue.IsPullRequest() {
+		return nil, ErrNotAnIssue
+	}
+
+	result := &Issue{
+		Number: issue.GetNumber(),
+		Title:  issue.GetTitle(),
+		State:  issue.GetState(),
+		URL:    issue.GetHTMLURL(),
+	}
+	for _, label := range issue.Labels {
+		result.Labels = append(result.Labels, label.GetName())
+	}
+	return result, nil
+}
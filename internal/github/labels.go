@@ -0,0 +1,120 @@
+package github
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// Label is an issue label
+type Label struct {
+	Name        string `yaml:"name"`
+	Color       string `yaml:"color"`
+	Description string `yaml:"description"`
+}
+
+// Label change actions
+const (
+	LabelCreate = "create"
+	LabelUpdate = "update"
+	LabelDelete = "delete"
+)
+
+// LabelChange is a label to create, update or delete. Current is the
+// existing label for updates and deletes.
+type LabelChange struct {
+	Action  string
+	Label   Label
+	Current Label
+}
+
+// ListLabels returns the labels of owner/name
+func (c *Client) ListLabels(ctx context.Context, owner, name string) ([]Label, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	var labels []Label
+	for {
+		page, resp, err := c.client.Issues.ListLabels(ctx, owner, name, opts)
+		if err != nil {
+			if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+				return nil, ErrUnauthorized
+			}
+			return nil, err
+		}
+		for _, label := range page {
+			labels = append(labels, Label{
+				Name:        label.GetName(),
+				Color:       label.GetColor(),
+				Description: label.GetDescription(),
+			})
+		}
+		if resp.NextPage == 0 {
+			return labels, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// PlanLabels compares the labels of a repository with the desired ones.
+// Names match case-insensitively, so renaming "Bug" to "bug" is an update,
+// and an empty color or description keeps the current one. Labels that
+// aren't desired are only deleted with prune.
+func PlanLabels(current, desired []Label, prune bool) []LabelChange {
+	existing := map[string]Label{}
+	for _, label := range current {
+		existing[strings.ToLower(label.Name)] = label
+	}
+
+	var changes []LabelChange
+	wanted := map[string]bool{}
+	for _, label := range desired {
+		label.Color = strings.ToLower(strings.TrimPrefix(label.Color, "#"))
+		key := strings.ToLower(label.Name)
+		wanted[key] = true
+
+		old, ok := existing[key]
+		if !ok {
+			changes = append(changes, LabelChange{Action: LabelCreate, Label: label})
+			continue
+		}
+		if label.Color == "" {
+			label.Color = old.Color
+		}
+		if label.Description == "" {
+			label.Description = old.Description
+		}
+		if old.Name != label.Name || !strings.EqualFold(old.Color, label.Color) || old.Description != label.Description {
+			changes = append(changes, LabelChange{Action: LabelUpdate, Label: label, Current: old})
+		}
+	}
+
+	if prune {
+		for _, label := range current {
+			if !wanted[strings.ToLower(label.Name)] {
+				changes = append(changes, LabelChange{Action: LabelDelete, Current: label})
+			}
+		}
+	}
+	return changes
+}
+
+// ApplyLabelChange creates, updates or deletes a label of owner/name
+func (c *Client) ApplyLabelChange(ctx context.Context, owner, name string, change LabelChange) error {
+	label := &github.Label{Name: github.String(change.Label.Name)}
+	if change.Label.Color != "" {
+		label.Color = github.String(change.Label.Color)
+	}
+	if change.Label.Description != "" {
+		label.Description = github.String(change.Label.Description)
+	}
+	var err error
+	switch change.Action {
+	case LabelCreate:
+		_, _, err = c.client.Issues.CreateLabel(ctx, owner, name, label)
+	case LabelUpdate:
+		_, _, err = c.client.Issues.EditLabel(ctx, owner, name, change.Current.Name, label)
+	case LabelDelete:
+		_, err = c.client.Issues.DeleteLabel(ctx, owner, name, change.Current.Name)
+	}
+	return err
+}
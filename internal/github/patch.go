@@ -0,0 +1,79 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// ErrNotPatchURL is returned for URLs that aren't a pull request or commit
+var ErrNotPatchURL = errors.New("not a pull request or commit URL")
+
+// PatchURL is a pull request or commit on a GitHub host
+type PatchURL struct {
+	Host  string
+	Owner string
+	Repo  string
+	// Number is set for pull requests, Commit for commits
+	Number int
+	Commit string
+}
+
+// ParsePatchURL parses the browser URL of a pull request
+// (https://host/owner/repo/pull/1) or commit (https://host/owner/repo/commit/sha),
+// with or without a .patch or .diff suffix or trailing path such as /files
+func ParsePatchURL(raw string) (PatchURL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return PatchURL{}, fmt.Errorf("%w: %s", ErrNotPatchURL, raw)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 {
+		return PatchURL{}, fmt.Errorf("%w: %s", ErrNotPatchURL, raw)
+	}
+	id := strings.TrimSuffix(strings.TrimSuffix(parts[3], ".patch"), ".diff")
+	result := PatchURL{Host: u.Hostname(), Owner: parts[0], Repo: parts[1]}
+
+	switch {
+	// A commit of a pull request: /pull/1/commits/sha
+	case parts[2] == "pull" && len(parts) >= 6 && parts[4] == "commits":
+		result.Commit = strings.TrimSuffix(strings.TrimSuffix(parts[5], ".patch"), ".diff")
+	case parts[2] == "pull":
+		number, err := strconv.Atoi(id)
+		if err != nil {
+			return PatchURL{}, fmt.Errorf("%w: %s", ErrNotPatchURL, raw)
+		}
+		result.Number = number
+	case parts[2] == "commit":
+		result.Commit = id
+	default:
+		return PatchURL{}, fmt.Errorf("%w: %s", ErrNotPatchURL, raw)
+	}
+	return result, nil
+}
+
+// PullRequestPatch returns the commits of a pull request as a mailbox of
+// patches
+func (c *Client) PullRequestPatch(ctx context.Context, owner, name string, number int) (string, error) {
+	patch, _, err := c.client.PullRequests.GetRaw(ctx, owner, name, number, github.RawOptions{Type: github.Patch})
+	return patch, rawError(err)
+}
+
+// CommitPatch returns a commit as a patch
+func (c *Client) CommitPatch(ctx context.Context, owner, name, sha string) (string, error) {
+	patch, _, err := c.client.Repositories.GetCommitRaw(ctx, owner, name, sha, github.RawOptions{Type: github.Patch})
+	return patch, rawError(err)
+}
+
+func rawError(err error) error {
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response.StatusCode == 401 {
+		return ErrUnauthorized
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePatchURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want PatchURL
+	}{
+		{"https://github.com/octo/app/pull/12", PatchURL{Host: "github.com", Owner: "octo", Repo: "app", Number: 12}},
+		{"https://github.com/octo/app/pull/12.patch", PatchURL{Host: "github.com", Owner: "octo", Repo: "app", Number: 12}},
+		{"https://github.com/octo/app/pull/12/files", PatchURL{Host: "github.com", Owner: "octo", Repo: "app", Number: 12}},
+		{"https://github.example.com/octo/app/commit/abc123.diff", PatchURL{Host: "github.example.com", Owner: "octo", Repo: "app", Commit: "abc123"}},
+		{"https://github.com/octo/app/pull/12/commits/abc123", PatchURL{Host: "github.com", Owner: "octo", Repo: "app", Commit: "abc123"}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePatchURL(tt.raw)
+		require.NoError(t, err, tt.raw)
+		assert.Equal(t, tt.want, got, tt.raw)
+	}
+
+	for _, raw := range []string{"https://github.com/octo/app", "https://github.com/octo/app/issues/3", "https://github.com/octo/app/pull/abc", "file:///tmp/x.patch"} {
+		_, err := ParsePatchURL(raw)
+		assert.ErrorIs(t, err, ErrNotPatchURL, raw)
+	}
+}
+
+func TestPatches(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/octo/app/pulls/12", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/vnd.github.v3.patch", r.Header.Get("Accept"))
+		w.Write([]byte("From abc Mon Sep 17 00:00:00 2001\nSubject: [PATCH] Fix\n"))
+	})
+	mux.HandleFunc("/api/v3/repos/octo/app/commits/abc123", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/vnd.github.v3.patch", r.Header.Get("Accept"))
+		w.Write([]byte("From abc123 Mon Sep 17 00:00:00 2001\n"))
+	})
+	mux.HandleFunc("/api/v3/repos/octo/app/pulls/13", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message": "Bad credentials"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := NewHostClient(Host{Name: "github.example.com", APIURL: server.URL + "/api/v3/", UploadURL: server.URL + "/api/uploads/", Token: "token"})
+	require.NoError(t, err)
+
+	patch, err := client.PullRequestPatch(context.Background(), "octo", "app", 12)
+	require.NoError(t, err)
+	assert.Contains(t, patch, "Subject: [PATCH] Fix")
+
+	patch, err = client.CommitPatch(context.Background(), "octo", "app", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "From abc123 Mon Sep 17 00:00:00 2001\n", patch)
+
+	_, err = client.PullRequestPatch(context.Background(), "octo", "app", 13)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
@@ -0,0 +1,403 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// ErrNoPullRequest is returned when a branch has no open pull request
+var ErrNoPullRequest = errors.New("no open pull request")
+
+// Check states, normalized from check runs and commit statuses
+const (
+	CheckPending = "pending"
+	CheckSuccess = "success"
+	CheckSkipped = "skipped"
+	CheckFailure = "failure"
+)
+
+// Check is a CI check run or commit status on a commit
+type Check struct {
+	Name  string
+	State string
+	URL   string
+}
+
+// Review is the latest review of a reviewer: APPROVED, CHANGES_REQUESTED,
+// COMMENTED or DISMISSED
+type Review struct {
+	User  string
+	State string
+}
+
+// PullRequestStatus is everything needed to decide whether a pull request
+// can be merged
+type PullRequestStatus struct {
+	Number  int
+	Title   string
+	URL     string
+	State   string
+	Draft   bool
+	Merged  bool
+	Base    string
+	Head    string
+	HeadSHA string
+	// Mergeable is nil while GitHub is still computing it
+	Mergeable *bool
+	// MergeableState is clean, blocked, behind, dirty, unstable, draft or unknown
+	MergeableState string
+	// AutoMerge is the merge method auto-merge will use, empty when disabled
+	AutoMerge          string
+	Reviews            []Review
+	RequestedReviewers []string
+	Checks             []Check
+}
+
+// CheckCounts counts the passed (including skipped), pending and failed checks
+func (s *PullRequestStatus) CheckCounts() (passed, pending, failed int) {
+	return CountChecks(s.Checks)
+}
+
+// CountChecks counts the passed (including skipped), pending and failed checks
+func CountChecks(checks []Check) (passed, pending, failed int) {
+	for _, check := range checks {
+		switch check.State {
+		case CheckSuccess, CheckSkipped:
+			passed++
+		case CheckFailure:
+			failed++
+		default:
+			pending++
+		}
+	}
+	return passed, pending, failed
+}
+
+// FindPullRequest returns the number of the open pull request whose head is
+// branch in headOwner's repository
+func (c *Client) FindPullRequest(ctx context.Context, owner, name, headOwner, branch string) (int, error) {
+	opts := &github.PullRequestListOptions{State: "open", Head: headOwner + ":" + branch}
+	prs, _, err := c.client.PullRequests.List(ctx, owner, name, opts)
+	if err != nil {
+		if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+			return 0, ErrUnauthorized
+		}
+		return 0, err
+	}
+	if len(prs) == 0 {
+		return 0, ErrNoPullRequest
+	}
+	return prs[0].GetNumber(), nil
+}
+
+// PullRequestStatus fetches a pull request with its reviews and checks
+func (c *Client) PullRequestStatus(ctx context.Context, owner, name string, number int) (*PullRequestStatus, error) {
+	pr, _, err := c.client.PullRequests.Get(ctx, owner, name, number)
+	if err != nil {
+		if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	status := &PullRequestStatus{
+		Number:         pr.GetNumber(),
+		Title:          pr.GetTitle(),
+		URL:            pr.GetHTMLURL(),
+		State:          pr.GetState(),
+		Draft:          pr.GetDraft(),
+		Merged:         pr.GetMerged(),
+		Base:           pr.GetBase().GetRef(),
+		Head:           pr.GetHead().GetRef(),
+		HeadSHA:        pr.GetHead().GetSHA(),
+		Mergeable:      pr.Mergeable,
+		MergeableState: pr.GetMergeableState(),
+		AutoMerge:      strings.ToLower(pr.GetAutoMerge().GetMergeMethod()),
+	}
+	for _, user := range pr.RequestedReviewers {
+		status.RequestedReviewers = append(status.RequestedReviewers, user.GetLogin())
+	}
+	for _, team := range pr.RequestedTeams {
+		status.RequestedReviewers = append(status.RequestedReviewers, "@"+team.GetSlug())
+	}
+
+	if status.Reviews, err = c.latestReviews(ctx, owner, name, number); err != nil {
+		return nil, err
+	}
+	if status.Checks, err = c.Checks(ctx, owner, name, status.HeadSHA); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// latestReviews keeps the last review of each reviewer; comments don't
+// replace an approval or a change request
+func (c *Client) latestReviews(ctx context.Context, owner, name string, number int) ([]Review, error) {
+	latest := map[string]string{}
+	var order []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		reviews, resp, err := c.client.PullRequests.ListReviews(ctx, owner, name, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, review := range reviews {
+			user, state := review.GetUser().GetLogin(), review.GetState()
+			previous, seen := latest[user]
+			if !seen {
+				order = append(order, user)
+			}
+			if state == "COMMENTED" && seen && previous != "COMMENTED" {
+				continue
+			}
+			latest[user] = state
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	reviews := make([]Review, 0, len(order))
+	for _, user := range order {
+		reviews = append(reviews, Review{User: user, State: latest[user]})
+	}
+	return reviews, nil
+}
+
+// Checks returns the check runs and commit statuses of ref, sorted by name
+func (c *Client) Checks(ctx context.Context, owner, name, ref string) ([]Check, error) {
+	var checks []Check
+
+	runOpts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		result, resp, err := c.client.Checks.ListCheckRunsForRef(ctx, owner, name, ref, runOpts)
+		if err != nil {
+			return nil, err
+		}
+		for _, run := range result.CheckRuns {
+			checks = append(checks, Check{
+				Name:  run.GetName(),
+				State: checkRunState(run.GetStatus(), run.GetConclusion()),
+				URL:   run.GetHTMLURL(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		runOpts.Page = resp.NextPage
+	}
+
+	combined, _, err := c.client.Repositories.GetCombinedStatus(ctx, owner, name, ref, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range combined.Statuses {
+		checks = append(checks, Check{
+			Name:  s.GetContext(),
+			State: commitStatusState(s.GetState()),
+			URL:   s.GetTargetURL(),
+		})
+	}
+
+	sort.SliceStable(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+	return checks, nil
+}
+
+func checkRunState(status, conclusion string) string {
+	if status != "completed" {
+		return CheckPending
+	}
+	switch conclusion {
+	case "success":
+		return CheckSuccess
+	case "neutral", "skipped":
+		return CheckSkipped
+	case "failure", "cancelled", "timed_out", "action_required", "startup_failure", "stale":
+		return CheckFailure
+	}
+	return CheckPending
+}
+
+func commitStatusState(state string) string {
+	switch state {
+	case "success":
+		return CheckSuccess
+	case "failure", "error":
+		return CheckFailure
+	}
+	return CheckPending
+}
+
+// MergeMethods returns the merge methods the repository allows, in the
+// order merge, squash, rebase
+func (c *Client) MergeMethods(ctx context.Context, owner, name string) ([]string, error) {
+	repo, _, err := c.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return nil, err
+	}
+	var methods []string
+	if repo.GetAllowMergeCommit() {
+		methods = append(methods, "merge")
+	}
+	if repo.GetAllowSquashMerge() {
+		methods = append(methods, "squash")
+	}
+	if repo.GetAllowRebaseMerge() {
+		methods = append(methods, "rebase")
+	}
+	return methods, nil
+}
+
+// MergePullRequest merges a pull request with method, only if its head is
+// still headSHA, and returns the merge commit
+func (c *Client) MergePullRequest(ctx context.Context, owner, name string, number int, method, headSHA string) (string, error) {
+	result, _, err := c.client.PullRequests.Merge(ctx, owner, name, number, "", &github.PullRequestOptions{
+		MergeMethod: method,
+		SHA:         headSHA,
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.GetSHA(), nil
+}
+
+// DeleteBranch deletes a branch of owner/name
+func (c *Client) DeleteBranch(ctx context.Context, owner, name, branch string) error {
+	_, err := c.client.Git.DeleteRef(ctx, owner, name, "heads/"+branch)
+	return err
+}
+
+// PullRequest is an open pull request
+type PullRequest struct {
+	Number  int
+	Title   string
+	Branch  string
+	Draft   bool
+	URL     string
+	Updated time.Time
+}
+
+// OpenPullRequestsBy returns the open pull requests of owner/name opened by
+// a user, the most recently updated first
+func (c *Client) OpenPullRequestsBy(ctx context.Context, owner, name, login string) ([]PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State:       "open",
+		Sort:        "updated",
+		Direction:   "desc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var result []PullRequest
+	for {
+		prs, resp, err := c.client.PullRequests.List(ctx, owner, name, opts)
+		if err != nil {
+			var errResp *github.ErrorResponse
+			if errors.As(err, &errResp) && errResp.Response.StatusCode == 401 {
+				return nil, ErrUnauthorized
+			}
+			return nil, err
+		}
+		for _, pr := range prs {
+			if !strings.EqualFold(pr.GetUser().GetLogin(), login) {
+				continue
+			}
+			result = append(result, PullRequest{
+				Number:  pr.GetNumber(),
+				Title:   pr.GetTitle(),
+				Branch:  pr.GetHead().GetRef(),
+				Draft:   pr.GetDraft(),
+				URL:     pr.GetHTMLURL(),
+				Updated: pr.GetUpdatedAt().Time,
+			})
+		}
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// CreatePullRequest opens a pull request of branch head into base and returns
+// its number and URL
+func (c *Client) CreatePullRequest(ctx context.Context, owner, name, head, base, title, body string, draft bool) (int, string, error) {
+	pr, _, err := c.client.PullRequests.Create(ctx, owner, name, &github.NewPullRequest{
+		Title: github.String(title),
+		Head:  github.String(head),
+		Base:  github.String(base),
+		Body:  github.String(body),
+		Draft: github.Bool(draft),
+	})
+	if err != nil {
+		return 0, "", err
+	}
+	return pr.GetNumber(), pr.GetHTMLURL(), nil
+}
+
+// UpdatePullRequest replaces the title and body of a pull request and returns
+// its URL. An empty title is left unchanged.
+func (c *Client) UpdatePullRequest(ctx context.Context, owner, name string, number int, title, body string) (string, error) {
+	update := &github.PullRequest{Body: github.String(body)}
+	if title != "" {
+		update.Title = github.String(title)
+	}
+	pr, _, err := c.client.PullRequests.Edit(ctx, owner, name, number, update)
+	if err != nil {
+		return "", err
+	}
+	return pr.GetHTMLURL(), nil
+}
+
+// PullRequestCommits is a pull request with its commits, to find what it
+// changed on its base branch
+type PullRequestCommits struct {
+	Number int
+	Title  string
+	URL    string
+	Merged bool
+	Base   string
+	// MergeCommit is the merge commit, the squashed commit or the last
+	// rebased commit, depending on how the pull request was merged
+	MergeCommit string
+	// Commits are the commits of the pull request, oldest first
+	Commits []string
+}
+
+// PullRequestCommits returns a pull request and its commits
+func (c *Client) PullRequestCommits(ctx context.Context, owner, name string, number int) (*PullRequestCommits, error) {
+	pr, _, err := c.client.PullRequests.Get(ctx, owner, name, number)
+	if err != nil {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == 401 {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	result := &PullRequestCommits{
+		Number:      pr.GetNumber(),
+		Title:       pr.GetTitle(),
+		URL:         pr.GetHTMLURL(),
+		Merged:      pr.GetMerged(),
+		Base:        pr.GetBase().GetRef(),
+		MergeCommit: pr.GetMergeCommitSHA(),
+	}
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		commits, resp, err := c.client.PullRequests.ListCommits(ctx, owner, name, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			result.Commits = append(result.Commits, commit.GetSHA())
+		}
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
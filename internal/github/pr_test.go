@@ -0,0 +1,114 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRunState(t *testing.T) {
+	assert.Equal(t, CheckPending, checkRunState("queued", ""))
+	assert.Equal(t, CheckPending, checkRunState("in_progress", ""))
+	assert.Equal(t, CheckSuccess, checkRunState("completed", "success"))
+	assert.Equal(t, CheckSkipped, checkRunState("completed", "skipped"))
+	assert.Equal(t, CheckFailure, checkRunState("completed", "timed_out"))
+
+	assert.Equal(t, CheckFailure, commitStatusState("error"))
+	assert.Equal(t, CheckPending, commitStatusState("pending"))
+}
+
+func TestCheckCounts(t *testing.T) {
+	status := &PullRequestStatus{Checks: []Check{
+		{Name: "build", State: CheckSuccess},
+		{Name: "lint", State: CheckSkipped},
+		{Name: "test", State: CheckPending},
+		{Name: "deploy", State: CheckFailure},
+	}}
+	passed, pending, failed := status.CheckCounts()
+	assert.Equal(t, 2, passed)
+	assert.Equal(t, 1, pending)
+	assert.Equal(t, 1, failed)
+}
+
+func TestOpenPullRequestsBy(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/octo/app/pulls", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "open", r.URL.Query().Get("state"))
+		w.Write([]byte(`[
+			{"number": 3, "title": "Add cache", "draft": true, "user": {"login": "Alice"}, "head": {"ref": "cache"}},
+			{"number": 2, "title": "Fix typo", "user": {"login": "bob"}, "head": {"ref": "typo"}}
+		]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := NewHostClient(Host{Name: "github.example.com", APIURL: server.URL + "/api/v3/", UploadURL: server.URL + "/api/uploads/", Token: "token"})
+	require.NoError(t, err)
+
+	prs, err := client.OpenPullRequestsBy(context.Background(), "octo", "app", "alice")
+	require.NoError(t, err)
+	require.Len(t, prs, 1)
+	assert.Equal(t, 3, prs[0].Number)
+	assert.Equal(t, "cache", prs[0].Branch)
+	assert.True(t, prs[0].Draft)
+}
+
+func TestCreateAndUpdatePullRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/octo/app/pulls", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "cache", body["head"])
+		assert.Equal(t, "main", body["base"])
+		assert.Equal(t, "Add cache", body["title"])
+		assert.Equal(t, true, body["draft"])
+		w.Write([]byte(`{"number": 7, "html_url": "https://github.example.com/octo/app/pull/7"}`))
+	})
+	mux.HandleFunc("/api/v3/repos/octo/app/pulls/7", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPatch, r.Method)
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "## Summary", body["body"])
+		assert.NotContains(t, body, "title")
+		w.Write([]byte(`{"number": 7, "html_url": "https://github.example.com/octo/app/pull/7"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := NewHostClient(Host{Name: "github.example.com", APIURL: server.URL + "/api/v3/", UploadURL: server.URL + "/api/uploads/", Token: "token"})
+	require.NoError(t, err)
+
+	number, url, err := client.CreatePullRequest(context.Background(), "octo", "app", "cache", "main", "Add cache", "body", true)
+	require.NoError(t, err)
+	assert.Equal(t, 7, number)
+	assert.Equal(t, "https://github.example.com/octo/app/pull/7", url)
+
+	url, err = client.UpdatePullRequest(context.Background(), "octo", "app", 7, "", "## Summary")
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.example.com/octo/app/pull/7", url)
+}
+
+func TestPullRequestCommits(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/octo/app/pulls/5", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"number": 5, "title": "Add cache", "merged": true, "merge_commit_sha": "m1", "base": {"ref": "main"}}`))
+	})
+	mux.HandleFunc("/api/v3/repos/octo/app/pulls/5/commits", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"sha": "c1"}, {"sha": "c2"}]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := NewHostClient(Host{Name: "github.example.com", APIURL: server.URL + "/api/v3/", UploadURL: server.URL + "/api/uploads/", Token: "token"})
+	require.NoError(t, err)
+
+	pr, err := client.PullRequestCommits(context.Background(), "octo", "app", 5)
+	require.NoError(t, err)
+	assert.Equal(t, &PullRequestCommits{
+		Number: 5, Title: "Add cache", Merged: true, Base: "main",
+		MergeCommit: "m1", Commits: []string{"c1", "c2"},
+	}, pr)
+}
@@ -0,0 +1,159 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// ErrReleaseNotFound is returned when a tag has no published release
+var ErrReleaseNotFound = errors.New("release not found")
+
+// Release is a GitHub release with its assets
+type Release struct {
+	ID     int64
+	Tag    string
+	Name   string
+	URL    string
+	Assets []Asset
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	ID   int64
+	Name string
+	Size int64
+	// State is uploaded, or open for an upload that never finished
+	State string
+	URL   string
+}
+
+// Release returns the published release of a tag
+func (c *Client) Release(ctx context.Context, owner, name, tag string) (*Release, error) {
+	release, _, err := c.client.Repositories.GetReleaseByTag(ctx, owner, name, tag)
+	if err != nil {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == 401 {
+			return nil, ErrUnauthorized
+		}
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == 404 {
+			return nil, fmt.Errorf("%w for tag %s", ErrReleaseNotFound, tag)
+		}
+		return nil, err
+	}
+
+	result := &Release{
+		ID:   release.GetID(),
+		Tag:  release.GetTagName(),
+		Name: release.GetName(),
+		URL:  release.GetHTMLURL(),
+	}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		assets, resp, err := c.client.Repositories.ListReleaseAssets(ctx, owner, name, release.GetID(), opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, asset := range assets {
+			result.Assets = append(result.Assets, Asset{
+				ID:    asset.GetID(),
+				Name:  asset.GetName(),
+				Size:  int64(asset.GetSize()),
+				State: asset.GetState(),
+				URL:   asset.GetBrowserDownloadURL(),
+			})
+		}
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// LatestRelease returns the newest release of a repository that is neither
+// a draft nor a prerelease
+func (c *Client) LatestRelease(ctx context.Context, owner, name string) (*Release, error) {
+	release, _, err := c.client.Repositories.GetLatestRelease(ctx, owner, name)
+	if err != nil {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == 401 {
+			return nil, ErrUnauthorized
+		}
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == 404 {
+			return nil, fmt.Errorf("%w for %s/%s", ErrReleaseNotFound, owner, name)
+		}
+		return nil, err
+	}
+	return &Release{
+		ID:   release.GetID(),
+		Tag:  release.GetTagName(),
+		Name: release.GetName(),
+		URL:  release.GetHTMLURL(),
+	}, nil
+}
+
+// UploadAsset attaches a file to a release under the given name
+func (c *Client) UploadAsset(ctx context.Context, owner, name string, releaseID int64, assetName string, file *os.File) (*Asset, error) {
+	asset, _, err := c.client.Repositories.UploadReleaseAsset(ctx, owner, name, releaseID, &github.UploadOptions{Name: assetName}, file)
+	if err != nil {
+		return nil, err
+	}
+	return &Asset{
+		ID:    asset.GetID(),
+		Name:  asset.GetName(),
+		Size:  int64(asset.GetSize()),
+		State: asset.GetState(),
+		URL:   asset.GetBrowserDownloadURL(),
+	}, nil
+}
+
+// DeleteAsset removes a file from a release
+func (c *Client) DeleteAsset(ctx context.Context, owner, name string, id int64) error {
+	_, err := c.client.Repositories.DeleteReleaseAsset(ctx, owner, name, id)
+	return err
+}
+
+// DownloadAsset opens the content of an asset, starting at offset bytes to
+// resume an interrupted download. It returns the offset the content starts
+// at: 0 when the server doesn't support resuming. The caller closes the
+// content.
+func (c *Client) DownloadAsset(ctx context.Context, owner, name string, id int64, offset int64) (io.ReadCloser, int64, error) {
+	content, location, err := c.client.Repositories.DownloadReleaseAsset(ctx, owner, name, id, nil)
+	if err != nil {
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response.StatusCode == 401 {
+			return nil, 0, ErrUnauthorized
+		}
+		return nil, 0, err
+	}
+	if location == "" {
+		return content, 0, nil
+	}
+
+	// The API redirects to the storage the asset is served from, which
+	// rejects the API token but accepts ranges
+	req, err := http.NewRequestWithContext(ctx, "GET", location, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, 0, nil
+	case http.StatusPartialContent:
+		return resp.Body, offset, nil
+	}
+	resp.Body.Close()
+	return nil, 0, fmt.Errorf("failed to download asset %d: %s", id, resp.Status)
+}
@@ -0,0 +1,120 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// releaseServer serves a v1.0.0 release with one asset, redirecting its
+// download to a storage server that supports ranges
+func releaseServer(t *testing.T, uploads *map[string]string) *Client {
+	content := "0123456789"
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"))
+		http.ServeContent(w, r, "app.tar.gz", time.Time{}, strings.NewReader(content))
+	}))
+	t.Cleanup(storage.Close)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/octo/app/releases/tags/v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 5, "tag_name": "v1.0.0"}`))
+	})
+	mux.HandleFunc("/api/v3/repos/octo/app/releases/5/assets", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id": 9, "name": "app.tar.gz", "size": 10, "state": "uploaded"}]`))
+	})
+	mux.HandleFunc("/api/v3/repos/octo/app/releases/assets/9", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/octet-stream", r.Header.Get("Accept"))
+		http.Redirect(w, r, storage.URL+"/app.tar.gz", http.StatusFound)
+	})
+	mux.HandleFunc("/api/uploads/repos/octo/app/releases/5/assets", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		(*uploads)[r.URL.Query().Get("name")] = string(body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 10, "name": "` + r.URL.Query().Get("name") + `", "size": 5, "state": "uploaded"}`))
+	})
+	mux.HandleFunc("/api/v3/repos/octo/app/releases/tags/v2.0.0", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewHostClient(Host{Name: "github.example.com", APIURL: server.URL + "/api/v3/", UploadURL: server.URL + "/api/uploads/", Token: "token"})
+	require.NoError(t, err)
+	return client
+}
+
+func TestRelease(t *testing.T) {
+	client := releaseServer(t, nil)
+
+	release, err := client.Release(context.Background(), "octo", "app", "v1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), release.ID)
+	assert.Equal(t, []Asset{{ID: 9, Name: "app.tar.gz", Size: 10, State: "uploaded"}}, release.Assets)
+
+	_, err = client.Release(context.Background(), "octo", "app", "v2.0.0")
+	assert.ErrorIs(t, err, ErrReleaseNotFound)
+}
+
+func TestDownloadAssetResumes(t *testing.T) {
+	client := releaseServer(t, nil)
+
+	for offset, want := range map[int64]string{0: "0123456789", 4: "456789"} {
+		content, start, err := client.DownloadAsset(context.Background(), "octo", "app", 9, offset)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		_, err = io.Copy(&buf, content)
+		content.Close()
+		require.NoError(t, err)
+		assert.Equal(t, offset, start)
+		assert.Equal(t, want, buf.String())
+	}
+}
+
+func TestUploadAsset(t *testing.T) {
+	uploads := map[string]string{}
+	client := releaseServer(t, &uploads)
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0644))
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	asset, err := client.UploadAsset(context.Background(), "octo", "app", 5, "notes.txt", file)
+	require.NoError(t, err)
+	assert.Equal(t, "notes.txt", asset.Name)
+	assert.Equal(t, map[string]string{"notes.txt": "hello"}, uploads)
+}
+
+func TestLatestReleaseWithoutToken(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/octo/app/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		// Releases of public repositories are read anonymously
+		assert.Empty(t, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id": 7, "tag_name": "v1.4.0", "html_url": "https://github.example.com/octo/app/releases/tag/v1.4.0"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewHostClient(Host{Name: "github.example.com", APIURL: server.URL + "/api/v3/", UploadURL: server.URL + "/api/uploads/"})
+	require.NoError(t, err)
+	release, err := client.LatestRelease(context.Background(), "octo", "app")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.4.0", release.Tag)
+	assert.Equal(t, "https://github.example.com/octo/app/releases/tag/v1.4.0", release.URL)
+
+	_, err = client.LatestRelease(context.Background(), "octo", "other")
+	assert.ErrorIs(t, err, ErrReleaseNotFound)
+}
@@ -32,6 +32,7 @@ func NewClient(token string) *Client {
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(context.Background(), ts)
+	tc.Transport = newRetryTransport(tc.Transport)
 	return &Client{
 		client: github.NewClient(tc),
 	}
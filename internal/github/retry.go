@@ -0,0 +1,185 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Debug, when set, receives a line per API request with the rate limit
+// quota left
+var Debug io.Writer
+
+// maxRetries is how many times a request is retried after a rate limit or a
+// server error
+const maxRetries = 4
+
+// maxRetryWait is the longest githelper waits before a retry. Longer waits,
+// such as for the hourly quota to reset, fail instead.
+const maxRetryWait = 2 * time.Minute
+
+// retryBackoff is the wait before the first retry after a server error; it
+// doubles with each retry
+var retryBackoff = time.Second
+
+// retryTransport retries the requests GitHub turns down for a while: secondary
+// rate limits and abuse detection, and server errors for the requests that
+// can safely be repeated
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func newRetryTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{base: base}
+}
+
+// RoundTrip sends req, and a copy of it for each retry: a RoundTripper must
+// not change the request it is given
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	sent := req
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(sent)
+		if err != nil {
+			return nil, err
+		}
+		logRateLimit(req, resp)
+
+		wait, retry := retryDelay(req, resp, attempt)
+		if !retry || attempt >= maxRetries {
+			return resp, nil
+		}
+		next := retryRequest(req)
+		if next == nil {
+			return resp, nil
+		}
+		// The body is read so the connection can be reused
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if Debug != nil {
+			fmt.Fprintf(Debug, "GitHub API: %s %s returned %d, retrying in %s\n", req.Method, req.URL.Path, resp.StatusCode, wait)
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		sent = next
+	}
+}
+
+// retryDelay tells whether a response is worth retrying, and after how long
+func retryDelay(req *http.Request, resp *http.Response, attempt int) (time.Duration, bool) {
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		// Secondary rate limits and abuse detection say when to come back
+		if after := resp.Header.Get("Retry-After"); after != "" {
+			seconds, err := strconv.Atoi(after)
+			if err != nil || time.Duration(seconds)*time.Second > maxRetryWait {
+				return 0, false
+			}
+			return time.Duration(seconds) * time.Second, true
+		}
+		// The primary quota is used up until its reset time
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+			if err != nil {
+				return 0, false
+			}
+			wait := time.Until(time.Unix(reset, 0)) + time.Second
+			if wait > maxRetryWait {
+				return 0, false
+			}
+			return max(wait, 0), true
+		}
+		// GitHub asks to wait at least a minute when it doesn't say how long
+		if isSecondaryRateLimit(resp) {
+			return min(backoff(attempt)*60, maxRetryWait), true
+		}
+		return 0, false
+	case resp.StatusCode >= 500:
+		// A request that failed on the server may have done something, only
+		// the ones that do the same thing twice are repeated
+		if !isIdempotent(req.Method) || resp.StatusCode == http.StatusNotImplemented {
+			return 0, false
+		}
+		return backoff(attempt), true
+	}
+	return 0, false
+}
+
+// isSecondaryRateLimit reports whether a 403 is a secondary rate limit that
+// came without Retry-After, peeking at its body
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	message := strings.ToLower(string(body))
+	return strings.Contains(message, "secondary rate limit") || strings.Contains(message, "abuse detection")
+}
+
+func backoff(attempt int) time.Duration {
+	return retryBackoff << attempt
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryRequest copies a request to send it again, with a fresh body from
+// GetBody. It returns nil when the body can't be read again.
+func retryRequest(req *http.Request) *http.Request {
+	retry := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return retry
+	}
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil
+	}
+	retry.Body = body
+	return retry
+}
+
+// logRateLimit prints the quota left after a request in debug mode
+func logRateLimit(req *http.Request, resp *http.Response) {
+	if Debug == nil {
+		return
+	}
+	remaining, limit := resp.Header.Get("X-RateLimit-Remaining"), resp.Header.Get("X-RateLimit-Limit")
+	if remaining == "" {
+		fmt.Fprintf(Debug, "GitHub API: %s %s %d\n", req.Method, req.URL.Path, resp.StatusCode)
+		return
+	}
+	reset := ""
+	if seconds, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		reset = ", resets at " + time.Unix(seconds, 0).Format("15:04:05")
+	}
+	fmt.Fprintf(Debug, "GitHub API: %s %s %d (%s/%s requests left%s)\n", req.Method, req.URL.Path, resp.StatusCode, remaining, limit, reset)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
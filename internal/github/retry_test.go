@@ -0,0 +1,156 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func retryTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	backoff := retryBackoff
+	retryBackoff = time.Millisecond
+	t.Cleanup(func() { retryBackoff = backoff })
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client, err := NewHostClient(Host{Name: "github.example.com", APIURL: server.URL + "/api/v3/", UploadURL: server.URL + "/api/uploads/", Token: "token"})
+	require.NoError(t, err)
+	return client
+}
+
+func TestRetryServerErrors(t *testing.T) {
+	calls := 0
+	client := retryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		w.Write([]byte(`{"full_name": "team/app"}`))
+	})
+
+	var debug bytes.Buffer
+	Debug = &debug
+	defer func() { Debug = nil }()
+
+	repo, _, err := client.client.Repositories.Get(context.Background(), "team", "app")
+	require.NoError(t, err)
+	assert.Equal(t, "team/app", repo.GetFullName())
+	assert.Equal(t, 3, calls)
+	assert.Contains(t, debug.String(), "returned 503, retrying")
+	assert.Contains(t, debug.String(), "4999/5000 requests left")
+}
+
+func TestRetrySecondaryRateLimit(t *testing.T) {
+	var bodies []string
+	client := retryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "You have exceeded a secondary rate limit"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number": 1}`))
+	})
+
+	// Rate limits are retried even for requests that create something, with
+	// the same body
+	issue, _, err := client.client.Issues.Create(context.Background(), "team", "app", &github.IssueRequest{Title: github.String("Bug")})
+	require.NoError(t, err)
+	assert.Equal(t, 1, issue.GetNumber())
+	require.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.Contains(t, bodies[1], `"title":"Bug"`)
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	calls := 0
+	client := retryTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method == http.MethodGet {
+			// The hourly quota resets too far away to wait
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "9999999999")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	// A POST that failed on the server may have done its work already
+	_, _, err := client.client.Issues.Create(context.Background(), "team", "app", &github.IssueRequest{Title: github.String("Bug")})
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	_, _, err = client.client.Repositories.Get(context.Background(), "team", "app")
+	require.Error(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+// recordingTransport answers every request with a 503 and keeps the requests
+// it was given
+type recordingTransport struct {
+	requests []*http.Request
+	bodies   []string
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		data, _ := io.ReadAll(req.Body)
+		body = string(data)
+	}
+	t.requests = append(t.requests, req)
+	t.bodies = append(t.bodies, body)
+	return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+func TestRetryLeavesTheRequestAlone(t *testing.T) {
+	backoff := retryBackoff
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = backoff }()
+
+	base := &recordingTransport{}
+	req, err := http.NewRequest(http.MethodPut, "https://api.github.com/repos/team/app/topics", strings.NewReader(`{"names":["go"]}`))
+	require.NoError(t, err)
+	body := req.Body
+
+	resp, err := newRetryTransport(base).RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	require.Len(t, base.requests, maxRetries+1)
+	assert.Same(t, req, base.requests[0])
+	for i, sent := range base.requests[1:] {
+		assert.NotSame(t, req, sent, "retry %d sends a copy", i+1)
+		assert.Equal(t, `{"names":["go"]}`, base.bodies[i+1])
+	}
+	assert.True(t, body == req.Body, "the request's body is not replaced")
+}
+
+func TestRetryNeedsGetBody(t *testing.T) {
+	base := &recordingTransport{}
+	req, err := http.NewRequest(http.MethodPut, "https://api.github.com/repos/team/app/topics", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	req.GetBody = nil
+
+	resp, err := newRetryTransport(base).RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Len(t, base.requests, 1, "a body that can't be read again isn't sent again")
+}
@@ -0,0 +1,425 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v53/github"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrRepoNotFound is returned when a repository doesn't exist or the token
+// can't see it
+var ErrRepoNotFound = errors.New("repository not found")
+
+// RepoSpec declares the settings of one or more repositories. Settings that
+// aren't set are left unchanged.
+type RepoSpec struct {
+	Repositories []string     `yaml:"repositories"`
+	Settings     RepoSettings `yaml:"settings"`
+	// BranchProtection maps branches to their rules; a null entry removes
+	// the protection of the branch
+	BranchProtection map[string]*BranchProtectionSettings `yaml:"branch_protection"`
+	Labels           []Label                              `yaml:"labels"`
+	// PruneLabels deletes the labels that aren't listed
+	PruneLabels bool `yaml:"prune_labels"`
+}
+
+// RepoSettings are the general settings of a repository
+type RepoSettings struct {
+	Description         *string  `yaml:"description"`
+	Homepage            *string  `yaml:"homepage"`
+	Private             *bool    `yaml:"private"`
+	Topics              []string `yaml:"topics"`
+	HasIssues           *bool    `yaml:"has_issues"`
+	HasWiki             *bool    `yaml:"has_wiki"`
+	HasProjects         *bool    `yaml:"has_projects"`
+	AllowMergeCommit    *bool    `yaml:"allow_merge_commit"`
+	AllowSquashMerge    *bool    `yaml:"allow_squash_merge"`
+	AllowRebaseMerge    *bool    `yaml:"allow_rebase_merge"`
+	AllowAutoMerge      *bool    `yaml:"allow_auto_merge"`
+	DeleteBranchOnMerge *bool    `yaml:"delete_branch_on_merge"`
+	DefaultBranch       *string  `yaml:"default_branch"`
+}
+
+// BranchProtectionSettings are the protection rules of a branch. Unlike
+// repository settings they are declared as a whole: rules that aren't set
+// are turned off. Push and dismissal restrictions are kept as they are.
+type BranchProtectionSettings struct {
+	RequiredStatusChecks          []string `yaml:"required_status_checks"`
+	StrictStatusChecks            bool     `yaml:"strict"`
+	RequirePullRequest            bool     `yaml:"require_pull_request"`
+	RequiredApprovingReviews      int      `yaml:"required_approving_reviews"`
+	DismissStaleReviews           bool     `yaml:"dismiss_stale_reviews"`
+	RequireCodeOwnerReviews       bool     `yaml:"require_code_owner_reviews"`
+	EnforceAdmins                 bool     `yaml:"enforce_admins"`
+	RequireLinearHistory          bool     `yaml:"require_linear_history"`
+	RequireConversationResolution bool     `yaml:"require_conversation_resolution"`
+	AllowForcePushes              bool     `yaml:"allow_force_pushes"`
+	AllowDeletions                bool     `yaml:"allow_deletions"`
+}
+
+// LoadRepoSpec reads a RepoSpec from a YAML file, rejecting unknown keys
+func LoadRepoSpec(path string) (*RepoSpec, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var spec RepoSpec
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("invalid settings file %s: %w", path, err)
+	}
+	for _, repo := range spec.Repositories {
+		if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRepoName, repo)
+		}
+	}
+	for _, label := range spec.Labels {
+		if label.Name == "" {
+			return nil, fmt.Errorf("invalid settings file %s: every label needs a name", path)
+		}
+	}
+	return &spec, nil
+}
+
+// RepoConfig returns the settings used to create a missing repository.
+// Repositories are private with issues and wiki unless the settings say
+// otherwise.
+func (s RepoSettings) RepoConfig() RepoConfig {
+	config := RepoConfig{Private: true, HasIssues: true, HasWiki: true, Topics: s.Topics}
+	if s.Private != nil {
+		config.Private = *s.Private
+	}
+	if s.Description != nil {
+		config.Description = *s.Description
+	}
+	if s.HasIssues != nil {
+		config.HasIssues = *s.HasIssues
+	}
+	if s.HasWiki != nil {
+		config.HasWiki = *s.HasWiki
+	}
+	return config
+}
+
+// RepoChange is a change needed to make a repository match a RepoSpec
+type RepoChange struct {
+	Summary string
+	Details []string
+	apply   func(ctx context.Context) error
+}
+
+// Apply makes the change
+func (ch RepoChange) Apply(ctx context.Context) error {
+	return ch.apply(ctx)
+}
+
+// PlanRepo compares owner/name with spec and returns the changes that make
+// it match, without changing anything
+func (c *Client) PlanRepo(ctx context.Context, owner, name string, spec RepoSpec) ([]RepoChange, error) {
+	repo, resp, err := c.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, ErrRepoNotFound
+		}
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	var changes []RepoChange
+	if change := c.planSettings(owner, name, repo, spec.Settings); change != nil {
+		changes = append(changes, *change)
+	}
+	if spec.Settings.Topics != nil {
+		current, desired := sortedCopy(repo.Topics), sortedCopy(spec.Settings.Topics)
+		if strings.Join(current, ",") != strings.Join(desired, ",") {
+			changes = append(changes, RepoChange{
+				Summary: "replace topics",
+				Details: []string{fmt.Sprintf("topics: [%s] → [%s]", strings.Join(current, ", "), strings.Join(desired, ", "))},
+				apply: func(ctx context.Context) error {
+					_, _, err := c.client.Repositories.ReplaceAllTopics(ctx, owner, name, desired)
+					return err
+				},
+			})
+		}
+	}
+
+	branches := make([]string, 0, len(spec.BranchProtection))
+	for branch := range spec.BranchProtection {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+	for _, branch := range branches {
+		change, err := c.planProtection(ctx, owner, name, branch, spec.BranchProtection[branch])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read protection of %s: %w", branch, err)
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	}
+
+	if len(spec.Labels) > 0 || spec.PruneLabels {
+		current, err := c.ListLabels(ctx, owner, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels: %w", err)
+		}
+		for _, labelChange := range PlanLabels(current, spec.Labels, spec.PruneLabels) {
+			labelChange := labelChange
+			changes = append(changes, RepoChange{
+				Summary: DescribeLabelChange(labelChange),
+				apply: func(ctx context.Context) error {
+					return c.ApplyLabelChange(ctx, owner, name, labelChange)
+				},
+			})
+		}
+	}
+	return changes, nil
+}
+
+// DescribeLabelChange formats a label change, e.g. "update label bug (color d73a4a → ff0000)"
+func DescribeLabelChange(change LabelChange) string {
+	switch change.Action {
+	case LabelCreate:
+		if change.Label.Color == "" {
+			return "create label " + change.Label.Name
+		}
+		return fmt.Sprintf("create label %s (#%s)", change.Label.Name, change.Label.Color)
+	case LabelDelete:
+		return fmt.Sprintf("delete label %s", change.Current.Name)
+	}
+	var diffs []string
+	if change.Current.Name != change.Label.Name {
+		diffs = append(diffs, "rename to "+change.Label.Name)
+	}
+	if !strings.EqualFold(change.Current.Color, change.Label.Color) {
+		diffs = append(diffs, fmt.Sprintf("color %s → %s", change.Current.Color, change.Label.Color))
+	}
+	if change.Current.Description != change.Label.Description {
+		diffs = append(diffs, "new description")
+	}
+	return fmt.Sprintf("update label %s (%s)", change.Current.Name, strings.Join(diffs, ", "))
+}
+
+// planSettings returns a single edit for every general setting that differs
+func (c *Client) planSettings(owner, name string, repo *github.Repository, s RepoSettings) *RepoChange {
+	edit := &github.Repository{}
+	var details []string
+	if diffSetting(&details, "description", s.Description, repo.GetDescription()) {
+		edit.Description = s.Description
+	}
+	if diffSetting(&details, "homepage", s.Homepage, repo.GetHomepage()) {
+		edit.Homepage = s.Homepage
+	}
+	if diffSetting(&details, "private", s.Private, repo.GetPrivate()) {
+		edit.Private = s.Private
+	}
+	if diffSetting(&details, "has_issues", s.HasIssues, repo.GetHasIssues()) {
+		edit.HasIssues = s.HasIssues
+	}
+	if diffSetting(&details, "has_wiki", s.HasWiki, repo.GetHasWiki()) {
+		edit.HasWiki = s.HasWiki
+	}
+	if diffSetting(&details, "has_projects", s.HasProjects, repo.GetHasProjects()) {
+		edit.HasProjects = s.HasProjects
+	}
+	if diffSetting(&details, "allow_merge_commit", s.AllowMergeCommit, repo.GetAllowMergeCommit()) {
+		edit.AllowMergeCommit = s.AllowMergeCommit
+	}
+	if diffSetting(&details, "allow_squash_merge", s.AllowSquashMerge, repo.GetAllowSquashMerge()) {
+		edit.AllowSquashMerge = s.AllowSquashMerge
+	}
+	if diffSetting(&details, "allow_rebase_merge", s.AllowRebaseMerge, repo.GetAllowRebaseMerge()) {
+		edit.AllowRebaseMerge = s.AllowRebaseMerge
+	}
+	if diffSetting(&details, "allow_auto_merge", s.AllowAutoMerge, repo.GetAllowAutoMerge()) {
+		edit.AllowAutoMerge = s.AllowAutoMerge
+	}
+	if diffSetting(&details, "delete_branch_on_merge", s.DeleteBranchOnMerge, repo.GetDeleteBranchOnMerge()) {
+		edit.DeleteBranchOnMerge = s.DeleteBranchOnMerge
+	}
+	if diffSetting(&details, "default_branch", s.DefaultBranch, repo.GetDefaultBranch()) {
+		edit.DefaultBranch = s.DefaultBranch
+	}
+	if len(details) == 0 {
+		return nil
+	}
+
+	return &RepoChange{
+		Summary: "update settings",
+		Details: details,
+		apply: func(ctx context.Context) error {
+			_, _, err := c.client.Repositories.Edit(ctx, owner, name, edit)
+			return err
+		},
+	}
+}
+
+// diffSetting records name when a desired value is set and differs from
+// the current one
+func diffSetting[T comparable](details *[]string, name string, desired *T, current T) bool {
+	if desired == nil || *desired == current {
+		return false
+	}
+	*details = append(*details, fmt.Sprintf("%s: %#v → %#v", name, current, *desired))
+	return true
+}
+
+func (c *Client) planProtection(ctx context.Context, owner, name, branch string, desired *BranchProtectionSettings) (*RepoChange, error) {
+	protection, resp, err := c.client.Repositories.GetBranchProtection(ctx, owner, name, branch)
+	if err != nil {
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return nil, err
+		}
+		protection = nil
+	}
+
+	if desired == nil {
+		if protection == nil {
+			return nil, nil
+		}
+		return &RepoChange{
+			Summary: "remove protection of " + branch,
+			apply: func(ctx context.Context) error {
+				_, err := c.client.Repositories.RemoveBranchProtection(ctx, owner, name, branch)
+				return err
+			},
+		}, nil
+	}
+
+	want := normalizeProtection(*desired)
+	summary := "protect " + branch
+	var have BranchProtectionSettings
+	if protection != nil {
+		have = protectionSettings(protection)
+		summary = "update protection of " + branch
+	}
+
+	var details []string
+	diffSetting(&details, "required_status_checks", ptr(strings.Join(want.RequiredStatusChecks, ", ")), strings.Join(have.RequiredStatusChecks, ", "))
+	diffSetting(&details, "strict", &want.StrictStatusChecks, have.StrictStatusChecks)
+	diffSetting(&details, "require_pull_request", &want.RequirePullRequest, have.RequirePullRequest)
+	diffSetting(&details, "required_approving_reviews", &want.RequiredApprovingReviews, have.RequiredApprovingReviews)
+	diffSetting(&details, "dismiss_stale_reviews", &want.DismissStaleReviews, have.DismissStaleReviews)
+	diffSetting(&details, "require_code_owner_reviews", &want.RequireCodeOwnerReviews, have.RequireCodeOwnerReviews)
+	diffSetting(&details, "enforce_admins", &want.EnforceAdmins, have.EnforceAdmins)
+	diffSetting(&details, "require_linear_history", &want.RequireLinearHistory, have.RequireLinearHistory)
+	diffSetting(&details, "require_conversation_resolution", &want.RequireConversationResolution, have.RequireConversationResolution)
+	diffSetting(&details, "allow_force_pushes", &want.AllowForcePushes, have.AllowForcePushes)
+	diffSetting(&details, "allow_deletions", &want.AllowDeletions, have.AllowDeletions)
+	if protection != nil && len(details) == 0 {
+		return nil, nil
+	}
+
+	req := &github.ProtectionRequest{}
+	if protection != nil {
+		req = protectionRequest(protection)
+	}
+	applyProtectionSettings(req, want)
+	return &RepoChange{
+		Summary: summary,
+		Details: details,
+		apply: func(ctx context.Context) error {
+			_, _, err := c.client.Repositories.UpdateBranchProtection(ctx, owner, name, branch, req)
+			return err
+		},
+	}, nil
+}
+
+// normalizeProtection sorts the checks and turns on require_pull_request
+// when review rules need it
+func normalizeProtection(s BranchProtectionSettings) BranchProtectionSettings {
+	s.RequiredStatusChecks = sortedCopy(s.RequiredStatusChecks)
+	if s.RequiredApprovingReviews > 0 || s.DismissStaleReviews || s.RequireCodeOwnerReviews {
+		s.RequirePullRequest = true
+	}
+	return s
+}
+
+// protectionSettings reads the rules of a protected branch
+func protectionSettings(p *github.Protection) BranchProtectionSettings {
+	var s BranchProtectionSettings
+	if checks := p.GetRequiredStatusChecks(); checks != nil {
+		s.StrictStatusChecks = checks.Strict
+		for _, check := range checks.Checks {
+			s.RequiredStatusChecks = append(s.RequiredStatusChecks, check.Context)
+		}
+		if len(checks.Checks) == 0 {
+			s.RequiredStatusChecks = append(s.RequiredStatusChecks, checks.Contexts...)
+		}
+	}
+	if reviews := p.GetRequiredPullRequestReviews(); reviews != nil {
+		s.RequirePullRequest = true
+		s.RequiredApprovingReviews = reviews.RequiredApprovingReviewCount
+		s.DismissStaleReviews = reviews.DismissStaleReviews
+		s.RequireCodeOwnerReviews = reviews.RequireCodeOwnerReviews
+	}
+	// Rules that are off may be missing from the response
+	if p.EnforceAdmins != nil {
+		s.EnforceAdmins = p.EnforceAdmins.Enabled
+	}
+	if p.RequireLinearHistory != nil {
+		s.RequireLinearHistory = p.RequireLinearHistory.Enabled
+	}
+	if p.RequiredConversationResolution != nil {
+		s.RequireConversationResolution = p.RequiredConversationResolution.Enabled
+	}
+	if p.AllowForcePushes != nil {
+		s.AllowForcePushes = p.AllowForcePushes.Enabled
+	}
+	if p.AllowDeletions != nil {
+		s.AllowDeletions = p.AllowDeletions.Enabled
+	}
+	return normalizeProtection(s)
+}
+
+// applyProtectionSettings sets the rules of s on req, keeping the
+// restrictions and review allowances already there
+func applyProtectionSettings(req *github.ProtectionRequest, s BranchProtectionSettings) {
+	req.RequiredStatusChecks = nil
+	if len(s.RequiredStatusChecks) > 0 || s.StrictStatusChecks {
+		checks := make([]*github.RequiredStatusCheck, 0, len(s.RequiredStatusChecks))
+		for _, check := range s.RequiredStatusChecks {
+			checks = append(checks, &github.RequiredStatusCheck{Context: check})
+		}
+		req.RequiredStatusChecks = &github.RequiredStatusChecks{Strict: s.StrictStatusChecks, Checks: checks}
+	}
+
+	if !s.RequirePullRequest {
+		req.RequiredPullRequestReviews = nil
+	} else {
+		if req.RequiredPullRequestReviews == nil {
+			req.RequiredPullRequestReviews = &github.PullRequestReviewsEnforcementRequest{}
+		}
+		req.RequiredPullRequestReviews.RequiredApprovingReviewCount = s.RequiredApprovingReviews
+		req.RequiredPullRequestReviews.DismissStaleReviews = s.DismissStaleReviews
+		req.RequiredPullRequestReviews.RequireCodeOwnerReviews = s.RequireCodeOwnerReviews
+	}
+
+	req.EnforceAdmins = s.EnforceAdmins
+	req.RequireLinearHistory = github.Bool(s.RequireLinearHistory)
+	req.RequiredConversationResolution = github.Bool(s.RequireConversationResolution)
+	req.AllowForcePushes = github.Bool(s.AllowForcePushes)
+	req.AllowDeletions = github.Bool(s.AllowDeletions)
+}
+
+func sortedCopy(values []string) []string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
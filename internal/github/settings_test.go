@@ -0,0 +1,98 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v53/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRepoSpec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`repositories: [octo/app]
+settings:
+  description: App
+  allow_merge_commit: false
+branch_protection:
+  main:
+    required_approving_reviews: 1
+  old: null
+labels:
+  - {name: bug, color: "#D73A4A"}
+`), 0644))
+
+	spec, err := LoadRepoSpec(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"octo/app"}, spec.Repositories)
+	assert.Equal(t, "App", *spec.Settings.Description)
+	assert.False(t, *spec.Settings.AllowMergeCommit)
+	assert.Nil(t, spec.Settings.Private)
+	assert.Equal(t, 1, spec.BranchProtection["main"].RequiredApprovingReviews)
+	assert.Contains(t, spec.BranchProtection, "old")
+	assert.Nil(t, spec.BranchProtection["old"])
+
+	require.NoError(t, os.WriteFile(path, []byte("settings:\n  descripton: typo\n"), 0644))
+	_, err = LoadRepoSpec(path)
+	assert.Error(t, err)
+}
+
+func TestPlanLabels(t *testing.T) {
+	current := []Label{
+		{Name: "Bug", Color: "d73a4a", Description: "Broken"},
+		{Name: "docs", Color: "0075ca"},
+		{Name: "wontfix", Color: "ffffff"},
+	}
+	desired := []Label{
+		{Name: "bug", Color: "#D73A4A"},
+		{Name: "docs", Color: "0075ca"},
+		{Name: "feature", Color: "a2eeef"},
+	}
+
+	changes := PlanLabels(current, desired, false)
+	require.Len(t, changes, 2)
+	assert.Equal(t, LabelUpdate, changes[0].Action)
+	assert.Equal(t, "Broken", changes[0].Label.Description)
+	assert.Equal(t, "update label Bug (rename to bug)", DescribeLabelChange(changes[0]))
+	assert.Equal(t, LabelCreate, changes[1].Action)
+
+	changes = PlanLabels(current, desired, true)
+	require.Len(t, changes, 3)
+	assert.Equal(t, LabelDelete, changes[2].Action)
+	assert.Equal(t, "wontfix", changes[2].Current.Name)
+}
+
+func TestProtectionSettingsRoundTrip(t *testing.T) {
+	want := normalizeProtection(BranchProtectionSettings{
+		RequiredStatusChecks:     []string{"test", "build"},
+		StrictStatusChecks:       true,
+		RequiredApprovingReviews: 2,
+		EnforceAdmins:            true,
+	})
+	assert.True(t, want.RequirePullRequest)
+
+	req := &github.ProtectionRequest{}
+	applyProtectionSettings(req, want)
+	assert.Equal(t, 2, req.RequiredPullRequestReviews.RequiredApprovingReviewCount)
+	require.Len(t, req.RequiredStatusChecks.Checks, 2)
+
+	// Read back what GitHub would return for that request
+	protection := &github.Protection{
+		RequiredStatusChecks: &github.RequiredStatusChecks{Strict: true, Contexts: []string{"build", "test"}},
+		RequiredPullRequestReviews: &github.PullRequestReviewsEnforcement{
+			RequiredApprovingReviewCount: 2,
+		},
+		EnforceAdmins: &github.AdminEnforcement{Enabled: true},
+	}
+	assert.Equal(t, want, protectionSettings(protection))
+}
+
+func TestDiffSetting(t *testing.T) {
+	var details []string
+	assert.False(t, diffSetting(&details, "private", nil, true))
+	assert.False(t, diffSetting(&details, "private", ptr(true), true))
+	assert.True(t, diffSetting(&details, "description", ptr("new"), "old"))
+	assert.Equal(t, []string{`description: "old" → "new"`}, details)
+}
@@ -0,0 +1,50 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v53/github"
+)
+
+// CurrentUser returns the login of the authenticated user and the OAuth
+// scopes granted to the token
+func (c *Client) CurrentUser(ctx context.Context) (string, []string, error) {
+	user, resp, err := c.client.Users.Get(ctx, "")
+	if err != nil {
+		if errResp, ok := err.(*github.ErrorResponse); ok && errResp.Response.StatusCode == 401 {
+			return "", nil, ErrUnauthorized
+		}
+		return "", nil, err
+	}
+
+	var scopes []string
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return user.GetLogin(), scopes, nil
+}
+
+// ErrAccountNotFound is returned when no user or organization has a login
+var ErrAccountNotFound = errors.New("no such user or organization")
+
+// AccountType returns whether login is a "User" or an "Organization"
+func (c *Client) AccountType(ctx context.Context, login string) (string, error) {
+	user, _, err := c.client.Users.Get(ctx, login)
+	if err != nil {
+		if errResp, ok := err.(*github.ErrorResponse); ok {
+			switch errResp.Response.StatusCode {
+			case 401:
+				return "", ErrUnauthorized
+			case 404:
+				return "", fmt.Errorf("%w: %s", ErrAccountNotFound, login)
+			}
+		}
+		return "", err
+	}
+	return user.GetType(), nil
+}
@@ -0,0 +1,34 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountType(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/users/acme", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"login": "acme", "type": "Organization"}`))
+	})
+	mux.HandleFunc("/api/v3/users/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewHostClient(Host{Name: "github.example.com", APIURL: server.URL + "/api/v3/", UploadURL: server.URL + "/api/uploads/", Token: "token"})
+	require.NoError(t, err)
+
+	kind, err := client.AccountType(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Equal(t, "Organization", kind)
+
+	_, err = client.AccountType(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+}
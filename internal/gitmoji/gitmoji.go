@@ -0,0 +1,121 @@
+// Package gitmoji maps gitmoji (https://gitmoji.dev), the emoji that start
+// commit messages, to conventional commit types and back.
+package gitmoji
+
+import (
+	"strings"
+)
+
+// Gitmoji is an emoji for a kind of change
+type Gitmoji struct {
+	Emoji       string
+	Code        string
+	Description string
+	// Type is the conventional commit type of the change
+	Type string
+}
+
+// String returns the emoji, or its shortcode when shortcode is set
+func (g Gitmoji) String(shortcode bool) string {
+	if shortcode {
+		return g.Code
+	}
+	return g.Emoji
+}
+
+// All are the gitmoji githelper offers. The first one of each type is the
+// one ForType returns.
+var All = []Gitmoji{
+	{"✨", ":sparkles:", "Introduce new features", "feat"},
+	{"🐛", ":bug:", "Fix a bug", "fix"},
+	{"📝", ":memo:", "Add or update documentation", "docs"},
+	{"🎨", ":art:", "Improve structure or format of the code", "style"},
+	{"♻️", ":recycle:", "Refactor code", "refactor"},
+	{"⚡️", ":zap:", "Improve performance", "perf"},
+	{"✅", ":white_check_mark:", "Add, update or pass tests", "test"},
+	{"📦️", ":package:", "Add or update compiled files or packages", "build"},
+	{"👷", ":construction_worker:", "Add or update the CI build system", "ci"},
+	{"🔧", ":wrench:", "Add or update configuration files", "chore"},
+	{"⏪️", ":rewind:", "Revert changes", "revert"},
+	{"🚑️", ":ambulance:", "Critical hotfix", "fix"},
+	{"🩹", ":adhesive_bandage:", "Simple fix for a non-critical issue", "fix"},
+	{"🔒️", ":lock:", "Fix security or privacy issues", "fix"},
+	{"✏️", ":pencil2:", "Fix typos", "fix"},
+	{"💚", ":green_heart:", "Fix the CI build", "ci"},
+	{"🚨", ":rotating_light:", "Fix compiler or linter warnings", "style"},
+	{"💄", ":lipstick:", "Add or update the UI and style files", "style"},
+	{"💥", ":boom:", "Introduce breaking changes", "feat"},
+	{"🌐", ":globe_with_meridians:", "Internationalization and localization", "feat"},
+	{"🗃️", ":card_file_box:", "Perform database related changes", "feat"},
+	{"🔥", ":fire:", "Remove code or files", "refactor"},
+	{"🚚", ":truck:", "Move or rename resources", "refactor"},
+	{"🏷️", ":label:", "Add or update types", "refactor"},
+	{"🧪", ":test_tube:", "Add a failing test", "test"},
+	{"⬆️", ":arrow_up:", "Upgrade dependencies", "build"},
+	{"⬇️", ":arrow_down:", "Downgrade dependencies", "build"},
+	{"➕", ":heavy_plus_sign:", "Add a dependency", "build"},
+	{"➖", ":heavy_minus_sign:", "Remove a dependency", "build"},
+	{"🔨", ":hammer:", "Add or update development scripts", "chore"},
+	{"🙈", ":see_no_evil:", "Add or update a .gitignore file", "chore"},
+	{"🔖", ":bookmark:", "Release or version tags", "chore"},
+	{"🚀", ":rocket:", "Deploy stuff", "chore"},
+	{"🚧", ":construction:", "Work in progress", "chore"},
+}
+
+// ForType returns the gitmoji of a conventional commit type
+func ForType(kind string) (Gitmoji, bool) {
+	kind = strings.ToLower(kind)
+	for _, g := range All {
+		if g.Type == kind {
+			return g, true
+		}
+	}
+	return Gitmoji{}, false
+}
+
+// Search returns the gitmoji whose shortcode, type or description contain
+// every word of query. An exact shortcode or emoji is the only match.
+func Search(query string) []Gitmoji {
+	query = strings.ToLower(strings.TrimSpace(query))
+	for _, g := range All {
+		if query == strings.Trim(g.Code, ":") || query == g.Code || trimVariation(query) == trimVariation(g.Emoji) {
+			return []Gitmoji{g}
+		}
+	}
+
+	words := strings.Fields(query)
+	var found []Gitmoji
+	for _, g := range All {
+		text := strings.ToLower(g.Code + " " + g.Type + " " + g.Description)
+		matches := len(words) > 0
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			found = append(found, g)
+		}
+	}
+	return found
+}
+
+// Prefix returns the gitmoji a message starts with, as an emoji or a
+// shortcode, and the rest of the message
+func Prefix(message string) (Gitmoji, string, bool) {
+	for _, g := range All {
+		for _, prefix := range []string{g.Code, g.Emoji, trimVariation(g.Emoji)} {
+			if rest, ok := strings.CutPrefix(message, prefix); ok {
+				return g, strings.TrimLeft(strings.TrimPrefix(rest, "\uFE0F"), " "), true
+			}
+		}
+	}
+	return Gitmoji{}, message, false
+}
+
+// trimVariation drops the variation selector that asks for an emoji to be
+// drawn in color, which some tools leave out
+func trimVariation(emoji string) string {
+	return strings.ReplaceAll(emoji, "\uFE0F", "")
+}
@@ -0,0 +1,56 @@
+package gitmoji
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForType(t *testing.T) {
+	for kind, emoji := range map[string]string{"feat": "✨", "fix": "🐛", "docs": "📝", "Refactor": "♻️", "ci": "👷"} {
+		g, ok := ForType(kind)
+		assert.True(t, ok, kind)
+		assert.Equal(t, emoji, g.Emoji, kind)
+	}
+	_, ok := ForType("wip")
+	assert.False(t, ok)
+
+	// Every type has a gitmoji
+	for _, g := range All {
+		primary, _ := ForType(g.Type)
+		assert.Equal(t, g.Type, primary.Type)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	assert.Equal(t, []Gitmoji{All[1]}, Search(":bug:"))
+	assert.Equal(t, []Gitmoji{All[1]}, Search("bug"))
+	assert.Equal(t, "⚡️", Search("⚡")[0].Emoji, "without the variation selector")
+	assert.Equal(t, []string{":arrow_up:", ":arrow_down:"}, codes(Search("grade dependencies")))
+	assert.Empty(t, Search("nothing like it"))
+	assert.Empty(t, Search(" "))
+}
+
+func TestPrefix(t *testing.T) {
+	g, rest, ok := Prefix(":sparkles: feat: add search")
+	assert.True(t, ok)
+	assert.Equal(t, "✨", g.Emoji)
+	assert.Equal(t, "feat: add search", rest)
+
+	g, rest, ok = Prefix("♻ Simplify parser")
+	assert.True(t, ok)
+	assert.Equal(t, ":recycle:", g.Code)
+	assert.Equal(t, "Simplify parser", rest)
+
+	_, rest, ok = Prefix("feat: add search")
+	assert.False(t, ok)
+	assert.Equal(t, "feat: add search", rest)
+}
+
+func codes(list []Gitmoji) []string {
+	var codes []string
+	for _, g := range list {
+		codes = append(codes, g.Code)
+	}
+	return codes
+}
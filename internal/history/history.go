@@ -0,0 +1,196 @@
+// Package history keeps a local log of the githelper commands that ran: in
+// which repository, which refs they changed and how they ended. Nothing in
+// it leaves the machine.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/git"
+)
+
+// maxSize is how large the log grows before its older half is dropped
+var maxSize int64 = 2 << 20
+
+// Entry is a command githelper ran
+type Entry struct {
+	Time time.Time `json:"time"`
+	// Command is the subcommand path, e.g. "branch clean"
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	// Repo is the root of the repository the command ran in, if any
+	Repo     string        `json:"repo,omitempty"`
+	Refs     []RefChange   `json:"refs,omitempty"`
+	Duration time.Duration `json:"duration"`
+	// ExitCode is 0 on success, 1 on error and 130 when interrupted
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+}
+
+// RefChange is a ref a command created, moved or deleted. Old is empty for a
+// created ref and New for a deleted one.
+type RefChange struct {
+	Name string `json:"name"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// DefaultFile returns ~/.githelper/history.jsonl
+func DefaultFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".githelper", "history.jsonl"), nil
+}
+
+// Refs returns the commit of every ref of the repository in the current
+// directory, and what HEAD points to. githelper's own backup refs are left
+// out.
+func Refs(ctx context.Context) map[string]string {
+	refs := map[string]string{}
+	output, err := git.Command(ctx, "for-each-ref", "--format=%(refname) %(objectname)").Output()
+	if err != nil {
+		return refs
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if name, sha, ok := strings.Cut(line, " "); ok && !strings.HasPrefix(name, "refs/githelper/") {
+			refs[name] = sha
+		}
+	}
+	if output, err := git.Command(ctx, "symbolic-ref", "-q", "HEAD").Output(); err == nil {
+		refs["HEAD"] = strings.TrimSpace(string(output))
+	} else if output, err := git.Command(ctx, "rev-parse", "-q", "--verify", "HEAD").Output(); err == nil {
+		refs["HEAD"] = strings.TrimSpace(string(output))
+	}
+	return refs
+}
+
+// Changes returns the refs that differ between two snapshots taken by Refs,
+// sorted by name
+func Changes(before, after map[string]string) []RefChange {
+	var changes []RefChange
+	for name, old := range before {
+		if after[name] != old {
+			changes = append(changes, RefChange{Name: name, Old: old, New: after[name]})
+		}
+	}
+	for name, sha := range after {
+		if _, ok := before[name]; !ok {
+			changes = append(changes, RefChange{Name: name, New: sha})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+// Append adds an entry to the log in file, dropping the oldest entries once
+// it's larger than maxSize
+func Append(file string, entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	_, err = f.Write(append(line, '\n'))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return trim(file)
+}
+
+// Read returns the entries of the log in file, oldest first
+func Read(file string) ([]Entry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// trim keeps the newest half of the log once it grows past maxSize, so it's
+// rarely rewritten
+func trim(file string) error {
+	info, err := os.Stat(file)
+	if err != nil || info.Size() <= maxSize {
+		return err
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	data = data[int64(len(data))-maxSize/2:]
+	// Start at the first whole line
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// Redact hides the values of flags that look like credentials, such as
+// --token or --webhook-secret, so they're not written to the log
+func Redact(args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i, arg := range redacted {
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		if !isSecretFlag(name) {
+			continue
+		}
+		if hasValue {
+			redacted[i] = "--" + name + "=***"
+		} else if i+1 < len(redacted) && !strings.HasPrefix(redacted[i+1], "-") {
+			redacted[i+1] = "***"
+		}
+	}
+	return redacted
+}
+
+func isSecretFlag(name string) bool {
+	for _, word := range []string{"token", "secret", "password", "key"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
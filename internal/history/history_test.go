@@ -0,0 +1,75 @@
+package history
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGit(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", args...).CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+func TestRefsAndChanges(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	runGit(t, "init", "-q", "-b", "main")
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "one")
+	runGit(t, "branch", "old")
+	first := runGit(t, "rev-parse", "HEAD")
+
+	before := Refs(context.Background())
+	assert.Equal(t, "refs/heads/main", before["HEAD"])
+	assert.Equal(t, first, before["refs/heads/old"])
+
+	runGit(t, "commit", "-q", "--allow-empty", "-m", "two")
+	runGit(t, "branch", "-q", "-D", "old")
+	runGit(t, "tag", "v1")
+	runGit(t, "update-ref", "refs/githelper/backup/x", first)
+	second := runGit(t, "rev-parse", "HEAD")
+
+	assert.Equal(t, []RefChange{
+		{Name: "refs/heads/main", Old: first, New: second},
+		{Name: "refs/heads/old", Old: first},
+		{Name: "refs/tags/v1", New: second},
+	}, Changes(before, Refs(context.Background())))
+}
+
+func TestAppendAndRead(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history.jsonl")
+	entries, err := Read(file)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	size := maxSize
+	maxSize = 4096
+	defer func() { maxSize = size }()
+	for i := 0; i < 100; i++ {
+		require.NoError(t, Append(file, Entry{Time: time.Now(), Command: "sync", ExitCode: i}))
+	}
+	// The log is cut to its newest half once it's full
+	entries, err = Read(file)
+	require.NoError(t, err)
+	assert.Less(t, len(entries), 100)
+	assert.Equal(t, "sync", entries[0].Command)
+	assert.Equal(t, 99, entries[len(entries)-1].ExitCode)
+}
+
+func TestRedact(t *testing.T) {
+	args := []string{"mirror", "--webhook-secret", "s3cret", "--token=abc", "--from", "a/b", "--api-key", "-v"}
+	assert.Equal(t, []string{"mirror", "--webhook-secret", "***", "--token=***", "--from", "a/b", "--api-key", "-v"}, Redact(args))
+	// The arguments themselves are left alone
+	assert.Equal(t, "s3cret", args[2])
+}
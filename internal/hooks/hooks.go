@@ -0,0 +1,83 @@
+// Package hooks runs the shell commands configured to run before and after
+// githelper commands, with the operation described in their environment.
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Stages at which hooks run
+const (
+	// Pre hooks run before the command; one failing stops it
+	Pre = "pre"
+	// Post hooks run after the command succeeded
+	Post = "post"
+	// OnError hooks run after the command failed
+	OnError = "on_error"
+)
+
+// Operation is the githelper command hooks run for
+type Operation struct {
+	// Command is the command path without "githelper", e.g. "pr merge"
+	Command string
+	Args    []string
+	// Repo is the top-level directory of the repository, if any; hooks run
+	// there
+	Repo   string
+	Branch string
+	DryRun bool
+	// ExitCode and Error are the outcome of the command, for post and
+	// on_error hooks
+	ExitCode int
+	Error    string
+}
+
+// Env returns the variables that describe the operation to a hook of stage
+func (o Operation) Env(stage string) []string {
+	return []string{
+		"GITHELPER_HOOK=" + stage,
+		"GITHELPER_COMMAND=" + o.Command,
+		"GITHELPER_ARGS=" + strings.Join(o.Args, " "),
+		"GITHELPER_REPO=" + o.Repo,
+		"GITHELPER_BRANCH=" + o.Branch,
+		"GITHELPER_DRY_RUN=" + strconv.FormatBool(o.DryRun),
+		"GITHELPER_EXIT_CODE=" + strconv.Itoa(o.ExitCode),
+		"GITHELPER_ERROR=" + o.Error,
+	}
+}
+
+// Running reports whether the current process is run by a hook, whose
+// githelper commands don't run hooks again
+func Running() bool {
+	return os.Getenv("GITHELPER_HOOK") != ""
+}
+
+// Command returns the command running line with the shell of the platform
+func Command(ctx context.Context, line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", line)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", line)
+}
+
+// Run runs the hooks of a stage one after the other and stops at the first
+// that fails. Their output goes to out.
+func Run(ctx context.Context, stage string, lines []string, op Operation, out io.Writer) error {
+	for _, line := range lines {
+		cmd := Command(ctx, line)
+		cmd.Dir = op.Repo
+		cmd.Env = append(os.Environ(), op.Env(stage)...)
+		cmd.Stdout, cmd.Stderr = out, out
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook '%s' failed: %w", stage, line, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks use sh in this test")
+	}
+	dir := t.TempDir()
+	op := Operation{Command: "pr merge", Args: []string{"pr", "merge", "42"}, Repo: dir, Branch: "feature", ExitCode: 1, Error: "boom"}
+
+	var out bytes.Buffer
+	err := Run(context.Background(), OnError, []string{
+		`echo "$GITHELPER_HOOK $GITHELPER_COMMAND ($GITHELPER_ARGS) on $GITHELPER_BRANCH"`,
+		`echo "$GITHELPER_EXIT_CODE $GITHELPER_ERROR dry-run=$GITHELPER_DRY_RUN" > result.txt`,
+	}, op, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "on_error pr merge (pr merge 42) on feature\n", out.String())
+	result, err := os.ReadFile(filepath.Join(dir, "result.txt"))
+	require.NoError(t, err, "hooks run in the repository")
+	assert.Equal(t, "1 boom dry-run=false\n", string(result))
+
+	err = Run(context.Background(), Pre, []string{"exit 3", "echo never"}, op, &out)
+	assert.ErrorContains(t, err, "pre hook 'exit 3' failed: exit status 3")
+	assert.NotContains(t, out.String(), "never", "a failing hook stops the others")
+}
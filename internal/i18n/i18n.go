@@ -0,0 +1,134 @@
+// Package i18n translates the messages githelper shows to users. Messages
+// are looked up by key in the catalog of the selected language, falling back
+// to English, so a catalog can be translated a piece at a time.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLanguage is used when no catalog matches the selected language
+const DefaultLanguage = "en"
+
+//go:embed locales/*.yaml
+var locales embed.FS
+
+var (
+	loadOnce sync.Once
+	catalogs map[string]map[string]string
+
+	mu       sync.RWMutex
+	language = DefaultLanguage
+)
+
+// load parses the embedded catalogs, one file per language
+func load() {
+	catalogs = map[string]map[string]string{}
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: failed to read catalogs: %v", err))
+	}
+	for _, entry := range entries {
+		data, err := locales.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: failed to read %s: %v", entry.Name(), err))
+		}
+		messages := map[string]string{}
+		if err := yaml.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: failed to parse %s: %v", entry.Name(), err))
+		}
+		catalogs[strings.TrimSuffix(entry.Name(), ".yaml")] = messages
+	}
+}
+
+// Languages returns the languages with a catalog
+func Languages() []string {
+	loadOnce.Do(load)
+	var names []string
+	for name := range catalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Normalize turns a locale such as "es_ES.UTF-8" or "pt-BR" into the
+// language of a catalog, or DefaultLanguage when there is none
+func Normalize(locale string) string {
+	loadOnce.Do(load)
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	locale = strings.ReplaceAll(locale, "-", "_")
+	if _, ok := catalogs[locale]; ok {
+		return locale
+	}
+	if i := strings.Index(locale, "_"); i >= 0 {
+		if _, ok := catalogs[locale[:i]]; ok {
+			return locale[:i]
+		}
+	}
+	return DefaultLanguage
+}
+
+// Detect picks the language from the configured one, or else from the
+// LC_ALL, LC_MESSAGES and LANG environment variables, in the order the C
+// library checks them
+func Detect(configured string) string {
+	if configured != "" {
+		return Normalize(configured)
+	}
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return Normalize(value)
+		}
+	}
+	return DefaultLanguage
+}
+
+// SetLanguage selects the language of the messages
+func SetLanguage(lang string) {
+	mu.Lock()
+	defer mu.Unlock()
+	language = Normalize(lang)
+}
+
+// Language returns the selected language
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return language
+}
+
+// T returns the message for key in the selected language, formatted with
+// args like fmt.Sprintf. Keys missing from every catalog are returned as
+// they are, so a typo shows up instead of an empty message.
+func T(key string, args ...interface{}) string {
+	loadOnce.Do(load)
+	message, ok := catalogs[Language()][key]
+	if !ok {
+		if message, ok = catalogs[DefaultLanguage][key]; !ok {
+			message = key
+		}
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Yes reports whether answer agrees to a yes/no question in the selected
+// language. "y" is always accepted.
+func Yes(answer string) bool {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == strings.ToLower(T("answer.yes"))
+}
@@ -0,0 +1,69 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"es":          "es",
+		"es_ES.UTF-8": "es",
+		"es-MX":       "es",
+		"ES_es@euro":  "es",
+		"en_US.UTF-8": "en",
+		"C":           "en",
+		"POSIX":       "en",
+		"fr_FR":       DefaultLanguage,
+		"":            DefaultLanguage,
+	}
+	for locale, want := range tests {
+		assert.Equal(t, want, Normalize(locale), locale)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "es_ES.UTF-8")
+	assert.Equal(t, "es", Detect(""))
+	assert.Equal(t, "en", Detect("en"), "the configured language wins")
+
+	t.Setenv("LC_ALL", "en_GB.UTF-8")
+	assert.Equal(t, "en", Detect(""), "LC_ALL overrides LANG")
+}
+
+func TestT(t *testing.T) {
+	defer SetLanguage(DefaultLanguage)
+
+	SetLanguage("es")
+	assert.Equal(t, "Ya estás al día con origin/main", T("sync.up_to_date", "origin/main"))
+	assert.Equal(t, "no such.key", T("no such.key"))
+	assert.True(t, Yes("s"))
+	assert.True(t, Yes("y"))
+	assert.False(t, Yes("n"))
+
+	SetLanguage("en")
+	assert.Equal(t, "Already up to date with origin/main", T("sync.up_to_date", "origin/main"))
+	assert.False(t, Yes("s"))
+}
+
+// verb matches the fmt verbs in a message
+var verb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogsMatchEnglish(t *testing.T) {
+	loadOnce.Do(load)
+	english := catalogs[DefaultLanguage]
+	for _, lang := range Languages() {
+		for key, message := range catalogs[lang] {
+			reference, ok := english[key]
+			if !assert.True(t, ok, "%s: %s is not in the English catalog", lang, key) {
+				continue
+			}
+			assert.Equal(t, verb.FindAllString(reference, -1), verb.FindAllString(message, -1),
+				"%s: %s has other verbs than in English", lang, key)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Package jobs keeps track of githelper commands running in the background:
+// what was started, where its output goes and how it ended.
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// EnvID is set to the ID of the job in the environment of the process
+// running it
+const EnvID = "GITHELPER_JOB"
+
+// States of a job
+const (
+	Running   = "running"
+	Succeeded = "succeeded"
+	Failed    = "failed"
+	Cancelled = "cancelled"
+	// Lost jobs stopped without recording how, e.g. when the machine
+	// restarted
+	Lost = "lost"
+)
+
+// cancelledExitCode is the exit code of a command stopped by a signal
+const cancelledExitCode = 130
+
+// ErrNotFound is returned for an ID that has no job
+var ErrNotFound = errors.New("job not found")
+
+// Job is a command started in the background. The process that starts it
+// writes job.json; the process running it writes result.json when it ends,
+// so neither overwrites the other.
+type Job struct {
+	ID      int       `json:"id"`
+	Command string    `json:"command"`
+	Args    []string  `json:"args"`
+	Dir     string    `json:"dir"`
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+	Result  *Result   `json:"-"`
+}
+
+// Result is how a job ended
+type Result struct {
+	Finished time.Time `json:"finished"`
+	ExitCode int       `json:"exit_code"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// State returns the state of the job
+func (j *Job) State() string {
+	switch {
+	case j.Result == nil && Alive(j.PID):
+		return Running
+	case j.Result == nil:
+		return Lost
+	case j.Result.ExitCode == 0:
+		return Succeeded
+	case j.Result.ExitCode == cancelledExitCode:
+		return Cancelled
+	}
+	return Failed
+}
+
+// Duration returns how long the job ran, or has been running
+func (j *Job) Duration() time.Duration {
+	if j.Result != nil {
+		return j.Result.Finished.Sub(j.Started)
+	}
+	return time.Since(j.Started)
+}
+
+// Store keeps a directory per job, named after its ID, with job.json,
+// result.json and output.log
+type Store struct {
+	Dir string
+}
+
+// DefaultStore returns the store in ~/.githelper/jobs
+func DefaultStore() (*Store, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return &Store{Dir: filepath.Join(home, ".githelper", "jobs")}, nil
+}
+
+func (s *Store) jobDir(id int) string {
+	return filepath.Join(s.Dir, strconv.Itoa(id))
+}
+
+// LogPath returns the file the output of a job goes to
+func (s *Store) LogPath(id int) string {
+	return filepath.Join(s.jobDir(id), "output.log")
+}
+
+// Create gives the job the next free ID and makes its directory. The job
+// is saved once it has a PID, with Save.
+func (s *Store) Create(job *Job) error {
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", s.Dir, err)
+	}
+	ids, err := s.ids()
+	if err != nil {
+		return err
+	}
+	next := 1
+	if len(ids) > 0 {
+		next = ids[len(ids)-1] + 1
+	}
+	// Mkdir fails when another githelper took the ID first
+	for ; ; next++ {
+		err := os.Mkdir(s.jobDir(next), 0700)
+		if err == nil {
+			job.ID = next
+			return nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to create job directory: %w", err)
+		}
+	}
+}
+
+// Save writes job.json
+func (s *Store) Save(job *Job) error {
+	return writeJSON(filepath.Join(s.jobDir(job.ID), "job.json"), job)
+}
+
+// Finish writes how the job ended
+func (s *Store) Finish(id int, result Result) error {
+	return writeJSON(filepath.Join(s.jobDir(id), "result.json"), result)
+}
+
+// Get returns a job
+func (s *Store) Get(id int) (*Job, error) {
+	var job Job
+	data, err := os.ReadFile(filepath.Join(s.jobDir(id), "job.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("invalid job %d: %w", id, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(s.jobDir(id), "result.json")); err == nil {
+		var result Result
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("invalid result of job %d: %w", id, err)
+		}
+		job.Result = &result
+	}
+	return &job, nil
+}
+
+// List returns every job, oldest first. Jobs still being started are left
+// out.
+func (s *Store) List() ([]*Job, error) {
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*Job
+	for _, id := range ids {
+		job, err := s.Get(id)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// Prune removes the oldest jobs that are over, keeping the last keep
+func (s *Store) Prune(keep int) error {
+	jobs, err := s.List()
+	if err != nil {
+		return err
+	}
+	var over []*Job
+	for _, job := range jobs {
+		if job.State() != Running {
+			over = append(over, job)
+		}
+	}
+	for len(over) > keep {
+		if err := os.RemoveAll(s.jobDir(over[0].ID)); err != nil {
+			return fmt.Errorf("failed to remove job %d: %w", over[0].ID, err)
+		}
+		over = over[1:]
+	}
+	return nil
+}
+
+// ids returns the IDs of the job directories, in order
+func (s *Store) ids() ([]int, error) {
+	entries, err := os.ReadDir(s.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.Dir, err)
+	}
+	var ids []int
+	for _, entry := range entries {
+		if id, err := strconv.Atoi(entry.Name()); err == nil && entry.IsDir() {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Readers never see a half-written file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return os.Rename(tmp, path)
+}
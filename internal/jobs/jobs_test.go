@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	store := &Store{Dir: t.TempDir()}
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var created []*Job
+	for _, command := range []string{"clone", "copy", "mirror"} {
+		job := &Job{Command: command, Args: []string{command}, PID: os.Getpid(), Started: started}
+		require.NoError(t, store.Create(job))
+		require.NoError(t, store.Save(job))
+		created = append(created, job)
+	}
+	assert.Equal(t, []int{1, 2, 3}, []int{created[0].ID, created[1].ID, created[2].ID})
+
+	require.NoError(t, store.Finish(1, Result{Finished: started.Add(time.Minute)}))
+	require.NoError(t, store.Finish(2, Result{Finished: started.Add(time.Second), ExitCode: 130, Error: "interrupted"}))
+
+	jobs, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+	assert.Equal(t, Succeeded, jobs[0].State())
+	assert.Equal(t, time.Minute, jobs[0].Duration())
+	assert.Equal(t, Cancelled, jobs[1].State())
+	// This process is alive and job 3 hasn't finished
+	assert.Equal(t, Running, jobs[2].State())
+
+	_, err = store.Get(7)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	// Running jobs are kept however many there are
+	require.NoError(t, store.Prune(0))
+	jobs, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, 3, jobs[0].ID)
+
+	// IDs keep growing
+	job := &Job{Command: "clone"}
+	require.NoError(t, store.Create(job))
+	assert.Equal(t, 4, job.ID)
+}
+
+func TestLostJob(t *testing.T) {
+	job := &Job{PID: 0}
+	assert.Equal(t, Lost, job.State())
+	job.Result = &Result{ExitCode: 1}
+	assert.Equal(t, Failed, job.State())
+}
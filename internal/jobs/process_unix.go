@@ -0,0 +1,29 @@
+//go:build !windows
+
+package jobs
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+)
+
+// Detach makes cmd run in a session of its own, so it outlives the
+// terminal it was started from
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// Alive reports whether a process is running
+func Alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// Terminate asks a process to stop, as Ctrl+C would
+func Terminate(pid int) error {
+	return syscall.Kill(pid, syscall.SIGTERM)
+}
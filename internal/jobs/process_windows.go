@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// detachedProcess starts the process without a console
+const detachedProcess = 0x00000008
+
+// Detach makes cmd run without the console it was started from, so it
+// outlives it
+func Detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess}
+}
+
+// Alive reports whether a process is running
+func Alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
+
+// Terminate stops a process. Windows has no SIGTERM, so it can't clean up.
+func Terminate(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
@@ -0,0 +1,298 @@
+// Package journal records the state of a repository before githelper
+// rewrites history, so the operation can be rolled back.
+package journal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gitpkg "github.com/EndlessUphill/git-helper/internal/git"
+)
+
+// BackupPrefix is the namespace of the refs that keep recorded commits alive
+const BackupPrefix = "refs/githelper/backup/"
+
+var ErrEmpty = errors.New("no operations recorded")
+
+// Entry is the state of the repository before an operation
+type Entry struct {
+	ID        string    `json:"id"`
+	Operation string    `json:"operation"`
+	Time      time.Time `json:"time"`
+	// Head is the branch checked out (refs/heads/...) or a commit when detached
+	Head string `json:"head"`
+	// Refs maps each recorded ref to the commit it pointed to
+	Refs map[string]string `json:"refs"`
+	// WorkTree is a stash commit of uncommitted changes, if there were any
+	WorkTree   string `json:"worktree,omitempty"`
+	RolledBack bool   `json:"rolled_back,omitempty"`
+}
+
+// Journal is the list of recorded operations of a repository, stored in
+// <git-dir>/githelper/journal.jsonl
+type Journal struct {
+	file string
+}
+
+// Open opens the journal of the repository in the current directory
+func Open(ctx context.Context) (*Journal, error) {
+	output, err := gitpkg.Command(ctx, "rev-parse", "--git-common-dir").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git directory: %w", err)
+	}
+	gitDir, err := filepath.Abs(strings.TrimSpace(string(output)))
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{file: filepath.Join(gitDir, "githelper", "journal.jsonl")}, nil
+}
+
+// Record saves the commit of the current branch, of any refs matching the
+// given patterns (e.g. "refs/heads/" and "refs/tags/" for every branch and
+// tag) and any uncommitted changes, before operation runs
+func (j *Journal) Record(ctx context.Context, operation string, refs ...string) (*Entry, error) {
+	now := time.Now()
+	entry := &Entry{
+		ID:        now.UTC().Format("20060102T150405.000"),
+		Operation: operation,
+		Time:      now,
+		Refs:      make(map[string]string),
+	}
+
+	if output, err := gitpkg.Command(ctx, "symbolic-ref", "-q", "HEAD").Output(); err == nil {
+		entry.Head = strings.TrimSpace(string(output))
+	} else if sha, err := revParse(ctx, "HEAD"); err == nil {
+		entry.Head = sha
+	}
+
+	if len(refs) > 0 {
+		args := append([]string{"for-each-ref", "--format=%(refname) %(objectname)"}, refs...)
+		output, err := gitpkg.Command(ctx, args...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list refs: %w", err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+			if name, sha, ok := strings.Cut(line, " "); ok {
+				entry.Refs[name] = sha
+			}
+		}
+	}
+	if strings.HasPrefix(entry.Head, "refs/") {
+		if sha, err := revParse(ctx, entry.Head); err == nil {
+			entry.Refs[entry.Head] = sha
+		}
+	} else if entry.Head != "" {
+		entry.Refs["HEAD"] = entry.Head
+	}
+	if len(entry.Refs) == 0 {
+		return nil, fmt.Errorf("nothing to record: the repository has no commits")
+	}
+
+	// Keep uncommitted changes: 'git stash create' makes a commit without
+	// touching the working tree or the stash list
+	if output, err := gitpkg.Command(ctx, "stash", "create").Output(); err == nil {
+		entry.WorkTree = strings.TrimSpace(string(output))
+	}
+
+	// Backup refs stop gc from pruning the recorded commits
+	for name, sha := range entry.Refs {
+		if err := updateRef(ctx, backupRef(entry.ID, name), sha); err != nil {
+			return nil, err
+		}
+	}
+	if entry.WorkTree != "" {
+		if err := updateRef(ctx, backupRef(entry.ID, "worktree"), entry.WorkTree); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := j.append(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Entries returns the recorded operations, oldest first
+func (j *Journal) Entries() ([]Entry, error) {
+	file, err := os.Open(j.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Last returns the most recent entry that was not rolled back
+func (j *Journal) Last() (*Entry, error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].RolledBack {
+			return &entries[i], nil
+		}
+	}
+	return nil, ErrEmpty
+}
+
+// Find returns the entry with the given ID
+func (j *Journal) Find(id string) (*Entry, error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no recorded operation with ID %s", id)
+}
+
+// Restore moves every recorded ref back, checks out the recorded HEAD and
+// reapplies uncommitted changes. The working tree must be clean.
+func (j *Journal) Restore(ctx context.Context, entry *Entry) error {
+	for name, sha := range entry.Refs {
+		if name == "HEAD" {
+			continue
+		}
+		if err := updateRef(ctx, name, sha); err != nil {
+			return err
+		}
+	}
+
+	// The checked out branch may have moved as well; bring the working tree
+	// in line before switching
+	if err := git(ctx, "reset", "-q", "--hard"); err != nil {
+		return fmt.Errorf("failed to reset working tree: %w", err)
+	}
+
+	if branch, ok := strings.CutPrefix(entry.Head, "refs/heads/"); ok {
+		if err := git(ctx, "checkout", "-q", branch); err != nil {
+			return fmt.Errorf("failed to check out %s: %w", branch, err)
+		}
+	} else if entry.Head != "" {
+		if err := git(ctx, "checkout", "-q", "--detach", entry.Head); err != nil {
+			return fmt.Errorf("failed to check out %s: %w", entry.Head, err)
+		}
+	}
+
+	if entry.WorkTree != "" {
+		if err := git(ctx, "stash", "apply", entry.WorkTree); err != nil {
+			return fmt.Errorf("failed to restore uncommitted changes (still available as %s): %w", entry.WorkTree, err)
+		}
+	}
+
+	return j.markRolledBack(entry.ID)
+}
+
+// Clear deletes the journal and all backup refs
+func (j *Journal) Clear(ctx context.Context) error {
+	output, err := gitpkg.Command(ctx, "for-each-ref", "--format=%(refname)", BackupPrefix).Output()
+	if err != nil {
+		return fmt.Errorf("failed to list backup refs: %w", err)
+	}
+	for _, name := range strings.Fields(string(output)) {
+		if err := git(ctx, "update-ref", "-d", name); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", name, err)
+		}
+	}
+	if err := os.Remove(j.file); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete journal: %w", err)
+	}
+	return nil
+}
+
+func (j *Journal) append(entry *Entry) error {
+	if err := os.MkdirAll(filepath.Dir(j.file), 0755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	file, err := os.OpenFile(j.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+func (j *Journal) markRolledBack(id string) error {
+	entries, err := j.Entries()
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, entry := range entries {
+		if entry.ID == id {
+			entry.RolledBack = true
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, string(data))
+	}
+	return os.WriteFile(j.file, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// backupRef returns the backup ref of a recorded ref, e.g.
+// refs/githelper/backup/<id>/heads/main
+func backupRef(id, name string) string {
+	return BackupPrefix + id + "/" + strings.TrimPrefix(name, "refs/")
+}
+
+func revParse(ctx context.Context, rev string) (string, error) {
+	output, err := gitpkg.Command(ctx, "rev-parse", "--verify", "-q", rev).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func updateRef(ctx context.Context, name, sha string) error {
+	if err := git(ctx, "update-ref", name, sha); err != nil {
+		return fmt.Errorf("failed to update %s: %w", name, err)
+	}
+	return nil
+}
+
+func git(ctx context.Context, args ...string) error {
+	cmd := gitpkg.Command(ctx, args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if len(output) > 0 {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package journal
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGit(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", args...).CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+// setupRepo creates a repository with two commits on main and makes it the
+// working directory
+func setupRepo(t *testing.T) string {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	runGit(t, "init", "-q", "-b", "main")
+	for _, content := range []string{"one", "two"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte(content), 0644))
+		runGit(t, "add", ".")
+		runGit(t, "commit", "-q", "-m", content)
+	}
+	return dir
+}
+
+func TestRecordAndRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := setupRepo(t)
+	main := runGit(t, "rev-parse", "HEAD")
+	runGit(t, "branch", "feature", "HEAD~1")
+	feature := runGit(t, "rev-parse", "feature")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte("local"), 0644))
+
+	j, err := Open(ctx)
+	require.NoError(t, err)
+	entry, err := j.Record(ctx, "purge", "refs/heads/")
+	require.NoError(t, err)
+
+	assert.Equal(t, "refs/heads/main", entry.Head)
+	assert.Equal(t, main, entry.Refs["refs/heads/main"])
+	assert.Equal(t, feature, entry.Refs["refs/heads/feature"])
+	assert.NotEmpty(t, entry.WorkTree)
+	assert.Equal(t, main, runGit(t, "rev-parse", BackupPrefix+entry.ID+"/heads/main"))
+
+	// Rewrite both branches and switch away
+	runGit(t, "reset", "-q", "--hard", "HEAD~1")
+	runGit(t, "checkout", "-q", "-b", "other")
+	runGit(t, "branch", "-f", "feature", main)
+
+	last, err := j.Last()
+	require.NoError(t, err)
+	require.NoError(t, j.Restore(ctx, last))
+
+	assert.Equal(t, "main", runGit(t, "branch", "--show-current"))
+	assert.Equal(t, main, runGit(t, "rev-parse", "HEAD"))
+	assert.Equal(t, feature, runGit(t, "rev-parse", "feature"))
+	content, err := os.ReadFile(filepath.Join(dir, "file.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "local", string(content))
+
+	_, err = j.Last()
+	assert.ErrorIs(t, err, ErrEmpty)
+}
+
+func TestRecordCurrentBranchOnly(t *testing.T) {
+	ctx := context.Background()
+	setupRepo(t)
+	runGit(t, "branch", "feature", "HEAD~1")
+
+	j, err := Open(ctx)
+	require.NoError(t, err)
+	entry, err := j.Record(ctx, "squash")
+	require.NoError(t, err)
+
+	assert.Len(t, entry.Refs, 1)
+	assert.Contains(t, entry.Refs, "refs/heads/main")
+	assert.Empty(t, entry.WorkTree)
+}
+
+func TestClear(t *testing.T) {
+	ctx := context.Background()
+	setupRepo(t)
+
+	j, err := Open(ctx)
+	require.NoError(t, err)
+	_, err = j.Record(ctx, "undo")
+	require.NoError(t, err)
+
+	require.NoError(t, j.Clear(ctx))
+	entries, err := j.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.Empty(t, runGit(t, "for-each-ref", BackupPrefix))
+}
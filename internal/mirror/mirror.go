@@ -0,0 +1,209 @@
+// Package mirror keeps a destination repository in sync with a source by
+// fetching into a local bare mirror and pushing it with --mirror.
+package mirror
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Mirror copies the branches and tags of From to To, using a bare repository
+// in Dir as the intermediate copy
+type Mirror struct {
+	From string
+	To   string
+	Dir  string
+}
+
+// State is the outcome of the syncs of a mirror, stored next to it
+type State struct {
+	From        string        `json:"from"`
+	To          string        `json:"to"`
+	LastSync    time.Time     `json:"last_sync"`
+	LastSuccess time.Time     `json:"last_success"`
+	LastError   string        `json:"last_error,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	Runs        int           `json:"runs"`
+	Failures    int           `json:"failures"`
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// New returns the mirror from -> to whose cache lives in a directory under
+// baseDir named after both URLs
+func New(from, to, baseDir string) *Mirror {
+	sum := sha256.Sum256([]byte(from + "\n" + to))
+	name := strings.Trim(unsafeChars.ReplaceAllString(strings.TrimSuffix(lastSegment(from), ".git"), "-"), "-")
+	if name == "" {
+		name = "mirror"
+	}
+	return &Mirror{
+		From: from,
+		To:   to,
+		Dir:  filepath.Join(baseDir, fmt.Sprintf("%s-%s.git", name, hex.EncodeToString(sum[:4]))),
+	}
+}
+
+func lastSegment(url string) string {
+	url = strings.TrimSuffix(url, "/")
+	if i := strings.LastIndexAny(url, "/:"); i >= 0 {
+		return url[i+1:]
+	}
+	return url
+}
+
+// Sync fetches the source, pruning deleted refs, and pushes the mirror to
+// the destination. The result is recorded in the state file.
+func (m *Mirror) Sync(ctx context.Context) error {
+	state, _ := m.State()
+	state.From, state.To = m.From, m.To
+	state.Runs++
+	start := time.Now()
+
+	err := m.sync(ctx)
+
+	state.LastSync = start
+	state.Duration = time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		state.Failures++
+		state.LastError = err.Error()
+	} else {
+		state.LastSuccess = start
+		state.LastError = ""
+	}
+	if saveErr := m.saveState(state); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return err
+}
+
+func (m *Mirror) sync(ctx context.Context) error {
+	if _, err := os.Stat(m.Dir); os.IsNotExist(err) {
+		if err := m.init(ctx); err != nil {
+			os.RemoveAll(m.Dir)
+			return err
+		}
+	}
+
+	// The source URL may have changed since the mirror was created
+	if err := git(ctx, m.Dir, "remote", "set-url", "origin", m.From); err != nil {
+		return err
+	}
+	if err := git(ctx, m.Dir, "fetch", "--prune", "--quiet", "origin"); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", m.From, err)
+	}
+
+	if err := git(ctx, m.Dir, "push", "--mirror", "--quiet", m.To); err != nil {
+		return fmt.Errorf("failed to push to %s: %w", m.To, err)
+	}
+	return nil
+}
+
+// init creates the bare repository that mirrors the branches and tags of the
+// source. Other refs, such as GitHub's read-only refs/pull/*, are left out
+// so the destination accepts the push.
+func (m *Mirror) init(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(m.Dir), 0755); err != nil {
+		return fmt.Errorf("failed to create mirror directory: %w", err)
+	}
+	if err := git(ctx, "", "init", "--bare", "--quiet", m.Dir); err != nil {
+		return fmt.Errorf("failed to create mirror: %w", err)
+	}
+	if err := git(ctx, m.Dir, "remote", "add", "origin", m.From); err != nil {
+		return err
+	}
+	if err := git(ctx, m.Dir, "config", "--replace-all", "remote.origin.fetch", "+refs/heads/*:refs/heads/*"); err != nil {
+		return err
+	}
+	return git(ctx, m.Dir, "config", "--add", "remote.origin.fetch", "+refs/tags/*:refs/tags/*")
+}
+
+func (m *Mirror) stateFile() string {
+	return strings.TrimSuffix(m.Dir, ".git") + ".json"
+}
+
+// State returns the recorded state, or an empty state before the first sync
+func (m *Mirror) State() (State, error) {
+	var state State
+	data, err := os.ReadFile(m.stateFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, err
+	}
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+func (m *Mirror) saveState(state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.Dir), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(m.stateFile(), data, 0644)
+}
+
+// States returns the state of every mirror in baseDir
+func States(baseDir string) ([]State, error) {
+	files, err := filepath.Glob(filepath.Join(baseDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var states []State
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var state State
+		if json.Unmarshal(data, &state) == nil {
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+// VerifySignature checks a GitHub webhook X-Hub-Signature-256 header
+// ("sha256=<hex>") against the payload
+func VerifySignature(secret string, payload []byte, signature string) bool {
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	expected, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func git(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// A daemon has nobody to answer credential prompts
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package mirror
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+func TestSync(t *testing.T) {
+	root := t.TempDir()
+	src := filepath.Join(root, "src")
+	dst := filepath.Join(root, "dst.git")
+	runGit(t, root, "init", "-q", "-b", "main", src)
+	runGit(t, src, "commit", "-q", "--allow-empty", "-m", "initial")
+	runGit(t, src, "branch", "feature")
+	runGit(t, src, "update-ref", "refs/pull/1/head", "HEAD")
+	runGit(t, root, "init", "-q", "--bare", dst)
+
+	m := New(src, dst, filepath.Join(root, "mirrors"))
+	require.NoError(t, m.Sync(context.Background()))
+	assert.Equal(t, "feature\nmain", runGit(t, dst, "for-each-ref", "--format=%(refname:short)", "refs/heads/"))
+	assert.Empty(t, runGit(t, dst, "for-each-ref", "refs/pull/"))
+
+	// Deleted branches are pruned and new tags are pushed
+	runGit(t, src, "branch", "-D", "feature")
+	runGit(t, src, "tag", "v1")
+	require.NoError(t, m.Sync(context.Background()))
+	assert.Equal(t, "main", runGit(t, dst, "for-each-ref", "--format=%(refname:short)", "refs/heads/"))
+	assert.Equal(t, "v1", runGit(t, dst, "for-each-ref", "--format=%(refname:short)", "refs/tags/"))
+
+	state, err := m.State()
+	require.NoError(t, err)
+	assert.Equal(t, 2, state.Runs)
+	assert.Zero(t, state.Failures)
+	assert.Empty(t, state.LastError)
+}
+
+func TestSyncRecordsFailures(t *testing.T) {
+	root := t.TempDir()
+	m := New(filepath.Join(root, "missing"), filepath.Join(root, "dst.git"), root)
+
+	assert.Error(t, m.Sync(context.Background()))
+	state, err := m.State()
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.Failures)
+	assert.NotEmpty(t, state.LastError)
+
+	states, err := States(root)
+	require.NoError(t, err)
+	assert.Len(t, states, 1)
+}
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"ref":"refs/heads/main"}`)
+	// printf '%s' '{"ref":"refs/heads/main"}' | openssl dgst -sha256 -hmac secret
+	signature := "sha256=d8f89f0618acd61fe621aa4e64078c0e2bca15d0b578b7f3eb734f55883c5320"
+
+	assert.True(t, VerifySignature("secret", payload, signature))
+	assert.False(t, VerifySignature("other", payload, signature))
+	assert.False(t, VerifySignature("secret", []byte("{}"), signature))
+	assert.False(t, VerifySignature("secret", payload, "sha1=abc"))
+	assert.False(t, VerifySignature("secret", payload, "sha256=zz"))
+}
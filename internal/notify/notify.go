@@ -0,0 +1,65 @@
+// Package notify tells the user that long-running work finished, with a
+// native desktop notification, the terminal bell or a chat webhook.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnsupported is returned when no notification tool is available
+var ErrUnsupported = errors.New("desktop notifications are not available")
+
+// Desktop shows a native notification: through osascript on macOS,
+// notify-send on Linux and the BSDs, and PowerShell on Windows
+func Desktop(title, message string) error {
+	name, args := command(runtime.GOOS, title, message)
+	if name == "" {
+		return ErrUnsupported
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return ErrUnsupported
+	}
+	if output, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to show notification: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Bell rings the terminal bell
+func Bell(w io.Writer) {
+	fmt.Fprint(w, "\a")
+}
+
+// command returns the program and arguments showing a notification on goos
+func command(goos, title, message string) (string, []string) {
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		return "osascript", []string{"-e", script}
+	case "windows":
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.Visible = $true
+$n.ShowBalloonTip(10000, %s, %s, 'Info')
+Start-Sleep -Seconds 5
+$n.Dispose()`, powerShellString(title), powerShellString(message))
+		return "powershell", []string{"-NoProfile", "-Command", script}
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "notify-send", []string{"--app-name=githelper", title, message}
+	}
+	return "", nil
+}
+
+func appleScriptString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
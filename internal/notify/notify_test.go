@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommand(t *testing.T) {
+	name, args := command("darwin", "Merged", `PR "#42"`)
+	assert.Equal(t, "osascript", name)
+	assert.Equal(t, []string{"-e", `display notification "PR \"#42\"" with title "Merged"`}, args)
+
+	name, args = command("linux", "Merged", "PR #42")
+	assert.Equal(t, "notify-send", name)
+	assert.Equal(t, []string{"--app-name=githelper", "Merged", "PR #42"}, args)
+
+	name, args = command("windows", "It's merged", "PR #42")
+	assert.Equal(t, "powershell", name)
+	assert.Contains(t, args[2], `ShowBalloonTip(10000, 'It''s merged', 'PR #42', 'Info')`)
+
+	name, _ = command("plan9", "Merged", "PR #42")
+	assert.Empty(t, name)
+}
+
+func TestBell(t *testing.T) {
+	var out bytes.Buffer
+	Bell(&out)
+	assert.Equal(t, "\a", out.String())
+}
@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Webhook kinds
+const (
+	Slack   = "slack"
+	Discord = "discord"
+	// JSON posts the Summary itself, for any other service
+	JSON = "json"
+)
+
+// discordLimit is the longest message Discord accepts
+const discordLimit = 2000
+
+// Summary is the outcome of a long-running operation
+type Summary struct {
+	// Operation is what ran, e.g. "mirror sync"
+	Operation string `json:"operation"`
+	// Command is the command line, without secrets
+	Command string `json:"command"`
+	Repo    string `json:"repo,omitempty"`
+	Success bool   `json:"success"`
+	// Message is the result of the operation or its error
+	Message  string        `json:"message"`
+	Details  []string      `json:"details,omitempty"`
+	Duration time.Duration `json:"-"`
+	// Seconds is Duration for the JSON payload
+	Seconds float64 `json:"duration_seconds"`
+}
+
+// Title says what ran and how it ended, e.g. "githelper mirror sync failed"
+func (s Summary) Title() string {
+	if s.Success {
+		return "githelper " + s.Operation + " succeeded"
+	}
+	return "githelper " + s.Operation + " failed"
+}
+
+// text describes the summary in a few lines of Markdown, with bold the
+// marker of bold text: * for Slack and ** for Discord
+func (s Summary) text(bold string) string {
+	icon := "✅"
+	if !s.Success {
+		icon = "❌"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s%s%s", icon, bold, s.Title(), bold)
+	if s.Repo != "" {
+		fmt.Fprintf(&b, " in %s", s.Repo)
+	}
+	if s.Duration > 0 {
+		fmt.Fprintf(&b, " after %s", s.Duration.Round(time.Second))
+	}
+	if s.Message != "" {
+		b.WriteString("\n" + s.Message)
+	}
+	for _, detail := range s.Details {
+		b.WriteString("\n• " + detail)
+	}
+	if s.Command != "" {
+		fmt.Fprintf(&b, "\n`%s`", s.Command)
+	}
+	return b.String()
+}
+
+// Webhook is a URL summaries are posted to
+type Webhook struct {
+	URL string `mapstructure:"url"`
+	// Type is Slack, Discord or JSON; empty guesses it from the URL
+	Type string `mapstructure:"type"`
+	// OnlyFailures skips the summaries of operations that succeeded
+	OnlyFailures bool `mapstructure:"only_failures"`
+}
+
+// Kind returns the type of the webhook
+func (w Webhook) Kind() string {
+	if w.Type != "" {
+		return strings.ToLower(w.Type)
+	}
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return JSON
+	}
+	host := strings.ToLower(u.Hostname())
+	switch {
+	case host == "hooks.slack.com":
+		return Slack
+	case (host == "discord.com" || host == "discordapp.com") && strings.HasPrefix(u.Path, "/api/webhooks/"):
+		return Discord
+	}
+	return JSON
+}
+
+// Payload returns the body posted for a summary
+func (w Webhook) Payload(s Summary) ([]byte, error) {
+	switch w.Kind() {
+	case Slack:
+		return json.Marshal(map[string]string{"text": s.text("*")})
+	case Discord:
+		text := []rune(s.text("**"))
+		if len(text) > discordLimit {
+			text = append(text[:discordLimit-1], '…')
+		}
+		return json.Marshal(map[string]string{"content": string(text)})
+	case JSON:
+		s.Seconds = s.Duration.Seconds()
+		return json.Marshal(s)
+	}
+	return nil, fmt.Errorf("unknown webhook type '%s' (use slack, discord or json)", w.Type)
+}
+
+// Send posts a summary to the webhook
+func (w Webhook) Send(ctx context.Context, client *http.Client, s Summary) error {
+	if w.OnlyFailures && s.Success {
+		return nil
+	}
+	body, err := w.Payload(s)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("%s rejected the notification: %s %s", req.URL.Host, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookKind(t *testing.T) {
+	assert.Equal(t, Slack, Webhook{URL: "https://hooks.slack.com/services/T0/B0/x"}.Kind())
+	assert.Equal(t, Discord, Webhook{URL: "https://discord.com/api/webhooks/1/abc"}.Kind())
+	assert.Equal(t, JSON, Webhook{URL: "https://ci.example.com/hook"}.Kind())
+	assert.Equal(t, Slack, Webhook{URL: "https://chat.example.com/hook", Type: "Slack"}.Kind())
+}
+
+func TestWebhookPayload(t *testing.T) {
+	summary := Summary{
+		Operation: "mirror sync",
+		Command:   "githelper mirror run-once",
+		Repo:      "acme/widget",
+		Message:   "exit status 128",
+		Details:   []string{"fetch failed"},
+		Duration:  90 * time.Second,
+	}
+
+	body, err := Webhook{Type: Slack}.Payload(summary)
+	require.NoError(t, err)
+	var slack map[string]string
+	require.NoError(t, json.Unmarshal(body, &slack))
+	assert.Equal(t, "❌ *githelper mirror sync failed* in acme/widget after 1m30s\nexit status 128\n• fetch failed\n`githelper mirror run-once`", slack["text"])
+
+	summary.Details = []string{strings.Repeat("x", 3000)}
+	body, err = Webhook{Type: Discord}.Payload(summary)
+	require.NoError(t, err)
+	var discord map[string]string
+	require.NoError(t, json.Unmarshal(body, &discord))
+	assert.True(t, strings.HasPrefix(discord["content"], "❌ **githelper mirror sync failed**"))
+	assert.Len(t, []rune(discord["content"]), discordLimit)
+
+	body, err = Webhook{Type: JSON}.Payload(summary)
+	require.NoError(t, err)
+	var generic map[string]any
+	require.NoError(t, json.Unmarshal(body, &generic))
+	assert.Equal(t, "mirror sync", generic["operation"])
+	assert.Equal(t, false, generic["success"])
+	assert.Equal(t, 90.0, generic["duration_seconds"])
+
+	_, err = Webhook{Type: "teams"}.Payload(summary)
+	assert.Error(t, err)
+}
+
+func TestWebhookSend(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		if r.URL.Path == "/broken" {
+			http.Error(w, "no such hook", http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ok := Summary{Operation: "checks wait", Success: true}
+	require.NoError(t, Webhook{URL: server.URL + "/hook"}.Send(context.Background(), server.Client(), ok))
+	require.Len(t, received, 1)
+	assert.Contains(t, received[0], `"success":true`)
+
+	// Only failures are sent to this one
+	require.NoError(t, Webhook{URL: server.URL + "/hook", OnlyFailures: true}.Send(context.Background(), server.Client(), ok))
+	assert.Len(t, received, 1)
+
+	err := Webhook{URL: server.URL + "/broken"}.Send(context.Background(), server.Client(), ok)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+	assert.Contains(t, err.Error(), "no such hook")
+}
@@ -0,0 +1,245 @@
+// Package plain turns githelper's output into plain text for screen
+// readers, dumb terminals and log files: emoji, colors, cursor movement and
+// box drawing are replaced with ASCII.
+package plain
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	variationSelector = '\uFE0F'
+	zeroWidthJoiner   = '\u200D'
+	keycap            = '\u20E3'
+)
+
+// words replace the emoji that carry meaning; other emoji are dropped
+var words = map[rune]string{
+	'✅': "OK:",
+	'❌': "Error:",
+	'⚠': "Warning:",
+	'ℹ': "Note:",
+	'💡': "Tip:",
+	'🛑': "Stopped:",
+	'⏱': "Timeout:",
+}
+
+// ascii replaces box drawing, block and arrow characters
+var ascii = strings.NewReplacer(
+	"─", "-", "━", "-", "═", "=", "│", "|", "┃", "|", "║", "|",
+	"┌", "+", "┐", "+", "└", "+", "┘", "+", "├", "+", "┤", "+", "┬", "+", "┴", "+", "┼", "+",
+	"╔", "+", "╗", "+", "╚", "+", "╝", "+", "╭", "+", "╮", "+", "╯", "+", "╰", "+",
+	"█", "#", "▓", "#", "▒", "+", "░", "-",
+	"▁", "_", "▂", "_", "▃", "-", "▄", "-", "▅", "=", "▆", "=", "▇", "#",
+	"■", "#", "▶", ">", "→", "->", "←", "<-", "↑", "^", "↓", "v", "•", "-", "…", "...", "·", ".",
+)
+
+// escape matches ANSI escape sequences: colors, erasing and cursor movement
+var escape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]|\x1b\][^\x07]*\x07`)
+
+// Text returns s as plain text
+func Text(s string) string {
+	s = escape.ReplaceAllString(s, "")
+	// Progress lines are redrawn with a carriage return
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	s = ascii.Replace(s)
+
+	var out strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == variationSelector || r == keycap || r == zeroWidthJoiner:
+			i += size
+			continue
+		case !isEmoji(r):
+			out.WriteRune(r)
+			i += size
+			continue
+		}
+		// Skip the rest of the emoji sequence, and the spaces after it
+		end, prev := i+size, r
+		for end < len(s) {
+			next, nextSize := utf8.DecodeRuneInString(s[end:])
+			if next != variationSelector && next != zeroWidthJoiner && !(prev == zeroWidthJoiner && isEmoji(next)) {
+				break
+			}
+			end, prev = end+nextSize, next
+		}
+		for end < len(s) && s[end] == ' ' {
+			end++
+		}
+		// "💡 Tips:" becomes "Tips:" rather than "Tip: Tips:"
+		if word := words[r]; word != "" && !strings.HasPrefix(s[end:], strings.TrimSuffix(word, ":")) {
+			out.WriteString(word)
+			if end < len(s) && s[end] != '\n' {
+				out.WriteByte(' ')
+			}
+		}
+		i = end
+	}
+	return out.String()
+}
+
+// isEmoji reports whether r is a pictograph or symbol drawn as an emoji.
+// Braille patterns are included for spinners.
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, // pictographs, emoticons, transport, flags
+		r >= 0x2600 && r <= 0x27BF, // symbols and dingbats
+		r >= 0x2B00 && r <= 0x2BFF, // stars and arrows
+		r >= 0x2800 && r <= 0x28FF, // braille spinner frames
+		r >= 0x2300 && r <= 0x23FF, // watches and media controls
+		r == 0x2139, r == 0x2194, r == 0x2195, r == 0x21A9, r == 0x21AA,
+		r == 0x203C, r == 0x2049, r == 0x00A9, r == 0x00AE:
+		return true
+	}
+	return false
+}
+
+// Writer writes the output of another writer as plain text
+type Writer struct {
+	mu  sync.Mutex
+	out io.Writer
+	// pending holds an escape sequence or character cut off at the end of
+	// the last write
+	pending []byte
+}
+
+// NewWriter returns a Writer writing to out
+func NewWriter(out io.Writer) *Writer {
+	return &Writer{out: out}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data := append(w.pending, p...)
+	cut := incompleteTail(data)
+	w.pending = append([]byte(nil), data[cut:]...)
+	if cut > 0 {
+		if _, err := io.WriteString(w.out, Text(string(data[:cut]))); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush writes what's held back from the last write
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(w.out, Text(string(w.pending)))
+	w.pending = nil
+	return err
+}
+
+// incompleteTail returns where an unfinished escape sequence, character or
+// emoji sequence starts at the end of data, or len(data)
+func incompleteTail(data []byte) int {
+	if i := bytes.LastIndexByte(data, 0x1b); i >= 0 && len(data)-i < 64 && escape.FindIndex(data[i:]) == nil {
+		return i
+	}
+	// A character cut in half
+	start := len(data) - 1
+	for start > 0 && len(data)-start < utf8.UTFMax && !utf8.RuneStart(data[start]) {
+		start--
+	}
+	end := len(data)
+	if start >= 0 && !utf8.FullRune(data[start:]) {
+		end = start
+	}
+	// An emoji may still get its variation selector, and the spaces after
+	// it are dropped along with it
+	for cut := end; cut > 0; {
+		r, size := utf8.DecodeLastRune(data[:cut])
+		switch {
+		case r == ' ' || r == variationSelector || r == zeroWidthJoiner || r == keycap:
+			cut -= size
+			continue
+		case isEmoji(r):
+			return cut - size
+		}
+		break
+	}
+	return end
+}
+
+var (
+	redirectMu sync.Mutex
+	terminal   = struct{ stdout, stderr *os.File }{os.Stdout, os.Stderr}
+	restore    func()
+)
+
+// Redirect sends everything written to os.Stdout and os.Stderr, by this
+// process and by the commands it runs, through a Writer. Call Restore
+// before exiting so nothing is lost.
+func Redirect() error {
+	redirectMu.Lock()
+	defer redirectMu.Unlock()
+	if restore != nil {
+		return nil
+	}
+	stdout, stdoutDone, err := pipe(terminal.stdout)
+	if err != nil {
+		return err
+	}
+	stderr, stderrDone, err := pipe(terminal.stderr)
+	if err != nil {
+		stdout.Close()
+		<-stdoutDone
+		return err
+	}
+	os.Stdout, os.Stderr = stdout, stderr
+	restore = func() {
+		os.Stdout, os.Stderr = terminal.stdout, terminal.stderr
+		stdout.Close()
+		stderr.Close()
+		<-stdoutDone
+		<-stderrDone
+	}
+	return nil
+}
+
+// pipe returns a file whose output is copied to out as plain text, and a
+// channel closed once everything is copied after the file is closed
+func pipe(out *os.File) (*os.File, chan struct{}, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		writer := NewWriter(out)
+		io.Copy(writer, r)
+		writer.Flush()
+		r.Close()
+	}()
+	return w, done, nil
+}
+
+// Restore undoes Redirect, writing out what's left
+func Restore() {
+	redirectMu.Lock()
+	defer redirectMu.Unlock()
+	if restore != nil {
+		restore()
+		restore = nil
+	}
+}
+
+// Terminal returns the standard output and error the process started with,
+// for programs such as editors that need the terminal itself
+func Terminal() (stdout, stderr *os.File) {
+	return terminal.stdout, terminal.stderr
+}
@@ -0,0 +1,45 @@
+package plain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"✅ Already up to date with origin/main\n", "OK: Already up to date with origin/main\n"},
+		{"⚠️  Proceeding with uncommitted changes\n", "Warning: Proceeding with uncommitted changes\n"},
+		{"ℹ️  Nothing to do", "Note: Nothing to do"},
+		{"📦 Stashing local changes...", "Stashing local changes..."},
+		{"💡 Tips:", "Tips:"},
+		{"1️⃣  GitHub", "1  GitHub"},
+		{"👩‍💻 Author", "Author"},
+		{"\x1b[31mred\x1b[0m text", "red text"},
+		{"Fetching... ⠋ 3s\r\x1b[KFetching... done\n", "Fetching... 3s\nFetching... done\n"},
+		{"██████░░░░ 60%", "######---- 60%"},
+		{"┌──┐\n│ab│\n└──┘", "+--+\n|ab|\n+--+"},
+		{"main → feature", "main -> feature"},
+		{"plain ascii stays", "plain ascii stays"},
+		{"¿Resolverlos ahora? [S/n]: ", "¿Resolverlos ahora? [S/n]: "},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Text(tt.in), tt.in)
+	}
+}
+
+func TestWriterSplitWrites(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	message := []byte("⚠️  careful \x1b[1mbold\x1b[0m ✅ done\n")
+	// Write one byte at a time, cutting characters and escapes in half
+	for i := range message {
+		_, err := w.Write(message[i : i+1])
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, w.Flush())
+	assert.Equal(t, "Warning: careful bold OK: done\n", out.String())
+}
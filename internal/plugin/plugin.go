@@ -0,0 +1,135 @@
+// Package plugin discovers githelper-<name> executables that extend
+// githelper with custom subcommands.
+package plugin
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Prefix is the executable name prefix of plugins
+const Prefix = "githelper-"
+
+// Manifest is the optional <executable>.yaml describing a plugin
+type Manifest struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Usage       string `yaml:"usage"`
+	// NeedsToken passes the GitHub token to the plugin
+	NeedsToken bool `yaml:"needs_token"`
+}
+
+// Plugin is an executable providing the subcommand Name
+type Plugin struct {
+	Name     string
+	Path     string
+	Manifest Manifest
+}
+
+// Dir returns the directory for plugins that are not on PATH
+func Dir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".githelper", "plugins")
+}
+
+// Find looks up the plugin providing the subcommand name, first in the
+// plugin directory and then on PATH
+func Find(name string) (*Plugin, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, false
+	}
+
+	if dir := Dir(); dir != "" {
+		if path, ok := executableIn(dir, Prefix+name); ok {
+			return load(name, path), true
+		}
+	}
+	if path, err := exec.LookPath(Prefix + name); err == nil {
+		return load(name, path), true
+	}
+	return nil, false
+}
+
+// List returns all plugins in the plugin directory and on PATH. When the
+// same name exists twice, the first one found wins, as with Find.
+func List() []Plugin {
+	dirs := []string{Dir()}
+	dirs = append(dirs, filepath.SplitList(os.Getenv("PATH"))...)
+
+	seen := make(map[string]bool)
+	var plugins []Plugin
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || seen[name] {
+				continue
+			}
+			if path, ok := executableIn(dir, entry.Name()); ok {
+				seen[name] = true
+				plugins = append(plugins, *load(name, path))
+			}
+		}
+	}
+
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// pluginName returns the subcommand name of a plugin file name
+func pluginName(file string) (string, bool) {
+	name, ok := strings.CutPrefix(file, Prefix)
+	if !ok || strings.HasSuffix(name, ".yaml") {
+		return "", false
+	}
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name, name != ""
+}
+
+func executableIn(dir, file string) (string, bool) {
+	path := filepath.Join(dir, file)
+	if runtime.GOOS == "windows" && filepath.Ext(path) == "" {
+		path += ".exe"
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+		return "", false
+	}
+	return path, true
+}
+
+// load reads the manifest stored next to the executable, if there is one
+func load(name, path string) *Plugin {
+	plugin := &Plugin{Name: name, Path: path}
+	manifest := strings.TrimSuffix(path, filepath.Ext(path)) + ".yaml"
+	if runtime.GOOS != "windows" {
+		manifest = path + ".yaml"
+	}
+	if data, err := os.ReadFile(manifest); err == nil {
+		yaml.Unmarshal(data, &plugin.Manifest)
+	}
+	if plugin.Manifest.Name == "" {
+		plugin.Manifest.Name = name
+	}
+	return plugin
+}
@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindAndList(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses executable bits")
+	}
+
+	home := t.TempDir()
+	bin := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", bin)
+
+	pluginDir := filepath.Join(home, ".githelper", "plugins")
+	assert.NoError(t, os.MkdirAll(pluginDir, 0755))
+
+	script := []byte("#!/bin/sh\n")
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "githelper-deploy"), script, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "githelper-deploy.yaml"), []byte("description: Deploy it\nneeds_token: true\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(bin, "githelper-notes"), script, 0644)) // not executable
+	assert.NoError(t, os.WriteFile(filepath.Join(pluginDir, "githelper-lint"), script, 0755))
+
+	found, ok := Find("deploy")
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(bin, "githelper-deploy"), found.Path)
+	assert.Equal(t, "Deploy it", found.Manifest.Description)
+	assert.True(t, found.Manifest.NeedsToken)
+
+	found, ok = Find("lint")
+	assert.True(t, ok)
+	assert.Equal(t, "lint", found.Manifest.Name)
+
+	_, ok = Find("notes")
+	assert.False(t, ok)
+	_, ok = Find("../deploy")
+	assert.False(t, ok)
+
+	var names []string
+	for _, p := range List() {
+		names = append(names, p.Name)
+	}
+	assert.Equal(t, []string{"deploy", "lint"}, names)
+}
@@ -0,0 +1,119 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxMessages is how many lines of git's other messages are kept for errors
+const maxMessages = 20
+
+var (
+	// Receiving objects:  45% (450/1000), 1.20 MiB | 1.00 MiB/s
+	// remote: Counting objects: 1234, done.
+	gitProgressLine = regexp.MustCompile(`^(?:remote: )?([A-Z][A-Za-z ]*?):\s+(?:\d+% \()?(\d+)(?:/(\d+))?`)
+	// Rewrite 1a2b3c4... (3/10) (0 seconds passed, remaining 0 predicted)
+	filterBranchLine = regexp.MustCompile(`^Rewrite [0-9a-f]+ \((\d+)/(\d+)\)`)
+)
+
+// Git runs a git command, showing its progress under title. The progress git
+// writes to stderr, which needs --progress for clone, fetch and push when
+// stderr isn't a terminal, drives the indicator; its other messages are
+// only shown in the error when the command fails.
+func Git(out io.Writer, title string, cmd *exec.Cmd) error {
+	return Run(out, title, func(stderr io.Writer) error {
+		cmd.Stderr = stderr
+		return cmd.Run()
+	})
+}
+
+// Run is Git for a command started by run, which must send the command's
+// stderr to the writer it is given
+func Run(out io.Writer, title string, run func(stderr io.Writer) error) error {
+	ind := Start(out, title)
+	stderr := &gitStderr{ind: ind}
+	err := run(stderr)
+	stderr.flush()
+	if err != nil {
+		ind.Fail()
+		if messages := stderr.messages(); messages != "" {
+			return fmt.Errorf("%w: %s", err, messages)
+		}
+		return err
+	}
+	ind.Done()
+	return nil
+}
+
+// ParseLine reads a progress line of git or filter-branch, returning the
+// phase and how far it is. total is 0 when git only counts.
+func ParseLine(line string) (phase string, current, total int64, ok bool) {
+	if match := filterBranchLine.FindStringSubmatch(line); match != nil {
+		current, _ = strconv.ParseInt(match[1], 10, 64)
+		total, _ = strconv.ParseInt(match[2], 10, 64)
+		return "Rewriting commits", current, total, true
+	}
+	if match := gitProgressLine.FindStringSubmatch(line); match != nil {
+		current, _ = strconv.ParseInt(match[2], 10, 64)
+		if match[3] != "" {
+			total, _ = strconv.ParseInt(match[3], 10, 64)
+		}
+		return match[1], current, total, true
+	}
+	return "", 0, 0, false
+}
+
+// gitStderr turns git's stderr into progress updates, keeping the other lines
+type gitStderr struct {
+	ind *Indicator
+
+	mu      sync.Mutex
+	partial string
+	kept    []string
+}
+
+func (w *gitStderr) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// Progress lines end with \r to be redrawn in place
+	text := w.partial + strings.ReplaceAll(string(p), "\r", "\n")
+	lines := strings.Split(text, "\n")
+	w.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		w.line(line)
+	}
+	return len(p), nil
+}
+
+func (w *gitStderr) line(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	if phase, current, total, ok := ParseLine(line); ok {
+		w.ind.Update(phase, current, total)
+		return
+	}
+	w.kept = append(w.kept, line)
+	if len(w.kept) > maxMessages {
+		w.kept = w.kept[1:]
+	}
+}
+
+func (w *gitStderr) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.line(w.partial)
+	w.partial = ""
+}
+
+func (w *gitStderr) messages() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.Join(w.kept, "\n")
+}
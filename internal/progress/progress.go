@@ -0,0 +1,164 @@
+// Package progress shows what long operations are doing: a spinner, or a
+// progress bar once the total is known, with the elapsed time. On a terminal
+// the line is redrawn in place; elsewhere, such as in CI logs, only the start
+// and the end of each operation are printed.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// refreshInterval is how often the line is redrawn on a terminal
+const refreshInterval = 100 * time.Millisecond
+
+// barWidth is the number of cells of a progress bar
+const barWidth = 20
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Indicator is the progress line of one operation
+type Indicator struct {
+	out     io.Writer
+	tty     bool
+	title   string
+	started time.Time
+
+	mu      sync.Mutex
+	phase   string
+	current int64
+	total   int64
+	frame   int
+
+	stop    chan struct{}
+	stopped sync.WaitGroup
+	once    sync.Once
+}
+
+// Start shows title until Done or Fail is called, animated when out is a
+// terminal
+func Start(out io.Writer, title string) *Indicator {
+	ind := &Indicator{
+		out:     out,
+		tty:     IsTerminal(out),
+		title:   title,
+		started: time.Now(),
+		stop:    make(chan struct{}),
+	}
+	if !ind.tty {
+		fmt.Fprintf(out, "%s...", title)
+		return ind
+	}
+	ind.stopped.Add(1)
+	go ind.animate()
+	return ind
+}
+
+// IsTerminal reports whether w writes to a terminal
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	// /dev/null is a character device too
+	if null, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, null) {
+		return false
+	}
+	return os.Getenv("TERM") != "dumb"
+}
+
+// Update sets what the operation is doing, and how far it is when total is
+// positive
+func (ind *Indicator) Update(phase string, current, total int64) {
+	ind.mu.Lock()
+	defer ind.mu.Unlock()
+	ind.phase, ind.current, ind.total = phase, current, total
+}
+
+// Done ends the operation, showing the time it took
+func (ind *Indicator) Done() {
+	ind.finish(fmt.Sprintf("done in %s", ind.Elapsed()))
+}
+
+// Fail ends the operation as failed
+func (ind *Indicator) Fail() {
+	ind.finish(fmt.Sprintf("failed after %s", ind.Elapsed()))
+}
+
+// Elapsed returns the time since the operation started, rounded for display
+func (ind *Indicator) Elapsed() time.Duration {
+	elapsed := time.Since(ind.started)
+	if elapsed < time.Second {
+		return elapsed.Round(time.Millisecond)
+	}
+	return elapsed.Round(time.Second)
+}
+
+func (ind *Indicator) finish(result string) {
+	ind.once.Do(func() {
+		if !ind.tty {
+			fmt.Fprintf(ind.out, " %s\n", result)
+			return
+		}
+		close(ind.stop)
+		ind.stopped.Wait()
+		fmt.Fprintf(ind.out, "\r%s... %s\x1b[K\n", ind.title, result)
+	})
+}
+
+func (ind *Indicator) animate() {
+	defer ind.stopped.Done()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		ind.draw()
+		select {
+		case <-ind.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (ind *Indicator) draw() {
+	ind.mu.Lock()
+	line := ind.line()
+	ind.frame++
+	ind.mu.Unlock()
+	fmt.Fprintf(ind.out, "\r%s\x1b[K", line)
+}
+
+// line renders the progress line; the caller holds the lock
+func (ind *Indicator) line() string {
+	var line strings.Builder
+	fmt.Fprintf(&line, "%s... %s", ind.title, spinnerFrames[ind.frame%len(spinnerFrames)])
+	if ind.phase != "" {
+		fmt.Fprintf(&line, " %s", ind.phase)
+	}
+	if ind.total > 0 {
+		fmt.Fprintf(&line, " %s %d%% (%d/%d)", Bar(ind.current, ind.total), percent(ind.current, ind.total), ind.current, ind.total)
+	}
+	fmt.Fprintf(&line, " %s", time.Since(ind.started).Round(time.Second))
+	return line.String()
+}
+
+// Bar draws a progress bar for current out of total
+func Bar(current, total int64) string {
+	filled := 0
+	if total > 0 {
+		filled = int(min(current, total) * barWidth / total)
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+}
+
+func percent(current, total int64) int64 {
+	return min(current, total) * 100 / total
+}
@@ -0,0 +1,72 @@
+package progress
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		phase   string
+		current int64
+		total   int64
+		ok      bool
+	}{
+		{"Receiving objects:  45% (450/1000), 1.20 MiB | 1.00 MiB/s", "Receiving objects", 450, 1000, true},
+		{"remote: Counting objects: 1234, done.", "Counting objects", 1234, 0, true},
+		{"Writing objects: 100% (3/3), 215 bytes | 215.00 KiB/s, done.", "Writing objects", 3, 3, true},
+		{"Rewrite 1a2b3c4d5e6f (3/10) (0 seconds passed, remaining 0 predicted)", "Rewriting commits", 3, 10, true},
+		{"Cloning into bare repository 'repo.git'...", "", 0, 0, false},
+		{"fatal: repository 'x' does not exist", "", 0, 0, false},
+	}
+	for _, tt := range tests {
+		phase, current, total, ok := ParseLine(tt.line)
+		assert.Equal(t, tt.ok, ok, tt.line)
+		assert.Equal(t, tt.phase, phase, tt.line)
+		assert.Equal(t, tt.current, current, tt.line)
+		assert.Equal(t, tt.total, total, tt.line)
+	}
+}
+
+func TestBar(t *testing.T) {
+	assert.Equal(t, "░░░░░░░░░░░░░░░░░░░░", Bar(0, 10))
+	assert.Equal(t, "██████████░░░░░░░░░░", Bar(5, 10))
+	assert.Equal(t, "████████████████████", Bar(12, 10))
+}
+
+func TestIndicatorWithoutTerminal(t *testing.T) {
+	var out bytes.Buffer
+	ind := Start(&out, "📥 Cloning")
+	ind.Update("Receiving objects", 5, 10)
+	ind.Done()
+	ind.Fail()
+	assert.Regexp(t, `^📥 Cloning\.\.\. done in \d+m?s\n$`, out.String())
+}
+
+func TestGit(t *testing.T) {
+	dir := t.TempDir()
+	init := exec.Command("git", "init", "-q", dir)
+	require.NoError(t, init.Run())
+
+	var out bytes.Buffer
+	clone := exec.Command("git", "clone", "--progress", dir, filepath.Join(dir, "clone"))
+	require.NoError(t, Git(&out, "Cloning", clone))
+	assert.Contains(t, out.String(), "Cloning... done in")
+
+	// The messages git printed explain the failure
+	out.Reset()
+	missing := exec.Command("git", "clone", "--progress", filepath.Join(dir, "missing"), filepath.Join(dir, "other"))
+	err := Git(&out, "Cloning", missing)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+	assert.Contains(t, out.String(), "Cloning... failed after")
+	_, statErr := os.Stat(filepath.Join(dir, "other"))
+	assert.True(t, os.IsNotExist(statErr))
+}
@@ -0,0 +1,245 @@
+// Package runner runs the external commands, git mostly, that change the
+// repository. Commands go through a Runner so that --dry-run and tests can
+// record what would run instead of running it.
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/EndlessUphill/git-helper/internal/git"
+)
+
+// Command is a command to run
+type Command struct {
+	Name string
+	Args []string
+	// Dir is the working directory; "" is the current one
+	Dir string
+	// Env holds variables set on top of the environment, as KEY=value
+	Env    []string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Git returns a git command
+func Git(args ...string) Command {
+	return Command{Name: "git", Args: args}
+}
+
+// WithEnv returns the command with the variables, as KEY=value, set
+func (c Command) WithEnv(env ...string) Command {
+	c.Env = append(append([]string(nil), c.Env...), env...)
+	return c
+}
+
+// WithOutput returns the command writing its output to stdout and stderr
+func (c Command) WithOutput(stdout, stderr io.Writer) Command {
+	c.Stdout, c.Stderr = stdout, stderr
+	return c
+}
+
+// String returns the command line, quoted for a shell where needed, e.g.
+// git commit -m 'Add cache'
+func (c Command) String() string {
+	words := []string{quote(c.Name)}
+	for _, arg := range c.Args {
+		words = append(words, quote(arg))
+	}
+	return strings.Join(words, " ")
+}
+
+func quote(word string) string {
+	// ~ only expands at the start of a word, as in HEAD~2
+	if word != "" && !strings.HasPrefix(word, "~") && !strings.ContainsAny(word, " \t\n'\"\\$`!*?[]{}()<>|&;#") {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// Runner runs commands
+type Runner interface {
+	// Run runs a command to completion
+	Run(ctx context.Context, cmd Command) error
+	// Output runs a command and returns its standard output
+	Output(ctx context.Context, cmd Command) ([]byte, error)
+}
+
+// Exec runs commands for real. git is interrupted rather than killed when
+// ctx is cancelled, like git.Command.
+type Exec struct{}
+
+func (Exec) command(ctx context.Context, c Command) *exec.Cmd {
+	var cmd *exec.Cmd
+	if c.Name == "git" {
+		cmd = git.Command(ctx, c.Args...)
+	} else {
+		cmd = exec.CommandContext(ctx, c.Name, c.Args...)
+	}
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Stdin = c.Stdin
+	cmd.Stdout = c.Stdout
+	cmd.Stderr = c.Stderr
+	return cmd
+}
+
+// Run runs a command
+func (e Exec) Run(ctx context.Context, c Command) error {
+	return e.command(ctx, c).Run()
+}
+
+// Output runs a command and returns its standard output. Its standard error
+// is kept in the *exec.ExitError, unless the command writes it elsewhere.
+func (e Exec) Output(ctx context.Context, c Command) ([]byte, error) {
+	c.Stdout = nil
+	return e.command(ctx, c).Output()
+}
+
+// Result is what a recorded command returns
+type Result struct {
+	Output string
+	Err    error
+}
+
+// Recorder records commands instead of running them. Tests use it to check
+// the exact git invocations of a command, and --dry-run to show them.
+type Recorder struct {
+	// Passthrough selects the commands run through Next rather than
+	// recorded, e.g. ReadOnly so a dry run still sees the repository
+	Passthrough func(Command) bool
+	Next        Runner
+	// Out, when set, gets a line for every recorded command
+	Out io.Writer
+
+	mu       sync.Mutex
+	commands []Command
+	results  map[string]Result
+}
+
+// NewDryRun returns a recorder that runs read-only git commands and prints
+// the others to out instead of running them
+func NewDryRun(out io.Writer) *Recorder {
+	return &Recorder{Passthrough: ReadOnly, Next: Exec{}, Out: out}
+}
+
+// Stub makes a recorded command, given as its String, return output and err.
+// Commands without a stub succeed without output.
+func (r *Recorder) Stub(command, output string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.results == nil {
+		r.results = map[string]Result{}
+	}
+	r.results[command] = Result{Output: output, Err: err}
+}
+
+// Commands returns the recorded commands, in order
+func (r *Recorder) Commands() []Command {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Command(nil), r.commands...)
+}
+
+// Invocations returns the recorded command lines, in order
+func (r *Recorder) Invocations() []string {
+	var lines []string
+	for _, c := range r.Commands() {
+		lines = append(lines, c.String())
+	}
+	return lines
+}
+
+func (r *Recorder) record(c Command) Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = append(r.commands, c)
+	if r.Out != nil {
+		fmt.Fprintf(r.Out, "Would run: %s\n", c)
+	}
+	return r.results[c.String()]
+}
+
+// Run records a command, or runs it through Next when it passes through
+func (r *Recorder) Run(ctx context.Context, c Command) error {
+	if r.Passthrough != nil && r.Passthrough(c) {
+		return r.Next.Run(ctx, c)
+	}
+	result := r.record(c)
+	if c.Stdout != nil && result.Output != "" {
+		io.Copy(c.Stdout, strings.NewReader(result.Output))
+	}
+	return result.Err
+}
+
+// Output records a command, or runs it through Next when it passes through
+func (r *Recorder) Output(ctx context.Context, c Command) ([]byte, error) {
+	if r.Passthrough != nil && r.Passthrough(c) {
+		return r.Next.Output(ctx, c)
+	}
+	result := r.record(c)
+	return []byte(result.Output), result.Err
+}
+
+// readOnlyGit are the git subcommands that never change the repository
+var readOnlyGit = map[string]bool{
+	"blame": true, "cat-file": true, "describe": true, "diff": true,
+	"for-each-ref": true, "grep": true, "log": true, "ls-files": true,
+	"ls-remote": true, "ls-tree": true, "merge-base": true,
+	"rev-list": true, "rev-parse": true, "shortlog": true, "show": true,
+	"show-ref": true, "status": true, "var": true,
+}
+
+// ReadOnly reports whether a command only reads the repository, so a dry
+// run may run it
+func ReadOnly(c Command) bool {
+	if c.Name != "git" || len(c.Args) == 0 {
+		return false
+	}
+	sub, args := c.Args[0], c.Args[1:]
+	if readOnlyGit[sub] {
+		return true
+	}
+	switch sub {
+	case "branch":
+		return hasAny(args, "--list", "-l", "--merged", "--no-merged", "--contains", "--show-current")
+	case "config":
+		return hasAny(args, "--get", "--get-all", "--get-regexp", "--list", "-l")
+	case "reflog":
+		return !hasAny(args, "expire", "delete")
+	case "remote":
+		return len(args) == 0 || hasAny(args, "get-url", "-v", "show")
+	case "symbolic-ref":
+		return len(args) > 0 && !hasAny(args, "--delete", "-d") && len(withoutFlags(args)) == 1
+	}
+	return false
+}
+
+func hasAny(args []string, flags ...string) bool {
+	for _, arg := range args {
+		for _, flag := range flags {
+			if arg == flag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func withoutFlags(args []string) []string {
+	var rest []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			rest = append(rest, arg)
+		}
+	}
+	return rest
+}
@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandString(t *testing.T) {
+	assert.Equal(t, "git reset --soft HEAD~2", Git("reset", "--soft", "HEAD~2").String())
+	assert.Equal(t, `git commit -m 'Add cache' -m 'it'\''s fast'`, Git("commit", "-m", "Add cache", "-m", "it's fast").String())
+	assert.Equal(t, "git branch -d ''", Git("branch", "-d", "").String())
+}
+
+func TestReadOnly(t *testing.T) {
+	for _, args := range [][]string{
+		{"log", "--oneline"}, {"rev-parse", "HEAD"}, {"branch", "--merged", "main"},
+		{"config", "--get", "user.name"}, {"remote", "get-url", "origin"},
+		{"symbolic-ref", "-q", "HEAD"}, {"reflog"},
+	} {
+		assert.True(t, ReadOnly(Git(args...)), "git %v", args)
+	}
+	for _, args := range [][]string{
+		{"reset", "--hard"}, {"branch", "-d", "old"}, {"config", "user.name", "Me"},
+		{"remote", "add", "fork", "url"}, {"symbolic-ref", "HEAD", "refs/heads/main"},
+		{"reflog", "expire", "--all"}, {"fetch", "-p"}, {},
+	} {
+		assert.False(t, ReadOnly(Git(args...)), "git %v", args)
+	}
+	assert.False(t, ReadOnly(Command{Name: "rm", Args: []string{"-rf", "log"}}))
+}
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	next := &Recorder{}
+	next.Stub("git rev-parse HEAD", "abc\n", nil)
+	var out bytes.Buffer
+	recorder := &Recorder{Passthrough: ReadOnly, Next: next, Out: &out}
+	failed := errors.New("rejected")
+	recorder.Stub("git push origin HEAD", "", failed)
+
+	head, err := recorder.Output(ctx, Git("rev-parse", "HEAD"))
+	require.NoError(t, err)
+	assert.Equal(t, "abc\n", string(head))
+	require.NoError(t, recorder.Run(ctx, Git("reset", "--soft", "HEAD~1")))
+	assert.ErrorIs(t, recorder.Run(ctx, Git("push", "origin", "HEAD")), failed)
+
+	assert.Equal(t, []string{"git reset --soft HEAD~1", "git push origin HEAD"}, recorder.Invocations())
+	assert.Equal(t, []string{"git rev-parse HEAD"}, next.Invocations(), "read-only commands pass through")
+	assert.Equal(t, "Would run: git reset --soft HEAD~1\nWould run: git push origin HEAD\n", out.String())
+}
+
+func TestExec(t *testing.T) {
+	ctx := context.Background()
+	output, err := Exec{}.Output(ctx, Git("--version"))
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "git version")
+
+	err = Exec{}.Run(ctx, Git("no-such-command"))
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+}
+
+func TestExecEnv(t *testing.T) {
+	output, err := Exec{}.Output(context.Background(), Git("var", "GIT_AUTHOR_IDENT").WithEnv("GIT_AUTHOR_NAME=Runner Test", "GIT_AUTHOR_EMAIL=runner@example.com"))
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "Runner Test <runner@example.com>")
+}
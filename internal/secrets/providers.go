@@ -0,0 +1,155 @@
+package secrets
+
+import (
+	"regexp"
+	"slices"
+)
+
+// Provider is a service whose credentials can leak, and how to rotate them
+type Provider struct {
+	ID   string
+	Name string
+	// Steps is the checklist to replace a leaked credential
+	Steps []string
+	// Docs is the provider's guide to rotating or revoking credentials
+	Docs string
+	// Rules are the IDs of the rules that find its credentials
+	Rules []string
+	// Markers find its credential files; the first group, if any, is the
+	// ID of the credential
+	Markers []*regexp.Regexp
+}
+
+// Providers are the services whose leaked credentials are recognized
+var Providers = []Provider{
+	{
+		ID:   "aws",
+		Name: "AWS",
+		Steps: []string{
+			"Create a new access key for the IAM user (aws iam create-access-key)",
+			"Deploy the new key everywhere the old one is used",
+			"Deactivate, then delete the leaked key (aws iam delete-access-key)",
+			"Look for calls made with the leaked key in CloudTrail",
+		},
+		Docs:    "https://docs.aws.amazon.com/IAM/latest/UserGuide/id_credentials_access-keys.html",
+		Rules:   []string{"aws-access-key"},
+		Markers: []*regexp.Regexp{regexp.MustCompile(`(?i)aws_secret_access_key`)},
+	},
+	{
+		ID:   "gcp",
+		Name: "Google Cloud",
+		Steps: []string{
+			"Create a new service account key or API key",
+			"Deploy the new key everywhere the old one is used",
+			"Delete the leaked key in the console or with 'gcloud iam service-accounts keys delete'",
+			"Look for requests made with the leaked key in Cloud Audit Logs",
+		},
+		Docs:  "https://cloud.google.com/iam/docs/keys-create-delete",
+		Rules: []string{"google-api-key"},
+		Markers: []*regexp.Regexp{
+			regexp.MustCompile(`"private_key_id"\s*:\s*"([0-9a-f]{16,})"`),
+			regexp.MustCompile(`"type"\s*:\s*"service_account"`),
+		},
+	},
+	{
+		ID:   "github",
+		Name: "GitHub",
+		Steps: []string{
+			"Revoke the leaked token in Settings > Developer settings",
+			"Create a new token with the fewest scopes that work",
+			"Update the tools and CI secrets that use it",
+			"Review the security log of the account for unexpected activity",
+		},
+		Docs:  "https://docs.github.com/en/authentication/keeping-your-account-and-data-secure/token-expiration-and-revocation",
+		Rules: []string{"github-token"},
+	},
+	{
+		ID:   "slack",
+		Name: "Slack",
+		Steps: []string{
+			"Regenerate the token in the settings of the Slack app",
+			"Update the integrations that use it",
+		},
+		Docs:  "https://api.slack.com/authentication/rotation",
+		Rules: []string{"slack-token"},
+	},
+	{
+		ID:   "stripe",
+		Name: "Stripe",
+		Steps: []string{
+			"Roll the key in the Stripe dashboard (Developers > API keys)",
+			"Deploy the new key everywhere the old one is used",
+			"Review the request logs for calls you don't recognize",
+		},
+		Docs:  "https://docs.stripe.com/keys",
+		Rules: []string{"stripe-key"},
+	},
+	{
+		ID:   "openai",
+		Name: "OpenAI",
+		Steps: []string{
+			"Delete the leaked key on the API keys page",
+			"Create a new key and update the tools that use it",
+			"Check the usage page for requests you didn't make",
+		},
+		Docs:  "https://platform.openai.com/api-keys",
+		Rules: []string{"openai-key"},
+	},
+	{
+		ID:   "private-key",
+		Name: "Private key",
+		Steps: []string{
+			"Generate a new key pair",
+			"Replace the public key wherever it is trusted: servers, deploy keys, certificates",
+			"Remove the old public key, or revoke its certificate",
+		},
+		Rules: []string{"private-key"},
+	},
+}
+
+// Exposure is a provider whose credentials were found
+type Exposure struct {
+	Provider Provider
+	// Credentials are the credentials found, redacted
+	Credentials []string
+}
+
+// DetectProviders returns the providers whose credentials appear in text, in
+// the order of Providers
+func DetectProviders(text string) []Exposure {
+	var exposures []Exposure
+	for _, provider := range Providers {
+		exposure := Exposure{Provider: provider}
+		found := false
+		seen := map[string]bool{}
+		add := func(credential string) {
+			if credential != "" && !seen[credential] {
+				seen[credential] = true
+				exposure.Credentials = append(exposure.Credentials, Redact(credential))
+			}
+		}
+		for _, rule := range Rules {
+			if !slices.Contains(provider.Rules, rule.ID) {
+				continue
+			}
+			for _, match := range rule.Pattern.FindAllString(text, -1) {
+				found = true
+				if rule.ID != "private-key" {
+					add(match)
+				}
+			}
+		}
+		for _, marker := range provider.Markers {
+			for _, match := range marker.FindAllStringSubmatch(text, -1) {
+				found = true
+				if len(match) > 1 {
+					add(match[1])
+				}
+			}
+		}
+		if found {
+			exposures = append(exposures, exposure)
+		}
+	}
+	return exposures
+}
@@ -0,0 +1,196 @@
+// Package secrets detects credentials such as API keys and private keys in
+// text and in git diffs.
+package secrets
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AllowMarker on a line suppresses findings on it, for test fixtures and
+// known false positives
+const AllowMarker = "githelper:allow-secret"
+
+// Rule is a kind of secret and the pattern that finds it
+type Rule struct {
+	ID          string
+	Description string
+	Pattern     *regexp.Regexp
+}
+
+// Rules are the secrets that are detected
+var Rules = []Rule{
+	{"aws-access-key", "AWS access key ID", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github-token", "GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"private-key", "private key", regexp.MustCompile(`-----BEGIN ((RSA|DSA|EC|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)},
+	{"slack-token", "Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{"google-api-key", "Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"stripe-key", "Stripe live key", regexp.MustCompile(`\b[sr]k_live_[0-9A-Za-z]{24,}\b`)},
+	{"openai-key", "OpenAI API key", regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9_-]{32,}\b`)},
+	{"generic-secret", "hardcoded password or secret", regexp.MustCompile(`(?i)\b(password|passwd|secret|api[_-]?key|access[_-]?token)["']?\s*[:=]\s*["'][^"'\s]{8,}["']`)},
+}
+
+// Finding is a secret found in a file
+type Finding struct {
+	Rule   Rule
+	Commit string
+	File   string
+	Line   int
+	// Match is the secret with all but its first characters masked
+	Match string
+}
+
+// Location formats the file and line of the finding
+func (f Finding) Location() string {
+	if f.Line == 0 {
+		return f.File
+	}
+	return f.File + ":" + strconv.Itoa(f.Line)
+}
+
+// ScanLine returns the secrets found in a line of text
+func ScanLine(line string) []Finding {
+	if strings.Contains(line, AllowMarker) {
+		return nil
+	}
+	var findings []Finding
+	for _, rule := range Rules {
+		if match := rule.Pattern.FindString(line); match != "" {
+			findings = append(findings, Finding{Rule: rule, Match: Redact(match)})
+		}
+	}
+	return findings
+}
+
+// KnownSecret is the rule of findings of a given secret value, see ScanDiffFor
+var KnownSecret = Rule{ID: "known-secret", Description: "copy of a known secret"}
+
+// assignment finds values assigned to keys that name a secret, such as
+// DB_PASSWORD=... in .env files or api_key: ... in YAML
+var assignment = regexp.MustCompile(`(?i)^\s*(?:export\s+)?["']?[\w.-]*(?:pass|secret|token|key|credential|auth)[\w.-]*["']?\s*[:=]\s*["']?([^"'\s#,]{8,})`)
+
+// Values returns the secrets in text unredacted, to look for other copies of
+// them: what the rules match, the values assigned to keys that name a secret,
+// and the first line of the body of private keys
+func Values(text string) []string {
+	var values []string
+	seen := map[string]bool{}
+	add := func(value string) {
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+
+	inKey := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.Contains(line, AllowMarker) {
+			continue
+		}
+		if inKey {
+			// The header is the same in every key; the body is what is secret
+			if body := strings.TrimSpace(line); body != "" && !strings.Contains(body, ":") {
+				add(body)
+				inKey = false
+			}
+			continue
+		}
+		for _, rule := range Rules {
+			switch rule.ID {
+			case "private-key":
+				inKey = inKey || rule.Pattern.MatchString(line)
+			case "generic-secret":
+				// The value is found by assignment below, without the key
+			default:
+				for _, match := range rule.Pattern.FindAllString(line, -1) {
+					add(match)
+				}
+			}
+		}
+		if match := assignment.FindStringSubmatch(line); match != nil {
+			add(match[1])
+		}
+	}
+	return values
+}
+
+// ScanDiff returns the secrets in the added lines of a patch, such as the
+// output of 'git log -p --format="commit %H"' or 'git diff'
+func ScanDiff(r io.Reader) ([]Finding, error) {
+	return scanDiff(r, ScanLine)
+}
+
+// ScanDiffFor returns where the added lines of a patch contain one of values,
+// such as the secrets of a file that was removed
+func ScanDiffFor(r io.Reader, values []string) ([]Finding, error) {
+	return scanDiff(r, func(line string) []Finding {
+		var findings []Finding
+		for _, value := range values {
+			if strings.Contains(line, value) {
+				findings = append(findings, Finding{Rule: KnownSecret, Match: Redact(value)})
+			}
+		}
+		return findings
+	})
+}
+
+// ScanDiffLines runs scan on the added lines of a patch, to look for things
+// other than secrets, such as leftover debug statements
+func ScanDiffLines(r io.Reader, scan func(line string) []Finding) ([]Finding, error) {
+	return scanDiff(r, scan)
+}
+
+// scanDiff runs scan on the added lines of a patch and sets the commit, file
+// and line of what it finds
+func scanDiff(r io.Reader, scan func(line string) []Finding) ([]Finding, error) {
+	var findings []Finding
+	var commit, file string
+	line := 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "commit "):
+			commit = strings.TrimPrefix(text, "commit ")
+		case strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+		case strings.HasPrefix(text, "@@ "):
+			line = hunkStart(text)
+		case strings.HasPrefix(text, "+"):
+			for _, finding := range scan(text[1:]) {
+				finding.Commit, finding.File, finding.Line = commit, file, line
+				findings = append(findings, finding)
+			}
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	return findings, scanner.Err()
+}
+
+// hunkStart returns the first line number of the new file in a hunk header
+// such as "@@ -1,4 +12,6 @@"
+func hunkStart(header string) int {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0
+	}
+	start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	n, _ := strconv.Atoi(start)
+	return n
+}
+
+// Redact keeps the first four characters of a secret and masks the rest
+func Redact(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", min(len(secret)-4, 16))
+}
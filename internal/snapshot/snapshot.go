@@ -0,0 +1,268 @@
+// Package snapshot records named checkpoints of a working tree (the checked
+// out commit, uncommitted changes and untracked files) that can be restored
+// later.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EndlessUphill/git-helper/internal/archive"
+	"github.com/EndlessUphill/git-helper/internal/git"
+)
+
+// RefPrefix is the namespace of the refs that keep snapshot commits alive
+const RefPrefix = "refs/githelper/snapshots/"
+
+var (
+	ErrNotFound = errors.New("snapshot not found")
+	ErrExists   = errors.New("snapshot already exists")
+)
+
+// Snapshot is a recorded state of the working tree
+type Snapshot struct {
+	Name    string    `json:"name"`
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+	// Branch is the branch checked out, empty when HEAD was detached
+	Branch string `json:"branch,omitempty"`
+	Commit string `json:"commit"`
+	// WorkTree is a stash commit of uncommitted changes, if there were any
+	WorkTree string `json:"worktree,omitempty"`
+	// Untracked is the number of untracked files saved in the tarball
+	Untracked int `json:"untracked,omitempty"`
+}
+
+// Store holds the snapshots of a repository in <git-dir>/githelper/snapshots,
+// one JSON file and one tarball of untracked files per snapshot
+type Store struct {
+	dir  string
+	root string
+}
+
+// Open opens the snapshot store of the repository in the current directory
+func Open(ctx context.Context) (*Store, error) {
+	output, err := git.Command(ctx, "rev-parse", "--git-common-dir", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git directory: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("snapshots need a working tree")
+	}
+	gitDir, err := filepath.Abs(lines[0])
+	if err != nil {
+		return nil, err
+	}
+	return &Store{dir: filepath.Join(gitDir, "githelper", "snapshots"), root: lines[1]}, nil
+}
+
+// ValidateName checks that name can be used as a snapshot name
+func ValidateName(ctx context.Context, name string) error {
+	if name == "" || strings.Contains(name, "/") ||
+		git.Command(ctx, "check-ref-format", RefPrefix+name).Run() != nil {
+		return fmt.Errorf("invalid snapshot name '%s'", name)
+	}
+	return nil
+}
+
+// Create records the checked out commit, uncommitted changes and untracked
+// files (ignored files excluded) under name, without changing the working tree
+func (s *Store) Create(ctx context.Context, name, message string) (*Snapshot, error) {
+	if err := ValidateName(ctx, name); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(s.metadataFile(name)); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrExists, name)
+	}
+
+	snap := &Snapshot{Name: name, Message: message, Time: time.Now()}
+	commit, err := s.output(ctx, "rev-parse", "--verify", "-q", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("nothing to snapshot: the repository has no commits")
+	}
+	snap.Commit = commit
+	if branch, err := s.output(ctx, "symbolic-ref", "-q", "--short", "HEAD"); err == nil {
+		snap.Branch = branch
+	}
+
+	// 'git stash create' makes a commit of the index and working tree
+	// without touching them or the stash list
+	if snap.WorkTree, err = s.output(ctx, "stash", "create"); err != nil {
+		return nil, fmt.Errorf("failed to save uncommitted changes: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	untracked, err := s.untrackedFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(untracked) > 0 {
+		if err := archive.PackFiles(s.root, untracked, s.tarball(name)); err != nil {
+			return nil, fmt.Errorf("failed to save untracked files: %w", err)
+		}
+		snap.Untracked = len(untracked)
+	}
+
+	// The ref stops gc from pruning the commits; the stash commit has the
+	// checked out commit as its parent
+	target := snap.Commit
+	if snap.WorkTree != "" {
+		target = snap.WorkTree
+	}
+	if err := s.git(ctx, "update-ref", RefPrefix+name, target); err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", RefPrefix+name, err)
+	}
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(s.metadataFile(name), append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+// List returns the snapshots, oldest first
+func (s *Store) List() ([]Snapshot, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var snaps []Snapshot
+	for _, file := range files {
+		snap, err := readSnapshot(file)
+		if err != nil {
+			continue
+		}
+		snaps = append(snaps, *snap)
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].Time.Before(snaps[j].Time) })
+	return snaps, nil
+}
+
+// Get returns the snapshot with the given name
+func (s *Store) Get(ctx context.Context, name string) (*Snapshot, error) {
+	if ValidateName(ctx, name) != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	snap, err := readSnapshot(s.metadataFile(name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return snap, err
+}
+
+// Restore discards the current working tree, untracked files included, and
+// brings back the snapshot: its branch is reset to the recorded commit and
+// checked out, then uncommitted changes and untracked files are reapplied
+func (s *Store) Restore(ctx context.Context, snap *Snapshot) error {
+	if err := s.git(ctx, "reset", "-q", "--hard"); err != nil {
+		return fmt.Errorf("failed to reset working tree: %w", err)
+	}
+	if err := s.git(ctx, "clean", "-q", "-fd"); err != nil {
+		return fmt.Errorf("failed to remove untracked files: %w", err)
+	}
+
+	if snap.Branch != "" {
+		if err := s.git(ctx, "checkout", "-q", "-B", snap.Branch, snap.Commit); err != nil {
+			return fmt.Errorf("failed to check out %s: %w", snap.Branch, err)
+		}
+	} else if err := s.git(ctx, "checkout", "-q", "--detach", snap.Commit); err != nil {
+		return fmt.Errorf("failed to check out %s: %w", snap.Commit, err)
+	}
+
+	if snap.WorkTree != "" {
+		if err := s.git(ctx, "stash", "apply", "-q", "--index", snap.WorkTree); err != nil {
+			return fmt.Errorf("failed to restore uncommitted changes (still available as %s): %w", snap.WorkTree, err)
+		}
+	}
+	if snap.Untracked > 0 {
+		if err := archive.Unpack(s.tarball(snap.Name), s.root); err != nil {
+			return fmt.Errorf("failed to restore untracked files: %w", err)
+		}
+	}
+	return nil
+}
+
+// Delete removes a snapshot and its ref
+func (s *Store) Delete(ctx context.Context, name string) error {
+	if _, err := s.Get(ctx, name); err != nil {
+		return err
+	}
+	if err := s.git(ctx, "update-ref", "-d", RefPrefix+name); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", RefPrefix+name, err)
+	}
+	if err := os.Remove(s.tarball(name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete untracked files: %w", err)
+	}
+	if err := os.Remove(s.metadataFile(name)); err != nil {
+		return fmt.Errorf("failed to delete snapshot: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) untrackedFiles(ctx context.Context) ([]string, error) {
+	output, err := s.output(ctx, "ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %w", err)
+	}
+	var files []string
+	for _, file := range strings.Split(output, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func (s *Store) metadataFile(name string) string {
+	return filepath.Join(s.dir, name+".json")
+}
+
+func (s *Store) tarball(name string) string {
+	return filepath.Join(s.dir, name+".tar.gz")
+}
+
+func readSnapshot(file string) (*Snapshot, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot %s: %w", filepath.Base(file), err)
+	}
+	return &snap, nil
+}
+
+// output runs git in the repository root and returns its trimmed output
+func (s *Store) output(ctx context.Context, args ...string) (string, error) {
+	cmd := git.Command(ctx, args...)
+	cmd.Dir = s.root
+	output, err := cmd.Output()
+	return strings.Trim(string(output), "\n"), err
+}
+
+func (s *Store) git(ctx context.Context, args ...string) error {
+	cmd := git.Command(ctx, args...)
+	cmd.Dir = s.root
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if len(output) > 0 {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGit(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", args...).CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+// setupRepo creates a repository with one commit on main and makes it the
+// working directory
+func setupRepo(t *testing.T) string {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	runGit(t, "init", "-q", "-b", "main")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.log\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte("one"), 0644))
+	runGit(t, "add", ".")
+	runGit(t, "commit", "-q", "-m", "one")
+	return dir
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	ctx := context.Background()
+	dir := setupRepo(t)
+	commit := runGit(t, "rev-parse", "HEAD")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte("staged"), 0644))
+	runGit(t, "add", "file.txt")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "file.txt"), []byte("unstaged"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "notes"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes", "todo.md"), []byte("todo"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "debug.log"), []byte("ignored"), 0644))
+
+	store, err := Open(ctx)
+	require.NoError(t, err)
+	snap, err := store.Create(ctx, "before-experiment", "baseline")
+	require.NoError(t, err)
+
+	assert.Equal(t, "main", snap.Branch)
+	assert.Equal(t, commit, snap.Commit)
+	assert.NotEmpty(t, snap.WorkTree)
+	assert.Equal(t, 1, snap.Untracked)
+	assert.Equal(t, snap.WorkTree, runGit(t, "rev-parse", RefPrefix+"before-experiment"))
+	// Creating a snapshot leaves the working tree alone
+	assert.Equal(t, "unstaged", readFile(t, filepath.Join(dir, "file.txt")))
+
+	_, err = store.Create(ctx, "before-experiment", "")
+	assert.True(t, errors.Is(err, ErrExists))
+
+	// Experiment: commit, add and delete files, switch branch
+	runGit(t, "commit", "-q", "-am", "experiment")
+	require.NoError(t, os.Remove(filepath.Join(dir, "notes", "todo.md")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scratch.txt"), []byte("scratch"), 0644))
+	runGit(t, "checkout", "-q", "-b", "other")
+
+	require.NoError(t, store.Restore(ctx, snap))
+	assert.Equal(t, "main", runGit(t, "rev-parse", "--abbrev-ref", "HEAD"))
+	assert.Equal(t, commit, runGit(t, "rev-parse", "HEAD"))
+	assert.Equal(t, "unstaged", readFile(t, filepath.Join(dir, "file.txt")))
+	assert.Equal(t, "staged", runGit(t, "show", ":file.txt"))
+	assert.Equal(t, "todo", readFile(t, filepath.Join(dir, "notes", "todo.md")))
+	assert.NoFileExists(t, filepath.Join(dir, "scratch.txt"))
+	assert.FileExists(t, filepath.Join(dir, "debug.log"))
+}
+
+func TestListAndDelete(t *testing.T) {
+	ctx := context.Background()
+	setupRepo(t)
+	store, err := Open(ctx)
+	require.NoError(t, err)
+
+	snaps, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, snaps)
+
+	first, err := store.Create(ctx, "first", "")
+	require.NoError(t, err)
+	assert.Empty(t, first.WorkTree)
+	assert.Equal(t, first.Commit, runGit(t, "rev-parse", RefPrefix+"first"))
+	_, err = store.Create(ctx, "second", "")
+	require.NoError(t, err)
+
+	snaps, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, snaps, 2)
+	assert.Equal(t, "first", snaps[0].Name)
+	assert.Equal(t, "second", snaps[1].Name)
+
+	require.NoError(t, store.Delete(ctx, "first"))
+	_, err = store.Get(ctx, "first")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Error(t, exec.Command("git", "rev-parse", "--verify", "-q", RefPrefix+"first").Run())
+	assert.True(t, errors.Is(store.Delete(ctx, "first"), ErrNotFound))
+}
+
+func TestValidateName(t *testing.T) {
+	ctx := context.Background()
+	for _, name := range []string{"wip", "before-refactor", "2024-01-02.1"} {
+		assert.NoError(t, ValidateName(ctx, name), name)
+	}
+	for _, name := range []string{"", "a/b", "bad name", "x..y", "end.lock", "../escape"} {
+		assert.Error(t, ValidateName(ctx, name), name)
+	}
+}
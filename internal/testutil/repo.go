@@ -0,0 +1,278 @@
+// Package testutil builds fixture repositories for tests: branches,
+// conflicts, large blobs, a detached HEAD and reflog states, scripted
+// step by step in a temporary directory.
+package testutil
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// identity is the author and committer of every fixture commit, so tests
+// don't depend on the machine's git configuration
+var identity = []string{
+	"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+	"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	"GIT_CONFIG_NOSYSTEM=1",
+}
+
+// Repo is a fixture repository. Its methods fail the test when git does.
+type Repo struct {
+	t   testing.TB
+	Dir string
+	// Remote is the bare repository of origin, once WithRemote made one
+	Remote string
+}
+
+// NewRepo creates an empty repository on main in a temporary directory,
+// with the fixture identity in its config for the commands under test
+func NewRepo(t testing.TB) *Repo {
+	t.Helper()
+	r := &Repo{t: t, Dir: t.TempDir()}
+	r.Git("init", "--quiet", "--initial-branch=main")
+	r.Git("config", "user.name", "Test")
+	r.Git("config", "user.email", "test@example.com")
+	r.Git("config", "commit.gpgsign", "false")
+	return r
+}
+
+// Git runs git in the repository and returns its trimmed output
+func (r *Repo) Git(args ...string) string {
+	r.t.Helper()
+	return r.git(r.Dir, args...)
+}
+
+func (r *Repo) git(dir string, args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), identity...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// Path returns the absolute path of a file in the working tree
+func (r *Repo) Path(name string) string {
+	return filepath.Join(r.Dir, filepath.FromSlash(name))
+}
+
+// WriteFile writes a file in the working tree, creating its directories
+func (r *Repo) WriteFile(name, content string) {
+	r.t.Helper()
+	path := r.Path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// ReadFile returns the content of a file in the working tree
+func (r *Repo) ReadFile(name string) string {
+	r.t.Helper()
+	data, err := os.ReadFile(r.Path(name))
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return string(data)
+}
+
+// Commit writes files, given as name and content pairs, and commits them
+// with message. It returns the new commit.
+func (r *Repo) Commit(message string, files ...string) string {
+	r.t.Helper()
+	if len(files)%2 != 0 {
+		r.t.Fatalf("Commit needs name and content pairs, got %d values", len(files))
+	}
+	for i := 0; i < len(files); i += 2 {
+		r.WriteFile(files[i], files[i+1])
+		r.Git("add", "--", files[i])
+	}
+	r.Git("commit", "--quiet", "--allow-empty", "-m", message)
+	return r.Head()
+}
+
+// Commits makes n commits each adding a file, named after prefix, and
+// returns them oldest first
+func (r *Repo) Commits(prefix string, n int) []string {
+	r.t.Helper()
+	var shas []string
+	for i := 1; i <= n; i++ {
+		name := fmt.Sprintf("%s%d.txt", prefix, i)
+		shas = append(shas, r.Commit(fmt.Sprintf("Add %s", name), name, fmt.Sprintf("%s %d\n", prefix, i)))
+	}
+	return shas
+}
+
+// Head returns the commit HEAD points to
+func (r *Repo) Head() string {
+	r.t.Helper()
+	return r.RevParse("HEAD")
+}
+
+// RevParse returns the commit a revision names
+func (r *Repo) RevParse(rev string) string {
+	r.t.Helper()
+	return r.Git("rev-parse", "--verify", rev+"^{commit}")
+}
+
+// Exists reports whether a revision names a commit
+func (r *Repo) Exists(rev string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	cmd.Dir = r.Dir
+	return cmd.Run() == nil
+}
+
+// Branch returns the current branch, or "" on a detached HEAD
+func (r *Repo) Branch() string {
+	r.t.Helper()
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	cmd.Dir = r.Dir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// Checkout switches to a branch, creating it at HEAD when it doesn't exist
+func (r *Repo) Checkout(branch string) {
+	r.t.Helper()
+	if r.Exists("refs/heads/" + branch) {
+		r.Git("checkout", "--quiet", branch)
+	} else {
+		r.Git("checkout", "--quiet", "-b", branch)
+	}
+}
+
+// Count returns the number of commits in a revision range
+func (r *Repo) Count(revRange string) int {
+	r.t.Helper()
+	var n int
+	fmt.Sscan(r.Git("rev-list", "--count", revRange), &n)
+	return n
+}
+
+// Log returns the subjects of the commits reachable from rev, newest first
+func (r *Repo) Log(rev string) []string {
+	r.t.Helper()
+	output := r.Git("log", "--format=%s", rev)
+	if output == "" {
+		return nil
+	}
+	return strings.Split(output, "\n")
+}
+
+// Status returns 'git status --porcelain', "" when the tree is clean
+func (r *Repo) Status() string {
+	r.t.Helper()
+	return r.Git("status", "--porcelain")
+}
+
+// Chdir makes the repository the working directory until the test ends,
+// since commands work on the current directory
+func (r *Repo) Chdir() {
+	r.t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.Chdir(r.Dir); err != nil {
+		r.t.Fatal(err)
+	}
+	r.t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// WithRemote creates a bare origin, pushes main to it and sets it as the
+// upstream of main
+func (r *Repo) WithRemote() *Repo {
+	r.t.Helper()
+	r.Remote = filepath.Join(r.t.TempDir(), "origin.git")
+	r.git(r.Dir, "init", "--quiet", "--bare", "--initial-branch=main", r.Remote)
+	r.Git("remote", "add", "origin", r.Remote)
+	r.Git("push", "--quiet", "--set-upstream", "origin", "main")
+	r.Git("remote", "set-head", "origin", "main")
+	return r
+}
+
+// PushFromClone makes commits on branch in another clone of origin and
+// pushes them, so the repository falls behind. It returns the commits.
+func (r *Repo) PushFromClone(branch string, n int) []string {
+	r.t.Helper()
+	if r.Remote == "" {
+		r.t.Fatal("PushFromClone needs WithRemote")
+	}
+	other := &Repo{t: r.t, Dir: filepath.Join(r.t.TempDir(), "clone")}
+	r.git(r.Dir, "clone", "--quiet", "--branch", branch, r.Remote, other.Dir)
+	shas := other.Commits("remote", n)
+	other.Git("push", "--quiet", "origin", branch)
+	return shas
+}
+
+// MergedBranches creates branches with one commit each, merged into main
+func (r *Repo) MergedBranches(names ...string) {
+	r.t.Helper()
+	for _, name := range names {
+		r.Git("checkout", "--quiet", "-b", name, "main")
+		r.Commit("Work on "+name, name+".txt", name+"\n")
+		r.Git("checkout", "--quiet", "main")
+		r.Git("merge", "--quiet", "--no-ff", "-m", "Merge "+name, name)
+	}
+}
+
+// Conflict leaves a merge of a branch into the current one stopped on a
+// conflict in file
+func (r *Repo) Conflict(branch, file string) {
+	r.t.Helper()
+	current := r.Branch()
+	r.Commit("Base of "+file, file, "base\n")
+	r.Git("checkout", "--quiet", "-b", branch)
+	r.Commit("Change "+file+" on "+branch, file, "theirs\n")
+	r.Git("checkout", "--quiet", current)
+	r.Commit("Change "+file+" on "+current, file, "ours\n")
+
+	cmd := exec.Command("git", "merge", "--no-edit", branch)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(), identity...)
+	if cmd.Run() == nil {
+		r.t.Fatalf("merging %s didn't conflict", branch)
+	}
+}
+
+// LargeBlob commits a file of size random bytes, which don't compress, and
+// returns the commit
+func (r *Repo) LargeBlob(name string, size int) string {
+	r.t.Helper()
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		r.t.Fatal(err)
+	}
+	return r.Commit("Add "+name, name, string(data))
+}
+
+// DetachedCommits detaches HEAD and makes n commits on no branch, returning
+// them oldest first
+func (r *Repo) DetachedCommits(n int) []string {
+	r.t.Helper()
+	r.Git("checkout", "--quiet", "--detach")
+	return r.Commits("detached", n)
+}
+
+// LostCommits makes n commits and resets them away, so only the reflog
+// still knows them. It returns them oldest first.
+func (r *Repo) LostCommits(n int) []string {
+	r.t.Helper()
+	base := r.Head()
+	shas := r.Commits("lost", n)
+	r.Git("reset", "--quiet", "--hard", base)
+	return shas
+}
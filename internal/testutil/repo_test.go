@@ -0,0 +1,35 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixtures(t *testing.T) {
+	r := NewRepo(t)
+	base := r.Commit("Base", "base.txt", "base\n")
+	assert.Equal(t, "main", r.Branch())
+	assert.Equal(t, []string{"Base"}, r.Log("HEAD"))
+
+	r.MergedBranches("done")
+	assert.Equal(t, 0, r.Count("main..done"))
+
+	lost := r.LostCommits(2)
+	assert.Len(t, lost, 2)
+	assert.NotEqual(t, lost[1], r.Head())
+	assert.Contains(t, r.Git("reflog", "--format=%H"), lost[1])
+
+	r.DetachedCommits(1)
+	assert.Equal(t, "", r.Branch())
+	r.Checkout("main")
+
+	r.WithRemote()
+	pushed := r.PushFromClone("main", 1)
+	r.Git("fetch", "--quiet")
+	assert.Equal(t, pushed[0], r.RevParse("origin/main"))
+
+	r.Conflict("feature", "shared.txt")
+	assert.Contains(t, r.Status(), "UU shared.txt")
+	assert.True(t, r.Exists(base))
+}
@@ -0,0 +1,75 @@
+package ui
+
+import "io"
+
+// std is the printer of the package functions
+var std, _ = New(nil, nil, Options{})
+
+// Configure sets the options of the package functions
+func Configure(opts Options) error {
+	p, err := New(std.out, std.err, opts)
+	if err != nil {
+		return err
+	}
+	std = p
+	return nil
+}
+
+// SetOutput sends the output of the package functions to out and err, e.g.
+// buffers in tests; nil restores os.Stdout and os.Stderr
+func SetOutput(out, err io.Writer) {
+	p, _ := New(out, err, std.opts)
+	std = p
+}
+
+// Default returns the printer of the package functions
+func Default() *Printer { return std }
+
+// Out returns where results are written
+func Out() io.Writer { return std.Out() }
+
+// Err returns where warnings and errors are written
+func Err() io.Writer { return std.Err() }
+
+// Status returns where progress indicators are written
+func Status() io.Writer { return std.Status() }
+
+// Print writes a result like fmt.Print
+func Print(a ...interface{}) { std.Print(a...) }
+
+// Println writes a result like fmt.Println
+func Println(a ...interface{}) { std.Println(a...) }
+
+// Printf writes a result like fmt.Printf
+func Printf(format string, a ...interface{}) { std.Printf(format, a...) }
+
+// Step reports progress of the command, like fmt.Println
+func Step(a ...interface{}) { std.Step(a...) }
+
+// Stepf reports progress of the command, like fmt.Printf without the newline
+func Stepf(format string, a ...interface{}) { std.Stepf(format, a...) }
+
+// Info writes a note, like fmt.Println
+func Info(a ...interface{}) { std.Info(a...) }
+
+// Infof writes a note, like fmt.Printf without the newline
+func Infof(format string, a ...interface{}) { std.Infof(format, a...) }
+
+// Success reports something done, like fmt.Println
+func Success(a ...interface{}) { std.Success(a...) }
+
+// Successf reports something done, like fmt.Printf without the newline
+func Successf(format string, a ...interface{}) { std.Successf(format, a...) }
+
+// Warn writes a warning, like fmt.Println
+func Warn(a ...interface{}) { std.Warn(a...) }
+
+// Warnf writes a warning, like fmt.Printf without the newline
+func Warnf(format string, a ...interface{}) { std.Warnf(format, a...) }
+
+// Error writes a failure the command goes on after, like fmt.Println
+func Error(a ...interface{}) { std.Error(a...) }
+
+// Errorf writes a failure the command goes on after, like fmt.Printf
+// without the newline
+func Errorf(format string, a ...interface{}) { std.Errorf(format, a...) }
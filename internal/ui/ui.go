@@ -0,0 +1,254 @@
+// Package ui prints githelper's output. Commands print results with Print,
+// Println and Printf, and report what happens with Step, Info, Success,
+// Warn and Error, whose icons and colors come from the theme. The printer
+// handles NO_COLOR, --quiet and --machine, so commands don't.
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/EndlessUphill/git-helper/internal/plain"
+	"github.com/EndlessUphill/git-helper/internal/progress"
+)
+
+// Level is the kind of a status message
+type Level int
+
+const (
+	// LevelStep is progress of the command, e.g. "📦 Stashing changes..."
+	LevelStep Level = iota
+	LevelInfo
+	LevelSuccess
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelStep:    "step",
+	LevelInfo:    "info",
+	LevelSuccess: "success",
+	LevelWarn:    "warning",
+	LevelError:   "error",
+}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// Theme is how status messages look: an icon, followed by its spacing, and
+// an SGR color such as "32" for green, per level
+type Theme struct {
+	Icons  map[Level]string
+	Colors map[Level]string
+	// Plain replaces the emoji of messages with ASCII, as --plain does
+	Plain bool
+}
+
+var emojiIcons = map[Level]string{
+	LevelInfo:    "ℹ️  ",
+	LevelSuccess: "✅ ",
+	LevelWarn:    "⚠️  ",
+	LevelError:   "❌ ",
+}
+
+// Themes are the themes the theme setting can select
+var Themes = map[string]Theme{
+	"default": {
+		Icons:  emojiIcons,
+		Colors: map[Level]string{LevelSuccess: "32", LevelWarn: "33", LevelError: "31"},
+	},
+	// Blue and orange tell success from failure with red-green color blindness
+	"colorblind": {
+		Icons:  emojiIcons,
+		Colors: map[Level]string{LevelSuccess: "34", LevelWarn: "38;5;214", LevelError: "38;5;208"},
+	},
+	"high-contrast": {
+		Icons:  emojiIcons,
+		Colors: map[Level]string{LevelStep: "1", LevelInfo: "1;96", LevelSuccess: "1;92", LevelWarn: "1;93", LevelError: "1;91"},
+	},
+	"ascii": {
+		Icons:  map[Level]string{LevelInfo: "[info] ", LevelSuccess: "[ok] ", LevelWarn: "[warn] ", LevelError: "[error] "},
+		Colors: map[Level]string{LevelSuccess: "32", LevelWarn: "33", LevelError: "31"},
+		Plain:  true,
+	},
+}
+
+// ThemeNames returns the names of the themes, sorted
+func ThemeNames() []string {
+	var names []string
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Options configure a Printer
+type Options struct {
+	// Theme is a name from Themes; "" is the default theme
+	Theme string
+	// NoColor turns colors off, as does the NO_COLOR environment variable
+	NoColor bool
+	// Quiet leaves out steps, information and successes; warnings, errors
+	// and results are still printed
+	Quiet bool
+	// Machine writes status messages to stderr as JSON lines without icons,
+	// leaving stdout to results
+	Machine bool
+}
+
+// Printer writes results and status messages
+type Printer struct {
+	mu sync.Mutex
+	// out and err are nil to write to os.Stdout and os.Stderr as they are at
+	// the time, since --plain replaces them
+	out, err io.Writer
+	opts     Options
+	theme    Theme
+}
+
+// New returns a printer writing to out and err, or to os.Stdout and
+// os.Stderr when they are nil
+func New(out, err io.Writer, opts Options) (*Printer, error) {
+	name := opts.Theme
+	if name == "" {
+		name = "default"
+	}
+	theme, ok := Themes[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown theme '%s'. Use one of: %s", opts.Theme, strings.Join(ThemeNames(), ", "))
+	}
+	return &Printer{out: out, err: err, opts: opts, theme: theme}, nil
+}
+
+// Out returns where results are written, e.g. for a tabwriter
+func (p *Printer) Out() io.Writer {
+	if p.out != nil {
+		return p.out
+	}
+	return os.Stdout
+}
+
+// Err returns where warnings and errors are written
+func (p *Printer) Err() io.Writer {
+	if p.err != nil {
+		return p.err
+	}
+	return os.Stderr
+}
+
+// Status returns where progress indicators are written: the output, or
+// nowhere in quiet and machine modes
+func (p *Printer) Status() io.Writer {
+	if p.opts.Quiet || p.opts.Machine {
+		return io.Discard
+	}
+	return p.Out()
+}
+
+// Print writes a result like fmt.Print
+func (p *Printer) Print(a ...interface{}) {
+	fmt.Fprint(p.Out(), a...)
+}
+
+// Println writes a result like fmt.Println
+func (p *Printer) Println(a ...interface{}) {
+	fmt.Fprintln(p.Out(), a...)
+}
+
+// Printf writes a result like fmt.Printf
+func (p *Printer) Printf(format string, a ...interface{}) {
+	fmt.Fprintf(p.Out(), format, a...)
+}
+
+// Message writes a status message of level on its own line. Newlines the
+// message starts with are written before the icon.
+func (p *Printer) Message(level Level, message string) {
+	if p.opts.Quiet && level < LevelWarn {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.opts.Machine {
+		line, _ := json.Marshal(struct {
+			Level   string `json:"level"`
+			Message string `json:"message"`
+		}{level.String(), strings.TrimSpace(plain.Text(message))})
+		fmt.Fprintf(p.Err(), "%s\n", line)
+		return
+	}
+
+	w := p.Out()
+	if level >= LevelWarn {
+		w = p.Err()
+	}
+	body := strings.TrimLeft(message, "\n")
+	lead := message[:len(message)-len(body)]
+	if p.theme.Plain {
+		body = plain.Text(body)
+	}
+	text := p.theme.Icons[level] + body
+	if color := p.theme.Colors[level]; color != "" && p.colored(w) {
+		text = "\x1b[" + color + "m" + text + "\x1b[0m"
+	}
+	fmt.Fprintf(w, "%s%s\n", lead, text)
+}
+
+// colored reports whether colors can be written to w
+func (p *Printer) colored(w io.Writer) bool {
+	return !p.opts.NoColor && os.Getenv("NO_COLOR") == "" && progress.IsTerminal(w)
+}
+
+// Step reports progress of the command, like fmt.Println
+func (p *Printer) Step(a ...interface{}) { p.Message(LevelStep, sprintln(a)) }
+
+// Stepf reports progress of the command, like fmt.Printf without the newline
+func (p *Printer) Stepf(format string, a ...interface{}) {
+	p.Message(LevelStep, fmt.Sprintf(format, a...))
+}
+
+// Info writes a note, like fmt.Println
+func (p *Printer) Info(a ...interface{}) { p.Message(LevelInfo, sprintln(a)) }
+
+// Infof writes a note, like fmt.Printf without the newline
+func (p *Printer) Infof(format string, a ...interface{}) {
+	p.Message(LevelInfo, fmt.Sprintf(format, a...))
+}
+
+// Success reports something done, like fmt.Println
+func (p *Printer) Success(a ...interface{}) { p.Message(LevelSuccess, sprintln(a)) }
+
+// Successf reports something done, like fmt.Printf without the newline
+func (p *Printer) Successf(format string, a ...interface{}) {
+	p.Message(LevelSuccess, fmt.Sprintf(format, a...))
+}
+
+// Warn writes a warning, like fmt.Println
+func (p *Printer) Warn(a ...interface{}) { p.Message(LevelWarn, sprintln(a)) }
+
+// Warnf writes a warning, like fmt.Printf without the newline
+func (p *Printer) Warnf(format string, a ...interface{}) {
+	p.Message(LevelWarn, fmt.Sprintf(format, a...))
+}
+
+// Error writes a failure the command goes on after, like fmt.Println.
+// Failures that stop the command are returned as errors instead.
+func (p *Printer) Error(a ...interface{}) { p.Message(LevelError, sprintln(a)) }
+
+// Errorf writes a failure the command goes on after, like fmt.Printf
+// without the newline
+func (p *Printer) Errorf(format string, a ...interface{}) {
+	p.Message(LevelError, fmt.Sprintf(format, a...))
+}
+
+// sprintln formats like fmt.Println, without the newline
+func sprintln(a []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(a...), "\n")
+}
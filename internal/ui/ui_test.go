@@ -0,0 +1,69 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPrinter(t *testing.T, opts Options) (*Printer, *bytes.Buffer, *bytes.Buffer) {
+	var out, err bytes.Buffer
+	p, e := New(&out, &err, opts)
+	require.NoError(t, e)
+	return p, &out, &err
+}
+
+func TestMessages(t *testing.T) {
+	p, out, errOut := newTestPrinter(t, Options{})
+	p.Step("📦 Stashing local changes...")
+	p.Successf("Pushed %d commit(s)", 3)
+	p.Info("\nNothing to prune")
+	p.Printf("%-6s %s\n", "main", "abc123")
+	p.Warnf("%s is behind", "main")
+	p.Error("Operation cancelled")
+
+	assert.Equal(t, "📦 Stashing local changes...\n✅ Pushed 3 commit(s)\n\nℹ️  Nothing to prune\nmain   abc123\n", out.String())
+	assert.Equal(t, "⚠️  main is behind\n❌ Operation cancelled\n", errOut.String(), "warnings and errors go to stderr")
+}
+
+func TestQuiet(t *testing.T) {
+	p, out, errOut := newTestPrinter(t, Options{Quiet: true})
+	p.Step("📦 Stashing local changes...")
+	p.Info("note")
+	p.Success("done")
+	p.Println("result")
+	p.Warn("careful")
+
+	assert.Equal(t, "result\n", out.String())
+	assert.Equal(t, "⚠️  careful\n", errOut.String())
+}
+
+func TestMachine(t *testing.T) {
+	p, out, errOut := newTestPrinter(t, Options{Machine: true})
+	p.Step("📦 Stashing local changes...")
+	p.Successf("Already up to date with %s", "origin/main")
+	p.Println(`{"name":"main"}`)
+
+	assert.Equal(t, "{\"name\":\"main\"}\n", out.String())
+	assert.Equal(t, `{"level":"step","message":"Stashing local changes..."}
+{"level":"success","message":"Already up to date with origin/main"}
+`, errOut.String())
+}
+
+func TestThemes(t *testing.T) {
+	p, out, _ := newTestPrinter(t, Options{Theme: "ascii"})
+	p.Success("Branch renamed")
+	p.Step("🔀 Retargeting → main")
+	assert.Equal(t, "[ok] Branch renamed\nRetargeting -> main\n", out.String())
+
+	_, err := New(nil, nil, Options{Theme: "neon"})
+	assert.ErrorContains(t, err, "unknown theme 'neon'")
+}
+
+func TestColors(t *testing.T) {
+	p, out, _ := newTestPrinter(t, Options{})
+	p.Success("done")
+	assert.NotContains(t, out.String(), "\x1b[", "no colors when not writing to a terminal")
+}
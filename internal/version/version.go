@@ -7,4 +7,7 @@ var (
 	CommitHash = "none"
 	// BuildDate is the date when the binary was built
 	BuildDate = "unknown"
-) 
\ No newline at end of file
+)
+
+// Repository is the GitHub repository githelper is released from
+const Repository = "EndlessUphill/git-helper"